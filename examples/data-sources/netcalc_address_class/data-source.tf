@@ -0,0 +1,15 @@
+variable "office_cidr_block" {
+  type    = string
+  default = "192.168.10.0/24"
+}
+
+data "netcalc_address_class" "office" {
+  address = var.office_cidr_block
+}
+
+check "office_is_private" {
+  assert {
+    condition     = data.netcalc_address_class.office.private || data.netcalc_address_class.office.cgnat
+    error_message = "${var.office_cidr_block} is not a private range: ${join(", ", data.netcalc_address_class.office.classes)}"
+  }
+}
@@ -0,0 +1,18 @@
+# A DHCP pool given as a range, as CIDR blocks for a firewall rule.
+data "netcalc_address_range" "dhcp" {
+  first_address = "10.0.1.10"
+  last_address  = "10.0.1.99"
+}
+
+# A subnet as a range, e.g. for a DHCP scope.
+data "netcalc_address_range" "subnet" {
+  cidr_block = "10.0.2.0/24"
+}
+
+output "dhcp_cidr_blocks" {
+  value = data.netcalc_address_range.dhcp.cidr_blocks
+}
+
+output "subnet_range" {
+  value = "${data.netcalc_address_range.subnet.first_address} - ${data.netcalc_address_range.subnet.last_address}"
+}
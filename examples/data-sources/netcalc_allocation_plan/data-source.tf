@@ -0,0 +1,11 @@
+data "netcalc_allocation_plan" "example" {
+  requests = [
+    { name = "app", cidr_mask_length = 24, count = 3 },
+    { name = "db", cidr_mask_length = 26, count = 2 },
+    { name = "app_v6", ip_family = "ipv6", cidr_mask_length = 64, count = 3 },
+  ]
+}
+
+output "address_plan" {
+  value = { for a in data.netcalc_allocation_plan.example.allocations : a.name => a.cidr_blocks }
+}
@@ -0,0 +1,11 @@
+data "netcalc_capacity" "example" {
+  cidr_mask_length = 24
+}
+
+# e.g. warn before the pools run out of /24s.
+check "capacity" {
+  assert {
+    condition     = data.netcalc_capacity.example.available_count >= 10
+    error_message = "Only ${data.netcalc_capacity.example.available_count} /24 subnets left."
+  }
+}
@@ -0,0 +1,17 @@
+variable "dns_server" {
+  type    = string
+  default = "10.0.1.2"
+}
+
+data "netcalc_contains" "dns_server" {
+  address     = var.dns_server
+  cidr_blocks = ["10.0.0.0/16", "10.1.0.0/16"]
+}
+
+# e.g. make sure a user supplied address is reachable.
+check "dns_server" {
+  assert {
+    condition     = data.netcalc_contains.dns_server.contains
+    error_message = "${var.dns_server} is not within the VPC CIDR blocks."
+  }
+}
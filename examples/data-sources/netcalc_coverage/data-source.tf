@@ -0,0 +1,17 @@
+variable "subnet_cidr_blocks" {
+  type    = list(string)
+  default = ["10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26"]
+}
+
+data "netcalc_coverage" "example" {
+  cidr_block         = "10.0.0.0/24"
+  subnet_cidr_blocks = var.subnet_cidr_blocks
+}
+
+# e.g. reject address plans that waste or double book space.
+check "address_plan" {
+  assert {
+    condition     = data.netcalc_coverage.example.exact
+    error_message = "Uncovered: ${join(", ", data.netcalc_coverage.example.uncovered_cidr_blocks)}. Overlapping: ${join(", ", data.netcalc_coverage.example.overlapping_cidr_blocks)}. Outside: ${join(", ", data.netcalc_coverage.example.outside_cidr_blocks)}."
+  }
+}
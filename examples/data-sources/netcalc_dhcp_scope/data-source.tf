@@ -0,0 +1,17 @@
+# Lease everything but the router and the first ten hosts, which are kept for
+# static assignments.
+data "netcalc_dhcp_scope" "office" {
+  cidr_block    = "10.0.1.0/24"
+  router_offset = 1
+  reserved_head = 10
+}
+
+output "dhcpd_subnet" {
+  value = <<-EOT
+    subnet 10.0.1.0 netmask ${data.netcalc_dhcp_scope.office.netmask} {
+      range ${data.netcalc_dhcp_scope.office.range_start} ${data.netcalc_dhcp_scope.office.range_end};
+      option routers ${data.netcalc_dhcp_scope.office.router};
+      option broadcast-address ${data.netcalc_dhcp_scope.office.broadcast};
+    }
+  EOT
+}
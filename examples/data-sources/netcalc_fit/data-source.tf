@@ -0,0 +1,13 @@
+data "netcalc_fit" "expansion" {
+  requests = [
+    { name = "services", cidr_mask_length = 24, count = 10 },
+    { name = "clusters", cidr_mask_length = 22, count = 2 },
+  ]
+}
+
+check "expansion" {
+  assert {
+    condition     = data.netcalc_fit.expansion.fits
+    error_message = "The ${data.netcalc_fit.expansion.failed_request_name} subnets do not fit in ${data.netcalc_fit.expansion.limiting_pool}."
+  }
+}
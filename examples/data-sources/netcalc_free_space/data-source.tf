@@ -0,0 +1,23 @@
+resource "netcalc_pool" "example" {
+  cidr_blocks = ["10.0.0.0/16"]
+}
+
+resource "netcalc_subnet" "example" {
+  for_each = toset(["app", "db"])
+
+  pool_id          = netcalc_pool.example.id
+  cidr_mask_length = 24
+}
+
+data "netcalc_free_space" "example" {
+  pool_id               = netcalc_pool.example.id
+  allocated_cidr_blocks = [for s in netcalc_subnet.example : s.cidr_block]
+}
+
+# e.g. fail the plan before the pool runs out of space.
+check "pool_capacity" {
+  assert {
+    condition     = data.netcalc_free_space.example.pools[0].utilization < 80
+    error_message = "Pool ${netcalc_pool.example.id} is over 80% utilized."
+  }
+}
@@ -0,0 +1,9 @@
+data "netcalc_gaps" "example" {
+  cidr_block       = "10.0.0.0/24"
+  used_cidr_blocks = ["10.0.0.0/26", "10.0.0.128/27"]
+}
+
+# e.g. the gaps that can hold a /27.
+output "gaps_for_a_27" {
+  value = [for g in data.netcalc_gaps.example.gaps : "${g.first_address}-${g.last_address}" if g.largest_cidr_mask_length <= 27]
+}
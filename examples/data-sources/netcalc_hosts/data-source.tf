@@ -0,0 +1,10 @@
+data "netcalc_hosts" "example" {
+  cidr_block = "10.0.1.0/28"
+}
+
+# e.g. a DNS record per host.
+output "dns_records" {
+  value = {
+    for i, ip in data.netcalc_hosts.example.host_addresses : format("host-%02d.example.com", i + 1) => ip
+  }
+}
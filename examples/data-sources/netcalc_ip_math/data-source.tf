@@ -0,0 +1,6 @@
+# The first address of the second /64 in a /48, beyond the offsets
+# cidrhost accepts.
+data "netcalc_ip_math" "example" {
+  cidr_block = "fd08:1ad7:3d3c::/48"
+  offset     = pow(2, 64) + 1
+}
@@ -0,0 +1,17 @@
+# The NAT64 addresses IPv6-only clients use to reach an IPv4 network.
+data "netcalc_ipv4_embedding" "legacy_network" {
+  ipv4 = "192.0.2.0/24"
+}
+
+# The IPv4 address behind a NAT64 address seen in logs.
+data "netcalc_ipv4_embedding" "client" {
+  ipv6 = "64:ff9b::c633:6401"
+}
+
+output "nat64_cidr_block" {
+  value = data.netcalc_ipv4_embedding.legacy_network.ipv6
+}
+
+output "client_ipv4" {
+  value = data.netcalc_ipv4_embedding.client.ipv4
+}
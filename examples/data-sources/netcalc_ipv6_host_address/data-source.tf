@@ -0,0 +1,15 @@
+# A predictable address per host, which survives re-creating the host.
+data "netcalc_ipv6_host_address" "web" {
+  cidr_block = "2001:db8:1:2::/64"
+  stable_key = "web-1.example.com"
+}
+
+# The SLAAC address of a network interface.
+data "netcalc_ipv6_host_address" "printer" {
+  cidr_block  = "2001:db8:1:2::/64"
+  mac_address = "00:1a:2b:3c:4d:5e"
+}
+
+output "web_address" {
+  value = data.netcalc_ipv6_host_address.web.ip_address
+}
@@ -0,0 +1,19 @@
+data "netcalc_kubernetes_cidr_plan" "example" {
+  clusters = [
+    # A /24 of pods per node, as with the controller manager's defaults.
+    { name = "prod", max_nodes = 250 },
+    { name = "staging", max_nodes = 50, node_pod_cidr_mask_length = 25 },
+    { name = "dev", max_nodes = 10, node_pod_cidr_mask_length = 26, service_cidr_mask_length = 24 },
+  ]
+}
+
+check "clusters_fit" {
+  assert {
+    condition     = data.netcalc_kubernetes_cidr_plan.example.fulfilled
+    error_message = "Not every cluster fits in the pools."
+  }
+}
+
+output "cluster_networks" {
+  value = { for p in data.netcalc_kubernetes_cidr_plan.example.plans : p.name => p }
+}
@@ -0,0 +1,17 @@
+data "netcalc_netmask" "example" {
+  cidr_mask_length = 20
+}
+
+# e.g. an ACL entry on a device that expects wildcard masks.
+output "acl_entry" {
+  value = "permit ip 10.0.16.0 ${data.netcalc_netmask.example.wildcard_mask} any"
+}
+
+# The reverse, from a legacy device configuration.
+data "netcalc_netmask" "legacy" {
+  netmask = "255.255.255.192"
+}
+
+output "legacy_mask_length" {
+  value = data.netcalc_netmask.legacy.cidr_mask_length
+}
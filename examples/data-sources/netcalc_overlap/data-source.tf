@@ -0,0 +1,14 @@
+data "netcalc_overlap" "peering" {
+  cidr_lists = {
+    shared = ["10.0.0.0/16"]
+    app    = ["10.1.0.0/16", "10.2.0.0/16"]
+  }
+}
+
+# e.g. refuse to plan a peering between overlapping networks.
+check "peering" {
+  assert {
+    condition     = !data.netcalc_overlap.peering.has_overlaps
+    error_message = join(", ", [for o in data.netcalc_overlap.peering.overlaps : "${o.cidr_block} (${o.list}) overlaps ${o.other_cidr_block} (${o.other_list})"])
+  }
+}
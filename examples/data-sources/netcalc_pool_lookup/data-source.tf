@@ -0,0 +1,27 @@
+resource "netcalc_pool" "us" {
+  cidr_blocks = ["10.1.0.0/16"]
+  tags        = { region = "us-east-1" }
+}
+
+resource "netcalc_pool" "eu" {
+  cidr_blocks = ["10.2.0.0/16"]
+  tags        = { region = "eu-west-1" }
+}
+
+variable "vpc_cidr_block" {
+  type    = string
+  default = "10.2.4.0/22"
+}
+
+data "netcalc_pool_lookup" "vpc" {
+  address = var.vpc_cidr_block
+  pools = {
+    us = netcalc_pool.us
+    eu = netcalc_pool.eu
+  }
+}
+
+# e.g. deploy to the region the VPC's address space belongs to.
+output "region" {
+  value = data.netcalc_pool_lookup.vpc.tags["region"]
+}
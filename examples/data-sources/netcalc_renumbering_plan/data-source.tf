@@ -0,0 +1,12 @@
+resource "netcalc_pool" "new" {
+  cidr_blocks = ["172.16.0.0/16"]
+}
+
+data "netcalc_renumbering_plan" "example" {
+  pool_id     = netcalc_pool.new.id
+  cidr_blocks = ["10.0.5.0/26", "10.0.8.0/24", "10.0.9.0/25"]
+}
+
+output "renumbering" {
+  value = data.netcalc_renumbering_plan.example.mapping
+}
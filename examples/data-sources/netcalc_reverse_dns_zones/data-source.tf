@@ -0,0 +1,9 @@
+data "netcalc_reverse_dns_zones" "example" {
+  cidr_block = "10.0.0.0/23"
+}
+
+# e.g. a reverse zone per /24 of the subnet.
+resource "aws_route53_zone" "reverse" {
+  for_each = toset(data.netcalc_reverse_dns_zones.example.zone_names)
+  name     = each.value
+}
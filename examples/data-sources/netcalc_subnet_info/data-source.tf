@@ -0,0 +1,8 @@
+data "netcalc_subnet_info" "example" {
+  cidr_block = "10.0.1.0/24"
+}
+
+# e.g. an ACL entry for routers that use wildcard masks.
+output "acl_entry" {
+  value = "permit ip ${data.netcalc_subnet_info.example.network_address} ${data.netcalc_subnet_info.example.wildcard_mask} any"
+}
@@ -0,0 +1,8 @@
+data "netcalc_ula" "example" {
+  seed = "production"
+}
+
+# e.g. allocate IPv6 subnets from the generated prefix.
+resource "netcalc_pool" "example" {
+  cidr_blocks = [data.netcalc_ula.example.cidr_block]
+}
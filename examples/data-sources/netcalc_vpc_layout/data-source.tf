@@ -0,0 +1,17 @@
+data "netcalc_vpc_layout" "example" {
+  cidr_block         = "10.0.0.0/16"
+  availability_zones = ["us-east-1a", "us-east-1b", "us-east-1c"]
+  tiers = {
+    public  = 24
+    private = 20
+    db      = 26
+  }
+}
+
+# e.g. a private subnet per availability zone.
+resource "aws_subnet" "private" {
+  for_each          = data.netcalc_vpc_layout.example.subnets["private"]
+  vpc_id            = "vpc-0123456789abcdef0"
+  availability_zone = each.key
+  cidr_block        = each.value
+}
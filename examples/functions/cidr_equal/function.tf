@@ -0,0 +1,9 @@
+variable "reported_cidr_block" {
+  type    = string
+  default = "2001:0DB8:0000:0000::/64"
+}
+
+# True, as both CIDR blocks are 2001:db8::/64.
+output "unchanged" {
+  value = provider::netcalc::cidr_equal(var.reported_cidr_block, "2001:db8::/64")
+}
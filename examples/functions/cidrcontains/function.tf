@@ -0,0 +1,8 @@
+variable "subnet_cidr_block" {
+  type = string
+
+  validation {
+    condition     = provider::netcalc::cidrcontains("10.0.0.0/16", var.subnet_cidr_block)
+    error_message = "The subnet must lie within the VPC CIDR block 10.0.0.0/16."
+  }
+}
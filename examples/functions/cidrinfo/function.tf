@@ -0,0 +1,13 @@
+locals {
+  subnet = provider::netcalc::cidrinfo("10.0.1.0/24")
+}
+
+# The result is "10.0.1.1".
+output "gateway" {
+  value = local.subnet.first_usable_host
+}
+
+# The result is "255.255.255.0".
+output "netmask" {
+  value = local.subnet.netmask
+}
@@ -0,0 +1,9 @@
+# The result is "255.255.255.0".
+output "ipv4_netmask" {
+  value = provider::netcalc::cidrnetmask("10.0.1.0/24")
+}
+
+# The result is "ffff:ffff:ffff:ffff::".
+output "ipv6_netmask" {
+  value = provider::netcalc::cidrnetmask("fd00::/64")
+}
@@ -0,0 +1,8 @@
+variable "peer_cidr_block" {
+  type = string
+
+  validation {
+    condition     = !provider::netcalc::cidroverlaps("10.0.0.0/16", var.peer_cidr_block)
+    error_message = "The peer network must not overlap the VPC CIDR block 10.0.0.0/16."
+  }
+}
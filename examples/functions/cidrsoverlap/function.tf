@@ -0,0 +1,8 @@
+variable "vpc_cidr_blocks" {
+  type = list(string)
+
+  validation {
+    condition     = !provider::netcalc::cidrsoverlap(var.vpc_cidr_blocks...)
+    error_message = "The VPC CIDR blocks must not overlap each other."
+  }
+}
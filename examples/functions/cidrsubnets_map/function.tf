@@ -0,0 +1,8 @@
+locals {
+  # The result is { app = "10.0.0.0/20", db = "10.0.16.0/24", web = "10.0.17.0/24" }.
+  subnets = provider::netcalc::cidrsubnets_map("10.0.0.0/16", {
+    app = 4
+    db  = 8
+    web = 8
+  })
+}
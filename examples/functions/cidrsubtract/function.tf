@@ -0,0 +1,4 @@
+# The result is ["10.0.1.0/24", "10.0.2.0/25", "10.0.3.0/24"].
+output "free_cidr_blocks" {
+  value = provider::netcalc::cidrsubtract("10.0.0.0/22", ["10.0.0.0/24", "10.0.2.128/25"])
+}
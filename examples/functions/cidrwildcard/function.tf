@@ -0,0 +1,4 @@
+# The result is "permit ip 10.0.1.0 0.0.0.255 any".
+output "acl_entry" {
+  value = "permit ip ${cidrhost("10.0.1.0/24", 0)} ${provider::netcalc::cidrwildcard("10.0.1.0/24")} any"
+}
@@ -0,0 +1,9 @@
+variable "api_address" {
+  type    = string
+  default = "2001:0DB8:0000:0000:0000:0000:0000:0001"
+}
+
+# True, as both addresses are "2001:db8::1" in canonical form.
+output "same_address" {
+  value = provider::netcalc::compress_ipv6(var.api_address) == provider::netcalc::compress_ipv6("2001:db8::1")
+}
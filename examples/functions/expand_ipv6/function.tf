@@ -0,0 +1,4 @@
+# The result is "2001:0db8:0000:0000:0000:0000:0000:0001".
+output "expanded" {
+  value = provider::netcalc::expand_ipv6("2001:db8::1")
+}
@@ -0,0 +1,8 @@
+variable "subnet_cidr_block" {
+  type = string
+
+  validation {
+    condition     = provider::netcalc::host_count(var.subnet_cidr_block, true) >= 100
+    error_message = "The subnet must provide at least 100 usable host addresses."
+  }
+}
@@ -0,0 +1,9 @@
+variable "dns_servers" {
+  type    = list(string)
+  default = ["10.0.0.2", "fd00::2"]
+}
+
+locals {
+  ipv4_dns_servers = [for s in var.dns_servers : s if provider::netcalc::ip_family(s) == "ipv4"]
+  ipv6_dns_servers = [for s in var.dns_servers : s if provider::netcalc::ip_family(s) == "ipv6"]
+}
@@ -0,0 +1,4 @@
+# The result is "10.0.0.0/26", as 50 hosts need a /26.
+output "subnet" {
+  value = cidrsubnet("10.0.0.0/16", provider::netcalc::mask_from_hosts(50, "ipv4") - 16, 0)
+}
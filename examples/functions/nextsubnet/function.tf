@@ -0,0 +1,8 @@
+locals {
+  used_cidr_blocks = ["10.0.0.0/24", "10.0.2.0/24"]
+}
+
+# The result is "10.0.1.0/24".
+output "next_subnet" {
+  value = provider::netcalc::nextsubnet("10.0.0.0/16", local.used_cidr_blocks, 24)
+}
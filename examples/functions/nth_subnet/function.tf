@@ -0,0 +1,4 @@
+# The result is "10.0.5.0/24".
+output "subnet" {
+  value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, 5)
+}
@@ -0,0 +1,4 @@
+# The result is "4.1.0.10.in-addr.arpa".
+output "ptr_name" {
+  value = provider::netcalc::reverse_ptr("10.0.1.4")
+}
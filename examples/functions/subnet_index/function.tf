@@ -0,0 +1,4 @@
+# The result is 5.
+output "index" {
+  value = provider::netcalc::subnet_index("10.0.0.0/16", "10.0.5.0/24")
+}
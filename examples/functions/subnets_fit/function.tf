@@ -0,0 +1,8 @@
+variable "vpc_cidr_block" {
+  type = string
+
+  validation {
+    condition     = provider::netcalc::subnets_fit(var.vpc_cidr_block, 24) >= 6
+    error_message = "The VPC must have room for six /24 subnets."
+  }
+}
@@ -0,0 +1,5 @@
+terraform import netcalc_allocation_ledger.example ./allocations.json
+
+# The ledger configured in the provider, such as ledger_s3, is imported by
+# the ID provider.
+terraform import netcalc_allocation_ledger.example provider
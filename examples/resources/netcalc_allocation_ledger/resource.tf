@@ -0,0 +1,22 @@
+# Record allocations in a ledger file that outlives this
+# Terraform state. Other configurations that point their
+# provider at the same file will not reuse these CIDR blocks.
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  ledger_path      = "${path.root}/allocations.json"
+}
+
+resource "netcalc_allocation_ledger" "example" {
+  path = "${path.root}/allocations.json"
+}
+
+# Depend on the ledger so it exists before allocations are
+# recorded in it.
+resource "netcalc_subnet" "example" {
+  cidr_mask_length = 24
+  depends_on       = [netcalc_allocation_ledger.example]
+}
+
+output "active_cidr_blocks" {
+  value = netcalc_allocation_ledger.example.active_cidr_blocks
+}
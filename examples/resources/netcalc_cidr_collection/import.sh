@@ -0,0 +1 @@
+terraform import netcalc_cidr_collection.example 10.0.0.0/24,10.0.2.0/24,10.0.1.0/24
\ No newline at end of file
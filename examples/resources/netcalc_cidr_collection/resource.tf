@@ -0,0 +1,18 @@
+resource "netcalc_subnet" "app" {
+  for_each         = toset(["web", "api", "worker"])
+  cidr_mask_length = 24
+}
+
+# Builds a firewall allow-list from the subnets. New subnets are
+# appended, so existing rules built from the list keep their
+# position.
+resource "netcalc_cidr_collection" "allow_list" {
+  cidr_blocks = [for s in netcalc_subnet.app : s.cidr_block]
+
+  # Members are only removed when listed here.
+  removed_cidr_blocks = ["10.0.9.0/24"]
+}
+
+output "allow_list" {
+  value = netcalc_cidr_collection.allow_list.members
+}
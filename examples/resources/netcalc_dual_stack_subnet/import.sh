@@ -0,0 +1 @@
+terraform import netcalc_dual_stack_subnet.example 10.0.0.0/24,fd18:fad4:bce5:4400::/64
\ No newline at end of file
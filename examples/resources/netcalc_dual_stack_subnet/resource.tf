@@ -0,0 +1,6 @@
+# Allocates a /24 IPv4 and a /64 IPv6 CIDR block together.
+# The provider's pools must contain both families.
+resource "netcalc_dual_stack_subnet" "example" {
+  ipv4_cidr_mask_length = 24
+  ipv6_cidr_mask_length = 64
+}
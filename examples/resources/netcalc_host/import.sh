@@ -0,0 +1 @@
+terraform import netcalc_host.example 10.0.1.0/24,1
\ No newline at end of file
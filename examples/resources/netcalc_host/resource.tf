@@ -0,0 +1,15 @@
+resource "netcalc_subnet" "example" {
+  cidr_mask_length = 24
+}
+
+# Reserves the first host address of the subnet for its gateway.
+resource "netcalc_host" "gateway" {
+  cidr_block = netcalc_subnet.example.cidr_block
+  host_index = 1
+}
+
+# Reserves the last usable host address for a DNS server.
+resource "netcalc_host" "dns" {
+  cidr_block = netcalc_subnet.example.cidr_block
+  host_index = -2
+}
@@ -0,0 +1 @@
+terraform import netcalc_ip_range.example 10.0.0.0/24,10.0.0.1-10.0.0.10
\ No newline at end of file
@@ -0,0 +1,19 @@
+resource "netcalc_subnet" "example" {
+  cidr_mask_length = 24
+}
+
+# Allocates the first 10 host addresses of the subnet, e.g. for
+# a DHCP exclusion range.
+resource "netcalc_ip_range" "example" {
+  cidr_block    = netcalc_subnet.example.cidr_block
+  address_count = 10
+}
+
+# Ranges in the same subnet do not overlap within an apply.
+# Ranges created in separate applies rely on their cidr_blocks
+# being reported in the provider's claimed_cidr_blocks.
+resource "netcalc_ip_range" "example_vips" {
+  cidr_block    = netcalc_subnet.example.cidr_block
+  address_count = 16
+  depends_on    = [netcalc_ip_range.example]
+}
@@ -0,0 +1 @@
+terraform import netcalc_pool.example 10.0.0.0/16,fd18:fad4:bce5:4400::/56,!10.0.0.0/24
\ No newline at end of file
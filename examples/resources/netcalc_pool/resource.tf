@@ -0,0 +1,38 @@
+# Pools can be declared in Terraform instead of the provider
+# configuration. Reserved CIDR blocks are never allocated.
+resource "netcalc_pool" "example" {
+  cidr_blocks          = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+  reserved_cidr_blocks = ["10.0.0.0/24"]
+  description          = "Shared services"
+
+  tags = {
+    environment = "production"
+  }
+}
+
+# Subnets allocate from a pool by referencing its ID, which can
+# also be passed between modules.
+resource "netcalc_subnet" "example" {
+  pool_id          = netcalc_pool.example.id
+  cidr_mask_length = 24
+}
+
+# Pools can be carved out of a parent pool. Here a /16 per
+# region is allocated from the corporate /8, and subnets are
+# then allocated from the regional pools.
+resource "netcalc_pool" "corporate" {
+  cidr_blocks = ["10.0.0.0/8"]
+}
+
+resource "netcalc_pool" "region" {
+  for_each = toset(["us-east-1", "eu-west-1"])
+
+  parent_pool_id   = netcalc_pool.corporate.id
+  cidr_mask_length = 16
+  description      = each.key
+}
+
+resource "netcalc_subnet" "us_east_1" {
+  pool_id          = netcalc_pool.region["us-east-1"].id
+  cidr_mask_length = 24
+}
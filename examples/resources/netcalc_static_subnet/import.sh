@@ -0,0 +1 @@
+terraform import netcalc_static_subnet.example 10.0.0.0/24
\ No newline at end of file
@@ -0,0 +1,13 @@
+# Registers a hand-assigned subnet, e.g. one created before
+# the network was managed by Terraform.
+resource "netcalc_static_subnet" "legacy" {
+  cidr_block = "10.0.0.0/24"
+}
+
+# Calculated subnets depend on the static subnet so it is
+# registered before they are allocated.
+resource "netcalc_subnet" "example" {
+  cidr_mask_length = 24
+
+  depends_on = [netcalc_static_subnet.legacy]
+}
@@ -16,3 +16,16 @@ resource "netcalc_subnet" "example_ipv6" {
   ip_family        = "ipv6"
   cidr_mask_length = 64
 }
+
+# Subnets can also be sized by the number of usable hosts they
+# need to hold. This allocates a /24 (254 usable IPv4 hosts).
+resource "netcalc_subnet" "example_hosts" {
+  min_hosts = 250
+}
+
+# Locked subnets cannot be destroyed or reallocated until they
+# are unlocked, protecting address space that is in production.
+resource "netcalc_subnet" "example_locked" {
+  cidr_mask_length = 24
+  locked           = true
+}
@@ -0,0 +1 @@
+terraform import netcalc_subnet_group.example us-east-1a=10.0.0.0/24,us-east-1b=10.0.1.0/24,us-east-1c=10.0.2.0/24
\ No newline at end of file
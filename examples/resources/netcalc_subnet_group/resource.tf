@@ -0,0 +1,10 @@
+# Allocates one /24 per availability zone. Adding or removing
+# a zone leaves the CIDR blocks of the other zones unchanged.
+resource "netcalc_subnet_group" "example" {
+  keys             = ["us-east-1a", "us-east-1b", "us-east-1c"]
+  cidr_mask_length = 24
+}
+
+output "subnets_by_az" {
+  value = netcalc_subnet_group.example.cidr_blocks
+}
@@ -0,0 +1 @@
+terraform import netcalc_subnet_pair.example a=10.0.0.0/24,b=10.0.64.0/24,c=10.0.128.0/24
\ No newline at end of file
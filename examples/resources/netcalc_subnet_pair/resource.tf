@@ -0,0 +1,15 @@
+resource "netcalc_pool" "az" {
+  for_each    = { a = "10.0.0.0/18", b = "10.0.64.0/18", c = "10.0.128.0/18" }
+  cidr_blocks = [each.value]
+}
+
+# Allocates one /24 in each availability zone's pool. The
+# subnets are created, replaced and destroyed together.
+resource "netcalc_subnet_pair" "example" {
+  pool_ids         = { for az, pool in netcalc_pool.az : az => pool.id }
+  cidr_mask_length = 24
+}
+
+output "subnets_by_az" {
+  value = netcalc_subnet_pair.example.cidr_blocks
+}
@@ -0,0 +1 @@
+terraform import netcalc_subnet_split.example 10.0.0.0/22,24
\ No newline at end of file
@@ -0,0 +1,6 @@
+# Splits a CIDR block allocated elsewhere, e.g. by AWS IPAM,
+# into four /24 networks.
+resource "netcalc_subnet_split" "example" {
+  cidr_block       = "10.0.0.0/22"
+  cidr_mask_length = 24
+}
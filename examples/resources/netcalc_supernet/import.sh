@@ -0,0 +1 @@
+terraform import netcalc_supernet.example 10.0.0.0/22
\ No newline at end of file
@@ -0,0 +1,15 @@
+resource "netcalc_subnet" "example" {
+  cidr_mask_length = 24
+}
+
+# Claims the smallest aggregate covering the subnet and a /24
+# that will be needed later. Other subnets are not allocated
+# from the claimed supernet.
+resource "netcalc_supernet" "example" {
+  cidr_blocks = [netcalc_subnet.example.cidr_block, "10.0.3.0/24"]
+}
+
+# Grow into the supernet by using it as a pool.
+resource "netcalc_pool" "example" {
+  cidr_blocks = [netcalc_supernet.example.cidr_block]
+}
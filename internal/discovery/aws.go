@@ -0,0 +1,146 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// EC2Client is the part of the EC2 API used to discover CIDR blocks.
+type EC2Client interface {
+	ec2.DescribeVpcsAPIClient
+	ec2.DescribeSubnetsAPIClient
+}
+
+// AWSOptions selects the CIDR blocks discovered in an AWS account.
+type AWSOptions struct {
+	// VPCs includes the IPv4 and IPv6 CIDR blocks associated with VPCs.
+	VPCs bool
+	// Subnets includes the IPv4 and IPv6 CIDR blocks of subnets.
+	Subnets bool
+}
+
+// AWS returns the CIDR blocks of the VPCs and subnets visible to the client,
+// which covers a single account and region. CIDR blocks that are being or
+// have been disassociated are left out.
+func AWS(ctx context.Context, client EC2Client, opts AWSOptions) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	add := func(cidr *string, state string) error {
+		if cidr == nil || state != "associated" && state != "associating" {
+			return nil
+		}
+		prefix, err := netip.ParsePrefix(*cidr)
+		if err != nil {
+			return fmt.Errorf("unable to parse CIDR block %q: %w", *cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+		return nil
+	}
+
+	if opts.VPCs {
+		pages := ec2.NewDescribeVpcsPaginator(client, &ec2.DescribeVpcsInput{})
+		for pages.HasMorePages() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to describe VPCs: %w", err)
+			}
+			for _, vpc := range page.Vpcs {
+				for _, a := range vpc.CidrBlockAssociationSet {
+					if err := add(a.CidrBlock, vpcCIDRState(a.CidrBlockState)); err != nil {
+						return nil, err
+					}
+				}
+				for _, a := range vpc.Ipv6CidrBlockAssociationSet {
+					if err := add(a.Ipv6CidrBlock, vpcCIDRState(a.Ipv6CidrBlockState)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	if opts.Subnets {
+		pages := ec2.NewDescribeSubnetsPaginator(client, &ec2.DescribeSubnetsInput{})
+		for pages.HasMorePages() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to describe subnets: %w", err)
+			}
+			for _, subnet := range page.Subnets {
+				if err := add(subnet.CidrBlock, "associated"); err != nil {
+					return nil, err
+				}
+				for _, a := range subnet.Ipv6CidrBlockAssociationSet {
+					var state string
+					if a.Ipv6CidrBlockState != nil {
+						state = string(a.Ipv6CidrBlockState.State)
+					}
+					if err := add(a.Ipv6CidrBlock, state); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return prefixes, nil
+}
+
+func vpcCIDRState(state *types.VpcCidrBlockState) string {
+	if state == nil {
+		return ""
+	}
+	return string(state.State)
+}
+
+// IPAMClient is the part of the EC2 API used to read an IPAM pool.
+type IPAMClient interface {
+	ec2.GetIpamPoolCidrsAPIClient
+	ec2.GetIpamPoolAllocationsAPIClient
+}
+
+// AWSIPAMPool returns the CIDR blocks provisioned to an IPAM pool, and the
+// CIDR blocks allocated from the pool, e.g. to VPCs or child pools. CIDR
+// blocks that are still being provisioned or are being deprovisioned are
+// not part of the pool.
+func AWSIPAMPool(ctx context.Context, client IPAMClient, poolID string) ([]netip.Prefix, []netip.Prefix, error) {
+	var cidrs, allocations []netip.Prefix
+	cidrPages := ec2.NewGetIpamPoolCidrsPaginator(client, &ec2.GetIpamPoolCidrsInput{IpamPoolId: &poolID})
+	for cidrPages.HasMorePages() {
+		page, err := cidrPages.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get CIDR blocks of IPAM pool %s: %w", poolID, err)
+		}
+		for _, c := range page.IpamPoolCidrs {
+			if c.Cidr == nil || c.State != types.IpamPoolCidrStateProvisioned {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(*c.Cidr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to parse CIDR block %q: %w", *c.Cidr, err)
+			}
+			cidrs = append(cidrs, prefix.Masked())
+		}
+	}
+
+	allocationPages := ec2.NewGetIpamPoolAllocationsPaginator(client, &ec2.GetIpamPoolAllocationsInput{IpamPoolId: &poolID})
+	for allocationPages.HasMorePages() {
+		page, err := allocationPages.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get allocations of IPAM pool %s: %w", poolID, err)
+		}
+		for _, a := range page.IpamPoolAllocations {
+			if a.Cidr == nil {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(*a.Cidr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to parse CIDR block %q: %w", *a.Cidr, err)
+			}
+			allocations = append(allocations, prefix.Masked())
+		}
+	}
+	return cidrs, allocations, nil
+}
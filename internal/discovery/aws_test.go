@@ -0,0 +1,151 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEC2 returns one VPC or subnet per page.
+type fakeEC2 struct {
+	vpcs    []types.Vpc
+	subnets []types.Subnet
+	err     error
+}
+
+func (f *fakeEC2) DescribeVpcs(ctx context.Context, in *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	i := pageIndex(in.NextToken)
+	out := &ec2.DescribeVpcsOutput{Vpcs: f.vpcs[i : i+1]}
+	if i+1 < len(f.vpcs) {
+		out.NextToken = aws.String(string(rune('0' + i + 1)))
+	}
+	return out, f.err
+}
+
+func (f *fakeEC2) DescribeSubnets(ctx context.Context, in *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	i := pageIndex(in.NextToken)
+	out := &ec2.DescribeSubnetsOutput{Subnets: f.subnets[i : i+1]}
+	if i+1 < len(f.subnets) {
+		out.NextToken = aws.String(string(rune('0' + i + 1)))
+	}
+	return out, f.err
+}
+
+func pageIndex(token *string) int {
+	if token == nil {
+		return 0
+	}
+	return int((*token)[0] - '0')
+}
+
+func TestAWS(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	client := &fakeEC2{
+		vpcs: []types.Vpc{
+			{
+				CidrBlockAssociationSet: []types.VpcCidrBlockAssociation{
+					{CidrBlock: aws.String("10.0.0.0/16"), CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeAssociated}},
+					{CidrBlock: aws.String("10.1.0.0/16"), CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeDisassociated}},
+				},
+				Ipv6CidrBlockAssociationSet: []types.VpcIpv6CidrBlockAssociation{
+					{Ipv6CidrBlock: aws.String("2600:1f18:1234:5600::/56"), Ipv6CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeAssociating}},
+				},
+			},
+			{
+				CidrBlockAssociationSet: []types.VpcCidrBlockAssociation{
+					{CidrBlock: aws.String("172.16.0.0/16"), CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeAssociated}},
+				},
+			},
+		},
+		subnets: []types.Subnet{
+			{
+				CidrBlock: aws.String("10.0.1.0/24"),
+				Ipv6CidrBlockAssociationSet: []types.SubnetIpv6CidrBlockAssociation{
+					{Ipv6CidrBlock: aws.String("2600:1f18:1234:5601::/64"), Ipv6CidrBlockState: &types.SubnetCidrBlockState{State: types.SubnetCidrBlockStateCodeAssociated}},
+				},
+			},
+			{
+				// IPv6 only subnets have no IPv4 CIDR block.
+				Ipv6CidrBlockAssociationSet: []types.SubnetIpv6CidrBlockAssociation{
+					{Ipv6CidrBlock: aws.String("2600:1f18:1234:5602::/64"), Ipv6CidrBlockState: &types.SubnetCidrBlockState{State: types.SubnetCidrBlockStateCodeDisassociating}},
+				},
+			},
+		},
+	}
+
+	prefixes, err := AWS(ctx, client, AWSOptions{VPCs: true, Subnets: true})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/16"),
+			netip.MustParsePrefix("2600:1f18:1234:5600::/56"),
+			netip.MustParsePrefix("172.16.0.0/16"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("2600:1f18:1234:5601::/64"),
+		}, prefixes)
+	}
+
+	prefixes, err = AWS(ctx, client, AWSOptions{Subnets: true})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("2600:1f18:1234:5601::/64"),
+		}, prefixes)
+	}
+
+	client.err = errors.New("UnauthorizedOperation")
+	_, err = AWS(ctx, client, AWSOptions{VPCs: true})
+	assert.ErrorContains(err, "unable to describe VPCs: UnauthorizedOperation")
+}
+
+// fakeIPAM returns a single page of pool CIDR blocks and allocations.
+type fakeIPAM struct {
+	cidrs       []types.IpamPoolCidr
+	allocations []types.IpamPoolAllocation
+	err         error
+}
+
+func (f *fakeIPAM) GetIpamPoolCidrs(ctx context.Context, in *ec2.GetIpamPoolCidrsInput, optFns ...func(*ec2.Options)) (*ec2.GetIpamPoolCidrsOutput, error) {
+	if aws.ToString(in.IpamPoolId) != "ipam-pool-1" {
+		return nil, errors.New("InvalidIpamPoolId.NotFound")
+	}
+	return &ec2.GetIpamPoolCidrsOutput{IpamPoolCidrs: f.cidrs}, f.err
+}
+
+func (f *fakeIPAM) GetIpamPoolAllocations(ctx context.Context, in *ec2.GetIpamPoolAllocationsInput, optFns ...func(*ec2.Options)) (*ec2.GetIpamPoolAllocationsOutput, error) {
+	return &ec2.GetIpamPoolAllocationsOutput{IpamPoolAllocations: f.allocations}, f.err
+}
+
+func TestAWSIPAMPool(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	client := &fakeIPAM{
+		cidrs: []types.IpamPoolCidr{
+			{Cidr: aws.String("10.0.0.0/16"), State: types.IpamPoolCidrStateProvisioned},
+			{Cidr: aws.String("10.1.0.0/16"), State: types.IpamPoolCidrStatePendingProvision},
+			{Cidr: aws.String("10.2.0.0/16"), State: types.IpamPoolCidrStateDeprovisioned},
+		},
+		allocations: []types.IpamPoolAllocation{
+			{Cidr: aws.String("10.0.0.0/20"), ResourceType: types.IpamPoolAllocationResourceTypeVpc},
+			{Cidr: aws.String("10.0.16.0/24"), ResourceType: types.IpamPoolAllocationResourceTypeCustom},
+		},
+	}
+
+	cidrs, allocations, err := AWSIPAMPool(ctx, client, "ipam-pool-1")
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}, cidrs)
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/20"),
+			netip.MustParsePrefix("10.0.16.0/24"),
+		}, allocations)
+	}
+
+	_, _, err = AWSIPAMPool(ctx, client, "ipam-pool-2")
+	assert.ErrorContains(err, "unable to get CIDR blocks of IPAM pool ipam-pool-2: InvalidIpamPoolId.NotFound")
+}
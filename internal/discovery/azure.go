@@ -0,0 +1,89 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+)
+
+// AzureOptions selects the CIDR blocks discovered in an Azure subscription.
+type AzureOptions struct {
+	// ResourceGroups limits discovery to virtual networks in these resource
+	// groups. Every resource group of the subscription is included if it is
+	// empty.
+	ResourceGroups []string
+	// VNets includes the address spaces of virtual networks.
+	VNets bool
+	// Subnets includes the address prefixes of subnets.
+	Subnets bool
+}
+
+// Azure returns the CIDR blocks of the virtual networks and subnets in the
+// subscription of the client.
+func Azure(ctx context.Context, client *armnetwork.VirtualNetworksClient, opts AzureOptions) ([]netip.Prefix, error) {
+	var vnets []*armnetwork.VirtualNetwork
+	if len(opts.ResourceGroups) == 0 {
+		pages := client.NewListAllPager(nil)
+		for pages.More() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to list virtual networks: %w", err)
+			}
+			vnets = append(vnets, page.Value...)
+		}
+	}
+	for _, resourceGroup := range opts.ResourceGroups {
+		pages := client.NewListPager(resourceGroup, nil)
+		for pages.More() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to list virtual networks in resource group %s: %w", resourceGroup, err)
+			}
+			vnets = append(vnets, page.Value...)
+		}
+	}
+
+	var prefixes []netip.Prefix
+	add := func(cidrs ...*string) error {
+		for _, cidr := range cidrs {
+			if cidr == nil {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(*cidr)
+			if err != nil {
+				return fmt.Errorf("unable to parse CIDR block %q: %w", *cidr, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		}
+		return nil
+	}
+	for _, vnet := range vnets {
+		if vnet.Properties == nil {
+			continue
+		}
+		if opts.VNets && vnet.Properties.AddressSpace != nil {
+			if err := add(vnet.Properties.AddressSpace.AddressPrefixes...); err != nil {
+				return nil, err
+			}
+		}
+		if !opts.Subnets {
+			continue
+		}
+		for _, subnet := range vnet.Properties.Subnets {
+			if subnet.Properties == nil {
+				continue
+			}
+			// Subnets have either a single address prefix or, when they
+			// have several, a list of them.
+			if err := add(subnet.Properties.AddressPrefix); err != nil {
+				return nil, err
+			}
+			if err := add(subnet.Properties.AddressPrefixes...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAzureCredential struct{}
+
+func (fakeAzureCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// newFakeAzureServer serves virtual networks of the subscription sub, with
+// the virtual networks of the subscription on two pages.
+func newFakeAzureServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/subscriptions/sub/providers/Microsoft.Network/virtualNetworks" && r.URL.Query().Get("page") == "":
+			fmt.Fprintf(w, `{"value":[{"name":"hub","properties":{"addressSpace":{"addressPrefixes":["10.0.0.0/16","fd00::/48"]},"subnets":[{"name":"default","properties":{"addressPrefix":"10.0.1.0/24"}}]}}],"nextLink":"%s%s?page=2&api-version=2023-11-01"}`, server.URL, r.URL.Path)
+		case r.URL.Path == "/subscriptions/sub/providers/Microsoft.Network/virtualNetworks":
+			fmt.Fprint(w, `{"value":[{"name":"spoke","properties":{"addressSpace":{"addressPrefixes":["10.1.0.0/16"]},"subnets":[{"name":"dual","properties":{"addressPrefixes":["10.1.0.0/24","fd00:0:0:1::/64"]}}]}}]}`)
+		case r.URL.Path == "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks":
+			fmt.Fprint(w, `{"value":[{"name":"hub","properties":{"addressSpace":{"addressPrefixes":["10.0.0.0/16"]},"subnets":[{"name":"default","properties":{"addressPrefix":"10.0.1.0/24"}}]}}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"ResourceGroupNotFound","message":"Resource group 'missing' could not be found."}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newFakeAzureClient(t *testing.T, server *httptest.Server) *armnetwork.VirtualNetworksClient {
+	client, err := armnetwork.NewVirtualNetworksClient("sub", fakeAzureCredential{}, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Endpoint: server.URL, Audience: "https://management.azure.com"},
+				},
+			},
+			Transport: server.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestAzure(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	client := newFakeAzureClient(t, newFakeAzureServer(t))
+
+	prefixes, err := Azure(ctx, client, AzureOptions{VNets: true, Subnets: true})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/16"),
+			netip.MustParsePrefix("fd00::/48"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("10.1.0.0/16"),
+			netip.MustParsePrefix("10.1.0.0/24"),
+			netip.MustParsePrefix("fd00:0:0:1::/64"),
+		}, prefixes)
+	}
+
+	prefixes, err = Azure(ctx, client, AzureOptions{ResourceGroups: []string{"network"}, Subnets: true})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}, prefixes)
+	}
+
+	_, err = Azure(ctx, client, AzureOptions{ResourceGroups: []string{"network", "missing"}, VNets: true})
+	assert.ErrorContains(err, "unable to list virtual networks in resource group missing")
+	assert.ErrorContains(err, "ResourceGroupNotFound")
+}
@@ -0,0 +1,4 @@
+// Package discovery finds the CIDR blocks already in use in cloud accounts,
+// so the provider can treat them as claimed without them being listed by
+// hand.
+package discovery
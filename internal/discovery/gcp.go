@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// GCPOptions selects the CIDR blocks discovered in a Google Cloud project.
+type GCPOptions struct {
+	// Project to discover subnetworks in.
+	Project string
+	// Regions limits discovery to subnetworks in these regions. Every region
+	// is included if it is empty.
+	Regions []string
+	// SecondaryRanges includes the secondary IP ranges of subnetworks, e.g.
+	// the ranges of GKE pods and services.
+	SecondaryRanges bool
+}
+
+// GCP returns the primary, secondary and IPv6 CIDR blocks of the subnetworks
+// in a project.
+func GCP(ctx context.Context, service *compute.Service, opts GCPOptions) ([]netip.Prefix, error) {
+	var subnetworks []*compute.Subnetwork
+	if len(opts.Regions) == 0 {
+		err := service.Subnetworks.AggregatedList(opts.Project).Context(ctx).Pages(ctx, func(page *compute.SubnetworkAggregatedList) error {
+			for _, scoped := range page.Items {
+				subnetworks = append(subnetworks, scoped.Subnetworks...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list subnetworks of project %s: %w", opts.Project, err)
+		}
+	}
+	for _, region := range opts.Regions {
+		err := service.Subnetworks.List(opts.Project, region).Context(ctx).Pages(ctx, func(page *compute.SubnetworkList) error {
+			subnetworks = append(subnetworks, page.Items...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list subnetworks of project %s in region %s: %w", opts.Project, region, err)
+		}
+	}
+
+	var prefixes []netip.Prefix
+	add := func(cidr string) error {
+		if cidr == "" {
+			return nil
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("unable to parse CIDR block %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+		return nil
+	}
+	for _, subnetwork := range subnetworks {
+		if err := add(subnetwork.IpCidrRange); err != nil {
+			return nil, err
+		}
+		if opts.SecondaryRanges {
+			for _, r := range subnetwork.SecondaryIpRanges {
+				if err := add(r.IpCidrRange); err != nil {
+					return nil, err
+				}
+			}
+		}
+		// Dual stack subnetworks have an internal or external IPv6 range.
+		if err := add(subnetwork.Ipv6CidrRange); err != nil {
+			return nil, err
+		}
+	}
+	return prefixes, nil
+}
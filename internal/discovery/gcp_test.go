@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// newFakeGCPService returns a compute service of a server with subnetworks
+// in the project netcalc, with the aggregated list on two pages.
+func newFakeGCPService(t *testing.T) *compute.Service {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/projects/netcalc/aggregated/subnetworks" && r.URL.Query().Get("pageToken") == "":
+			fmt.Fprint(w, `{"items":{"regions/us-central1":{"subnetworks":[{"name":"gke","ipCidrRange":"10.0.0.0/20","secondaryIpRanges":[{"rangeName":"pods","ipCidrRange":"10.4.0.0/14"}]}]},"regions/asia-east1":{"warning":{"code":"NO_RESULTS_ON_PAGE"}}},"nextPageToken":"2"}`)
+		case r.URL.Path == "/projects/netcalc/aggregated/subnetworks":
+			fmt.Fprint(w, `{"items":{"regions/europe-west1":{"subnetworks":[{"name":"dual","ipCidrRange":"10.1.0.0/24","ipv6CidrRange":"2600:1900:4000:1::/64"}]}}}`)
+		case r.URL.Path == "/projects/netcalc/regions/europe-west1/subnetworks":
+			fmt.Fprint(w, `{"items":[{"name":"dual","ipCidrRange":"10.1.0.0/24","ipv6CidrRange":"2600:1900:4000:1::/64"}]}`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"message":"Required 'compute.subnetworks.list' permission for 'projects/other'","errors":[{"reason":"forbidden"}]}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	service, err := compute.NewService(context.Background(), option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestGCP(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	service := newFakeGCPService(t)
+
+	prefixes, err := GCP(ctx, service, GCPOptions{Project: "netcalc", SecondaryRanges: true})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/20"),
+			netip.MustParsePrefix("10.4.0.0/14"),
+			netip.MustParsePrefix("10.1.0.0/24"),
+			netip.MustParsePrefix("2600:1900:4000:1::/64"),
+		}, prefixes)
+	}
+
+	prefixes, err = GCP(ctx, service, GCPOptions{Project: "netcalc"})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/20"),
+			netip.MustParsePrefix("10.1.0.0/24"),
+			netip.MustParsePrefix("2600:1900:4000:1::/64"),
+		}, prefixes)
+	}
+
+	prefixes, err = GCP(ctx, service, GCPOptions{Project: "netcalc", Regions: []string{"europe-west1"}})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.1.0.0/24"),
+			netip.MustParsePrefix("2600:1900:4000:1::/64"),
+		}, prefixes)
+	}
+
+	_, err = GCP(ctx, service, GCPOptions{Project: "other"})
+	assert.ErrorContains(err, "unable to list subnetworks of project other")
+	assert.ErrorContains(err, "Required 'compute.subnetworks.list' permission")
+}
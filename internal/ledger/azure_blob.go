@@ -0,0 +1,265 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// AzureBlobLockOptions configures a lock held as a lease on an Azure Storage
+// blob.
+type AzureBlobLockOptions struct {
+	// URL of the blob, e.g.
+	// https://account.blob.core.windows.net/netcalc/netcalc.lock. The blob
+	// is created empty if it does not exist, but the container must exist.
+	URL string
+	// SASToken authorizes requests with a shared access signature, if set.
+	SASToken string
+	// Credential authorizes requests with Microsoft Entra ID tokens when no
+	// SAS token is set.
+	Credential azcore.TokenCredential
+	// Timeout is how long to wait for a lock held by another process, one
+	// minute if zero.
+	Timeout time.Duration
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// azureBlobLock is a lock held by acquiring a lease on a blob. The lease
+// expires if the process dies, which releases the lock.
+type azureBlobLock struct {
+	opts AzureBlobLockOptions
+	blob azureBlob
+}
+
+// azureBlob sends requests for a blob to the Azure Storage REST API.
+type azureBlob struct {
+	url        string
+	sasToken   string
+	credential azcore.TokenCredential
+	httpClient *http.Client
+}
+
+// azureLeaseDuration is how long, in seconds, a lock outlives a process that
+// died while holding it.
+const azureLeaseDuration = "60"
+
+// azureStorageVersion is the version of the Azure Storage REST API used.
+const azureStorageVersion = "2021-12-02"
+
+// NewAzureBlobLock returns a lock held as a lease on an Azure Storage blob.
+func NewAzureBlobLock(opts AzureBlobLockOptions) Locker {
+	opts.SASToken = strings.TrimPrefix(opts.SASToken, "?")
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultLockTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &azureBlobLock{
+		opts: opts,
+		blob: azureBlob{url: opts.URL, sasToken: opts.SASToken, credential: opts.Credential, httpClient: opts.HTTPClient},
+	}
+}
+
+func (l *azureBlobLock) Lock(ctx context.Context) (func(context.Context) error, error) {
+	var leaseID string
+	err := waitForLock(ctx, l.opts.Timeout, func() (bool, error) {
+		status, header, err := l.blob.do(ctx, http.MethodPut, "comp=lease", map[string]string{
+			"x-ms-lease-action":   "acquire",
+			"x-ms-lease-duration": azureLeaseDuration,
+		}, http.StatusCreated, http.StatusConflict, http.StatusNotFound)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case status == http.StatusCreated:
+			leaseID = header.Get("x-ms-lease-id")
+			return true, nil
+		case status == http.StatusNotFound && header.Get("x-ms-error-code") == "BlobNotFound":
+			// Create the blob, unless another process just did, and try
+			// again.
+			_, _, err := l.blob.do(ctx, http.MethodPut, "", map[string]string{
+				"x-ms-blob-type": "BlockBlob",
+				"If-None-Match":  "*",
+			}, http.StatusCreated, http.StatusConflict)
+			return false, err
+		case status == http.StatusConflict && header.Get("x-ms-error-code") == "LeaseAlreadyPresent":
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to acquire lease: %s", header.Get("x-ms-error-code"))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lease on blob %s: %w", l.opts.URL, err)
+	}
+	return func(ctx context.Context) error {
+		_, _, err := l.blob.do(ctx, http.MethodPut, "comp=lease", map[string]string{
+			"x-ms-lease-action": "release",
+			"x-ms-lease-id":     leaseID,
+		}, http.StatusOK)
+		return err
+	}, nil
+}
+
+// do sends a request for the blob to the Azure Storage REST API, and fails
+// unless the response has one of the expected statuses.
+func (b azureBlob) do(ctx context.Context, method string, query string, headers map[string]string, expected ...int) (int, http.Header, error) {
+	status, header, _, err := b.send(ctx, method, query, headers, nil, expected...)
+	return status, header, err
+}
+
+// send is like do, with a request body, and also returns the response body.
+func (b azureBlob) send(ctx context.Context, method string, query string, headers map[string]string, body []byte, expected ...int) (int, http.Header, []byte, error) {
+	target := b.url
+	for _, q := range []string{query, b.sasToken} {
+		if q == "" {
+			continue
+		}
+		if strings.Contains(target, "?") {
+			target += "&" + q
+		} else {
+			target += "?" + q
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if b.sasToken == "" && b.credential != nil {
+		token, err := b.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://storage.azure.com/.default"}})
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("unable to get an Azure Storage token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, resp.Header, nil, err
+	}
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return resp.StatusCode, resp.Header, respBody, nil
+		}
+	}
+	// The URL is not part of the error, as it may contain a SAS token.
+	return resp.StatusCode, resp.Header, respBody, fmt.Errorf("%s %s: %s: %s", method, query, resp.Status, strings.TrimSpace(string(respBody)))
+}
+
+// AzureBlobOptions configures a ledger stored in an Azure Storage blob.
+type AzureBlobOptions struct {
+	// URL of the ledger blob, e.g.
+	// https://account.blob.core.windows.net/netcalc/ledger.json. The blob
+	// is created if it does not exist, but the container must exist. The
+	// lock is a lease on the blob with the same URL and a .lock suffix.
+	URL string
+	// SASToken authorizes requests with a shared access signature, if set.
+	SASToken string
+	// Credential authorizes requests with Microsoft Entra ID tokens when no
+	// SAS token is set.
+	Credential azcore.TokenCredential
+	// LockTimeout is how long to wait for a lock held by another process,
+	// one minute if zero.
+	LockTimeout time.Duration
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// azureBlobStore stores the ledger document in a block blob, which is
+// written on the condition that its ETag did not change since it was read.
+type azureBlobStore struct {
+	blob azureBlob
+}
+
+// NewAzureBlobLedger returns a ledger stored in an Azure Storage blob, which
+// is created on the first write if it does not exist. Updates hold a lease
+// on a second blob, which expires if the process dies, so concurrent
+// Terraform runs sharing the ledger take turns.
+func NewAzureBlobLedger(opts AzureBlobOptions) *RemoteLedger {
+	opts.SASToken = strings.TrimPrefix(opts.SASToken, "?")
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &RemoteLedger{
+		// The URL is not part of the name, as it may contain a SAS token.
+		name: fmt.Sprintf("Azure blob %s", azureBlobName(opts.URL)),
+		store: &azureBlobStore{
+			blob: azureBlob{url: opts.URL, sasToken: opts.SASToken, credential: opts.Credential, httpClient: opts.HTTPClient},
+		},
+		locker: NewAzureBlobLock(AzureBlobLockOptions{
+			URL:        opts.URL + ".lock",
+			SASToken:   opts.SASToken,
+			Credential: opts.Credential,
+			Timeout:    opts.LockTimeout,
+			HTTPClient: opts.HTTPClient,
+		}),
+		now: time.Now,
+	}
+}
+
+func (s *azureBlobStore) load(ctx context.Context) (document, string, error) {
+	status, header, body, err := s.blob.send(ctx, http.MethodGet, "", nil, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return document{}, "", err
+	}
+	if status == http.StatusNotFound {
+		if header.Get("x-ms-error-code") != "BlobNotFound" {
+			return document{}, "", fmt.Errorf("unable to read blob: %s", header.Get("x-ms-error-code"))
+		}
+		return document{}, "", nil
+	}
+	doc, err := parseDocument(body, fmt.Sprintf("Azure blob %s", azureBlobName(s.blob.url)))
+	return doc, header.Get("ETag"), err
+}
+
+func (s *azureBlobStore) save(ctx context.Context, doc document, version string) error {
+	data, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{
+		"x-ms-blob-type":         "BlockBlob",
+		"x-ms-blob-content-type": "application/json",
+	}
+	if version == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = version
+	}
+	status, _, _, err := s.blob.send(ctx, http.MethodPut, "", headers, data, http.StatusCreated, http.StatusConflict, http.StatusPreconditionFailed)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusCreated {
+		// The blob was written since it was read, e.g. by a process not
+		// holding the lock.
+		return errConflict
+	}
+	return nil
+}
+
+// azureBlobName returns the container and name of the blob with the URL.
+func azureBlobName(blobURL string) string {
+	u, err := url.Parse(blobURL)
+	if err != nil {
+		return blobURL
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
@@ -0,0 +1,158 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzureBlob serves the blob and lease requests used by Azure blob locks
+// and ledgers.
+type fakeAzureBlob struct {
+	m        sync.Mutex
+	blobs    map[string]bool
+	contents map[string][]byte
+	etags    map[string]string
+	leases   map[string]string
+	count    int
+}
+
+func newFakeAzureBlob(t *testing.T) (*fakeAzureBlob, *httptest.Server) {
+	f := &fakeAzureBlob{blobs: map[string]bool{}, contents: map[string][]byte{}, etags: map[string]string{}, leases: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeAzureBlob) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.URL.Query().Get("sig") != "secret" {
+		w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		http.Error(w, "Server failed to authenticate the request.", http.StatusForbidden)
+		return
+	}
+	blob := r.URL.Path
+	if r.Method == http.MethodGet {
+		if !f.blobs[blob] {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", f.etags[blob])
+		_, _ = w.Write(f.contents[blob])
+		return
+	}
+	if r.URL.Query().Get("comp") != "lease" {
+		if f.blobs[blob] && r.Header.Get("If-None-Match") == "*" {
+			w.Header().Set("x-ms-error-code", "BlobAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != f.etags[blob] {
+			w.Header().Set("x-ms-error-code", "ConditionNotMet")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		f.count++
+		f.blobs[blob] = true
+		f.contents[blob], _ = io.ReadAll(r.Body)
+		f.etags[blob] = fmt.Sprintf(`"0x%d"`, f.count)
+		w.Header().Set("ETag", f.etags[blob])
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if !f.blobs[blob] {
+		w.Header().Set("x-ms-error-code", "BlobNotFound")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Header.Get("x-ms-lease-action") {
+	case "acquire":
+		if _, ok := f.leases[blob]; ok {
+			w.Header().Set("x-ms-error-code", "LeaseAlreadyPresent")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.count++
+		f.leases[blob] = fmt.Sprintf("lease-%d", f.count)
+		w.Header().Set("x-ms-lease-id", f.leases[blob])
+		w.WriteHeader(http.StatusCreated)
+	case "release":
+		if f.leases[blob] != r.Header.Get("x-ms-lease-id") {
+			w.Header().Set("x-ms-error-code", "LeaseIdMismatchWithLeaseOperation")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		delete(f.leases, blob)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAzureBlobLock(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeAzureBlob(t)
+	opts := AzureBlobLockOptions{URL: server.URL + "/netcalc/apply.lock", SASToken: "?sv=2021-12-02&sig=secret", Timeout: 200 * time.Millisecond}
+
+	// The blob is created by the first lock.
+	unlock, err := NewAzureBlobLock(opts).Lock(ctx)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.True(fake.blobs["/netcalc/apply.lock"])
+	assert.Contains(fake.leases, "/netcalc/apply.lock")
+
+	// The lock is held until it is released.
+	_, err = NewAzureBlobLock(opts).Lock(ctx)
+	assert.ErrorContains(err, "still held")
+	assert.NoError(unlock(ctx))
+	assert.Empty(fake.leases)
+
+	unlock, err = NewAzureBlobLock(opts).Lock(ctx)
+	if assert.NoError(err) {
+		assert.NoError(unlock(ctx))
+	}
+
+	// Requests are rejected with a useful error.
+	opts.SASToken = "sig=wrong"
+	_, err = NewAzureBlobLock(opts).Lock(ctx)
+	assert.ErrorContains(err, "failed to authenticate")
+}
+
+func TestAzureBlobLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeAzureBlob(t)
+	opts := AzureBlobOptions{URL: server.URL + "/netcalc/ledger.json", SASToken: "?sv=2021-12-02&sig=secret", LockTimeout: time.Second}
+	l := NewAzureBlobLedger(opts)
+	assert.Equal("Azure blob netcalc/ledger.json", l.String())
+
+	testLedger(t, l, func() Ledger { return NewAzureBlobLedger(opts) })
+	assert.Contains(string(fake.contents["/netcalc/ledger.json"]), `"released_at"`)
+	// The lock is a lease on a separate blob, which is released after
+	// every update.
+	assert.True(fake.blobs["/netcalc/ledger.json.lock"])
+	assert.Empty(fake.leases)
+
+	// A write not holding the lock is detected by the ETag, and retried.
+	store := l.store.(*azureBlobStore)
+	doc, version, err := store.load(ctx)
+	if assert.NoError(err) {
+		assert.NoError(store.save(ctx, doc, version))
+		assert.ErrorIs(store.save(ctx, doc, version), errConflict)
+	}
+
+	// Requests are rejected with a useful error.
+	opts.SASToken = "sig=wrong"
+	_, err = NewAzureBlobLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "failed to authenticate")
+}
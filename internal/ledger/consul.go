@@ -0,0 +1,196 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsulOptions configures a ledger stored in Consul KV, or a Consul lock.
+type ConsulOptions struct {
+	// Address of the Consul agent, e.g. 127.0.0.1:8500 or
+	// https://consul.example.com. The scheme defaults to http.
+	Address string
+	// Token is the ACL token, if any.
+	Token string
+	// Datacenter defaults to the datacenter of the agent.
+	Datacenter string
+	// Key of the ledger document, e.g. netcalc/ledger. The lock of a ledger
+	// is held on the key with a .lock suffix. For NewConsulLock, the key of
+	// the lock itself.
+	Key string
+	// LockTimeout is how long to wait for a lock held by another process,
+	// one minute if zero.
+	LockTimeout time.Duration
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// consulStore stores the ledger document in a Consul key, which is written
+// with check-and-set.
+type consulStore struct {
+	opts ConsulOptions
+}
+
+// consulLock is a lock held by acquiring a Consul key with a session. The
+// session expires if the process dies, which releases the lock.
+type consulLock struct {
+	store *consulStore
+	// owner identifies the process holding the lock.
+	owner string
+}
+
+// consulSessionTTL is how long a lock outlives a process that died while
+// holding it.
+const consulSessionTTL = "60s"
+
+// NewConsulLedger returns a ledger stored in a Consul key, which is created
+// on the first write if it does not exist. Updates hold a lock acquired with
+// a Consul session, so concurrent Terraform runs sharing the ledger take
+// turns.
+func NewConsulLedger(opts ConsulOptions) *RemoteLedger {
+	store := newConsulStore(opts)
+	lockOpts := store.opts
+	lockOpts.Key += ".lock"
+	return &RemoteLedger{
+		name:   fmt.Sprintf("Consul key %s", store.opts.Key),
+		store:  store,
+		locker: NewConsulLock(lockOpts),
+		now:    time.Now,
+	}
+}
+
+// NewConsulLock returns a lock held on the Consul key opts.Key with a
+// session.
+func NewConsulLock(opts ConsulOptions) Locker {
+	hostname, _ := os.Hostname()
+	return &consulLock{
+		store: newConsulStore(opts),
+		owner: fmt.Sprintf("netcalc %s/%d", hostname, os.Getpid()),
+	}
+}
+
+// newConsulStore returns a store for the Consul key opts.Key, filling in the
+// defaults of the options.
+func newConsulStore(opts ConsulOptions) *consulStore {
+	if !strings.Contains(opts.Address, "://") {
+		opts.Address = "http://" + opts.Address
+	}
+	opts.Address = strings.TrimSuffix(opts.Address, "/")
+	opts.Key = strings.Trim(opts.Key, "/")
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = defaultLockTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &consulStore{opts: opts}
+}
+
+func (s *consulStore) load(ctx context.Context) (document, string, error) {
+	var pairs []struct {
+		Value       []byte
+		ModifyIndex uint64
+	}
+	status, err := s.do(ctx, http.MethodGet, "/v1/kv/"+s.opts.Key, nil, nil, &pairs)
+	if err != nil {
+		return document{}, "", err
+	}
+	if status == http.StatusNotFound || len(pairs) == 0 {
+		// A check-and-set with index 0 only writes a key that does not exist.
+		return document{}, "0", nil
+	}
+	doc, err := parseDocument(pairs[0].Value, "Consul key "+s.opts.Key)
+	return doc, strconv.FormatUint(pairs[0].ModifyIndex, 10), err
+}
+
+func (s *consulStore) save(ctx context.Context, doc document, version string) error {
+	b, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if _, err := s.do(ctx, http.MethodPut, "/v1/kv/"+s.opts.Key, url.Values{"cas": {version}}, b, &ok); err != nil {
+		return err
+	}
+	if !ok {
+		return errConflict
+	}
+	return nil
+}
+
+func (l *consulLock) Lock(ctx context.Context) (func(context.Context) error, error) {
+	var session struct {
+		ID string
+	}
+	sessionRequest, _ := json.Marshal(map[string]string{
+		"Name":      l.owner,
+		"TTL":       consulSessionTTL,
+		"Behavior":  "delete",
+		"LockDelay": "0s",
+	})
+	if _, err := l.store.do(ctx, http.MethodPut, "/v1/session/create", nil, sessionRequest, &session); err != nil {
+		return nil, fmt.Errorf("unable to create Consul session: %w", err)
+	}
+	destroy := func(ctx context.Context) error {
+		_, err := l.store.do(ctx, http.MethodPut, "/v1/session/destroy/"+session.ID, nil, nil, nil)
+		return err
+	}
+
+	err := waitForLock(ctx, l.store.opts.LockTimeout, func() (bool, error) {
+		var acquired bool
+		_, err := l.store.do(ctx, http.MethodPut, "/v1/kv/"+l.store.opts.Key, url.Values{"acquire": {session.ID}}, []byte(l.owner), &acquired)
+		return acquired, err
+	})
+	if err != nil {
+		_ = destroy(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("Consul key %s: %w", l.store.opts.Key, err)
+	}
+	// Destroying the session releases the lock and deletes the lock key.
+	return destroy, nil
+}
+
+// do sends a request to the Consul HTTP API and decodes the JSON response
+// into out. A missing key is not an error, but reported by the status.
+func (s *consulStore) do(ctx context.Context, method string, path string, query url.Values, body []byte, out any) (int, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if s.opts.Datacenter != "" {
+		query.Set("dc", s.opts.Datacenter)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.opts.Address+path+"?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if s.opts.Token != "" {
+		req.Header.Set("X-Consul-Token", s.opts.Token)
+	}
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.Unmarshal(b, out)
+}
@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul serves the KV and session endpoints used by the Consul ledger.
+type fakeConsul struct {
+	m        sync.Mutex
+	index    uint64
+	values   map[string][]byte
+	indexes  map[string]uint64
+	holders  map[string]string
+	sessions int
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *httptest.Server) {
+	f := &fakeConsul{values: map[string][]byte{}, indexes: map[string]uint64{}, holders: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	switch {
+	case r.URL.Path == "/v1/session/create":
+		f.sessions++
+		fmt.Fprintf(w, `{"ID":"session-%d"}`, f.sessions)
+	case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+		session := strings.TrimPrefix(r.URL.Path, "/v1/session/destroy/")
+		for key, holder := range f.holders {
+			if holder == session {
+				// Sessions with the delete behavior delete the keys they hold.
+				delete(f.holders, key)
+				delete(f.values, key)
+			}
+		}
+		fmt.Fprint(w, "true")
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/"):
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		if r.Method == http.MethodGet {
+			value, ok := f.values[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"Key": key, "Value": value, "ModifyIndex": f.indexes[key]}})
+			return
+		}
+		if session := query.Get("acquire"); session != "" {
+			if holder, ok := f.holders[key]; ok && holder != session {
+				fmt.Fprint(w, "false")
+				return
+			}
+			f.holders[key] = session
+		}
+		if cas := query.Get("cas"); cas != "" {
+			if index, _ := strconv.ParseUint(cas, 10, 64); index != f.indexes[key] {
+				fmt.Fprint(w, "false")
+				return
+			}
+		}
+		f.index++
+		f.values[key] = body
+		f.indexes[key] = f.index
+		fmt.Fprint(w, "true")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestConsulLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeConsul(t)
+	opts := ConsulOptions{Address: strings.TrimPrefix(server.URL, "http://"), Key: "/netcalc/ledger", LockTimeout: 10 * time.Second}
+	l := NewConsulLedger(opts)
+	assert.Equal("Consul key netcalc/ledger", l.String())
+
+	testLedger(t, l, func() Ledger { return NewConsulLedger(opts) })
+	assert.Empty(fake.holders)
+	assert.NotContains(fake.values, "netcalc/ledger.lock")
+
+	// A lock held by another process times out.
+	fake.holders["netcalc/ledger.lock"] = "other"
+	impatient := opts
+	impatient.LockTimeout = 200 * time.Millisecond
+	assert.ErrorContains(NewConsulLedger(impatient).Allocate(ctx, netip.MustParsePrefix("10.2.0.0/24"), "b"), "still held")
+	delete(fake.holders, "netcalc/ledger.lock")
+
+	// Requests are rejected with a useful error.
+	opts.Token = "wrong"
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") == "wrong" {
+			http.Error(w, "ACL not found", http.StatusForbidden)
+		}
+	})
+	_, err := NewConsulLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "ACL not found")
+}
+
+func TestConsulLock(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeConsul(t)
+	opts := ConsulOptions{Address: server.URL, Key: "netcalc/apply.lock", LockTimeout: 200 * time.Millisecond}
+
+	unlock, err := NewConsulLock(opts).Lock(ctx)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(fake.holders, "netcalc/apply.lock")
+
+	// The lock is held until it is released.
+	_, err = NewConsulLock(opts).Lock(ctx)
+	assert.ErrorContains(err, "still held")
+	assert.NoError(unlock(ctx))
+	assert.Empty(fake.holders)
+
+	unlock, err = NewConsulLock(opts).Lock(ctx)
+	if assert.NoError(err) {
+		assert.NoError(unlock(ctx))
+	}
+}
@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// document is the JSON form in which ledgers store their entries, shared by
+// the ledger file and the remote ledgers.
+type document struct {
+	Entries []Entry `json:"entries"`
+}
+
+// parseDocument parses a ledger document read from the named location. An
+// empty document is an empty ledger.
+func parseDocument(b []byte, name string) (document, error) {
+	var doc document
+	if len(b) == 0 {
+		return doc, nil
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return doc, fmt.Errorf("unable to parse ledger %s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// marshal returns the document in the indented form in which it is stored.
+func (d document) marshal() ([]byte, error) {
+	if d.Entries == nil {
+		d.Entries = []Entry{}
+	}
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// allocate records that owner holds the prefix, and reports whether the
+// document changed. Allocating a prefix the owner already holds is a no-op,
+// and allocations containing or being one of nested do not conflict with it.
+func (d *document) allocate(prefix netip.Prefix, owner string, now time.Time, nested []netip.Prefix) (bool, error) {
+	for _, e := range d.Entries {
+		if e.Active() && e.CIDR.Overlaps(prefix) {
+			if e.CIDR == prefix && e.Owner == owner {
+				return false, nil
+			}
+			if e.CIDR != prefix && containsAny(e.CIDR, nested) {
+				continue
+			}
+			return false, fmt.Errorf("%s overlaps %s, which is allocated to %q", prefix, e.CIDR, e.Owner)
+		}
+	}
+	d.Entries = append(d.Entries, Entry{
+		CIDR:        prefix,
+		Owner:       owner,
+		AllocatedAt: now.UTC(),
+	})
+	return true, nil
+}
+
+// containsAny reports whether a prefix contains or is any of the prefixes.
+func containsAny(prefix netip.Prefix, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if prefix.Bits() <= p.Bits() && prefix.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// release records that owner no longer holds the prefix, and reports whether
+// the document changed.
+func (d *document) release(prefix netip.Prefix, owner string, now time.Time) bool {
+	for i, e := range d.Entries {
+		if e.Active() && e.CIDR == prefix && e.Owner == owner {
+			releasedAt := now.UTC()
+			d.Entries[i].ReleasedAt = &releasedAt
+			return true
+		}
+	}
+	// Nothing to release, e.g. the allocation was made before the ledger
+	// was configured.
+	return false
+}
@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBClient is the part of the DynamoDB API used by DynamoDB locks.
+type DynamoDBClient interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBLockOptions configures a lock held as a DynamoDB item.
+type DynamoDBLockOptions struct {
+	// Table holding the lock. Its partition key must be the string attribute
+	// LockID, as for Terraform's S3 backend, so the same table can be used
+	// for both.
+	Table string
+	// LockID is the partition key of the lock item.
+	LockID string
+	// Timeout is how long to wait for a lock held by another process, one
+	// minute if zero.
+	Timeout time.Duration
+}
+
+// dynamoDBLock is a lock held by creating a DynamoDB item with a conditional
+// write, and released by deleting it.
+type dynamoDBLock struct {
+	client DynamoDBClient
+	opts   DynamoDBLockOptions
+	// owner identifies the process holding the lock.
+	owner string
+}
+
+// NewDynamoDBLock returns a lock held as an item in a DynamoDB table.
+func NewDynamoDBLock(client DynamoDBClient, opts DynamoDBLockOptions) Locker {
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultLockTimeout
+	}
+	hostname, _ := os.Hostname()
+	return &dynamoDBLock{
+		client: client,
+		opts:   opts,
+		owner:  fmt.Sprintf("netcalc %s/%d %d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+func (l *dynamoDBLock) Lock(ctx context.Context) (func(context.Context) error, error) {
+	err := waitForLock(ctx, l.opts.Timeout, func() (bool, error) {
+		_, err := l.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(l.opts.Table),
+			Item: map[string]dynamodbtypes.AttributeValue{
+				"LockID": &dynamodbtypes.AttributeValueMemberS{Value: l.opts.LockID},
+				"Info":   &dynamodbtypes.AttributeValueMemberS{Value: l.owner},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(LockID)"),
+		})
+		var conditionFailed *dynamodbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lock item %q in DynamoDB table %s: %w", l.opts.LockID, l.opts.Table, err)
+	}
+	return l.unlock, nil
+}
+
+// unlock deletes the lock item, unless another process took it over.
+func (l *dynamoDBLock) unlock(ctx context.Context) error {
+	_, err := l.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(l.opts.Table),
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"LockID": &dynamodbtypes.AttributeValueMemberS{Value: l.opts.LockID},
+		},
+		ConditionExpression: aws.String("Info = :info"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":info": &dynamodbtypes.AttributeValueMemberS{Value: l.owner},
+		},
+	})
+	return err
+}
@@ -0,0 +1,33 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDBLock(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake := newFakeAWS()
+	opts := DynamoDBLockOptions{Table: "locks", LockID: "netcalc", Timeout: 200 * time.Millisecond}
+
+	unlock, err := NewDynamoDBLock(fake, opts).Lock(ctx)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(fake.locks, "netcalc")
+
+	// The lock is held until it is released.
+	_, err = NewDynamoDBLock(fake, opts).Lock(ctx)
+	assert.ErrorContains(err, "still held")
+	assert.NoError(unlock(ctx))
+	assert.Empty(fake.locks)
+
+	unlock, err = NewDynamoDBLock(fake, opts).Lock(ctx)
+	if assert.NoError(err) {
+		assert.NoError(unlock(ctx))
+	}
+}
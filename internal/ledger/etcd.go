@@ -0,0 +1,294 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdOptions configures a ledger stored in etcd.
+type EtcdOptions struct {
+	// Endpoints of the etcd cluster, e.g. 127.0.0.1:2379 or
+	// https://etcd.example.com:2379, which are tried in order. The scheme
+	// defaults to http.
+	Endpoints []string
+	// Username and Password authenticate with etcd, if authentication is
+	// enabled.
+	Username string
+	Password string
+	// Key of the ledger document, e.g. /netcalc/ledger. The lock is held on
+	// the key with a .lock suffix.
+	Key string
+	// LockTimeout is how long to wait for a lock held by another process,
+	// one minute if zero.
+	LockTimeout time.Duration
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// etcdStore stores the ledger document in an etcd key through the JSON
+// gateway of the etcd v3 API. The document is written in a transaction that
+// compares its revision, and locked by creating a second key attached to a
+// lease. The lease expires if the process dies, which releases the lock.
+type etcdStore struct {
+	opts EtcdOptions
+	// lockOwner identifies the process holding the lock.
+	lockOwner string
+	// tokenMutex guards token, which authenticates requests once obtained
+	// with the username and password.
+	tokenMutex sync.Mutex
+	token      string
+}
+
+// etcdLeaseTTL is how many seconds a lock outlives a process that died while
+// holding it.
+const etcdLeaseTTL = 60
+
+// NewEtcdLedger returns a ledger stored in an etcd key, which is created on
+// the first write if it does not exist. Updates hold a lock attached to an
+// etcd lease, so concurrent Terraform runs sharing the ledger take turns.
+func NewEtcdLedger(opts EtcdOptions) *RemoteLedger {
+	endpoints := make([]string, 0, len(opts.Endpoints))
+	for _, endpoint := range opts.Endpoints {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		endpoints = append(endpoints, strings.TrimSuffix(endpoint, "/"))
+	}
+	opts.Endpoints = endpoints
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = defaultLockTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	hostname, _ := os.Hostname()
+	store := &etcdStore{
+		opts:      opts,
+		lockOwner: fmt.Sprintf("netcalc %s/%d", hostname, os.Getpid()),
+	}
+	return &RemoteLedger{
+		name:   fmt.Sprintf("etcd key %s", opts.Key),
+		store:  store,
+		locker: store,
+		now:    time.Now,
+	}
+}
+
+// etcdInt decodes the 64-bit integers of the JSON gateway, which are encoded
+// as strings.
+type etcdInt int64
+
+func (i *etcdInt) UnmarshalJSON(b []byte) error {
+	n, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	*i = etcdInt(n)
+	return err
+}
+
+func (i etcdInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+type etcdCompare struct {
+	Key            []byte   `json:"key"`
+	Target         string   `json:"target"`
+	Result         string   `json:"result"`
+	ModRevision    *etcdInt `json:"mod_revision,omitempty"`
+	CreateRevision *etcdInt `json:"create_revision,omitempty"`
+}
+
+type etcdPut struct {
+	Key   []byte  `json:"key"`
+	Value []byte  `json:"value"`
+	Lease etcdInt `json:"lease,omitempty"`
+}
+
+type etcdRequestOp struct {
+	RequestPut etcdPut `json:"request_put"`
+}
+
+type etcdTxn struct {
+	Compare []etcdCompare   `json:"compare"`
+	Success []etcdRequestOp `json:"success"`
+}
+
+func (s *etcdStore) load(ctx context.Context) (document, string, error) {
+	var resp struct {
+		Kvs []struct {
+			Value       []byte
+			ModRevision etcdInt `json:"mod_revision"`
+		}
+	}
+	if err := s.do(ctx, "/v3/kv/range", map[string][]byte{"key": []byte(s.opts.Key)}, &resp); err != nil {
+		return document{}, "", err
+	}
+	if len(resp.Kvs) == 0 {
+		// A key that does not exist compares as revision 0.
+		return document{}, "0", nil
+	}
+	doc, err := parseDocument(resp.Kvs[0].Value, "etcd key "+s.opts.Key)
+	return doc, strconv.FormatInt(int64(resp.Kvs[0].ModRevision), 10), err
+}
+
+func (s *etcdStore) save(ctx context.Context, doc document, version string) error {
+	b, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	revision, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return err
+	}
+	succeeded, err := s.txn(ctx, etcdTxn{
+		Compare: []etcdCompare{{Key: []byte(s.opts.Key), Target: "MOD", Result: "EQUAL", ModRevision: (*etcdInt)(&revision)}},
+		Success: []etcdRequestOp{{RequestPut: etcdPut{Key: []byte(s.opts.Key), Value: b}}},
+	})
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		return errConflict
+	}
+	return nil
+}
+
+func (s *etcdStore) Lock(ctx context.Context) (func(context.Context) error, error) {
+	var lease struct {
+		ID etcdInt
+	}
+	if err := s.do(ctx, "/v3/lease/grant", map[string]etcdInt{"TTL": etcdLeaseTTL}, &lease); err != nil {
+		return nil, fmt.Errorf("unable to grant etcd lease: %w", err)
+	}
+	revoke := func(ctx context.Context) error {
+		return s.do(ctx, "/v3/lease/revoke", map[string]etcdInt{"ID": lease.ID}, nil)
+	}
+
+	err := waitForLock(ctx, s.opts.LockTimeout, func() (bool, error) {
+		return s.txn(ctx, etcdTxn{
+			Compare: []etcdCompare{{Key: []byte(s.lockKey()), Target: "CREATE", Result: "EQUAL", CreateRevision: new(etcdInt)}},
+			Success: []etcdRequestOp{{RequestPut: etcdPut{Key: []byte(s.lockKey()), Value: []byte(s.lockOwner), Lease: lease.ID}}},
+		})
+	})
+	if err != nil {
+		_ = revoke(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("etcd key %s: %w", s.lockKey(), err)
+	}
+	// Revoking the lease deletes the lock key.
+	return revoke, nil
+}
+
+func (s *etcdStore) lockKey() string {
+	return s.opts.Key + ".lock"
+}
+
+// txn runs a transaction and reports whether its comparisons succeeded.
+func (s *etcdStore) txn(ctx context.Context, txn etcdTxn) (bool, error) {
+	var resp struct {
+		Succeeded bool
+	}
+	err := s.do(ctx, "/v3/kv/txn", txn, &resp)
+	return resp.Succeeded, err
+}
+
+// do sends a request to the JSON gateway of the first reachable endpoint and
+// decodes the JSON response into out.
+func (s *etcdStore) do(ctx context.Context, path string, in any, out any) error {
+	if len(s.opts.Endpoints) == 0 {
+		return errors.New("no etcd endpoints configured")
+	}
+	token, err := s.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, endpoint := range s.opts.Endpoints {
+		err := s.post(ctx, endpoint+path, token, body, out)
+		var statusErr *etcdStatusError
+		if err == nil || errors.As(err, &statusErr) || ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// etcdStatusError is an error response of an endpoint, which is not retried
+// with the other endpoints.
+type etcdStatusError struct {
+	url    string
+	status string
+	body   string
+}
+
+func (e *etcdStatusError) Error() string {
+	return fmt.Sprintf("POST %s: %s: %s", e.url, e.status, e.body)
+}
+
+// authenticate returns the token for the configured username, obtaining it on
+// first use, or an empty token without a username.
+func (s *etcdStore) authenticate(ctx context.Context) (string, error) {
+	if s.opts.Username == "" {
+		return "", nil
+	}
+	s.tokenMutex.Lock()
+	defer s.tokenMutex.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+	body, err := json.Marshal(map[string]string{"name": s.opts.Username, "password": s.opts.Password})
+	if err != nil {
+		return "", err
+	}
+	var auth struct {
+		Token string
+	}
+	var errs []error
+	for _, endpoint := range s.opts.Endpoints {
+		if err = s.post(ctx, endpoint+"/v3/auth/authenticate", "", body, &auth); err == nil {
+			s.token = auth.Token
+			return s.token, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", fmt.Errorf("unable to authenticate with etcd: %w", errors.Join(errs...))
+}
+
+func (s *etcdStore) post(ctx context.Context, url string, token string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &etcdStatusError{url: url, status: resp.Status, body: strings.TrimSpace(string(b))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,155 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEtcd serves the range, transaction, lease and authentication requests
+// of the etcd JSON gateway used by the etcd ledger.
+type fakeEtcd struct {
+	m         sync.Mutex
+	revision  int64
+	values    map[string][]byte
+	revisions map[string][2]int64
+	leases    map[string]int64
+	nextLease int64
+	password  string
+}
+
+func newFakeEtcd(t *testing.T) (*fakeEtcd, *httptest.Server) {
+	f := &fakeEtcd{values: map[string][]byte{}, revisions: map[string][2]int64{}, leases: map[string]int64{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	var req struct {
+		Name     string
+		Password string
+		Key      []byte
+		TTL      etcdInt
+		ID       etcdInt
+		Compare  []etcdCompare
+		Success  []etcdRequestOp
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	if r.URL.Path == "/v3/auth/authenticate" {
+		if req.Name != "netcalc" || req.Password != f.password {
+			http.Error(w, `{"error":"etcdserver: authentication failed, invalid user ID or password","code":3}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"token":"token"}`)
+		return
+	}
+	if f.password != "" && r.Header.Get("Authorization") != "token" {
+		http.Error(w, `{"error":"etcdserver: user name is empty","code":3}`, http.StatusBadRequest)
+		return
+	}
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		value, ok := f.values[string(req.Key)]
+		if !ok {
+			fmt.Fprint(w, `{"header":{}}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"kvs": []map[string]any{{
+			"key":          req.Key,
+			"value":        value,
+			"mod_revision": strconv.FormatInt(f.revisions[string(req.Key)][1], 10),
+		}}})
+	case "/v3/kv/txn":
+		for _, c := range req.Compare {
+			revisions := f.revisions[string(c.Key)]
+			if c.Target == "MOD" && revisions[1] != int64(*c.ModRevision) ||
+				c.Target == "CREATE" && revisions[0] != int64(*c.CreateRevision) {
+				fmt.Fprint(w, `{"header":{}}`)
+				return
+			}
+		}
+		for _, op := range req.Success {
+			key := string(op.RequestPut.Key)
+			f.revision++
+			revisions, ok := f.revisions[key]
+			if !ok {
+				revisions[0] = f.revision
+			}
+			revisions[1] = f.revision
+			f.revisions[key] = revisions
+			f.values[key] = op.RequestPut.Value
+			if op.RequestPut.Lease != 0 {
+				f.leases[key] = int64(op.RequestPut.Lease)
+			}
+		}
+		fmt.Fprint(w, `{"header":{},"succeeded":true}`)
+	case "/v3/lease/grant":
+		f.nextLease++
+		fmt.Fprintf(w, `{"ID":"%d","TTL":"%d"}`, f.nextLease, req.TTL)
+	case "/v3/lease/revoke":
+		// Revoking a lease deletes the keys attached to it.
+		for key, lease := range f.leases {
+			if lease == int64(req.ID) {
+				delete(f.leases, key)
+				delete(f.values, key)
+				delete(f.revisions, key)
+			}
+		}
+		fmt.Fprint(w, `{"header":{}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEtcdLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeEtcd(t)
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	opts := EtcdOptions{
+		Endpoints:   []string{unreachable.URL, server.URL},
+		Key:         "/netcalc/ledger",
+		LockTimeout: 10 * time.Second,
+	}
+	l := NewEtcdLedger(opts)
+	assert.Equal("etcd key /netcalc/ledger", l.String())
+
+	testLedger(t, l, func() Ledger { return NewEtcdLedger(opts) })
+	assert.Empty(fake.leases)
+	assert.NotContains(fake.values, "/netcalc/ledger.lock")
+
+	// A lock held by another process times out.
+	fake.revisions["/netcalc/ledger.lock"] = [2]int64{1, 1}
+	impatient := opts
+	impatient.LockTimeout = 200 * time.Millisecond
+	assert.ErrorContains(NewEtcdLedger(impatient).Allocate(ctx, netip.MustParsePrefix("10.2.0.0/24"), "b"), "still held")
+	delete(fake.revisions, "/netcalc/ledger.lock")
+
+	// Requests authenticate with the username and password.
+	fake.password = "secret"
+	_, err := NewEtcdLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "user name is empty")
+	opts.Username = "netcalc"
+	opts.Password = "wrong"
+	_, err = NewEtcdLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "authentication failed")
+	opts.Password = "secret"
+	entries, err := NewEtcdLedger(opts).Entries(ctx)
+	if assert.NoError(err) {
+		assert.Len(entries, 5)
+	}
+}
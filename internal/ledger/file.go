@@ -0,0 +1,129 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileLedger is a ledger stored as a JSON file on the local filesystem.
+// Writes replace the file atomically, but concurrent writers in different
+// processes are not coordinated.
+type FileLedger struct {
+	path string
+	m    sync.Mutex
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+var _ Ledger = &FileLedger{}
+
+// NewFileLedger returns a ledger stored in the file at path. The file is
+// created on the first write if it does not exist.
+func NewFileLedger(path string) *FileLedger {
+	return &FileLedger{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// Path returns the path of the ledger file.
+func (l *FileLedger) Path() string {
+	return l.path
+}
+
+// Init creates an empty ledger file if none exists, and fails if the
+// existing file is not a valid ledger.
+func (l *FileLedger) Init(ctx context.Context) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	contents, err := l.read()
+	if err != nil {
+		return err
+	}
+	return l.write(contents)
+}
+
+// Exists reports whether the ledger file exists.
+func (l *FileLedger) Exists() (bool, error) {
+	_, err := os.Stat(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (l *FileLedger) Entries(ctx context.Context) ([]Entry, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	contents, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	return contents.Entries, nil
+}
+
+func (l *FileLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	contents, err := l.read()
+	if err != nil {
+		return err
+	}
+	changed, err := contents.allocate(prefix, owner, l.now(), nested)
+	if err != nil || !changed {
+		return err
+	}
+	return l.write(contents)
+}
+
+func (l *FileLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	contents, err := l.read()
+	if err != nil {
+		return err
+	}
+	if !contents.release(prefix, owner, l.now()) {
+		return nil
+	}
+	return l.write(contents)
+}
+
+func (l *FileLedger) read() (document, error) {
+	b, err := os.ReadFile(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return document{}, nil
+	}
+	if err != nil {
+		return document{}, err
+	}
+	return parseDocument(b, "file "+l.path)
+}
+
+// write replaces the ledger file by renaming a temporary file over it, so
+// readers never see a partially written ledger.
+func (l *FileLedger) write(contents document) error {
+	b, err := contents.marshal()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), l.path)
+}
@@ -0,0 +1,63 @@
+package ledger
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l := NewFileLedger(path)
+	l.now = func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	exists, err := l.Exists()
+	if assert.NoError(err) {
+		assert.False(exists)
+	}
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) {
+		assert.Empty(entries)
+	}
+	assert.NoError(l.Init(ctx))
+	exists, err = l.Exists()
+	if assert.NoError(err) {
+		assert.True(exists)
+	}
+
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	// Allocating again to the same owner is a no-op.
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.Error(l.Allocate(ctx, netip.MustParsePrefix("10.0.0.0/16"), "b"))
+
+	// Releasing as another owner does not release the allocation.
+	assert.NoError(l.Release(ctx, prefix, "b"))
+	owner, ok, err := Owner(ctx, l, prefix)
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("a", owner)
+	}
+
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	assert.NoError(l.Allocate(ctx, prefix, "b"))
+
+	// Entries survive reopening the ledger, and releases are kept as tombstones.
+	entries, err = NewFileLedger(path).Entries(ctx)
+	if assert.NoError(err) && assert.Len(entries, 2) {
+		assert.Equal("a", entries[0].Owner)
+		assert.False(entries[0].Active())
+		assert.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), *entries[0].ReleasedAt)
+		assert.Equal("b", entries[1].Owner)
+		assert.True(entries[1].Active())
+	}
+
+	assert.NoError(os.WriteFile(path, []byte("not json"), 0o600))
+	assert.Error(l.Init(ctx))
+}
@@ -0,0 +1,88 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/storage/v1"
+)
+
+// GCSOptions configures a ledger stored in Google Cloud Storage.
+type GCSOptions struct {
+	// Bucket and Object locate the ledger object.
+	Bucket string
+	Object string
+}
+
+// gcsStore stores the ledger document as a Cloud Storage object. The version
+// of the document is the generation of the object, and writes are
+// conditional on it, so they fail if the object was written in the
+// meantime.
+type gcsStore struct {
+	service *storage.Service
+	opts    GCSOptions
+}
+
+// NewGCSLedger returns a ledger stored in a Google Cloud Storage object,
+// which is created on the first write if it does not exist. Every write is
+// conditional on the generation of the object that was read, and is retried
+// when another Terraform run wrote the object in the meantime, so concurrent
+// runs do not hand out overlapping allocations.
+func NewGCSLedger(service *storage.Service, opts GCSOptions) *RemoteLedger {
+	return &RemoteLedger{
+		name:  fmt.Sprintf("gs://%s/%s", opts.Bucket, opts.Object),
+		store: &gcsStore{service: service, opts: opts},
+		now:   time.Now,
+	}
+}
+
+func (s *gcsStore) load(ctx context.Context) (document, string, error) {
+	resp, err := s.service.Objects.Get(s.opts.Bucket, s.opts.Object).Context(ctx).Download()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		// Generation 0 matches only an object that does not exist.
+		return document{}, "0", nil
+	}
+	if err != nil {
+		return document{}, "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return document{}, "", err
+	}
+	generation := resp.Header.Get("X-Goog-Generation")
+	if generation == "" {
+		return document{}, "", fmt.Errorf("unable to read gs://%s/%s: the response has no generation", s.opts.Bucket, s.opts.Object)
+	}
+	doc, err := parseDocument(b, fmt.Sprintf("gs://%s/%s", s.opts.Bucket, s.opts.Object))
+	return doc, generation, err
+}
+
+func (s *gcsStore) save(ctx context.Context, doc document, version string) error {
+	b, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	generation, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = s.service.Objects.Insert(s.opts.Bucket, &storage.Object{Name: s.opts.Object, ContentType: "application/json"}).
+		Media(bytes.NewReader(b), googleapi.ContentType("application/json")).
+		IfGenerationMatch(generation).
+		Context(ctx).
+		Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return errConflict
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// gcsObject is an object stored by fakeGCS.
+type gcsObject struct {
+	data       []byte
+	generation int64
+}
+
+// fakeGCS serves the object requests of the JSON API used by the GCS ledger.
+type fakeGCS struct {
+	m          sync.Mutex
+	objects    map[string]gcsObject
+	generation int64
+}
+
+func newFakeGCS(t *testing.T) (*fakeGCS, *storage.Service) {
+	f := &fakeGCS{objects: map[string]gcsObject{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	service, err := storage.NewService(context.Background(), option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, service
+}
+
+func (f *fakeGCS) error(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"error":{"code":%d,"message":%q}}`, code, message)
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if bucketObject, ok := strings.CutPrefix(r.URL.Path, "/storage/v1/b/"); ok && r.Method == http.MethodGet {
+		bucket, object, _ := strings.Cut(bucketObject, "/o/")
+		obj, ok := f.objects[bucket+"/"+object]
+		if !ok {
+			f.error(w, http.StatusNotFound, "No such object")
+			return
+		}
+		w.Header().Set("X-Goog-Generation", strconv.FormatInt(obj.generation, 10))
+		_, _ = w.Write(obj.data)
+		return
+	}
+	bucket, ok := strings.CutPrefix(r.URL.Path, "/upload/storage/v1/b/")
+	if !ok || r.Method != http.MethodPost {
+		f.error(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	bucket = strings.TrimSuffix(bucket, "/o")
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		f.error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	parts := multipart.NewReader(r.Body, params["boundary"])
+	var metadata storage.Object
+	for i := 0; i < 2; i++ {
+		part, err := parts.NextPart()
+		if err != nil {
+			f.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		b, _ := io.ReadAll(part)
+		if i == 0 {
+			_ = json.Unmarshal(b, &metadata)
+			continue
+		}
+		key := bucket + "/" + metadata.Name
+		if want := r.URL.Query().Get("ifGenerationMatch"); want != "" && want != strconv.FormatInt(f.objects[key].generation, 10) {
+			f.error(w, http.StatusPreconditionFailed, "At least one of the pre-conditions you specified did not hold.")
+			return
+		}
+		f.generation++
+		f.objects[key] = gcsObject{data: b, generation: f.generation}
+		_ = json.NewEncoder(w).Encode(storage.Object{Bucket: bucket, Name: metadata.Name, Generation: f.generation})
+	}
+}
+
+func TestGCSLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, service := newFakeGCS(t)
+	opts := GCSOptions{Bucket: "network", Object: "netcalc/ledger.json"}
+	l := NewGCSLedger(service, opts)
+	assert.Equal("gs://network/netcalc/ledger.json", l.String())
+
+	testLedger(t, l, func() Ledger { return NewGCSLedger(service, opts) })
+	assert.Equal(int64(6), fake.objects["network/netcalc/ledger.json"].generation)
+
+	// A stale generation is a conflict.
+	store := l.store.(*gcsStore)
+	doc, version, err := store.load(ctx)
+	if assert.NoError(err) {
+		assert.NoError(store.save(ctx, doc, version))
+		assert.ErrorIs(store.save(ctx, doc, version), errConflict)
+	}
+}
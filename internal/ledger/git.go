@@ -0,0 +1,285 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// GitOptions configures a ledger committed to a Git repository.
+type GitOptions struct {
+	// URL of the repository, e.g. https://github.com/example/network.git.
+	URL string
+	// Branch the ledger is committed to, main if empty. It is created on
+	// the first write if it does not exist.
+	Branch string
+	// Path of the ledger file within the repository, allocations.json if
+	// empty.
+	Path string
+	// Auth authenticates with the remote, if it requires authentication.
+	Auth transport.AuthMethod
+	// AuthorName and AuthorEmail identify the author of the commits,
+	// netcalc if empty.
+	AuthorName  string
+	AuthorEmail string
+}
+
+// gitStore stores the ledger document as a file in a Git repository. The
+// version of the document is the commit the branch pointed at when it was
+// read, and a write is a new commit on top of it that is pushed without
+// force, so it fails when the branch moved in the meantime.
+type gitStore struct {
+	opts GitOptions
+	// now returns the time of the commits. It is replaced in tests.
+	now func() time.Time
+}
+
+// NewGitLedger returns a ledger committed to a file in a Git repository, so
+// that every allocation and release is a commit that can be reviewed and
+// audited like any other change to the repository. Writes are pushed on top
+// of the commit that was read, and are retried when another Terraform run
+// pushed to the branch in the meantime, so concurrent runs do not hand out
+// overlapping allocations.
+func NewGitLedger(opts GitOptions) *RemoteLedger {
+	if opts.Branch == "" {
+		opts.Branch = "main"
+	}
+	opts.Path = strings.Trim(opts.Path, "/")
+	if opts.Path == "" {
+		opts.Path = "allocations.json"
+	}
+	if opts.AuthorName == "" {
+		opts.AuthorName = "netcalc"
+	}
+	if opts.AuthorEmail == "" {
+		opts.AuthorEmail = "netcalc"
+	}
+	return &RemoteLedger{
+		name:  fmt.Sprintf("Git file %s on branch %s of %s", opts.Path, opts.Branch, opts.URL),
+		store: &gitStore{opts: opts, now: time.Now},
+		now:   time.Now,
+	}
+}
+
+func (s *gitStore) load(ctx context.Context) (document, string, error) {
+	repo, err := s.clone(ctx, nil)
+	if repo == nil || err != nil {
+		return document{}, "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return document{}, "", err
+	}
+	b, err := s.readFile(repo, head.Hash())
+	if err != nil {
+		return document{}, "", err
+	}
+	doc, err := parseDocument(b, s.name())
+	return doc, head.Hash().String(), err
+}
+
+func (s *gitStore) save(ctx context.Context, doc document, version string) error {
+	fs := memfs.New()
+	repo, err := s.clone(ctx, fs)
+	if err != nil {
+		return err
+	}
+	var prior document
+	if repo == nil {
+		if version != "" {
+			// The branch was deleted since it was read.
+			return errConflict
+		}
+		if repo, err = s.initBranch(fs); err != nil {
+			return err
+		}
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return err
+		}
+		if head.Hash().String() != version {
+			return errConflict
+		}
+		b, err := s.readFile(repo, head.Hash())
+		if err != nil {
+			return err
+		}
+		if prior, err = parseDocument(b, s.name()); err != nil {
+			return err
+		}
+	}
+
+	data, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	if err := writeFile(fs, s.opts.Path, data); err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if _, err := wt.Add(s.opts.Path); err != nil {
+		return err
+	}
+	_, err = wt.Commit(commitMessage(prior, doc), &git.CommitOptions{
+		Author: &object.Signature{Name: s.opts.AuthorName, Email: s.opts.AuthorEmail, When: s.now()},
+	})
+	if err != nil {
+		return err
+	}
+
+	ref := plumbing.NewBranchReferenceName(s.opts.Branch)
+	err = repo.PushContext(ctx, &git.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", ref, ref))},
+		Auth:     s.opts.Auth,
+	})
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	// The push is rejected when the branch moved since it was cloned, either
+	// by the client or, when it moved after the refs were advertised, by the
+	// server. Either way the push is retried as a conflict.
+	if current, lsErr := s.remoteHead(ctx); lsErr == nil && current != version {
+		return errConflict
+	}
+	return fmt.Errorf("unable to push to %s: %w", s.opts.URL, err)
+}
+
+// clone clones the branch into memory, with a worktree in fs unless fs is
+// nil. It returns a nil repository if the repository is empty or the branch
+// does not exist.
+func (s *gitStore) clone(ctx context.Context, fs billy.Filesystem) (*git.Repository, error) {
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), fs, &git.CloneOptions{
+		URL:           s.opts.URL,
+		Auth:          s.opts.Auth,
+		ReferenceName: plumbing.NewBranchReferenceName(s.opts.Branch),
+		SingleBranch:  true,
+		Depth:         1,
+		NoCheckout:    fs == nil,
+		Tags:          git.NoTags,
+	})
+	var noMatchingRef git.NoMatchingRefSpecError
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) || errors.As(err, &noMatchingRef) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to clone %s: %w", s.opts.URL, err)
+	}
+	return repo, nil
+}
+
+// initBranch returns a new repository with a worktree in fs, whose HEAD is
+// the unborn branch of the ledger and whose origin is the remote.
+func (s *gitStore) initBranch(fs billy.Filesystem) (*git.Repository, error) {
+	repo, err := git.InitWithOptions(memory.NewStorage(), fs, git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName(s.opts.Branch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{s.opts.URL}})
+	return repo, err
+}
+
+// remoteHead returns the commit the branch points at in the remote, or an
+// empty string if it does not exist.
+func (s *gitStore) remoteHead(ctx context.Context) (string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{s.opts.URL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: s.opts.Auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.NewBranchReferenceName(s.opts.Branch) {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", nil
+}
+
+// readFile returns the content of the ledger file in the commit, which is
+// empty if the file does not exist.
+func (s *gitStore) readFile(repo *git.Repository, hash plumbing.Hash) ([]byte, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	f, err := commit.File(s.opts.Path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (s *gitStore) name() string {
+	return fmt.Sprintf("Git file %s", s.opts.Path)
+}
+
+// writeFile replaces the file at name in fs, creating its directory if
+// needed.
+func writeFile(fs billy.Filesystem, name string, data []byte) error {
+	if dir := path.Dir(name); dir != "." {
+		if err := fs.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := fs.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// commitMessage describes the allocations and releases that turn the prior
+// document into doc.
+func commitMessage(prior, doc document) string {
+	var changes []string
+	for i, e := range doc.Entries {
+		switch {
+		case i >= len(prior.Entries):
+			changes = append(changes, fmt.Sprintf("Allocate %s to %s", e.CIDR, e.Owner))
+		case !e.Active() && prior.Entries[i].Active():
+			changes = append(changes, fmt.Sprintf("Release %s from %s", e.CIDR, e.Owner))
+		}
+	}
+	switch len(changes) {
+	case 0:
+		return "Update allocations"
+	case 1:
+		return changes[0]
+	default:
+		return fmt.Sprintf("Update %d allocations\n\n%s", len(changes), strings.Join(changes, "\n"))
+	}
+}
@@ -0,0 +1,88 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// gitLog returns the messages of the commits on the branch of the bare
+// repository in dir, newest first.
+func gitLog(t *testing.T, dir, branch string) []string {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	_ = commits.ForEach(func(c *object.Commit) error {
+		messages = append(messages, c.Message)
+		return nil
+	})
+	return messages
+}
+
+func TestGitLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	opts := GitOptions{URL: dir, Path: "/network/allocations.json"}
+	l := NewGitLedger(opts)
+	assert.Equal(fmt.Sprintf("Git file network/allocations.json on branch main of %s", dir), l.String())
+
+	testLedger(t, l, func() Ledger { return NewGitLedger(opts) })
+	assert.Equal([]string{"Release 10.0.0.0/24 from a", "Allocate 10.0.0.0/24 to a"}, gitLog(t, dir, "main")[4:])
+
+	// Commits carry the configured author.
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	opts.AuthorName = "Network Team"
+	opts.AuthorEmail = "network@example.com"
+	assert.NoError(NewGitLedger(opts).Allocate(ctx, prefix, "c"))
+	repo, _ := git.PlainOpen(dir)
+	ref, _ := repo.Reference(plumbing.NewBranchReferenceName("main"), true)
+	if commit, err := repo.CommitObject(ref.Hash()); assert.NoError(err) {
+		assert.Equal("Network Team", commit.Author.Name)
+		assert.Equal("network@example.com", commit.Author.Email)
+		assert.WithinDuration(time.Now(), commit.Author.When, time.Minute)
+	}
+	assert.Len(gitLog(t, dir, "main"), 7)
+
+	// A branch that does not exist is created on the first write.
+	opts.Branch = "staging"
+	assert.NoError(NewGitLedger(opts).Allocate(ctx, prefix, "d"))
+	assert.Equal([]string{"Allocate 10.0.0.0/24 to d"}, gitLog(t, dir, "staging"))
+
+	// A repository that does not exist is reported.
+	opts.URL = t.TempDir() + "/missing"
+	_, err := NewGitLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "unable to clone")
+}
+
+func TestGitCommitMessage(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	var doc document
+	_, _ = doc.allocate(netip.MustParsePrefix("10.0.0.0/24"), "a", now, nil)
+	prior := document{Entries: append([]Entry(nil), doc.Entries...)}
+	doc.release(netip.MustParsePrefix("10.0.0.0/24"), "a", now)
+	_, _ = doc.allocate(netip.MustParsePrefix("10.0.1.0/24"), "b", now, nil)
+	assert.Equal("Update 2 allocations\n\nRelease 10.0.0.0/24 from a\nAllocate 10.0.1.0/24 to b", commitMessage(prior, doc))
+	assert.Equal("Update allocations", commitMessage(doc, doc))
+}
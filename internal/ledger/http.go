@@ -0,0 +1,157 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPOptions configures a ledger kept by a REST API. URLs may contain the
+// placeholders {cidr} and {owner}, which are replaced with the URL-escaped
+// CIDR block and owner of the request.
+type HTTPOptions struct {
+	// ListURL is requested with GET and returns the allocations, either as a
+	// JSON array of entries or as a ledger document, i.e. an object with an
+	// entries attribute. Entries have the attributes cidr and owner, and
+	// optionally allocated_at and released_at.
+	ListURL string
+	// ClaimURL is requested with ClaimMethod, POST if empty, to record an
+	// allocation. The request body is a JSON object with the attributes cidr
+	// and owner. A 409 Conflict response rejects the allocation.
+	ClaimURL    string
+	ClaimMethod string
+	// ReleaseURL is requested with ReleaseMethod, POST if empty, to record a
+	// release, with the same request body as a claim. A 404 Not Found
+	// response means there was nothing to release.
+	ReleaseURL    string
+	ReleaseMethod string
+	// Headers are sent with every request, e.g. Authorization.
+	Headers map[string]string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// HTTPLedger is a ledger kept by a REST API, for organizations with an IPAM
+// service of their own. Allocations are checked for overlaps with the listed
+// allocations before they are claimed, and the API is expected to reject
+// claims that conflict with allocations made in the meantime.
+type HTTPLedger struct {
+	opts HTTPOptions
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+var _ Ledger = &HTTPLedger{}
+
+// NewHTTPLedger returns a ledger kept by a REST API.
+func NewHTTPLedger(opts HTTPOptions) *HTTPLedger {
+	if opts.ClaimMethod == "" {
+		opts.ClaimMethod = http.MethodPost
+	}
+	if opts.ReleaseMethod == "" {
+		opts.ReleaseMethod = http.MethodPost
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &HTTPLedger{opts: opts, now: time.Now}
+}
+
+// String returns a description of where the ledger is stored, for use in
+// diagnostics.
+func (l *HTTPLedger) String() string {
+	return l.opts.ListURL
+}
+
+func (l *HTTPLedger) Entries(ctx context.Context) ([]Entry, error) {
+	b, _, err := l.do(ctx, http.MethodGet, l.opts.ListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(b, &entries); err != nil {
+			return nil, fmt.Errorf("unable to parse ledger %s: %w", l, err)
+		}
+		return entries, nil
+	}
+	doc, err := parseDocument(b, l.String())
+	return doc.Entries, err
+}
+
+func (l *HTTPLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	entries, err := l.Entries(ctx)
+	if err != nil {
+		return err
+	}
+	doc := document{Entries: entries}
+	changed, err := doc.allocate(prefix, owner, l.now(), nested)
+	if err != nil || !changed {
+		return err
+	}
+	_, status, err := l.do(ctx, l.opts.ClaimMethod, l.url(l.opts.ClaimURL, prefix, owner), l.body(prefix, owner))
+	if status == http.StatusConflict {
+		return fmt.Errorf("%s was rejected by %s, as it is already allocated: %w", prefix, l, err)
+	}
+	return err
+}
+
+func (l *HTTPLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	_, status, err := l.do(ctx, l.opts.ReleaseMethod, l.url(l.opts.ReleaseURL, prefix, owner), l.body(prefix, owner))
+	if status == http.StatusNotFound {
+		// Nothing to release, e.g. the allocation was made before the ledger
+		// was configured.
+		return nil
+	}
+	return err
+}
+
+// url replaces the placeholders of a URL.
+func (l *HTTPLedger) url(u string, prefix netip.Prefix, owner string) string {
+	return strings.NewReplacer(
+		"{cidr}", url.PathEscape(prefix.String()),
+		"{owner}", url.PathEscape(owner),
+	).Replace(u)
+}
+
+// body returns the request body of a claim or release.
+func (l *HTTPLedger) body(prefix netip.Prefix, owner string) []byte {
+	b, _ := json.Marshal(map[string]string{"cidr": prefix.String(), "owner": owner})
+	return b
+}
+
+// do sends a request to the API and returns the response body and status. It
+// fails unless the status is a success.
+func (l *HTTPLedger) do(ctx context.Context, method string, u string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range l.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := l.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return b, resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, resp.StatusCode, nil
+}
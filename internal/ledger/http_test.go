@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIPAM is a REST API keeping allocations in memory, which lists them at
+// /allocations, claims them with POST /allocations and releases them with
+// DELETE /allocations/{cidr}.
+type fakeIPAM struct {
+	m           sync.Mutex
+	allocations []Entry
+}
+
+func newFakeIPAM(t *testing.T) (*fakeIPAM, *httptest.Server) {
+	f := &fakeIPAM{}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeIPAM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/allocations":
+		_ = json.NewEncoder(w).Encode(f.allocations)
+	case r.Method == http.MethodPost && r.URL.Path == "/allocations":
+		var e Entry
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, a := range f.allocations {
+			if a.CIDR.Overlaps(e.CIDR) {
+				http.Error(w, fmt.Sprintf("%s overlaps %s", e.CIDR, a.CIDR), http.StatusConflict)
+				return
+			}
+		}
+		f.allocations = append(f.allocations, e)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		for i, a := range f.allocations {
+			if "/allocations/"+a.CIDR.String() == r.URL.Path && a.Owner == r.URL.Query().Get("owner") {
+				f.allocations = append(f.allocations[:i], f.allocations[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHTTPLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeIPAM(t)
+	fake.allocations = []Entry{{CIDR: netip.MustParsePrefix("10.1.0.0/24"), Owner: "platform"}}
+	opts := HTTPOptions{
+		ListURL:       server.URL + "/allocations",
+		ClaimURL:      server.URL + "/allocations",
+		ReleaseURL:    server.URL + "/allocations/{cidr}?owner={owner}",
+		ReleaseMethod: http.MethodDelete,
+		Headers:       map[string]string{"Authorization": "Bearer secret"},
+	}
+	l := NewHTTPLedger(opts)
+	assert.Equal(server.URL+"/allocations", l.String())
+
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) && assert.Len(entries, 1) {
+		assert.Equal(Entry{CIDR: netip.MustParsePrefix("10.1.0.0/24"), Owner: "platform"}, entries[0])
+	}
+
+	prefix := netip.MustParsePrefix("10.1.1.0/24")
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.Len(fake.allocations, 2)
+	assert.ErrorContains(l.Allocate(ctx, netip.MustParsePrefix("10.1.0.0/16"), "b"), `overlaps 10.1.0.0/24, which is allocated to "platform"`)
+	owner, ok, err := Owner(ctx, l, prefix)
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("a", owner)
+	}
+
+	// Only the owner releases an allocation, and releasing twice is fine.
+	assert.NoError(l.Release(ctx, prefix, "b"))
+	assert.Len(fake.allocations, 2)
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	assert.Len(fake.allocations, 1)
+	assert.NoError(l.Release(ctx, prefix, "a"))
+
+	// Claims the API rejects as conflicting fail, e.g. when another process
+	// allocated the CIDR block after the overlap check.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"entries": []}`)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	})
+	assert.ErrorContains(l.Allocate(ctx, netip.MustParsePrefix("10.1.0.0/25"), "c"), "10.1.0.0/25 was rejected by "+server.URL+"/allocations, as it is already allocated")
+
+	server.Config.Handler = fake
+	opts.Headers = nil
+	_, err = NewHTTPLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "401 Unauthorized: invalid token")
+}
@@ -0,0 +1,196 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// InfobloxOptions configures a ledger kept in Infoblox.
+type InfobloxOptions struct {
+	// Host of the Infoblox grid manager, e.g. infoblox.example.com or
+	// https://infoblox.example.com. The scheme defaults to https.
+	Host string
+	// WAPIVersion defaults to 2.12.
+	WAPIVersion string
+	// Username and Password authenticate with Infoblox.
+	Username string
+	Password string
+	// NetworkView defaults to default.
+	NetworkView string
+	// NetworkContainer is the network container, e.g. 10.0.0.0/8, whose
+	// networks are the allocations of the ledger.
+	NetworkContainer netip.Prefix
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// infobloxPageSize is how many objects are requested per page.
+const infobloxPageSize = 1000
+
+// InfobloxLedger is a ledger kept as networks in an Infoblox network
+// container, so allocations show up in the IPAM of an organization. Networks
+// and network containers in the container created by other means are
+// reported as allocations too, and allocations are created as networks with
+// the owner as their comment.
+//
+// Allocations are checked for overlaps with the networks in the container
+// before they are created, and Infoblox rejects networks that already exist.
+// Released networks are deleted, so the ledger has no tombstones.
+type InfobloxLedger struct {
+	opts InfobloxOptions
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+var _ Ledger = &InfobloxLedger{}
+
+// NewInfobloxLedger returns a ledger kept in an Infoblox network container.
+func NewInfobloxLedger(opts InfobloxOptions) *InfobloxLedger {
+	if !strings.Contains(opts.Host, "://") {
+		opts.Host = "https://" + opts.Host
+	}
+	opts.Host = strings.TrimSuffix(opts.Host, "/")
+	if opts.WAPIVersion == "" {
+		opts.WAPIVersion = "2.12"
+	}
+	if opts.NetworkView == "" {
+		opts.NetworkView = "default"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &InfobloxLedger{opts: opts, now: time.Now}
+}
+
+// String returns a description of where the ledger is stored, for use in
+// diagnostics.
+func (l *InfobloxLedger) String() string {
+	return fmt.Sprintf("Infoblox network container %s", l.opts.NetworkContainer)
+}
+
+// infobloxNetwork is a network or network container object.
+type infobloxNetwork struct {
+	Ref     string `json:"_ref"`
+	Network string `json:"network"`
+	Comment string `json:"comment"`
+}
+
+func (l *InfobloxLedger) Entries(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	for _, objectType := range []string{"network", "networkcontainer"} {
+		networks, err := l.search(ctx, objectType, url.Values{"network_container": {l.opts.NetworkContainer.String()}})
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range networks {
+			prefix, err := netip.ParsePrefix(n.Network)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s %q of %s: %w", objectType, n.Network, l, err)
+			}
+			entries = append(entries, Entry{CIDR: prefix, Owner: n.Comment})
+		}
+	}
+	return entries, nil
+}
+
+func (l *InfobloxLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	entries, err := l.Entries(ctx)
+	if err != nil {
+		return err
+	}
+	doc := document{Entries: entries}
+	changed, err := doc.allocate(prefix, owner, l.now(), nested)
+	if err != nil || !changed {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{
+		"network":      prefix.String(),
+		"network_view": l.opts.NetworkView,
+		"comment":      owner,
+	})
+	if err != nil {
+		return err
+	}
+	return l.do(ctx, http.MethodPost, "network", nil, body, nil)
+}
+
+func (l *InfobloxLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	networks, err := l.search(ctx, "network", url.Values{"network": {prefix.String()}})
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		// Networks of other owners, e.g. created outside of Terraform, are
+		// left alone.
+		if n.Comment == owner {
+			return l.do(ctx, http.MethodDelete, n.Ref, nil, nil, nil)
+		}
+	}
+	return nil
+}
+
+// search returns the objects of a type in the network view matching the
+// query, following result pages.
+func (l *InfobloxLedger) search(ctx context.Context, objectType string, query url.Values) ([]infobloxNetwork, error) {
+	query.Set("network_view", l.opts.NetworkView)
+	query.Set("_return_fields", "network,comment")
+	query.Set("_paging", "1")
+	query.Set("_return_as_object", "1")
+	query.Set("_max_results", fmt.Sprint(infobloxPageSize))
+	var networks []infobloxNetwork
+	for {
+		var page struct {
+			Result     []infobloxNetwork `json:"result"`
+			NextPageID string            `json:"next_page_id"`
+		}
+		if err := l.do(ctx, http.MethodGet, objectType, query, nil, &page); err != nil {
+			return nil, err
+		}
+		networks = append(networks, page.Result...)
+		if page.NextPageID == "" {
+			return networks, nil
+		}
+		query = url.Values{"_page_id": {page.NextPageID}}
+	}
+}
+
+// do sends a request to the WAPI and decodes the JSON response into out.
+func (l *InfobloxLedger) do(ctx context.Context, method string, path string, query url.Values, body []byte, out any) error {
+	u := fmt.Sprintf("%s/wapi/v%s/%s?%s", l.opts.Host, l.opts.WAPIVersion, path, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(l.opts.Username, l.opts.Password)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var wapiErr struct {
+			Text string `json:"text"`
+		}
+		if json.Unmarshal(b, &wapiErr) == nil && wapiErr.Text != "" {
+			return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, wapiErr.Text)
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,161 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeInfoblox serves the network and network container objects of the WAPI
+// from memory, one object per result page.
+type fakeInfoblox struct {
+	m          sync.Mutex
+	networks   []infobloxNetwork
+	containers []infobloxNetwork
+	nextRef    int
+	pages      map[string][]infobloxNetwork
+}
+
+func newFakeInfoblox(t *testing.T) (*fakeInfoblox, *httptest.Server) {
+	f := &fakeInfoblox{pages: map[string][]infobloxNetwork{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeInfoblox) add(networks *[]infobloxNetwork, network string, comment string) {
+	f.nextRef++
+	*networks = append(*networks, infobloxNetwork{
+		Ref:     fmt.Sprintf("network/ref%d:%s/default", f.nextRef, network),
+		Network: network,
+		Comment: comment,
+	})
+}
+
+func (f *fakeInfoblox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if user, password, _ := r.BasicAuth(); user != "admin" || password != "infoblox" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"Error":"AdmConProtoError: Authentication failed","code":"Client.Ibap.Proto","text":"Authentication failed"}`)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/wapi/v2.12/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet:
+		var results []infobloxNetwork
+		if id := query.Get("_page_id"); id != "" {
+			results = f.pages[id]
+		} else {
+			networks := f.networks
+			if path == "networkcontainer" {
+				networks = f.containers
+			}
+			for _, n := range networks {
+				prefix := netip.MustParsePrefix(n.Network)
+				container := query.Get("network_container")
+				if container != "" && netip.MustParsePrefix(container).Contains(prefix.Addr()) && container != n.Network ||
+					query.Get("network") == n.Network {
+					results = append(results, n)
+				}
+			}
+		}
+		page := map[string]any{"result": []infobloxNetwork{}}
+		if len(results) > 0 {
+			page["result"] = results[:1]
+		}
+		if len(results) > 1 {
+			id := fmt.Sprintf("page%d", len(f.pages))
+			f.pages[id] = results[1:]
+			page["next_page_id"] = id
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	case r.Method == http.MethodPost && path == "network":
+		var n infobloxNetwork
+		_ = json.NewDecoder(r.Body).Decode(&n)
+		f.add(&f.networks, n.Network, n.Comment)
+		fmt.Fprintf(w, "%q", f.networks[len(f.networks)-1].Ref)
+	case r.Method == http.MethodDelete:
+		for i, n := range f.networks {
+			if n.Ref == path {
+				f.networks = append(f.networks[:i], f.networks[i+1:]...)
+				fmt.Fprintf(w, "%q", path)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestInfobloxLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeInfoblox(t)
+	fake.add(&fake.containers, "10.0.0.0/8", "")
+	fake.add(&fake.containers, "10.2.0.0/16", "platform")
+	fake.add(&fake.networks, "10.1.0.0/24", "")
+	fake.add(&fake.networks, "192.168.0.0/24", "")
+	opts := InfobloxOptions{
+		Host:             server.URL,
+		Username:         "admin",
+		Password:         "infoblox",
+		NetworkContainer: netip.MustParsePrefix("10.0.0.0/8"),
+	}
+	l := NewInfobloxLedger(opts)
+	assert.Equal("Infoblox network container 10.0.0.0/8", l.String())
+
+	// Networks and containers in the container are allocations.
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) && assert.Len(entries, 2) {
+		assert.Equal(Entry{CIDR: netip.MustParsePrefix("10.1.0.0/24")}, entries[0])
+		assert.Equal(Entry{CIDR: netip.MustParsePrefix("10.2.0.0/16"), Owner: "platform"}, entries[1])
+	}
+
+	prefix := netip.MustParsePrefix("10.1.1.0/24")
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.ErrorContains(l.Allocate(ctx, netip.MustParsePrefix("10.1.0.0/16"), "b"), `overlaps 10.1.0.0/24, which is allocated to ""`)
+	assert.ErrorContains(l.Allocate(ctx, netip.MustParsePrefix("10.2.1.0/24"), "b"), `overlaps 10.2.0.0/16, which is allocated to "platform"`)
+	owner, ok, err := Owner(ctx, l, prefix)
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("a", owner)
+	}
+
+	// Only the owner releases a network.
+	assert.NoError(l.Release(ctx, prefix, "b"))
+	assert.Len(fake.networks, 3)
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	assert.Len(fake.networks, 2)
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	_, ok, err = Owner(ctx, l, prefix)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+
+	// Errors of the WAPI are reported with their text, e.g. when another
+	// process created the network after the overlap check.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"Error":"AdmConDataError: None (IBDataConflictError: IB.Data.Conflict:The network 10.3.0.0/24 already exists.)","code":"Client.Ibap.Data.Conflict","text":"The network 10.3.0.0/24 already exists."}`)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	})
+	assert.ErrorContains(l.Allocate(ctx, netip.MustParsePrefix("10.3.0.0/24"), "c"), "400 Bad Request: The network 10.3.0.0/24 already exists.")
+	opts.Password = "wrong"
+	_, err = NewInfobloxLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "401 Unauthorized: Authentication failed")
+}
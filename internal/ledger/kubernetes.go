@@ -0,0 +1,231 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KubernetesOptions configures a ledger stored in a Kubernetes object.
+type KubernetesOptions struct {
+	// Host is the URL of the Kubernetes API server, e.g.
+	// https://kubernetes.default.svc.
+	Host string
+	// Token is the bearer token that authenticates the requests, if any.
+	Token string
+	// Namespace of the object. It is empty for a cluster-scoped custom
+	// resource.
+	Namespace string
+	// Name of the object.
+	Name string
+	// Key of the ConfigMap data entry holding the ledger document,
+	// allocations.json if empty. It is not used for custom resources.
+	Key string
+	// CustomResource is the kind of custom resource whose spec is the ledger
+	// document. The ledger is stored in a ConfigMap if it is nil.
+	CustomResource *KubernetesCustomResource
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// KubernetesCustomResource identifies the kind of a custom resource.
+type KubernetesCustomResource struct {
+	// APIVersion is the group and version, e.g. netcalc.example.com/v1.
+	APIVersion string
+	// Kind, e.g. SubnetLedger.
+	Kind string
+	// Plural is the resource name of the kind in API paths, the lowercase
+	// kind with an s suffix if empty.
+	Plural string
+}
+
+// kubernetesStore stores the ledger document in a ConfigMap data entry or in
+// the spec of a custom resource. Writes are merge patches that carry the
+// resource version that was read, which the API server rejects if the object
+// changed in the meantime.
+type kubernetesStore struct {
+	opts KubernetesOptions
+}
+
+// kubernetesObject is the part of a ConfigMap or custom resource used by the
+// ledger.
+type kubernetesObject struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Metadata   struct {
+		Name            string `json:"name,omitempty"`
+		Namespace       string `json:"namespace,omitempty"`
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data,omitempty"`
+	Spec json.RawMessage   `json:"spec,omitempty"`
+}
+
+// NewKubernetesLedger returns a ledger stored in a Kubernetes ConfigMap or
+// custom resource, which is created on the first write if it does not exist.
+// Every write is conditional on the resource version that was read, and is
+// retried when another Terraform run or an in-cluster controller changed the
+// object in the meantime, so they do not hand out overlapping allocations.
+func NewKubernetesLedger(opts KubernetesOptions) *RemoteLedger {
+	opts.Host = strings.TrimSuffix(opts.Host, "/")
+	if opts.Key == "" {
+		opts.Key = "allocations.json"
+	}
+	if cr := opts.CustomResource; cr != nil && cr.Plural == "" {
+		copied := *cr
+		copied.Plural = strings.ToLower(cr.Kind) + "s"
+		opts.CustomResource = &copied
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	store := &kubernetesStore{opts: opts}
+	return &RemoteLedger{
+		name:  store.name(),
+		store: store,
+		now:   time.Now,
+	}
+}
+
+func (s *kubernetesStore) load(ctx context.Context) (document, string, error) {
+	b, status, err := s.do(ctx, http.MethodGet, s.objectPath(), "", nil)
+	if status == http.StatusNotFound {
+		return document{}, "", nil
+	}
+	if err != nil {
+		return document{}, "", err
+	}
+	var obj kubernetesObject
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return document{}, "", fmt.Errorf("unable to parse %s: %w", s.name(), err)
+	}
+	var content []byte
+	if s.opts.CustomResource != nil {
+		if string(obj.Spec) != "null" {
+			content = obj.Spec
+		}
+	} else {
+		content = []byte(obj.Data[s.opts.Key])
+	}
+	doc, err := parseDocument(content, s.name())
+	return doc, obj.Metadata.ResourceVersion, err
+}
+
+func (s *kubernetesStore) save(ctx context.Context, doc document, version string) error {
+	data, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	var obj kubernetesObject
+	if cr := s.opts.CustomResource; cr != nil {
+		obj.Spec = data
+	} else {
+		obj.Data = map[string]string{s.opts.Key: string(data)}
+	}
+
+	if version == "" {
+		// The object did not exist. Creating it fails with a conflict if it
+		// was created in the meantime.
+		obj.Metadata.Name = s.opts.Name
+		obj.Metadata.Namespace = s.opts.Namespace
+		obj.APIVersion, obj.Kind = "v1", "ConfigMap"
+		if cr := s.opts.CustomResource; cr != nil {
+			obj.APIVersion, obj.Kind = cr.APIVersion, cr.Kind
+		}
+		body, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, status, err := s.do(ctx, http.MethodPost, s.collectionPath(), "application/json", body)
+		if status == http.StatusConflict {
+			return errConflict
+		}
+		return err
+	}
+
+	// A merge patch replaces the ledger entry or the spec, and leaves the
+	// other data entries and metadata of the object alone.
+	obj.Metadata.ResourceVersion = version
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, status, err := s.do(ctx, http.MethodPatch, s.objectPath(), "application/merge-patch+json", body)
+	if status == http.StatusConflict || status == http.StatusNotFound {
+		// The object was changed or deleted since it was read.
+		return errConflict
+	}
+	return err
+}
+
+// collectionPath returns the API path of the objects of the ledger's kind in
+// its namespace.
+func (s *kubernetesStore) collectionPath() string {
+	cr := s.opts.CustomResource
+	if cr == nil {
+		return fmt.Sprintf("/api/v1/namespaces/%s/configmaps", url.PathEscape(s.opts.Namespace))
+	}
+	if s.opts.Namespace == "" {
+		return fmt.Sprintf("/apis/%s/%s", cr.APIVersion, cr.Plural)
+	}
+	return fmt.Sprintf("/apis/%s/namespaces/%s/%s", cr.APIVersion, url.PathEscape(s.opts.Namespace), cr.Plural)
+}
+
+// objectPath returns the API path of the ledger's object.
+func (s *kubernetesStore) objectPath() string {
+	return s.collectionPath() + "/" + url.PathEscape(s.opts.Name)
+}
+
+func (s *kubernetesStore) name() string {
+	kind := "ConfigMap"
+	if s.opts.CustomResource != nil {
+		kind = s.opts.CustomResource.Kind
+	}
+	if s.opts.Namespace == "" {
+		return fmt.Sprintf("Kubernetes %s %s", kind, s.opts.Name)
+	}
+	return fmt.Sprintf("Kubernetes %s %s/%s", kind, s.opts.Namespace, s.opts.Name)
+}
+
+// do sends a request to the Kubernetes API and returns the response body and
+// status. It fails unless the status is a success, with the message of the
+// Status object in the response if there is one.
+func (s *kubernetesStore) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.opts.Host+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.Token)
+	}
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(b))
+		if json.Unmarshal(b, &status) == nil && status.Message != "" {
+			message = status.Message
+		}
+		return b, resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, message)
+	}
+	return b, resp.StatusCode, nil
+}
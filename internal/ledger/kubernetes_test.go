@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKubernetes serves the requests made by the Kubernetes ledger for
+// ConfigMaps and custom resources, keyed by their collection path and name.
+type fakeKubernetes struct {
+	m       sync.Mutex
+	objects map[string]kubernetesObject
+	version int
+}
+
+func newFakeKubernetes(t *testing.T) (*fakeKubernetes, *httptest.Server) {
+	f := &fakeKubernetes{objects: map[string]kubernetesObject{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeKubernetes) status(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"kind":"Status","status":"Failure","message":%q,"code":%d}`, message, code)
+}
+
+func (f *fakeKubernetes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		f.status(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	var patch kubernetesObject
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			f.status(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	switch r.Method {
+	case http.MethodGet:
+		obj, ok := f.objects[r.URL.Path]
+		if !ok {
+			f.status(w, http.StatusNotFound, "not found")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(obj)
+	case http.MethodPost:
+		key := r.URL.Path + "/" + patch.Metadata.Name
+		if _, ok := f.objects[key]; ok {
+			f.status(w, http.StatusConflict, "already exists")
+			return
+		}
+		f.version++
+		patch.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.objects[key] = patch
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(patch)
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/merge-patch+json" {
+			f.status(w, http.StatusUnsupportedMediaType, "unsupported patch type")
+			return
+		}
+		obj, ok := f.objects[r.URL.Path]
+		if !ok {
+			f.status(w, http.StatusNotFound, "not found")
+			return
+		}
+		if patch.Metadata.ResourceVersion != "" && patch.Metadata.ResourceVersion != obj.Metadata.ResourceVersion {
+			f.status(w, http.StatusConflict, "the object has been modified")
+			return
+		}
+		for k, v := range patch.Data {
+			obj.Data[k] = v
+		}
+		if patch.Spec != nil {
+			obj.Spec = patch.Spec
+		}
+		f.version++
+		obj.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.objects[r.URL.Path] = obj
+		_ = json.NewEncoder(w).Encode(obj)
+	default:
+		f.status(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func TestKubernetesLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeKubernetes(t)
+	opts := KubernetesOptions{Host: server.URL + "/", Token: "secret", Namespace: "network", Name: "netcalc"}
+	l := NewKubernetesLedger(opts)
+	assert.Equal("Kubernetes ConfigMap network/netcalc", l.String())
+
+	testLedger(t, l, func() Ledger { return NewKubernetesLedger(opts) })
+
+	// Other data entries of the ConfigMap are left alone.
+	configMap := fake.objects["/api/v1/namespaces/network/configmaps/netcalc"]
+	configMap.Data["owner"] = "platform"
+	fake.objects["/api/v1/namespaces/network/configmaps/netcalc"] = configMap
+	assert.NoError(l.Release(ctx, netip.MustParsePrefix("10.1.0.0/24"), "0"))
+	configMap = fake.objects["/api/v1/namespaces/network/configmaps/netcalc"]
+	assert.Equal("v1", configMap.APIVersion)
+	assert.Equal("ConfigMap", configMap.Kind)
+	assert.Equal("platform", configMap.Data["owner"])
+	assert.Contains(configMap.Data["allocations.json"], `"released_at"`)
+
+	// A custom resource holds the ledger in its spec.
+	crOpts := KubernetesOptions{
+		Host:           server.URL,
+		Token:          "secret",
+		Name:           "netcalc",
+		CustomResource: &KubernetesCustomResource{APIVersion: "netcalc.example.com/v1", Kind: "SubnetLedger"},
+	}
+	crLedger := NewKubernetesLedger(crOpts)
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	assert.Equal("Kubernetes SubnetLedger netcalc", crLedger.String())
+	assert.NoError(crLedger.Allocate(ctx, prefix, "c"))
+	assert.NoError(crLedger.Allocate(ctx, netip.MustParsePrefix("10.0.1.0/24"), "c"))
+	cr := fake.objects["/apis/netcalc.example.com/v1/subnetledgers/netcalc"]
+	assert.Equal("SubnetLedger", cr.Kind)
+	var spec document
+	if assert.NoError(json.Unmarshal(cr.Spec, &spec)) {
+		assert.Len(spec.Entries, 2)
+	}
+
+	// Requests are rejected with the message of the API server.
+	opts.Token = "wrong"
+	_, err := NewKubernetesLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "Unauthorized")
+}
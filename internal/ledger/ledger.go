@@ -0,0 +1,52 @@
+// Package ledger records the allocations made by the provider in a store that
+// outlives a single Terraform run, so that allocations can be audited and
+// checked for conflicts with other systems.
+package ledger
+
+import (
+	"context"
+	"net/netip"
+	"time"
+)
+
+// Entry is an allocation recorded in a ledger. Released allocations are kept
+// as tombstones with ReleasedAt set.
+type Entry struct {
+	CIDR        netip.Prefix `json:"cidr"`
+	Owner       string       `json:"owner"`
+	AllocatedAt time.Time    `json:"allocated_at"`
+	ReleasedAt  *time.Time   `json:"released_at,omitempty"`
+}
+
+// Active reports whether the allocation has not been released.
+func (e Entry) Active() bool {
+	return e.ReleasedAt == nil
+}
+
+// Ledger is a store of allocations.
+type Ledger interface {
+	// Entries returns every entry in the ledger, including tombstones.
+	Entries(ctx context.Context) ([]Entry, error)
+	// Allocate records that owner holds the prefix. It fails if the prefix
+	// overlaps an active allocation of another prefix or owner, unless that
+	// allocation contains or is one of nested, such as the subnet a host
+	// address is claimed in, or a subnet that a supernet covers.
+	Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error
+	// Release records that owner no longer holds the prefix.
+	Release(ctx context.Context, prefix netip.Prefix, owner string) error
+}
+
+// Owner returns the owner of the active allocation of a prefix, and false if
+// the prefix is not allocated in the ledger.
+func Owner(ctx context.Context, l Ledger, prefix netip.Prefix) (string, bool, error) {
+	entries, err := l.Entries(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	for _, e := range entries {
+		if e.Active() && e.CIDR == prefix {
+			return e.Owner, true, nil
+		}
+	}
+	return "", false, nil
+}
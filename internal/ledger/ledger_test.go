@@ -0,0 +1,108 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLedger []Entry
+
+func (f fakeLedger) Entries(ctx context.Context) ([]Entry, error) {
+	return f, nil
+}
+
+func (f fakeLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	return nil
+}
+
+func (f fakeLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	return nil
+}
+
+func TestOwner(t *testing.T) {
+	assert := assert.New(t)
+	released := time.Now()
+	l := fakeLedger{
+		{CIDR: netip.MustParsePrefix("10.0.0.0/24"), Owner: "old", ReleasedAt: &released},
+		{CIDR: netip.MustParsePrefix("10.0.0.0/24"), Owner: "current"},
+		{CIDR: netip.MustParsePrefix("10.0.1.0/24"), Owner: "gone", ReleasedAt: &released},
+	}
+
+	owner, ok, err := Owner(context.Background(), l, netip.MustParsePrefix("10.0.0.0/24"))
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("current", owner)
+	}
+	_, ok, err = Owner(context.Background(), l, netip.MustParsePrefix("10.0.1.0/24"))
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+}
+
+func TestAllocateNested(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	l := NewFileLedger(filepath.Join(t.TempDir(), "ledger.json"))
+
+	subnet := netip.MustParsePrefix("10.0.0.0/24")
+	host := netip.MustParsePrefix("10.0.0.4/32")
+	assert.NoError(l.Allocate(ctx, subnet, "a"))
+	assert.ErrorContains(l.Allocate(ctx, host, "b"), `10.0.0.4/32 overlaps 10.0.0.0/24, which is allocated to "a"`)
+	assert.ErrorContains(l.Allocate(ctx, host, "b", netip.MustParsePrefix("10.0.1.0/24")), `10.0.0.4/32 overlaps 10.0.0.0/24, which is allocated to "a"`)
+	// A host address may be claimed in the subnet, but only once.
+	assert.NoError(l.Allocate(ctx, host, "b", subnet))
+	assert.ErrorContains(l.Allocate(ctx, host, "c", subnet), `10.0.0.4/32 overlaps 10.0.0.4/32, which is allocated to "b"`)
+	// A supernet may cover the subnet, but not be claimed twice.
+	supernet := netip.MustParsePrefix("10.0.0.0/22")
+	assert.NoError(l.Allocate(ctx, supernet, "c", subnet, host))
+	assert.ErrorContains(l.Allocate(ctx, supernet, "d", subnet, host), `10.0.0.0/22 overlaps 10.0.0.0/22, which is allocated to "c"`)
+}
+
+// testLedger checks the behaviour shared by ledgers kept in a store, starting
+// from an empty store. newLedger returns another handle on the store of l,
+// as a process sharing the ledger would open.
+func testLedger(t *testing.T, l Ledger, newLedger func() Ledger) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) {
+		assert.Empty(entries)
+	}
+
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.ErrorContains(newLedger().Allocate(ctx, netip.MustParsePrefix("10.0.0.0/16"), "b"), `10.0.0.0/16 overlaps 10.0.0.0/24, which is allocated to "a"`)
+	owner, ok, err := Owner(ctx, newLedger(), prefix)
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("a", owner)
+	}
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	_, ok, err = Owner(ctx, newLedger(), prefix)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+
+	// Concurrent writers either take turns or retry conflicting writes, so
+	// every allocation is recorded.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := netip.MustParsePrefix(fmt.Sprintf("10.1.%d.0/24", i))
+			assert.NoError(newLedger().Allocate(ctx, p, fmt.Sprint(i)))
+		}(i)
+	}
+	wg.Wait()
+	entries, err = l.Entries(ctx)
+	if assert.NoError(err) {
+		assert.Len(entries, 5)
+	}
+}
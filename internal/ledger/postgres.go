@@ -0,0 +1,208 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PostgresOptions configures a ledger kept in a PostgreSQL table.
+type PostgresOptions struct {
+	// Table is the name of the allocations table, optionally qualified with
+	// a schema, e.g. network.allocations. It defaults to
+	// netcalc_allocations.
+	Table string
+}
+
+// postgresTableName matches a table name, optionally qualified with a schema.
+var postgresTableName = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PostgresLedger is a ledger kept as rows of a PostgreSQL table, so
+// allocations can be reported on with SQL. Every allocation is a row with the
+// columns cidr, owner, allocated_at and released_at, and released
+// allocations are kept as rows with released_at set. The table is created if
+// it does not exist, with a unique index on the CIDR blocks of active
+// allocations.
+//
+// Allocations hold a transaction-level advisory lock while they check for
+// overlapping allocations, so concurrent Terraform runs take turns instead
+// of handing out overlapping CIDR blocks. The lock is released when the
+// transaction ends, also if Terraform is interrupted.
+type PostgresLedger struct {
+	db    *sql.DB
+	table string
+	// lockKey identifies the advisory lock of the table.
+	lockKey int64
+	// m guards created, which is set once the table is known to exist.
+	m       sync.Mutex
+	created bool
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+var _ Ledger = &PostgresLedger{}
+
+// NewPostgresLedger returns a ledger kept in a table of the PostgreSQL
+// database of db.
+func NewPostgresLedger(db *sql.DB, opts PostgresOptions) (*PostgresLedger, error) {
+	if opts.Table == "" {
+		opts.Table = "netcalc_allocations"
+	}
+	if !postgresTableName.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(opts.Table))
+	return &PostgresLedger{
+		db:      db,
+		table:   opts.Table,
+		lockKey: int64(hash.Sum64()),
+		now:     time.Now,
+	}, nil
+}
+
+// String returns a description of where the ledger is stored, for use in
+// diagnostics.
+func (l *PostgresLedger) String() string {
+	return fmt.Sprintf("PostgreSQL table %s", l.table)
+}
+
+// createTable creates the table and its index if they do not exist, once
+// per ledger.
+func (l *PostgresLedger) createTable(ctx context.Context) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.created {
+		return nil
+	}
+	name := l.table[strings.LastIndex(l.table, ".")+1:]
+	err := l.transaction(ctx, func(tx *sql.Tx) error {
+		// CREATE ... IF NOT EXISTS fails when run concurrently, so it holds
+		// the advisory lock too.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id bigserial PRIMARY KEY,
+	cidr cidr NOT NULL,
+	owner text NOT NULL,
+	allocated_at timestamptz NOT NULL,
+	released_at timestamptz
+)`, l.table)); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s_active_cidr ON %s (cidr) WHERE released_at IS NULL`, name, l.table))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", l, err)
+	}
+	l.created = true
+	return nil
+}
+
+// transaction runs f in a transaction holding the advisory lock of the
+// table, and commits it if f succeeds.
+func (l *PostgresLedger) transaction(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, l.lockKey); err != nil {
+		return fmt.Errorf("unable to lock %s: %w", l, err)
+	}
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (l *PostgresLedger) Entries(ctx context.Context) ([]Entry, error) {
+	if err := l.createTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := l.db.QueryContext(ctx, fmt.Sprintf(`SELECT cidr, owner, allocated_at, released_at FROM %s ORDER BY id`, l.table))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", l, err)
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var (
+			cidr       string
+			e          Entry
+			releasedAt sql.NullTime
+		)
+		if err := rows.Scan(&cidr, &e.Owner, &e.AllocatedAt, &releasedAt); err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", l, err)
+		}
+		if e.CIDR, err = netip.ParsePrefix(cidr); err != nil {
+			return nil, fmt.Errorf("unable to parse CIDR block %q of %s: %w", cidr, l, err)
+		}
+		e.AllocatedAt = e.AllocatedAt.UTC()
+		if releasedAt.Valid {
+			t := releasedAt.Time.UTC()
+			e.ReleasedAt = &t
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", l, err)
+	}
+	return entries, nil
+}
+
+func (l *PostgresLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	if err := l.createTable(ctx); err != nil {
+		return err
+	}
+	return l.transaction(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT cidr, owner FROM %s WHERE released_at IS NULL AND cidr && $1::cidr`, l.table), prefix.String())
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", l, err)
+		}
+		var doc document
+		for rows.Next() {
+			var cidr string
+			var e Entry
+			if err := rows.Scan(&cidr, &e.Owner); err != nil {
+				rows.Close()
+				return fmt.Errorf("unable to read %s: %w", l, err)
+			}
+			if e.CIDR, err = netip.ParsePrefix(cidr); err != nil {
+				rows.Close()
+				return fmt.Errorf("unable to parse CIDR block %q of %s: %w", cidr, l, err)
+			}
+			doc.Entries = append(doc.Entries, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("unable to read %s: %w", l, err)
+		}
+		changed, err := doc.allocate(prefix, owner, l.now(), nested)
+		if err != nil || !changed {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (cidr, owner, allocated_at) VALUES ($1, $2, $3)`, l.table), prefix.String(), owner, l.now().UTC()); err != nil {
+			return fmt.Errorf("unable to record the allocation of %s in %s: %w", prefix, l, err)
+		}
+		return nil
+	})
+}
+
+func (l *PostgresLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	if err := l.createTable(ctx); err != nil {
+		return err
+	}
+	// A single statement needs no lock, and releasing an allocation that is
+	// not in the table is a no-op.
+	_, err := l.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET released_at = $1 WHERE cidr = $2::cidr AND owner = $3 AND released_at IS NULL`, l.table), l.now().UTC(), prefix.String(), owner)
+	if err != nil {
+		return fmt.Errorf("unable to record the release of %s in %s: %w", prefix, l, err)
+	}
+	return nil
+}
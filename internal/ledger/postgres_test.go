@@ -0,0 +1,208 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("fakepostgres", &fakePostgres{databases: map[string]*fakePostgresDatabase{}})
+}
+
+// fakePostgres is a database/sql driver that runs the statements of the
+// PostgreSQL ledger against an in-memory table, with a database per data
+// source name.
+type fakePostgres struct {
+	m         sync.Mutex
+	databases map[string]*fakePostgresDatabase
+}
+
+type fakePostgresDatabase struct {
+	// lock is the advisory lock, held by a connection until the end of its
+	// transaction.
+	lock    sync.Mutex
+	m       sync.Mutex
+	creates int
+	rows    []fakePostgresRow
+}
+
+type fakePostgresRow struct {
+	cidr        netip.Prefix
+	owner       string
+	allocatedAt time.Time
+	releasedAt  *time.Time
+}
+
+func (d *fakePostgres) Open(name string) (driver.Conn, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.databases[name] == nil {
+		d.databases[name] = &fakePostgresDatabase{}
+	}
+	return &fakePostgresConn{db: d.databases[name]}, nil
+}
+
+type fakePostgresConn struct {
+	db     *fakePostgresDatabase
+	locked bool
+}
+
+func (c *fakePostgresConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakePostgresStmt{conn: c, query: query}, nil
+}
+
+func (c *fakePostgresConn) Close() error { return nil }
+
+func (c *fakePostgresConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakePostgresConn) Commit() error { return c.endTransaction() }
+
+func (c *fakePostgresConn) Rollback() error { return c.endTransaction() }
+
+func (c *fakePostgresConn) endTransaction() error {
+	if c.locked {
+		c.locked = false
+		c.db.lock.Unlock()
+	}
+	return nil
+}
+
+type fakePostgresStmt struct {
+	conn  *fakePostgresConn
+	query string
+}
+
+func (s *fakePostgresStmt) Close() error { return nil }
+
+func (s *fakePostgresStmt) NumInput() int { return -1 }
+
+func (s *fakePostgresStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	if strings.HasPrefix(s.query, "SELECT pg_advisory_xact_lock(") {
+		db.lock.Lock()
+		s.conn.locked = true
+		return driver.RowsAffected(0), nil
+	}
+	db.m.Lock()
+	defer db.m.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE "):
+		db.creates++
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT INTO netcalc_allocations (cidr, owner, allocated_at) VALUES ($1, $2, $3)"):
+		row := fakePostgresRow{cidr: netip.MustParsePrefix(args[0].(string)), owner: args[1].(string), allocatedAt: args[2].(time.Time)}
+		for _, r := range db.rows {
+			if r.releasedAt == nil && r.cidr == row.cidr {
+				return nil, errors.New(`duplicate key value violates unique constraint "netcalc_allocations_active_cidr"`)
+			}
+		}
+		db.rows = append(db.rows, row)
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "UPDATE netcalc_allocations SET released_at = $1 WHERE cidr = $2::cidr AND owner = $3 AND released_at IS NULL"):
+		releasedAt := args[0].(time.Time)
+		var n int64
+		for i, r := range db.rows {
+			if r.releasedAt == nil && r.cidr.String() == args[1] && r.owner == args[2] {
+				db.rows[i].releasedAt = &releasedAt
+				n++
+			}
+		}
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", s.query)
+}
+
+func (s *fakePostgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.m.Lock()
+	defer db.m.Unlock()
+	var values [][]driver.Value
+	switch s.query {
+	case "SELECT cidr, owner, allocated_at, released_at FROM netcalc_allocations ORDER BY id":
+		for _, r := range db.rows {
+			var releasedAt driver.Value
+			if r.releasedAt != nil {
+				releasedAt = *r.releasedAt
+			}
+			values = append(values, []driver.Value{r.cidr.String(), r.owner, r.allocatedAt, releasedAt})
+		}
+	case "SELECT cidr, owner FROM netcalc_allocations WHERE released_at IS NULL AND cidr && $1::cidr":
+		prefix := netip.MustParsePrefix(args[0].(string))
+		for _, r := range db.rows {
+			if r.releasedAt == nil && r.cidr.Overlaps(prefix) {
+				values = append(values, []driver.Value{r.cidr.String(), r.owner})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected query %q", s.query)
+	}
+	return &fakePostgresRows{values: values}, nil
+}
+
+type fakePostgresRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakePostgresRows) Columns() []string {
+	if len(r.values) == 0 {
+		return nil
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakePostgresRows) Close() error { return nil }
+
+func (r *fakePostgresRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestPostgresLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	db, err := sql.Open("fakepostgres", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	_, err = NewPostgresLedger(db, PostgresOptions{Table: "allocations; DROP TABLE users"})
+	assert.ErrorContains(err, "invalid table name")
+	l, err := NewPostgresLedger(db, PostgresOptions{})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("PostgreSQL table netcalc_allocations", l.String())
+
+	testLedger(t, l, func() Ledger {
+		l, _ := NewPostgresLedger(db, PostgresOptions{})
+		return l
+	})
+
+	// Allocating again to the same owner is a no-op, and only the owner
+	// releases an allocation.
+	prefix := netip.MustParsePrefix("10.1.0.0/24")
+	assert.NoError(l.Allocate(ctx, prefix, "0"))
+	assert.NoError(l.Release(ctx, prefix, "a"))
+	owner, ok, err := Owner(ctx, l, prefix)
+	if assert.NoError(err) && assert.True(ok) {
+		assert.Equal("0", owner)
+	}
+
+	// The table is created once per ledger.
+	assert.Equal(2*8, db.Driver().(*fakePostgres).databases[t.Name()].creates)
+}
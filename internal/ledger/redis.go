@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions configures a ledger stored in Redis.
+type RedisOptions struct {
+	// Key of the ledger document, e.g. netcalc:ledger. The lock of the
+	// ledger is held on the key with a .lock suffix.
+	Key string
+	// LockTimeout is how long to wait for a lock held by another process,
+	// one minute if zero.
+	LockTimeout time.Duration
+}
+
+// redisLockTTL is how long a lock outlives a process that died while holding
+// it.
+const redisLockTTL = 30 * time.Second
+
+// redisUnlock deletes the lock key only if it still holds the token of the
+// process releasing it, so a lock that expired and was taken by another
+// process is left alone.
+var redisUnlock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// redisStore stores the ledger document as the value of a Redis key. Writes
+// are serialized by a redisLock, so the version of the document is not used.
+type redisStore struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// redisLock is a lock held by setting a Redis key that does not exist. The
+// key expires if the process dies, which releases the lock.
+type redisLock struct {
+	client  redis.UniversalClient
+	key     string
+	timeout time.Duration
+}
+
+// NewRedisLedger returns a ledger stored in a Redis key, which is created on
+// the first write if it does not exist. Updates hold a lock set with SET NX
+// on a second key, so concurrent Terraform runs sharing the ledger take
+// turns with little latency.
+func NewRedisLedger(client redis.UniversalClient, opts RedisOptions) *RemoteLedger {
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = defaultLockTimeout
+	}
+	return &RemoteLedger{
+		name:   fmt.Sprintf("Redis key %s", opts.Key),
+		store:  &redisStore{client: client, key: opts.Key},
+		locker: &redisLock{client: client, key: opts.Key + ".lock", timeout: opts.LockTimeout},
+		now:    time.Now,
+	}
+}
+
+func (s *redisStore) load(ctx context.Context) (document, string, error) {
+	b, err := s.client.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return document{}, "", nil
+	}
+	if err != nil {
+		return document{}, "", fmt.Errorf("unable to read Redis key %s: %w", s.key, err)
+	}
+	doc, err := parseDocument(b, "Redis key "+s.key)
+	return doc, "", err
+}
+
+func (s *redisStore) save(ctx context.Context, doc document, _ string) error {
+	b, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.key, b, 0).Err(); err != nil {
+		return fmt.Errorf("unable to write Redis key %s: %w", s.key, err)
+	}
+	return nil
+}
+
+func (l *redisLock) Lock(ctx context.Context) (func(context.Context) error, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(b)
+	err := waitForLock(ctx, l.timeout, func() (bool, error) {
+		return l.client.SetNX(ctx, l.key, token, redisLockTTL).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return redisUnlock.Run(ctx, l.client, []string{l.key}, token).Err()
+	}, nil
+}
@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	opts := RedisOptions{Key: "netcalc:ledger"}
+	l := NewRedisLedger(client, opts)
+	assert.Equal("Redis key netcalc:ledger", l.String())
+
+	testLedger(t, l, func() Ledger { return NewRedisLedger(client, opts) })
+	assert.False(server.Exists("netcalc:ledger.lock"))
+
+	// A lock held by another process is waited for, and expires if the
+	// process died.
+	assert.NoError(server.Set("netcalc:ledger.lock", "other"))
+	server.SetTTL("netcalc:ledger.lock", redisLockTTL)
+	opts.LockTimeout = 300 * time.Millisecond
+	assert.ErrorContains(NewRedisLedger(client, opts).Allocate(ctx, netip.MustParsePrefix("10.2.0.0/24"), "c"), "still held by another process")
+	server.FastForward(redisLockTTL)
+	assert.NoError(NewRedisLedger(client, opts).Allocate(ctx, netip.MustParsePrefix("10.2.0.0/24"), "c"))
+
+	// Releasing a lock that expired and was taken by another process leaves
+	// it alone.
+	unlock, err := NewRedisLedger(client, opts).locker.Lock(ctx)
+	if assert.NoError(err) {
+		server.FastForward(redisLockTTL)
+		assert.NoError(server.Set("netcalc:ledger.lock", "other"))
+		assert.NoError(unlock(ctx))
+		value, _ := server.Get("netcalc:ledger.lock")
+		assert.Equal("other", value)
+	}
+}
@@ -0,0 +1,139 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// errConflict is returned by a documentStore when the document changed since
+// it was loaded.
+var errConflict = errors.New("ledger was changed concurrently")
+
+// defaultLockTimeout is how long to wait for a lock held by another process
+// when no timeout is configured.
+const defaultLockTimeout = time.Minute
+
+// maxConflictRetries is how often an update is retried after a conflicting
+// write by another process before giving up.
+const maxConflictRetries = 10
+
+// documentStore stores a ledger document in a system shared by several
+// Terraform runs.
+type documentStore interface {
+	// load returns the document, or an empty document if none exists yet,
+	// along with an opaque version of it.
+	load(ctx context.Context) (document, string, error)
+	// save replaces the document, and fails with errConflict if the document
+	// no longer has the given version. Stores that rely on a locker to
+	// serialize updates may ignore the version.
+	save(ctx context.Context, doc document, version string) error
+}
+
+// Locker is a lock shared by processes on different machines. Ledgers use one
+// to serialize updates of their document, and the provider to serialize
+// allocations of Terraform runs sharing a ledger.
+type Locker interface {
+	// Lock blocks until the lock is held or ctx is done, and returns a
+	// function that releases it.
+	Lock(ctx context.Context) (func(context.Context) error, error)
+}
+
+// RemoteLedger is a ledger stored as a single JSON document in a shared
+// system, such as an object store or a key/value store. Updates either hold
+// a lock in the shared system or are retried when another process wrote the
+// document in the meantime, so concurrent Terraform runs do not hand out
+// overlapping allocations.
+type RemoteLedger struct {
+	name   string
+	store  documentStore
+	locker Locker
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+var _ Ledger = &RemoteLedger{}
+
+// String returns a description of where the ledger is stored, for use in
+// diagnostics.
+func (l *RemoteLedger) String() string {
+	return l.name
+}
+
+func (l *RemoteLedger) Entries(ctx context.Context) ([]Entry, error) {
+	doc, _, err := l.store.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Entries, nil
+}
+
+func (l *RemoteLedger) Allocate(ctx context.Context, prefix netip.Prefix, owner string, nested ...netip.Prefix) error {
+	return l.update(ctx, func(doc *document) (bool, error) {
+		return doc.allocate(prefix, owner, l.now(), nested)
+	})
+}
+
+func (l *RemoteLedger) Release(ctx context.Context, prefix netip.Prefix, owner string) error {
+	return l.update(ctx, func(doc *document) (bool, error) {
+		return doc.release(prefix, owner, l.now()), nil
+	})
+}
+
+// update applies a change to the document, retrying on conflicting writes.
+func (l *RemoteLedger) update(ctx context.Context, change func(*document) (bool, error)) error {
+	for i := 0; i < maxConflictRetries; i++ {
+		err := l.tryUpdate(ctx, change)
+		if !errors.Is(err, errConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("unable to update %s: %w after %d attempts", l, errConflict, maxConflictRetries)
+}
+
+func (l *RemoteLedger) tryUpdate(ctx context.Context, change func(*document) (bool, error)) (err error) {
+	if l.locker != nil {
+		unlock, err := l.locker.Lock(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to lock %s: %w", l, err)
+		}
+		defer func() {
+			if unlockErr := unlock(context.WithoutCancel(ctx)); unlockErr != nil && err == nil {
+				err = fmt.Errorf("unable to unlock %s: %w", l, unlockErr)
+			}
+		}()
+	}
+	doc, version, err := l.store.load(ctx)
+	if err != nil {
+		return err
+	}
+	changed, err := change(&doc)
+	if err != nil || !changed {
+		return err
+	}
+	return l.store.save(ctx, doc, version)
+}
+
+// waitForLock calls acquire until it reports that a lock was taken, waiting
+// between attempts, and gives up when ctx is done or after timeout.
+func waitForLock(ctx context.Context, timeout time.Duration, acquire func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	delay := 100 * time.Millisecond
+	for {
+		ok, err := acquire()
+		if err != nil || ok {
+			return err
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("lock is still held by another process after %s", timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, 2*time.Second)
+	}
+}
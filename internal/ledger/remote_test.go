@@ -0,0 +1,63 @@
+package ledger
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore is a documentStore that simulates writes by other processes
+// between loading and saving the document.
+type fakeStore struct {
+	doc       document
+	version   int
+	conflicts int
+}
+
+func (s *fakeStore) load(ctx context.Context) (document, string, error) {
+	doc := document{Entries: append([]Entry(nil), s.doc.Entries...)}
+	return doc, strconv.Itoa(s.version), nil
+}
+
+func (s *fakeStore) save(ctx context.Context, doc document, version string) error {
+	if s.conflicts > 0 {
+		s.conflicts--
+		s.version++
+	}
+	if version != strconv.Itoa(s.version) {
+		return errConflict
+	}
+	s.doc = doc
+	s.version++
+	return nil
+}
+
+func TestRemoteLedgerConflicts(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	store := &fakeStore{}
+	l := &RemoteLedger{name: "fake", store: store, now: time.Now}
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+
+	// Conflicting writes are retried.
+	store.conflicts = 3
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) {
+		assert.Len(entries, 1)
+	}
+
+	// Unchanged documents are not saved.
+	version := store.version
+	assert.NoError(l.Allocate(ctx, prefix, "a"))
+	assert.NoError(l.Release(ctx, netip.MustParsePrefix("10.0.1.0/24"), "a"))
+	assert.Equal(version, store.version)
+
+	// Persistent conflicts are reported.
+	store.conflicts = maxConflictRetries
+	assert.ErrorIs(l.Release(ctx, prefix, "a"), errConflict)
+}
@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Client is the part of the S3 API used by the S3 ledger.
+type S3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Options configures a ledger stored in S3.
+type S3Options struct {
+	// Bucket and Key locate the ledger object.
+	Bucket string
+	Key    string
+	// LockTable is the DynamoDB table holding the lock. Its partition key
+	// must be the string attribute LockID, as for Terraform's S3 backend, so
+	// the same table can be used for both.
+	LockTable string
+	// LockTimeout is how long to wait for a lock held by another process,
+	// one minute if zero.
+	LockTimeout time.Duration
+}
+
+// s3Store stores the ledger document as an S3 object.
+type s3Store struct {
+	s3   S3Client
+	opts S3Options
+}
+
+// NewS3Ledger returns a ledger stored in an S3 object, which is created on
+// the first write if it does not exist. Updates hold a lock item in a
+// DynamoDB table, so concurrent Terraform runs sharing the ledger take turns.
+func NewS3Ledger(s3Client S3Client, dynamodbClient DynamoDBClient, opts S3Options) *RemoteLedger {
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = defaultLockTimeout
+	}
+	// The lock item is named after the ledger object, like the lock items
+	// of Terraform's S3 backend.
+	return &RemoteLedger{
+		name:  fmt.Sprintf("s3://%s/%s", opts.Bucket, opts.Key),
+		store: &s3Store{s3: s3Client, opts: opts},
+		locker: NewDynamoDBLock(dynamodbClient, DynamoDBLockOptions{
+			Table:   opts.LockTable,
+			LockID:  opts.Bucket + "/" + opts.Key,
+			Timeout: opts.LockTimeout,
+		}),
+		now: time.Now,
+	}
+}
+
+func (s *s3Store) load(ctx context.Context) (document, string, error) {
+	out, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.opts.Bucket),
+		Key:    aws.String(s.opts.Key),
+	})
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return document{}, "", nil
+	}
+	if err != nil {
+		return document{}, "", err
+	}
+	defer out.Body.Close()
+	b, err := io.ReadAll(out.Body)
+	if err != nil {
+		return document{}, "", err
+	}
+	doc, err := parseDocument(b, fmt.Sprintf("s3://%s/%s", s.opts.Bucket, s.opts.Key))
+	return doc, aws.ToString(out.ETag), err
+}
+
+func (s *s3Store) save(ctx context.Context, doc document, version string) error {
+	b, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	_, err = s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.opts.Bucket),
+		Key:         aws.String(s.opts.Key),
+		Body:        bytes.NewReader(b),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
@@ -0,0 +1,92 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAWS implements the S3 and DynamoDB calls used by the S3 ledger in
+// memory, including the conditional writes of the lock.
+type fakeAWS struct {
+	m       sync.Mutex
+	objects map[string][]byte
+	locks   map[string]string
+}
+
+func newFakeAWS() *fakeAWS {
+	return &fakeAWS{objects: map[string][]byte{}, locks: map[string]string{}}
+}
+
+func (f *fakeAWS) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	b, ok := f.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(b))}, nil
+}
+
+func (f *fakeAWS) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	b, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = b
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeAWS) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	id := params.Item["LockID"].(*dynamodbtypes.AttributeValueMemberS).Value
+	if _, ok := f.locks[id]; ok {
+		return nil, &dynamodbtypes.ConditionalCheckFailedException{}
+	}
+	f.locks[id] = params.Item["Info"].(*dynamodbtypes.AttributeValueMemberS).Value
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeAWS) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	id := params.Key["LockID"].(*dynamodbtypes.AttributeValueMemberS).Value
+	if f.locks[id] != params.ExpressionAttributeValues[":info"].(*dynamodbtypes.AttributeValueMemberS).Value {
+		return nil, &dynamodbtypes.ConditionalCheckFailedException{}
+	}
+	delete(f.locks, id)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func TestS3Ledger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake := newFakeAWS()
+	opts := S3Options{Bucket: "bucket", Key: "netcalc/ledger.json", LockTable: "locks", LockTimeout: 10 * time.Second}
+	l := NewS3Ledger(fake, fake, opts)
+	assert.Equal("s3://bucket/netcalc/ledger.json", l.String())
+
+	testLedger(t, l, func() Ledger { return NewS3Ledger(fake, fake, opts) })
+	assert.Empty(fake.locks)
+
+	// A lock held by another process times out.
+	fake.locks["bucket/netcalc/ledger.json"] = "other"
+	impatient := opts
+	impatient.LockTimeout = 200 * time.Millisecond
+	assert.ErrorContains(NewS3Ledger(fake, fake, impatient).Allocate(ctx, netip.MustParsePrefix("10.2.0.0/24"), "b"), "still held")
+	delete(fake.locks, "bucket/netcalc/ledger.json")
+}
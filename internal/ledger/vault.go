@@ -0,0 +1,149 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VaultOptions configures a ledger stored in a Vault KV version 2 secret.
+type VaultOptions struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200.
+	Address string
+	// Token authenticates the requests.
+	Token string
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string
+	// Mount is the path the KV secrets engine is mounted at, secret if
+	// empty.
+	Mount string
+	// Path of the secret within the mount, e.g. netcalc/ledger.
+	Path string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// vaultStore stores the ledger document as the data of a Vault KV version 2
+// secret, which is written with check-and-set.
+type vaultStore struct {
+	opts VaultOptions
+}
+
+// vaultSecret is the response to reading a KV version 2 secret.
+type vaultSecret struct {
+	Data struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata struct {
+			Version uint64 `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+// NewVaultLedger returns a ledger stored in a Vault KV version 2 secret,
+// which is created on the first write if it does not exist. Every write is a
+// check-and-set against the version that was read, and is retried when
+// another Terraform run wrote the secret in the meantime, so concurrent runs
+// do not hand out overlapping allocations.
+func NewVaultLedger(opts VaultOptions) *RemoteLedger {
+	opts.Address = strings.TrimSuffix(opts.Address, "/")
+	opts.Mount = strings.Trim(opts.Mount, "/")
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	opts.Path = strings.Trim(opts.Path, "/")
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &RemoteLedger{
+		name:  fmt.Sprintf("Vault secret %s/%s", opts.Mount, opts.Path),
+		store: &vaultStore{opts: opts},
+		now:   time.Now,
+	}
+}
+
+func (s *vaultStore) load(ctx context.Context) (document, string, error) {
+	b, status, err := s.do(ctx, http.MethodGet, nil)
+	if status == http.StatusNotFound {
+		// The secret does not exist, or its current version was deleted, in
+		// which case the response still has the metadata of that version. A
+		// check-and-set with version 0 only writes a secret that does not
+		// exist.
+		var secret vaultSecret
+		_ = json.Unmarshal(b, &secret)
+		return document{}, strconv.FormatUint(secret.Data.Metadata.Version, 10), nil
+	}
+	if err != nil {
+		return document{}, "", err
+	}
+	var secret vaultSecret
+	if err := json.Unmarshal(b, &secret); err != nil {
+		return document{}, "", fmt.Errorf("unable to parse Vault secret %s/%s: %w", s.opts.Mount, s.opts.Path, err)
+	}
+	version := strconv.FormatUint(secret.Data.Metadata.Version, 10)
+	if len(secret.Data.Data) == 0 || string(secret.Data.Data) == "null" {
+		return document{}, version, nil
+	}
+	doc, err := parseDocument(secret.Data.Data, fmt.Sprintf("Vault secret %s/%s", s.opts.Mount, s.opts.Path))
+	return doc, version, err
+}
+
+func (s *vaultStore) save(ctx context.Context, doc document, version string) error {
+	data, err := doc.marshal()
+	if err != nil {
+		return err
+	}
+	cas, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{
+		"options": map[string]uint64{"cas": cas},
+		"data":    json.RawMessage(data),
+	})
+	if err != nil {
+		return err
+	}
+	b, status, err := s.do(ctx, http.MethodPut, body)
+	if status == http.StatusBadRequest && strings.Contains(string(b), "check-and-set") {
+		return errConflict
+	}
+	return err
+}
+
+// do sends a request for the secret to the Vault HTTP API and returns the
+// response body and status. It fails unless the status is a success.
+func (s *vaultStore) do(ctx context.Context, method string, body []byte) ([]byte, int, error) {
+	path := fmt.Sprintf("/v1/%s/data/%s", s.opts.Mount, s.opts.Path)
+	req, err := http.NewRequestWithContext(ctx, method, s.opts.Address+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.opts.Token != "" {
+		req.Header.Set("X-Vault-Token", s.opts.Token)
+	}
+	if s.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.opts.Namespace)
+	}
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return b, resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, resp.StatusCode, nil
+}
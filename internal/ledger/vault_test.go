@@ -0,0 +1,100 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault serves the KV version 2 endpoints used by the Vault ledger, for
+// a secrets engine mounted at secret.
+type fakeVault struct {
+	m        sync.Mutex
+	versions map[string][]json.RawMessage
+	// deleted is the path whose current version was deleted, if any.
+	deleted string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	f := &fakeVault{versions: map[string][]json.RawMessage{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("X-Vault-Token") != "secret" || r.Header.Get("X-Vault-Namespace") != "team" {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	versions := f.versions[path]
+	switch r.Method {
+	case http.MethodGet:
+		if len(versions) == 0 || f.deleted == path {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"data":{"data":null,"metadata":{"version":%d}}}`, len(versions))
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"data":%s,"metadata":{"version":%d}}}`, versions[len(versions)-1], len(versions))
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Options.CAS != nil && *req.Options.CAS != len(versions) {
+			http.Error(w, `{"errors":["check-and-set parameter did not match the current version"]}`, http.StatusBadRequest)
+			return
+		}
+		f.versions[path] = append(versions, req.Data)
+		f.deleted = ""
+		fmt.Fprintf(w, `{"data":{"version":%d}}`, len(f.versions[path]))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestVaultLedger(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	fake, server := newFakeVault(t)
+	opts := VaultOptions{Address: server.URL + "/", Token: "secret", Namespace: "team", Path: "/netcalc/ledger"}
+	l := NewVaultLedger(opts)
+	assert.Equal("Vault secret secret/netcalc/ledger", l.String())
+
+	testLedger(t, l, func() Ledger { return NewVaultLedger(opts) })
+	assert.Len(fake.versions["netcalc/ledger"], 6)
+
+	// A deleted secret is written again as a new version.
+	fake.deleted = "netcalc/ledger"
+	assert.NoError(l.Allocate(ctx, netip.MustParsePrefix("10.0.0.0/24"), "c"))
+	entries, err := l.Entries(ctx)
+	if assert.NoError(err) && assert.Len(entries, 1) {
+		assert.Equal("c", entries[0].Owner)
+	}
+
+	// Requests are rejected with a useful error.
+	opts.Token = "wrong"
+	_, err = NewVaultLedger(opts).Entries(ctx)
+	assert.ErrorContains(err, "permission denied")
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AddressClassDataSource{}
+
+func NewAddressClassDataSource() datasource.DataSource {
+	return &AddressClassDataSource{}
+}
+
+// AddressClassDataSource defines the data source implementation.
+type AddressClassDataSource struct {
+}
+
+// AddressClassDataSourceModel describes the data source data model.
+type AddressClassDataSourceModel struct {
+	Address       types.String `tfsdk:"address"`
+	IPFamily      types.String `tfsdk:"ip_family"`
+	Classes       types.List   `tfsdk:"classes"`
+	Private       types.Bool   `tfsdk:"private"`
+	CGNAT         types.Bool   `tfsdk:"cgnat"`
+	Loopback      types.Bool   `tfsdk:"loopback"`
+	LinkLocal     types.Bool   `tfsdk:"link_local"`
+	Multicast     types.Bool   `tfsdk:"multicast"`
+	Documentation types.Bool   `tfsdk:"documentation"`
+	Benchmarking  types.Bool   `tfsdk:"benchmarking"`
+	UniqueLocal   types.Bool   `tfsdk:"unique_local"`
+	Unspecified   types.Bool   `tfsdk:"unspecified"`
+	Reserved      types.Bool   `tfsdk:"reserved"`
+	GlobalUnicast types.Bool   `tfsdk:"global_unicast"`
+	ID            types.String `tfsdk:"id"`
+}
+
+func (d *AddressClassDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_address_class"
+}
+
+func (d *AddressClassDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Address class data source. Classifies an address or CIDR block by the special-purpose ranges it is in, such as private, CGNAT, link-local or documentation ranges, e.g. for preconditions that keep public addresses out of internal allocations.",
+
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address or CIDR block to classify, e.g. `10.0.1.4` or `10.0.1.0/24`. A CIDR block is only in a class if it lies entirely within the class's ranges.",
+				Required:            true,
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "IP family of the address, either ipv4 or ipv6.",
+				Computed:            true,
+			},
+			"classes": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the classes the address is in, matching the boolean attributes that are true. Empty if a CIDR block spans several ranges.",
+				Computed:            true,
+			},
+			subnet.ClassPrivate: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is in an RFC 1918 private range: 10.0.0.0/8, 172.16.0.0/12 or 192.168.0.0/16.",
+				Computed:            true,
+			},
+			subnet.ClassCGNAT: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is in the RFC 6598 shared address space used by carrier-grade NAT, 100.64.0.0/10.",
+				Computed:            true,
+			},
+			subnet.ClassLoopback: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is a loopback address, in 127.0.0.0/8 or ::1.",
+				Computed:            true,
+			},
+			subnet.ClassLinkLocal: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is link-local, in 169.254.0.0/16 or fe80::/10.",
+				Computed:            true,
+			},
+			subnet.ClassMulticast: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is multicast, in 224.0.0.0/4 or ff00::/8.",
+				Computed:            true,
+			},
+			subnet.ClassDocumentation: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is reserved for documentation, in 192.0.2.0/24, 198.51.100.0/24, 203.0.113.0/24, 2001:db8::/32 or 3fff::/20.",
+				Computed:            true,
+			},
+			subnet.ClassBenchmarking: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is reserved for benchmarking, in 198.18.0.0/15 or 2001:2::/48.",
+				Computed:            true,
+			},
+			subnet.ClassUniqueLocal: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is an IPv6 unique local address, in fc00::/7.",
+				Computed:            true,
+			},
+			subnet.ClassUnspecified: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is unspecified, in 0.0.0.0/8 or ::.",
+				Computed:            true,
+			},
+			subnet.ClassReserved: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is in another special-purpose range, such as 240.0.0.0/4, IPv4-mapped IPv6 addresses or the NAT64 well-known prefix.",
+				Computed:            true,
+			},
+			subnet.ClassGlobalUnicast: schema.BoolAttribute{
+				MarkdownDescription: "Whether the address is a globally routable unicast address: outside every special-purpose range, and for IPv6 within 2000::/3.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *AddressClassDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AddressClassDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := parseAddressOrPrefix(data.Address.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("address"), "Address parsing error", fmt.Sprintf("Unable to parse address or CIDR: %q, %v", data.Address.ValueString(), err))
+		return
+	}
+
+	classes := subnet.Classify(prefix)
+	is := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		is[class] = true
+	}
+	classList, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, classes...))
+	resp.Diagnostics.Append(diags...)
+	data.Classes = classList
+	data.Private = types.BoolValue(is[subnet.ClassPrivate])
+	data.CGNAT = types.BoolValue(is[subnet.ClassCGNAT])
+	data.Loopback = types.BoolValue(is[subnet.ClassLoopback])
+	data.LinkLocal = types.BoolValue(is[subnet.ClassLinkLocal])
+	data.Multicast = types.BoolValue(is[subnet.ClassMulticast])
+	data.Documentation = types.BoolValue(is[subnet.ClassDocumentation])
+	data.Benchmarking = types.BoolValue(is[subnet.ClassBenchmarking])
+	data.UniqueLocal = types.BoolValue(is[subnet.ClassUniqueLocal])
+	data.Unspecified = types.BoolValue(is[subnet.ClassUnspecified])
+	data.Reserved = types.BoolValue(is[subnet.ClassReserved])
+	data.GlobalUnicast = types.BoolValue(is[subnet.ClassGlobalUnicast])
+	data.IPFamily = types.StringValue(ipFamilyIPv4)
+	if prefix.Addr().Is6() {
+		data.IPFamily = types.StringValue(ipFamilyIPv6)
+	}
+	data.ID = data.Address
+
+	tflog.Trace(ctx, "read an address class data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
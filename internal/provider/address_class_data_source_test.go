@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAddressClassDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_address_class" "private" {
+					address = "10.1.2.0/24"
+				}
+				data "netcalc_address_class" "public" {
+					address = "8.8.8.8"
+				}
+				data "netcalc_address_class" "ula" {
+					address = "fd12:3456::/64"
+				}
+				data "netcalc_address_class" "mixed" {
+					address = "0.0.0.0/0"
+				}
+				output "mixed_classes" {
+					value = length(data.netcalc_address_class.mixed.classes)
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "id", "10.1.2.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "ip_family", "ipv4"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "private", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "global_unicast", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "classes.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.private", "classes.0", "private"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.public", "private", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.public", "global_unicast", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.ula", "ip_family", "ipv6"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.ula", "unique_local", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.mixed", "private", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_address_class.mixed", "global_unicast", "false"),
+					resource.TestCheckOutput("mixed_classes", "0"),
+				),
+			},
+		},
+	})
+}
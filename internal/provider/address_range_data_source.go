@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AddressRangeDataSource{}
+
+func NewAddressRangeDataSource() datasource.DataSource {
+	return &AddressRangeDataSource{}
+}
+
+// AddressRangeDataSource defines the data source implementation.
+type AddressRangeDataSource struct {
+}
+
+// AddressRangeDataSourceModel describes the data source data model.
+type AddressRangeDataSourceModel struct {
+	FirstAddress types.String `tfsdk:"first_address"`
+	LastAddress  types.String `tfsdk:"last_address"`
+	CIDRBlock    types.String `tfsdk:"cidr_block"`
+	CIDRBlocks   types.List   `tfsdk:"cidr_blocks"`
+	AddressCount types.Number `tfsdk:"address_count"`
+	ID           types.String `tfsdk:"id"`
+}
+
+func (d *AddressRangeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_address_range"
+}
+
+func (d *AddressRangeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Address range data source. Converts between an address range and CIDR blocks: a range given by its first and last address is split into the minimal list of CIDR blocks, and a CIDR block is expanded into its first and last address, e.g. for firewall rules and DHCP scopes that expect the other form.",
+
+		Attributes: map[string]schema.Attribute{
+			"first_address": schema.StringAttribute{
+				MarkdownDescription: "First address of the range. Must be set together with last_address, unless cidr_block is set, in which case it is the first address of the CIDR block.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("cidr_block")),
+					stringvalidator.AlsoRequires(path.MatchRoot("last_address")),
+				},
+			},
+			"last_address": schema.StringAttribute{
+				MarkdownDescription: "Last address of the range, inclusive. Must be of the same IP family as first_address and not before it. When cidr_block is set, the last address of the CIDR block.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("first_address")),
+				},
+			},
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to expand. Exactly one of cidr_block or first_address must be set. When the range is set instead, the CIDR block exactly covering it, or null if it takes more than one CIDR block.",
+				Optional:            true,
+				Computed:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Minimal list of CIDR blocks covering exactly the range, in address order.",
+				Computed:            true,
+			},
+			"address_count": schema.NumberAttribute{
+				MarkdownDescription: "Number of addresses in the range.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the range in the form `first-last`.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *AddressRangeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AddressRangeDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var addressRange subnet.AddressRange
+	if !data.CIDRBlock.IsNull() {
+		prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+			return
+		}
+		prefix = prefix.Masked()
+		addressRange = subnet.AddressRange{Start: prefix.Addr(), End: subnet.LastAddr(prefix)}
+	} else {
+		first, err := netip.ParseAddr(data.FirstAddress.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("first_address"), "Address parsing error", fmt.Sprintf("Unable to parse address: %q, %v", data.FirstAddress.ValueString(), err))
+		}
+		last, err := netip.ParseAddr(data.LastAddress.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("last_address"), "Address parsing error", fmt.Sprintf("Unable to parse address: %q, %v", data.LastAddress.ValueString(), err))
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		first, last = first.Unmap(), last.Unmap()
+		if first.Is4() != last.Is4() || first.Compare(last) > 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("last_address"), "Invalid address range", fmt.Sprintf("Last address %s must be of the same IP family as first address %s and not before it.", last, first))
+			return
+		}
+		addressRange = subnet.AddressRange{Start: first, End: last}
+	}
+
+	prefixes := addressRange.Prefixes()
+	cidrBlocks, diags := prefixList(ctx, prefixes)
+	resp.Diagnostics.Append(diags...)
+	data.CIDRBlocks = cidrBlocks
+	// Configured values are kept as given, only the missing form is filled in.
+	if data.CIDRBlock.IsNull() {
+		if len(prefixes) == 1 {
+			data.CIDRBlock = types.StringValue(prefixes[0].String())
+		}
+		data.ID = types.StringValue(data.FirstAddress.ValueString() + "-" + data.LastAddress.ValueString())
+	} else {
+		data.FirstAddress = types.StringValue(addressRange.Start.String())
+		data.LastAddress = types.StringValue(addressRange.End.String())
+		data.ID = types.StringValue(addressRange.String())
+	}
+	data.AddressCount = types.NumberValue(new(big.Float).SetInt(addressRange.Size()))
+
+	tflog.Trace(ctx, "read an address range data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
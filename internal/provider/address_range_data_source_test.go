@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAddressRangeDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Range validation
+			{
+				Config: `
+				data "netcalc_address_range" "reversed" {
+					first_address = "10.0.0.9"
+					last_address  = "10.0.0.1"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid\s+address\s+range`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_address_range" "range" {
+					first_address = "10.0.1.10"
+					last_address  = "10.0.1.99"
+				}
+				data "netcalc_address_range" "aligned" {
+					first_address = "fd00::"
+					last_address  = "fd00::ff"
+				}
+				data "netcalc_address_range" "cidr" {
+					cidr_block = "10.0.2.0/24"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "id", "10.0.1.10-10.0.1.99"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.#", "6"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.0", "10.0.1.10/31"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.1", "10.0.1.12/30"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.2", "10.0.1.16/28"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.3", "10.0.1.32/27"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.4", "10.0.1.64/27"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "cidr_blocks.5", "10.0.1.96/30"),
+					resource.TestCheckNoResourceAttr("data.netcalc_address_range.range", "cidr_block"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.range", "address_count", "90"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.aligned", "cidr_block", "fd00::/120"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.aligned", "cidr_blocks.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.cidr", "first_address", "10.0.2.0"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.cidr", "last_address", "10.0.2.255"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.cidr", "cidr_blocks.0", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.cidr", "address_count", "256"),
+					resource.TestCheckResourceAttr("data.netcalc_address_range.cidr", "id", "10.0.2.0-10.0.2.255"),
+				),
+			},
+		},
+	})
+}
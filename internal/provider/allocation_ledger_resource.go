@@ -0,0 +1,297 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AllocationLedgerResource{}
+var _ resource.ResourceWithImportState = &AllocationLedgerResource{}
+var _ resource.ResourceWithConfigure = &AllocationLedgerResource{}
+var _ resource.ResourceWithModifyPlan = &AllocationLedgerResource{}
+
+func NewAllocationLedgerResource() resource.Resource {
+	return &AllocationLedgerResource{}
+}
+
+// AllocationLedgerResource defines the resource implementation.
+type AllocationLedgerResource struct {
+	ledger ledger.Ledger
+}
+
+// AllocationLedgerResourceModel describes the resource data model.
+type AllocationLedgerResourceModel struct {
+	Path             types.String `tfsdk:"path"`
+	Entries          types.List   `tfsdk:"entries"`
+	ActiveCIDRBlocks types.Set    `tfsdk:"active_cidr_blocks"`
+	ID               types.String `tfsdk:"id"`
+}
+
+// ledgerEntryModel describes an entry of the ledger.
+type ledgerEntryModel struct {
+	CIDRBlock   types.String `tfsdk:"cidr_block"`
+	Owner       types.String `tfsdk:"owner"`
+	AllocatedAt types.String `tfsdk:"allocated_at"`
+	ReleasedAt  types.String `tfsdk:"released_at"`
+}
+
+var ledgerEntryAttrTypes = map[string]attr.Type{
+	"cidr_block":   types.StringType,
+	"owner":        types.StringType,
+	"allocated_at": types.StringType,
+	"released_at":  types.StringType,
+}
+
+func (r *AllocationLedgerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_allocation_ledger"
+}
+
+func (r *AllocationLedgerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Allocation ledger resource. Creates a JSON file that records every allocation made by the provider, so allocations survive across Terraform states and can be audited. Allocations are recorded once the provider's `ledger_path` is set to the ledger's path. Without a `path`, the resource exposes the ledger configured in the provider instead, such as `ledger_s3` or `ledger_postgres`. Destroying the resource leaves the ledger in place.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the ledger file. An existing ledger file at this path is adopted. When not set, the entries of the ledger configured in the provider are read, which must then be configured.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"entries": schema.ListNestedAttribute{
+				MarkdownDescription: "Every allocation recorded in the ledger, in the order they were made. Released allocations are kept with `released_at` set.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"cidr_block": schema.StringAttribute{
+							MarkdownDescription: "Allocated CIDR block.",
+							Computed:            true,
+						},
+						"owner": schema.StringAttribute{
+							MarkdownDescription: "Opaque identifier of the resource holding the allocation.",
+							Computed:            true,
+						},
+						"allocated_at": schema.StringAttribute{
+							MarkdownDescription: "RFC 3339 timestamp of the allocation.",
+							Computed:            true,
+						},
+						"released_at": schema.StringAttribute{
+							MarkdownDescription: "RFC 3339 timestamp of the release, or null while the CIDR block is allocated.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"active_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks that are currently allocated.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, same as the path, or `provider` for the ledger configured in the provider.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan warns when the provider would not record its allocations in the
+// ledger being created.
+func (r *AllocationLedgerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan AllocationLedgerResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Path.IsUnknown() || plan.Path.IsNull() {
+		return
+	}
+	if fileLedger, ok := r.ledger.(*ledger.FileLedger); ok && fileLedger.Path() == plan.Path.ValueString() {
+		return
+	}
+	resp.Diagnostics.AddAttributeWarning(
+		path.Root("path"),
+		"Ledger not configured in provider",
+		fmt.Sprintf("Allocations are only recorded in the ledger once the provider's ledger_path is set to %q.", plan.Path.ValueString()),
+	)
+}
+
+func (r *AllocationLedgerResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.ledger = data.ledger
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *AllocationLedgerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AllocationLedgerResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, diags := r.ledgerOf(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(providerLedgerID)
+	if fileLedger, ok := l.(*ledger.FileLedger); ok && !data.Path.IsNull() {
+		if err := fileLedger.Init(ctx); err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to create the allocation ledger: %v", err))
+			return
+		}
+		data.ID = data.Path
+	}
+	resp.Diagnostics.Append(readLedgerEntries(ctx, l, &data)...)
+	tflog.Info(ctx, "created an allocation ledger resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AllocationLedgerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AllocationLedgerResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, diags := r.ledgerOf(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if fileLedger, ok := l.(*ledger.FileLedger); ok && !data.Path.IsNull() {
+		exists, err := fileLedger.Exists()
+		if err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+			return
+		}
+		if !exists {
+			tflog.Info(ctx, "ledger file no longer exists; removing resource in order to indicate it needs to be created")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	}
+	resp.Diagnostics.Append(readLedgerEntries(ctx, l, &data)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AllocationLedgerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan AllocationLedgerResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The path requires replacement, so only the entries need refreshing.
+	l, diags := r.ledgerOf(plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(readLedgerEntries(ctx, l, &plan)...)
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AllocationLedgerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// The ledger is kept so the allocation history is not lost.
+	tflog.Info(ctx, "deleted an allocation ledger resource")
+}
+
+func (r *AllocationLedgerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID != providerLedgerID {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("path"), req.ID)...)
+	}
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	tflog.Info(ctx, "imported an allocation ledger resource")
+}
+
+// providerLedgerID is the ID of a resource exposing the ledger configured in
+// the provider.
+const providerLedgerID = "provider"
+
+// ledgerOf returns the ledger file at the path of the model, or the ledger
+// configured in the provider if the path is not set.
+func (r *AllocationLedgerResource) ledgerOf(data AllocationLedgerResourceModel) (ledger.Ledger, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	if !data.Path.IsNull() {
+		return ledger.NewFileLedger(data.Path.ValueString()), diagnostics
+	}
+	if r.ledger == nil {
+		diagnostics.AddAttributeError(path.Root("path"), "Ledger not configured in provider", "Set path, or configure a ledger in the provider, such as ledger_path or ledger_s3.")
+		return nil, diagnostics
+	}
+	return r.ledger, diagnostics
+}
+
+// readLedgerEntries saves the ledger's entries into the model.
+func readLedgerEntries(ctx context.Context, l ledger.Ledger, data *AllocationLedgerResourceModel) (diagnostics diag.Diagnostics) {
+	entries, err := l.Entries(ctx)
+	if err != nil {
+		diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+		return diagnostics
+	}
+
+	entryModels := []ledgerEntryModel{}
+	active := []string{}
+	for _, e := range entries {
+		entry := ledgerEntryModel{
+			CIDRBlock:   types.StringValue(e.CIDR.String()),
+			Owner:       types.StringValue(e.Owner),
+			AllocatedAt: types.StringValue(e.AllocatedAt.Format(time.RFC3339)),
+			ReleasedAt:  types.StringNull(),
+		}
+		if e.Active() {
+			active = append(active, e.CIDR.String())
+		} else {
+			entry.ReleasedAt = types.StringValue(e.ReleasedAt.Format(time.RFC3339))
+		}
+		entryModels = append(entryModels, entry)
+	}
+
+	val, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ledgerEntryAttrTypes}, entryModels)
+	diagnostics.Append(diags...)
+	data.Entries = val
+	activeVal, diags := types.SetValueFrom(ctx, types.StringType, active)
+	diagnostics.Append(diags...)
+	data.ActiveCIDRBlocks = activeVal
+	return diagnostics
+}
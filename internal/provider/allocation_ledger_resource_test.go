@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccAllocationLedgerResource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				// Simulate an allocation made from another Terraform state.
+				PreConfig: func() {
+					if err := ledger.NewFileLedger(path).Allocate(context.Background(), netip.MustParsePrefix("10.0.0.0/24"), "elsewhere"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccAllocationLedgerResourceConfig(path, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					depends_on       = [netcalc_allocation_ledger.test]
+				}
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					cidr_mask_length = 24
+					cidr_count       = 1
+					depends_on       = [netcalc_subnet.test]
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "id", path),
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "entries.#", "1"),
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "entries.0.owner", "elsewhere"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.0.2.0/24"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(path), "10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "netcalc_allocation_ledger.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"entries", "active_cidr_blocks"},
+			},
+			// Releasing allocations leaves tombstones
+			{
+				Config: testAccAllocationLedgerResourceConfig(path, ""),
+				Check:  testAccCheckLedgerActive(ledger.NewFileLedger(path), "10.0.0.0/24"),
+			},
+			// The entries are refreshed on read
+			{
+				Config: testAccAllocationLedgerResourceConfig(path, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "entries.#", "3"),
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "active_cidr_blocks.#", "1"),
+					resource.TestCheckResourceAttrSet("netcalc_allocation_ledger.test", "entries.1.released_at"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccAllocationLedgerResourceNotConfigured(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+}
+
+resource "netcalc_allocation_ledger" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Set\s+path,\s+or\s+configure\s+a\s+ledger\s+in\s+the\s+provider`),
+			},
+		},
+	})
+}
+
+func testAccAllocationLedgerResourceConfig(path string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  ledger_path      = %[1]q
+}
+
+resource "netcalc_allocation_ledger" "test" {
+  path = %[1]q
+}
+%[2]s
+`, path, resources)
+}
+
+// testAccCheckLedgerActive checks the CIDR blocks that are active in a ledger.
+func testAccCheckLedgerActive(l ledger.Ledger, cidrBlocks ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		entries, err := l.Entries(context.Background())
+		if err != nil {
+			return err
+		}
+		var active []string
+		for _, e := range entries {
+			if e.Active() {
+				active = append(active, e.CIDR.String())
+			}
+		}
+		if fmt.Sprint(active) != fmt.Sprint(cidrBlocks) {
+			return fmt.Errorf("expected active CIDR blocks %v, got %v", cidrBlocks, active)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// lockDynamoDBModel describes the lock_dynamodb provider attribute.
+type lockDynamoDBModel struct {
+	Table    types.String `tfsdk:"table"`
+	LockID   types.String `tfsdk:"lock_id"`
+	Region   types.String `tfsdk:"region"`
+	Profile  types.String `tfsdk:"profile"`
+	Endpoint types.String `tfsdk:"endpoint"`
+}
+
+// lockConsulModel describes the lock_consul provider attribute.
+type lockConsulModel struct {
+	Address    types.String `tfsdk:"address"`
+	Token      types.String `tfsdk:"token"`
+	Datacenter types.String `tfsdk:"datacenter"`
+	Key        types.String `tfsdk:"key"`
+}
+
+// lockAzureBlobModel describes the lock_azure_blob provider attribute.
+type lockAzureBlobModel struct {
+	StorageAccountName types.String `tfsdk:"storage_account_name"`
+	ContainerName      types.String `tfsdk:"container_name"`
+	BlobName           types.String `tfsdk:"blob_name"`
+	SASToken           types.String `tfsdk:"sas_token"`
+	Endpoint           types.String `tfsdk:"endpoint"`
+}
+
+// lockDescription is the part of the description shared by the lock
+// attributes.
+const lockDescription = "Resources that record allocations in the ledger hold the lock while they allocate or release CIDR blocks, and see the allocations other Terraform runs recorded in the ledger in the meantime, so concurrent applies in different workspaces sharing a ledger cannot hand out the same CIDR blocks."
+
+func lockDynamoDBAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Serializes allocations with a lock item in a DynamoDB table. " + lockDescription + " Credentials are taken from the usual AWS environment variables, shared configuration files and instance roles.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Name of the DynamoDB table holding the lock. Its partition key must be the string attribute `LockID`, as for Terraform's S3 backend, so the same table can be used for both.",
+				Required:            true,
+			},
+			"lock_id": schema.StringAttribute{
+				MarkdownDescription: "`LockID` of the lock item. Runs share the lock when they use the same ID. Defaults to `netcalc`.",
+				Optional:            true,
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "AWS region of the table. Defaults to the region of the AWS configuration.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the AWS shared configuration profile to use.",
+				Optional:            true,
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom DynamoDB endpoint URL.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			lockConflictsValidator(),
+		},
+	}
+}
+
+func lockConsulAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Serializes allocations with a lock acquired on a Consul key with a session, which expires if Terraform is interrupted. " + lockDescription,
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address of the Consul agent, e.g. `consul.example.com:8500` or `https://consul.example.com`. Defaults to the `CONSUL_HTTP_ADDR` environment variable, or `127.0.0.1:8500`.",
+				Optional:            true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "ACL token. Defaults to the `CONSUL_HTTP_TOKEN` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"datacenter": schema.StringAttribute{
+				MarkdownDescription: "Datacenter of the key. Defaults to the datacenter of the agent.",
+				Optional:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key the lock is held on, e.g. `netcalc/allocation.lock`. It must differ from the lock key of a ledger in Consul, which is the ledger key with a `.lock` suffix.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			lockConflictsValidator(),
+		},
+	}
+}
+
+func lockAzureBlobAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Serializes allocations with a lease on an Azure Storage blob, which expires if Terraform is interrupted. " + lockDescription + " Requests are authorized with a SAS token, or otherwise with Microsoft Entra ID credentials taken from the environment, a managed identity or the Azure CLI.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the storage account.",
+				Required:            true,
+			},
+			"container_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the container of the blob, which must exist.",
+				Required:            true,
+			},
+			"blob_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the blob that is leased. It is created empty if it does not exist. Defaults to `netcalc.lock`.",
+				Optional:            true,
+			},
+			"sas_token": schema.StringAttribute{
+				MarkdownDescription: "SAS token authorizing writes to the blob. Defaults to the `AZURE_STORAGE_SAS_TOKEN` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "Blob service endpoint URL. Defaults to `https://<storage_account_name>.blob.core.windows.net`.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			lockConflictsValidator(),
+		},
+	}
+}
+
+// lockAttributes are the provider attributes that configure an allocation
+// lock, of which at most one may be set.
+var lockAttributes = []string{"lock_dynamodb", "lock_consul", "lock_azure_blob"}
+
+// lockConflictsValidator rejects lock attributes set alongside another lock
+// attribute.
+func lockConflictsValidator() validator.Object {
+	var expressions []path.Expression
+	for _, name := range lockAttributes {
+		expressions = append(expressions, path.MatchRoot(name))
+	}
+	return objectvalidator.ConflictsWith(expressions...)
+}
+
+// newLocker returns the lock configured by one of the lock attributes, and
+// the path of that attribute for diagnostics, or nil if no lock is
+// configured.
+func newLocker(ctx context.Context, data SubnetCalculatorProviderModel, diagnostics *diag.Diagnostics) (ledger.Locker, path.Path) {
+	switch {
+	case !data.LockDynamoDB.IsNull():
+		var lockData lockDynamoDBModel
+		diagnostics.Append(data.LockDynamoDB.As(ctx, &lockData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("lock_dynamodb")
+		}
+		cfg, err := loadAWSConfig(ctx, lockData.Region.ValueString(), lockData.Profile.ValueString())
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("lock_dynamodb"), "Lock error", fmt.Sprintf("Unable to load AWS configuration: %v", err))
+			return nil, path.Root("lock_dynamodb")
+		}
+		client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+			if endpoint := lockData.Endpoint.ValueString(); endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+			}
+		})
+		lockID := lockData.LockID.ValueString()
+		if lockID == "" {
+			lockID = "netcalc"
+		}
+		return ledger.NewDynamoDBLock(client, ledger.DynamoDBLockOptions{
+			Table:  lockData.Table.ValueString(),
+			LockID: lockID,
+		}), path.Root("lock_dynamodb")
+	case !data.LockConsul.IsNull():
+		var lockData lockConsulModel
+		diagnostics.Append(data.LockConsul.As(ctx, &lockData, basetypes.ObjectAsOptions{})...)
+		return ledger.NewConsulLock(consulOptions(lockData.Address, lockData.Token, lockData.Datacenter, lockData.Key)), path.Root("lock_consul")
+	case !data.LockAzureBlob.IsNull():
+		var lockData lockAzureBlobModel
+		diagnostics.Append(data.LockAzureBlob.As(ctx, &lockData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("lock_azure_blob")
+		}
+		blobName := lockData.BlobName.ValueString()
+		if blobName == "" {
+			blobName = "netcalc.lock"
+		}
+		sasToken, credential := azureStorageAuth(lockData.SASToken, path.Root("lock_azure_blob"), "Lock error", diagnostics)
+		if diagnostics.HasError() {
+			return nil, path.Root("lock_azure_blob")
+		}
+		opts := ledger.AzureBlobLockOptions{
+			URL:        azureBlobURL(lockData.Endpoint, lockData.StorageAccountName, lockData.ContainerName, blobName),
+			SASToken:   sasToken,
+			Credential: credential,
+		}
+		return ledger.NewAzureBlobLock(opts), path.Root("lock_azure_blob")
+	}
+	return nil, path.Empty()
+}
+
+func sharedLedgerAttribute() schema.Attribute {
+	return schema.BoolAttribute{
+		MarkdownDescription: "Whether this provider block coordinates its allocations with other provider blocks, such as its aliases, through the allocation ledger. Every provider block has its own calculator, so by default it only knows about the allocations of other provider blocks that were recorded in the ledger before it was configured, and aliases allocating from the same pools in one run hand out the same CIDR blocks. With `shared_ledger`, the ledger is read again before every allocation, so the allocations of other provider blocks are taken into account as soon as they are recorded. Requires a ledger. Configure a lock as well when the provider blocks allocate concurrently. Defaults to `false`.",
+		Optional:            true,
+	}
+}
+
+// allocationLock serializes the allocations of Terraform runs sharing a
+// ledger. While it is held, a resource sees every allocation recorded in the
+// ledger, including those other runs and provider blocks made since the
+// provider was configured, and records its own before another run can
+// allocate.
+type allocationLock struct {
+	// mu serializes the resources of this run, so they do not contend for
+	// the shared lock.
+	mu sync.Mutex
+	// locker is nil when only shared_ledger is set, in which case the
+	// ledger is read again without locking out other runs.
+	locker     ledger.Locker
+	ledger     ledger.Ledger
+	calculator SubnetCalculator
+}
+
+// acquire holds the lock and adds the allocations recorded in the ledger to
+// the calculator. It returns a function that releases the lock, and does
+// nothing for a nil lock.
+func (l *allocationLock) acquire(ctx context.Context) (func(context.Context) diag.Diagnostics, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	if l == nil {
+		return func(context.Context) diag.Diagnostics { return nil }, diagnostics
+	}
+
+	l.mu.Lock()
+	unlock := func(context.Context) error { return nil }
+	if l.locker != nil {
+		var err error
+		if unlock, err = l.locker.Lock(ctx); err != nil {
+			l.mu.Unlock()
+			diagnostics.AddError("Lock error", fmt.Sprintf("Unable to acquire the allocation lock: %v", err))
+			return nil, diagnostics
+		}
+		tflog.Debug(ctx, "acquired the allocation lock")
+	}
+	release := func(ctx context.Context) diag.Diagnostics {
+		defer l.mu.Unlock()
+		var diagnostics diag.Diagnostics
+		if err := unlock(ctx); err != nil {
+			diagnostics.AddWarning("Lock error", fmt.Sprintf("Unable to release the allocation lock: %v. Other Terraform runs wait for it until it expires or is removed.", err))
+		}
+		return diagnostics
+	}
+
+	if l.ledger != nil {
+		entries, err := l.ledger.Entries(ctx)
+		if err != nil {
+			diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+			diagnostics.Append(release(ctx)...)
+			return nil, diagnostics
+		}
+		holdPrefixes(l.calculator, activePrefixes(entries))
+	}
+	return release, diagnostics
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccAllocationLock(t *testing.T) {
+	server := newFakeConsulServer(t)
+	consulLedger := newConsulLedger(ledgerConsulModel{
+		Address: types.StringValue(server.URL),
+		Key:     types.StringValue("netcalc/ledger"),
+	})
+	lock := ledger.NewConsulLock(ledger.ConsulOptions{
+		Address:     server.URL,
+		Key:         "netcalc/allocation.lock",
+		LockTimeout: 100 * time.Millisecond,
+	})
+
+	// Another workspace records an allocation in the shared ledger while
+	// this one waits for the lock the first time.
+	fake := server.Config.Handler
+	var once sync.Once
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/kv/netcalc/allocation.lock" && r.URL.Query().Has("acquire") {
+			once.Do(func() {
+				if err := consulLedger.Allocate(r.Context(), netip.MustParsePrefix("10.0.0.0/24"), "other"); err != nil {
+					t.Errorf("unable to record allocation of the other workspace: %v", err)
+				}
+			})
+		}
+		fake.ServeHTTP(w, r)
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Lock validation
+			{
+				Config: testAccAllocationLockConfig(server.URL, `
+				lock_dynamodb = {
+					table = "locks"
+				}`, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`Attribute\s+"lock_consul"\s+cannot\s+be\s+specified\s+when\s+"lock_dynamodb"\s+is\s+specified`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccAllocationLockConfig(server.URL, "", `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					testAccCheckLedgerActive(consulLedger, "10.0.0.0/24", "10.0.1.0/24"),
+					testAccCheckLockReleased(lock),
+				),
+			},
+			// Releasing allocations
+			{
+				Config: testAccAllocationLockConfig(server.URL, "", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckLedgerActive(consulLedger, "10.0.0.0/24"),
+					testAccCheckLockReleased(lock),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckLockReleased checks that no process holds a lock.
+func testAccCheckLockReleased(l ledger.Locker) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		unlock, err := l.Lock(context.Background())
+		if err != nil {
+			return err
+		}
+		return unlock(context.Background())
+	}
+}
+
+func testAccAllocationLockConfig(address string, extra string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  %[2]s
+
+  ledger_consul = {
+    address = %[1]q
+    key     = "netcalc/ledger"
+  }
+  lock_consul = {
+    address = %[1]q
+    key     = "netcalc/allocation.lock"
+  }
+}
+%[3]s
+`, address, strings.TrimSpace(extra), resources)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// allocationPrivateKey is the private state key holding the owner that a
+// resource's allocations are recorded under in a ledger.
+const allocationPrivateKey = "allocation"
+
+type allocationPrivateData struct {
+	Owner string `json:"owner"`
+}
+
+// privateState is implemented by the private state of requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+func newAllocationOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func setAllocationOwner(ctx context.Context, private privateState, owner string) diag.Diagnostics {
+	value, err := json.Marshal(allocationPrivateData{Owner: owner})
+	if err != nil {
+		var diagnostics diag.Diagnostics
+		diagnostics.AddError("Private state error", fmt.Sprintf("Unable to encode allocation owner: %v", err))
+		return diagnostics
+	}
+	return private.SetKey(ctx, allocationPrivateKey, value)
+}
+
+// getAllocationOwner returns the owner stored in private state, or an empty
+// string for resources that were imported.
+func getAllocationOwner(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	value, diagnostics := private.GetKey(ctx, allocationPrivateKey)
+	if diagnostics.HasError() || len(value) == 0 {
+		return "", diagnostics
+	}
+	var data allocationPrivateData
+	if err := json.Unmarshal(value, &data); err != nil {
+		diagnostics.AddError("Private state error", fmt.Sprintf("Unable to decode allocation owner: %v", err))
+	}
+	return data.Owner, diagnostics
+}
+
+// recordAllocations records the prefixes under owner in the ledger, nested in
+// the allocations containing one of nested. If a prefix cannot be recorded,
+// the prefixes recorded before it are released again, as the owner of a
+// failed create is never saved.
+func recordAllocations(ctx context.Context, l ledger.Ledger, owner string, nested []netip.Prefix, prefixes ...netip.Prefix) (diagnostics diag.Diagnostics) {
+	if l == nil {
+		return nil
+	}
+	for i, prefix := range prefixes {
+		err := l.Allocate(ctx, prefix, owner, nested...)
+		if err == nil {
+			continue
+		}
+		diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record allocation of %s: %v", prefix, err))
+		for _, recorded := range prefixes[:i] {
+			if err := l.Release(ctx, recorded, owner); err != nil {
+				diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", recorded, err))
+			}
+		}
+		return diagnostics
+	}
+	return nil
+}
+
+// activePrefixes returns the CIDR blocks of the active entries of a ledger.
+func activePrefixes(entries []ledger.Entry) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, e := range entries {
+		if e.Active() {
+			prefixes = append(prefixes, e.CIDR)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,336 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// maxAllocationRequestCount bounds the number of CIDR blocks a single
+// allocation request can ask for.
+const maxAllocationRequestCount = 4096
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AllocationPlanDataSource{}
+var _ datasource.DataSourceWithConfigure = &AllocationPlanDataSource{}
+
+func NewAllocationPlanDataSource() datasource.DataSource {
+	return &AllocationPlanDataSource{}
+}
+
+// AllocationPlanDataSource defines the data source implementation.
+type AllocationPlanDataSource struct {
+	calculator SubnetCalculator
+	strategy   subnet.Strategy
+}
+
+// AllocationPlanDataSourceModel describes the data source data model.
+type AllocationPlanDataSourceModel struct {
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	Requests            types.List   `tfsdk:"requests"`
+	Allocations         types.List   `tfsdk:"allocations"`
+	Fulfilled           types.Bool   `tfsdk:"fulfilled"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+// AllocationRequestModel describes a batch of requested CIDR blocks.
+type AllocationRequestModel struct {
+	Name           types.String `tfsdk:"name"`
+	IPFamily       types.String `tfsdk:"ip_family"`
+	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	Count          types.Int64  `tfsdk:"count"`
+}
+
+// AllocationModel describes the CIDR blocks that would be assigned to a
+// request.
+type AllocationModel struct {
+	Name       types.String `tfsdk:"name"`
+	CIDRBlocks types.List   `tfsdk:"cidr_blocks"`
+	Fulfilled  types.Bool   `tfsdk:"fulfilled"`
+}
+
+func (d *AllocationPlanDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_allocation_plan"
+}
+
+func (d *AllocationPlanDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Allocation plan data source. Previews the CIDR blocks a batch of requests would be assigned, without allocating anything, e.g. to review an address plan before creating the subnets. Requests are allocated in order, the same way netcalc_subnet resources allocate, taking into account allocations known to the provider: those made earlier in the same apply, the provider's `claimed_cidr_blocks`, and the allocated_cidr_blocks given here. Resources created later in the same apply may be assigned different CIDR blocks.",
+
+		Attributes: map[string]schema.Attribute{
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to treat as allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"requests": allocationRequestsAttribute("Requested CIDR blocks, allocated in order."),
+			"allocations": schema.ListNestedAttribute{
+				MarkdownDescription: "CIDR blocks that would be assigned, one entry per request in the same order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the request.",
+							Computed:            true,
+						},
+						"cidr_blocks": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "CIDR blocks that would be assigned. When the request cannot be fulfilled, only those that fit are listed.",
+							Computed:            true,
+						},
+						"fulfilled": schema.BoolAttribute{
+							MarkdownDescription: "Whether all of the requested CIDR blocks fit.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"fulfilled": schema.BoolAttribute{
+				MarkdownDescription: "Whether all requests can be fulfilled.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the pool allocated from.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// allocationRequestsAttribute returns the schema of a list of allocation
+// requests.
+func allocationRequestsAttribute(description string) schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: description,
+		Required:            true,
+		Validators:          []validator.List{listvalidator.SizeAtLeast(1)},
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "Name identifying the request in the results.",
+					Optional:            true,
+				},
+				"ip_family": schema.StringAttribute{
+					MarkdownDescription: "IP family of the requested CIDR blocks, either ipv4 or ipv6. Defaults to ipv4.",
+					Optional:            true,
+					Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6)},
+				},
+				"cidr_mask_length": schema.Int64Attribute{
+					MarkdownDescription: "Network size of the requested CIDR blocks in bits.",
+					Required:            true,
+					Validators:          []validator.Int64{int64validator.Between(0, 128)},
+				},
+				"count": schema.Int64Attribute{
+					MarkdownDescription: fmt.Sprintf("Number of CIDR blocks requested, between 1 and %d. Defaults to 1.", maxAllocationRequestCount),
+					Optional:            true,
+					Validators:          []validator.Int64{int64validator.Between(1, maxAllocationRequestCount)},
+				},
+			},
+		},
+	}
+}
+
+func (d *AllocationPlanDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+		d.strategy = data.strategy
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *AllocationPlanDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AllocationPlanDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var requests []AllocationRequestModel
+	resp.Diagnostics.Append(data.Requests.ElementsAs(ctx, &requests, false)...)
+	sim, diags := newAllocationSimulation(ctx, d.calculator, d.strategy, data.PoolID, data.AllocatedCIDRBlocks)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateAllocationRequests(requests)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	allocations := []AllocationModel{}
+	fulfilled := true
+	for _, r := range requests {
+		cidrs, err := sim.allocate(ctx, r)
+		if ctx.Err() != nil {
+			resp.Diagnostics.AddError("Allocation plan error", fmt.Sprintf("Unable to simulate the allocations: %v", err))
+			return
+		}
+		cidrList, diags := prefixList(ctx, cidrs)
+		resp.Diagnostics.Append(diags...)
+		allocations = append(allocations, AllocationModel{
+			Name:       r.Name,
+			CIDRBlocks: cidrList,
+			Fulfilled:  types.BoolValue(err == nil),
+		})
+		fulfilled = fulfilled && err == nil
+	}
+
+	allocationList, diags := types.ListValueFrom(ctx, data.Allocations.ElementType(ctx), allocations)
+	resp.Diagnostics.Append(diags...)
+	data.Allocations = allocationList
+	data.Fulfilled = types.BoolValue(fulfilled)
+	data.ID = types.StringValue(sim.id)
+
+	tflog.Trace(ctx, "read an allocation plan data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// validateAllocationRequests checks the mask lengths of IPv4 requests, which
+// the schema validators cannot tell apart from IPv6 requests.
+func validateAllocationRequests(requests []AllocationRequestModel) (diagnostics diag.Diagnostics) {
+	for i, r := range requests {
+		if r.IPFamily.ValueString() != ipFamilyIPv6 && r.CIDRMaskLength.ValueInt64() > 32 {
+			diagnostics.AddAttributeError(path.Root("requests").AtListIndex(i).AtName("cidr_mask_length"), "Invalid CIDR mask length", fmt.Sprintf("IPv4 CIDR mask length must be at most 32, got: %d", r.CIDRMaskLength.ValueInt64()))
+		}
+	}
+	return diagnostics
+}
+
+// allocationSimulation allocates CIDR blocks from a copy of the provider's
+// calculator, so that nothing is actually allocated.
+type allocationSimulation struct {
+	calc *subnet.Calculator
+	// pools and reserved are set when allocating from a netcalc_pool rather
+	// than the provider's pools.
+	pools    []netip.Prefix
+	reserved []netip.Prefix
+	// allocated are the CIDR blocks allocated from the pools so far, which
+	// are passed along as used, since the calculator ignores allocations
+	// covering a whole pool.
+	allocated []netip.Prefix
+	id        string
+	strategy  subnet.Strategy
+}
+
+// newAllocationSimulation sets up a simulation of allocations with the given
+// strategy from the pool with the given ID, or the provider's pools if it is
+// null, treating the given CIDR blocks as allocated.
+func newAllocationSimulation(ctx context.Context, calculator SubnetCalculator, strategy subnet.Strategy, id types.String, allocated types.Set) (*allocationSimulation, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	sim := &allocationSimulation{calc: simulationCalculator(calculator), strategy: strategy}
+	holdPrefixes(sim.calc, parsePrefixSet(ctx, allocated, &diagnostics))
+	if id.IsNull() {
+		sim.id = poolID(append(sim.calc.Pools(false), sim.calc.Pools(true)...), nil)
+		return sim, diagnostics
+	}
+
+	pools, reserved, err := parsePoolID(id.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", id.ValueString(), err))
+		return nil, diagnostics
+	}
+	sim.pools, sim.reserved, sim.id = pools, reserved, id.ValueString()
+	return sim, diagnostics
+}
+
+// simulationCalculator returns a calculator with the pools, reserved and
+// allocated CIDR blocks of the provider's calculator.
+func simulationCalculator(calculator SubnetCalculator) *subnet.Calculator {
+	calc := subnet.NewCalculator()
+	calc.PoolPriorities = calculator.PoolPriorities()
+	for _, ipv6 := range []bool{false, true} {
+		for _, pool := range calculator.Pools(ipv6) {
+			// The pools were added to the provider's calculator already.
+			_ = calc.AddPool(pool)
+		}
+		for _, prefix := range calculator.AllocatedPrefixes(ipv6) {
+			calc.HoldPrefix(prefix)
+		}
+	}
+	for _, prefix := range calculator.ReservedPrefixes() {
+		calc.AddReservedPrefix(prefix)
+	}
+	return calc
+}
+
+// allocate allocates the CIDR blocks of a request one at a time, and returns
+// those that fit along with an error if not all of them did.
+func (s *allocationSimulation) allocate(ctx context.Context, r AllocationRequestModel) ([]netip.Prefix, error) {
+	ipv6 := r.IPFamily.ValueString() == ipFamilyIPv6
+	maskLength := int(r.CIDRMaskLength.ValueInt64())
+	count := 1
+	if !r.Count.IsNull() {
+		count = int(r.Count.ValueInt64())
+	}
+
+	var cidrs []netip.Prefix
+	for len(cidrs) < count {
+		next, err := s.next(ctx, ipv6, maskLength)
+		if err != nil {
+			return cidrs, err
+		}
+		cidrs = append(cidrs, next)
+	}
+	return cidrs, nil
+}
+
+func (s *allocationSimulation) next(ctx context.Context, ipv6 bool, maskLength int) (netip.Prefix, error) {
+	if s.pools != nil {
+		next, err := s.calc.NextAvailableSubnetInPools(s.familyPools(ipv6), append(s.reserved[:len(s.reserved):len(s.reserved)], s.allocated...), maskLength, s.strategy)
+		if err == nil {
+			s.allocated = append(s.allocated, next)
+		}
+		return next, err
+	}
+	return s.calc.NextAvailableSubnet(ctx, ipv6, maskLength, s.strategy)
+}
+
+// familyPools returns the pools of one IP family that are allocated from.
+func (s *allocationSimulation) familyPools(ipv6 bool) []netip.Prefix {
+	if s.pools == nil {
+		return s.calc.Pools(ipv6)
+	}
+	var pools []netip.Prefix
+	for _, p := range s.pools {
+		if p.Addr().Is6() == ipv6 {
+			pools = append(pools, p)
+		}
+	}
+	return pools
+}
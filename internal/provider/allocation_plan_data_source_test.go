@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAllocationPlanDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Mask length validation
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				data "netcalc_allocation_plan" "invalid" {
+					requests = [{ cidr_mask_length = 48 }]
+				}`,
+				ExpectError: regexp.MustCompile(`IPv4\s+CIDR\s+mask\s+length\s+must\s+be\s+at\s+most\s+32`),
+			},
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd00::/48"]
+				}
+				data "netcalc_allocation_plan" "test" {
+					allocated_cidr_blocks = ["10.0.0.0/24"]
+					requests = [
+						{ name = "app", cidr_mask_length = 24, count = 2 },
+						{ name = "db", cidr_mask_length = 23 },
+						{ name = "v6", ip_family = "ipv6", cidr_mask_length = 64 },
+					]
+				}
+				data "netcalc_allocation_plan" "pool" {
+					pool_id  = "10.1.0.0/23,!10.1.0.0/24"
+					requests = [
+						{ name = "app", cidr_mask_length = 25, count = 3 },
+						{ name = "db", cidr_mask_length = 24 },
+					]
+				}
+				output "db" {
+					value = length(data.netcalc_allocation_plan.pool.allocations[1].cidr_blocks)
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					depends_on       = [data.netcalc_allocation_plan.test]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "id", "10.0.0.0/16,fd00::/48"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "fulfilled", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "allocations.0.name", "app"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "allocations.0.cidr_blocks.0", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "allocations.0.cidr_blocks.1", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "allocations.1.cidr_blocks.0", "10.0.4.0/23"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.test", "allocations.2.cidr_blocks.0", "fd00::/64"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.pool", "fulfilled", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.pool", "allocations.0.fulfilled", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_allocation_plan.pool", "allocations.0.cidr_blocks.#", "2"),
+					resource.TestCheckOutput("db", "0"),
+					// The preview does not allocate anything.
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+				),
+			},
+		},
+	})
+}
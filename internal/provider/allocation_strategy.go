@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// allocationStrategyDescription describes the allocation strategies for the
+// provider's and the resources' allocation_strategy attributes.
+const allocationStrategyDescription = "`first_fit` allocates at the lowest free address, `best_fit` allocates in the smallest free CIDR block that fits, keeping large free CIDR blocks intact, and `spread` allocates at the start of the largest free CIDR block, leaving room for every subnet to grow."
+
+// allocationStrategyNames returns the names of the supported allocation
+// strategies.
+func allocationStrategyNames() []string {
+	names := make([]string, 0, len(subnet.Strategies))
+	for _, s := range subnet.Strategies {
+		names = append(names, string(s))
+	}
+	return names
+}
+
+// allocationStrategyAttribute returns the allocation_strategy attribute of a
+// resource. Changing it only affects CIDR blocks allocated afterwards.
+func allocationStrategyAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "Strategy for choosing where new CIDR blocks are allocated, overriding the provider's allocation_strategy. " + allocationStrategyDescription + " Changing the strategy does not reallocate existing CIDR blocks.",
+		Optional:            true,
+		Validators:          []validator.String{stringvalidator.OneOf(allocationStrategyNames()...)},
+	}
+}
+
+// allocationStrategy returns the strategy configured on a resource, or the
+// provider's default strategy when it is not set.
+func allocationStrategy(strategy types.String, defaultStrategy subnet.Strategy) subnet.Strategy {
+	if strategy.IsNull() || strategy.IsUnknown() {
+		return defaultStrategy
+	}
+	return subnet.Strategy(strategy.ValueString())
+}
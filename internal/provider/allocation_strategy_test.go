@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccAllocationStrategy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/16"]
+					allocation_strategy = "worst_fit"
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`value\s+must\s+be\s+one\s+of`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccAllocationStrategyConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.default", "cidr_block", "10.1.128.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.first_fit", "cidr_block", "10.2.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.default", "cidr_blocks.x", "10.3.128.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.default", "cidr_blocks.y", "10.3.64.0/24"),
+					resource.TestCheckResourceAttr("netcalc_pool.best_fit", "cidr_blocks.0", "10.4.16.0/20"),
+				),
+			},
+			// Changing the strategy keeps the allocated CIDR blocks
+			{
+				Config: testAccAllocationStrategyConfig("first_fit"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet.default", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("netcalc_subnet_group.default", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.default", "cidr_block", "10.1.128.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.default", "cidr_blocks.x", "10.3.128.0/24"),
+				),
+			},
+		},
+	})
+}
+
+// testAccAllocationStrategyConfig allocates from pools that each have their
+// first /24 claimed, so every strategy allocates somewhere else. The default
+// subnet and group use the provider's strategy unless one is given.
+func testAccAllocationStrategyConfig(strategy string) string {
+	override := ""
+	if strategy != "" {
+		override = "allocation_strategy = \"" + strategy + "\""
+	}
+	return `
+	provider "netcalc" {
+		claimed_cidr_blocks = ["10.1.0.0/24", "10.2.0.0/24", "10.3.0.0/24", "10.4.0.0/24"]
+		allocation_strategy = "spread"
+		pools = {
+			a = { cidr_blocks = ["10.1.0.0/16"] }
+			b = { cidr_blocks = ["10.2.0.0/16"] }
+			c = { cidr_blocks = ["10.3.0.0/16"] }
+		}
+	}
+	resource "netcalc_subnet" "default" {
+		pool                = "a"
+		cidr_mask_length    = 24
+		` + override + `
+	}
+	resource "netcalc_subnet" "first_fit" {
+		pool                = "b"
+		cidr_mask_length    = 24
+		allocation_strategy = "first_fit"
+	}
+	resource "netcalc_subnet_group" "default" {
+		pool                = "c"
+		keys                = ["x", "y"]
+		cidr_mask_length    = 24
+		` + override + `
+	}
+	resource "netcalc_pool" "parent" {
+		cidr_blocks = ["10.4.0.0/16"]
+	}
+	resource "netcalc_pool" "best_fit" {
+		parent_pool_id      = netcalc_pool.parent.id
+		cidr_mask_length    = 20
+		allocation_strategy = "best_fit"
+	}`
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/geezyx/subnet-calculator/internal/discovery"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// awsDiscoveryModel describes the aws_discovery provider attribute.
+type awsDiscoveryModel struct {
+	Regions        types.List   `tfsdk:"regions"`
+	RoleARNs       types.List   `tfsdk:"role_arns"`
+	Profile        types.String `tfsdk:"profile"`
+	IncludeVPCs    types.Bool   `tfsdk:"include_vpcs"`
+	IncludeSubnets types.Bool   `tfsdk:"include_subnets"`
+	EC2Endpoint    types.String `tfsdk:"ec2_endpoint"`
+}
+
+func awsDiscoveryAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Discovers the CIDR blocks of existing VPCs and subnets in AWS accounts and treats them as claimed CIDR blocks, so they do not have to be listed in `claimed_cidr_blocks`. Requires the `ec2:DescribeVpcs` and `ec2:DescribeSubnets` permissions. CIDR blocks are discovered whenever the provider is configured, so a plan also claims CIDR blocks created since the last one.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"regions": schema.ListAttribute{
+				MarkdownDescription: "Regions to discover CIDR blocks in, e.g. `[\"us-east-1\", \"eu-west-1\"]`.",
+				ElementType:         types.StringType,
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"role_arns": schema.ListAttribute{
+				MarkdownDescription: "IAM roles to assume, one for each account to discover CIDR blocks in. Defaults to the account of the configured credentials.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the AWS profile of the credentials. Defaults to the `AWS_PROFILE` environment variable or the default profile.",
+				Optional:            true,
+			},
+			"include_vpcs": schema.BoolAttribute{
+				MarkdownDescription: "Whether to claim the CIDR blocks of VPCs. Disable it when allocating subnets inside a discovered VPC. Defaults to `true`.",
+				Optional:            true,
+			},
+			"include_subnets": schema.BoolAttribute{
+				MarkdownDescription: "Whether to claim the CIDR blocks of subnets. Defaults to `true`.",
+				Optional:            true,
+			},
+			"ec2_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom endpoint of the EC2 API.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// loadAWSConfig loads the AWS configuration from the environment and shared
+// configuration files, with an optional region and profile.
+func loadAWSConfig(ctx context.Context, region string, profile string) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+	return config.LoadDefaultConfig(ctx, opts...)
+}
+
+// discoverAWS returns the CIDR blocks discovered by the aws_discovery
+// provider attribute, in every combination of account and region.
+func discoverAWS(ctx context.Context, data awsDiscoveryModel, diagnostics *diag.Diagnostics) []netip.Prefix {
+	var regions, roleARNs []string
+	diagnostics.Append(data.Regions.ElementsAs(ctx, &regions, false)...)
+	diagnostics.Append(data.RoleARNs.ElementsAs(ctx, &roleARNs, false)...)
+	if diagnostics.HasError() {
+		return nil
+	}
+	cfg, err := loadAWSConfig(ctx, "", data.Profile.ValueString())
+	if err != nil {
+		diagnostics.AddError("AWS discovery error", fmt.Sprintf("Unable to load AWS configuration: %v", err))
+		return nil
+	}
+
+	// An empty role stands for the configured credentials.
+	if len(roleARNs) == 0 {
+		roleARNs = []string{""}
+	}
+	discoveryOpts := discovery.AWSOptions{
+		VPCs:    data.IncludeVPCs.IsNull() || data.IncludeVPCs.ValueBool(),
+		Subnets: data.IncludeSubnets.IsNull() || data.IncludeSubnets.ValueBool(),
+	}
+	var prefixes []netip.Prefix
+	for _, roleARN := range roleARNs {
+		for _, region := range regions {
+			accountCfg := cfg.Copy()
+			accountCfg.Region = region
+			if roleARN != "" {
+				accountCfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(accountCfg), roleARN))
+			}
+			client := ec2.NewFromConfig(accountCfg, func(o *ec2.Options) {
+				if endpoint := data.EC2Endpoint.ValueString(); endpoint != "" {
+					o.BaseEndpoint = aws.String(endpoint)
+				}
+			})
+			discovered, err := discovery.AWS(ctx, client, discoveryOpts)
+			if err != nil {
+				location := region
+				if roleARN != "" {
+					location = fmt.Sprintf("%s with role %s", region, roleARN)
+				}
+				diagnostics.AddError("AWS discovery error", fmt.Sprintf("Unable to discover CIDR blocks in %s: %v", location, err))
+				return nil
+			}
+			tflog.Debug(ctx, "discovered AWS CIDR blocks", map[string]interface{}{
+				"region":      region,
+				"role_arn":    roleARN,
+				"cidr_blocks": fmt.Sprint(discovered),
+			})
+			prefixes = append(prefixes, discovered...)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// newFakeEC2Server serves DescribeVpcs and DescribeSubnets requests with one
+// VPC and one subnet, and GetIpamPoolCidrs and GetIpamPoolAllocations
+// requests for the IPAM pool ipam-pool-1 with one CIDR block and one
+// allocation. It rejects requests for eu-west-1.
+func newFakeEC2Server(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "text/xml")
+		if strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Response><Errors><Error><Code>UnauthorizedOperation</Code><Message>You are not authorized to perform this operation.</Message></Error></Errors><RequestID>1</RequestID></Response>`)
+			return
+		}
+		switch r.Form.Get("Action") {
+		case "DescribeVpcs":
+			fmt.Fprint(w, `<DescribeVpcsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>1</requestId><vpcSet><item><vpcId>vpc-1</vpcId><cidrBlock>10.0.0.0/16</cidrBlock><cidrBlockAssociationSet><item><associationId>vpc-cidr-assoc-1</associationId><cidrBlock>10.0.0.0/16</cidrBlock><cidrBlockState><state>associated</state></cidrBlockState></item></cidrBlockAssociationSet></item></vpcSet></DescribeVpcsResponse>`)
+		case "DescribeSubnets":
+			fmt.Fprint(w, `<DescribeSubnetsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>1</requestId><subnetSet><item><subnetId>subnet-1</subnetId><vpcId>vpc-2</vpcId><cidrBlock>10.1.0.0/24</cidrBlock></item></subnetSet></DescribeSubnetsResponse>`)
+		case "GetIpamPoolCidrs", "GetIpamPoolAllocations":
+			if r.Form.Get("IpamPoolId") != "ipam-pool-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `<Response><Errors><Error><Code>InvalidIpamPoolId.NotFound</Code><Message>The pool ID does not exist</Message></Error></Errors><RequestID>1</RequestID></Response>`)
+				return
+			}
+			if r.Form.Get("Action") == "GetIpamPoolCidrs" {
+				fmt.Fprint(w, `<GetIpamPoolCidrsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>1</requestId><ipamPoolCidrSet><item><cidr>10.0.0.0/16</cidr><state>provisioned</state></item></ipamPoolCidrSet></GetIpamPoolCidrsResponse>`)
+				return
+			}
+			fmt.Fprint(w, `<GetIpamPoolAllocationsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>1</requestId><ipamPoolAllocationSet><item><cidr>10.0.0.0/17</cidr><resourceType>vpc</resourceType></item></ipamPoolAllocationSet></GetIpamPoolAllocationsResponse>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	return server
+}
+
+func TestAccProviderAWSDiscovery(t *testing.T) {
+	server := newFakeEC2Server(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Discovery errors
+			{
+				Config:      testAccProviderAWSDiscoveryConfig(server.URL, `regions = ["us-east-1", "eu-west-1"]`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+discover\s+CIDR\s+blocks\s+in\s+eu-west-1:(.|\s)*UnauthorizedOperation`),
+			},
+			// VPCs and subnets are claimed
+			{
+				Config: testAccProviderAWSDiscoveryConfig(server.URL, `regions = ["us-east-1"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+			},
+			// Only subnets are claimed
+			{
+				Config: testAccProviderAWSDiscoveryConfig(server.URL, `regions = ["us-east-1"]
+    include_vpcs = false`) + `
+resource "netcalc_subnet" "vpc" {
+  cidr_mask_length = 17
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_subnet.vpc", "cidr_block", "10.0.0.0/17"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderAWSDiscoveryConfig(endpoint string, extra string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/8"]
+
+  aws_discovery = {
+    %[2]s
+    ec2_endpoint = %[1]q
+  }
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 16
+}
+`, endpoint, extra)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/geezyx/subnet-calculator/internal/discovery"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// awsIPAMPoolModel describes the aws_ipam_pool provider attribute.
+type awsIPAMPoolModel struct {
+	PoolID      types.String `tfsdk:"pool_id"`
+	Region      types.String `tfsdk:"region"`
+	Profile     types.String `tfsdk:"profile"`
+	EC2Endpoint types.String `tfsdk:"ec2_endpoint"`
+}
+
+func awsIPAMPoolAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Sources pool CIDR blocks from an AWS VPC IPAM pool, so IPAM remains the top-level owner of the address space while netcalc allocates within it. The CIDR blocks provisioned to the IPAM pool are added to `pool_cidr_blocks`, and the CIDR blocks already allocated from it, e.g. to VPCs or child pools, are treated as claimed CIDR blocks. Allocations made by netcalc are not registered in IPAM. Requires the `ec2:GetIpamPoolCidrs` and `ec2:GetIpamPoolAllocations` permissions.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the IPAM pool, e.g. `ipam-pool-0123456789abcdef0`.",
+				Required:            true,
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "Region of the IPAM pool, which is the locale of the pool or, for pools without a locale, the home region of the IPAM. Defaults to the `AWS_REGION` environment variable or the region of the profile.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the AWS profile of the credentials. Defaults to the `AWS_PROFILE` environment variable or the default profile.",
+				Optional:            true,
+			},
+			"ec2_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom endpoint of the EC2 API.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// readAWSIPAMPool returns the pool CIDR blocks and claimed CIDR blocks of
+// the IPAM pool configured by the aws_ipam_pool provider attribute.
+func readAWSIPAMPool(ctx context.Context, data awsIPAMPoolModel, diagnostics *diag.Diagnostics) ([]netip.Prefix, []netip.Prefix) {
+	cfg, err := loadAWSConfig(ctx, data.Region.ValueString(), data.Profile.ValueString())
+	if err != nil {
+		diagnostics.AddError("AWS IPAM error", fmt.Sprintf("Unable to load AWS configuration: %v", err))
+		return nil, nil
+	}
+	client := ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+		if endpoint := data.EC2Endpoint.ValueString(); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	cidrs, allocations, err := discovery.AWSIPAMPool(ctx, client, data.PoolID.ValueString())
+	if err != nil {
+		diagnostics.AddError("AWS IPAM error", fmt.Sprintf("Unable to read IPAM pool: %v", err))
+		return nil, nil
+	}
+	tflog.Debug(ctx, "read AWS IPAM pool", map[string]interface{}{
+		"pool_id":     data.PoolID.ValueString(),
+		"cidr_blocks": fmt.Sprint(cidrs),
+		"allocations": fmt.Sprint(allocations),
+	})
+	return cidrs, allocations
+}
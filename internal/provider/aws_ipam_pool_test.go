@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderAWSIPAMPool(t *testing.T) {
+	server := newFakeEC2Server(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// IPAM errors
+			{
+				Config:      testAccProviderAWSIPAMPoolConfig(server.URL, "ipam-pool-2"),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+read\s+IPAM\s+pool:(.|\s)*InvalidIpamPoolId.NotFound`),
+			},
+			// Pool CIDR blocks and allocations
+			{
+				Config: testAccProviderAWSIPAMPoolConfig(server.URL, "ipam-pool-1"),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.128.0/24"),
+			},
+		},
+	})
+}
+
+func testAccProviderAWSIPAMPoolConfig(endpoint string, poolID string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  aws_ipam_pool = {
+    pool_id      = %[2]q
+    region       = "us-east-1"
+    ec2_endpoint = %[1]q
+  }
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 24
+}
+`, endpoint, poolID)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/geezyx/subnet-calculator/internal/discovery"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// azureDiscoveryModel describes the azure_discovery provider attribute.
+type azureDiscoveryModel struct {
+	SubscriptionIDs types.List `tfsdk:"subscription_ids"`
+	ResourceGroups  types.List `tfsdk:"resource_groups"`
+	IncludeVNets    types.Bool `tfsdk:"include_vnets"`
+	IncludeSubnets  types.Bool `tfsdk:"include_subnets"`
+}
+
+func azureDiscoveryAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Discovers the address spaces of existing virtual networks and the address prefixes of their subnets in Azure subscriptions and treats them as claimed CIDR blocks, so they do not have to be listed in `claimed_cidr_blocks`. Credentials are taken from the environment, a managed identity or the Azure CLI, and need permission to read virtual networks, e.g. with the Reader role.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"subscription_ids": schema.ListAttribute{
+				MarkdownDescription: "Subscriptions to discover CIDR blocks in.",
+				ElementType:         types.StringType,
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"resource_groups": schema.ListAttribute{
+				MarkdownDescription: "Resource groups to discover virtual networks in, in every subscription. Defaults to every resource group.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"include_vnets": schema.BoolAttribute{
+				MarkdownDescription: "Whether to claim the address spaces of virtual networks. Disable it when allocating subnets inside a discovered virtual network. Defaults to `true`.",
+				Optional:            true,
+			},
+			"include_subnets": schema.BoolAttribute{
+				MarkdownDescription: "Whether to claim the address prefixes of subnets. Defaults to `true`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newAzureVirtualNetworksClient returns a client for the virtual networks of
+// a subscription. It is replaced in tests.
+var newAzureVirtualNetworksClient = func(subscriptionID string, credential azcore.TokenCredential) (*armnetwork.VirtualNetworksClient, error) {
+	return armnetwork.NewVirtualNetworksClient(subscriptionID, credential, nil)
+}
+
+// discoverAzure returns the CIDR blocks discovered by the azure_discovery
+// provider attribute, in every subscription.
+func discoverAzure(ctx context.Context, data azureDiscoveryModel, diagnostics *diag.Diagnostics) []netip.Prefix {
+	var subscriptionIDs []string
+	discoveryOpts := discovery.AzureOptions{
+		VNets:   data.IncludeVNets.IsNull() || data.IncludeVNets.ValueBool(),
+		Subnets: data.IncludeSubnets.IsNull() || data.IncludeSubnets.ValueBool(),
+	}
+	diagnostics.Append(data.SubscriptionIDs.ElementsAs(ctx, &subscriptionIDs, false)...)
+	diagnostics.Append(data.ResourceGroups.ElementsAs(ctx, &discoveryOpts.ResourceGroups, false)...)
+	if diagnostics.HasError() {
+		return nil
+	}
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		diagnostics.AddError("Azure discovery error", fmt.Sprintf("Unable to load Azure credentials: %v", err))
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+	for _, subscriptionID := range subscriptionIDs {
+		client, err := newAzureVirtualNetworksClient(subscriptionID, credential)
+		if err != nil {
+			diagnostics.AddError("Azure discovery error", fmt.Sprintf("Unable to create Azure client for subscription %s: %v", subscriptionID, err))
+			return nil
+		}
+		discovered, err := discovery.Azure(ctx, client, discoveryOpts)
+		if err != nil {
+			diagnostics.AddError("Azure discovery error", fmt.Sprintf("Unable to discover CIDR blocks in subscription %s: %v", subscriptionID, err))
+			return nil
+		}
+		tflog.Debug(ctx, "discovered Azure CIDR blocks", map[string]interface{}{
+			"subscription_id": subscriptionID,
+			"cidr_blocks":     fmt.Sprint(discovered),
+		})
+		prefixes = append(prefixes, discovered...)
+	}
+	return prefixes
+}
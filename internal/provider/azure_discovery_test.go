@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+type fakeAzureCredential struct{}
+
+func (fakeAzureCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// useFakeAzureServer replaces the Azure clients with clients of a server
+// with one virtual network and one subnet in the subscription sub, and
+// rejects requests for other subscriptions.
+func useFakeAzureServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/subscriptions/sub/providers/Microsoft.Network/virtualNetworks" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":"AuthorizationFailed","message":"The client does not have authorization to perform action."}}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":[{"name":"hub","properties":{"addressSpace":{"addressPrefixes":["10.0.0.0/16"]},"subnets":[{"name":"default","properties":{"addressPrefix":"10.1.0.0/24"}}]}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	newClient := newAzureVirtualNetworksClient
+	t.Cleanup(func() { newAzureVirtualNetworksClient = newClient })
+	newAzureVirtualNetworksClient = func(subscriptionID string, credential azcore.TokenCredential) (*armnetwork.VirtualNetworksClient, error) {
+		return armnetwork.NewVirtualNetworksClient(subscriptionID, fakeAzureCredential{}, &arm.ClientOptions{
+			ClientOptions: policy.ClientOptions{
+				Cloud: cloud.Configuration{
+					Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+						cloud.ResourceManager: {Endpoint: server.URL, Audience: "https://management.azure.com"},
+					},
+				},
+				Transport: server.Client(),
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		})
+	}
+}
+
+func TestAccProviderAzureDiscovery(t *testing.T) {
+	useFakeAzureServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Discovery errors
+			{
+				Config:      testAccProviderAzureDiscoveryConfig(`subscription_ids = ["sub", "other"]`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+discover\s+CIDR\s+blocks\s+in\s+subscription\s+other:(.|\s)*AuthorizationFailed`),
+			},
+			// Virtual networks and subnets are claimed
+			{
+				Config: testAccProviderAzureDiscoveryConfig(`subscription_ids = ["sub"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+			},
+			// Only subnets are claimed
+			{
+				Config: testAccProviderAzureDiscoveryConfig(`subscription_ids = ["sub"]
+    include_vnets    = false`) + `
+resource "netcalc_subnet" "vnet" {
+  cidr_mask_length = 17
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_subnet.vnet", "cidr_block", "10.0.0.0/17"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderAzureDiscoveryConfig(extra string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/8"]
+
+  azure_discovery = {
+    %s
+  }
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 16
+}
+`, extra)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CapacityDataSource{}
+var _ datasource.DataSourceWithConfigure = &CapacityDataSource{}
+
+func NewCapacityDataSource() datasource.DataSource {
+	return &CapacityDataSource{}
+}
+
+// CapacityDataSource defines the data source implementation.
+type CapacityDataSource struct {
+	calculator SubnetCalculator
+}
+
+// CapacityDataSourceModel describes the data source data model.
+type CapacityDataSourceModel struct {
+	CIDRMaskLength      types.Int64  `tfsdk:"cidr_mask_length"`
+	IPFamily            types.String `tfsdk:"ip_family"`
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	AvailableCount      types.Number `tfsdk:"available_count"`
+	Pools               types.List   `tfsdk:"pools"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+// CapacityPoolModel describes the capacity of a single pool.
+type CapacityPoolModel struct {
+	CIDRBlock      types.String `tfsdk:"cidr_block"`
+	AvailableCount types.Number `tfsdk:"available_count"`
+}
+
+func (d *CapacityDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_capacity"
+}
+
+func (d *CapacityDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Capacity data source. Reports how many more subnets of a given size could be allocated from the pools, e.g. for pre-flight checks and alerting. Only allocations known to the provider are counted: those made earlier in the same apply, the provider's `claimed_cidr_blocks`, and the allocated_cidr_blocks given here.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size of the subnets in bits.",
+				Required:            true,
+				Validators:          []validator.Int64{int64validator.Between(0, 128)},
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "IP family of the subnets, either ipv4 or ipv6. Defaults to ipv4.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6)},
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks. The pool's reserved CIDR blocks are never allocated.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to treat as allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"available_count": schema.NumberAttribute{
+				MarkdownDescription: "Number of subnets that could still be allocated from all pools.",
+				Computed:            true,
+			},
+			"pools": schema.ListNestedAttribute{
+				MarkdownDescription: "Capacity of each pool of the IP family, in address order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"cidr_block": schema.StringAttribute{
+							MarkdownDescription: "CIDR block of the pool.",
+							Computed:            true,
+						},
+						"available_count": schema.NumberAttribute{
+							MarkdownDescription: "Number of subnets that could still be allocated from the pool.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the pool allocated from.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *CapacityDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *CapacityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data CapacityDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ipv6 := data.IPFamily.ValueString() == ipFamilyIPv6
+	maskLength := int(data.CIDRMaskLength.ValueInt64())
+	if !ipv6 && maskLength > 32 {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "Invalid CIDR mask length", fmt.Sprintf("IPv4 CIDR mask length must be at most 32, got: %d", maskLength))
+		return
+	}
+	used := parsePrefixSet(ctx, data.AllocatedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pools []netip.Prefix
+	if data.PoolID.IsNull() {
+		pools = d.calculator.Pools(ipv6)
+		data.ID = types.StringValue(poolID(append(d.calculator.Pools(false), d.calculator.Pools(true)...), nil))
+	} else {
+		allPools, reserved, err := parsePoolID(data.PoolID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", data.PoolID.ValueString(), err))
+			return
+		}
+		for _, p := range sortPrefixes(allPools) {
+			if p.Addr().Is6() == ipv6 {
+				pools = append(pools, p)
+			}
+		}
+		used = append(used, reserved...)
+		data.ID = data.PoolID
+	}
+
+	total := new(big.Int)
+	poolModels := []CapacityPoolModel{}
+	for _, pool := range pools {
+		count := subnet.CountSubnets(d.calculator.FreePrefixesInPool(pool, used), maskLength)
+		total.Add(total, count)
+		poolModels = append(poolModels, CapacityPoolModel{
+			CIDRBlock:      types.StringValue(pool.String()),
+			AvailableCount: types.NumberValue(new(big.Float).SetInt(count)),
+		})
+	}
+
+	poolList, diags := types.ListValueFrom(ctx, data.Pools.ElementType(ctx), poolModels)
+	resp.Diagnostics.Append(diags...)
+	data.Pools = poolList
+	data.AvailableCount = types.NumberValue(new(big.Float).SetInt(total))
+
+	tflog.Trace(ctx, "read a capacity data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
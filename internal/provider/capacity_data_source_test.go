@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCapacityDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/22", "10.1.0.0/24", "fd00::/48"]
+					claimed_cidr_blocks = ["10.0.0.0/24"]
+				}
+				data "netcalc_capacity" "ipv4" {
+					cidr_mask_length      = 24
+					allocated_cidr_blocks = ["10.0.1.0/25"]
+				}
+				data "netcalc_capacity" "ipv6" {
+					ip_family        = "ipv6"
+					cidr_mask_length = 56
+				}
+				data "netcalc_capacity" "pool" {
+					pool_id          = "10.2.0.0/16,!10.2.0.0/17"
+					cidr_mask_length = 20
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv4", "available_count", "3"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv4", "pools.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv4", "pools.0.cidr_block", "10.0.0.0/22"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv4", "pools.0.available_count", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv4", "pools.1.available_count", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.ipv6", "available_count", "256"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.pool", "id", "10.2.0.0/16,!10.2.0.0/17"),
+					resource.TestCheckResourceAttr("data.netcalc_capacity.pool", "available_count", "8"),
+				),
+			},
+		},
+	})
+}
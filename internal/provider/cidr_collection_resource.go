@@ -0,0 +1,244 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CIDRCollectionResource{}
+var _ resource.ResourceWithImportState = &CIDRCollectionResource{}
+var _ resource.ResourceWithModifyPlan = &CIDRCollectionResource{}
+
+func NewCIDRCollectionResource() resource.Resource {
+	return &CIDRCollectionResource{}
+}
+
+// CIDRCollectionResource defines the resource implementation.
+type CIDRCollectionResource struct {
+}
+
+// CIDRCollectionResourceModel describes the resource data model.
+type CIDRCollectionResourceModel struct {
+	CIDRBlocks        types.Set    `tfsdk:"cidr_blocks"`
+	RemovedCIDRBlocks types.Set    `tfsdk:"removed_cidr_blocks"`
+	Members           types.List   `tfsdk:"members"`
+	ID                types.String `tfsdk:"id"`
+}
+
+func (r *CIDRCollectionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cidr_collection"
+}
+
+func (r *CIDRCollectionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "CIDR collection resource. Maintains a growing list of CIDR blocks, such as a firewall allow-list built from allocations made over time. New CIDR blocks are appended to the end of the list, and existing members keep their position until they are explicitly removed, so the list only changes where it has to.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks that must be members of the collection. Dropping a member from this set is an error unless it is also listed in removed_cidr_blocks.",
+				Required:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"removed_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks to remove from the collection. They must not be listed in cidr_blocks.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"members": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Members of the collection in the order they were added. CIDR blocks added in the same apply are ordered by address.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the members separated by commas.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan plans the members, so that removing a member without listing it
+// in removed_cidr_blocks is reported before anything is applied.
+func (r *CIDRCollectionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan CIDRCollectionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !setKnown(plan.CIDRBlocks) || !setKnown(plan.RemovedCIDRBlocks) {
+		return
+	}
+	var state CIDRCollectionResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+
+	resp.Diagnostics.Append(collectMembers(ctx, &plan, state.Members)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *CIDRCollectionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CIDRCollectionResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(collectMembers(ctx, &data, types.ListNull(types.StringType))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, "created a CIDR collection resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CIDRCollectionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CIDRCollectionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CIDRCollectionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan CIDRCollectionResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state CIDRCollectionResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(collectMembers(ctx, &plan, state.Members)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, "updated a CIDR collection resource")
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *CIDRCollectionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "deleted a CIDR collection resource")
+}
+
+func (r *CIDRCollectionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The ID lists the members in order.
+	members := strings.Split(req.ID, ",")
+	for _, m := range members {
+		if _, err := netip.ParsePrefix(m); err != nil {
+			resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+			return
+		}
+	}
+
+	cidrBlocks, diags := types.SetValueFrom(ctx, types.StringType, members)
+	resp.Diagnostics.Append(diags...)
+	memberList, diags := types.ListValueFrom(ctx, types.StringType, members)
+	resp.Diagnostics.Append(diags...)
+	data := CIDRCollectionResourceModel{
+		CIDRBlocks:        cidrBlocks,
+		RemovedCIDRBlocks: types.SetNull(types.StringType),
+		Members:           memberList,
+		ID:                types.StringValue(req.ID),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a CIDR collection resource")
+}
+
+// collectMembers calculates the model's members from the previous members:
+// removed CIDR blocks are dropped, and new CIDR blocks are appended in address
+// order.
+func collectMembers(ctx context.Context, data *CIDRCollectionResourceModel, previous types.List) (diagnostics diag.Diagnostics) {
+	wanted := parsePrefixSet(ctx, data.CIDRBlocks, &diagnostics)
+	removed := map[netip.Prefix]bool{}
+	for _, p := range parsePrefixSet(ctx, data.RemovedCIDRBlocks, &diagnostics) {
+		removed[p] = true
+	}
+	var previousMembers []string
+	if !previous.IsNull() && !previous.IsUnknown() {
+		diagnostics.Append(previous.ElementsAs(ctx, &previousMembers, false)...)
+	}
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+
+	isWanted := map[netip.Prefix]bool{}
+	for _, p := range wanted {
+		isWanted[p] = true
+		if removed[p] {
+			diagnostics.AddAttributeError(path.Root("removed_cidr_blocks"), "Conflicting CIDR block", fmt.Sprintf("CIDR block %s is listed in both cidr_blocks and removed_cidr_blocks.", p))
+		}
+	}
+
+	members := []string{}
+	isMember := map[netip.Prefix]bool{}
+	for _, m := range previousMembers {
+		p, err := netip.ParsePrefix(m)
+		if err != nil {
+			diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse member CIDR: %q, %v", m, err))
+			continue
+		}
+		if removed[p] {
+			continue
+		}
+		if !isWanted[p] {
+			diagnostics.AddAttributeError(path.Root("cidr_blocks"), "Member removed implicitly", fmt.Sprintf("CIDR block %s is a member of the collection. List it in removed_cidr_blocks to remove it.", p))
+			continue
+		}
+		members = append(members, p.String())
+		isMember[p] = true
+	}
+	for _, p := range sortPrefixes(wanted) {
+		if !isMember[p] {
+			members = append(members, p.String())
+			isMember[p] = true
+		}
+	}
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+
+	memberList, diags := types.ListValueFrom(ctx, types.StringType, members)
+	diagnostics.Append(diags...)
+	data.Members = memberList
+	data.ID = types.StringValue(strings.Join(members, ","))
+	return diagnostics
+}
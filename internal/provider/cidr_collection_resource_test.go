@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCIDRCollectionResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A CIDR block cannot be both wanted and removed
+			{
+				Config: `
+				resource "netcalc_cidr_collection" "test" {
+					cidr_blocks         = ["10.0.2.0/24"]
+					removed_cidr_blocks = ["10.0.2.0/24"]
+				}`,
+				ExpectError: regexp.MustCompile(`Conflicting CIDR block`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				resource "netcalc_cidr_collection" "test" {
+					cidr_blocks = ["10.0.2.0/24", "10.0.0.0/24"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.#", "2"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.1", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "id", "10.0.0.0/24,10.0.2.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_cidr_collection.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// New members are appended
+			{
+				Config: `
+				resource "netcalc_cidr_collection" "test" {
+					cidr_blocks = ["10.0.2.0/24", "10.0.0.0/24", "10.0.1.0/24"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.#", "3"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.1", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.2", "10.0.1.0/24"),
+				),
+			},
+			// Members cannot be dropped without removing them explicitly
+			{
+				Config: `
+				resource "netcalc_cidr_collection" "test" {
+					cidr_blocks = ["10.0.0.0/24", "10.0.1.0/24"]
+				}`,
+				ExpectError: regexp.MustCompile(`List it in\s+removed_cidr_blocks to remove it`),
+			},
+			// Explicitly removed members are dropped
+			{
+				Config: `
+				resource "netcalc_cidr_collection" "test" {
+					cidr_blocks         = ["10.0.0.0/24", "10.0.1.0/24"]
+					removed_cidr_blocks = ["10.0.2.0/24"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.#", "2"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_cidr_collection.test", "members.1", "10.0.1.0/24"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
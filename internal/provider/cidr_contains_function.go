@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDRContainsFunction{}
+
+func NewCIDRContainsFunction() function.Function {
+	return &CIDRContainsFunction{}
+}
+
+// CIDRContainsFunction defines the function implementation.
+type CIDRContainsFunction struct {
+}
+
+func (f *CIDRContainsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrcontains"
+}
+
+func (f *CIDRContainsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Checks whether a CIDR block contains an address or CIDR block",
+		MarkdownDescription: "Returns whether an address or CIDR block lies within a CIDR block, like the netcalc_contains data source, for use in variable validation, preconditions and conditional expressions. A CIDR block is only contained if it lies entirely within the containing CIDR block, and addresses of different IP families are never contained.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "CIDR block to search, e.g. `10.0.0.0/16`.",
+			},
+			function.StringParameter{
+				Name:                "address",
+				MarkdownDescription: "Address or CIDR block to look for, e.g. `10.0.1.4` or `10.0.1.0/24`.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDRContainsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock, address string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock, &address))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	other, err := parseAddressOrPrefix(address)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Unable to parse address or CIDR: %q, %v", address, err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, prefixContains(prefix, other)))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDRContainsFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrcontains("10.0.0.0/16", "10.0.0.300")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address\s+or\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "address" {
+					value = provider::netcalc::cidrcontains("10.0.0.0/16", "10.0.1.4")
+				}
+				output "cidr_block" {
+					value = provider::netcalc::cidrcontains("10.0.0.0/16", "10.0.1.0/24")
+				}
+				output "larger" {
+					value = provider::netcalc::cidrcontains("10.0.0.0/16", "10.0.0.0/8")
+				}
+				output "outside" {
+					value = provider::netcalc::cidrcontains("10.0.0.0/16", "10.1.0.1")
+				}
+				output "ipv6" {
+					value = provider::netcalc::cidrcontains("fd00::/48", "fd00:0:0:1::1")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("address", "true"),
+					resource.TestCheckOutput("cidr_block", "true"),
+					resource.TestCheckOutput("larger", "false"),
+					resource.TestCheckOutput("outside", "false"),
+					resource.TestCheckOutput("ipv6", "true"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDREqualFunction{}
+
+func NewCIDREqualFunction() function.Function {
+	return &CIDREqualFunction{}
+}
+
+// CIDREqualFunction defines the function implementation.
+type CIDREqualFunction struct {
+}
+
+func (f *CIDREqualFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_equal"
+}
+
+func (f *CIDREqualFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Checks whether two CIDR blocks are the same network",
+		MarkdownDescription: "Returns whether two CIDR blocks describe the same network once host bits are masked and IPv6 addresses are compressed, e.g. `10.0.1.5/24` equals `10.0.1.0/24` and `FD00:0:0::/48` equals `fd00::/48`. Use it instead of `==` on values from different sources to avoid false differences caused by formatting.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "First CIDR block, e.g. `10.0.1.0/24`.",
+			},
+			function.StringParameter{
+				Name:                "other_cidr_block",
+				MarkdownDescription: "Second CIDR block, e.g. `10.0.1.5/24`.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDREqualFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock, otherCIDRBlock string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock, &otherCIDRBlock))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	other, otherFuncErr := parsePrefixArgument(1, otherCIDRBlock)
+	resp.Error = function.ConcatFuncErrors(funcErr, otherFuncErr)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, prefix == other))
+}
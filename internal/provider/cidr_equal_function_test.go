@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDREqualFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidr_equal("10.0.1.0/24", "10.0.1.0")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "host_bits" {
+					value = provider::netcalc::cidr_equal("10.0.1.5/24", "10.0.1.0/24")
+				}
+				output "ipv6_notation" {
+					value = provider::netcalc::cidr_equal("FD00:0000:0:0::/48", "fd00::/48")
+				}
+				output "prefix_length" {
+					value = provider::netcalc::cidr_equal("10.0.0.0/16", "10.0.0.0/24")
+				}
+				output "mixed_family" {
+					value = provider::netcalc::cidr_equal("0.0.0.0/0", "::/0")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("host_bits", "true"),
+					resource.TestCheckOutput("ipv6_notation", "true"),
+					resource.TestCheckOutput("prefix_length", "false"),
+					resource.TestCheckOutput("mixed_family", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readPrefixFile reads CIDR blocks from a file, whose format depends on its
+// extension:
+//
+//   - .json files hold an array of CIDR blocks, or an array of objects with
+//     a cidr_block or cidr attribute.
+//   - .csv files hold a CIDR block per row, in the cidr_block or cidr column
+//     if the file has a header, and in the first column otherwise.
+//   - Other files hold a CIDR block per line. Empty lines and comments
+//     starting with # are ignored.
+func readPrefixFile(path string) ([]netip.Prefix, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parsePrefixJSON(b)
+	case ".csv":
+		return parsePrefixCSV(b)
+	default:
+		return parsePrefixLines(b)
+	}
+}
+
+func parsePrefixJSON(b []byte) ([]netip.Prefix, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(b, &elems); err != nil {
+		return nil, fmt.Errorf("expected a JSON array: %w", err)
+	}
+	prefixes := make([]netip.Prefix, 0, len(elems))
+	for i, elem := range elems {
+		var cidr string
+		if err := json.Unmarshal(elem, &cidr); err != nil {
+			var object struct {
+				CIDRBlock string `json:"cidr_block"`
+				CIDR      string `json:"cidr"`
+			}
+			if err := json.Unmarshal(elem, &object); err != nil || object.CIDRBlock == "" && object.CIDR == "" {
+				return nil, fmt.Errorf("element %d: expected a CIDR block or an object with a cidr_block or cidr attribute", i)
+			}
+			cidr = object.CIDRBlock
+			if cidr == "" {
+				cidr = object.CIDR
+			}
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func parsePrefixCSV(b []byte) ([]netip.Prefix, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	var prefixes []netip.Prefix
+	column := 0
+	for row := 0; ; row++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return prefixes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if row == 0 {
+			if i := csvCIDRColumn(record); i >= 0 {
+				column = i
+				continue
+			}
+		}
+		if column >= len(record) || strings.TrimSpace(record[column]) == "" {
+			return nil, fmt.Errorf("line %d: missing CIDR block", line)
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[column]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+}
+
+// csvCIDRColumn returns the index of the cidr_block or cidr column of a CSV
+// header, or -1 if the record is not a header.
+func csvCIDRColumn(header []string) int {
+	for _, name := range []string{"cidr_block", "cidr"} {
+		for i, field := range header {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parsePrefixLines(b []byte) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for i, line := range strings.Split(string(b), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderCIDRFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pool.json":      `["10.0.0.0/16", {"cidr_block": "10.1.0.0/16"}, {"cidr": "10.2.0.0/16"}]`,
+		"claimed.csv":    "name,cidr_block\n# Imported from the spreadsheet\nlegacy,10.0.0.0/24\n\"db, primary\", 10.0.1.0/24\n",
+		"claimed.txt":    "10.0.2.0/24 # VPN\n\n# Reserved\n10.0.3.0/24\n",
+		"invalid.txt":    "10.0.2.0/24\n10.0.3.0\n",
+		"invalid.csv":    "10.0.2.0/24\nname\n",
+		"no_header.csv":  "10.0.4.0/24,vpn\n",
+		"invalid.json":   `{"cidr_blocks": []}`,
+		"no_cidrs.json":  `[{"name": "legacy"}]`,
+		"whitespace.txt": "  10.0.4.0/23  \r\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// File validation
+			{
+				Config:      testAccProviderCIDRFilesConfig(dir, "pool.json", "missing.txt"),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+read\s+claimed\s+CIDR\s+blocks\s+from\s+.*missing.txt`),
+			},
+			{
+				Config:      testAccProviderCIDRFilesConfig(dir, "pool.json", "invalid.txt"),
+				ExpectError: regexp.MustCompile(`invalid.txt:\s+line\s+2:\s+netip.ParsePrefix\("10.0.3.0"\):\s+no\s+'/'`),
+			},
+			{
+				Config:      testAccProviderCIDRFilesConfig(dir, "pool.json", "invalid.csv"),
+				ExpectError: regexp.MustCompile(`invalid.csv:\s+line\s+2:\s+netip.ParsePrefix\("name"\)`),
+			},
+			{
+				Config:      testAccProviderCIDRFilesConfig(dir, "invalid.json", "claimed.txt"),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+read\s+pool\s+CIDR\s+blocks\s+from\s+.*invalid.json:\s+expected\s+a\s+JSON\s+array`),
+			},
+			{
+				Config:      testAccProviderCIDRFilesConfig(dir, "no_cidrs.json", "claimed.txt"),
+				ExpectError: regexp.MustCompile(`element\s+0:\s+expected\s+a\s+CIDR\s+block\s+or\s+an\s+object\s+with\s+a\s+cidr_block\s+or\s+cidr\s+attribute`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccProviderCIDRFilesConfig(dir, "pool.json", "claimed.csv"),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.2.0/23"),
+			},
+			{
+				Config: testAccProviderCIDRFilesConfig(dir, "pool.json", "claimed.txt") + `
+resource "netcalc_subnet" "other" {
+  cidr_mask_length = 24
+}
+`,
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.other", "cidr_block", "10.0.0.0/24"),
+			},
+			{
+				Config: `
+provider "netcalc" {
+  pool_cidr_blocks_file    = ` + fmt.Sprintf("%q", filepath.Join(dir, "whitespace.txt")) + `
+  claimed_cidr_blocks_file = ` + fmt.Sprintf("%q", filepath.Join(dir, "no_header.csv")) + `
+}
+
+resource "netcalc_subnet" "whitespace" {
+  cidr_mask_length = 25
+}
+`,
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.whitespace", "cidr_block", "10.0.5.0/25"),
+			},
+		},
+	})
+}
+
+func testAccProviderCIDRFilesConfig(dir string, poolFile string, claimedFile string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks_file    = %[1]q
+  claimed_cidr_blocks_file = %[2]q
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 23
+}
+`, filepath.Join(dir, poolFile), filepath.Join(dir, claimedFile))
+}
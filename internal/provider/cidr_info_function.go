@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDRInfoFunction{}
+
+func NewCIDRInfoFunction() function.Function {
+	return &CIDRInfoFunction{}
+}
+
+// CIDRInfoFunction defines the function implementation.
+type CIDRInfoFunction struct {
+}
+
+// CIDRInfoModel describes the object returned by the function.
+type CIDRInfoModel struct {
+	CIDRBlock        types.String `tfsdk:"cidr_block"`
+	IPFamily         types.String `tfsdk:"ip_family"`
+	PrefixLength     types.Int64  `tfsdk:"prefix_length"`
+	NetworkAddress   types.String `tfsdk:"network_address"`
+	Netmask          types.String `tfsdk:"netmask"`
+	WildcardMask     types.String `tfsdk:"wildcard_mask"`
+	BroadcastAddress types.String `tfsdk:"broadcast_address"`
+	FirstUsableHost  types.String `tfsdk:"first_usable_host"`
+	LastUsableHost   types.String `tfsdk:"last_usable_host"`
+	HostCount        types.Number `tfsdk:"host_count"`
+}
+
+func (f *CIDRInfoFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrinfo"
+}
+
+func (f *CIDRInfoFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Describes a CIDR block",
+		MarkdownDescription: "Returns an object describing a CIDR block, replacing repeated calls to cidrhost and cidrnetmask. The attributes are the same as those of the netcalc_subnet_info data source: `cidr_block`, `ip_family`, `prefix_length`, `network_address`, `netmask`, `wildcard_mask`, `broadcast_address` (null for IPv6), `first_usable_host`, `last_usable_host` and `host_count`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "CIDR block to describe. Host bits are ignored, e.g. `10.0.1.5/24` is described as `10.0.1.0/24`.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"cidr_block":        types.StringType,
+				"ip_family":         types.StringType,
+				"prefix_length":     types.Int64Type,
+				"network_address":   types.StringType,
+				"netmask":           types.StringType,
+				"wildcard_mask":     types.StringType,
+				"broadcast_address": types.StringType,
+				"first_usable_host": types.StringType,
+				"last_usable_host":  types.StringType,
+				"host_count":        types.NumberType,
+			},
+		},
+	}
+}
+
+func (f *CIDRInfoFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	hosts := subnet.HostRange(prefix)
+
+	info := CIDRInfoModel{
+		CIDRBlock:        types.StringValue(prefix.String()),
+		IPFamily:         types.StringValue(ipFamilyIPv4),
+		PrefixLength:     types.Int64Value(int64(prefix.Bits())),
+		NetworkAddress:   types.StringValue(prefix.Addr().String()),
+		Netmask:          types.StringValue(subnet.Netmask(prefix).String()),
+		WildcardMask:     types.StringValue(subnet.WildcardMask(prefix).String()),
+		BroadcastAddress: types.StringValue(subnet.LastAddr(prefix).String()),
+		FirstUsableHost:  types.StringValue(hosts.Start.String()),
+		LastUsableHost:   types.StringValue(hosts.End.String()),
+		HostCount:        types.NumberValue(new(big.Float).SetInt(subnet.HostCount(prefix))),
+	}
+	if prefix.Addr().Is6() {
+		info.IPFamily = types.StringValue(ipFamilyIPv6)
+		info.BroadcastAddress = types.StringNull()
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, info))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDRInfoFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrinfo("10.0.0.1")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				locals {
+					ipv4 = provider::netcalc::cidrinfo("10.0.1.5/24")
+					ipv6 = provider::netcalc::cidrinfo("fd00::/126")
+				}
+				output "ipv4_cidr_block" {
+					value = local.ipv4.cidr_block
+				}
+				output "ipv4_ip_family" {
+					value = local.ipv4.ip_family
+				}
+				output "ipv4_prefix_length" {
+					value = local.ipv4.prefix_length
+				}
+				output "ipv4_netmask" {
+					value = local.ipv4.netmask
+				}
+				output "ipv4_wildcard_mask" {
+					value = local.ipv4.wildcard_mask
+				}
+				output "ipv4_broadcast_address" {
+					value = local.ipv4.broadcast_address
+				}
+				output "ipv4_first_usable_host" {
+					value = local.ipv4.first_usable_host
+				}
+				output "ipv4_last_usable_host" {
+					value = local.ipv4.last_usable_host
+				}
+				output "ipv4_host_count" {
+					value = local.ipv4.host_count
+				}
+				output "ipv6_ip_family" {
+					value = local.ipv6.ip_family
+				}
+				output "ipv6_broadcast_address_null" {
+					value = local.ipv6.broadcast_address == null
+				}
+				output "ipv6_last_usable_host" {
+					value = local.ipv6.last_usable_host
+				}
+				output "ipv6_host_count" {
+					value = local.ipv6.host_count
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4_cidr_block", "10.0.1.0/24"),
+					resource.TestCheckOutput("ipv4_ip_family", "ipv4"),
+					resource.TestCheckOutput("ipv4_prefix_length", "24"),
+					resource.TestCheckOutput("ipv4_netmask", "255.255.255.0"),
+					resource.TestCheckOutput("ipv4_wildcard_mask", "0.0.0.255"),
+					resource.TestCheckOutput("ipv4_broadcast_address", "10.0.1.255"),
+					resource.TestCheckOutput("ipv4_first_usable_host", "10.0.1.1"),
+					resource.TestCheckOutput("ipv4_last_usable_host", "10.0.1.254"),
+					resource.TestCheckOutput("ipv4_host_count", "254"),
+					resource.TestCheckOutput("ipv6_ip_family", "ipv6"),
+					resource.TestCheckOutput("ipv6_broadcast_address_null", "true"),
+					resource.TestCheckOutput("ipv6_last_usable_host", "fd00::3"),
+					resource.TestCheckOutput("ipv6_host_count", "4"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDROverlapsFunction{}
+var _ function.Function = &CIDRsOverlapFunction{}
+
+func NewCIDROverlapsFunction() function.Function {
+	return &CIDROverlapsFunction{}
+}
+
+// CIDROverlapsFunction defines the function implementation.
+type CIDROverlapsFunction struct {
+}
+
+func (f *CIDROverlapsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidroverlaps"
+}
+
+func (f *CIDROverlapsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Checks whether two CIDR blocks overlap",
+		MarkdownDescription: "Returns whether two CIDR blocks share any address, e.g. for preconditions that keep peered networks apart. CIDR blocks of different IP families never overlap. Use cidrsoverlap to check more than two CIDR blocks.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "First CIDR block, e.g. `10.0.0.0/16`.",
+			},
+			function.StringParameter{
+				Name:                "other_cidr_block",
+				MarkdownDescription: "Second CIDR block, e.g. `10.0.128.0/20`.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDROverlapsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock, otherCIDRBlock string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock, &otherCIDRBlock))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	other, otherFuncErr := parsePrefixArgument(1, otherCIDRBlock)
+	resp.Error = function.ConcatFuncErrors(funcErr, otherFuncErr)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, prefix.Overlaps(other)))
+}
+
+func NewCIDRsOverlapFunction() function.Function {
+	return &CIDRsOverlapFunction{}
+}
+
+// CIDRsOverlapFunction defines the function implementation.
+type CIDRsOverlapFunction struct {
+}
+
+func (f *CIDRsOverlapFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrsoverlap"
+}
+
+func (f *CIDRsOverlapFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Checks whether any of a number of CIDR blocks overlap",
+		MarkdownDescription: "Returns whether any two of the given CIDR blocks share an address. Pass a list with the expansion symbol, e.g. `cidrsoverlap(var.cidr_blocks...)`. Use the netcalc_overlap data source to find out which CIDR blocks overlap.",
+		VariadicParameter: function.StringParameter{
+			Name:                "cidr_blocks",
+			MarkdownDescription: "CIDR blocks to check.",
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDRsOverlapFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlocks []string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlocks))
+	if resp.Error != nil {
+		return
+	}
+
+	prefixes, funcErr := parsePrefixListArgument(0, cidrBlocks)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	overlaps := false
+	for i, a := range prefixes {
+		for _, b := range prefixes[i+1:] {
+			overlaps = overlaps || a.Overlaps(b)
+		}
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, overlaps))
+}
+
+// parsePrefixArgument parses a CIDR block passed as the function argument at
+// the given position.
+func parsePrefixArgument(position int64, s string) (netip.Prefix, *function.FuncError) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, function.NewArgumentFuncError(position, fmt.Sprintf("Unable to parse CIDR: %q, %v", s, err))
+	}
+	return prefix.Masked(), nil
+}
+
+// parsePrefixListArgument parses a list of CIDR blocks passed as the function
+// argument at the given position.
+func parsePrefixListArgument(position int64, cidrs []string) ([]netip.Prefix, *function.FuncError) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, funcErr := parsePrefixArgument(position, cidr)
+		if funcErr != nil {
+			return nil, funcErr
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
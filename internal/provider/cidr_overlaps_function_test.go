@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDROverlapsFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidroverlaps("10.0.0.0/16", "10.0.0.0/33")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "nested" {
+					value = provider::netcalc::cidroverlaps("10.0.0.0/16", "10.0.128.0/20")
+				}
+				output "enclosing" {
+					value = provider::netcalc::cidroverlaps("10.0.128.0/20", "10.0.0.0/8")
+				}
+				output "disjoint" {
+					value = provider::netcalc::cidroverlaps("10.0.0.0/16", "10.1.0.0/16")
+				}
+				output "mixed_family" {
+					value = provider::netcalc::cidroverlaps("10.0.0.0/8", "::/0")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("nested", "true"),
+					resource.TestCheckOutput("enclosing", "true"),
+					resource.TestCheckOutput("disjoint", "false"),
+					resource.TestCheckOutput("mixed_family", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCIDRsOverlapFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrsoverlap("10.0.0.0/16", "10.1.0.0")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				locals {
+					cidr_blocks = ["10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16"]
+				}
+				output "empty" {
+					value = provider::netcalc::cidrsoverlap()
+				}
+				output "disjoint" {
+					value = provider::netcalc::cidrsoverlap(local.cidr_blocks...)
+				}
+				output "overlapping" {
+					value = provider::netcalc::cidrsoverlap("10.0.0.0/16", "10.1.0.0/16", "10.1.4.0/24")
+				}
+				output "ipv6" {
+					value = provider::netcalc::cidrsoverlap("fd00::/48", "fd00:0:1::/48", "fd00::/64")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("empty", "false"),
+					resource.TestCheckOutput("disjoint", "false"),
+					resource.TestCheckOutput("overlapping", "true"),
+					resource.TestCheckOutput("ipv6", "true"),
+				),
+			},
+		},
+	})
+}
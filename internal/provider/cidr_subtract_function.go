@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDRSubtractFunction{}
+
+func NewCIDRSubtractFunction() function.Function {
+	return &CIDRSubtractFunction{}
+}
+
+// CIDRSubtractFunction defines the function implementation.
+type CIDRSubtractFunction struct {
+}
+
+func (f *CIDRSubtractFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrsubtract"
+}
+
+func (f *CIDRSubtractFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the free space of a pool",
+		MarkdownDescription: "Removes the used CIDR blocks from a pool and returns what is left as a list of minimal CIDR blocks in address order. The list is empty when the whole pool is used.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "pool",
+				MarkdownDescription: "CIDR block to subtract from, e.g. `10.0.0.0/16`.",
+			},
+			function.ListParameter{
+				Name:                "used_cidr_blocks",
+				MarkdownDescription: "CIDR blocks to remove. CIDR blocks outside the pool or of the other IP family are ignored.",
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *CIDRSubtractFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var pool string
+	var usedCIDRBlocks []string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &pool, &usedCIDRBlocks))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, pool)
+	used, usedFuncErr := parsePrefixListArgument(1, usedCIDRBlocks)
+	resp.Error = function.ConcatFuncErrors(funcErr, usedFuncErr)
+	if resp.Error != nil {
+		return
+	}
+
+	free := []string{}
+	for _, p := range subnet.Subtract(prefix, used) {
+		free = append(free, p.String())
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, free))
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDRSubtractFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrsubtract("10.0.0.0/16", ["10.0.0.0/24", "10.0.1"])
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "free" {
+					value = join(",", provider::netcalc::cidrsubtract("10.0.0.0/22", ["10.0.0.0/24", "10.0.2.128/25", "fd00::/8"]))
+				}
+				output "unused" {
+					value = join(",", provider::netcalc::cidrsubtract("10.0.0.0/22", []))
+				}
+				output "exhausted" {
+					value = length(provider::netcalc::cidrsubtract("10.0.0.0/22", ["10.0.0.0/16"]))
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("free", "10.0.1.0/24,10.0.2.0/25,10.0.3.0/24"),
+					resource.TestCheckOutput("unused", "10.0.0.0/22"),
+					resource.TestCheckOutput("exhausted", "0"),
+				),
+			},
+		},
+	})
+}
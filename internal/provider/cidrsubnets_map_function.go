@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDRSubnetsMapFunction{}
+
+func NewCIDRSubnetsMapFunction() function.Function {
+	return &CIDRSubnetsMapFunction{}
+}
+
+// CIDRSubnetsMapFunction defines the function implementation.
+type CIDRSubnetsMapFunction struct {
+}
+
+func (f *CIDRSubnetsMapFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrsubnets_map"
+}
+
+func (f *CIDRSubnetsMapFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Divides a CIDR block into named subnets",
+		MarkdownDescription: "A keyed alternative to the built-in cidrsubnets function. Takes a map of names to the number of additional bits of each subnet and returns a map of the same names to the subnet CIDR blocks. Subnets are placed one after another like cidrsubnets does, in lexical order of the names, so `{ app = 8, db = 8 }` gives the same result as `cidrsubnets(prefix, 8, 8)`. As with cidrsubnets, adding a name can move the subnets of the names that sort after it.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "prefix",
+				MarkdownDescription: "CIDR block to divide, e.g. `10.0.0.0/16`.",
+			},
+			function.MapParameter{
+				Name:                "newbits",
+				MarkdownDescription: "Map of subnet names to the number of bits to add to the prefix length, e.g. `{ app = 8, db = 9 }`.",
+				ElementType:         types.Int64Type,
+			},
+		},
+		Return: function.MapReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *CIDRSubnetsMapFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock string
+	var newbits map[string]int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock, &newbits))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	names := sortedKeys(newbits)
+	maskLengths := make([]int, 0, len(names))
+	for _, name := range names {
+		bits := newbits[name]
+		if bits < 0 || bits > int64(prefix.Addr().BitLen()-prefix.Bits()) {
+			resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Invalid newbits %d for %q: must be between 0 and %d for %s", bits, name, prefix.Addr().BitLen()-prefix.Bits(), prefix))
+			return
+		}
+		maskLengths = append(maskLengths, prefix.Bits()+int(bits))
+	}
+
+	subnets, err := subnet.Sequential(prefix, maskLengths)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Unable to divide %s: %v", prefix, err))
+		return
+	}
+
+	cidrs := make(map[string]string, len(names))
+	for i, name := range names {
+		cidrs[name] = subnets[i].String()
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, cidrs))
+}
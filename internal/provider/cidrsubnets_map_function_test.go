@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDRSubnetsMapFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Newbits validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrsubnets_map("10.0.0.0/16", { app = 17 })
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid\s+newbits\s+17\s+for\s+"app"`),
+			},
+			// Capacity validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrsubnets_map("10.0.0.0/24", { app = 1, db = 2, web = 1 })
+				}`,
+				ExpectError: regexp.MustCompile(`subnets\s+do\s+not\s+fit\s+in\s+10.0.0.0/24`),
+			},
+			// Function testing
+			{
+				Config: `
+				locals {
+					subnets = provider::netcalc::cidrsubnets_map("10.0.0.0/16", { web = 8, app = 4, db = 8 })
+				}
+				output "app" {
+					value = local.subnets["app"]
+				}
+				output "db" {
+					value = local.subnets["db"]
+				}
+				output "web" {
+					value = local.subnets["web"]
+				}
+				output "builtin" {
+					value = values(local.subnets) == cidrsubnets("10.0.0.0/16", 4, 8, 8)
+				}
+				output "empty" {
+					value = length(provider::netcalc::cidrsubnets_map("fd00::/48", {}))
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("app", "10.0.0.0/20"),
+					resource.TestCheckOutput("db", "10.0.16.0/24"),
+					resource.TestCheckOutput("web", "10.0.17.0/24"),
+					resource.TestCheckOutput("builtin", "true"),
+					resource.TestCheckOutput("empty", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Values of the claimed_cidr_blocks_outside_pools provider attribute.
+const (
+	claimedOutsidePoolsAllow = "allow"
+	claimedOutsidePoolsWarn  = "warn"
+	claimedOutsidePoolsError = "error"
+)
+
+func claimedOutsidePoolsAttribute() schema.Attribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "What to do about CIDR blocks of `claimed_cidr_blocks` and `claimed_cidr_blocks_file` that are not entirely within a CIDR block of `pool_cidr_blocks`, `pool_cidr_blocks_file`, `aws_ipam_pool` or `pools`, which is usually a typo, such as claiming `10.1.0.0/24` from the pool `10.0.0.0/16`, or a sign that the inventory and the pools disagree. The `existing_cidr_blocks` of `netcalc_subnets` resources are checked against their `pool_cidr_blocks` the same way. One of `allow`, `warn`, which reports a warning, and `error`, which fails the run. CIDR blocks of discovery, remote states, the allocations of `aws_ipam_pool` and the ledger are not checked, since they usually cover more than the pools. Defaults to `allow`.",
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(claimedOutsidePoolsAllow, claimedOutsidePoolsWarn, claimedOutsidePoolsError),
+		},
+	}
+}
+
+// claimedOutsidePools returns the handling of claimed CIDR blocks outside
+// the pools configured by the claimed_cidr_blocks_outside_pools provider
+// attribute.
+func claimedOutsidePools(mode types.String) subnet.AllocationOutsidePools {
+	switch mode.ValueString() {
+	case claimedOutsidePoolsWarn:
+		return subnet.AllocationOutsidePoolsWarn
+	case claimedOutsidePoolsError:
+		return subnet.AllocationOutsidePoolsError
+	default:
+		return subnet.AllocationOutsidePoolsAllow
+	}
+}
+
+// prefixAllocator is a calculator that prefixes can be allocated in.
+type prefixAllocator interface {
+	AddAllocatedPrefix(prefix netip.Prefix) error
+}
+
+// addAllocatedPrefixes marks the CIDR blocks claimed by an attribute as
+// allocated, reporting those outside the pools as the calculator's
+// AllocationOutsidePools is set.
+func addAllocatedPrefixes(calculator prefixAllocator, prefixes []netip.Prefix, attribute path.Path, diagnostics *diag.Diagnostics) {
+	for _, prefix := range prefixes {
+		err := calculator.AddAllocatedPrefix(prefix)
+		if err == nil {
+			continue
+		}
+		var outside *subnet.OutsidePoolsError
+		if !errors.As(err, &outside) {
+			diagnostics.AddAttributeError(attribute, "Allocation error", fmt.Sprintf("Unable to mark %s as allocated: %v", prefix, err))
+			continue
+		}
+		summary := "Claimed CIDR block outside pools"
+		detail := fmt.Sprintf("The claimed CIDR block %s is not entirely within a pool, so either it or the pools are wrong. Check it for typos, or set claimed_cidr_blocks_outside_pools = \"allow\".", prefix)
+		if outside.Allocated {
+			diagnostics.AddAttributeWarning(attribute, summary, detail)
+		} else {
+			diagnostics.AddAttributeError(attribute, summary, detail)
+		}
+	}
+}
+
+// prefixHolder is a calculator that prefixes can be held in.
+type prefixHolder interface {
+	HoldPrefix(prefix netip.Prefix)
+}
+
+// holdPrefixes marks CIDR blocks known to be in use, such as discovered ones
+// or those of the ledger, as allocated without checking them against the
+// pools.
+func holdPrefixes(calculator prefixHolder, prefixes []netip.Prefix) {
+	for _, prefix := range prefixes {
+		calculator.HoldPrefix(prefix)
+	}
+}
+
+// prefixOverlapsAny reports whether a prefix overlaps any of the prefixes.
+func prefixOverlapsAny(prefix netip.Prefix, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Overlaps(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderClaimedOutsidePools(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	// Ledger entries outside the pools are not checked.
+	if err := ledger.NewFileLedger(ledgerPath).Allocate(context.Background(), netip.MustParsePrefix("172.16.0.0/24"), "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderClaimedOutsidePoolsConfig(ledgerPath, "error", `["10.0.0.0/24", "10.1.0.0/24"]`, `[]`),
+				ExpectError: regexp.MustCompile(`The\s+claimed\s+CIDR\s+block\s+10.1.0.0/24\s+is\s+not\s+entirely\s+within\s+a\s+pool`),
+			},
+			// Claims overlapping a pool are still outside it.
+			{
+				Config:      testAccProviderClaimedOutsidePoolsConfig(ledgerPath, "error", `["10.0.0.0/24", "10.0.0.0/15"]`, `[]`),
+				ExpectError: regexp.MustCompile(`The\s+claimed\s+CIDR\s+block\s+10.0.0.0/15\s+is\s+not\s+entirely\s+within\s+a\s+pool`),
+			},
+			// The existing CIDR blocks of netcalc_subnets are checked against
+			// its own pools.
+			{
+				Config:      testAccProviderClaimedOutsidePoolsConfig(ledgerPath, "error", `["10.0.0.0/24"]`, `["172.16.0.0/24"]`),
+				ExpectError: regexp.MustCompile(`The\s+claimed\s+CIDR\s+block\s+172.16.0.0/24\s+is\s+not\s+entirely\s+within\s+a\s+pool`),
+			},
+			// CIDR blocks of named pools count as pools.
+			{
+				Config: testAccProviderClaimedOutsidePoolsConfig(ledgerPath, "error", `["10.0.0.0/24", "192.168.0.0/24"]`, `["172.17.0.0/24"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "172.17.1.0/24"),
+				),
+			},
+			{
+				Config: testAccProviderClaimedOutsidePoolsConfig(ledgerPath, "warn", `["10.0.0.0/24", "10.1.0.0/24"]`, `["172.16.0.0/24", "172.17.0.0/24"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+			},
+		},
+	})
+}
+
+func testAccProviderClaimedOutsidePoolsConfig(ledgerPath string, mode string, claimed string, existing string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks                  = ["10.0.0.0/16"]
+  claimed_cidr_blocks               = %[3]s
+  claimed_cidr_blocks_outside_pools = %[2]q
+  ledger_path                       = %[1]q
+
+  pools = {
+    lab = {
+      cidr_blocks = ["192.168.0.0/16"]
+    }
+  }
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 24
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks     = ["172.17.0.0/16"]
+  existing_cidr_blocks = %[4]s
+  cidr_mask_length     = 24
+  cidr_count           = 1
+}
+`, ledgerPath, mode, claimed, existing)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ContainsDataSource{}
+
+func NewContainsDataSource() datasource.DataSource {
+	return &ContainsDataSource{}
+}
+
+// ContainsDataSource defines the data source implementation.
+type ContainsDataSource struct {
+}
+
+// ContainsDataSourceModel describes the data source data model.
+type ContainsDataSourceModel struct {
+	Address           types.String `tfsdk:"address"`
+	CIDRBlock         types.String `tfsdk:"cidr_block"`
+	CIDRBlocks        types.List   `tfsdk:"cidr_blocks"`
+	Contains          types.Bool   `tfsdk:"contains"`
+	MatchingCIDRBlock types.String `tfsdk:"matching_cidr_block"`
+	ID                types.String `tfsdk:"id"`
+}
+
+func (d *ContainsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_contains"
+}
+
+func (d *ContainsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Contains data source. Checks whether an address or CIDR block falls within a CIDR block or any of a list of CIDR blocks, for use in conditional expressions, preconditions and check blocks.",
+
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address or CIDR block to look for, e.g. `10.0.1.4` or `10.0.1.0/28`. A CIDR block is only contained if it lies entirely within the containing CIDR block.",
+				Required:            true,
+			},
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to search. Exactly one of cidr_block or cidr_blocks must be set.",
+				Optional:            true,
+				Validators: []validator.String{
+					ipAddressValidator{},
+					stringvalidator.ExactlyOneOf(path.MatchRoot("cidr_blocks")),
+				},
+			},
+			"cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks to search.",
+				Optional:            true,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"contains": schema.BoolAttribute{
+				MarkdownDescription: "Whether the address lies within any of the CIDR blocks.",
+				Computed:            true,
+			},
+			"matching_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Most specific CIDR block containing the address, as given in the configuration. When several CIDR blocks are equally specific, the first one listed is used. Null if contains is false.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ContainsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ContainsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	address, err := parseAddressOrPrefix(data.Address.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("address"), "Address parsing error", fmt.Sprintf("Unable to parse address or CIDR: %q, %v", data.Address.ValueString(), err))
+		return
+	}
+	cidrBlocks := []string{data.CIDRBlock.ValueString()}
+	if data.CIDRBlock.IsNull() {
+		cidrBlocks = nil
+		resp.Diagnostics.Append(data.CIDRBlocks.ElementsAs(ctx, &cidrBlocks, false)...)
+	}
+
+	data.MatchingCIDRBlock = types.StringNull()
+	best := -1
+	for _, cidr := range cidrBlocks {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+			continue
+		}
+		prefix = prefix.Masked()
+		if prefix.Bits() > best && prefix.Bits() <= address.Bits() && prefix.Contains(address.Addr()) {
+			best = prefix.Bits()
+			data.MatchingCIDRBlock = types.StringValue(cidr)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Contains = types.BoolValue(best >= 0)
+	data.ID = data.Address
+
+	tflog.Trace(ctx, "read a contains data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseAddressOrPrefix parses a CIDR block or a single address, which is
+// returned as a single address prefix.
+func parseAddressOrPrefix(s string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
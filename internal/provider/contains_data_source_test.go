@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccContainsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_contains" "address" {
+					address     = "10.0.1.4"
+					cidr_blocks = ["192.168.0.0/16", "10.0.0.0/8", "10.0.1.0/24", "fd00::/8"]
+				}
+				data "netcalc_contains" "cidr" {
+					address    = "10.0.1.0/23"
+					cidr_block = "10.0.1.0/24"
+				}
+				data "netcalc_contains" "ipv6" {
+					address    = "fd00::1/64"
+					cidr_block = "fd00::/48"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_contains.address", "id", "10.0.1.4"),
+					resource.TestCheckResourceAttr("data.netcalc_contains.address", "contains", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_contains.address", "matching_cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_contains.cidr", "contains", "false"),
+					resource.TestCheckNoResourceAttr("data.netcalc_contains.cidr", "matching_cidr_block"),
+					resource.TestCheckResourceAttr("data.netcalc_contains.ipv6", "contains", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_contains.ipv6", "matching_cidr_block", "fd00::/48"),
+				),
+			},
+			{
+				Config: `
+				data "netcalc_contains" "invalid" {
+					address    = "10.0.1"
+					cidr_block = "10.0.0.0/8"
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address\s+or\s+CIDR`),
+			},
+		},
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CoverageDataSource{}
+
+func NewCoverageDataSource() datasource.DataSource {
+	return &CoverageDataSource{}
+}
+
+// CoverageDataSource defines the data source implementation.
+type CoverageDataSource struct {
+}
+
+// CoverageDataSourceModel describes the data source data model.
+type CoverageDataSourceModel struct {
+	CIDRBlock             types.String `tfsdk:"cidr_block"`
+	SubnetCIDRBlocks      types.List   `tfsdk:"subnet_cidr_blocks"`
+	Exact                 types.Bool   `tfsdk:"exact"`
+	UncoveredCIDRBlocks   types.List   `tfsdk:"uncovered_cidr_blocks"`
+	OverlappingCIDRBlocks types.List   `tfsdk:"overlapping_cidr_blocks"`
+	OutsideCIDRBlocks     types.List   `tfsdk:"outside_cidr_blocks"`
+	ID                    types.String `tfsdk:"id"`
+}
+
+func (d *CoverageDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_coverage"
+}
+
+func (d *CoverageDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Coverage data source. Checks that a list of subnets exactly covers a parent CIDR block, with no gaps, no overlaps and nothing outside the parent, e.g. as a policy check on an externally managed address plan.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Parent CIDR block.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"subnet_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks of the subnets.",
+				Required:            true,
+				Validators:          []validator.List{listvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"exact": schema.BoolAttribute{
+				MarkdownDescription: "Whether the subnets exactly cover the parent CIDR block, i.e. there are no uncovered, overlapping or outside CIDR blocks.",
+				Computed:            true,
+			},
+			"uncovered_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Parts of the parent CIDR block not covered by any subnet, as the fewest CIDR blocks in address order.",
+				Computed:            true,
+			},
+			"overlapping_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks covered by more than one subnet, in address order.",
+				Computed:            true,
+			},
+			"outside_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Parts of the subnets outside the parent CIDR block, in address order.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the parent CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *CoverageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data CoverageDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	parent, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	parent = parent.Masked()
+	var subnetCIDRs []string
+	resp.Diagnostics.Append(data.SubnetCIDRBlocks.ElementsAs(ctx, &subnetCIDRs, false)...)
+	var subnets []netip.Prefix
+	for _, cidr := range subnetCIDRs {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet_cidr_blocks"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+			continue
+		}
+		subnets = append(subnets, p.Masked())
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uncovered := subnet.Subtract(parent, subnets)
+	var overlapping, outside []netip.Prefix
+	for i, a := range subnets {
+		for _, b := range subnets[i+1:] {
+			if !a.Overlaps(b) {
+				continue
+			}
+			// Prefixes overlap only if one contains the other, so the
+			// overlap is the smaller one.
+			overlap := a
+			if b.Bits() > a.Bits() {
+				overlap = b
+			}
+			overlapping = append(overlapping, overlap)
+		}
+		outside = append(outside, subnet.Subtract(a, []netip.Prefix{parent})...)
+	}
+	overlapping, outside = uniquePrefixes(overlapping), uniquePrefixes(outside)
+
+	uncoveredList, diags := prefixList(ctx, uncovered)
+	resp.Diagnostics.Append(diags...)
+	overlappingList, diags := prefixList(ctx, overlapping)
+	resp.Diagnostics.Append(diags...)
+	outsideList, diags := prefixList(ctx, outside)
+	resp.Diagnostics.Append(diags...)
+	data.UncoveredCIDRBlocks = uncoveredList
+	data.OverlappingCIDRBlocks = overlappingList
+	data.OutsideCIDRBlocks = outsideList
+	data.Exact = types.BoolValue(len(uncovered) == 0 && len(overlapping) == 0 && len(outside) == 0)
+	data.ID = types.StringValue(parent.String())
+
+	tflog.Trace(ctx, "read a coverage data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// uniquePrefixes returns the prefixes sorted with duplicates removed.
+func uniquePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	unique := []netip.Prefix{}
+	for _, p := range sortPrefixes(prefixes) {
+		if len(unique) == 0 || unique[len(unique)-1] != p {
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCoverageDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_coverage" "exact" {
+					cidr_block         = "10.0.0.0/24"
+					subnet_cidr_blocks = ["10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26"]
+				}
+				data "netcalc_coverage" "broken" {
+					cidr_block         = "10.0.0.0/24"
+					subnet_cidr_blocks = ["10.0.0.0/26", "10.0.0.0/27", "10.0.0.128/25", "10.0.1.0/26", "10.0.1.0/26"]
+				}
+				output "uncovered" {
+					value = length(data.netcalc_coverage.exact.uncovered_cidr_blocks)
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_coverage.exact", "id", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.exact", "exact", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.exact", "uncovered_cidr_blocks.#", "0"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.exact", "overlapping_cidr_blocks.#", "0"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.exact", "outside_cidr_blocks.#", "0"),
+					resource.TestCheckOutput("uncovered", "0"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "exact", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "uncovered_cidr_blocks.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "uncovered_cidr_blocks.0", "10.0.0.64/26"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "overlapping_cidr_blocks.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "overlapping_cidr_blocks.0", "10.0.0.0/27"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "overlapping_cidr_blocks.1", "10.0.1.0/26"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "outside_cidr_blocks.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_coverage.broken", "outside_cidr_blocks.0", "10.0.1.0/26"),
+				),
+			},
+		},
+	})
+}
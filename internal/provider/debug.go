@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"strconv"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// debugEnabled returns the value of the debug provider attribute, which
+// defaults to the NETCALC_DEBUG environment variable.
+func debugEnabled(debug types.Bool) bool {
+	if !debug.IsNull() {
+		return debug.ValueBool()
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("NETCALC_DEBUG"))
+	return enabled
+}
+
+// logTracer logs the decisions of allocations with tflog.
+type logTracer struct {
+	ctx context.Context
+}
+
+func (t logTracer) Rejected(candidate netip.Prefix, conflict netip.Prefix, reason string) {
+	fields := map[string]interface{}{
+		"candidate": candidate.String(),
+		"reason":    reason,
+	}
+	if conflict.IsValid() {
+		fields["conflict"] = conflict.String()
+	}
+	tflog.Debug(t.ctx, "rejected candidate CIDR block", fields)
+}
+
+func (t logTracer) Chosen(subnet netip.Prefix, reason string) {
+	tflog.Info(t.ctx, "chose CIDR block", map[string]interface{}{
+		"cidr_block": subnet.String(),
+		"reason":     reason,
+	})
+}
+
+// tracedCalculator returns the calculator of a resource, which logs the
+// decisions of its allocations when debug is enabled.
+func tracedCalculator(ctx context.Context, calculator SubnetCalculator, debug bool) SubnetCalculator {
+	if !debug {
+		return calculator
+	}
+	return calculator.Traced(logTracer{ctx: ctx})
+}
+
+// traceCalculator is a syncCalculator reporting the decisions of its
+// allocations to a tracer.
+type traceCalculator struct {
+	*syncCalculator
+	tracer subnet.Tracer
+}
+
+func (s *syncCalculator) Traced(tracer subnet.Tracer) SubnetCalculator {
+	return traceCalculator{syncCalculator: s, tracer: tracer}
+}
+
+// trace sets the tracer of the calculator, and returns a function resetting
+// it. The caller holds the lock.
+func (t traceCalculator) trace() func() {
+	t.c.Tracer = t.tracer
+	return func() { t.c.Tracer = nil }
+}
+
+func (t traceCalculator) NextAvailableIPv4Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	defer t.trace()()
+	return t.c.NextAvailableIPv4Subnet(ctx, numBits)
+}
+
+func (t traceCalculator) NextAvailableIPv6Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	defer t.trace()()
+	return t.c.NextAvailableIPv6Subnet(ctx, numBits)
+}
+
+func (t traceCalculator) NextAvailableSubnet(ctx context.Context, ipv6 bool, numBits int, strategy subnet.Strategy) (netip.Prefix, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	defer t.trace()()
+	return t.c.NextAvailableSubnet(ctx, ipv6, numBits, strategy)
+}
+
+func (t traceCalculator) NextAvailableSubnetInPools(pools []netip.Prefix, reserved []netip.Prefix, numBits int, strategy subnet.Strategy) (netip.Prefix, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	defer t.trace()()
+	return t.c.NextAvailableSubnetInPools(pools, reserved, numBits, strategy)
+}
+
+var _ SubnetCalculator = traceCalculator{}
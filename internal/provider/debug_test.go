@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccProviderDebug(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "terraform.log")
+	t.Setenv("TF_LOG", "DEBUG")
+	t.Setenv("TF_ACC_LOG_PATH", logPath)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: `
+provider "netcalc" {
+  pool_cidr_blocks     = ["10.0.0.0/22"]
+  claimed_cidr_blocks  = ["10.0.0.0/25"]
+  reserved_cidr_blocks = ["10.0.1.0/24"]
+  debug                = true
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 24
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.2.0/24"),
+					// Every candidate is logged with the reason it was
+					// rejected, and so is the final choice.
+					testAccCheckLogLine(logPath, "[DEBUG]", "rejected candidate CIDR block", "candidate=10.0.0.0/24", "conflict=10.0.0.0/25", `reason="overlaps allocated CIDR block 10.0.0.0/25"`),
+					testAccCheckLogLine(logPath, "[DEBUG]", "rejected candidate CIDR block", "candidate=10.0.1.0/24", "conflict=10.0.1.0/24", `reason="overlaps reserved CIDR block 10.0.1.0/24"`),
+					testAccCheckLogLine(logPath, "[INFO]", "chose CIDR block", "cidr_block=10.0.2.0/24", `reason="first available subnet, with the first_fit strategy"`),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckLogLine checks that a line of a log file contains every part.
+func testAccCheckLogLine(logPath string, parts ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		b, err := os.ReadFile(logPath)
+		if err != nil {
+			return err
+		}
+	lines:
+		for _, line := range strings.Split(string(b), "\n") {
+			for _, part := range parts {
+				if !strings.Contains(line, part) {
+					continue lines
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("no line of the log contains %q", parts)
+	}
+}
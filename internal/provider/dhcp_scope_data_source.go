@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DHCPScopeDataSource{}
+
+func NewDHCPScopeDataSource() datasource.DataSource {
+	return &DHCPScopeDataSource{}
+}
+
+// DHCPScopeDataSource defines the data source implementation.
+type DHCPScopeDataSource struct {
+}
+
+// DHCPScopeDataSourceModel describes the data source data model.
+type DHCPScopeDataSourceModel struct {
+	CIDRBlock    types.String `tfsdk:"cidr_block"`
+	RouterOffset types.Int64  `tfsdk:"router_offset"`
+	ReservedHead types.Int64  `tfsdk:"reserved_head"`
+	ReservedTail types.Int64  `tfsdk:"reserved_tail"`
+	RangeStart   types.String `tfsdk:"range_start"`
+	RangeEnd     types.String `tfsdk:"range_end"`
+	Router       types.String `tfsdk:"router"`
+	Broadcast    types.String `tfsdk:"broadcast"`
+	Netmask      types.String `tfsdk:"netmask"`
+	Exclusions   types.List   `tfsdk:"exclusions"`
+	AddressCount types.Number `tfsdk:"address_count"`
+	ID           types.String `tfsdk:"id"`
+}
+
+// DHCPExclusionModel describes a range of addresses excluded from a scope.
+type DHCPExclusionModel struct {
+	FirstAddress types.String `tfsdk:"first_address"`
+	LastAddress  types.String `tfsdk:"last_address"`
+}
+
+func (d *DHCPScopeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dhcp_scope"
+}
+
+func (d *DHCPScopeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "DHCP scope data source. Computes the parameters of a DHCP scope for a subnet from a few reservation rules: the range of leased addresses, the router, the broadcast address and the addresses excluded from the range.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the subnet.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"router_offset": schema.Int64Attribute{
+				MarkdownDescription: "Host index of the router, following the conventions of Terraform's cidrhost function: 1 is the first address after the network address, and negative offsets count back from the last address. Must be a usable host address. Defaults to 1.",
+				Optional:            true,
+			},
+			"reserved_head": schema.Int64Attribute{
+				MarkdownDescription: "Number of usable host addresses at the start of the subnet left out of the range, e.g. for static assignments. Defaults to 0.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"reserved_tail": schema.Int64Attribute{
+				MarkdownDescription: "Number of usable host addresses at the end of the subnet left out of the range. Defaults to 0.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"range_start": schema.StringAttribute{
+				MarkdownDescription: "First leased address. A router at the start of the range is left out of it.",
+				Computed:            true,
+			},
+			"range_end": schema.StringAttribute{
+				MarkdownDescription: "Last leased address. A router at the end of the range is left out of it.",
+				Computed:            true,
+			},
+			"router": schema.StringAttribute{
+				MarkdownDescription: "Router address, the default gateway of the subnet.",
+				Computed:            true,
+			},
+			"broadcast": schema.StringAttribute{
+				MarkdownDescription: "Broadcast address. Null for IPv6 subnets and IPv4 subnets of a /31 or smaller, which have none.",
+				Computed:            true,
+			},
+			"netmask": schema.StringAttribute{
+				MarkdownDescription: "Netmask in address form, e.g. `255.255.255.0`.",
+				Computed:            true,
+			},
+			"exclusions": schema.ListNestedAttribute{
+				MarkdownDescription: "Ranges of addresses within the range that must not be leased, such as a router inside it, in address order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"first_address": schema.StringAttribute{
+							MarkdownDescription: "First excluded address.",
+							Computed:            true,
+						},
+						"last_address": schema.StringAttribute{
+							MarkdownDescription: "Last excluded address.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"address_count": schema.NumberAttribute{
+				MarkdownDescription: "Number of addresses leased, the size of the range less the exclusions.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DHCPScopeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DHCPScopeDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+	routerOffset := int64(1)
+	if !data.RouterOffset.IsNull() {
+		routerOffset = data.RouterOffset.ValueInt64()
+	}
+
+	scope, err := subnet.NewDHCPScope(prefix, routerOffset, uint64(data.ReservedHead.ValueInt64()), uint64(data.ReservedTail.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid DHCP scope", fmt.Sprintf("Unable to plan a DHCP scope for %s: %v", prefix, err))
+		return
+	}
+
+	exclusions := make([]DHCPExclusionModel, 0, len(scope.Exclusions))
+	for _, e := range scope.Exclusions {
+		exclusions = append(exclusions, DHCPExclusionModel{
+			FirstAddress: types.StringValue(e.Start.String()),
+			LastAddress:  types.StringValue(e.End.String()),
+		})
+	}
+	exclusionList, diags := types.ListValueFrom(ctx, data.Exclusions.ElementType(ctx), exclusions)
+	resp.Diagnostics.Append(diags...)
+	data.Exclusions = exclusionList
+	data.RangeStart = types.StringValue(scope.Range.Start.String())
+	data.RangeEnd = types.StringValue(scope.Range.End.String())
+	data.Router = types.StringValue(scope.Router.String())
+	data.Broadcast = types.StringNull()
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		data.Broadcast = types.StringValue(subnet.LastAddr(prefix).String())
+	}
+	data.Netmask = types.StringValue(subnet.Netmask(prefix).String())
+	data.AddressCount = types.NumberValue(new(big.Float).SetInt(scope.Size()))
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a DHCP scope data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
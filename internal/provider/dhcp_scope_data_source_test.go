@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccDHCPScopeDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Reservation validation
+			{
+				Config: `
+				data "netcalc_dhcp_scope" "full" {
+					cidr_block    = "10.0.1.0/29"
+					reserved_head = 6
+				}`,
+				ExpectError: regexp.MustCompile(`no\s+addresses\s+to\s+lease`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_dhcp_scope" "default" {
+					cidr_block = "10.0.1.0/24"
+				}
+				data "netcalc_dhcp_scope" "reserved" {
+					cidr_block    = "10.0.2.0/24"
+					router_offset = 100
+					reserved_head = 10
+					reserved_tail = 5
+				}
+				data "netcalc_dhcp_scope" "ipv6" {
+					cidr_block = "fd00::/120"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "id", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "range_start", "10.0.1.2"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "range_end", "10.0.1.254"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "router", "10.0.1.1"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "broadcast", "10.0.1.255"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "netmask", "255.255.255.0"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "exclusions.#", "0"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.default", "address_count", "253"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "range_start", "10.0.2.11"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "range_end", "10.0.2.249"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "router", "10.0.2.100"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "exclusions.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "exclusions.0.first_address", "10.0.2.100"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "exclusions.0.last_address", "10.0.2.100"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.reserved", "address_count", "238"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.ipv6", "range_start", "fd00::2"),
+					resource.TestCheckResourceAttr("data.netcalc_dhcp_scope.ipv6", "range_end", "fd00::ff"),
+					resource.TestCheckNoResourceAttr("data.netcalc_dhcp_scope.ipv6", "broadcast"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,314 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &DualStackSubnetResource{}
+var _ resource.ResourceWithImportState = &DualStackSubnetResource{}
+var _ resource.ResourceWithConfigure = &DualStackSubnetResource{}
+var _ resource.ResourceWithModifyPlan = &DualStackSubnetResource{}
+
+func NewDualStackSubnetResource() resource.Resource {
+	return &DualStackSubnetResource{}
+}
+
+// DualStackSubnetResource defines the resource implementation.
+type DualStackSubnetResource struct {
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	utilization   *utilizationWarning
+	maskPolicy    maskLengthPolicy
+	strategy      subnet.Strategy
+	debug         bool
+}
+
+// DualStackSubnetResourceModel describes the resource data model.
+type DualStackSubnetResourceModel struct {
+	IPv4CIDRMaskLength types.Int64  `tfsdk:"ipv4_cidr_mask_length"`
+	IPv6CIDRMaskLength types.Int64  `tfsdk:"ipv6_cidr_mask_length"`
+	Strategy           types.String `tfsdk:"allocation_strategy"`
+	IPv4CIDRBlock      types.String `tfsdk:"ipv4_cidr_block"`
+	IPv6CIDRBlock      types.String `tfsdk:"ipv6_cidr_block"`
+	ID                 types.String `tfsdk:"id"`
+}
+
+func (r *DualStackSubnetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dual_stack_subnet"
+}
+
+func (r *DualStackSubnetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Dual stack subnet resource. Allocates an IPv4 and an IPv6 CIDR block together, for platforms that require both families in every subnet. Either both CIDR blocks are allocated or neither is.",
+
+		Attributes: map[string]schema.Attribute{
+			"ipv4_cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "IPv4 network size in bits. e.g. if you wanted a /24 network, 24 would be the value here.",
+				Required:            true,
+				Validators:          []validator.Int64{int64validator.Between(0, 32)},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"ipv6_cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "IPv6 network size in bits. e.g. if you wanted a /64 network, 64 would be the value here.",
+				Required:            true,
+				Validators:          []validator.Int64{int64validator.Between(0, 128)},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"allocation_strategy": allocationStrategyAttribute(),
+			"ipv4_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Calculated IPv4 CIDR block.",
+				Computed:            true,
+			},
+			"ipv6_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Calculated IPv6 CIDR block.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the IPv4 and IPv6 CIDR blocks separated by a comma.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan checks new mask lengths against the mask length policy, so they
+// are rejected before anything is applied.
+func (r *DualStackSubnetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state DualStackSubnetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ipv4 := types.StringValue(ipFamilyIPv4)
+	ipv6 := types.StringValue(ipFamilyIPv6)
+	var pools []netip.Prefix
+	if r.calculator != nil {
+		pools = append(r.calculator.Pools(false), r.calculator.Pools(true)...)
+	}
+	resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("ipv4_cidr_mask_length"), ipv4, plan.IPv4CIDRMaskLength, ipv4, state.IPv4CIDRMaskLength, pools)...)
+	resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("ipv6_cidr_mask_length"), ipv6, plan.IPv6CIDRMaskLength, ipv6, state.IPv6CIDRMaskLength, pools)...)
+}
+
+func (r *DualStackSubnetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.utilization = data.utilization
+		r.maskPolicy = data.maskPolicy
+		r.strategy = data.strategy
+		r.debug = data.debug
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *DualStackSubnetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DualStackSubnetResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	strategy := allocationStrategy(data.Strategy, r.strategy)
+	calculator := tracedCalculator(ctx, r.calculator, r.debug)
+	ipv4, err := calculator.NextAvailableSubnet(ctx, false, int(data.IPv4CIDRMaskLength.ValueInt64()), strategy)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv4_cidr_mask_length"), "CIDR calculation error", fmt.Sprintf("Unable to calculate next available IPv4 CIDR: %v", err))
+		return
+	}
+	ipv6, err := calculator.NextAvailableSubnet(ctx, true, int(data.IPv6CIDRMaskLength.ValueInt64()), strategy)
+	if err != nil {
+		// Release the IPv4 CIDR block so a failed pair allocates nothing.
+		r.calculator.DeleteAllocatedPrefix(ipv4)
+		resp.Diagnostics.AddAttributeError(path.Root("ipv6_cidr_mask_length"), "CIDR calculation error", fmt.Sprintf("Unable to calculate next available IPv6 CIDR: %v", err))
+		return
+	}
+
+	owner, err := newAllocationOwner()
+	if err != nil {
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, nil, ipv4, ipv6)...)
+	if resp.Diagnostics.HasError() {
+		r.calculator.DeleteAllocatedPrefix(ipv4)
+		r.calculator.DeleteAllocatedPrefix(ipv6)
+		return
+	}
+	resp.Diagnostics.Append(r.webhook.allocated(ctx, "netcalc_dual_stack_subnet", owner, ipv4, ipv6)...)
+	resp.Diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_dual_stack_subnet", owner, ipv4, ipv6)...)
+	resp.Diagnostics.Append(r.utilization.allocated(ctx, ipv4, ipv6)...)
+
+	setDualStackSubnet(&data, ipv4, ipv6)
+	tflog.Info(ctx, "created a dual stack subnet resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DualStackSubnetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DualStackSubnetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if the CIDR blocks are still valid. Losing either of them means the
+	// pair has to be allocated again.
+	for _, cidr := range []types.String{data.IPv4CIDRBlock, data.IPv6CIDRBlock} {
+		p := parsePrefix(cidr, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !r.calculator.PrefixInPools(p) {
+			tflog.Info(ctx, fmt.Sprintf("CIDR block %s is no longer valid; removing resource in order to indicate replacement is needed", p))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DualStackSubnetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan DualStackSubnetResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state DualStackSubnetResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	// Set state values. Changing a mask length replaces the resource, so
+	// nothing is reallocated here.
+	plan.IPv4CIDRBlock = state.IPv4CIDRBlock
+	plan.IPv6CIDRBlock = state.IPv6CIDRBlock
+	plan.ID = state.ID
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *DualStackSubnetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DualStackSubnetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	for _, cidr := range []types.String{data.IPv4CIDRBlock, data.IPv6CIDRBlock} {
+		prefix := parsePrefix(cidr, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		r.calculator.ReleasePrefix(prefix)
+		if r.ledger != nil {
+			if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+				return
+			}
+		}
+		resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_dual_stack_subnet", owner, prefix)...)
+		resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_dual_stack_subnet", owner, prefix)...)
+	}
+	tflog.Info(ctx, "deleted a dual stack subnet resource")
+}
+
+func (r *DualStackSubnetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Parse the CIDRs from the ID.
+	ipv4CIDR, ipv6CIDR, _ := strings.Cut(req.ID, ",")
+	ipv4, err := netip.ParsePrefix(ipv4CIDR)
+	if err != nil || !ipv4.Addr().Is4() {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse IPv4 CIDR from ID: %q, expected ipv4_cidr_block,ipv6_cidr_block", req.ID))
+		return
+	}
+	ipv6, err := netip.ParsePrefix(ipv6CIDR)
+	if err != nil || !ipv6.Addr().Is6() {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse IPv6 CIDR from ID: %q, expected ipv4_cidr_block,ipv6_cidr_block", req.ID))
+		return
+	}
+
+	data := DualStackSubnetResourceModel{
+		IPv4CIDRMaskLength: types.Int64Value(int64(ipv4.Bits())),
+		IPv6CIDRMaskLength: types.Int64Value(int64(ipv6.Bits())),
+	}
+	setDualStackSubnet(&data, ipv4, ipv6)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a dual stack subnet resource")
+}
+
+// setDualStackSubnet saves an allocated pair of CIDR blocks into the model.
+func setDualStackSubnet(data *DualStackSubnetResourceModel, ipv4 netip.Prefix, ipv6 netip.Prefix) {
+	data.IPv4CIDRBlock = types.StringValue(ipv4.String())
+	data.IPv6CIDRBlock = types.StringValue(ipv6.String())
+	data.ID = types.StringValue(ipv4.String() + "," + ipv6.String())
+}
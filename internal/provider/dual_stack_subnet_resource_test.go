@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccDualStackSubnetResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Both CIDR blocks must be available
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/64"]
+					claimed_cidr_blocks = ["fd18:fad4:bce5:4400::/64"]
+				}
+				resource "netcalc_dual_stack_subnet" "test" {
+					ipv4_cidr_mask_length = 24
+					ipv6_cidr_mask_length = 64
+				}`,
+				ExpectError: regexp.MustCompile(`Unable to calculate next available IPv6 CIDR`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccDualStackSubnetResourceConfig(24),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "ipv4_cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "ipv6_cidr_block", "fd18:fad4:bce5:4401::/64"),
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "id", "10.0.0.0/24,fd18:fad4:bce5:4401::/64"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_dual_stack_subnet.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccDualStackSubnetResourceConfig(25),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "ipv4_cidr_block", "10.0.0.0/25"),
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "ipv6_cidr_block", "fd18:fad4:bce5:4401::/64"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccDualStackSubnetResourceConfig(ipv4MaskLength int) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks    = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+  claimed_cidr_blocks = ["fd18:fad4:bce5:4400::/64"]
+}
+
+resource "netcalc_dual_stack_subnet" "test" {
+  ipv4_cidr_mask_length = %d
+  ipv6_cidr_mask_length = 64
+}
+`, ipv4MaskLength)
+}
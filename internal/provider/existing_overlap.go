@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Values of the existing_cidr_blocks_overlap attribute of netcalc_subnets.
+const (
+	existingOverlapAllow = "allow"
+	existingOverlapWarn  = "warn"
+	existingOverlapError = "error"
+)
+
+func existingOverlapAttribute() schema.Attribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "What to do when CIDR blocks added to `existing_cidr_blocks` overlap CIDR blocks already allocated to this resource, which usually means the same addresses are booked twice, once here and once elsewhere. One of `allow`, `warn`, which reports a warning, and `error`, which fails the plan. Defaults to `warn`.",
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(existingOverlapAllow, existingOverlapWarn, existingOverlapError),
+		},
+	}
+}
+
+// checkExistingOverlaps reports the CIDR blocks added to existing_cidr_blocks
+// since the last apply that overlap CIDR blocks allocated to the resource, as
+// configured by its existing_cidr_blocks_overlap attribute.
+func checkExistingOverlaps(ctx context.Context, plan, state SubnetsResourceModel, allocated []netip.Prefix, diagnostics *diag.Diagnostics) {
+	if plan.ExistingCIDRBlocksOverlap.ValueString() == existingOverlapAllow {
+		return
+	}
+	previous := make(map[netip.Prefix]bool)
+	if !state.ExistingCIDRBlocks.IsNull() {
+		for _, prefix := range parsePrefixSet(ctx, state.ExistingCIDRBlocks, diagnostics) {
+			previous[prefix] = true
+		}
+	}
+	for _, prefix := range parsePrefixSet(ctx, plan.ExistingCIDRBlocks, diagnostics) {
+		if previous[prefix] {
+			continue
+		}
+		for _, cidr := range allocated {
+			if !cidr.Overlaps(prefix) {
+				continue
+			}
+			summary := "Existing CIDR block overlaps an allocation"
+			detail := fmt.Sprintf("The CIDR block %s added to existing_cidr_blocks overlaps %s, which is allocated to this resource. It is probably booked twice, here and elsewhere. Check where else it is used, or set existing_cidr_blocks_overlap = \"allow\".", prefix, cidr)
+			if plan.ExistingCIDRBlocksOverlap.ValueString() == existingOverlapError {
+				diagnostics.AddAttributeError(path.Root("existing_cidr_blocks"), summary, detail)
+			} else {
+				diagnostics.AddAttributeWarning(path.Root("existing_cidr_blocks"), summary, detail)
+			}
+		}
+	}
+}
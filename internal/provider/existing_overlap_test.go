@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSubnetsResourceExistingOverlap(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubnetsResourceExistingOverlapConfig(`[]`, "error"),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24"),
+			},
+			// Adding an allocated CIDR block to existing_cidr_blocks fails
+			// when overlaps are errors.
+			{
+				Config:      testAccSubnetsResourceExistingOverlapConfig(`["10.0.1.128/25"]`, "error"),
+				ExpectError: regexp.MustCompile(`The\s+CIDR\s+block\s+10.0.1.128/25\s+added\s+to\s+existing_cidr_blocks\s+overlaps\s+10.0.1.0/24`),
+			},
+			// Overlaps are warnings by default, keeping the allocations.
+			{
+				Config: testAccSubnetsResourceExistingOverlapConfig(`["10.0.1.128/25", "10.0.2.0/24"]`, ""),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24"),
+			},
+			// Only CIDR blocks added since the last apply are checked.
+			{
+				Config: testAccSubnetsResourceExistingOverlapConfig(`["10.0.1.128/25", "10.0.2.0/24"]`, "error"),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24"),
+			},
+			{
+				Config: testAccSubnetsResourceExistingOverlapConfig(`["10.0.1.128/25", "10.0.2.0/24", "10.0.0.0/26"]`, "allow"),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24"),
+			},
+		},
+	})
+}
+
+func testAccSubnetsResourceExistingOverlapConfig(existing string, overlap string) string {
+	attribute := ""
+	if overlap != "" {
+		attribute = fmt.Sprintf("existing_cidr_blocks_overlap = %q", overlap)
+	}
+	return fmt.Sprintf(`
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks     = ["10.0.0.0/16"]
+  existing_cidr_blocks = %[1]s
+  cidr_mask_length     = 24
+  cidr_count           = 2
+  %[2]s
+}
+`, existing, attribute)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FitDataSource{}
+var _ datasource.DataSourceWithConfigure = &FitDataSource{}
+
+func NewFitDataSource() datasource.DataSource {
+	return &FitDataSource{}
+}
+
+// FitDataSource defines the data source implementation.
+type FitDataSource struct {
+	calculator SubnetCalculator
+	strategy   subnet.Strategy
+}
+
+// FitDataSourceModel describes the data source data model.
+type FitDataSourceModel struct {
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	Requests            types.List   `tfsdk:"requests"`
+	Fits                types.Bool   `tfsdk:"fits"`
+	FailedRequestIndex  types.Int64  `tfsdk:"failed_request_index"`
+	FailedRequestName   types.String `tfsdk:"failed_request_name"`
+	LimitingPool        types.String `tfsdk:"limiting_pool"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+func (d *FitDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fit"
+}
+
+func (d *FitDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Fit data source. Checks whether a set of additional subnets, e.g. ten more /24s and two /22s, would fit in the pools, without allocating anything. Only allocations known to the provider are taken into account: those made earlier in the same apply, the provider's `claimed_cidr_blocks`, and the allocated_cidr_blocks given here.",
+
+		Attributes: map[string]schema.Attribute{
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to treat as allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"requests": allocationRequestsAttribute("Requested CIDR blocks. Unlike netcalc_allocation_plan, the largest CIDR blocks are allocated first, which packs them as tightly as possible, so the order of the requests does not matter."),
+			"fits": schema.BoolAttribute{
+				MarkdownDescription: "Whether all requests fit.",
+				Computed:            true,
+			},
+			"failed_request_index": schema.Int64Attribute{
+				MarkdownDescription: "Index in requests of the first request that does not fit in allocation order. Null if all requests fit.",
+				Computed:            true,
+			},
+			"failed_request_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the first request that does not fit. Null if all requests fit or the request has no name.",
+				Computed:            true,
+			},
+			"limiting_pool": schema.StringAttribute{
+				MarkdownDescription: "Pool that ran out of space, in the form of a pool ID made up of the CIDR blocks of the request's IP family. Null if all requests fit.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the pool allocated from.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FitDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+		d.strategy = data.strategy
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *FitDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FitDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var requests []AllocationRequestModel
+	resp.Diagnostics.Append(data.Requests.ElementsAs(ctx, &requests, false)...)
+	resp.Diagnostics.Append(validateAllocationRequests(requests)...)
+	sim, diags := newAllocationSimulation(ctx, d.calculator, d.strategy, data.PoolID, data.AllocatedCIDRBlocks)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	order := make([]int, len(requests))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return requests[order[i]].CIDRMaskLength.ValueInt64() < requests[order[j]].CIDRMaskLength.ValueInt64()
+	})
+
+	data.Fits = types.BoolValue(true)
+	data.FailedRequestIndex = types.Int64Null()
+	data.FailedRequestName = types.StringNull()
+	data.LimitingPool = types.StringNull()
+	for _, i := range order {
+		r := requests[i]
+		if _, err := sim.allocate(ctx, r); err != nil {
+			if ctx.Err() != nil {
+				resp.Diagnostics.AddError("Fit calculation error", fmt.Sprintf("Unable to simulate the allocations: %v", err))
+				return
+			}
+			data.Fits = types.BoolValue(false)
+			data.FailedRequestIndex = types.Int64Value(int64(i))
+			data.FailedRequestName = r.Name
+			data.LimitingPool = types.StringValue(poolID(sim.familyPools(r.IPFamily.ValueString() == ipFamilyIPv6), nil))
+			break
+		}
+	}
+	data.ID = types.StringValue(sim.id)
+
+	tflog.Trace(ctx, "read a fit data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
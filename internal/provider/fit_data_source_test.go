@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFitDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/20", "fd00::/60"]
+					claimed_cidr_blocks = ["10.0.0.0/24"]
+				}
+				data "netcalc_fit" "fits" {
+					requests = [
+						{ name = "small", cidr_mask_length = 24, count = 7 },
+						{ name = "large", cidr_mask_length = 22, count = 2 },
+					]
+				}
+				data "netcalc_fit" "too_many" {
+					requests = [
+						{ name = "ipv4", cidr_mask_length = 24, count = 4 },
+						{ name = "ipv6", ip_family = "ipv6", cidr_mask_length = 64, count = 17 },
+					]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_fit.fits", "id", "10.0.0.0/20,fd00::/60"),
+					resource.TestCheckResourceAttr("data.netcalc_fit.fits", "fits", "true"),
+					resource.TestCheckNoResourceAttr("data.netcalc_fit.fits", "limiting_pool"),
+					resource.TestCheckResourceAttr("data.netcalc_fit.too_many", "fits", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_fit.too_many", "failed_request_index", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_fit.too_many", "failed_request_name", "ipv6"),
+					resource.TestCheckResourceAttr("data.netcalc_fit.too_many", "limiting_pool", "fd00::/60"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FreeSpaceDataSource{}
+var _ datasource.DataSourceWithConfigure = &FreeSpaceDataSource{}
+
+func NewFreeSpaceDataSource() datasource.DataSource {
+	return &FreeSpaceDataSource{}
+}
+
+// FreeSpaceDataSource defines the data source implementation.
+type FreeSpaceDataSource struct {
+	calculator SubnetCalculator
+}
+
+// FreeSpaceDataSourceModel describes the data source data model.
+type FreeSpaceDataSourceModel struct {
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	Pools               types.List   `tfsdk:"pools"`
+	FreeCIDRBlocks      types.List   `tfsdk:"free_cidr_blocks"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+// FreeSpacePoolModel describes the free space of a single pool.
+type FreeSpacePoolModel struct {
+	CIDRBlock        types.String  `tfsdk:"cidr_block"`
+	FreeCIDRBlocks   types.List    `tfsdk:"free_cidr_blocks"`
+	AddressCount     types.Number  `tfsdk:"address_count"`
+	FreeAddressCount types.Number  `tfsdk:"free_address_count"`
+	Utilization      types.Float64 `tfsdk:"utilization"`
+}
+
+func (d *FreeSpaceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_free_space"
+}
+
+func (d *FreeSpaceDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Free space data source. Reports the free CIDR blocks remaining in each pool and how much of each pool is used, e.g. for capacity dashboards or check blocks. Only allocations known to the provider are counted: those made earlier in the same apply, the provider's `claimed_cidr_blocks`, and the allocated_cidr_blocks given here.",
+
+		Attributes: map[string]schema.Attribute{
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to report on instead of the provider's pool_cidr_blocks. The pool's reserved CIDR blocks count as used.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to count as used, e.g. the cidr_blocks of netcalc_subnets resources.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"pools": schema.ListNestedAttribute{
+				MarkdownDescription: "Free space of each pool, in address order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"cidr_block": schema.StringAttribute{
+							MarkdownDescription: "CIDR block of the pool.",
+							Computed:            true,
+						},
+						"free_cidr_blocks": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Free space of the pool as the fewest CIDR blocks that cover it, in address order.",
+							Computed:            true,
+						},
+						"address_count": schema.NumberAttribute{
+							MarkdownDescription: "Number of addresses in the pool.",
+							Computed:            true,
+						},
+						"free_address_count": schema.NumberAttribute{
+							MarkdownDescription: "Number of addresses in the free CIDR blocks.",
+							Computed:            true,
+						},
+						"utilization": schema.Float64Attribute{
+							MarkdownDescription: "Percentage of the pool's addresses in use, from 0 to 100.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"free_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Free CIDR blocks of all pools, in address order with IPv4 first.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the reported pool.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FreeSpaceDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *FreeSpaceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FreeSpaceDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	used := parsePrefixSet(ctx, data.AllocatedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pools []netip.Prefix
+	if data.PoolID.IsNull() {
+		pools = append(d.calculator.Pools(false), d.calculator.Pools(true)...)
+		data.ID = types.StringValue(poolID(pools, nil))
+	} else {
+		var reserved []netip.Prefix
+		var err error
+		pools, reserved, err = parsePoolID(data.PoolID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", data.PoolID.ValueString(), err))
+			return
+		}
+		used = append(used, reserved...)
+		data.ID = data.PoolID
+	}
+
+	poolModels := []FreeSpacePoolModel{}
+	freeBlocks := []netip.Prefix{}
+	for _, pool := range sortPrefixes(pools) {
+		free := d.calculator.FreePrefixesInPool(pool, used)
+		freeList, diags := prefixList(ctx, free)
+		resp.Diagnostics.Append(diags...)
+
+		total := addressCount([]netip.Prefix{pool})
+		freeCount := addressCount(free)
+		usedCount := new(big.Float).Sub(total, freeCount)
+		utilization, _ := new(big.Float).Quo(usedCount.Mul(usedCount, big.NewFloat(100)), total).Float64()
+
+		poolModels = append(poolModels, FreeSpacePoolModel{
+			CIDRBlock:        types.StringValue(pool.String()),
+			FreeCIDRBlocks:   freeList,
+			AddressCount:     types.NumberValue(total),
+			FreeAddressCount: types.NumberValue(freeCount),
+			Utilization:      types.Float64Value(utilization),
+		})
+		freeBlocks = append(freeBlocks, free...)
+	}
+
+	poolList, diags := types.ListValueFrom(ctx, data.Pools.ElementType(ctx), poolModels)
+	resp.Diagnostics.Append(diags...)
+	data.Pools = poolList
+	freeList, diags := prefixList(ctx, freeBlocks)
+	resp.Diagnostics.Append(diags...)
+	data.FreeCIDRBlocks = freeList
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "read a free space data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// addressCount returns the number of addresses in the given non-overlapping
+// prefixes.
+func addressCount(prefixes []netip.Prefix) *big.Float {
+	count := new(big.Int)
+	for _, p := range prefixes {
+		count.Add(count, new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits())))
+	}
+	return new(big.Float).SetInt(count)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFreeSpaceDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				data "netcalc_free_space" "provider" {
+					allocated_cidr_blocks = ["10.0.0.0/24", "10.0.1.0/24"]
+				}
+				data "netcalc_free_space" "pool" {
+					pool_id = "10.1.0.0/24,!10.1.0.0/26"
+				}
+				data "netcalc_free_space" "full" {
+					pool_id               = "10.2.0.0/24"
+					allocated_cidr_blocks = ["10.2.0.0/24"]
+				}
+				output "full" {
+					value = length(data.netcalc_free_space.full.free_cidr_blocks) + length(data.netcalc_free_space.full.pools[0].free_cidr_blocks)
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "id", "10.0.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.cidr_block", "10.0.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.free_cidr_blocks.#", "7"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.free_cidr_blocks.0", "10.0.2.0/23"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.free_cidr_blocks.6", "10.0.128.0/17"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.address_count", "65536"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.free_address_count", "65024"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "pools.0.utilization", "0.78125"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.provider", "free_cidr_blocks.#", "7"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.pool", "id", "10.1.0.0/24,!10.1.0.0/26"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.pool", "free_cidr_blocks.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.pool", "free_cidr_blocks.0", "10.1.0.64/26"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.pool", "free_cidr_blocks.1", "10.1.0.128/25"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.pool", "pools.0.utilization", "25"),
+					resource.TestCheckResourceAttr("data.netcalc_free_space.full", "pools.0.utilization", "100"),
+					resource.TestCheckOutput("full", "0"),
+				),
+			},
+		},
+	})
+}
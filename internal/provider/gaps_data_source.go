@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &GapsDataSource{}
+
+func NewGapsDataSource() datasource.DataSource {
+	return &GapsDataSource{}
+}
+
+// GapsDataSource defines the data source implementation.
+type GapsDataSource struct {
+}
+
+// GapsDataSourceModel describes the data source data model.
+type GapsDataSourceModel struct {
+	CIDRBlock      types.String `tfsdk:"cidr_block"`
+	UsedCIDRBlocks types.Set    `tfsdk:"used_cidr_blocks"`
+	Gaps           types.List   `tfsdk:"gaps"`
+	ID             types.String `tfsdk:"id"`
+}
+
+// GapModel describes a range of unused addresses.
+type GapModel struct {
+	FirstAddress          types.String `tfsdk:"first_address"`
+	LastAddress           types.String `tfsdk:"last_address"`
+	AddressCount          types.Number `tfsdk:"address_count"`
+	CIDRBlocks            types.List   `tfsdk:"cidr_blocks"`
+	LargestCIDRMaskLength types.Int64  `tfsdk:"largest_cidr_mask_length"`
+}
+
+func (d *GapsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_gaps"
+}
+
+func (d *GapsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Gaps data source. Reports the unused ranges of addresses between the used subnets of a pool and their sizes, e.g. to find room for an awkwardly sized subnet.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the pool.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"used_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks in use. CIDR blocks outside the pool are ignored.",
+				Required:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"gaps": schema.ListNestedAttribute{
+				MarkdownDescription: "Ranges of contiguous unused addresses, in address order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"first_address": schema.StringAttribute{
+							MarkdownDescription: "First address of the gap.",
+							Computed:            true,
+						},
+						"last_address": schema.StringAttribute{
+							MarkdownDescription: "Last address of the gap.",
+							Computed:            true,
+						},
+						"address_count": schema.NumberAttribute{
+							MarkdownDescription: "Number of addresses in the gap.",
+							Computed:            true,
+						},
+						"cidr_blocks": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Fewest CIDR blocks that cover the gap, in address order.",
+							Computed:            true,
+						},
+						"largest_cidr_mask_length": schema.Int64Attribute{
+							MarkdownDescription: "Mask length of the largest CIDR block that fits in the gap.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the CIDR block of the pool without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *GapsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data GapsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+	used := parsePrefixSet(ctx, data.UsedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	gaps := []GapModel{}
+	for _, gap := range subnet.Gaps(prefix, used) {
+		cidrs := gap.Prefixes()
+		largest := cidrs[0].Bits()
+		for _, c := range cidrs {
+			if c.Bits() < largest {
+				largest = c.Bits()
+			}
+		}
+		cidrList, diags := types.ListValueFrom(ctx, types.StringType, prefixStrings(cidrs))
+		resp.Diagnostics.Append(diags...)
+		gaps = append(gaps, GapModel{
+			FirstAddress:          types.StringValue(gap.Start.String()),
+			LastAddress:           types.StringValue(gap.End.String()),
+			AddressCount:          types.NumberValue(new(big.Float).SetInt(gap.Size())),
+			CIDRBlocks:            cidrList,
+			LargestCIDRMaskLength: types.Int64Value(int64(largest)),
+		})
+	}
+
+	gapList, diags := types.ListValueFrom(ctx, data.Gaps.ElementType(ctx), gaps)
+	resp.Diagnostics.Append(diags...)
+	data.Gaps = gapList
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a gaps data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
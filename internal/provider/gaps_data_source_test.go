@@ -0,0 +1,41 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccGapsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_gaps" "test" {
+					cidr_block       = "10.0.0.0/24"
+					used_cidr_blocks = ["10.0.0.0/26", "10.0.0.128/27", "192.168.0.0/24"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "id", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.0.first_address", "10.0.0.64"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.0.last_address", "10.0.0.127"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.0.address_count", "64"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.0.largest_cidr_mask_length", "26"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.first_address", "10.0.0.160"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.address_count", "96"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.cidr_blocks.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.cidr_blocks.0", "10.0.0.160/27"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.cidr_blocks.1", "10.0.0.192/26"),
+					resource.TestCheckResourceAttr("data.netcalc_gaps.test", "gaps.1.largest_cidr_mask_length", "26"),
+				),
+			},
+		},
+	})
+}
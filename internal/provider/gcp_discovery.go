@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/discovery"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/api/compute/v1"
+)
+
+// gcpDiscoveryModel describes the gcp_discovery provider attribute.
+type gcpDiscoveryModel struct {
+	Projects               types.List `tfsdk:"projects"`
+	Regions                types.List `tfsdk:"regions"`
+	IncludeSecondaryRanges types.Bool `tfsdk:"include_secondary_ranges"`
+}
+
+func gcpDiscoveryAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Discovers the IP ranges of existing VPC subnetworks in Google Cloud projects and treats them as claimed CIDR blocks, so they do not have to be listed in `claimed_cidr_blocks`. Credentials are taken from the application default credentials, and need the `compute.subnetworks.list` permission.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"projects": schema.ListAttribute{
+				MarkdownDescription: "Projects to discover CIDR blocks in.",
+				ElementType:         types.StringType,
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"regions": schema.ListAttribute{
+				MarkdownDescription: "Regions to discover subnetworks in, in every project. Defaults to every region.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"include_secondary_ranges": schema.BoolAttribute{
+				MarkdownDescription: "Whether to claim the secondary IP ranges of subnetworks, e.g. the ranges of GKE pods and services. Defaults to `true`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newGCPComputeService returns a client for the Compute Engine API. It is
+// replaced in tests.
+var newGCPComputeService = func(ctx context.Context) (*compute.Service, error) {
+	return compute.NewService(ctx)
+}
+
+// discoverGCP returns the CIDR blocks discovered by the gcp_discovery
+// provider attribute, in every project.
+func discoverGCP(ctx context.Context, data gcpDiscoveryModel, diagnostics *diag.Diagnostics) []netip.Prefix {
+	var projects, regions []string
+	diagnostics.Append(data.Projects.ElementsAs(ctx, &projects, false)...)
+	diagnostics.Append(data.Regions.ElementsAs(ctx, &regions, false)...)
+	if diagnostics.HasError() {
+		return nil
+	}
+	service, err := newGCPComputeService(ctx)
+	if err != nil {
+		diagnostics.AddError("GCP discovery error", fmt.Sprintf("Unable to create Compute Engine client: %v", err))
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+	for _, project := range projects {
+		discovered, err := discovery.GCP(ctx, service, discovery.GCPOptions{
+			Project:         project,
+			Regions:         regions,
+			SecondaryRanges: data.IncludeSecondaryRanges.IsNull() || data.IncludeSecondaryRanges.ValueBool(),
+		})
+		if err != nil {
+			diagnostics.AddError("GCP discovery error", fmt.Sprintf("Unable to discover CIDR blocks in project %s: %v", project, err))
+			return nil
+		}
+		tflog.Debug(ctx, "discovered GCP CIDR blocks", map[string]interface{}{
+			"project":     project,
+			"cidr_blocks": fmt.Sprint(discovered),
+		})
+		prefixes = append(prefixes, discovered...)
+	}
+	return prefixes
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// useFakeGCPServer replaces the Compute Engine client with a client of a
+// server with one subnetwork with a secondary range in the project netcalc,
+// and rejects requests for other projects.
+func useFakeGCPServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/projects/netcalc/aggregated/subnetworks" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"message":"Required 'compute.subnetworks.list' permission","errors":[{"reason":"forbidden"}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":{"regions/us-central1":{"subnetworks":[{"name":"gke","ipCidrRange":"10.0.0.0/16","secondaryIpRanges":[{"rangeName":"pods","ipCidrRange":"10.1.0.0/16"}]}]}}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	newService := newGCPComputeService
+	t.Cleanup(func() { newGCPComputeService = newService })
+	newGCPComputeService = func(ctx context.Context) (*compute.Service, error) {
+		return compute.NewService(ctx, option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	}
+}
+
+func TestAccProviderGCPDiscovery(t *testing.T) {
+	useFakeGCPServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Discovery errors
+			{
+				Config:      testAccProviderGCPDiscoveryConfig(`projects = ["netcalc", "other"]`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+discover\s+CIDR\s+blocks\s+in\s+project\s+other:(.|\s)*compute.subnetworks.list`),
+			},
+			// Primary and secondary ranges are claimed
+			{
+				Config: testAccProviderGCPDiscoveryConfig(`projects = ["netcalc"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+			},
+			// Only primary ranges are claimed
+			{
+				Config: testAccProviderGCPDiscoveryConfig(`projects = ["netcalc"]
+    include_secondary_ranges = false`) + `
+resource "netcalc_subnet" "pods" {
+  cidr_mask_length = 17
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_subnet.pods", "cidr_block", "10.1.0.0/17"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderGCPDiscoveryConfig(extra string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/8"]
+
+  gcp_discovery = {
+    %s
+  }
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 16
+}
+`, extra)
+}
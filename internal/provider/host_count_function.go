@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &HostCountFunction{}
+
+func NewHostCountFunction() function.Function {
+	return &HostCountFunction{}
+}
+
+// HostCountFunction defines the function implementation.
+type HostCountFunction struct {
+}
+
+func (f *HostCountFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "host_count"
+}
+
+func (f *HostCountFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the number of addresses in a CIDR block",
+		MarkdownDescription: "Returns the number of addresses in a CIDR block, or the number of usable host addresses, which excludes the network and broadcast addresses of IPv4 subnets larger than a /31. The count is returned as a decimal string, as IPv6 counts such as `18446744073709551616` for a /64 lose precision as numbers in many tools; Terraform converts it to a number where one is needed.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "CIDR block to count, e.g. `10.0.0.0/24`.",
+			},
+			function.BoolParameter{
+				Name:                "usable_only",
+				MarkdownDescription: "Whether to count usable host addresses only.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *HostCountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock string
+	var usableOnly bool
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock, &usableOnly))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	count := subnet.AddressRange{Start: prefix.Addr(), End: subnet.LastAddr(prefix)}.Size()
+	if usableOnly {
+		count = subnet.HostCount(prefix)
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, count.String()))
+}
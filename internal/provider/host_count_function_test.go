@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccHostCountFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::host_count("10.0.0.0", true)
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4_total" {
+					value = provider::netcalc::host_count("10.0.0.0/24", false)
+				}
+				output "ipv4_usable" {
+					value = provider::netcalc::host_count("10.0.0.0/24", true)
+				}
+				output "ipv4_point_to_point" {
+					value = provider::netcalc::host_count("10.0.0.0/31", true)
+				}
+				output "ipv6_usable" {
+					value = provider::netcalc::host_count("fd00::/64", true)
+				}
+				output "ipv6_all" {
+					value = provider::netcalc::host_count("::/0", false)
+				}
+				output "arithmetic" {
+					value = provider::netcalc::host_count("10.0.0.0/24", true) - 4
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4_total", "256"),
+					resource.TestCheckOutput("ipv4_usable", "254"),
+					resource.TestCheckOutput("ipv4_point_to_point", "2"),
+					resource.TestCheckOutput("ipv6_usable", "18446744073709551616"),
+					resource.TestCheckOutput("ipv6_all", "340282366920938463463374607431768211456"),
+					resource.TestCheckOutput("arithmetic", "250"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,304 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &HostResource{}
+var _ resource.ResourceWithImportState = &HostResource{}
+var _ resource.ResourceWithConfigure = &HostResource{}
+var _ resource.ResourceWithModifyPlan = &HostResource{}
+
+func NewHostResource() resource.Resource {
+	return &HostResource{}
+}
+
+// HostResource defines the resource implementation.
+type HostResource struct {
+	calculator SubnetCalculator
+	ledger     ledger.Ledger
+	lock       *allocationLock
+}
+
+// HostResourceModel describes the resource data model.
+type HostResourceModel struct {
+	CIDRBlock     types.String `tfsdk:"cidr_block"`
+	HostIndex     types.Int64  `tfsdk:"host_index"`
+	IPAddress     types.String `tfsdk:"ip_address"`
+	HostCIDRBlock types.String `tfsdk:"host_cidr_block"`
+	ID            types.String `tfsdk:"id"`
+}
+
+func (r *HostResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_host"
+}
+
+func (r *HostResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Host resource. Claims the host address at a fixed offset within a subnet, e.g. a gateway or DNS server, so that no other netcalc_host or netcalc_ip_range can claim it. The host address is recorded in the provider's ledger within the subnet, so host addresses claimed in earlier applies and by other Terraform states are protected as well.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the subnet containing the host.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"host_index": schema.Int64Attribute{
+				MarkdownDescription: "Offset of the host within the subnet, following the conventions of the cidrhost function: 0 is the network address, and negative values count back from the last address.",
+				Required:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"ip_address": schema.StringAttribute{
+				MarkdownDescription: "Claimed host address.",
+				Computed:            true,
+			},
+			"host_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Claimed host address as a single address CIDR block, e.g. `10.0.1.4/32`.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, same as the ip_address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan plans the host address, so it is known before the host is
+// claimed and an index outside the subnet is reported when planning.
+func (r *HostResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan HostResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.CIDRBlock.IsUnknown() || plan.HostIndex.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(calculateHost(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *HostResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *HostResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data HostResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	resp.Diagnostics.Append(calculateHost(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	addr := netip.MustParseAddr(data.IPAddress.ValueString())
+	hostRange := subnet.AddressRange{Start: addr, End: addr}
+	if err := r.calculator.ClaimRange(hostRange); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("host_index"), "Host address already claimed", fmt.Sprintf("Unable to claim host address: %v", err))
+		return
+	}
+
+	// Record the claim under a new owner, within the subnet, so later reads
+	// and other Terraform states can tell whether the host address is taken.
+	owner, err := newAllocationOwner()
+	if err != nil {
+		r.calculator.DeleteAllocatedRange(hostRange)
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	subnetPrefix := netip.MustParsePrefix(data.CIDRBlock.ValueString())
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, []netip.Prefix{subnetPrefix}, netip.PrefixFrom(addr, addr.BitLen()))...)
+	if resp.Diagnostics.HasError() {
+		r.calculator.DeleteAllocatedRange(hostRange)
+		return
+	}
+	tflog.Info(ctx, "created a host resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *HostResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data HostResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if another system has claimed the host address in the ledger.
+	if r.ledger != nil {
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		p := parsePrefix(data.HostCIDRBlock, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ledgerOwner, ok, err := ledger.Owner(ctx, r.ledger, p)
+		if err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to look up allocation of %s: %v", p, err))
+			return
+		}
+		// Imported hosts have no owner, so they cannot be checked.
+		if owner != "" && ok && ledgerOwner != owner {
+			resp.Diagnostics.AddWarning(
+				"Host address claimed elsewhere",
+				fmt.Sprintf("The host address %s is registered to %q in the ledger. The host will be claimed again, which fails until the conflict is resolved.", data.IPAddress.ValueString(), ledgerOwner),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *HostResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan HostResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	// Save updated data into Terraform state. Every configurable attribute
+	// requires replacement, so there is nothing to claim here.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *HostResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data HostResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	addr, err := netip.ParseAddr(data.IPAddress.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Address parsing error", fmt.Sprintf("Unable to parse host address: %q, %v", data.IPAddress.ValueString(), err))
+		return
+	}
+	r.calculator.ReleaseRange(subnet.AddressRange{Start: addr, End: addr})
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if r.ledger != nil {
+		prefix := netip.PrefixFrom(addr, addr.BitLen())
+		if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+			return
+		}
+	}
+	tflog.Info(ctx, "deleted a host resource")
+}
+
+func (r *HostResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The ID is made up of the subnet CIDR block and the host index, e.g.
+	// "10.0.1.0/24,4".
+	cidrBlock, index, ok := strings.Cut(req.ID, ",")
+	hostIndex, err := strconv.ParseInt(index, 10, 64)
+	if !ok || err != nil {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Expected an ID in the form cidr_block,host_index, got: %q", req.ID))
+		return
+	}
+	if _, err := netip.ParsePrefix(cidrBlock); err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+		return
+	}
+
+	data := HostResourceModel{
+		CIDRBlock: types.StringValue(cidrBlock),
+		HostIndex: types.Int64Value(hostIndex),
+	}
+	resp.Diagnostics.Append(calculateHost(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a host resource")
+}
+
+// calculateHost calculates the host address from the model's CIDR block and
+// host index.
+func calculateHost(data *HostResourceModel) (diagnostics diag.Diagnostics) {
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return diagnostics
+	}
+	addr, err := subnet.HostAddr(prefix, data.HostIndex.ValueInt64())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("host_index"), "Invalid host index", fmt.Sprintf("Unable to calculate host address: %v", err))
+		return diagnostics
+	}
+
+	data.IPAddress = types.StringValue(addr.String())
+	data.HostCIDRBlock = types.StringValue(netip.PrefixFrom(addr, addr.BitLen()).String())
+	data.ID = types.StringValue(addr.String())
+	return diagnostics
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHostResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The host index must be within the subnet
+			{
+				Config: `
+				resource "netcalc_host" "test" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 256
+				}`,
+				ExpectError: regexp.MustCompile(`host index 256 is outside 10.0.1.0/24`),
+			},
+			// Host addresses claimed in earlier applies cannot be claimed again
+			{
+				Config: `
+				provider "netcalc" {
+					claimed_cidr_blocks = ["10.0.1.1/32"]
+				}
+				resource "netcalc_host" "test" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 1
+				}`,
+				ExpectError: regexp.MustCompile(`Host address already claimed`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				resource "netcalc_host" "gateway" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 1
+				}
+				resource "netcalc_host" "dns" {
+					cidr_block = "fd00::/64"
+					host_index = -2
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_host.gateway", "ip_address", "10.0.1.1"),
+					resource.TestCheckResourceAttr("netcalc_host.gateway", "host_cidr_block", "10.0.1.1/32"),
+					resource.TestCheckResourceAttr("netcalc_host.gateway", "id", "10.0.1.1"),
+					resource.TestCheckResourceAttr("netcalc_host.dns", "ip_address", "fd00::ffff:ffff:ffff:fffe"),
+					resource.TestCheckResourceAttr("netcalc_host.dns", "host_cidr_block", "fd00::ffff:ffff:ffff:fffe/128"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_host.gateway",
+				ImportState:       true,
+				ImportStateId:     "10.0.1.0/24,1",
+				ImportStateVerify: true,
+			},
+			// Two new hosts cannot claim the same address
+			{
+				Config: `
+				resource "netcalc_host" "gateway" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 1
+				}
+				resource "netcalc_host" "router" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 1
+				}
+				resource "netcalc_host" "other" {
+					cidr_block = "10.0.1.0/24"
+					host_index = 1
+				}`,
+				ExpectError: regexp.MustCompile(`10.0.1.1-10.0.1.1 overlaps allocated range`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccHostResourceLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	l := ledger.NewFileLedger(ledgerPath)
+	// The subnet and one of its hosts were claimed by other Terraform
+	// states.
+	subnet := netip.MustParsePrefix("10.0.1.0/24")
+	if err := l.Allocate(context.Background(), subnet, "subnets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Allocate(context.Background(), netip.MustParsePrefix("10.0.1.2/32"), "other", subnet); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccHostResourceLedgerConfig(ledgerPath, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_host.test", "ip_address", "10.0.1.1"),
+					testAccCheckLedgerActive(l, "10.0.1.0/24", "10.0.1.2/32", "10.0.1.1/32"),
+				),
+			},
+			// A host address claimed elsewhere is claimed again, which fails
+			{
+				PreConfig: func() {
+					prefix := netip.MustParsePrefix("10.0.1.1/32")
+					owner, _, err := ledger.Owner(context.Background(), l, prefix)
+					if err == nil {
+						err = l.Release(context.Background(), prefix, owner)
+					}
+					if err == nil {
+						err = l.Allocate(context.Background(), prefix, "intruder", subnet)
+					}
+					if err != nil {
+						t.Fatalf("unable to reassign %s in the ledger: %v", prefix, err)
+					}
+				},
+				Config:      testAccHostResourceLedgerConfig(ledgerPath, 1),
+				ExpectError: regexp.MustCompile(`Host address already claimed`),
+			},
+			// Host addresses claimed by other Terraform states cannot be
+			// claimed again
+			{
+				Config:      testAccHostResourceLedgerConfig(ledgerPath, 2),
+				ExpectError: regexp.MustCompile(`Host address already claimed`),
+			},
+		},
+	})
+}
+
+func testAccHostResourceLedgerConfig(ledgerPath string, hostIndex int) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %[1]q
+}
+
+resource "netcalc_host" "test" {
+  cidr_block = "10.0.1.0/24"
+  host_index = %[2]d
+}
+`, ledgerPath, hostIndex)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// defaultHostsLimit is the number of hosts listed when no limit is set.
+	defaultHostsLimit = 256
+	// maxHostsLimit bounds the size of the host list, which is kept in state.
+	maxHostsLimit = 65536
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &HostsDataSource{}
+
+func NewHostsDataSource() datasource.DataSource {
+	return &HostsDataSource{}
+}
+
+// HostsDataSource defines the data source implementation.
+type HostsDataSource struct {
+}
+
+// HostsDataSourceModel describes the data source data model.
+type HostsDataSourceModel struct {
+	CIDRBlock     types.String `tfsdk:"cidr_block"`
+	Offset        types.Int64  `tfsdk:"offset"`
+	Limit         types.Int64  `tfsdk:"limit"`
+	HostAddresses types.List   `tfsdk:"host_addresses"`
+	HostCount     types.Number `tfsdk:"host_count"`
+	HasMore       types.Bool   `tfsdk:"has_more"`
+	ID            types.String `tfsdk:"id"`
+}
+
+func (d *HostsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_hosts"
+}
+
+func (d *HostsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: fmt.Sprintf("Hosts data source. Lists the usable host addresses of a subnet, e.g. to create a DNS record or inventory entry per host. Large subnets are listed a page at a time, at most %d addresses per data source.", maxHostsLimit),
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the subnet.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"offset": schema.Int64Attribute{
+				MarkdownDescription: "Number of usable host addresses to skip. Defaults to 0.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"limit": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of host addresses to list, between 1 and %d. Defaults to %d.", maxHostsLimit, defaultHostsLimit),
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.Between(1, maxHostsLimit)},
+			},
+			"host_addresses": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Usable host addresses in address order. The network and broadcast addresses of IPv4 subnets larger than a /31 are not listed.",
+				Computed:            true,
+			},
+			"host_count": schema.NumberAttribute{
+				MarkdownDescription: "Total number of usable host addresses in the subnet.",
+				Computed:            true,
+			},
+			"has_more": schema.BoolAttribute{
+				MarkdownDescription: "Whether there are more host addresses after the listed ones, which can be listed by raising the offset.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *HostsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data HostsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+	offset := uint64(data.Offset.ValueInt64())
+	limit := uint64(defaultHostsLimit)
+	if !data.Limit.IsNull() {
+		limit = uint64(data.Limit.ValueInt64())
+	}
+
+	hosts := subnet.Hosts(prefix, offset, limit)
+	hostAddresses := make([]string, 0, len(hosts))
+	for _, a := range hosts {
+		hostAddresses = append(hostAddresses, a.String())
+	}
+	hostList, diags := types.ListValueFrom(ctx, types.StringType, hostAddresses)
+	resp.Diagnostics.Append(diags...)
+	data.HostAddresses = hostList
+
+	hostCount := subnet.HostCount(prefix)
+	listed := new(big.Int).SetUint64(offset + uint64(len(hosts)))
+	data.HostCount = types.NumberValue(new(big.Float).SetInt(hostCount))
+	data.HasMore = types.BoolValue(listed.Cmp(hostCount) < 0)
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a hosts data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
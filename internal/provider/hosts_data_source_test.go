@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHostsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Limit validation
+			{
+				Config: `
+				data "netcalc_hosts" "too_many" {
+					cidr_block = "10.0.0.0/8"
+					limit      = 100000
+				}`,
+				ExpectError: regexp.MustCompile(`Attribute\s+limit\s+value\s+must\s+be\s+between`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_hosts" "small" {
+					cidr_block = "10.0.1.0/29"
+				}
+				data "netcalc_hosts" "paged" {
+					cidr_block = "fd00::/120"
+					offset     = 16
+					limit      = 2
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "id", "10.0.1.0/29"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "host_addresses.#", "6"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "host_addresses.0", "10.0.1.1"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "host_addresses.5", "10.0.1.6"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "host_count", "6"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.small", "has_more", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.paged", "host_addresses.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.paged", "host_addresses.0", "fd00::10"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.paged", "host_addresses.1", "fd00::11"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.paged", "host_count", "256"),
+					resource.TestCheckResourceAttr("data.netcalc_hosts.paged", "has_more", "true"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &IPFamilyFunction{}
+
+func NewIPFamilyFunction() function.Function {
+	return &IPFamilyFunction{}
+}
+
+// IPFamilyFunction defines the function implementation.
+type IPFamilyFunction struct {
+}
+
+func (f *IPFamilyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ip_family"
+}
+
+func (f *IPFamilyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the IP family of an address or CIDR block",
+		MarkdownDescription: "Returns `ipv4` or `ipv6` for an address or CIDR block, so modules can handle each IP family without matching on the notation. IPv4-mapped IPv6 addresses such as `::ffff:10.0.0.1` are IPv6.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "address",
+				MarkdownDescription: "Address or CIDR block, e.g. `10.0.1.4` or `fd00::/48`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *IPFamilyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var address string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &address))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, err := parseAddressOrPrefix(address)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse address or CIDR: %q, %v", address, err))
+		return
+	}
+
+	ipFamily := ipFamilyIPv4
+	if prefix.Addr().Is6() {
+		ipFamily = ipFamilyIPv6
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, ipFamily))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccIPFamilyFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::ip_family("example.com")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address\s+or\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4_address" {
+					value = provider::netcalc::ip_family("10.0.1.4")
+				}
+				output "ipv4_cidr_block" {
+					value = provider::netcalc::ip_family("10.0.0.0/16")
+				}
+				output "ipv6_address" {
+					value = provider::netcalc::ip_family("fd00::1")
+				}
+				output "ipv6_cidr_block" {
+					value = provider::netcalc::ip_family("fd00::/48")
+				}
+				output "ipv4_mapped" {
+					value = provider::netcalc::ip_family("::ffff:10.0.0.1")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4_address", "ipv4"),
+					resource.TestCheckOutput("ipv4_cidr_block", "ipv4"),
+					resource.TestCheckOutput("ipv6_address", "ipv6"),
+					resource.TestCheckOutput("ipv6_cidr_block", "ipv6"),
+					resource.TestCheckOutput("ipv4_mapped", "ipv6"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &IPMathDataSource{}
+
+func NewIPMathDataSource() datasource.DataSource {
+	return &IPMathDataSource{}
+}
+
+// IPMathDataSource defines the data source implementation.
+type IPMathDataSource struct {
+}
+
+// IPMathDataSourceModel describes the data source data model.
+type IPMathDataSourceModel struct {
+	CIDRBlock     types.String `tfsdk:"cidr_block"`
+	Offset        types.Number `tfsdk:"offset"`
+	IPAddress     types.String `tfsdk:"ip_address"`
+	HostCIDRBlock types.String `tfsdk:"host_cidr_block"`
+	ID            types.String `tfsdk:"id"`
+}
+
+func (d *IPMathDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ip_math"
+}
+
+func (d *IPMathDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "IP math data source. Calculates the address at an offset within a CIDR block, like the cidrhost function but without its limits on IPv6 offsets, so any address of a large IPv6 prefix can be reached.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to calculate the address in.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"offset": schema.NumberAttribute{
+				MarkdownDescription: "Offset of the address within the CIDR block: 0 is the network address, and negative values count back from the last address. Must be a whole number, and may exceed the range of 64-bit integers.",
+				Required:            true,
+			},
+			"ip_address": schema.StringAttribute{
+				MarkdownDescription: "Calculated address.",
+				Computed:            true,
+			},
+			"host_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Calculated address as a single address CIDR block, e.g. `10.0.1.4/32`.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the ip_address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *IPMathDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data IPMathDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	offset := data.Offset.ValueBigFloat()
+	if !offset.IsInt() {
+		resp.Diagnostics.AddAttributeError(path.Root("offset"), "Invalid offset", fmt.Sprintf("Offset must be a whole number, got: %s", offset.Text('g', -1)))
+		return
+	}
+	index, _ := offset.Int(nil)
+	addr, err := subnet.HostAddrBig(prefix, index)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("offset"), "Invalid offset", fmt.Sprintf("Unable to calculate address: %v", err))
+		return
+	}
+
+	data.IPAddress = types.StringValue(addr.String())
+	data.HostCIDRBlock = types.StringValue(netip.PrefixFrom(addr, addr.BitLen()).String())
+	data.ID = types.StringValue(addr.String())
+
+	tflog.Trace(ctx, "read an IP math data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
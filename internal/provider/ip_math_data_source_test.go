@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIPMathDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Offset validation
+			{
+				Config: `
+				data "netcalc_ip_math" "outside" {
+					cidr_block = "10.0.1.0/24"
+					offset     = 256
+				}`,
+				ExpectError: regexp.MustCompile(`host\s+index\s+256\s+is\s+outside\s+10.0.1.0/24`),
+			},
+			{
+				Config: `
+				data "netcalc_ip_math" "fraction" {
+					cidr_block = "10.0.1.0/24"
+					offset     = 1.5
+				}`,
+				ExpectError: regexp.MustCompile(`Offset\s+must\s+be\s+a\s+whole\s+number`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_ip_math" "gateway" {
+					cidr_block = "10.0.1.0/24"
+					offset     = 1
+				}
+				data "netcalc_ip_math" "last" {
+					cidr_block = "10.0.1.0/24"
+					offset     = -2
+				}
+				data "netcalc_ip_math" "big" {
+					cidr_block = "fd00::/48"
+					offset     = 18446744073709551617
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.gateway", "id", "10.0.1.1"),
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.gateway", "ip_address", "10.0.1.1"),
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.gateway", "host_cidr_block", "10.0.1.1/32"),
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.last", "ip_address", "10.0.1.254"),
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.big", "ip_address", "fd00:0:0:1::1"),
+					resource.TestCheckResourceAttr("data.netcalc_ip_math.big", "host_cidr_block", "fd00:0:0:1::1/128"),
+				),
+			},
+		},
+	})
+}
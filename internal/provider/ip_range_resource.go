@@ -0,0 +1,306 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &IPRangeResource{}
+var _ resource.ResourceWithImportState = &IPRangeResource{}
+var _ resource.ResourceWithConfigure = &IPRangeResource{}
+
+func NewIPRangeResource() resource.Resource {
+	return &IPRangeResource{}
+}
+
+// IPRangeResource defines the resource implementation.
+type IPRangeResource struct {
+	calculator SubnetCalculator
+	ledger     ledger.Ledger
+	lock       *allocationLock
+}
+
+// IPRangeResourceModel describes the resource data model.
+type IPRangeResourceModel struct {
+	CIDRBlock    types.String `tfsdk:"cidr_block"`
+	AddressCount types.Int64  `tfsdk:"address_count"`
+	StartAddress types.String `tfsdk:"start_address"`
+	EndAddress   types.String `tfsdk:"end_address"`
+	CIDRBlocks   types.List   `tfsdk:"cidr_blocks"`
+	ID           types.String `tfsdk:"id"`
+}
+
+func (r *IPRangeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ip_range"
+}
+
+func (r *IPRangeResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "IP range resource. Allocates a contiguous range of host addresses within a subnet, such as a DHCP exclusion range or a pool of load balancer VIPs. The CIDR blocks of the range are recorded in the provider's ledger within the subnet, so ranges allocated in earlier applies and by other Terraform states are protected as well.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the subnet to allocate the range from. The network and broadcast addresses of IPv4 subnets are never allocated, nor are any of the provider's claimed_cidr_blocks that fall within the subnet.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"address_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of addresses in the range.",
+				Required:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"start_address": schema.StringAttribute{
+				MarkdownDescription: "First address of the allocated range.",
+				Computed:            true,
+			},
+			"end_address": schema.StringAttribute{
+				MarkdownDescription: "Last address of the allocated range.",
+				Computed:            true,
+			},
+			"cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Smallest list of CIDR blocks that exactly covers the allocated range.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the allocated range in the form `start_address-end_address`.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *IPRangeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *IPRangeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data IPRangeResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix := parsePrefix(data.CIDRBlock, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	addressRange, err := r.calculator.NextAvailableRange(prefix, uint64(data.AddressCount.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Range calculation error", fmt.Sprintf("Unable to calculate next available range: %v", err))
+		return
+	}
+
+	// Record the range under a new owner, within the subnet, so later reads
+	// and other Terraform states can tell whether the range is taken.
+	owner, err := newAllocationOwner()
+	if err != nil {
+		r.calculator.DeleteAllocatedRange(addressRange)
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, []netip.Prefix{prefix}, addressRange.Prefixes()...)...)
+	if resp.Diagnostics.HasError() {
+		r.calculator.DeleteAllocatedRange(addressRange)
+		return
+	}
+	resp.Diagnostics.Append(setIPRange(ctx, &data, addressRange)...)
+
+	tflog.Info(ctx, "created an IP range resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *IPRangeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data IPRangeResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if another system has claimed any part of the range in the ledger.
+	if r.ledger != nil {
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		addressRange, err := subnet.ParseAddressRange(data.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Range parsing error", fmt.Sprintf("Unable to parse range from ID: %q, %v", data.ID.ValueString(), err))
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, p := range addressRange.Prefixes() {
+			ledgerOwner, ok, err := ledger.Owner(ctx, r.ledger, p)
+			if err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to look up allocation of %s: %v", p, err))
+				return
+			}
+			// Imported ranges have no owner, so they cannot be checked.
+			if owner != "" && ok && ledgerOwner != owner {
+				resp.Diagnostics.AddWarning(
+					"IP range claimed elsewhere",
+					fmt.Sprintf("The CIDR block %s of range %s is registered to %q in the ledger. The range will be allocated again.", p, addressRange, ledgerOwner),
+				)
+				resp.State.RemoveResource(ctx)
+				return
+			}
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *IPRangeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan IPRangeResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	// Save updated data into Terraform state. Every configurable attribute
+	// requires replacement, so there is nothing to reallocate here.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *IPRangeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data IPRangeResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	addressRange, err := subnet.ParseAddressRange(data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Range parsing error", fmt.Sprintf("Unable to parse range from ID: %q, %v", data.ID.ValueString(), err))
+		return
+	}
+	r.calculator.ReleaseRange(addressRange)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if r.ledger != nil {
+		for _, p := range addressRange.Prefixes() {
+			if err := r.ledger.Release(ctx, p, owner); err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", p, err))
+				return
+			}
+		}
+	}
+	tflog.Info(ctx, "deleted an IP range resource")
+}
+
+func (r *IPRangeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The ID is made up of the subnet CIDR block and the range, e.g.
+	// "10.0.0.0/24,10.0.0.1-10.0.0.10".
+	cidrBlock, rangeID, ok := strings.Cut(req.ID, ",")
+	if !ok {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Expected an ID in the form cidr_block,start_address-end_address, got: %q", req.ID))
+		return
+	}
+	prefix, err := netip.ParsePrefix(cidrBlock)
+	if err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+		return
+	}
+	addressRange, err := subnet.ParseAddressRange(rangeID)
+	if err != nil {
+		resp.Diagnostics.AddError("Range parsing error", fmt.Sprintf("Unable to parse range from ID: %q, %v", req.ID, err))
+		return
+	}
+	hosts := subnet.HostRange(prefix)
+	if addressRange.Start.Compare(hosts.Start) < 0 || addressRange.End.Compare(hosts.End) > 0 {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Range %s is not within the host addresses of %s", addressRange, prefix))
+		return
+	}
+
+	data := IPRangeResourceModel{
+		CIDRBlock:    types.StringValue(prefix.String()),
+		AddressCount: types.Int64Value(rangeSize(addressRange)),
+	}
+	resp.Diagnostics.Append(setIPRange(ctx, &data, addressRange)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported an IP range resource")
+}
+
+// setIPRange saves an allocated range into the model.
+func setIPRange(ctx context.Context, data *IPRangeResourceModel, addressRange subnet.AddressRange) diag.Diagnostics {
+	cidrBlocks, diagnostics := types.ListValueFrom(ctx, types.StringType, prefixStrings(addressRange.Prefixes()))
+	data.StartAddress = types.StringValue(addressRange.Start.String())
+	data.EndAddress = types.StringValue(addressRange.End.String())
+	data.CIDRBlocks = cidrBlocks
+	data.ID = types.StringValue(addressRange.String())
+	return diagnostics
+}
+
+// rangeSize returns the number of addresses in a range, which always fits in
+// an int64 for ranges created by this resource.
+func rangeSize(addressRange subnet.AddressRange) int64 {
+	var size int64
+	for _, p := range addressRange.Prefixes() {
+		size += int64(1) << (p.Addr().BitLen() - p.Bits())
+	}
+	return size
+}
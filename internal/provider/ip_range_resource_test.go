@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIPRangeResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccIPRangeResourceConfig(10),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "start_address", "10.0.0.1"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "end_address", "10.0.0.10"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "cidr_blocks.#", "5"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "cidr_blocks.0", "10.0.0.1/32"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "cidr_blocks.4", "10.0.0.10/32"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "id", "10.0.0.1-10.0.0.10"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.vips", "start_address", "10.0.0.11"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.vips", "end_address", "10.0.0.26"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.ipv6", "start_address", "fd18:fad4:bce5:4400::"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.ipv6", "end_address", "fd18:fad4:bce5:4400::ff"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.ipv6", "cidr_blocks.0", "fd18:fad4:bce5:4400::/120"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_ip_range.test",
+				ImportState:       true,
+				ImportStateId:     "10.0.0.0/24,10.0.0.1-10.0.0.10",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing. Ranges allocated in earlier applies are
+			// reported to the provider like any other claimed CIDR blocks.
+			{
+				Config: `
+				provider "netcalc" {
+					claimed_cidr_blocks = ["10.0.0.11/32", "10.0.0.12/30", "10.0.0.16/29", "10.0.0.24/31", "10.0.0.26/32"]
+				}` + testAccIPRangeResourceConfig(20),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "start_address", "10.0.0.27"),
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "end_address", "10.0.0.46"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccIPRangeResourceConfig(count int) string {
+	return fmt.Sprintf(`
+resource "netcalc_ip_range" "test" {
+  cidr_block    = "10.0.0.0/24"
+  address_count = %d
+}
+
+resource "netcalc_ip_range" "vips" {
+  cidr_block    = "10.0.0.0/24"
+  address_count = 16
+  depends_on    = [netcalc_ip_range.test]
+}
+
+resource "netcalc_ip_range" "ipv6" {
+  cidr_block    = "fd18:fad4:bce5:4400::/64"
+  address_count = 256
+}
+`, count)
+}
+
+func TestAccIPRangeResourceLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	l := ledger.NewFileLedger(ledgerPath)
+	// The subnet and one of its addresses were claimed by other Terraform
+	// states.
+	subnet := netip.MustParsePrefix("10.0.0.0/24")
+	if err := l.Allocate(context.Background(), subnet, "subnets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Allocate(context.Background(), netip.MustParsePrefix("10.0.0.1/32"), "other", subnet); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccIPRangeResourceLedgerConfig(ledgerPath),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "id", "10.0.0.2-10.0.0.5"),
+					testAccCheckLedgerActive(l, "10.0.0.0/24", "10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31"),
+				),
+			},
+			// A range claimed elsewhere is allocated again
+			{
+				PreConfig: func() {
+					prefix := netip.MustParsePrefix("10.0.0.4/31")
+					owner, _, err := ledger.Owner(context.Background(), l, prefix)
+					if err == nil {
+						err = l.Release(context.Background(), prefix, owner)
+					}
+					if err == nil {
+						err = l.Allocate(context.Background(), prefix, "intruder", subnet)
+					}
+					if err != nil {
+						t.Fatalf("unable to reassign %s in the ledger: %v", prefix, err)
+					}
+				},
+				Config: testAccIPRangeResourceLedgerConfig(ledgerPath),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_ip_range.test", "id", "10.0.0.6-10.0.0.9"),
+					testAccCheckLedgerActive(l, "10.0.0.0/24", "10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/31", "10.0.0.8/31"),
+				),
+			},
+			// Delete testing releases the range in the ledger
+			{
+				Config: fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %q
+}
+`, ledgerPath),
+				Check: testAccCheckLedgerActive(l, "10.0.0.0/24", "10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31"),
+			},
+		},
+	})
+}
+
+func testAccIPRangeResourceLedgerConfig(ledgerPath string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %q
+}
+
+resource "netcalc_ip_range" "test" {
+  cidr_block    = "10.0.0.0/24"
+  address_count = 4
+}
+`, ledgerPath)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	mechanismNAT64     = "nat64"
+	mechanismSixToFour = "6to4"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &IPv4EmbeddingDataSource{}
+
+func NewIPv4EmbeddingDataSource() datasource.DataSource {
+	return &IPv4EmbeddingDataSource{}
+}
+
+// IPv4EmbeddingDataSource defines the data source implementation.
+type IPv4EmbeddingDataSource struct {
+}
+
+// IPv4EmbeddingDataSourceModel describes the data source data model.
+type IPv4EmbeddingDataSourceModel struct {
+	Mechanism   types.String `tfsdk:"mechanism"`
+	NAT64Prefix types.String `tfsdk:"nat64_prefix"`
+	IPv4        types.String `tfsdk:"ipv4"`
+	IPv6        types.String `tfsdk:"ipv6"`
+	ID          types.String `tfsdk:"id"`
+}
+
+func (d *IPv4EmbeddingDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ipv4_embedding"
+}
+
+func (d *IPv4EmbeddingDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "IPv4 embedding data source. Maps IPv4 addresses and CIDR blocks into IPv6 as done by NAT64 (RFC 6052) and 6to4 (RFC 3056), and back, e.g. for DNS64 and firewall configuration in dual-stack transitions. Exactly one of ipv4 or ipv6 must be set, and the other is computed from it.",
+
+		Attributes: map[string]schema.Attribute{
+			"mechanism": schema.StringAttribute{
+				MarkdownDescription: "Transition mechanism, either `nat64` or `6to4`. Defaults to nat64.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(mechanismNAT64, mechanismSixToFour)},
+			},
+			"nat64_prefix": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("NAT64 prefix, a /32, /40, /48, /56, /64 or /96. Defaults to the well-known prefix %s. Only used by nat64.", subnet.NAT64WellKnownPrefix),
+				Optional:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"ipv4": schema.StringAttribute{
+				MarkdownDescription: "IPv4 address or CIDR block, e.g. `192.0.2.33` or `192.0.2.0/24`.",
+				Optional:            true,
+				Computed:            true,
+				Validators:          []validator.String{stringvalidator.ExactlyOneOf(path.MatchRoot("ipv6"))},
+			},
+			"ipv6": schema.StringAttribute{
+				MarkdownDescription: "IPv6 address or CIDR block with an embedded IPv4 address, e.g. `64:ff9b::c000:221` or `64:ff9b::c000:200/120`. When computed, an address if ipv4 is an address, and a CIDR block otherwise.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the IPv4 and IPv6 forms separated by a comma.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *IPv4EmbeddingDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data IPv4EmbeddingDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix := subnet.NAT64WellKnownPrefix
+	if data.Mechanism.ValueString() == mechanismSixToFour {
+		prefix = subnet.SixToFourPrefix
+	} else if !data.NAT64Prefix.IsNull() {
+		p, err := netip.ParsePrefix(data.NAT64Prefix.ValueString())
+		if err != nil || !p.Addr().Is6() || !subnet.ValidNAT64PrefixLength(p.Bits()) {
+			resp.Diagnostics.AddAttributeError(path.Root("nat64_prefix"), "Invalid NAT64 prefix", fmt.Sprintf("NAT64 prefix must be an IPv6 /32, /40, /48, /56, /64 or /96, got: %q", data.NAT64Prefix.ValueString()))
+			return
+		}
+		prefix = p.Masked()
+	}
+
+	if !data.IPv4.IsNull() {
+		ipv4, err := parseAddressOrPrefix(data.IPv4.ValueString())
+		if err == nil && !ipv4.Addr().Is4() {
+			err = fmt.Errorf("not an IPv4 address")
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv4"), "Address parsing error", fmt.Sprintf("Unable to parse IPv4 address or CIDR: %q, %v", data.IPv4.ValueString(), err))
+			return
+		}
+		ipv6, err := subnet.EmbedIPv4(prefix, ipv4)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv4"), "Embedding error", err.Error())
+			return
+		}
+		data.IPv6 = types.StringValue(ipv6.String())
+		if !strings.Contains(data.IPv4.ValueString(), "/") {
+			data.IPv6 = types.StringValue(ipv6.Addr().String())
+		}
+	} else {
+		ipv6, err := parseAddressOrPrefix(data.IPv6.ValueString())
+		if err == nil && !ipv6.Addr().Is6() {
+			err = fmt.Errorf("not an IPv6 address")
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv6"), "Address parsing error", fmt.Sprintf("Unable to parse IPv6 address or CIDR: %q, %v", data.IPv6.ValueString(), err))
+			return
+		}
+		ipv4, err := subnet.ExtractIPv4(prefix, ipv6)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ipv6"), "Extraction error", err.Error())
+			return
+		}
+		data.IPv4 = types.StringValue(ipv4.String())
+		if !strings.Contains(data.IPv6.ValueString(), "/") {
+			data.IPv4 = types.StringValue(ipv4.Addr().String())
+		}
+	}
+	data.ID = types.StringValue(data.IPv4.ValueString() + "," + data.IPv6.ValueString())
+
+	tflog.Trace(ctx, "read an IPv4 embedding data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
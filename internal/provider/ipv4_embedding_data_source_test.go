@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIPv4EmbeddingDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Prefix validation
+			{
+				Config: `
+				data "netcalc_ipv4_embedding" "invalid" {
+					nat64_prefix = "2001:db8::/80"
+					ipv4         = "192.0.2.33"
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid\s+NAT64\s+prefix`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_ipv4_embedding" "address" {
+					ipv4 = "192.0.2.33"
+				}
+				data "netcalc_ipv4_embedding" "cidr" {
+					nat64_prefix = "2001:db8:100::/40"
+					ipv4         = "192.0.2.0/24"
+				}
+				data "netcalc_ipv4_embedding" "reverse" {
+					ipv6 = "64:ff9b::c633:6401"
+				}
+				data "netcalc_ipv4_embedding" "six_to_four" {
+					mechanism = "6to4"
+					ipv4      = "192.0.2.0/24"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_ipv4_embedding.address", "ipv6", "64:ff9b::c000:221"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv4_embedding.address", "id", "192.0.2.33,64:ff9b::c000:221"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv4_embedding.cidr", "ipv6", "2001:db8:1c0:2::/64"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv4_embedding.reverse", "ipv4", "198.51.100.1"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv4_embedding.six_to_four", "ipv6", "2002:c000:200::/40"),
+				),
+			},
+		},
+	})
+}
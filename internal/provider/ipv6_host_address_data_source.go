@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &IPv6HostAddressDataSource{}
+
+func NewIPv6HostAddressDataSource() datasource.DataSource {
+	return &IPv6HostAddressDataSource{}
+}
+
+// IPv6HostAddressDataSource defines the data source implementation.
+type IPv6HostAddressDataSource struct {
+}
+
+// IPv6HostAddressDataSourceModel describes the data source data model.
+type IPv6HostAddressDataSourceModel struct {
+	CIDRBlock     types.String `tfsdk:"cidr_block"`
+	MACAddress    types.String `tfsdk:"mac_address"`
+	StableKey     types.String `tfsdk:"stable_key"`
+	IPAddress     types.String `tfsdk:"ip_address"`
+	HostCIDRBlock types.String `tfsdk:"host_cidr_block"`
+	ID            types.String `tfsdk:"id"`
+}
+
+func (d *IPv6HostAddressDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ipv6_host_address"
+}
+
+func (d *IPv6HostAddressDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "IPv6 host address data source. Computes a predictable host address in an IPv6 subnet, either the modified EUI-64 address of a MAC address or a stable address derived from a key such as a host name. Exactly one of mac_address or stable_key must be set.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "IPv6 CIDR block of the subnet, usually a /64. Must be a /64 or shorter with mac_address.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"mac_address": schema.StringAttribute{
+				MarkdownDescription: "48-bit or 64-bit MAC address to derive a modified EUI-64 interface identifier from, e.g. `00:1a:2b:3c:4d:5e`.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.ExactlyOneOf(path.MatchRoot("stable_key"))},
+			},
+			"stable_key": schema.StringAttribute{
+				MarkdownDescription: "Key to derive the host bits from, e.g. a host name. The host bits are taken from the SHA-256 digest of the CIDR block and the key, so the same key yields the same address in the same subnet and different addresses in different subnets. Distinct keys can collide in small subnets.",
+				Optional:            true,
+			},
+			"ip_address": schema.StringAttribute{
+				MarkdownDescription: "Host address.",
+				Computed:            true,
+			},
+			"host_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Host address as a /128 CIDR block.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the host address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *IPv6HostAddressDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data IPv6HostAddressDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err == nil && !prefix.Addr().Is6() {
+		err = fmt.Errorf("not an IPv6 CIDR block")
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+
+	var addr netip.Addr
+	if data.MACAddress.IsNull() {
+		addr = subnet.StableAddr(prefix, data.StableKey.ValueString())
+	} else {
+		mac, err := net.ParseMAC(data.MACAddress.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("mac_address"), "MAC address parsing error", fmt.Sprintf("Unable to parse MAC address: %q, %v", data.MACAddress.ValueString(), err))
+			return
+		}
+		if addr, err = subnet.EUI64Addr(prefix, mac); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("mac_address"), "EUI-64 error", fmt.Sprintf("Unable to compute the EUI-64 address: %v", err))
+			return
+		}
+	}
+
+	data.IPAddress = types.StringValue(addr.String())
+	data.HostCIDRBlock = types.StringValue(netip.PrefixFrom(addr, addr.BitLen()).String())
+	data.ID = data.IPAddress
+
+	tflog.Trace(ctx, "read an IPv6 host address data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIPv6HostAddressDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Prefix length validation
+			{
+				Config: `
+				data "netcalc_ipv6_host_address" "too_small" {
+					cidr_block  = "2001:db8:1:2::/80"
+					mac_address = "00:1a:2b:3c:4d:5e"
+				}`,
+				ExpectError: regexp.MustCompile(`EUI-64\s+error`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_ipv6_host_address" "eui64" {
+					cidr_block  = "2001:db8:1:2::/64"
+					mac_address = "00:1a:2b:3c:4d:5e"
+				}
+				data "netcalc_ipv6_host_address" "stable" {
+					cidr_block = "2001:db8:1:2::/64"
+					stable_key = "web-1"
+				}
+				data "netcalc_ipv6_host_address" "stable_again" {
+					cidr_block = "2001:db8:1:2::/64"
+					stable_key = "web-1"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_ipv6_host_address.eui64", "ip_address", "2001:db8:1:2:21a:2bff:fe3c:4d5e"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv6_host_address.eui64", "host_cidr_block", "2001:db8:1:2:21a:2bff:fe3c:4d5e/128"),
+					resource.TestCheckResourceAttr("data.netcalc_ipv6_host_address.eui64", "id", "2001:db8:1:2:21a:2bff:fe3c:4d5e"),
+					resource.TestMatchResourceAttr("data.netcalc_ipv6_host_address.stable", "ip_address", regexp.MustCompile(`^2001:db8:1:2:`)),
+					resource.TestCheckResourceAttrPair("data.netcalc_ipv6_host_address.stable", "ip_address", "data.netcalc_ipv6_host_address.stable_again", "ip_address"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ExpandIPv6Function{}
+var _ function.Function = &CompressIPv6Function{}
+
+func NewExpandIPv6Function() function.Function {
+	return &ExpandIPv6Function{}
+}
+
+// ExpandIPv6Function defines the function implementation.
+type ExpandIPv6Function struct {
+}
+
+func (f *ExpandIPv6Function) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "expand_ipv6"
+}
+
+func (f *ExpandIPv6Function) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Writes an IPv6 address in full form",
+		MarkdownDescription: "Writes an IPv6 address or CIDR block with all eight groups of four hexadecimal digits, e.g. `fd00::1` becomes `fd00:0000:0000:0000:0000:0000:0000:0001`. Host bits and the prefix length of a CIDR block are kept. IPv4 addresses and CIDR blocks are returned unchanged, so lists of both IP families can be normalized in one go.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "address",
+				MarkdownDescription: "Address or CIDR block, e.g. `fd00::1` or `fd00::/48`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ExpandIPv6Function) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var address string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &address))
+	if resp.Error != nil {
+		return
+	}
+
+	expanded, err := formatAddressOrPrefix(address, netip.Addr.StringExpanded)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse address or CIDR: %q, %v", address, err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, expanded))
+}
+
+func NewCompressIPv6Function() function.Function {
+	return &CompressIPv6Function{}
+}
+
+// CompressIPv6Function defines the function implementation.
+type CompressIPv6Function struct {
+}
+
+func (f *CompressIPv6Function) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "compress_ipv6"
+}
+
+func (f *CompressIPv6Function) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Writes an IPv6 address in canonical form",
+		MarkdownDescription: "Writes an IPv6 address or CIDR block in the canonical short form of RFC 5952, e.g. `FD00:0:0:0:0:0:0:0001` becomes `fd00::1`. Host bits and the prefix length of a CIDR block are kept. IPv4 addresses and CIDR blocks are returned unchanged, so lists of both IP families can be normalized in one go.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "address",
+				MarkdownDescription: "Address or CIDR block, e.g. `fd00:0:0:0::1` or `fd00:0:0::/48`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *CompressIPv6Function) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var address string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &address))
+	if resp.Error != nil {
+		return
+	}
+
+	compressed, err := formatAddressOrPrefix(address, netip.Addr.String)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse address or CIDR: %q, %v", address, err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, compressed))
+}
+
+// formatAddressOrPrefix rewrites an IPv6 address or CIDR block with the given
+// notation, keeping host bits and the prefix length. IPv4 addresses are
+// written in dotted decimal form.
+func formatAddressOrPrefix(s string, format func(netip.Addr) string) (string, error) {
+	formatAddr := func(addr netip.Addr) string {
+		if addr.Is6() {
+			return format(addr)
+		}
+		return addr.String()
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return formatAddr(addr), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%d", formatAddr(prefix.Addr()), prefix.Bits()), nil
+}
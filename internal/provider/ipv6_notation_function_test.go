@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccExpandIPv6Function(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::expand_ipv6("fd00:::1")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address\s+or\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "address" {
+					value = provider::netcalc::expand_ipv6("FD00::1")
+				}
+				output "cidr_block" {
+					value = provider::netcalc::expand_ipv6("2001:db8::1/64")
+				}
+				output "ipv4" {
+					value = provider::netcalc::expand_ipv6("10.0.0.0/16")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("address", "fd00:0000:0000:0000:0000:0000:0000:0001"),
+					resource.TestCheckOutput("cidr_block", "2001:0db8:0000:0000:0000:0000:0000:0001/64"),
+					resource.TestCheckOutput("ipv4", "10.0.0.0/16"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCompressIPv6Function(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::compress_ipv6("fd00::/129")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address\s+or\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "address" {
+					value = provider::netcalc::compress_ipv6("FD00:0000:0000:0000:0000:0000:0000:0001")
+				}
+				output "cidr_block" {
+					value = provider::netcalc::compress_ipv6("2001:0db8:0:0:0:0:0:1/64")
+				}
+				output "ipv4" {
+					value = provider::netcalc::compress_ipv6("10.0.0.1")
+				}
+				output "equal" {
+					value = provider::netcalc::compress_ipv6("2001:DB8:0:0:1::1") == provider::netcalc::compress_ipv6("2001:db8::1:0:0:1")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("address", "fd00::1"),
+					resource.TestCheckOutput("cidr_block", "2001:db8::1/64"),
+					resource.TestCheckOutput("ipv4", "10.0.0.1"),
+					resource.TestCheckOutput("equal", "true"),
+				),
+			},
+		},
+	})
+}
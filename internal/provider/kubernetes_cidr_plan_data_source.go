@@ -0,0 +1,305 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// maxKubernetesNodes bounds the number of nodes planned per cluster.
+	maxKubernetesNodes = 65536
+
+	// Default mask lengths of a node's pod CIDR block and of the service CIDR
+	// block, matching the defaults of common managed Kubernetes offerings.
+	defaultIPv4NodePodMaskLength = 24
+	defaultIPv6NodePodMaskLength = 80
+	defaultIPv4ServiceMaskLength = 20
+	defaultIPv6ServiceMaskLength = 112
+	// defaultIPv6NodeMaskLength is the mask length of IPv6 node subnets, which
+	// cloud networks require to be a /64.
+	defaultIPv6NodeMaskLength = 64
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &KubernetesCIDRPlanDataSource{}
+var _ datasource.DataSourceWithConfigure = &KubernetesCIDRPlanDataSource{}
+
+func NewKubernetesCIDRPlanDataSource() datasource.DataSource {
+	return &KubernetesCIDRPlanDataSource{}
+}
+
+// KubernetesCIDRPlanDataSource defines the data source implementation.
+type KubernetesCIDRPlanDataSource struct {
+	calculator SubnetCalculator
+	strategy   subnet.Strategy
+}
+
+// KubernetesCIDRPlanDataSourceModel describes the data source data model.
+type KubernetesCIDRPlanDataSourceModel struct {
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	Clusters            types.List   `tfsdk:"clusters"`
+	Plans               types.List   `tfsdk:"plans"`
+	Fulfilled           types.Bool   `tfsdk:"fulfilled"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+// KubernetesClusterModel describes the networks a cluster needs.
+type KubernetesClusterModel struct {
+	Name                  types.String `tfsdk:"name"`
+	IPFamily              types.String `tfsdk:"ip_family"`
+	MaxNodes              types.Int64  `tfsdk:"max_nodes"`
+	NodePodCIDRMaskLength types.Int64  `tfsdk:"node_pod_cidr_mask_length"`
+	ServiceCIDRMaskLength types.Int64  `tfsdk:"service_cidr_mask_length"`
+	NodeCIDRMaskLength    types.Int64  `tfsdk:"node_cidr_mask_length"`
+}
+
+// KubernetesCIDRPlanModel describes the CIDR blocks planned for a cluster.
+type KubernetesCIDRPlanModel struct {
+	Name             types.String `tfsdk:"name"`
+	NodeCIDRBlock    types.String `tfsdk:"node_cidr_block"`
+	PodCIDRBlock     types.String `tfsdk:"pod_cidr_block"`
+	ServiceCIDRBlock types.String `tfsdk:"service_cidr_block"`
+	Fulfilled        types.Bool   `tfsdk:"fulfilled"`
+}
+
+func (d *KubernetesCIDRPlanDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubernetes_cidr_plan"
+}
+
+func (d *KubernetesCIDRPlanDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Kubernetes CIDR plan data source. Plans non-overlapping node, pod and service CIDR blocks for a set of clusters from the pools, sizing the pod CIDR block so every node gets a pod CIDR block of its own. Like netcalc_allocation_plan, nothing is allocated: pass the planned CIDR blocks to netcalc_subnet resources or cluster configuration to use them.",
+
+		Attributes: map[string]schema.Attribute{
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to treat as allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"clusters": schema.ListNestedAttribute{
+				MarkdownDescription: "Clusters to plan, allocated in order. Each cluster's pod, node and service CIDR blocks are allocated in that order.",
+				Required:            true,
+				Validators:          []validator.List{listvalidator.SizeAtLeast(1)},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name identifying the cluster in the plans.",
+							Required:            true,
+						},
+						"ip_family": schema.StringAttribute{
+							MarkdownDescription: "IP family of the cluster's networks, either ipv4 or ipv6. Defaults to ipv4.",
+							Optional:            true,
+							Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6)},
+						},
+						"max_nodes": schema.Int64Attribute{
+							MarkdownDescription: fmt.Sprintf("Maximum number of nodes in the cluster, between 1 and %d.", maxKubernetesNodes),
+							Required:            true,
+							Validators:          []validator.Int64{int64validator.Between(1, maxKubernetesNodes)},
+						},
+						"node_pod_cidr_mask_length": schema.Int64Attribute{
+							MarkdownDescription: fmt.Sprintf("Mask length of the pod CIDR block assigned to each node, as set by the controller manager's `--node-cidr-mask-size`. Defaults to %d for IPv4 and %d for IPv6.", defaultIPv4NodePodMaskLength, defaultIPv6NodePodMaskLength),
+							Optional:            true,
+							Validators:          []validator.Int64{int64validator.Between(0, 128)},
+						},
+						"service_cidr_mask_length": schema.Int64Attribute{
+							MarkdownDescription: fmt.Sprintf("Mask length of the service CIDR block. Defaults to %d for IPv4 and %d for IPv6.", defaultIPv4ServiceMaskLength, defaultIPv6ServiceMaskLength),
+							Optional:            true,
+							Validators:          []validator.Int64{int64validator.Between(0, 128)},
+						},
+						"node_cidr_mask_length": schema.Int64Attribute{
+							MarkdownDescription: fmt.Sprintf("Mask length of the node CIDR block. Defaults to the smallest subnet with a usable host address per node for IPv4, and %d for IPv6.", defaultIPv6NodeMaskLength),
+							Optional:            true,
+							Validators:          []validator.Int64{int64validator.Between(0, 128)},
+						},
+					},
+				},
+			},
+			"plans": schema.ListNestedAttribute{
+				MarkdownDescription: "Planned CIDR blocks, one per cluster in the same order as clusters.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the cluster.",
+							Computed:            true,
+						},
+						"node_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "CIDR block of the node subnet. Null if the cluster does not fit.",
+							Computed:            true,
+						},
+						"pod_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "Cluster CIDR block that node pod CIDR blocks are assigned from. Null if the cluster does not fit.",
+							Computed:            true,
+						},
+						"service_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "Service CIDR block. Null if the cluster does not fit.",
+							Computed:            true,
+						},
+						"fulfilled": schema.BoolAttribute{
+							MarkdownDescription: "Whether all of the cluster's CIDR blocks fit.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"fulfilled": schema.BoolAttribute{
+				MarkdownDescription: "Whether every cluster fits.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the pool allocated from.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *KubernetesCIDRPlanDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+		d.strategy = data.strategy
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *KubernetesCIDRPlanDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data KubernetesCIDRPlanDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var clusters []KubernetesClusterModel
+	resp.Diagnostics.Append(data.Clusters.ElementsAs(ctx, &clusters, false)...)
+	sim, diags := newAllocationSimulation(ctx, d.calculator, d.strategy, data.PoolID, data.AllocatedCIDRBlocks)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Work out the mask lengths of every cluster before allocating anything.
+	maskLengths := make([][3]int, len(clusters))
+	for i, c := range clusters {
+		clusterPath := path.Root("clusters").AtListIndex(i)
+		ipv6 := c.IPFamily.ValueString() == ipFamilyIPv6
+		addressBits := 32
+		nodePodMaskLength, serviceMaskLength := defaultIPv4NodePodMaskLength, defaultIPv4ServiceMaskLength
+		nodeMaskLength, err := subnet.MaskLengthForHosts(uint64(c.MaxNodes.ValueInt64()), false)
+		if ipv6 {
+			addressBits = 128
+			nodePodMaskLength, serviceMaskLength = defaultIPv6NodePodMaskLength, defaultIPv6ServiceMaskLength
+			nodeMaskLength, err = defaultIPv6NodeMaskLength, nil
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(clusterPath.AtName("max_nodes"), "Invalid node count", err.Error())
+			continue
+		}
+		if !c.NodePodCIDRMaskLength.IsNull() {
+			nodePodMaskLength = int(c.NodePodCIDRMaskLength.ValueInt64())
+		}
+		if !c.ServiceCIDRMaskLength.IsNull() {
+			serviceMaskLength = int(c.ServiceCIDRMaskLength.ValueInt64())
+		}
+		if !c.NodeCIDRMaskLength.IsNull() {
+			nodeMaskLength = int(c.NodeCIDRMaskLength.ValueInt64())
+		}
+		for _, m := range []struct {
+			name   string
+			length int
+		}{
+			{"node_pod_cidr_mask_length", nodePodMaskLength},
+			{"service_cidr_mask_length", serviceMaskLength},
+			{"node_cidr_mask_length", nodeMaskLength},
+		} {
+			if m.length > addressBits {
+				resp.Diagnostics.AddAttributeError(clusterPath.AtName(m.name), "Invalid CIDR mask length", fmt.Sprintf("IPv4 CIDR mask length must be at most 32, got: %d", m.length))
+			}
+		}
+		// Every node needs a pod CIDR block of its own, so the cluster CIDR
+		// block is large enough to hold max_nodes of them.
+		podMaskLength := nodePodMaskLength - bits.Len64(uint64(c.MaxNodes.ValueInt64())-1)
+		if podMaskLength < 0 {
+			resp.Diagnostics.AddAttributeError(clusterPath.AtName("max_nodes"), "Invalid node count", fmt.Sprintf("%d nodes with a /%d pod CIDR block each do not fit in the address space.", c.MaxNodes.ValueInt64(), nodePodMaskLength))
+		}
+		maskLengths[i] = [3]int{podMaskLength, nodeMaskLength, serviceMaskLength}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plans := make([]KubernetesCIDRPlanModel, 0, len(clusters))
+	fulfilled := true
+	for i, c := range clusters {
+		ipv6 := c.IPFamily.ValueString() == ipFamilyIPv6
+		var cidrs [3]netip.Prefix
+		var err error
+		for j, maskLength := range maskLengths[i] {
+			if cidrs[j], err = sim.next(ctx, ipv6, maskLength); err != nil {
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			resp.Diagnostics.AddError("Kubernetes CIDR plan error", fmt.Sprintf("Unable to simulate the allocations: %v", err))
+			return
+		}
+		plan := KubernetesCIDRPlanModel{
+			Name:             c.Name,
+			PodCIDRBlock:     types.StringNull(),
+			NodeCIDRBlock:    types.StringNull(),
+			ServiceCIDRBlock: types.StringNull(),
+			Fulfilled:        types.BoolValue(err == nil),
+		}
+		if err == nil {
+			plan.PodCIDRBlock = types.StringValue(cidrs[0].String())
+			plan.NodeCIDRBlock = types.StringValue(cidrs[1].String())
+			plan.ServiceCIDRBlock = types.StringValue(cidrs[2].String())
+		}
+		plans = append(plans, plan)
+		fulfilled = fulfilled && err == nil
+	}
+
+	planList, diags := types.ListValueFrom(ctx, data.Plans.ElementType(ctx), plans)
+	resp.Diagnostics.Append(diags...)
+	data.Plans = planList
+	data.Fulfilled = types.BoolValue(fulfilled)
+	data.ID = types.StringValue(sim.id)
+
+	tflog.Trace(ctx, "read a Kubernetes CIDR plan data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
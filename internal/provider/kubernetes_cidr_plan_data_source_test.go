@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccKubernetesCIDRPlanDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Node count validation
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/12"]
+				}
+				data "netcalc_kubernetes_cidr_plan" "invalid" {
+					clusters = [{ name = "huge", max_nodes = 65536, node_pod_cidr_mask_length = 8 }]
+				}`,
+				ExpectError: regexp.MustCompile(`do\s+not\s+fit\s+in\s+the\s+address\s+space`),
+			},
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/12"]
+				}
+				data "netcalc_kubernetes_cidr_plan" "test" {
+					clusters = [
+						{ name = "prod", max_nodes = 100 },
+						{ name = "dev", max_nodes = 16, node_pod_cidr_mask_length = 26, service_cidr_mask_length = 24 },
+						{ name = "v6", ip_family = "ipv6", max_nodes = 10 },
+					]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "id", "10.0.0.0/12"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.#", "3"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.0.name", "prod"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.0.pod_cidr_block", "10.0.0.0/17"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.0.node_cidr_block", "10.0.128.0/25"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.0.service_cidr_block", "10.0.144.0/20"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.0.fulfilled", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.1.pod_cidr_block", "10.0.132.0/22"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.1.node_cidr_block", "10.0.128.128/27"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.1.service_cidr_block", "10.0.129.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.2.fulfilled", "false"),
+					resource.TestCheckNoResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "plans.2.pod_cidr_block"),
+					resource.TestCheckResourceAttr("data.netcalc_kubernetes_cidr_plan.test", "fulfilled", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerAzureBlobModel describes the ledger_azure_blob provider attribute.
+type ledgerAzureBlobModel struct {
+	StorageAccountName types.String `tfsdk:"storage_account_name"`
+	ContainerName      types.String `tfsdk:"container_name"`
+	BlobName           types.String `tfsdk:"blob_name"`
+	SASToken           types.String `tfsdk:"sas_token"`
+	Endpoint           types.String `tfsdk:"endpoint"`
+}
+
+func ledgerAzureBlobAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a JSON blob in Azure Storage instead of a local file, so Terraform states on different machines share a ledger. Updates hold a lease on a second blob, named after the ledger blob with a `.lock` suffix, which expires if Terraform is interrupted, so concurrent applies take turns instead of handing out overlapping CIDR blocks. Requests are authorized with a SAS token, or otherwise with Microsoft Entra ID credentials taken from the environment, a managed identity or the Azure CLI.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the storage account.",
+				Required:            true,
+			},
+			"container_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the container of the blob, which must exist.",
+				Required:            true,
+			},
+			"blob_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the ledger blob, e.g. `netcalc/ledger.json`. The blob is created on the first allocation.",
+				Required:            true,
+			},
+			"sas_token": schema.StringAttribute{
+				MarkdownDescription: "SAS token authorizing reads and writes of the blobs. Defaults to the `AZURE_STORAGE_SAS_TOKEN` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "Blob service endpoint URL. Defaults to `https://<storage_account_name>.blob.core.windows.net`.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newAzureBlobLedger returns the ledger configured by the ledger_azure_blob
+// provider attribute.
+func newAzureBlobLedger(data ledgerAzureBlobModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	sasToken, credential := azureStorageAuth(data.SASToken, path.Root("ledger_azure_blob"), "Ledger error", diagnostics)
+	if diagnostics.HasError() {
+		return nil
+	}
+	return ledger.NewAzureBlobLedger(ledger.AzureBlobOptions{
+		URL:        azureBlobURL(data.Endpoint, data.StorageAccountName, data.ContainerName, data.BlobName.ValueString()),
+		SASToken:   sasToken,
+		Credential: credential,
+	})
+}
+
+// azureBlobURL returns the URL of a blob, with the endpoint defaulting to the
+// public blob service endpoint of the storage account.
+func azureBlobURL(endpoint, storageAccountName, containerName types.String, blobName string) string {
+	base := endpoint.ValueString()
+	if base == "" {
+		base = fmt.Sprintf("https://%s.blob.core.windows.net", storageAccountName.ValueString())
+	}
+	return fmt.Sprintf("%s/%s/%s", base, containerName.ValueString(), blobName)
+}
+
+// azureStorageAuth returns the SAS token authorizing Azure Storage requests,
+// defaulting to the AZURE_STORAGE_SAS_TOKEN environment variable, or the
+// Microsoft Entra ID credential of the environment if there is none.
+func azureStorageAuth(sasToken types.String, attribute path.Path, summary string, diagnostics *diag.Diagnostics) (string, azcore.TokenCredential) {
+	token := sasToken.ValueString()
+	if token == "" {
+		token = os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	}
+	if token != "" {
+		return token, nil
+	}
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		diagnostics.AddAttributeError(attribute, summary, fmt.Sprintf("Unable to load Azure credentials: %v", err))
+		return "", nil
+	}
+	return "", credential
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeAzureBlobServer serves the blob and lease requests made by the Azure
+// blob ledger from memory.
+type fakeAzureBlobServer struct {
+	m      sync.Mutex
+	blobs  map[string][]byte
+	etags  map[string]string
+	leases map[string]string
+	count  int
+}
+
+func newFakeAzureBlobServer(t *testing.T) *httptest.Server {
+	f := &fakeAzureBlobServer{blobs: map[string][]byte{}, etags: map[string]string{}, leases: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeAzureBlobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.URL.Query().Get("sig") != "secret" {
+		w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		http.Error(w, "Server failed to authenticate the request.", http.StatusForbidden)
+		return
+	}
+	blob := r.URL.Path
+	b, exists := f.blobs[blob]
+	switch {
+	case r.Method == http.MethodGet && exists:
+		w.Header().Set("ETag", f.etags[blob])
+		_, _ = w.Write(b)
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "lease" && exists:
+		switch r.Header.Get("x-ms-lease-action") {
+		case "acquire":
+			if _, ok := f.leases[blob]; ok {
+				w.Header().Set("x-ms-error-code", "LeaseAlreadyPresent")
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			f.count++
+			f.leases[blob] = fmt.Sprintf("lease-%d", f.count)
+			w.Header().Set("x-ms-lease-id", f.leases[blob])
+			w.WriteHeader(http.StatusCreated)
+		case "release":
+			delete(f.leases, blob)
+		}
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "":
+		if exists && r.Header.Get("If-None-Match") == "*" {
+			w.Header().Set("x-ms-error-code", "BlobAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != f.etags[blob] {
+			w.Header().Set("x-ms-error-code", "ConditionNotMet")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		f.count++
+		f.blobs[blob], _ = io.ReadAll(r.Body)
+		f.etags[blob] = fmt.Sprintf(`"0x%d"`, f.count)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.Header().Set("x-ms-error-code", "BlobNotFound")
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newAzureBlobLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeAzureBlobServer(t)
+	t.Setenv("AZURE_STORAGE_SAS_TOKEN", "?sv=2021-12-02&sig=secret")
+	var diags diag.Diagnostics
+	azureLedger := newAzureBlobLedger(ledgerAzureBlobModel{
+		StorageAccountName: types.StringValue("account"),
+		ContainerName:      types.StringValue("netcalc"),
+		BlobName:           types.StringValue("ledger.json"),
+		Endpoint:           types.StringValue(server.URL),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return fmt.Sprintf(`
+  ledger_azure_blob = {
+    storage_account_name = "account"
+    container_name       = "netcalc"
+    blob_name            = "ledger.json"
+    endpoint             = %q
+  }`, server.URL), azureLedger
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderLedgerBackends(t *testing.T) {
+	for _, tc := range []struct {
+		attribute string
+		// newLedger starts a fake of the backend, and returns the provider
+		// attribute configuring it and a handle on the same ledger.
+		newLedger func(t *testing.T) (string, ledger.Ledger)
+	}{
+		{"ledger_s3", newS3LedgerFixture},
+		{"ledger_consul", newConsulLedgerFixture},
+		{"ledger_etcd", newEtcdLedgerFixture},
+		{"ledger_infoblox", newInfobloxLedgerFixture},
+		{"ledger_http", newHTTPLedgerFixture},
+		{"ledger_vault", newVaultLedgerFixture},
+		{"ledger_git", newGitLedgerFixture},
+		{"ledger_kubernetes", newKubernetesLedgerFixture},
+		{"ledger_azure_blob", newAzureBlobLedgerFixture},
+		{"ledger_gcs", newGCSLedgerFixture},
+		{"ledger_postgres", newPostgresLedgerFixture},
+		{"ledger_redis", newRedisLedgerFixture},
+	} {
+		t.Run(tc.attribute, func(t *testing.T) {
+			backend, l := tc.newLedger(t)
+			testAccProviderLedger(t, tc.attribute, backend, l)
+		})
+	}
+}
+
+// testAccProviderLedger checks that the provider records its allocations in
+// the ledger configured by backend, an assignment of the attribute, by
+// reading them through the handle l.
+func testAccProviderLedger(t *testing.T, attribute string, backend string, l ledger.Ledger) {
+	// Another workspace sharing the ledger allocated a CIDR block.
+	if err := l.Allocate(context.Background(), netip.MustParsePrefix("10.0.0.0/24"), "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig(`ledger_path = "ledger.json"`, backend, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(fmt.Sprintf(`Attribute\s+"ledger_path"\s+cannot\s+be\s+specified\s+when\s+%q\s+is\s+specified`, attribute)),
+			},
+			// Create and Read testing
+			{
+				Config: testAccProviderLedgerConfig("", backend, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+
+				resource "netcalc_allocation_ledger" "test" {
+					depends_on = [netcalc_subnet.test]
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					testAccCheckLedgerActive(l, "10.0.0.0/24", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_allocation_ledger.test", "id", "provider"),
+					resource.TestCheckTypeSetElemAttr("netcalc_allocation_ledger.test", "active_cidr_blocks.*", "10.0.0.0/24"),
+					resource.TestCheckTypeSetElemAttr("netcalc_allocation_ledger.test", "active_cidr_blocks.*", "10.0.1.0/24"),
+				),
+			},
+			// Releasing allocations
+			{
+				Config: testAccProviderLedgerConfig("", backend, ""),
+				Check:  testAccCheckLedgerActive(l, "10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func testAccProviderLedgerConfig(extra string, backend string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  %[1]s
+
+  %[2]s
+}
+%[3]s
+`, strings.TrimSpace(extra), strings.TrimSpace(backend), resources)
+}
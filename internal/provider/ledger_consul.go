@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerConsulModel describes the ledger_consul provider attribute.
+type ledgerConsulModel struct {
+	Address    types.String `tfsdk:"address"`
+	Token      types.String `tfsdk:"token"`
+	Datacenter types.String `tfsdk:"datacenter"`
+	Key        types.String `tfsdk:"key"`
+}
+
+func ledgerConsulAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a Consul key instead of a local file, so Terraform states on different machines share a ledger. Updates hold a lock acquired with a Consul session, which expires if Terraform is interrupted, so concurrent applies take turns instead of handing out overlapping CIDR blocks.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address of the Consul agent, e.g. `consul.example.com:8500` or `https://consul.example.com`. Defaults to the `CONSUL_HTTP_ADDR` environment variable, or `127.0.0.1:8500`.",
+				Optional:            true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "ACL token. Defaults to the `CONSUL_HTTP_TOKEN` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"datacenter": schema.StringAttribute{
+				MarkdownDescription: "Datacenter of the key. Defaults to the datacenter of the agent.",
+				Optional:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the ledger, e.g. `netcalc/ledger`. The key is created on the first allocation, and the lock is held on the same key with a `.lock` suffix.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newConsulLedger returns the ledger configured by the ledger_consul provider
+// attribute.
+func newConsulLedger(data ledgerConsulModel) ledger.Ledger {
+	return ledger.NewConsulLedger(consulOptions(data.Address, data.Token, data.Datacenter, data.Key))
+}
+
+// consulOptions returns the options of a Consul client, with the address and
+// token defaulting to the environment variables of the Consul CLI.
+func consulOptions(address, token, datacenter, key types.String) ledger.ConsulOptions {
+	opts := ledger.ConsulOptions{
+		Address:    address.ValueString(),
+		Token:      token.ValueString(),
+		Datacenter: datacenter.ValueString(),
+		Key:        key.ValueString(),
+	}
+	if opts.Address == "" {
+		opts.Address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if opts.Address == "" {
+		opts.Address = "127.0.0.1:8500"
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	return opts
+}
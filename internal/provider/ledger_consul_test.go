@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeConsulServer serves the KV and session requests made by the Consul
+// ledger from memory.
+type fakeConsulServer struct {
+	m        sync.Mutex
+	index    uint64
+	values   map[string][]byte
+	indexes  map[string]uint64
+	holders  map[string]string
+	sessions int
+}
+
+func newFakeConsulServer(t *testing.T) *httptest.Server {
+	f := &fakeConsulServer{values: map[string][]byte{}, indexes: map[string]uint64{}, holders: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeConsulServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	switch {
+	case r.URL.Path == "/v1/session/create":
+		f.sessions++
+		fmt.Fprintf(w, `{"ID":"session-%d"}`, f.sessions)
+	case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+		session := strings.TrimPrefix(r.URL.Path, "/v1/session/destroy/")
+		for key, holder := range f.holders {
+			if holder == session {
+				delete(f.holders, key)
+				delete(f.values, key)
+			}
+		}
+		fmt.Fprint(w, "true")
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/"):
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		if r.Method == http.MethodGet {
+			value, ok := f.values[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"Key": key, "Value": value, "ModifyIndex": f.indexes[key]}})
+			return
+		}
+		if session := query.Get("acquire"); session != "" {
+			if holder, ok := f.holders[key]; ok && holder != session {
+				fmt.Fprint(w, "false")
+				return
+			}
+			f.holders[key] = session
+		}
+		if cas := query.Get("cas"); cas != "" {
+			if index, _ := strconv.ParseUint(cas, 10, 64); index != f.indexes[key] {
+				fmt.Fprint(w, "false")
+				return
+			}
+		}
+		f.index++
+		f.values[key] = body
+		f.indexes[key] = f.index
+		fmt.Fprint(w, "true")
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newConsulLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeConsulServer(t)
+	consulLedger := newConsulLedger(ledgerConsulModel{
+		Address: types.StringValue(server.URL),
+		Key:     types.StringValue("netcalc/ledger"),
+	})
+	return fmt.Sprintf(`
+  ledger_consul = {
+    address = %q
+    key     = "netcalc/ledger"
+  }`, server.URL), consulLedger
+}
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerEtcdModel describes the ledger_etcd provider attribute.
+type ledgerEtcdModel struct {
+	Endpoints types.List   `tfsdk:"endpoints"`
+	Username  types.String `tfsdk:"username"`
+	Password  types.String `tfsdk:"password"`
+	Key       types.String `tfsdk:"key"`
+}
+
+func ledgerEtcdAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in an etcd key instead of a local file, so Terraform states on different machines share a ledger. The ledger is written in transactions, and updates hold a lock attached to an etcd lease, which expires if Terraform is interrupted, so concurrent applies take turns instead of handing out overlapping CIDR blocks. Requests use the JSON gateway of the etcd v3 API, which etcd serves on its client port.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"endpoints": schema.ListAttribute{
+				MarkdownDescription: "Endpoints of the etcd cluster, e.g. `etcd-0.example.com:2379` or `https://etcd-0.example.com:2379`, which are tried in order. Defaults to the comma separated `ETCDCTL_ENDPOINTS` environment variable, or `127.0.0.1:2379`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username, if etcd authentication is enabled. Defaults to the `ETCDCTL_USER` environment variable.",
+				Optional:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the user. Defaults to the `ETCDCTL_PASSWORD` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the ledger, e.g. `/netcalc/ledger`. The key is created on the first allocation, and the lock is held on the same key with a `.lock` suffix.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newEtcdLedger returns the ledger configured by the ledger_etcd provider
+// attribute.
+func newEtcdLedger(ctx context.Context, data ledgerEtcdModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	var endpoints []string
+	if !data.Endpoints.IsNull() {
+		diagnostics.Append(data.Endpoints.ElementsAs(ctx, &endpoints, false)...)
+	} else if env := os.Getenv("ETCDCTL_ENDPOINTS"); env != "" {
+		endpoints = strings.Split(env, ",")
+	} else {
+		endpoints = []string{"127.0.0.1:2379"}
+	}
+	username := data.Username.ValueString()
+	if username == "" {
+		username = os.Getenv("ETCDCTL_USER")
+	}
+	password := data.Password.ValueString()
+	if password == "" {
+		password = os.Getenv("ETCDCTL_PASSWORD")
+	}
+	return ledger.NewEtcdLedger(ledger.EtcdOptions{
+		Endpoints: endpoints,
+		Username:  username,
+		Password:  password,
+		Key:       data.Key.ValueString(),
+	})
+}
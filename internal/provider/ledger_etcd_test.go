@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeEtcdServer serves the range, transaction and lease requests made by
+// the etcd ledger from memory.
+type fakeEtcdServer struct {
+	m         sync.Mutex
+	revision  int
+	values    map[string][]byte
+	revisions map[string][2]int
+	leases    map[string]string
+	nextLease int
+}
+
+func newFakeEtcdServer(t *testing.T) *httptest.Server {
+	f := &fakeEtcdServer{values: map[string][]byte{}, revisions: map[string][2]int{}, leases: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeEtcdServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	var req struct {
+		Key     []byte
+		ID      string
+		Compare []struct {
+			Key            []byte
+			Target         string
+			ModRevision    json.Number `json:"mod_revision"`
+			CreateRevision json.Number `json:"create_revision"`
+		}
+		Success []struct {
+			RequestPut struct {
+				Key   []byte
+				Value []byte
+				Lease string
+			} `json:"request_put"`
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		value, ok := f.values[string(req.Key)]
+		if !ok {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"kvs": []map[string]any{{
+			"value":        value,
+			"mod_revision": fmt.Sprint(f.revisions[string(req.Key)][1]),
+		}}})
+	case "/v3/kv/txn":
+		for _, c := range req.Compare {
+			revisions := f.revisions[string(c.Key)]
+			if c.Target == "MOD" && fmt.Sprint(revisions[1]) != c.ModRevision.String() ||
+				c.Target == "CREATE" && fmt.Sprint(revisions[0]) != c.CreateRevision.String() {
+				fmt.Fprint(w, `{}`)
+				return
+			}
+		}
+		for _, op := range req.Success {
+			key := string(op.RequestPut.Key)
+			f.revision++
+			revisions, ok := f.revisions[key]
+			if !ok {
+				revisions[0] = f.revision
+			}
+			revisions[1] = f.revision
+			f.revisions[key] = revisions
+			f.values[key] = op.RequestPut.Value
+			if op.RequestPut.Lease != "" {
+				f.leases[key] = op.RequestPut.Lease
+			}
+		}
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case "/v3/lease/grant":
+		f.nextLease++
+		fmt.Fprintf(w, `{"ID":"%d","TTL":"60"}`, f.nextLease)
+	case "/v3/lease/revoke":
+		for key, lease := range f.leases {
+			if lease == req.ID {
+				delete(f.leases, key)
+				delete(f.values, key)
+				delete(f.revisions, key)
+			}
+		}
+		fmt.Fprint(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newEtcdLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeEtcdServer(t)
+	var diags diag.Diagnostics
+	etcdLedger := newEtcdLedger(context.Background(), ledgerEtcdModel{
+		Endpoints: types.ListValueMust(types.StringType, []attr.Value{types.StringValue(server.URL)}),
+		Key:       types.StringValue("/netcalc/ledger"),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return fmt.Sprintf(`
+  ledger_etcd = {
+    endpoints = [%q]
+    key       = "/netcalc/ledger"
+  }`, server.URL), etcdLedger
+}
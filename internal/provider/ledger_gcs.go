@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/api/storage/v1"
+)
+
+// ledgerGCSModel describes the ledger_gcs provider attribute.
+type ledgerGCSModel struct {
+	Bucket types.String `tfsdk:"bucket"`
+	Object types.String `tfsdk:"object"`
+}
+
+func ledgerGCSAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a JSON object in Google Cloud Storage instead of a local file, so Terraform states on different machines share a ledger. Every write is conditional on the generation of the object that was read, and is retried when another Terraform run wrote the object in the meantime, so concurrent applies do not hand out overlapping CIDR blocks. Credentials are taken from the application default credentials, and need permission to read and create objects in the bucket.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket, which must exist.",
+				Required:            true,
+			},
+			"object": schema.StringAttribute{
+				MarkdownDescription: "Name of the ledger object, e.g. `netcalc/ledger.json`. The object is created on the first allocation.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newGCSStorageService returns a client for the Cloud Storage API. It is
+// replaced in tests.
+var newGCSStorageService = func(ctx context.Context) (*storage.Service, error) {
+	return storage.NewService(ctx)
+}
+
+// newGCSLedger returns the ledger configured by the ledger_gcs provider
+// attribute.
+func newGCSLedger(ctx context.Context, data ledgerGCSModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	service, err := newGCSStorageService(ctx)
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ledger_gcs"), "Ledger error", fmt.Sprintf("Unable to create a Cloud Storage client: %v", err))
+		return nil
+	}
+	return ledger.NewGCSLedger(service, ledger.GCSOptions{
+		Bucket: data.Bucket.ValueString(),
+		Object: data.Object.ValueString(),
+	})
+}
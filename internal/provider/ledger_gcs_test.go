@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// fakeGCSServer serves the object downloads and multipart uploads made by
+// the GCS ledger from memory.
+type fakeGCSServer struct {
+	m           sync.Mutex
+	objects     map[string][]byte
+	generations map[string]int64
+	generation  int64
+}
+
+// useFakeGCSServer makes the provider use a fake Cloud Storage API.
+func useFakeGCSServer(t *testing.T) {
+	f := &fakeGCSServer{objects: map[string][]byte{}, generations: map[string]int64{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	newService := newGCSStorageService
+	t.Cleanup(func() { newGCSStorageService = newService })
+	newGCSStorageService = func(ctx context.Context) (*storage.Service, error) {
+		return storage.NewService(ctx, option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	}
+}
+
+func (f *fakeGCSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if bucketObject, ok := strings.CutPrefix(r.URL.Path, "/storage/v1/b/"); ok && r.Method == http.MethodGet {
+		key := strings.Replace(bucketObject, "/o/", "/", 1)
+		b, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":404,"message":"No such object"}}`)
+			return
+		}
+		w.Header().Set("X-Goog-Generation", strconv.FormatInt(f.generations[key], 10))
+		_, _ = w.Write(b)
+		return
+	}
+	bucket, _ := strings.CutPrefix(r.URL.Path, "/upload/storage/v1/b/")
+	_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	parts := multipart.NewReader(r.Body, params["boundary"])
+	var metadata storage.Object
+	if part, err := parts.NextPart(); err == nil {
+		_ = json.NewDecoder(part).Decode(&metadata)
+	}
+	part, err := parts.NextPart()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":{"code":400,"message":%q}}`, err.Error())
+		return
+	}
+	key := strings.TrimSuffix(bucket, "/o") + "/" + metadata.Name
+	if r.URL.Query().Get("ifGenerationMatch") != strconv.FormatInt(f.generations[key], 10) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		fmt.Fprint(w, `{"error":{"code":412,"message":"At least one of the pre-conditions you specified did not hold."}}`)
+		return
+	}
+	f.generation++
+	f.objects[key], _ = io.ReadAll(part)
+	f.generations[key] = f.generation
+	_ = json.NewEncoder(w).Encode(storage.Object{Name: metadata.Name, Generation: f.generation})
+}
+
+func newGCSLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	useFakeGCSServer(t)
+	var diags diag.Diagnostics
+	gcsLedger := newGCSLedger(context.Background(), ledgerGCSModel{
+		Bucket: types.StringValue("network"),
+		Object: types.StringValue("netcalc/ledger.json"),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return `
+  ledger_gcs = {
+    bucket = "network"
+    object = "netcalc/ledger.json"
+  }`, gcsLedger
+}
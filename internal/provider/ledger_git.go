@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerGitModel describes the ledger_git provider attribute.
+type ledgerGitModel struct {
+	URL           types.String `tfsdk:"url"`
+	Branch        types.String `tfsdk:"branch"`
+	Path          types.String `tfsdk:"path"`
+	Username      types.String `tfsdk:"username"`
+	Token         types.String `tfsdk:"token"`
+	SSHPrivateKey types.String `tfsdk:"ssh_private_key"`
+	AuthorName    types.String `tfsdk:"author_name"`
+	AuthorEmail   types.String `tfsdk:"author_email"`
+}
+
+func ledgerGitAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a JSON file committed to a Git repository instead of a local file, so Terraform states on different machines share a ledger and every allocation and release is a commit that can be reviewed and audited. Commits are pushed on top of the commit that was read, without force, and are retried when another Terraform run pushed to the branch in the meantime, so concurrent applies do not hand out overlapping CIDR blocks.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL of the repository, e.g. `https://github.com/example/network.git` or `git@github.com:example/network.git`.",
+				Required:            true,
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch the ledger is committed to, which is created on the first allocation if it does not exist. Defaults to `main`.",
+				Optional:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the ledger file within the repository. Defaults to `allocations.json`.",
+				Optional:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username to authenticate with over HTTPS, along with the token, or over SSH, along with the private key. Defaults to `git`.",
+				Optional:            true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "Password or access token to authenticate with over HTTPS, which needs permission to push to the branch.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"ssh_private_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key to authenticate with over SSH. The host key of the server is verified against the `known_hosts` files of the user.",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("token")),
+				},
+			},
+			"author_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the author of the commits. Defaults to `netcalc`.",
+				Optional:            true,
+			},
+			"author_email": schema.StringAttribute{
+				MarkdownDescription: "Email address of the author of the commits. Defaults to `netcalc`.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newGitLedger returns the ledger configured by the ledger_git provider
+// attribute.
+func newGitLedger(data ledgerGitModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	opts := ledger.GitOptions{
+		URL:         data.URL.ValueString(),
+		Branch:      data.Branch.ValueString(),
+		Path:        data.Path.ValueString(),
+		AuthorName:  data.AuthorName.ValueString(),
+		AuthorEmail: data.AuthorEmail.ValueString(),
+	}
+	username := data.Username.ValueString()
+	if username == "" {
+		username = "git"
+	}
+	switch {
+	case data.SSHPrivateKey.ValueString() != "":
+		auth, err := ssh.NewPublicKeys(username, []byte(data.SSHPrivateKey.ValueString()), "")
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("ledger_git").AtName("ssh_private_key"), "Ledger error", fmt.Sprintf("Unable to parse the SSH private key: %v", err))
+			return nil
+		}
+		opts.Auth = auth
+	case data.Token.ValueString() != "":
+		opts.Auth = &http.BasicAuth{Username: username, Password: data.Token.ValueString()}
+	}
+	return ledger.NewGitLedger(opts)
+}
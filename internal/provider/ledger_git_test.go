@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccProviderLedgerGit(t *testing.T) {
+	dir := newGitRepository(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerGitBackend(dir, `ssh_private_key = "invalid"`), `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+the\s+SSH\s+private\s+key`),
+			},
+			// Every update of the ledger is a commit by the configured author.
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerGitBackend(dir, ""), `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				Check: testAccCheckGitHead(dir, "ipam", "Allocate 10.0.0.0/24 to "),
+			},
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerGitBackend(dir, ""), ""),
+				Check:  testAccCheckGitHead(dir, "ipam", "Release 10.0.0.0/24 from "),
+			},
+		},
+	})
+}
+
+func newGitLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	dir := newGitRepository(t)
+	var diags diag.Diagnostics
+	gitLedger := newGitLedger(ledgerGitModel{
+		URL:    types.StringValue(dir),
+		Branch: types.StringValue("ipam"),
+		Path:   types.StringValue("network/allocations.json"),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return testAccLedgerGitBackend(dir, ""), gitLedger
+}
+
+// newGitRepository returns the directory of a new bare repository.
+func newGitRepository(t *testing.T) string {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// testAccCheckGitHead checks that the message of the last commit on the
+// branch of the bare repository in dir starts with prefix, and that the
+// commit was made by the configured author.
+func testAccCheckGitHead(dir, branch, prefix string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		repo, err := git.PlainOpen(dir)
+		if err != nil {
+			return err
+		}
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		if err != nil {
+			return err
+		}
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(commit.Message, prefix) {
+			return fmt.Errorf("expected the last commit to start with %q, got %q", prefix, commit.Message)
+		}
+		if commit.Author.Name != "Terraform" || commit.Author.Email != "terraform@example.com" {
+			return fmt.Errorf("unexpected author %s", commit.Author)
+		}
+		return nil
+	}
+}
+
+func testAccLedgerGitBackend(dir string, auth string) string {
+	return fmt.Sprintf(`
+  ledger_git = {
+    url          = %[1]q
+    branch       = "ipam"
+    path         = "network/allocations.json"
+    author_name  = "Terraform"
+    author_email = "terraform@example.com"
+    %[2]s
+  }`, dir, auth)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerHTTPModel describes the ledger_http provider attribute.
+type ledgerHTTPModel struct {
+	ListURL       types.String `tfsdk:"list_url"`
+	ClaimURL      types.String `tfsdk:"claim_url"`
+	ClaimMethod   types.String `tfsdk:"claim_method"`
+	ReleaseURL    types.String `tfsdk:"release_url"`
+	ReleaseMethod types.String `tfsdk:"release_method"`
+	Headers       types.Map    `tfsdk:"headers"`
+}
+
+func ledgerHTTPAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations with a REST API instead of a local file, so an IPAM service of your own can take part without a dedicated plugin. Allocations are checked for overlaps with the listed allocations before they are claimed, and the API should reject claims of CIDR blocks allocated in the meantime with `409 Conflict`. The URLs may contain the placeholders `{cidr}` and `{owner}`, which are replaced with the URL-escaped CIDR block and owner ID of the request.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"list_url": schema.StringAttribute{
+				MarkdownDescription: "URL requested with `GET` to list the allocations. The response is a JSON array of objects with the attributes `cidr` and `owner`, and optionally `allocated_at` and `released_at` in RFC 3339 format, or an object with these objects in an `entries` attribute, like a ledger file.",
+				Required:            true,
+			},
+			"claim_url": schema.StringAttribute{
+				MarkdownDescription: "URL requested to claim an allocation, with a JSON object with the attributes `cidr` and `owner` as the request body.",
+				Required:            true,
+			},
+			"claim_method": schema.StringAttribute{
+				MarkdownDescription: "HTTP method of claims, `POST` or `PUT`. Defaults to `POST`.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(http.MethodPost, http.MethodPut)},
+			},
+			"release_url": schema.StringAttribute{
+				MarkdownDescription: "URL requested to release an allocation, with the same request body as a claim. A `404 Not Found` response means there was nothing to release.",
+				Required:            true,
+			},
+			"release_method": schema.StringAttribute{
+				MarkdownDescription: "HTTP method of releases, `POST`, `PUT` or `DELETE`. Defaults to `POST`.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(http.MethodPost, http.MethodPut, http.MethodDelete)},
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Headers sent with every request, e.g. `Authorization`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newHTTPLedger returns the ledger configured by the ledger_http provider
+// attribute.
+func newHTTPLedger(ctx context.Context, data ledgerHTTPModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	opts := ledger.HTTPOptions{
+		ListURL:       data.ListURL.ValueString(),
+		ClaimURL:      data.ClaimURL.ValueString(),
+		ClaimMethod:   data.ClaimMethod.ValueString(),
+		ReleaseURL:    data.ReleaseURL.ValueString(),
+		ReleaseMethod: data.ReleaseMethod.ValueString(),
+	}
+	diagnostics.Append(data.Headers.ElementsAs(ctx, &opts.Headers, false)...)
+	return ledger.NewHTTPLedger(opts)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// fakeIPAMServer is a REST API keeping allocations in memory, keyed by CIDR
+// block, which lists them at /allocations, claims them with PUT
+// /allocations/{cidr} and releases them with DELETE /allocations/{cidr}.
+type fakeIPAMServer struct {
+	m           sync.Mutex
+	allocations map[string]string
+}
+
+func newFakeIPAMServer(t *testing.T) *httptest.Server {
+	f := &fakeIPAMServer{allocations: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeIPAMServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("X-API-Key") != "secret" {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+		return
+	}
+	cidr := strings.TrimPrefix(r.URL.Path, "/allocations/")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/allocations":
+		entries := []map[string]string{}
+		for _, cidr := range sortedKeys(f.allocations) {
+			entries = append(entries, map[string]string{"cidr": cidr, "owner": f.allocations[cidr]})
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	case r.Method == http.MethodPut:
+		if _, ok := f.allocations[cidr]; ok {
+			http.Error(w, "already allocated", http.StatusConflict)
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.allocations[cidr] = body["owner"]
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.allocations[cidr]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.allocations, cidr)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAccProviderLedgerHTTP(t *testing.T) {
+	server := newFakeIPAMServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerHTTPBackend(server.URL, "PATCH"), `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`Attribute\s+ledger_http.claim_method\s+value\s+must\s+be\s+one\s+of`),
+			},
+		},
+	})
+}
+
+func newHTTPLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeIPAMServer(t)
+	return testAccLedgerHTTPBackend(server.URL, "PUT"), newTestHTTPLedger(t, server.URL)
+}
+
+// newTestHTTPLedger returns a handle on the ledger of testAccLedgerHTTPBackend.
+func newTestHTTPLedger(t *testing.T, url string) ledger.Ledger {
+	var diags diag.Diagnostics
+	httpLedger := newHTTPLedger(context.Background(), ledgerHTTPModel{
+		ListURL:       types.StringValue(url + "/allocations"),
+		ClaimURL:      types.StringValue(url + "/allocations/{cidr}"),
+		ClaimMethod:   types.StringValue(http.MethodPut),
+		ReleaseURL:    types.StringValue(url + "/allocations/{cidr}"),
+		ReleaseMethod: types.StringValue(http.MethodDelete),
+		Headers:       types.MapValueMust(types.StringType, map[string]attr.Value{"X-API-Key": types.StringValue("secret")}),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return httpLedger
+}
+
+func testAccLedgerHTTPBackend(url string, claimMethod string) string {
+	return fmt.Sprintf(`
+  ledger_http = {
+    list_url       = "%[1]s/allocations"
+    claim_url      = "%[1]s/allocations/{cidr}"
+    claim_method   = %[2]q
+    release_url    = "%[1]s/allocations/{cidr}"
+    release_method = "DELETE"
+    headers = {
+      X-API-Key = "secret"
+    }
+  }`, url, claimMethod)
+}
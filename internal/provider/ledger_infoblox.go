@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerInfobloxModel describes the ledger_infoblox provider attribute.
+type ledgerInfobloxModel struct {
+	Host             types.String `tfsdk:"host"`
+	Username         types.String `tfsdk:"username"`
+	Password         types.String `tfsdk:"password"`
+	WAPIVersion      types.String `tfsdk:"wapi_version"`
+	NetworkView      types.String `tfsdk:"network_view"`
+	NetworkContainer types.String `tfsdk:"network_container"`
+	Insecure         types.Bool   `tfsdk:"insecure"`
+}
+
+func ledgerInfobloxAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations as networks in an Infoblox network container instead of a local file, so allocations made by Terraform show up in the enterprise IPAM. Every network and network container already in the container is claimed, whether it was created by Terraform or not. Allocations are created as networks with a generated owner ID as their comment, and deleted when they are released.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"host": schema.StringAttribute{
+				MarkdownDescription: "Host of the Infoblox grid manager, e.g. `infoblox.example.com`. Defaults to the `INFOBLOX_SERVER` environment variable.",
+				Optional:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username. Defaults to the `INFOBLOX_USERNAME` environment variable.",
+				Optional:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the user. Defaults to the `INFOBLOX_PASSWORD` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"wapi_version": schema.StringAttribute{
+				MarkdownDescription: "Version of the WAPI. Defaults to `2.12`.",
+				Optional:            true,
+			},
+			"network_view": schema.StringAttribute{
+				MarkdownDescription: "Network view of the network container. Defaults to `default`.",
+				Optional:            true,
+			},
+			"network_container": schema.StringAttribute{
+				MarkdownDescription: "Network container whose networks are the allocations, e.g. `10.0.0.0/8`. The container is usually one of the `pool_cidr_blocks`.",
+				Required:            true,
+			},
+			"insecure": schema.BoolAttribute{
+				MarkdownDescription: "Whether to skip verifying the TLS certificate of the grid manager, which is self-signed by default. Defaults to `false`.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newInfobloxLedger returns the ledger configured by the ledger_infoblox
+// provider attribute.
+func newInfobloxLedger(data ledgerInfobloxModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	container, err := netip.ParsePrefix(data.NetworkContainer.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ledger_infoblox").AtName("network_container"), "CIDR parsing error", fmt.Sprintf("Unable to parse network container: %q, %v", data.NetworkContainer.ValueString(), err))
+		return nil
+	}
+	opts := ledger.InfobloxOptions{
+		Host:             data.Host.ValueString(),
+		Username:         data.Username.ValueString(),
+		Password:         data.Password.ValueString(),
+		WAPIVersion:      data.WAPIVersion.ValueString(),
+		NetworkView:      data.NetworkView.ValueString(),
+		NetworkContainer: container.Masked(),
+	}
+	if opts.Host == "" {
+		opts.Host = os.Getenv("INFOBLOX_SERVER")
+	}
+	if opts.Username == "" {
+		opts.Username = os.Getenv("INFOBLOX_USERNAME")
+	}
+	if opts.Password == "" {
+		opts.Password = os.Getenv("INFOBLOX_PASSWORD")
+	}
+	if opts.Host == "" {
+		diagnostics.AddAttributeError(path.Root("ledger_infoblox").AtName("host"), "Missing Infoblox host", "Set host or the INFOBLOX_SERVER environment variable.")
+		return nil
+	}
+	if data.Insecure.ValueBool() {
+		opts.HTTPClient = &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+	}
+	return ledger.NewInfobloxLedger(opts)
+}
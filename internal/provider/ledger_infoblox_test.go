@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// fakeInfobloxServer serves the network objects of the WAPI from memory. It
+// has no network containers, and every network is in the container.
+type fakeInfobloxServer struct {
+	m        sync.Mutex
+	networks map[string]string
+}
+
+func newFakeInfobloxServer(t *testing.T) *httptest.Server {
+	f := &fakeInfobloxServer{networks: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeInfobloxServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	object := strings.TrimPrefix(r.URL.Path, "/wapi/v2.12/")
+
+	switch {
+	case r.Method == http.MethodGet && object == "network":
+		results := []map[string]string{}
+		for _, network := range sortedKeys(f.networks) {
+			comment := f.networks[network]
+			if filter := r.URL.Query().Get("network"); filter == "" || filter == network {
+				results = append(results, map[string]string{"_ref": "network/" + network, "network": network, "comment": comment})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": results})
+	case r.Method == http.MethodGet && object == "networkcontainer":
+		fmt.Fprint(w, `{"result":[]}`)
+	case r.Method == http.MethodPost && object == "network":
+		var n map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&n)
+		f.networks[n["network"]] = n["comment"]
+		fmt.Fprintf(w, `"network/%s"`, n["network"])
+	case r.Method == http.MethodDelete:
+		delete(f.networks, strings.TrimPrefix(object, "network/"))
+		fmt.Fprintf(w, "%q", object)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAccProviderLedgerInfoblox(t *testing.T) {
+	server := newFakeInfobloxServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerInfobloxBackend(server.URL, "10.0.0.0"), `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+network\s+container:\s+"10.0.0.0"`),
+			},
+		},
+	})
+}
+
+func newInfobloxLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeInfobloxServer(t)
+	var diags diag.Diagnostics
+	infobloxLedger := newInfobloxLedger(ledgerInfobloxModel{
+		Host:             types.StringValue(server.URL),
+		NetworkContainer: types.StringValue("10.0.0.0/16"),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return testAccLedgerInfobloxBackend(server.URL, "10.0.0.0/16"), infobloxLedger
+}
+
+func testAccLedgerInfobloxBackend(host string, container string) string {
+	return fmt.Sprintf(`
+  ledger_infoblox = {
+    host              = %[1]q
+    username          = "admin"
+    password          = "infoblox"
+    network_container = %[2]q
+  }`, host, container)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// serviceAccountDir is where Kubernetes mounts the credentials of the
+// service account of a pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ledgerKubernetesModel describes the ledger_kubernetes provider attribute.
+type ledgerKubernetesModel struct {
+	Host                 types.String `tfsdk:"host"`
+	Token                types.String `tfsdk:"token"`
+	ClusterCACertificate types.String `tfsdk:"cluster_ca_certificate"`
+	ClientCertificate    types.String `tfsdk:"client_certificate"`
+	ClientKey            types.String `tfsdk:"client_key"`
+	Insecure             types.Bool   `tfsdk:"insecure"`
+	Namespace            types.String `tfsdk:"namespace"`
+	Name                 types.String `tfsdk:"name"`
+	Key                  types.String `tfsdk:"key"`
+	CustomResource       types.Object `tfsdk:"custom_resource"`
+}
+
+// ledgerKubernetesCustomResourceModel describes the custom_resource attribute
+// of the ledger_kubernetes provider attribute.
+type ledgerKubernetesCustomResourceModel struct {
+	APIVersion types.String `tfsdk:"api_version"`
+	Kind       types.String `tfsdk:"kind"`
+	Plural     types.String `tfsdk:"plural"`
+}
+
+func ledgerKubernetesAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a Kubernetes ConfigMap or custom resource instead of a local file, so subnet planning for a cluster, such as node and pod ranges, shares state with in-cluster controllers. Every write is conditional on the resource version of the object that was read, and is retried when another Terraform run or a controller changed the object in the meantime, so they do not hand out overlapping CIDR blocks. When run in a pod, the provider connects with the credentials of its service account by default.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"host": schema.StringAttribute{
+				MarkdownDescription: "URL of the Kubernetes API server, e.g. `https://kubernetes.example.com:6443`. Defaults to the `KUBE_HOST` environment variable, or the API server of the cluster the provider runs in.",
+				Optional:            true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "Bearer token to authenticate with. Defaults to the `KUBE_TOKEN` environment variable, or the token of the service account of the pod the provider runs in.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"cluster_ca_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded certificate of the authority that signed the certificate of the API server. Defaults to the `KUBE_CLUSTER_CA_CERT_DATA` environment variable, or the certificate of the cluster the provider runs in.",
+				Optional:            true,
+			},
+			"client_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate to authenticate with, along with `client_key`.",
+				Optional:            true,
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of the client certificate.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"insecure": schema.BoolAttribute{
+				MarkdownDescription: "Whether to skip verifying the TLS certificate of the API server. Defaults to `false`.",
+				Optional:            true,
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "Namespace of the object. Defaults to the namespace of the pod the provider runs in, or `default` for a ConfigMap. A custom resource without a namespace is cluster-scoped.",
+				Optional:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the object, which is created on the first allocation if it does not exist.",
+				Required:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the ConfigMap data entry holding the ledger, which other entries of the ConfigMap may be used alongside. Defaults to `allocations.json`.",
+				Optional:            true,
+			},
+			"custom_resource": schema.SingleNestedAttribute{
+				MarkdownDescription: "Kind of custom resource to store the ledger in instead of a ConfigMap. The spec of the resource is the ledger, an object with an `entries` array of objects with the attributes `cidr`, `owner`, `allocated_at` and optionally `released_at`, which the CustomResourceDefinition has to allow.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"api_version": schema.StringAttribute{
+						MarkdownDescription: "Group and version of the resource, e.g. `netcalc.example.com/v1`.",
+						Required:            true,
+						Validators: []validator.String{
+							stringvalidator.RegexMatches(regexp.MustCompile(`^[a-z0-9.-]+/[a-z0-9]+$`), "must be a group and version, e.g. netcalc.example.com/v1"),
+						},
+					},
+					"kind": schema.StringAttribute{
+						MarkdownDescription: "Kind of the resource, e.g. `SubnetLedger`.",
+						Required:            true,
+					},
+					"plural": schema.StringAttribute{
+						MarkdownDescription: "Plural name of the resource in API paths. Defaults to the lowercase kind with an `s` suffix.",
+						Optional:            true,
+					},
+				},
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newKubernetesLedger returns the ledger configured by the ledger_kubernetes
+// provider attribute, with the connection defaulting to the environment
+// variables of the Kubernetes provider, and then to the service account of
+// the pod the provider runs in.
+func newKubernetesLedger(ctx context.Context, data ledgerKubernetesModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	attributePath := path.Root("ledger_kubernetes")
+	opts := ledger.KubernetesOptions{
+		Host:      data.Host.ValueString(),
+		Token:     data.Token.ValueString(),
+		Namespace: data.Namespace.ValueString(),
+		Name:      data.Name.ValueString(),
+		Key:       data.Key.ValueString(),
+	}
+	caCertificate := data.ClusterCACertificate.ValueString()
+	if opts.Host == "" {
+		opts.Host = os.Getenv("KUBE_HOST")
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("KUBE_TOKEN")
+	}
+	if caCertificate == "" {
+		caCertificate = os.Getenv("KUBE_CLUSTER_CA_CERT_DATA")
+	}
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); opts.Host == "" && host != "" {
+		// In a pod, connect to the API server of the cluster with the
+		// credentials of the service account.
+		opts.Host = "https://" + net.JoinHostPort(host, port)
+		if opts.Token == "" {
+			opts.Token = readServiceAccountFile("token")
+		}
+		if caCertificate == "" {
+			caCertificate = readServiceAccountFile("ca.crt")
+		}
+	}
+	if opts.Host == "" {
+		diagnostics.AddAttributeError(attributePath.AtName("host"), "Missing Kubernetes host", "Set host or the KUBE_HOST environment variable, or run the provider in a Kubernetes pod.")
+		return nil
+	}
+	if !strings.Contains(opts.Host, "://") {
+		opts.Host = "https://" + opts.Host
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = readServiceAccountFile("namespace")
+	}
+	if !data.CustomResource.IsNull() {
+		var cr ledgerKubernetesCustomResourceModel
+		diagnostics.Append(data.CustomResource.As(ctx, &cr, basetypes.ObjectAsOptions{})...)
+		opts.CustomResource = &ledger.KubernetesCustomResource{
+			APIVersion: cr.APIVersion.ValueString(),
+			Kind:       cr.Kind.ValueString(),
+			Plural:     cr.Plural.ValueString(),
+		}
+	} else if opts.Namespace == "" {
+		opts.Namespace = "default"
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: data.Insecure.ValueBool()}
+	if caCertificate != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caCertificate)) {
+			diagnostics.AddAttributeError(attributePath.AtName("cluster_ca_certificate"), "Ledger error", "Unable to parse the cluster CA certificate, which must be PEM encoded.")
+			return nil
+		}
+	}
+	if !data.ClientCertificate.IsNull() || !data.ClientKey.IsNull() {
+		certificate, err := tls.X509KeyPair([]byte(data.ClientCertificate.ValueString()), []byte(data.ClientKey.ValueString()))
+		if err != nil {
+			diagnostics.AddAttributeError(attributePath.AtName("client_certificate"), "Ledger error", fmt.Sprintf("Unable to parse the client certificate and key: %v", err))
+			return nil
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	opts.HTTPClient = &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}}
+	return ledger.NewKubernetesLedger(opts)
+}
+
+// readServiceAccountFile returns the content of a file of the service
+// account credentials mounted in a pod, or an empty string if there is none.
+func readServiceAccountFile(name string) string {
+	b, err := os.ReadFile(serviceAccountDir + "/" + name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// fakeKubernetesServer serves the ConfigMap requests made by the Kubernetes
+// ledger from memory.
+type fakeKubernetesServer struct {
+	m          sync.Mutex
+	configMaps map[string]map[string]any
+	version    int
+}
+
+func newFakeKubernetesServer(t *testing.T) *httptest.Server {
+	f := &fakeKubernetesServer{configMaps: map[string]map[string]any{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeKubernetesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, `{"kind":"Status","message":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	collection, ok := strings.CutPrefix(r.URL.Path, "/api/v1/namespaces/network/configmaps")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		Metadata struct {
+			Name            string `json:"name"`
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Data map[string]any `json:"data"`
+	}
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	name := strings.TrimPrefix(collection, "/")
+	data, exists := f.configMaps[name]
+	switch {
+	case r.Method == http.MethodGet && exists:
+	case r.Method == http.MethodPost && name == "":
+		if _, exists := f.configMaps[body.Metadata.Name]; exists {
+			http.Error(w, `{"kind":"Status","message":"already exists"}`, http.StatusConflict)
+			return
+		}
+		name, data = body.Metadata.Name, body.Data
+		f.version++
+		f.configMaps[name] = data
+	case r.Method == http.MethodPatch && exists:
+		if body.Metadata.ResourceVersion != strconv.Itoa(f.version) {
+			http.Error(w, `{"kind":"Status","message":"the object has been modified"}`, http.StatusConflict)
+			return
+		}
+		for k, v := range body.Data {
+			data[k] = v
+		}
+		f.version++
+	default:
+		http.Error(w, `{"kind":"Status","message":"not found"}`, http.StatusNotFound)
+		return
+	}
+	// Every ConfigMap has the latest resource version, which is enough for
+	// a single ledger.
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"metadata": map[string]any{"name": name, "resourceVersion": strconv.Itoa(f.version)},
+		"data":     data,
+	})
+}
+
+func TestAccProviderLedgerKubernetes(t *testing.T) {
+	t.Setenv("KUBE_HOST", newFakeKubernetesServer(t).URL)
+	t.Setenv("KUBE_TOKEN", "secret")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerKubernetesBackend(`cluster_ca_certificate = "invalid"`), `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+the\s+cluster\s+CA\s+certificate`),
+			},
+		},
+	})
+}
+
+func newKubernetesLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeKubernetesServer(t)
+	t.Setenv("KUBE_HOST", server.URL)
+	t.Setenv("KUBE_TOKEN", "secret")
+	var diags diag.Diagnostics
+	kubernetesLedger := newKubernetesLedger(context.Background(), ledgerKubernetesModel{
+		Namespace:      types.StringValue("network"),
+		Name:           types.StringValue("netcalc"),
+		CustomResource: types.ObjectNull(nil),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return testAccLedgerKubernetesBackend(""), kubernetesLedger
+}
+
+func testAccLedgerKubernetesBackend(tls string) string {
+	return fmt.Sprintf(`
+  ledger_kubernetes = {
+    namespace = "network"
+    name      = "netcalc"
+    %s
+  }`, tls)
+}
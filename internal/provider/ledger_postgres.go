@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	_ "github.com/lib/pq"
+)
+
+// ledgerPostgresModel describes the ledger_postgres provider attribute.
+type ledgerPostgresModel struct {
+	ConnectionString types.String `tfsdk:"connection_string"`
+	Table            types.String `tfsdk:"table"`
+}
+
+func ledgerPostgresAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a PostgreSQL table instead of a local file, so Terraform states on different machines share a ledger, and allocations can be reported on with SQL. Every allocation is a row with the columns `cidr`, `owner`, `allocated_at` and `released_at`, and released allocations are kept with `released_at` set. The table is created if it does not exist, with a unique index on the CIDR blocks of active allocations. Allocations hold a transaction-level advisory lock, so concurrent applies take turns instead of handing out overlapping CIDR blocks.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"connection_string": schema.StringAttribute{
+				MarkdownDescription: "Connection string of the database, e.g. `postgres://netcalc@db.example.com/network?sslmode=verify-full`. Connection parameters that are not set default to the standard PostgreSQL environment variables, such as `PGHOST`, `PGUSER` and `PGPASSWORD`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Name of the allocations table, optionally qualified with a schema, e.g. `network.allocations`. Defaults to `netcalc_allocations`.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// postgresDriverName is the database/sql driver of the PostgreSQL ledger. It
+// is replaced in tests.
+var postgresDriverName = "postgres"
+
+// newPostgresLedger returns the ledger configured by the ledger_postgres
+// provider attribute.
+func newPostgresLedger(data ledgerPostgresModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	db, err := sql.Open(postgresDriverName, data.ConnectionString.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ledger_postgres").AtName("connection_string"), "Ledger error", fmt.Sprintf("Unable to parse the connection string: %v", err))
+		return nil
+	}
+	postgresLedger, err := ledger.NewPostgresLedger(db, ledger.PostgresOptions{Table: data.Table.ValueString()})
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ledger_postgres").AtName("table"), "Ledger error", fmt.Sprintf("Unable to configure the allocation ledger: %v", err))
+		return nil
+	}
+	return postgresLedger
+}
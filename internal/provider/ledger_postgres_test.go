@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func init() {
+	sql.Register("fakepostgres", &fakePostgresDriver{})
+}
+
+// fakePostgresDriver is a database/sql driver that runs the statements of
+// the PostgreSQL ledger against an in-memory table shared by all
+// connections.
+type fakePostgresDriver struct {
+	m    sync.Mutex
+	rows [][]driver.Value
+}
+
+func (d *fakePostgresDriver) Open(string) (driver.Conn, error) { return d, nil }
+
+func (d *fakePostgresDriver) Prepare(query string) (driver.Stmt, error) {
+	return &fakePostgresStmt{driver: d, query: query}, nil
+}
+
+func (d *fakePostgresDriver) Close() error              { return nil }
+func (d *fakePostgresDriver) Begin() (driver.Tx, error) { return d, nil }
+func (d *fakePostgresDriver) Commit() error             { return nil }
+func (d *fakePostgresDriver) Rollback() error           { return nil }
+
+type fakePostgresStmt struct {
+	driver *fakePostgresDriver
+	query  string
+}
+
+func (s *fakePostgresStmt) Close() error  { return nil }
+func (s *fakePostgresStmt) NumInput() int { return -1 }
+
+func (s *fakePostgresStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.driver
+	d.m.Lock()
+	defer d.m.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "SELECT pg_advisory_xact_lock("), strings.HasPrefix(s.query, "CREATE "):
+	case strings.HasPrefix(s.query, "INSERT INTO "):
+		d.rows = append(d.rows, []driver.Value{args[0], args[1], args[2], nil})
+	case strings.HasPrefix(s.query, "UPDATE "):
+		for _, row := range d.rows {
+			if row[0] == args[1] && row[1] == args[2] && row[3] == nil {
+				row[3] = args[0]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakePostgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.driver
+	d.m.Lock()
+	defer d.m.Unlock()
+	rows := &fakePostgresRows{}
+	for _, row := range d.rows {
+		switch {
+		case strings.HasPrefix(s.query, "SELECT cidr, owner, allocated_at, released_at FROM "):
+			rows.values = append(rows.values, append([]driver.Value{}, row...))
+		case strings.HasPrefix(s.query, "SELECT cidr, owner FROM "):
+			if row[3] == nil && netip.MustParsePrefix(row[0].(string)).Overlaps(netip.MustParsePrefix(args[0].(string))) {
+				rows.values = append(rows.values, row[:2])
+			}
+		default:
+			return nil, fmt.Errorf("unexpected query %q", s.query)
+		}
+	}
+	return rows, nil
+}
+
+type fakePostgresRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakePostgresRows) Columns() []string {
+	if len(r.values) == 0 {
+		return nil
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakePostgresRows) Close() error { return nil }
+
+func (r *fakePostgresRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestAccProviderLedgerPostgres(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Ledger validation
+			{
+				Config: testAccProviderLedgerConfig("", `
+				ledger_postgres = {
+				  table = "allocations; DROP TABLE users"
+				}`, `
+				resource "netcalc_subnet" "test" {
+				  cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`invalid\s+table\s+name`),
+			},
+		},
+	})
+}
+
+func newPostgresLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	driverName := postgresDriverName
+	t.Cleanup(func() { postgresDriverName = driverName })
+	postgresDriverName = "fakepostgres"
+
+	var diags diag.Diagnostics
+	postgresLedger := newPostgresLedger(ledgerPostgresModel{
+		ConnectionString: types.StringValue("postgres://localhost/network"),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return `
+  ledger_postgres = {
+    connection_string = "postgres://localhost/network"
+  }`, postgresLedger
+}
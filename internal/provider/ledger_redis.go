@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// ledgerRedisModel describes the ledger_redis provider attribute.
+type ledgerRedisModel struct {
+	URL      types.String `tfsdk:"url"`
+	Password types.String `tfsdk:"password"`
+	Key      types.String `tfsdk:"key"`
+}
+
+func ledgerRedisAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a Redis key instead of a local file, so Terraform states on different machines share a ledger with little latency, e.g. for preview environments that allocate and release subnets constantly. Updates hold a lock set with `SET NX` on a second key, named after the ledger key with a `.lock` suffix, which expires if Terraform is interrupted, so concurrent applies take turns instead of handing out overlapping CIDR blocks.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL of the Redis server, e.g. `redis://redis.example.com:6379/0`, or `rediss://` to connect with TLS. Defaults to the `REDIS_URL` environment variable, or `redis://localhost:6379`.",
+				Optional:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password to authenticate with, instead of one in the URL. Defaults to the `REDIS_PASSWORD` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the ledger, e.g. `netcalc:ledger`. The key is created on the first allocation.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newRedisLedger returns the ledger configured by the ledger_redis provider
+// attribute.
+func newRedisLedger(data ledgerRedisModel, diagnostics *diag.Diagnostics) ledger.Ledger {
+	url := data.URL.ValueString()
+	if url == "" {
+		url = os.Getenv("REDIS_URL")
+	}
+	if url == "" {
+		url = "redis://localhost:6379"
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ledger_redis").AtName("url"), "Ledger error", fmt.Sprintf("Unable to parse the Redis URL: %v", err))
+		return nil
+	}
+	if password := data.Password.ValueString(); password != "" {
+		opts.Password = password
+	} else if password := os.Getenv("REDIS_PASSWORD"); password != "" && opts.Password == "" {
+		opts.Password = password
+	}
+	return ledger.NewRedisLedger(redis.NewClient(opts), ledger.RedisOptions{Key: data.Key.ValueString()})
+}
@@ -0,0 +1,29 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func newRedisLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	t.Setenv("REDIS_URL", "redis://"+server.Addr())
+	t.Setenv("REDIS_PASSWORD", "secret")
+	var diags diag.Diagnostics
+	redisLedger := newRedisLedger(ledgerRedisModel{Key: types.StringValue("netcalc:ledger")}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return `
+  ledger_redis = {
+    key = "netcalc:ledger"
+  }`, redisLedger
+}
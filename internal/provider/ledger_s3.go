@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerS3Model describes the ledger_s3 provider attribute.
+type ledgerS3Model struct {
+	Bucket           types.String `tfsdk:"bucket"`
+	Key              types.String `tfsdk:"key"`
+	DynamoDBTable    types.String `tfsdk:"dynamodb_table"`
+	Region           types.String `tfsdk:"region"`
+	Profile          types.String `tfsdk:"profile"`
+	S3Endpoint       types.String `tfsdk:"s3_endpoint"`
+	DynamoDBEndpoint types.String `tfsdk:"dynamodb_endpoint"`
+	UsePathStyle     types.Bool   `tfsdk:"use_path_style"`
+}
+
+func ledgerS3Attribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a JSON object in S3 instead of a local file, so Terraform states on different machines share a ledger. Updates hold a lock item in a DynamoDB table, so concurrent applies take turns instead of handing out overlapping CIDR blocks. Credentials are taken from the usual AWS environment variables, shared configuration files and instance roles.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the S3 bucket.",
+				Required:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the ledger object, e.g. `netcalc/ledger.json`. The object is created on the first allocation.",
+				Required:            true,
+			},
+			"dynamodb_table": schema.StringAttribute{
+				MarkdownDescription: "Name of the DynamoDB table holding the lock. Its partition key must be the string attribute `LockID`, as for Terraform's S3 backend, so the same table can be used for both.",
+				Required:            true,
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "AWS region of the bucket and table. Defaults to the region of the AWS configuration.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the AWS shared configuration profile to use.",
+				Optional:            true,
+			},
+			"s3_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom S3 endpoint URL, e.g. for S3-compatible object stores.",
+				Optional:            true,
+			},
+			"dynamodb_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom DynamoDB endpoint URL.",
+				Optional:            true,
+			},
+			"use_path_style": schema.BoolAttribute{
+				MarkdownDescription: "Whether to address the bucket in the path rather than the host name of S3 requests, as some S3-compatible object stores require.",
+				Optional:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newS3Ledger returns the ledger configured by the ledger_s3 provider
+// attribute.
+func newS3Ledger(ctx context.Context, data ledgerS3Model) (ledger.Ledger, error) {
+	cfg, err := loadAWSConfig(ctx, data.Region.ValueString(), data.Profile.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS configuration: %w", err)
+	}
+
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := data.S3Endpoint.ValueString(); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = data.UsePathStyle.ValueBool()
+	})
+	dynamodbClient := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if endpoint := data.DynamoDBEndpoint.ValueString(); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return ledger.NewS3Ledger(s3Client, dynamodbClient, ledger.S3Options{
+		Bucket:    data.Bucket.ValueString(),
+		Key:       data.Key.ValueString(),
+		LockTable: data.DynamoDBTable.ValueString(),
+	}), nil
+}
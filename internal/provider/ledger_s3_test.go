@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeAWSServer serves the S3 and DynamoDB requests made by the S3 ledger
+// from memory, with path style S3 addressing.
+type fakeAWSServer struct {
+	m       sync.Mutex
+	objects map[string][]byte
+	locks   map[string]string
+}
+
+func newFakeAWSServer(t *testing.T) *httptest.Server {
+	f := &fakeAWSServer{objects: map[string][]byte{}, locks: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	return server
+}
+
+func (f *fakeAWSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	body, _ := io.ReadAll(r.Body)
+
+	target := r.Header.Get("X-Amz-Target")
+	switch {
+	case target == "DynamoDB_20120810.PutItem":
+		var req struct {
+			Item map[string]map[string]string
+		}
+		_ = json.Unmarshal(body, &req)
+		id := req.Item["LockID"]["S"]
+		if _, ok := f.locks[id]; ok {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+			return
+		}
+		f.locks[id] = req.Item["Info"]["S"]
+		fmt.Fprint(w, `{}`)
+	case target == "DynamoDB_20120810.DeleteItem":
+		var req struct {
+			Key map[string]map[string]string
+		}
+		_ = json.Unmarshal(body, &req)
+		delete(f.locks, req.Key["LockID"]["S"])
+		fmt.Fprint(w, `{}`)
+	case r.Method == http.MethodGet:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, len(b)))
+		_, _ = w.Write(b)
+	case r.Method == http.MethodPut:
+		f.objects[r.URL.Path] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, len(body)))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newS3LedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeAWSServer(t)
+	s3Ledger, err := newS3Ledger(context.Background(), ledgerS3Model{
+		Bucket:           types.StringValue("bucket"),
+		Key:              types.StringValue("netcalc/ledger.json"),
+		DynamoDBTable:    types.StringValue("locks"),
+		Region:           types.StringValue("us-east-1"),
+		S3Endpoint:       types.StringValue(server.URL),
+		DynamoDBEndpoint: types.StringValue(server.URL),
+		UsePathStyle:     types.BoolValue(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf(`
+  ledger_s3 = {
+    bucket            = "bucket"
+    key               = "netcalc/ledger.json"
+    dynamodb_table    = "locks"
+    region            = "us-east-1"
+    s3_endpoint       = %[1]q
+    dynamodb_endpoint = %[1]q
+    use_path_style    = true
+  }`, server.URL), s3Ledger
+}
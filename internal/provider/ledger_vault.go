@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ledgerVaultModel describes the ledger_vault provider attribute.
+type ledgerVaultModel struct {
+	Address   types.String `tfsdk:"address"`
+	Token     types.String `tfsdk:"token"`
+	Namespace types.String `tfsdk:"namespace"`
+	Mount     types.String `tfsdk:"mount"`
+	Path      types.String `tfsdk:"path"`
+}
+
+func ledgerVaultAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Records allocations in a secret of a Vault KV version 2 secrets engine instead of a local file, so Terraform states on different machines share a ledger. Every write is a check-and-set against the version of the secret that was read, and is retried when another Terraform run wrote the secret in the meantime, so concurrent applies do not hand out overlapping CIDR blocks.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address of the Vault server, e.g. `https://vault.example.com:8200`. Defaults to the `VAULT_ADDR` environment variable, or `https://127.0.0.1:8200`.",
+				Optional:            true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "Token to authenticate with, which needs the `read`, `create` and `update` capabilities on the secret. Defaults to the `VAULT_TOKEN` environment variable.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "Vault Enterprise namespace of the secrets engine. Defaults to the `VAULT_NAMESPACE` environment variable.",
+				Optional:            true,
+			},
+			"mount": schema.StringAttribute{
+				MarkdownDescription: "Path the KV version 2 secrets engine is mounted at. Defaults to `secret`.",
+				Optional:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the secret within the secrets engine, e.g. `netcalc/ledger`. The secret is created on the first allocation, and every change of the ledger is a new version of it.",
+				Required:            true,
+			},
+		},
+		Validators: []validator.Object{
+			ledgerConflictsValidator(),
+		},
+	}
+}
+
+// newVaultLedger returns the ledger configured by the ledger_vault provider
+// attribute, with the address, token and namespace defaulting to the
+// environment variables of the Vault CLI.
+func newVaultLedger(data ledgerVaultModel) ledger.Ledger {
+	opts := ledger.VaultOptions{
+		Address:   data.Address.ValueString(),
+		Token:     data.Token.ValueString(),
+		Namespace: data.Namespace.ValueString(),
+		Mount:     data.Mount.ValueString(),
+		Path:      data.Path.ValueString(),
+	}
+	if opts.Address == "" {
+		opts.Address = os.Getenv("VAULT_ADDR")
+	}
+	if opts.Address == "" {
+		opts.Address = "https://127.0.0.1:8200"
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	return ledger.NewVaultLedger(opts)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// fakeVaultServer serves the KV version 2 requests made by the Vault ledger
+// from memory, for a secrets engine mounted at kv.
+type fakeVaultServer struct {
+	m        sync.Mutex
+	versions map[string][]json.RawMessage
+}
+
+func newFakeVaultServer(t *testing.T) *httptest.Server {
+	f := &fakeVaultServer{versions: map[string][]json.RawMessage{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeVaultServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("X-Vault-Token") != "secret" {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/kv/data/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	versions := f.versions[path]
+	switch r.Method {
+	case http.MethodGet:
+		if len(versions) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"data":%s,"metadata":{"version":%d}}}`, versions[len(versions)-1], len(versions))
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Options.CAS != nil && *req.Options.CAS != len(versions) {
+			http.Error(w, `{"errors":["check-and-set parameter did not match the current version"]}`, http.StatusBadRequest)
+			return
+		}
+		f.versions[path] = append(versions, req.Data)
+		fmt.Fprintf(w, `{"data":{"version":%d}}`, len(f.versions[path]))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newVaultLedgerFixture(t *testing.T) (string, ledger.Ledger) {
+	server := newFakeVaultServer(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "secret")
+	vaultLedger := newVaultLedger(ledgerVaultModel{
+		Mount: types.StringValue("kv"),
+		Path:  types.StringValue("netcalc/ledger"),
+	})
+	return `
+  ledger_vault = {
+    mount = "kv"
+    path  = "netcalc/ledger"
+  }`, vaultLedger
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MaskFromHostsFunction{}
+
+func NewMaskFromHostsFunction() function.Function {
+	return &MaskFromHostsFunction{}
+}
+
+// MaskFromHostsFunction defines the function implementation.
+type MaskFromHostsFunction struct {
+}
+
+func (f *MaskFromHostsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "mask_from_hosts"
+}
+
+func (f *MaskFromHostsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the mask length for a number of hosts",
+		MarkdownDescription: "Returns the longest mask length (the smallest subnet) that provides at least the given number of usable host addresses. IPv4 subnets reserve the network and broadcast addresses, so 30 hosts need a /27 while 31 hosts need a /26.",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:                "host_count",
+				MarkdownDescription: "Number of usable host addresses, at least 1.",
+			},
+			function.StringParameter{
+				Name:                "ip_family",
+				MarkdownDescription: "IP family of the subnet, either ipv4 or ipv6.",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+func (f *MaskFromHostsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var hostCount int64
+	var ipFamily string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &hostCount, &ipFamily))
+	if resp.Error != nil {
+		return
+	}
+
+	if hostCount < 1 {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Host count must be at least 1, got %d", hostCount))
+		return
+	}
+	if ipFamily != ipFamilyIPv4 && ipFamily != ipFamilyIPv6 {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("IP family must be %s or %s, got %q", ipFamilyIPv4, ipFamilyIPv6, ipFamily))
+		return
+	}
+
+	maskLength, err := subnet.MaskLengthForHosts(uint64(hostCount), ipFamily == ipFamilyIPv6)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to size subnet: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, int64(maskLength)))
+}
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccMaskFromHostsFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Host count validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::mask_from_hosts(0, "ipv4")
+				}`,
+				ExpectError: regexp.MustCompile(`Host\s+count\s+must\s+be\s+at\s+least\s+1`),
+			},
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::mask_from_hosts(4294967295, "ipv4")
+				}`,
+				ExpectError: regexp.MustCompile(`do\s+not\s+fit\s+in\s+the\s+IPv4\s+address\s+space`),
+			},
+			// IP family validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::mask_from_hosts(10, "dual")
+				}`,
+				ExpectError: regexp.MustCompile(`IP\s+family\s+must\s+be\s+ipv4\s+or\s+ipv6`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4_30" {
+					value = provider::netcalc::mask_from_hosts(30, "ipv4")
+				}
+				output "ipv4_31" {
+					value = provider::netcalc::mask_from_hosts(31, "ipv4")
+				}
+				output "ipv4_1" {
+					value = provider::netcalc::mask_from_hosts(1, "ipv4")
+				}
+				output "ipv6_1" {
+					value = provider::netcalc::mask_from_hosts(1, "ipv6")
+				}
+				output "ipv6_256" {
+					value = provider::netcalc::mask_from_hosts(256, "ipv6")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4_30", "27"),
+					resource.TestCheckOutput("ipv4_31", "26"),
+					resource.TestCheckOutput("ipv4_1", "30"),
+					resource.TestCheckOutput("ipv6_1", "128"),
+					resource.TestCheckOutput("ipv6_256", "120"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// maskLengthLimitModel describes the min_cidr_mask_length and
+// max_cidr_mask_length provider attributes.
+type maskLengthLimitModel struct {
+	IPv4 types.Int64 `tfsdk:"ipv4"`
+	IPv6 types.Int64 `tfsdk:"ipv6"`
+}
+
+// maskLengthPolicyDescription is the part of the description shared by the
+// mask length limit attributes.
+const maskLengthPolicyDescription = "Applies to the subnets of netcalc_subnet, netcalc_subnet_group, netcalc_subnet_pair, netcalc_dual_stack_subnet and netcalc_static_subnet resources when they are created or their mask length changes, so tightening the limits does not affect existing subnets."
+
+func maskLengthLimitAttribute(description string) schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: description + " " + maskLengthPolicyDescription,
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"ipv4": schema.Int64Attribute{
+				MarkdownDescription: "Limit of IPv4 subnets.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.Between(0, 32)},
+			},
+			"ipv6": schema.Int64Attribute{
+				MarkdownDescription: "Limit of IPv6 subnets.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.Between(0, 128)},
+			},
+		},
+	}
+}
+
+// maskLengthPolicy limits the mask lengths of subnets per IP family. A limit
+// of zero does not apply.
+type maskLengthPolicy struct {
+	minIPv4, maxIPv4 int64
+	minIPv6, maxIPv6 int64
+}
+
+// newMaskLengthPolicy returns the policy configured by the
+// min_cidr_mask_length and max_cidr_mask_length provider attributes.
+func newMaskLengthPolicy(ctx context.Context, data SubnetCalculatorProviderModel, diagnostics *diag.Diagnostics) maskLengthPolicy {
+	var minimum, maximum maskLengthLimitModel
+	if !data.MinCIDRMaskLength.IsNull() {
+		diagnostics.Append(data.MinCIDRMaskLength.As(ctx, &minimum, basetypes.ObjectAsOptions{})...)
+	}
+	if !data.MaxCIDRMaskLength.IsNull() {
+		diagnostics.Append(data.MaxCIDRMaskLength.As(ctx, &maximum, basetypes.ObjectAsOptions{})...)
+	}
+	p := maskLengthPolicy{
+		minIPv4: minimum.IPv4.ValueInt64(),
+		maxIPv4: maximum.IPv4.ValueInt64(),
+		minIPv6: minimum.IPv6.ValueInt64(),
+		maxIPv6: maximum.IPv6.ValueInt64(),
+	}
+	for _, family := range []string{ipFamilyIPv4, ipFamilyIPv6} {
+		minimum, maximum := p.limits(family == ipFamilyIPv6)
+		if minimum > 0 && maximum > 0 && minimum > maximum {
+			diagnostics.AddAttributeError(
+				path.Root("min_cidr_mask_length").AtName(family),
+				"Invalid mask length limits",
+				fmt.Sprintf("The minimum %s CIDR mask length /%d is greater than the maximum /%d, so no subnet would be allowed.", family, minimum, maximum),
+			)
+		}
+	}
+	return p
+}
+
+// limits returns the minimum and maximum mask length of an IP family.
+func (p maskLengthPolicy) limits(ipv6 bool) (int64, int64) {
+	if ipv6 {
+		return p.minIPv6, p.maxIPv6
+	}
+	return p.minIPv4, p.maxIPv4
+}
+
+// check reports a mask length outside the policy as an error of the given
+// attribute.
+func (p maskLengthPolicy) check(attribute path.Path, ipv6 bool, maskLength int64) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	minimum, maximum := p.limits(ipv6)
+	family := ipFamilyIPv4
+	if ipv6 {
+		family = ipFamilyIPv6
+	}
+	switch {
+	case minimum > 0 && maskLength < minimum:
+		diagnostics.AddAttributeError(
+			attribute,
+			"CIDR mask length not allowed",
+			fmt.Sprintf("A /%d %s subnet is larger than the provider allows. The provider attribute min_cidr_mask_length.%s requires a mask length of at least /%d.", maskLength, family, family, minimum),
+		)
+	case maximum > 0 && maskLength > maximum:
+		diagnostics.AddAttributeError(
+			attribute,
+			"CIDR mask length not allowed",
+			fmt.Sprintf("A /%d %s subnet is smaller than the provider allows. The provider attribute max_cidr_mask_length.%s requires a mask length of at most /%d.", maskLength, family, family, maximum),
+		)
+	}
+	return diagnostics
+}
+
+// checkPlanned reports a planned mask length of an IP family that does not
+// fit in the pools, the CIDR blocks of each pool the subnets are allocated
+// from, or is outside the policy. Mask lengths that are unknown, or unchanged
+// since the prior state, are not checked.
+func (p maskLengthPolicy) checkPlanned(attribute path.Path, family types.String, maskLength types.Int64, priorFamily types.String, priorMaskLength types.Int64, pools ...[]netip.Prefix) diag.Diagnostics {
+	if family.IsUnknown() || maskLength.IsNull() || maskLength.IsUnknown() {
+		return nil
+	}
+	if family.Equal(priorFamily) && maskLength.Equal(priorMaskLength) {
+		return nil
+	}
+	ipv6 := family.ValueString() == ipFamilyIPv6
+	diagnostics := checkMaskLengthFits(attribute, ipv6, maskLength.ValueInt64(), pools...)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+	return p.check(attribute, ipv6, maskLength.ValueInt64())
+}
+
+// checkMaskLengthFits reports a mask length that is longer than the addresses
+// of its IP family, or shorter than every CIDR block of the IP family in one
+// of the pools, so no subnet could be allocated. Pools without CIDR blocks of
+// the IP family are not checked.
+func checkMaskLengthFits(attribute path.Path, ipv6 bool, maskLength int64, pools ...[]netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	family, bits := ipFamilyIPv4, int64(32)
+	if ipv6 {
+		family, bits = ipFamilyIPv6, 128
+	}
+	if maskLength < 0 || maskLength > bits {
+		diagnostics.AddAttributeError(
+			attribute,
+			"Invalid CIDR mask length",
+			fmt.Sprintf("An %s CIDR mask length must be between /0 and /%d, got: /%d.", family, bits, maskLength),
+		)
+		return diagnostics
+	}
+	for _, pool := range pools {
+		var largest netip.Prefix
+		for _, prefix := range pool {
+			if prefix.Addr().Is6() == ipv6 && (!largest.IsValid() || prefix.Bits() < largest.Bits()) {
+				largest = prefix
+			}
+		}
+		if largest.IsValid() && maskLength < int64(largest.Bits()) {
+			diagnostics.AddAttributeError(
+				attribute,
+				"CIDR mask length too short",
+				fmt.Sprintf("A /%d %s subnet does not fit in its pool, whose largest CIDR block is %s. Use a mask length of at least /%d.", maskLength, family, largest, largest.Bits()),
+			)
+			return diagnostics
+		}
+	}
+	return diagnostics
+}
+
+// allocationPools returns the CIDR blocks of the pool a resource allocates
+// from: its netcalc_pool or named pool, or else the pools of the provider. It
+// returns nil when the pool is not known yet.
+func allocationPools(calculator SubnetCalculator, pools map[string]namedPool, pool types.String, id types.String) []netip.Prefix {
+	if calculator == nil {
+		return nil
+	}
+	poolID, ok := resolvePool(pools, pool, id)
+	if !ok || poolID.IsUnknown() {
+		return nil
+	}
+	if poolID.IsNull() {
+		return append(calculator.Pools(false), calculator.Pools(true)...)
+	}
+	prefixes, _, err := parsePoolID(poolID.ValueString())
+	if err != nil {
+		return nil
+	}
+	return prefixes
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderMaskLengthPolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Policy validation
+			{
+				Config: testAccProviderMaskLengthPolicyConfig(`
+				min_cidr_mask_length = {
+					ipv4 = 28
+				}`, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				ExpectError: regexp.MustCompile(`The\s+minimum\s+ipv4\s+CIDR\s+mask\s+length\s+/28\s+is\s+greater\s+than\s+the\s+maximum\s+/26`),
+			},
+			// Subnets outside the policy are rejected when planning.
+			{
+				Config: testAccProviderMaskLengthPolicyConfig("", `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 20
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/20\s+ipv4\s+subnet\s+is\s+larger\s+than\s+the\s+provider\s+allows`),
+			},
+			{
+				Config: testAccProviderMaskLengthPolicyConfig("", `
+				resource "netcalc_subnet" "test" {
+					min_hosts = 2
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/30\s+ipv4\s+subnet\s+is\s+smaller\s+than\s+the\s+provider\s+allows.\s+The\s+provider\s+attribute\s+max_cidr_mask_length.ipv4\s+requires\s+a\s+mask\s+length\s+of\s+at\s+most\s+/26`),
+			},
+			{
+				Config: testAccProviderMaskLengthPolicyConfig("", `
+				resource "netcalc_dual_stack_subnet" "test" {
+					ipv4_cidr_mask_length = 24
+					ipv6_cidr_mask_length = 56
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/56\s+ipv6\s+subnet\s+is\s+larger\s+than\s+the\s+provider\s+allows`),
+			},
+			{
+				Config: testAccProviderMaskLengthPolicyConfig("", `
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.0.0.0/27"
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/27\s+ipv4\s+subnet\s+is\s+smaller\s+than\s+the\s+provider\s+allows`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccProviderMaskLengthPolicyConfig("", `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_dual_stack_subnet" "test" {
+					ipv4_cidr_mask_length = 26
+					ipv6_cidr_mask_length = 64
+					depends_on            = [netcalc_subnet.test]
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_dual_stack_subnet.test", "ipv4_cidr_block", "10.0.1.0/26"),
+				),
+			},
+			// Tightening the policy does not affect existing subnets.
+			{
+				Config: testAccProviderMaskLengthPolicyConfig(`
+				min_cidr_mask_length = {
+					ipv4 = 25
+				}`, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_dual_stack_subnet" "test" {
+					ipv4_cidr_mask_length = 26
+					ipv6_cidr_mask_length = 64
+					depends_on            = [netcalc_subnet.test]
+				}`),
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func testAccProviderMaskLengthPolicyConfig(minimum string, resources string) string {
+	if minimum == "" {
+		minimum = `
+				min_cidr_mask_length = {
+					ipv4 = 22
+					ipv6 = 60
+				}`
+	}
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16", "fd00::/48"]
+  %[1]s
+  max_cidr_mask_length = {
+    ipv4 = 26
+  }
+}
+%[2]s
+`, minimum, resources)
+}
+
+// Mask lengths that cannot fit in their IP family or their pools are rejected
+// when planning, without a mask length policy.
+func TestAccMaskLengthFits(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 33
+				}`),
+				ExpectError: regexp.MustCompile(`An\s+ipv4\s+CIDR\s+mask\s+length\s+must\s+be\s+between\s+/0\s+and\s+/32,\s+got:\s+/33`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnet" "test" {
+					ip_family        = "ipv6"
+					cidr_mask_length = 129
+				}`),
+				ExpectError: regexp.MustCompile(`An\s+ipv6\s+CIDR\s+mask\s+length\s+must\s+be\s+between\s+/0\s+and\s+/128,\s+got:\s+/129`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnet_group" "test" {
+					keys             = ["a"]
+					cidr_mask_length = 15
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/15\s+ipv4\s+subnet\s+does\s+not\s+fit\s+in\s+its\s+pool,\s+whose\s+largest\s+CIDR\s+block\s+is\s+10.0.0.0/16`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnet" "test" {
+					pool             = "lab"
+					cidr_mask_length = 20
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/20\s+ipv4\s+subnet\s+does\s+not\s+fit\s+in\s+its\s+pool,\s+whose\s+largest\s+CIDR\s+block\s+is\s+192.168.0.0/24`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_dual_stack_subnet" "test" {
+					ipv4_cidr_mask_length = 24
+					ipv6_cidr_mask_length = 40
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/40\s+ipv6\s+subnet\s+does\s+not\s+fit\s+in\s+its\s+pool,\s+whose\s+largest\s+CIDR\s+block\s+is\s+fd00::/48`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["172.16.0.0/24"]
+					cidr_mask_length = 33
+					cidr_count       = 1
+				}`),
+				ExpectError: regexp.MustCompile(`An\s+ipv4\s+CIDR\s+mask\s+length\s+must\s+be\s+between\s+/0\s+and\s+/32`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["fd01::/64"]
+					cidr_mask_length = 60
+					cidr_count       = 1
+				}`),
+				ExpectError: regexp.MustCompile(`A\s+/60\s+ipv6\s+subnet\s+does\s+not\s+fit\s+in\s+its\s+pool,\s+whose\s+largest\s+CIDR\s+block\s+is\s+fd01::/64`),
+			},
+			{
+				Config: testAccMaskLengthFitsConfig(`
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 16
+				}`),
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/16"),
+			},
+		},
+	})
+}
+
+func testAccMaskLengthFitsConfig(resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16", "10.1.0.0/24", "fd00::/48"]
+
+  pools = {
+    lab = {
+      cidr_blocks = ["192.168.0.0/24"]
+    }
+  }
+}
+%s
+`, resources)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderMaxCIDRCount(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderMaxCIDRCountConfig(4, 0),
+				ExpectError: regexp.MustCompile(`Attribute\s+cidr_count\s+value\s+must\s+be\s+at\s+least\s+1`),
+			},
+			{
+				Config:      testAccProviderMaxCIDRCountConfig(4, 5),
+				ExpectError: regexp.MustCompile(`max_cidr_count\s+allows\s+at\s+most\s+4`),
+			},
+			{
+				Config: testAccProviderMaxCIDRCountConfig(4, 4),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24,10.0.2.0/24,10.0.3.0/24"),
+			},
+			// Growing past the maximum is rejected as well.
+			{
+				Config:      testAccProviderMaxCIDRCountConfig(4, 6),
+				ExpectError: regexp.MustCompile(`A\s+cidr_count\s+of\s+6\s+is\s+more\s+than\s+the\s+provider\s+allows`),
+			},
+		},
+	})
+}
+
+func testAccProviderMaxCIDRCountConfig(maxCount int, count int) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  max_cidr_count = %[1]d
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  cidr_mask_length = 24
+  cidr_count       = %[2]d
+}
+`, maxCount, count)
+}
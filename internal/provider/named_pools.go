@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// namedPoolModel describes a pool in the provider's pools attribute.
+type namedPoolModel struct {
+	CIDRBlocks  types.List   `tfsdk:"cidr_blocks"`
+	Description types.String `tfsdk:"description"`
+	Tags        types.Map    `tfsdk:"tags"`
+}
+
+// namedPool is a pool configured in the provider's pools attribute.
+type namedPool struct {
+	cidrBlocks  []netip.Prefix
+	description types.String
+	tags        types.Map
+}
+
+func namedPoolsAttribute() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		MarkdownDescription: "Named pools, keyed by name, for managing several independent address plans from one provider block. Resources allocate from a named pool by setting their `pool` attribute to its name. The CIDR blocks of named pools are not part of `pool_cidr_blocks`, so resources without a pool never allocate from them.",
+		Optional:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"cidr_blocks": schema.ListAttribute{
+					ElementType:         types.StringType,
+					MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks of the pool. Subnets are allocated from them in the order set by the provider's `pool_priorities`, not in the order listed.",
+					Required:            true,
+					Validators: []validator.List{
+						listvalidator.SizeAtLeast(1),
+						listvalidator.ValueStringsAre(ipAddressValidator{}),
+					},
+				},
+				"description": schema.StringAttribute{
+					MarkdownDescription: "Description of the pool, reported by the netcalc_pool_lookup data source.",
+					Optional:            true,
+				},
+				"tags": schema.MapAttribute{
+					ElementType:         types.StringType,
+					MarkdownDescription: "Tags describing the pool, reported by the netcalc_pool_lookup data source.",
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// readNamedPools parses the provider's pools attribute.
+func readNamedPools(ctx context.Context, data types.Map, diagnostics *diag.Diagnostics) map[string]namedPool {
+	var models map[string]namedPoolModel
+	diagnostics.Append(data.ElementsAs(ctx, &models, false)...)
+	pools := map[string]namedPool{}
+	for name, model := range models {
+		pools[name] = namedPool{
+			cidrBlocks:  parsePrefixList(model.CIDRBlocks, diagnostics),
+			description: model.Description,
+			tags:        model.Tags,
+		}
+	}
+	return pools
+}
+
+// resolvePool returns the ID of the pool a resource allocates from: the ID of
+// the provider's named pool when pool is set, and otherwise id, the resource's
+// pool_id. It returns false when no named pool has the given name.
+func resolvePool(pools map[string]namedPool, pool types.String, id types.String) (types.String, bool) {
+	if pool.IsNull() {
+		return id, true
+	}
+	if pool.IsUnknown() {
+		return types.StringUnknown(), true
+	}
+	p, ok := pools[pool.ValueString()]
+	if !ok {
+		return types.StringNull(), false
+	}
+	return types.StringValue(poolID(p.cidrBlocks, nil)), true
+}
+
+// unknownPoolError reports a pool name that is not configured in the provider.
+func unknownPoolError(pools map[string]namedPool, pool types.String) diag.Diagnostic {
+	detail := fmt.Sprintf("No pool named %q is configured in the provider's pools.", pool.ValueString())
+	if len(pools) > 0 {
+		detail += fmt.Sprintf(" Configured pools: %s.", strings.Join(sortedKeys(pools), ", "))
+	}
+	return diag.NewAttributeErrorDiagnostic(path.Root("pool"), "Unknown pool", detail)
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccNamedPools(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Unknown pool names are rejected when planning.
+			{
+				Config: `
+				provider "netcalc" {
+					pools = {
+						us = { cidr_blocks = ["10.1.0.0/16"] }
+					}
+				}
+				resource "netcalc_subnet" "test" {
+					pool             = "eu"
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`No\s+pool\s+named\s+"eu"\s+is\s+configured[\s\S]*Configured\s+pools:\s+us\.`),
+			},
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = "10.1.0.0/16"
+					pool             = "us"
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`Attribute\s+"pool_id"\s+cannot\s+be\s+specified\s+when\s+"pool"\s+is\s+specified`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					pools = {
+						us = {
+							cidr_blocks = ["10.1.0.0/16"]
+							tags        = { region = "us" }
+						}
+						eu = {
+							cidr_blocks = ["10.2.0.0/16"]
+							description = "Europe"
+						}
+						ap = {
+							cidr_blocks = ["10.3.0.0/16"]
+						}
+					}
+				}
+				resource "netcalc_subnet" "default" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "us" {
+					pool             = "us"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "eu" {
+					pool             = "eu"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet_group" "ap" {
+					pool             = "ap"
+					keys             = ["a", "b"]
+					cidr_mask_length = 24
+				}
+				data "netcalc_pool_lookup" "eu" {
+					address = "10.2.0.5"
+				}
+				data "netcalc_pool_lookup" "us" {
+					address = "10.1.0.0/24"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.default", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.us", "cidr_block", "10.1.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.eu", "cidr_block", "10.2.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.a", "10.3.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.b", "10.3.1.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.eu", "pool_name", "eu"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.eu", "pool_id", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.eu", "description", "Europe"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.us", "pool_name", "us"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.us", "tags.region", "us"),
+				),
+			},
+			// Moving to a pool that contains the CIDR blocks keeps them,
+			// moving to one that does not reallocates.
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					pools = {
+						us = {
+							cidr_blocks = ["10.1.0.0/16"]
+						}
+						eu = {
+							cidr_blocks = ["10.2.0.0/16"]
+						}
+						ap = {
+							cidr_blocks = ["10.3.0.0/16"]
+						}
+						all = {
+							cidr_blocks = ["10.0.0.0/8"]
+						}
+					}
+				}
+				resource "netcalc_subnet" "default" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "us" {
+					pool             = "eu"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "eu" {
+					pool             = "all"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet_group" "ap" {
+					pool             = "all"
+					keys             = ["a", "b"]
+					cidr_mask_length = 24
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet.us", plancheck.ResourceActionDestroyBeforeCreate),
+						plancheck.ExpectResourceAction("netcalc_subnet.eu", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("netcalc_subnet_group.ap", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("netcalc_subnet.us", "cidr_block", regexp.MustCompile(`^10\.2\.\d+\.0/24$`)),
+					resource.TestCheckResourceAttr("netcalc_subnet.eu", "cidr_block", "10.2.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.a", "10.3.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.b", "10.3.1.0/24"),
+				),
+			},
+			// Subnets that no longer fit in their pool are reallocated.
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					pools = {
+						eu = {
+							cidr_blocks = ["10.2.0.0/16"]
+						}
+						ap = {
+							cidr_blocks = ["10.4.0.0/16"]
+						}
+						all = {
+							cidr_blocks = ["10.0.0.0/8"]
+						}
+					}
+				}
+				resource "netcalc_subnet" "default" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "us" {
+					pool             = "eu"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "eu" {
+					pool             = "all"
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet_group" "ap" {
+					pool             = "ap"
+					keys             = ["a", "b"]
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.a", "10.4.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.ap", "cidr_blocks.b", "10.4.1.0/24"),
+				),
+			},
+		},
+	})
+}
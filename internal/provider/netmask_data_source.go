@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &NetmaskDataSource{}
+
+func NewNetmaskDataSource() datasource.DataSource {
+	return &NetmaskDataSource{}
+}
+
+// NetmaskDataSource defines the data source implementation.
+type NetmaskDataSource struct {
+}
+
+// NetmaskDataSourceModel describes the data source data model.
+type NetmaskDataSourceModel struct {
+	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	IPFamily       types.String `tfsdk:"ip_family"`
+	Netmask        types.String `tfsdk:"netmask"`
+	WildcardMask   types.String `tfsdk:"wildcard_mask"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func (d *NetmaskDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_netmask"
+}
+
+func (d *NetmaskDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Netmask data source. Converts between a prefix length, a netmask such as `255.255.255.0` and a wildcard mask such as `0.0.0.255`, e.g. for templating device configurations that expect masks in address form. Exactly one of cidr_mask_length, netmask or wildcard_mask must be set, and the others are computed from it.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Prefix length, between 0 and 32 for IPv4 and 0 and 128 for IPv6.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 128),
+					int64validator.ExactlyOneOf(path.MatchRoot("netmask"), path.MatchRoot("wildcard_mask")),
+				},
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "IP family of the masks, either ipv4 or ipv6. Can only be set with cidr_mask_length, and defaults to ipv4. Otherwise the IP family of the given mask.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6),
+					stringvalidator.AlsoRequires(path.MatchRoot("cidr_mask_length")),
+				},
+			},
+			"netmask": schema.StringAttribute{
+				MarkdownDescription: "Netmask in address form, e.g. `255.255.255.0`. The mask bits must be contiguous.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"wildcard_mask": schema.StringAttribute{
+				MarkdownDescription: "Inverse of the netmask, e.g. `0.0.0.255`, as used by ACLs. The mask bits must be contiguous.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the prefix length.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *NetmaskDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data NetmaskDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var prefix netip.Prefix
+	switch {
+	case !data.Netmask.IsNull():
+		mask, err := netip.ParseAddr(data.Netmask.ValueString())
+		if err == nil {
+			var length int
+			length, err = subnet.MaskLength(mask.Unmap())
+			prefix = netip.PrefixFrom(mask.Unmap(), length)
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("netmask"), "Netmask parsing error", fmt.Sprintf("Unable to parse netmask: %q, %v", data.Netmask.ValueString(), err))
+			return
+		}
+	case !data.WildcardMask.IsNull():
+		mask, err := netip.ParseAddr(data.WildcardMask.ValueString())
+		if err == nil {
+			var length int
+			length, err = subnet.WildcardMaskLength(mask.Unmap())
+			prefix = netip.PrefixFrom(mask.Unmap(), length)
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wildcard_mask"), "Wildcard mask parsing error", fmt.Sprintf("Unable to parse wildcard mask: %q, %v", data.WildcardMask.ValueString(), err))
+			return
+		}
+	default:
+		maskLength := int(data.CIDRMaskLength.ValueInt64())
+		addr := netip.IPv4Unspecified()
+		if data.IPFamily.ValueString() == ipFamilyIPv6 {
+			addr = netip.IPv6Unspecified()
+		}
+		if maskLength > addr.BitLen() {
+			resp.Diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "Invalid CIDR mask length", fmt.Sprintf("CIDR mask length %d is longer than an IPv4 address.", maskLength))
+			return
+		}
+		prefix = netip.PrefixFrom(addr, maskLength)
+	}
+
+	// Configured values are kept as given, only the other forms are filled in.
+	if data.CIDRMaskLength.IsNull() {
+		data.CIDRMaskLength = types.Int64Value(int64(prefix.Bits()))
+	}
+	if data.Netmask.IsNull() {
+		data.Netmask = types.StringValue(subnet.Netmask(prefix).String())
+	}
+	if data.WildcardMask.IsNull() {
+		data.WildcardMask = types.StringValue(subnet.WildcardMask(prefix).String())
+	}
+	data.IPFamily = types.StringValue(ipFamilyIPv4)
+	if prefix.Addr().Is6() {
+		data.IPFamily = types.StringValue(ipFamilyIPv6)
+	}
+	data.ID = types.StringValue(strconv.Itoa(prefix.Bits()))
+
+	tflog.Trace(ctx, "read a netmask data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
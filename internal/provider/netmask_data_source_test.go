@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccNetmaskDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Netmask validation
+			{
+				Config: `
+				data "netcalc_netmask" "invalid" {
+					netmask = "255.0.255.0"
+				}`,
+				ExpectError: regexp.MustCompile(`not\s+contiguous`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_netmask" "length" {
+					cidr_mask_length = 20
+				}
+				data "netcalc_netmask" "netmask" {
+					netmask = "255.255.255.192"
+				}
+				data "netcalc_netmask" "wildcard" {
+					wildcard_mask = "0.0.0.255"
+				}
+				data "netcalc_netmask" "ipv6" {
+					cidr_mask_length = 64
+					ip_family        = "ipv6"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_netmask.length", "id", "20"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.length", "ip_family", "ipv4"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.length", "netmask", "255.255.240.0"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.length", "wildcard_mask", "0.0.15.255"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.netmask", "cidr_mask_length", "26"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.netmask", "wildcard_mask", "0.0.0.63"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.wildcard", "cidr_mask_length", "24"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.wildcard", "netmask", "255.255.255.0"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.ipv6", "netmask", "ffff:ffff:ffff:ffff::"),
+					resource.TestCheckResourceAttr("data.netcalc_netmask.ipv6", "wildcard_mask", "::ffff:ffff:ffff:ffff"),
+				),
+			},
+		},
+	})
+}
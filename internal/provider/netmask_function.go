@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CIDRNetmaskFunction{}
+var _ function.Function = &CIDRWildcardFunction{}
+
+func NewCIDRNetmaskFunction() function.Function {
+	return &CIDRNetmaskFunction{}
+}
+
+// CIDRNetmaskFunction defines the function implementation.
+type CIDRNetmaskFunction struct {
+}
+
+func (f *CIDRNetmaskFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrnetmask"
+}
+
+func (f *CIDRNetmaskFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the netmask of a CIDR block",
+		MarkdownDescription: "Returns the netmask of a CIDR block in address form, e.g. `255.255.255.0` for `10.0.1.0/24`. Unlike the built-in cidrnetmask function it also accepts IPv6 CIDR blocks, whose netmask is returned in IPv6 address form, e.g. `ffff:ffff:ffff:ffff::` for a /64.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "CIDR block, e.g. `10.0.1.0/24`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *CIDRNetmaskFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, subnet.Netmask(prefix).String()))
+}
+
+func NewCIDRWildcardFunction() function.Function {
+	return &CIDRWildcardFunction{}
+}
+
+// CIDRWildcardFunction defines the function implementation.
+type CIDRWildcardFunction struct {
+}
+
+func (f *CIDRWildcardFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidrwildcard"
+}
+
+func (f *CIDRWildcardFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the wildcard mask of a CIDR block",
+		MarkdownDescription: "Returns the inverse of the netmask of a CIDR block, e.g. `0.0.0.255` for `10.0.1.0/24`, as used by Cisco ACLs and OSPF network statements. IPv6 CIDR blocks are accepted too, e.g. `::ffff:ffff:ffff:ffff` for a /64.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "cidr_block",
+				MarkdownDescription: "CIDR block, e.g. `10.0.1.0/24`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *CIDRWildcardFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrBlock string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &cidrBlock))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, cidrBlock)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, subnet.WildcardMask(prefix).String()))
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCIDRNetmaskFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrnetmask("24")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4" {
+					value = provider::netcalc::cidrnetmask("10.0.1.0/24")
+				}
+				output "ipv4_builtin" {
+					value = provider::netcalc::cidrnetmask("10.0.1.0/19") == cidrnetmask("10.0.1.0/19")
+				}
+				output "ipv6" {
+					value = provider::netcalc::cidrnetmask("fd00::/64")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4", "255.255.255.0"),
+					resource.TestCheckOutput("ipv4_builtin", "true"),
+					resource.TestCheckOutput("ipv6", "ffff:ffff:ffff:ffff::"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCIDRWildcardFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::cidrwildcard("10.0.1.0/33")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4" {
+					value = provider::netcalc::cidrwildcard("10.0.1.0/24")
+				}
+				output "ipv4_host" {
+					value = provider::netcalc::cidrwildcard("10.0.1.4/32")
+				}
+				output "ipv6" {
+					value = provider::netcalc::cidrwildcard("fd00::/64")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4", "0.0.0.255"),
+					resource.TestCheckOutput("ipv4_host", "0.0.0.0"),
+					resource.TestCheckOutput("ipv6", "::ffff:ffff:ffff:ffff"),
+				),
+			},
+		},
+	})
+}
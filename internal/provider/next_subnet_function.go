@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &NextSubnetFunction{}
+
+func NewNextSubnetFunction() function.Function {
+	return &NextSubnetFunction{}
+}
+
+// NextSubnetFunction defines the function implementation.
+type NextSubnetFunction struct {
+}
+
+func (f *NextSubnetFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "nextsubnet"
+}
+
+func (f *NextSubnetFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the first free subnet of a pool",
+		MarkdownDescription: "Returns the first subnet with the given mask length in a pool that does not overlap any of the used CIDR blocks. Unlike the netcalc_subnet resource nothing is recorded, so keeping track of the used CIDR blocks is up to the caller. Fails if no such subnet is free.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "pool",
+				MarkdownDescription: "CIDR block to allocate from, e.g. `10.0.0.0/16`.",
+			},
+			function.ListParameter{
+				Name:                "used_cidr_blocks",
+				MarkdownDescription: "CIDR blocks that are already in use. CIDR blocks outside the pool or of the other IP family are ignored.",
+				ElementType:         types.StringType,
+			},
+			function.Int64Parameter{
+				Name:                "cidr_mask_length",
+				MarkdownDescription: "Mask length of the subnet, e.g. `24`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NextSubnetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var pool string
+	var usedCIDRBlocks []string
+	var maskLength int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &pool, &usedCIDRBlocks, &maskLength))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, pool)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	used, funcErr := parsePrefixListArgument(1, usedCIDRBlocks)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	if maskLength < int64(prefix.Bits()) || maskLength > int64(prefix.Addr().BitLen()) {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("Mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen()))
+		return
+	}
+
+	next, err := subnet.NextSubnet(prefix, used, int(maskLength))
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Unable to find a free subnet in %s: %v", prefix, err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, next.String()))
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccNextSubnetFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/16", ["10.0.0.0"], 24)
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Mask length validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/16", [], 8)
+				}`,
+				ExpectError: regexp.MustCompile(`Mask\s+length\s+/8\s+must\s+be\s+between\s+/16\s+and\s+/32`),
+			},
+			// Exhausted pool
+			{
+				Config: `
+				output "exhausted" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/24", ["10.0.0.0/25", "10.0.0.192/26"], 25)
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+find\s+a\s+free\s+subnet`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "empty" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/16", [], 24)
+				}
+				output "gap" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/16", ["10.0.0.0/24", "10.0.2.0/24", "10.1.0.0/16"], 24)
+				}
+				output "aligned" {
+					value = provider::netcalc::nextsubnet("10.0.0.0/16", ["10.0.0.0/24", "10.0.2.0/24"], 23)
+				}
+				output "ipv6" {
+					value = provider::netcalc::nextsubnet("fd00::/48", ["fd00::/64", "10.0.0.0/8"], 64)
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("empty", "10.0.0.0/24"),
+					resource.TestCheckOutput("gap", "10.0.1.0/24"),
+					resource.TestCheckOutput("aligned", "10.0.4.0/23"),
+					resource.TestCheckOutput("ipv6", "fd00:0:0:1::/64"),
+				),
+			},
+		},
+	})
+}
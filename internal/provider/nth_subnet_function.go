@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &NthSubnetFunction{}
+var _ function.Function = &SubnetIndexFunction{}
+
+func NewNthSubnetFunction() function.Function {
+	return &NthSubnetFunction{}
+}
+
+// NthSubnetFunction defines the function implementation.
+type NthSubnetFunction struct {
+}
+
+func (f *NthSubnetFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "nth_subnet"
+}
+
+func (f *NthSubnetFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns a subnet of a CIDR block by index",
+		MarkdownDescription: "Returns the subnet at an index among all subnets of the given mask length in a parent CIDR block, in address order, e.g. `10.0.5.0/24` for index 5 of the /24s in `10.0.0.0/16`. Unlike the built-in cidrsubnet function it takes the mask length rather than the number of added bits, and the index may exceed the range of 64-bit integers. Use subnet_index for the inverse.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "parent",
+				MarkdownDescription: "CIDR block to take the subnet from, e.g. `10.0.0.0/16`.",
+			},
+			function.Int64Parameter{
+				Name:                "cidr_mask_length",
+				MarkdownDescription: "Mask length of the subnet, e.g. `24`.",
+			},
+			function.NumberParameter{
+				Name:                "index",
+				MarkdownDescription: "Index of the subnet: 0 is the first subnet, and negative values count back from the last subnet. Must be a whole number.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NthSubnetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parent string
+	var maskLength int64
+	var index *big.Float
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &parent, &maskLength, &index))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, parent)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	if maskLength < int64(prefix.Bits()) || maskLength > int64(prefix.Addr().BitLen()) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen()))
+		return
+	}
+	if !index.IsInt() {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("Index must be a whole number, got: %s", index.Text('g', -1)))
+		return
+	}
+	i, _ := index.Int(nil)
+
+	nth, err := subnet.NthSubnet(prefix, int(maskLength), i)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("Unable to calculate subnet: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, nth.String()))
+}
+
+func NewSubnetIndexFunction() function.Function {
+	return &SubnetIndexFunction{}
+}
+
+// SubnetIndexFunction defines the function implementation.
+type SubnetIndexFunction struct {
+}
+
+func (f *SubnetIndexFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "subnet_index"
+}
+
+func (f *SubnetIndexFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the index of a subnet within a CIDR block",
+		MarkdownDescription: "Returns the index of a subnet among all subnets of the same size in a parent CIDR block, e.g. 5 for `10.0.5.0/24` in `10.0.0.0/16`. This is the inverse of nth_subnet. Fails if the subnet is not within the parent.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "parent",
+				MarkdownDescription: "CIDR block containing the subnet, e.g. `10.0.0.0/16`.",
+			},
+			function.StringParameter{
+				Name:                "subnet",
+				MarkdownDescription: "Subnet to find the index of, e.g. `10.0.5.0/24`.",
+			},
+		},
+		Return: function.NumberReturn{},
+	}
+}
+
+func (f *SubnetIndexFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parent, child string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &parent, &child))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, parent)
+	other, otherFuncErr := parsePrefixArgument(1, child)
+	resp.Error = function.ConcatFuncErrors(funcErr, otherFuncErr)
+	if resp.Error != nil {
+		return
+	}
+
+	index, err := subnet.SubnetIndex(prefix, other)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Unable to calculate subnet index: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, new(big.Float).SetInt(index)))
+}
@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccNthSubnetFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Mask length validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 8, 0)
+				}`,
+				ExpectError: regexp.MustCompile(`Mask\s+length\s+/8\s+must\s+be\s+between\s+/16\s+and\s+/32`),
+			},
+			// Index validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, 1.5)
+				}`,
+				ExpectError: regexp.MustCompile(`Index\s+must\s+be\s+a\s+whole\s+number`),
+			},
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, 256)
+				}`,
+				ExpectError: regexp.MustCompile(`subnet\s+index\s+256\s+is\s+outside\s+10.0.0.0/16`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "first" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, 0)
+				}
+				output "fifth" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, 5)
+				}
+				output "last" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 24, -1)
+				}
+				output "builtin" {
+					value = provider::netcalc::nth_subnet("10.0.0.0/16", 20, 3) == cidrsubnet("10.0.0.0/16", 4, 3)
+				}
+				output "ipv6_large" {
+					value = provider::netcalc::nth_subnet("fd00::/32", 128, 18446744073709551617)
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("first", "10.0.0.0/24"),
+					resource.TestCheckOutput("fifth", "10.0.5.0/24"),
+					resource.TestCheckOutput("last", "10.0.255.0/24"),
+					resource.TestCheckOutput("builtin", "true"),
+					resource.TestCheckOutput("ipv6_large", "fd00:0:0:1::1/128"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubnetIndexFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::subnet_index("10.0.0.0/16", "10.1.0.0/24")
+				}`,
+				ExpectError: regexp.MustCompile(`10.1.0.0/24\s+is\s+not\s+within\s+10.0.0.0/16`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "fifth" {
+					value = provider::netcalc::subnet_index("10.0.0.0/16", "10.0.5.0/24")
+				}
+				output "parent" {
+					value = provider::netcalc::subnet_index("10.0.0.0/16", "10.0.0.0/16")
+				}
+				output "round_trip" {
+					value = provider::netcalc::subnet_index("fd00::/48", provider::netcalc::nth_subnet("fd00::/48", 64, 300))
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("fifth", "5"),
+					resource.TestCheckOutput("parent", "0"),
+					resource.TestCheckOutput("round_trip", "300"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &OverlapDataSource{}
+
+func NewOverlapDataSource() datasource.DataSource {
+	return &OverlapDataSource{}
+}
+
+// OverlapDataSource defines the data source implementation.
+type OverlapDataSource struct {
+}
+
+// OverlapDataSourceModel describes the data source data model.
+type OverlapDataSourceModel struct {
+	CIDRLists   types.Map    `tfsdk:"cidr_lists"`
+	Overlaps    types.List   `tfsdk:"overlaps"`
+	HasOverlaps types.Bool   `tfsdk:"has_overlaps"`
+	ID          types.String `tfsdk:"id"`
+}
+
+// OverlapModel describes a pair of overlapping CIDR blocks.
+type OverlapModel struct {
+	CIDRBlock      types.String `tfsdk:"cidr_block"`
+	List           types.String `tfsdk:"list"`
+	OtherCIDRBlock types.String `tfsdk:"other_cidr_block"`
+	OtherList      types.String `tfsdk:"other_list"`
+}
+
+// listedPrefix is a CIDR block and the name of the list it came from.
+type listedPrefix struct {
+	cidr   string
+	prefix netip.Prefix
+	list   string
+}
+
+func (d *OverlapDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_overlap"
+}
+
+func (d *OverlapDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Overlap data source. Reports every pair of overlapping CIDR blocks across one or more named lists, e.g. to check that peered VPC ranges or merged plans are disjoint before applying them.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_lists": schema.MapAttribute{
+				ElementType:         types.ListType{ElemType: types.StringType},
+				MarkdownDescription: "Lists of CIDR blocks to check, keyed by a name that identifies the list in the overlaps. CIDR blocks within the same list are checked against each other too.",
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.ValueListsAre(listvalidator.ValueStringsAre(ipAddressValidator{})),
+				},
+			},
+			"overlaps": schema.ListNestedAttribute{
+				MarkdownDescription: "Overlapping pairs of CIDR blocks, ordered by list name and position within the list. Each pair is reported once.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"cidr_block": schema.StringAttribute{
+							MarkdownDescription: "First CIDR block of the pair.",
+							Computed:            true,
+						},
+						"list": schema.StringAttribute{
+							MarkdownDescription: "Name of the list the first CIDR block came from.",
+							Computed:            true,
+						},
+						"other_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "Second CIDR block of the pair.",
+							Computed:            true,
+						},
+						"other_list": schema.StringAttribute{
+							MarkdownDescription: "Name of the list the second CIDR block came from.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"has_overlaps": schema.BoolAttribute{
+				MarkdownDescription: "Whether any CIDR blocks overlap, for use in check blocks and preconditions.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, a placeholder required by the testing framework.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *OverlapDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data OverlapDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var lists map[string][]string
+	resp.Diagnostics.Append(data.CIDRLists.ElementsAs(ctx, &lists, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var prefixes []listedPrefix
+	for _, name := range sortedKeys(lists) {
+		for _, cidr := range lists[name] {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("cidr_lists").AtMapKey(name), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+				continue
+			}
+			prefixes = append(prefixes, listedPrefix{cidr: cidr, prefix: prefix.Masked(), list: name})
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	overlaps := []OverlapModel{}
+	for i, a := range prefixes {
+		for _, b := range prefixes[i+1:] {
+			if a.prefix.Overlaps(b.prefix) {
+				overlaps = append(overlaps, OverlapModel{
+					CIDRBlock:      types.StringValue(a.cidr),
+					List:           types.StringValue(a.list),
+					OtherCIDRBlock: types.StringValue(b.cidr),
+					OtherList:      types.StringValue(b.list),
+				})
+			}
+		}
+	}
+
+	overlapList, diags := types.ListValueFrom(ctx, data.Overlaps.ElementType(ctx), overlaps)
+	resp.Diagnostics.Append(diags...)
+	data.Overlaps = overlapList
+	data.HasOverlaps = types.BoolValue(len(overlaps) > 0)
+	data.ID = types.StringValue("overlap")
+
+	tflog.Trace(ctx, "read an overlap data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccOverlapDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_overlap" "test" {
+					cidr_lists = {
+						vpc_a = ["10.0.0.0/16", "10.1.0.0/16"]
+						vpc_b = ["10.1.128.0/17", "10.2.0.0/16", "10.2.0.0/24"]
+					}
+				}
+				data "netcalc_overlap" "disjoint" {
+					cidr_lists = {
+						vpc_a = ["10.0.0.0/16"]
+						vpc_b = ["10.1.0.0/16"]
+					}
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "has_overlaps", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.0.cidr_block", "10.1.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.0.list", "vpc_a"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.0.other_cidr_block", "10.1.128.0/17"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.0.other_list", "vpc_b"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.1.cidr_block", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.1.list", "vpc_b"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.1.other_cidr_block", "10.2.0.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.test", "overlaps.1.other_list", "vpc_b"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.disjoint", "has_overlaps", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_overlap.disjoint", "overlaps.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PoolLookupDataSource{}
+var _ datasource.DataSourceWithConfigure = &PoolLookupDataSource{}
+
+func NewPoolLookupDataSource() datasource.DataSource {
+	return &PoolLookupDataSource{}
+}
+
+// PoolLookupDataSource defines the data source implementation.
+type PoolLookupDataSource struct {
+	calculator SubnetCalculator
+	pools      map[string]namedPool
+}
+
+// PoolLookupDataSourceModel describes the data source data model.
+type PoolLookupDataSourceModel struct {
+	Address       types.String `tfsdk:"address"`
+	Pools         types.Map    `tfsdk:"pools"`
+	Found         types.Bool   `tfsdk:"found"`
+	PoolName      types.String `tfsdk:"pool_name"`
+	PoolID        types.String `tfsdk:"pool_id"`
+	PoolCIDRBlock types.String `tfsdk:"pool_cidr_block"`
+	Description   types.String `tfsdk:"description"`
+	Tags          types.Map    `tfsdk:"tags"`
+	ID            types.String `tfsdk:"id"`
+}
+
+// PoolLookupPoolModel describes a pool to search.
+type PoolLookupPoolModel struct {
+	ID          types.String `tfsdk:"id"`
+	Description types.String `tfsdk:"description"`
+	Tags        types.Map    `tfsdk:"tags"`
+}
+
+func (d *PoolLookupDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pool_lookup"
+}
+
+func (d *PoolLookupDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Pool lookup data source. Finds the pool containing an address or CIDR block, e.g. so a module can branch on the region of the address plan a subnet belongs to.",
+
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address or CIDR block to look up, e.g. `10.1.2.3` or `10.1.2.0/24`. A CIDR block must lie entirely within a pool CIDR block.",
+				Required:            true,
+			},
+			"pools": schema.MapNestedAttribute{
+				MarkdownDescription: "Pools to search, keyed by name, such as a map of netcalc_pool resources. When not set, the provider's pool_cidr_blocks, which have no name, description or tags, and the provider's named pools are searched. Reserved CIDR blocks are part of the pool they are reserved in.",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						// Optional rather than required, as required nested
+						// attributes are reported missing while the pool ID
+						// is unknown. Read checks that it is set.
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Pool ID. Required.",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the pool.",
+							Optional:            true,
+						},
+						"tags": schema.MapAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Tags describing the pool.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"found": schema.BoolAttribute{
+				MarkdownDescription: "Whether a pool contains the address. When the address is in several pools, such as a parent pool and a pool carved out of it, the pool with the most specific CIDR block is used, and pools with equally specific CIDR blocks are chosen by name.",
+				Computed:            true,
+			},
+			"pool_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the pool containing the address. Null if no pool contains it or it is in the provider's pool_cidr_blocks.",
+				Computed:            true,
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the pool containing the address. Null if no pool contains it.",
+				Computed:            true,
+			},
+			"pool_cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block of the pool that contains the address. Null if no pool contains it.",
+				Computed:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the pool containing the address.",
+				Computed:            true,
+			},
+			"tags": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Tags of the pool containing the address.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the address.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *PoolLookupDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+		d.pools = data.pools
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *PoolLookupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PoolLookupDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	address, err := parseAddressOrPrefix(data.Address.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("address"), "Address parsing error", fmt.Sprintf("Unable to parse address or CIDR: %q, %v", data.Address.ValueString(), err))
+		return
+	}
+
+	data.PoolName = types.StringNull()
+	data.PoolID = types.StringNull()
+	data.PoolCIDRBlock = types.StringNull()
+	data.Description = types.StringNull()
+	data.Tags = types.MapNull(types.StringType)
+	best := -1
+	if data.Pools.IsNull() {
+		for _, p := range append(d.calculator.Pools(false), d.calculator.Pools(true)...) {
+			if p.Bits() > best && prefixContains(p, address) {
+				best = p.Bits()
+				data.PoolID = types.StringValue(poolID([]netip.Prefix{p}, nil))
+				data.PoolCIDRBlock = types.StringValue(p.String())
+			}
+		}
+		for _, name := range sortedKeys(d.pools) {
+			pool := d.pools[name]
+			for _, p := range pool.cidrBlocks {
+				if p.Bits() > best && prefixContains(p, address) {
+					best = p.Bits()
+					data.PoolName = types.StringValue(name)
+					data.PoolID = types.StringValue(poolID(pool.cidrBlocks, nil))
+					data.PoolCIDRBlock = types.StringValue(p.String())
+					data.Description = pool.description
+					data.Tags = pool.tags
+				}
+			}
+		}
+	} else {
+		var pools map[string]PoolLookupPoolModel
+		resp.Diagnostics.Append(data.Pools.ElementsAs(ctx, &pools, false)...)
+		for _, name := range sortedKeys(pools) {
+			pool := pools[name]
+			if pool.ID.IsNull() {
+				resp.Diagnostics.AddAttributeError(path.Root("pools").AtMapKey(name).AtName("id"), "Missing pool ID", fmt.Sprintf("Pool %q has no ID.", name))
+				continue
+			}
+			prefixes, _, err := parsePoolID(pool.ID.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("pools").AtMapKey(name).AtName("id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", pool.ID.ValueString(), err))
+				continue
+			}
+			for _, p := range prefixes {
+				if p.Bits() > best && prefixContains(p, address) {
+					best = p.Bits()
+					data.PoolName = types.StringValue(name)
+					data.PoolID = pool.ID
+					data.PoolCIDRBlock = types.StringValue(p.String())
+					data.Description = pool.Description
+					data.Tags = pool.Tags
+				}
+			}
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Found = types.BoolValue(best >= 0)
+	data.ID = data.Address
+
+	tflog.Trace(ctx, "read a pool lookup data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// prefixContains reports whether a prefix contains all of another prefix.
+func prefixContains(prefix netip.Prefix, other netip.Prefix) bool {
+	return prefix.Bits() <= other.Bits() && prefix.Contains(other.Addr())
+}
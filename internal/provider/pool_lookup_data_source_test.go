@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPoolLookupDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd00::/48"]
+				}
+				resource "netcalc_pool" "corporate" {
+					cidr_blocks = ["10.0.0.0/8"]
+					description = "Corporate"
+				}
+				resource "netcalc_pool" "us" {
+					parent_pool_id = netcalc_pool.corporate.id
+					cidr_blocks    = ["10.1.0.0/16"]
+					tags           = { region = "us" }
+				}
+				resource "netcalc_pool" "eu" {
+					parent_pool_id = netcalc_pool.corporate.id
+					cidr_blocks    = ["10.2.0.0/16"]
+					tags           = { region = "eu" }
+				}
+				locals {
+					pools = {
+						corporate = netcalc_pool.corporate
+						us        = netcalc_pool.us
+						eu        = netcalc_pool.eu
+					}
+				}
+				data "netcalc_pool_lookup" "region" {
+					address = "10.2.3.0/24"
+					pools   = local.pools
+				}
+				data "netcalc_pool_lookup" "corporate" {
+					address = "10.3.0.1"
+					pools   = local.pools
+				}
+				data "netcalc_pool_lookup" "provider" {
+					address = "fd00::1"
+				}
+				data "netcalc_pool_lookup" "none" {
+					address = "192.168.0.1"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.region", "found", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.region", "pool_name", "eu"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.region", "pool_id", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.region", "pool_cidr_block", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.region", "tags.region", "eu"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.corporate", "pool_name", "corporate"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.corporate", "description", "Corporate"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.provider", "found", "true"),
+					resource.TestCheckNoResourceAttr("data.netcalc_pool_lookup.provider", "pool_name"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.provider", "pool_cidr_block", "fd00::/48"),
+					resource.TestCheckResourceAttr("data.netcalc_pool_lookup.none", "found", "false"),
+					resource.TestCheckNoResourceAttr("data.netcalc_pool_lookup.none", "pool_id"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Values of the overlapping_pool_cidr_blocks provider attribute.
+const (
+	poolOverlapMerge = "merge"
+	poolOverlapWarn  = "warn"
+	poolOverlapError = "error"
+)
+
+func poolOverlapAttribute() schema.Attribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "What to do about CIDR blocks of `pool_cidr_blocks`, `pool_cidr_blocks_file` and `aws_ipam_pool` that duplicate or overlap each other. One of `merge`, which keeps the larger of the overlapping CIDR blocks as the pool, `warn`, which merges them and reports a warning, and `error`, which fails the run. Defaults to `merge`. Adjacent CIDR blocks of the same size, such as `10.0.0.0/17` and `10.0.128.0/17`, are always merged into one pool so that subnets can span them, unless they have a priority in `pool_priorities`.",
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(poolOverlapMerge, poolOverlapWarn, poolOverlapError),
+		},
+	}
+}
+
+// poolOverlap returns the handling of overlapping pools configured by the
+// overlapping_pool_cidr_blocks provider attribute.
+func poolOverlap(mode types.String) subnet.PoolOverlap {
+	switch mode.ValueString() {
+	case poolOverlapWarn:
+		return subnet.PoolOverlapWarn
+	case poolOverlapError:
+		return subnet.PoolOverlapError
+	default:
+		return subnet.PoolOverlapMerge
+	}
+}
+
+// addPools adds the pools configured by a provider attribute to the
+// calculator, reporting invalid and overlapping pools.
+func addPools(calculator SubnetCalculator, prefixes []netip.Prefix, attribute path.Path, diagnostics *diag.Diagnostics) {
+	for _, prefix := range prefixes {
+		err := calculator.AddPool(prefix)
+		var overlap *subnet.OverlappingPoolError
+		switch {
+		case err == nil:
+		case errors.As(err, &overlap) && overlap.Merged:
+			diagnostics.AddAttributeWarning(attribute, "Overlapping pool CIDR blocks", fmt.Sprintf("The pool CIDR block %s overlaps %v, and they were merged. Remove the duplicates, or set overlapping_pool_cidr_blocks = \"merge\".", prefix, overlap.Overlapping))
+		case errors.As(err, &overlap):
+			diagnostics.AddAttributeError(attribute, "Overlapping pool CIDR blocks", fmt.Sprintf("The pool CIDR block %s overlaps %v. Remove the duplicates, or set overlapping_pool_cidr_blocks to \"merge\" or \"warn\".", prefix, overlap.Overlapping))
+		default:
+			diagnostics.AddAttributeError(attribute, "Invalid pool CIDR block", fmt.Sprintf("Unable to add pool: %v", err))
+		}
+	}
+}
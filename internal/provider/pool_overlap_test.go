@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderOverlappingPoolCIDRBlocks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderOverlappingPoolCIDRBlocksConfig("error", `["10.0.0.0/16", "10.0.0.0/24"]`),
+				ExpectError: regexp.MustCompile(`The\s+pool\s+CIDR\s+block\s+10.0.0.0/24\s+overlaps\s+\[10.0.0.0/16\]`),
+			},
+			// The smaller pool is merged into the larger one rather than
+			// replacing it.
+			{
+				Config: testAccProviderOverlappingPoolCIDRBlocksConfig("merge", `["10.0.0.0/16", "10.0.0.0/24"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/17"),
+			},
+			{
+				Config: testAccProviderOverlappingPoolCIDRBlocksConfig("warn", `["10.0.0.0/24", "10.0.0.0/16"]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/17"),
+			},
+		},
+	})
+}
+
+func testAccProviderOverlappingPoolCIDRBlocksConfig(mode string, pools string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks             = %[2]s
+  overlapping_pool_cidr_blocks = %[1]q
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 17
+}
+`, mode, pools)
+}
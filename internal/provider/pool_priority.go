@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func poolPrioritiesAttribute() schema.Attribute {
+	return schema.MapAttribute{
+		ElementType:         types.Int64Type,
+		MarkdownDescription: "Priorities of pool CIDR blocks, keyed by CIDR block, e.g. `{ \"10.1.0.0/16\" = 10 }`. Subnets are allocated from CIDR blocks with a higher priority first. CIDR blocks without a priority have priority `0`, and CIDR blocks of the same priority are used in canonical order: IPv4 before IPv6, then by address. Applies to `pool_cidr_blocks` and the CIDR blocks of named pools.",
+		Optional:            true,
+	}
+}
+
+// readPoolPriorities parses the pool_priorities provider attribute.
+func readPoolPriorities(ctx context.Context, data types.Map, diagnostics *diag.Diagnostics) map[netip.Prefix]int {
+	if data.IsNull() {
+		return nil
+	}
+	var values map[string]int64
+	diagnostics.Append(data.ElementsAs(ctx, &values, false)...)
+	priorities := map[netip.Prefix]int{}
+	for cidr, priority := range values {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("pool_priorities").AtMapKey(cidr), "Invalid pool CIDR block", fmt.Sprintf("Unable to parse CIDR block %q: %v", cidr, err))
+			continue
+		}
+		priorities[canonicalPrefix(prefix, diagnostics)] = int(priority)
+	}
+	return priorities
+}
+
+// checkPoolPriorities warns about priorities of CIDR blocks that are not
+// pools, which have no effect.
+func checkPoolPriorities(priorities map[netip.Prefix]int, pools []netip.Prefix, diagnostics *diag.Diagnostics) {
+	var unknown []netip.Prefix
+	for prefix := range priorities {
+		if !slices.Contains(pools, prefix) {
+			unknown = append(unknown, prefix)
+		}
+	}
+	for _, prefix := range sortPrefixes(unknown) {
+		diagnostics.AddAttributeWarning(path.Root("pool_priorities").AtMapKey(prefix.String()), "Priority of an unknown pool", fmt.Sprintf("%s is not one of the pool CIDR blocks, so its priority has no effect.", prefix))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderPoolPriorities(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderPoolPrioritiesConfig(`{ "10.1.0.0" = 1 }`, "first"),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR\s+block\s+"10.1.0.0"`),
+			},
+			// Without priorities, pools are used in address order rather
+			// than the order they are listed in.
+			{
+				Config: testAccProviderPoolPrioritiesConfig(`{}`, "first"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.default", "cidr_block", "10.0.0.0/26"),
+					resource.TestCheckResourceAttr("netcalc_subnet.named", "cidr_block", "10.2.0.0/26"),
+				),
+			},
+			// Priorities only affect new allocations, so the subnets are
+			// replaced to allocate them again.
+			{
+				Config: testAccProviderPoolPrioritiesConfig(`{ "10.1.0.0/24" = 1, "10.3.0.0/24" = 1 }`, "second"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.default", "cidr_block", "10.1.0.0/26"),
+					resource.TestCheckResourceAttr("netcalc_subnet.named", "cidr_block", "10.3.0.0/26"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderPoolPrioritiesConfig(priorities string, generation string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.1.0.0/24", "10.0.0.0/24"]
+  pool_priorities  = %[1]s
+
+  pools = {
+    named = {
+      cidr_blocks = ["10.3.0.0/24", "10.2.0.0/24"]
+    }
+  }
+}
+
+resource "terraform_data" "generation" {
+  input = %[2]q
+}
+
+resource "netcalc_subnet" "default" {
+  cidr_mask_length = 26
+
+  lifecycle {
+    replace_triggered_by = [terraform_data.generation]
+  }
+}
+
+resource "netcalc_subnet" "named" {
+  pool             = "named"
+  cidr_mask_length = 26
+
+  lifecycle {
+    replace_triggered_by = [terraform_data.generation]
+  }
+}
+`, priorities, generation)
+}
@@ -0,0 +1,591 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &PoolResource{}
+var _ resource.ResourceWithImportState = &PoolResource{}
+var _ resource.ResourceWithConfigure = &PoolResource{}
+var _ resource.ResourceWithValidateConfig = &PoolResource{}
+var _ resource.ResourceWithModifyPlan = &PoolResource{}
+
+func NewPoolResource() resource.Resource {
+	return &PoolResource{}
+}
+
+// PoolResource defines the resource implementation.
+type PoolResource struct {
+	calculator SubnetCalculator
+	ledger     ledger.Ledger
+	lock       *allocationLock
+	strategy   subnet.Strategy
+	debug      bool
+}
+
+// PoolResourceModel describes the resource data model.
+type PoolResourceModel struct {
+	CIDRBlocks         types.Set    `tfsdk:"cidr_blocks"`
+	ReservedCIDRBlocks types.Set    `tfsdk:"reserved_cidr_blocks"`
+	ParentPoolID       types.String `tfsdk:"parent_pool_id"`
+	CIDRMaskLength     types.Int64  `tfsdk:"cidr_mask_length"`
+	IPFamily           types.String `tfsdk:"ip_family"`
+	Strategy           types.String `tfsdk:"allocation_strategy"`
+	Description        types.String `tfsdk:"description"`
+	Tags               types.Map    `tfsdk:"tags"`
+	ID                 types.String `tfsdk:"id"`
+}
+
+func (r *PoolResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pool"
+}
+
+func (r *PoolResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Pool resource. Declares a pool of address space that other netcalc resources allocate from by referencing its ID. Pools can be carved out of a parent pool, e.g. a /16 per region out of a corporate /8, and subnets then out of the regional pools.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that form the pool. Exactly one of cidr_blocks or cidr_mask_length must be set; when cidr_mask_length is set, this is the CIDR block allocated from the parent pool.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(ipAddressValidator{}),
+					setvalidator.ExactlyOneOf(path.MatchRoot("cidr_mask_length")),
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"reserved_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks within the pool that must never be allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"parent_pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the netcalc_pool this pool is carved out of. The pool's CIDR blocks must lie within the parent pool, and are treated as allocated in it so that sibling pools and subnets allocated from the parent never overlap them. Changing the parent only causes a new allocation when the pool no longer fits in it. The CIDR blocks are recorded in the provider's ledger within the parent pool, so child pools created in earlier applies and by other Terraform states are protected as well.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(parentPoolNoLongerContainsCIDRs, "The pool's CIDR blocks no longer fall within the parent pool, the pool will be replaced.", ""),
+				},
+			},
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size in bits of a CIDR block to allocate from the parent pool, instead of listing cidr_blocks.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 128),
+					int64validator.AlsoRequires(path.MatchRoot("parent_pool_id")),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "The IP family of the CIDR block allocated from the parent pool. Must be one of ipv4 or ipv6, defaults to ipv4.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6),
+					stringvalidator.AlsoRequires(path.MatchRoot("cidr_mask_length")),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"allocation_strategy": schema.StringAttribute{
+				MarkdownDescription: "Strategy for choosing where in the parent pool the CIDR block is allocated, overriding the provider's allocation_strategy. " + allocationStrategyDescription,
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(allocationStrategyNames()...),
+					stringvalidator.AlsoRequires(path.MatchRoot("cidr_mask_length")),
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the pool.",
+				Optional:            true,
+			},
+			"tags": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Tags describing the pool.",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Pool ID, made up of the pool's CIDR blocks followed by its reserved CIDR blocks prefixed with `!`.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *PoolResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data PoolResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || !setKnown(data.CIDRBlocks) || !setKnown(data.ReservedCIDRBlocks) {
+		return
+	}
+
+	pools := parsePrefixSet(ctx, data.CIDRBlocks, &resp.Diagnostics)
+	reserved := parsePrefixSet(ctx, data.ReservedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for i, a := range pools {
+		for _, b := range pools[i+1:] {
+			if a.Overlaps(b) {
+				resp.Diagnostics.AddAttributeError(path.Root("cidr_blocks"), "Overlapping pool CIDR blocks", fmt.Sprintf("CIDR blocks %s and %s overlap.", a, b))
+			}
+		}
+	}
+	// A pool allocated from its parent has no CIDR blocks until it is created.
+	if data.CIDRBlocks.IsNull() {
+		return
+	}
+	for _, p := range reserved {
+		if !prefixInAny(p, pools) {
+			resp.Diagnostics.AddAttributeError(path.Root("reserved_cidr_blocks"), "Reserved CIDR block outside pool", fmt.Sprintf("Reserved CIDR block %s is not within any of the pool's CIDR blocks.", p))
+		}
+	}
+}
+
+// ModifyPlan plans the pool ID from its CIDR blocks, so that resources
+// allocating from the pool know its extent when they are planned.
+func (r *PoolResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan PoolResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !setKnown(plan.CIDRBlocks) || !setKnown(plan.ReservedCIDRBlocks) {
+		return
+	}
+
+	pools := parsePrefixSet(ctx, plan.CIDRBlocks, &resp.Diagnostics)
+	reserved := parsePrefixSet(ctx, plan.ReservedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() || len(pools) == 0 {
+		return
+	}
+	if !plan.ParentPoolID.IsNull() && !plan.ParentPoolID.IsUnknown() {
+		resp.Diagnostics.Append(checkParentPool(plan.ParentPoolID, pools)...)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), poolID(pools, reserved))...)
+}
+
+func (r *PoolResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.strategy = data.strategy
+		r.debug = data.debug
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *PoolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PoolResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.ParentPoolID.IsNull() {
+		unlock, diags := r.lock.acquire(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+		owner, err := newAllocationOwner()
+		if err != nil {
+			resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+			return
+		}
+		resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+		resp.Diagnostics.Append(r.allocateFromParent(ctx, &data, owner, nil)...)
+	}
+	pools := parsePrefixSet(ctx, data.CIDRBlocks, &resp.Diagnostics)
+	reserved := parsePrefixSet(ctx, data.ReservedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(poolID(pools, reserved))
+
+	tflog.Info(ctx, "created a pool resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PoolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PoolResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if another system has claimed the pool's CIDR blocks in the ledger.
+	if r.ledger != nil && !data.ParentPoolID.IsNull() {
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		pools := parsePrefixSet(ctx, data.CIDRBlocks, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, p := range pools {
+			ledgerOwner, ok, err := ledger.Owner(ctx, r.ledger, p)
+			if err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to look up allocation of %s: %v", p, err))
+				return
+			}
+			// Imported pools have no owner, so they cannot be checked.
+			if owner != "" && ok && ledgerOwner != owner {
+				resp.Diagnostics.AddWarning(
+					"Pool CIDR block claimed elsewhere",
+					fmt.Sprintf("The CIDR block %s is registered to %q in the ledger. The pool will be claimed in its parent pool again.", p, ledgerOwner),
+				)
+				resp.State.RemoveResource(ctx)
+				return
+			}
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PoolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan PoolResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state PoolResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Move the claim on the parent pool to the new CIDR blocks. Allocating a
+	// new CIDR block replaces the pool, so the CIDR blocks here are known.
+	// CIDR blocks the pool keeps stay claimed, as subnets may have been
+	// allocated from them.
+	if !plan.CIDRBlocks.Equal(state.CIDRBlocks) || !plan.ParentPoolID.Equal(state.ParentPoolID) {
+		unlock, diags := r.lock.acquire(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		var kept, released []netip.Prefix
+		if !state.ParentPoolID.IsNull() {
+			var pools []netip.Prefix
+			if !plan.ParentPoolID.IsNull() {
+				pools = parsePrefixSet(ctx, plan.CIDRBlocks, &resp.Diagnostics)
+			}
+			for _, p := range parsePrefixSet(ctx, state.CIDRBlocks, &resp.Diagnostics) {
+				if slices.Contains(pools, p) {
+					kept = append(kept, p)
+				} else {
+					released = append(released, p)
+				}
+			}
+			resp.Diagnostics.Append(r.releaseFromParent(ctx, released, owner)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		if !plan.ParentPoolID.IsNull() {
+			// Imported pools have no owner yet.
+			if owner == "" {
+				var err error
+				if owner, err = newAllocationOwner(); err != nil {
+					resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+					return
+				}
+				resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+			}
+			resp.Diagnostics.Append(r.allocateFromParent(ctx, &plan, owner, kept)...)
+		}
+	}
+
+	pools := parsePrefixSet(ctx, plan.CIDRBlocks, &resp.Diagnostics)
+	reserved := parsePrefixSet(ctx, plan.ReservedCIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.ID = types.StringValue(poolID(pools, reserved))
+	tflog.Info(ctx, "updated a pool resource")
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *PoolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PoolResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Return the CIDR blocks to the parent pool.
+	if !data.ParentPoolID.IsNull() {
+		unlock, diags := r.lock.acquire(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		pools := parsePrefixSet(ctx, data.CIDRBlocks, &resp.Diagnostics)
+		resp.Diagnostics.Append(r.releaseFromParent(ctx, pools, owner)...)
+	}
+	tflog.Info(ctx, "deleted a pool resource")
+}
+
+func (r *PoolResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	pools, reserved, err := parsePoolID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Unable to parse pool ID %q: %v", req.ID, err))
+		return
+	}
+
+	cidrBlocks, diagnostics := types.SetValueFrom(ctx, types.StringType, prefixStrings(pools))
+	resp.Diagnostics.Append(diagnostics...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_blocks"), cidrBlocks)...)
+	if len(reserved) > 0 {
+		reservedCIDRBlocks, diagnostics := types.SetValueFrom(ctx, types.StringType, prefixStrings(reserved))
+		resp.Diagnostics.Append(diagnostics...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("reserved_cidr_blocks"), reservedCIDRBlocks)...)
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	tflog.Info(ctx, "imported a pool resource")
+}
+
+// allocateFromParent allocates the pool's CIDR block from its parent pool when
+// it is not known yet, or otherwise claims its CIDR blocks in the parent,
+// except for those that are already claimed. The newly claimed CIDR blocks are
+// recorded in the ledger under owner, within the parent pool.
+func (r *PoolResource) allocateFromParent(ctx context.Context, data *PoolResourceModel, owner string, claimedBefore []netip.Prefix) (diagnostics diag.Diagnostics) {
+	parents, parentReserved, err := parsePoolID(data.ParentPoolID.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("parent_pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", data.ParentPoolID.ValueString(), err))
+		return diagnostics
+	}
+
+	if data.CIDRBlocks.IsUnknown() {
+		var familyPools []netip.Prefix
+		for _, p := range parents {
+			if p.Addr().Is6() == (data.IPFamily.ValueString() == ipFamilyIPv6) {
+				familyPools = append(familyPools, p)
+			}
+		}
+		next, err := tracedCalculator(ctx, r.calculator, r.debug).NextAvailableSubnetInPools(familyPools, parentReserved, int(data.CIDRMaskLength.ValueInt64()), allocationStrategy(data.Strategy, r.strategy))
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "CIDR calculation error", fmt.Sprintf("Unable to calculate next available CIDR in the parent pool: %v", err))
+			return diagnostics
+		}
+		diagnostics.Append(recordAllocations(ctx, r.ledger, owner, parents, next)...)
+		if diagnostics.HasError() {
+			r.calculator.DeleteAllocatedPrefix(next)
+			return diagnostics
+		}
+		cidrBlocks, diags := types.SetValueFrom(ctx, types.StringType, []string{next.String()})
+		diagnostics.Append(diags...)
+		data.CIDRBlocks = cidrBlocks
+		return diagnostics
+	}
+
+	pools := parsePrefixSet(ctx, data.CIDRBlocks, &diagnostics)
+	diagnostics.Append(checkParentPool(data.ParentPoolID, pools)...)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+	var claimed []netip.Prefix
+	for _, p := range pools {
+		if slices.Contains(claimedBefore, p) {
+			continue
+		}
+		if err := r.calculator.ClaimPrefix(p, nil); err != nil {
+			diagnostics.AddAttributeError(path.Root("cidr_blocks"), "CIDR block already allocated", fmt.Sprintf("Unable to claim CIDR block in the parent pool: %v", err))
+			// Release the CIDR blocks that were claimed so a failed pool
+			// claims nothing.
+			for _, c := range claimed {
+				r.calculator.DeleteAllocatedPrefix(c)
+			}
+			return diagnostics
+		}
+		claimed = append(claimed, p)
+	}
+	diagnostics.Append(recordAllocations(ctx, r.ledger, owner, parents, claimed...)...)
+	if diagnostics.HasError() {
+		for _, c := range claimed {
+			r.calculator.DeleteAllocatedPrefix(c)
+		}
+	}
+	return diagnostics
+}
+
+// releaseFromParent returns CIDR blocks of a pool to its parent pool and
+// records their release in the ledger.
+func (r *PoolResource) releaseFromParent(ctx context.Context, pools []netip.Prefix, owner string) (diagnostics diag.Diagnostics) {
+	for _, p := range pools {
+		r.calculator.ReleasePrefix(p)
+		if r.ledger == nil {
+			continue
+		}
+		if err := r.ledger.Release(ctx, p, owner); err != nil {
+			diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", p, err))
+			return diagnostics
+		}
+	}
+	return diagnostics
+}
+
+// checkParentPool checks that all of a pool's CIDR blocks lie within its
+// parent pool.
+func checkParentPool(parentPoolID types.String, pools []netip.Prefix) (diagnostics diag.Diagnostics) {
+	parents, parentReserved, err := parsePoolID(parentPoolID.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("parent_pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", parentPoolID.ValueString(), err))
+		return diagnostics
+	}
+	for _, p := range pools {
+		if !prefixInPool(p, parents, parentReserved) {
+			diagnostics.AddAttributeError(path.Root("cidr_blocks"), "CIDR block outside parent pool", fmt.Sprintf("CIDR block %s does not lie within the parent pool or overlaps one of its reserved CIDR blocks.", p))
+		}
+	}
+	return diagnostics
+}
+
+// parentPoolNoLongerContainsCIDRs requires replacement when a pool's CIDR
+// blocks no longer fit in its newly planned parent pool.
+func parentPoolNoLongerContainsCIDRs(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	if req.PlanValue.IsNull() {
+		return
+	}
+
+	var cidrBlocks types.Set
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cidr_blocks"), &cidrBlocks)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, elem := range cidrBlocks.Elements() {
+		cidrBlock, ok := elem.(types.String)
+		if !ok || !poolContainsCIDR(req.PlanValue, cidrBlock) {
+			resp.RequiresReplace = true
+			return
+		}
+	}
+}
+
+// poolID encodes a pool's CIDR blocks and reserved CIDR blocks in canonical
+// order, e.g. "10.0.0.0/16,10.1.0.0/16,!10.0.0.0/24".
+func poolID(pools []netip.Prefix, reserved []netip.Prefix) string {
+	var parts []string
+	for _, p := range sortPrefixes(pools) {
+		parts = append(parts, p.String())
+	}
+	for _, p := range sortPrefixes(reserved) {
+		parts = append(parts, "!"+p.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// parsePoolID decodes a pool ID created by poolID.
+func parsePoolID(id string) (pools []netip.Prefix, reserved []netip.Prefix, err error) {
+	for _, part := range strings.Split(id, ",") {
+		cidr := strings.TrimPrefix(part, "!")
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cidr != part {
+			reserved = append(reserved, p)
+		} else {
+			pools = append(pools, p)
+		}
+	}
+	if len(pools) == 0 {
+		return nil, nil, fmt.Errorf("pool ID must contain at least one CIDR block")
+	}
+	return pools, reserved, nil
+}
+
+// prefixInAny reports whether a prefix is entirely contained in any of the
+// given prefixes.
+func prefixInAny(prefix netip.Prefix, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Bits() <= prefix.Bits() && p.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortPrefixes returns a copy of prefixes sorted by family, address and mask length.
+func sortPrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	sorted := append([]netip.Prefix(nil), prefixes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if c := sorted[i].Addr().Compare(sorted[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Bits() < sorted[j].Bits()
+	})
+	return sorted
+}
@@ -0,0 +1,318 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccPoolResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Reserved CIDR blocks must be within the pool
+			{
+				Config: `
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16"]
+					reserved_cidr_blocks = ["10.1.0.0/24"]
+				}`,
+				ExpectError: regexp.MustCompile(`Reserved CIDR block outside pool`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+					description          = "Test pool"
+					tags = {
+						environment = "test"
+					}
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "ipv6" {
+					pool_id          = netcalc_pool.test.id
+					ip_family        = "ipv6"
+					cidr_mask_length = 64
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.test", "id", "10.0.0.0/16,fd18:fad4:bce5:4400::/56,!10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_block", "fd18:fad4:bce5:4400::/64"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "netcalc_pool.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"description", "tags"},
+			},
+			// Growing the pool does not cause recalculation
+			{
+				Config: `
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16", "10.1.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+					description          = "Grown test pool"
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "ipv6" {
+					pool_id          = netcalc_pool.test.id
+					ip_family        = "ipv6"
+					cidr_mask_length = 64
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.test", "id", "10.0.0.0/16,10.1.0.0/16,fd18:fad4:bce5:4400::/56,!10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_block", "fd18:fad4:bce5:4400::/64"),
+				),
+			},
+			// Reserving an allocated CIDR block causes recalculation
+			{
+				Config: `
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16", "10.1.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					reserved_cidr_blocks = ["10.0.0.0/23"]
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "ipv6" {
+					pool_id          = netcalc_pool.test.id
+					ip_family        = "ipv6"
+					cidr_mask_length = 64
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet.test", plancheck.ResourceActionDestroyBeforeCreate),
+						plancheck.ExpectResourceAction("netcalc_subnet.ipv6", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_block", "fd18:fad4:bce5:4400::/64"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPoolResourceParentPool(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Child pools must lie within their parent pool
+			{
+				Config: `
+				resource "netcalc_pool" "corporate" {
+					cidr_blocks = ["10.0.0.0/8"]
+				}
+				resource "netcalc_pool" "test" {
+					parent_pool_id = netcalc_pool.corporate.id
+					cidr_blocks    = ["172.16.0.0/16"]
+				}`,
+				ExpectError: regexp.MustCompile(`CIDR block outside parent pool`),
+			},
+			// Child pools must not overlap each other
+			{
+				Config: `
+				resource "netcalc_pool" "corporate" {
+					cidr_blocks = ["10.0.0.0/8"]
+				}
+				resource "netcalc_pool" "a" {
+					parent_pool_id = netcalc_pool.corporate.id
+					cidr_blocks    = ["10.0.0.0/16"]
+				}
+				resource "netcalc_pool" "b" {
+					parent_pool_id = netcalc_pool.corporate.id
+					cidr_blocks    = ["10.0.0.0/17"]
+				}`,
+				ExpectError: regexp.MustCompile(`overlaps allocated\s+CIDR block`),
+			},
+			// Create and Read testing, regional pools are allocated from the
+			// corporate pool and subnets from the regional pools
+			{
+				Config: `
+				resource "netcalc_pool" "corporate" {
+					cidr_blocks          = ["10.0.0.0/8"]
+					reserved_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_pool" "us_east" {
+					parent_pool_id   = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+				}
+				resource "netcalc_pool" "us_west" {
+					parent_pool_id   = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+					depends_on       = [netcalc_pool.us_east]
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = netcalc_pool.us_west.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "corporate" {
+					pool_id          = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+					depends_on       = [netcalc_pool.us_east, netcalc_pool.us_west]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.us_east", "id", "10.1.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_pool.us_east", "cidr_blocks.0", "10.1.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_pool.us_west", "id", "10.2.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.2.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.corporate", "cidr_block", "10.3.0.0/16"),
+				),
+			},
+			// Growing the parent pool keeps the child pools
+			{
+				Config: `
+				resource "netcalc_pool" "corporate" {
+					cidr_blocks          = ["10.0.0.0/8", "172.16.0.0/12"]
+					reserved_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_pool" "us_east" {
+					parent_pool_id   = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+				}
+				resource "netcalc_pool" "us_west" {
+					parent_pool_id   = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+					depends_on       = [netcalc_pool.us_east]
+				}
+				resource "netcalc_subnet" "test" {
+					pool_id          = netcalc_pool.us_west.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "corporate" {
+					pool_id          = netcalc_pool.corporate.id
+					cidr_mask_length = 16
+					depends_on       = [netcalc_pool.us_east, netcalc_pool.us_west]
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_pool.us_east", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("netcalc_pool.us_west", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("netcalc_subnet.test", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.us_east", "id", "10.1.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_pool.us_west", "id", "10.2.0.0/16"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccPoolResourceParentPoolLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	l := ledger.NewFileLedger(ledgerPath)
+	// A regional pool was carved out of the corporate pool by another
+	// Terraform state.
+	if err := l.Allocate(context.Background(), netip.MustParsePrefix("10.1.0.0/16"), "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccPoolResourceParentPoolLedgerConfig(ledgerPath, `["10.0.0.0/8"]`, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.us_west", "id", "10.0.0.0/16"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					testAccCheckLedgerActive(l, "10.1.0.0/16", "10.0.0.0/16", "10.0.0.0/24"),
+				),
+			},
+			// Growing the parent pool keeps the child pool and its subnet
+			{
+				Config: testAccPoolResourceParentPoolLedgerConfig(ledgerPath, `["10.0.0.0/8", "172.16.0.0/12"]`, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.us_west", "id", "10.0.0.0/16"),
+					testAccCheckLedgerActive(l, "10.1.0.0/16", "10.0.0.0/16", "10.0.0.0/24"),
+				),
+			},
+			// Deleting the subnet releases it in the ledger
+			{
+				Config: testAccPoolResourceParentPoolLedgerConfig(ledgerPath, `["10.0.0.0/8", "172.16.0.0/12"]`, false),
+				Check:  testAccCheckLedgerActive(l, "10.1.0.0/16", "10.0.0.0/16"),
+			},
+			// A child pool claimed elsewhere is allocated again
+			{
+				PreConfig: func() {
+					prefix := netip.MustParsePrefix("10.0.0.0/16")
+					owner, _, err := ledger.Owner(context.Background(), l, prefix)
+					if err == nil {
+						err = l.Release(context.Background(), prefix, owner)
+					}
+					if err == nil {
+						err = l.Allocate(context.Background(), prefix, "intruder")
+					}
+					if err != nil {
+						t.Fatalf("unable to reassign %s in the ledger: %v", prefix, err)
+					}
+				},
+				Config: testAccPoolResourceParentPoolLedgerConfig(ledgerPath, `["10.0.0.0/8", "172.16.0.0/12"]`, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_pool.us_west", "id", "10.2.0.0/16"),
+					testAccCheckLedgerActive(l, "10.1.0.0/16", "10.0.0.0/16", "10.2.0.0/16"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPoolResourceParentPoolLedgerConfig(ledgerPath, corporate string, withSubnet bool) string {
+	config := fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %[1]q
+}
+
+resource "netcalc_pool" "corporate" {
+  cidr_blocks = %[2]s
+}
+
+resource "netcalc_pool" "us_west" {
+  parent_pool_id   = netcalc_pool.corporate.id
+  cidr_mask_length = 16
+}
+`, ledgerPath, corporate)
+	if withSubnet {
+		config += `
+resource "netcalc_subnet" "test" {
+  pool_id          = netcalc_pool.us_west.id
+  cidr_mask_length = 24
+}
+`
+	}
+	return config
+}
@@ -6,24 +6,37 @@ package provider
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/netip"
+	"os"
+	"slices"
+	"strings"
 	"sync"
+	"unicode"
 
+	"github.com/geezyx/subnet-calculator/internal/ledger"
 	"github.com/geezyx/subnet-calculator/internal/subnet"
 	"github.com/hashicorp/terraform-plugin-framework-validators/helpers/validatordiag"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure NetcalcProvider satisfies various provider interfaces.
 var _ provider.Provider = &NetcalcProvider{}
+var _ provider.ProviderWithFunctions = &NetcalcProvider{}
 
 // NetcalcProvider defines the provider implementation.
 type NetcalcProvider struct {
@@ -36,18 +49,121 @@ type NetcalcProvider struct {
 }
 
 type SubnetCalculator interface {
-	AddPool(prefix netip.Prefix)
-	AddAllocatedPrefix(prefix netip.Prefix)
-	NextAvailableIPv4Subnet(numBits int) (netip.Prefix, error)
-	NextAvailableIPv6Subnet(numBits int) (netip.Prefix, error)
+	AddPool(prefix netip.Prefix) error
+	AddAllocatedPrefix(prefix netip.Prefix) error
+	// HoldPrefix marks a prefix as allocated without checking it against
+	// the pools, such as one a resource allocated before.
+	HoldPrefix(prefix netip.Prefix)
+	AddReservedPrefix(prefix netip.Prefix)
+	NextAvailableIPv4Subnet(ctx context.Context, numBits int) (netip.Prefix, error)
+	NextAvailableIPv6Subnet(ctx context.Context, numBits int) (netip.Prefix, error)
+	NextAvailableSubnet(ctx context.Context, ipv6 bool, numBits int, strategy subnet.Strategy) (netip.Prefix, error)
+	NextAvailableSubnetInPools(pools []netip.Prefix, reserved []netip.Prefix, numBits int, strategy subnet.Strategy) (netip.Prefix, error)
 	DeleteAllocatedPrefix(prefix netip.Prefix)
+	// ReleasePrefix returns a prefix given up by a resource to the pools,
+	// unless released prefixes are held until the end of the run.
+	ReleasePrefix(prefix netip.Prefix)
+	// ReleasedPrefixes returns the prefixes released and held during the
+	// run.
+	ReleasedPrefixes() []netip.Prefix
 	PrefixInPools(prefix netip.Prefix) bool
+	ClaimPrefix(prefix netip.Prefix, covered []netip.Prefix) error
+	NextAvailableRange(prefix netip.Prefix, count uint64) (subnet.AddressRange, error)
+	DeleteAllocatedRange(r subnet.AddressRange)
+	// ReleaseRange returns an address range given up by a resource, unless
+	// released prefixes are held until the end of the run.
+	ReleaseRange(r subnet.AddressRange)
+	ClaimRange(r subnet.AddressRange) error
+	Pools(ipv6 bool) []netip.Prefix
+	// PoolPriorities returns the priorities of pools, keyed by pool.
+	PoolPriorities() map[netip.Prefix]int
+	ReservedPrefixes() []netip.Prefix
+	AllocatedPrefixes(ipv6 bool) []netip.Prefix
+	FreePrefixesInPool(pool netip.Prefix, used []netip.Prefix) []netip.Prefix
+	// Traced returns the calculator, reporting the decisions of its
+	// allocations to a tracer.
+	Traced(tracer subnet.Tracer) SubnetCalculator
 }
 
+// netcalcProviderData is shared with resources through Configure.
+type netcalcProviderData struct {
+	calculator SubnetCalculator
+	// ledger records allocations outside of Terraform state. It is nil when
+	// no ledger is configured.
+	ledger ledger.Ledger
+	// lock serializes allocations across Terraform runs. It is nil when no
+	// lock is configured.
+	lock *allocationLock
+	// pools are the named pools, keyed by name.
+	pools map[string]namedPool
+	// strategy is the allocation strategy of resources that do not set one.
+	strategy subnet.Strategy
+	// debug logs every candidate considered while allocating a subnet.
+	debug bool
+	// webhook is notified of allocations and releases. It is nil when no
+	// webhook is configured.
+	webhook *webhook
+	// ssmParameters publishes allocations to AWS Systems Manager Parameter
+	// Store. It is nil when it is not configured.
+	ssmParameters *ssmParameters
+	// maskPolicy limits the mask lengths of subnets.
+	maskPolicy maskLengthPolicy
+	// utilization warns about pools filling up. It is nil when no threshold
+	// is configured.
+	utilization *utilizationWarning
+	// maxCIDRCount is the largest cidr_count of netcalc_subnets resources.
+	maxCIDRCount int64
+	// claimedOutsidePools is what happens to claimed CIDR blocks outside
+	// the pools.
+	claimedOutsidePools subnet.AllocationOutsidePools
+}
+
+// defaultMaxCIDRCount is the largest cidr_count of netcalc_subnets resources
+// unless the provider sets max_cidr_count.
+const defaultMaxCIDRCount = 1024
+
 // SubnetCalculatorProviderModel describes the provider data model.
 type SubnetCalculatorProviderModel struct {
-	PoolCIDRBlocks    types.List `tfsdk:"pool_cidr_blocks"`
-	ClaimedCIDRBlocks types.List `tfsdk:"claimed_cidr_blocks"`
+	PoolCIDRBlocks        types.List    `tfsdk:"pool_cidr_blocks"`
+	Pools                 types.Map     `tfsdk:"pools"`
+	ClaimedCIDRBlocks     types.List    `tfsdk:"claimed_cidr_blocks"`
+	ClaimedOutsidePools   types.String  `tfsdk:"claimed_cidr_blocks_outside_pools"`
+	ReservedCIDRBlocks    types.List    `tfsdk:"reserved_cidr_blocks"`
+	AllocationStrategy    types.String  `tfsdk:"allocation_strategy"`
+	MinCIDRMaskLength     types.Object  `tfsdk:"min_cidr_mask_length"`
+	MaxCIDRMaskLength     types.Object  `tfsdk:"max_cidr_mask_length"`
+	Debug                 types.Bool    `tfsdk:"debug"`
+	HoldReleasedCIDRs     types.Bool    `tfsdk:"hold_released_cidr_blocks"`
+	WarnUtilization       types.Float64 `tfsdk:"warn_utilization_percent"`
+	PoolOverlap           types.String  `tfsdk:"overlapping_pool_cidr_blocks"`
+	MaxCIDRCount          types.Int64   `tfsdk:"max_cidr_count"`
+	PoolPriorities        types.Map     `tfsdk:"pool_priorities"`
+	SharedLedger          types.Bool    `tfsdk:"shared_ledger"`
+	PoolCIDRBlocksFile    types.String  `tfsdk:"pool_cidr_blocks_file"`
+	ClaimedCIDRBlocksFile types.String  `tfsdk:"claimed_cidr_blocks_file"`
+	LedgerPath            types.String  `tfsdk:"ledger_path"`
+	LedgerS3              types.Object  `tfsdk:"ledger_s3"`
+	LedgerConsul          types.Object  `tfsdk:"ledger_consul"`
+	LedgerEtcd            types.Object  `tfsdk:"ledger_etcd"`
+	LedgerInfoblox        types.Object  `tfsdk:"ledger_infoblox"`
+	LedgerHTTP            types.Object  `tfsdk:"ledger_http"`
+	LedgerVault           types.Object  `tfsdk:"ledger_vault"`
+	LedgerGit             types.Object  `tfsdk:"ledger_git"`
+	LedgerKubernetes      types.Object  `tfsdk:"ledger_kubernetes"`
+	LedgerAzureBlob       types.Object  `tfsdk:"ledger_azure_blob"`
+	LedgerGCS             types.Object  `tfsdk:"ledger_gcs"`
+	LedgerPostgres        types.Object  `tfsdk:"ledger_postgres"`
+	LedgerRedis           types.Object  `tfsdk:"ledger_redis"`
+	LockDynamoDB          types.Object  `tfsdk:"lock_dynamodb"`
+	LockConsul            types.Object  `tfsdk:"lock_consul"`
+	LockAzureBlob         types.Object  `tfsdk:"lock_azure_blob"`
+	Webhook               types.Object  `tfsdk:"webhook"`
+	SSMParameters         types.Object  `tfsdk:"ssm_parameters"`
+	AWSDiscovery          types.Object  `tfsdk:"aws_discovery"`
+	RemoteStates          types.List    `tfsdk:"remote_states"`
+	AzureDiscovery        types.Object  `tfsdk:"azure_discovery"`
+	GCPDiscovery          types.Object  `tfsdk:"gcp_discovery"`
+	AWSIPAMPool           types.Object  `tfsdk:"aws_ipam_pool"`
 }
 
 func (p *NetcalcProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -61,15 +177,81 @@ func (p *NetcalcProvider) Schema(ctx context.Context, req provider.SchemaRequest
 			"pool_cidr_blocks": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that form a collective pool to be allocated in this provider.",
+				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that form a collective pool to be allocated in this provider. Defaults to the CIDR blocks in the `NETCALC_POOL_CIDR_BLOCKS` environment variable, separated by commas or whitespace.",
 				Validators:          []validator.List{listvalidator.ValueStringsAre(ipAddressValidator{})},
 			},
+			"pools":           namedPoolsAttribute(),
+			"pool_priorities": poolPrioritiesAttribute(),
 			"claimed_cidr_blocks": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that are already claimed by other resources.",
+				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that are already claimed by other resources. Defaults to the CIDR blocks in the `NETCALC_CLAIMED_CIDR_BLOCKS` environment variable, separated by commas or whitespace.",
+				Validators:          []validator.List{listvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"claimed_cidr_blocks_outside_pools": claimedOutsidePoolsAttribute(),
+			"reserved_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "IPv4 and/or IPv6 CIDR blocks that must never be allocated, such as anycast ranges or ranges used by legacy equipment. Unlike claimed CIDR blocks, which record existing usage, reserved CIDR blocks apply to every pool, including netcalc_pool resources carved out of them, and subnets of `pool_cidr_blocks` that overlap them are reallocated. Defaults to the CIDR blocks in the `NETCALC_RESERVED_CIDR_BLOCKS` environment variable, separated by commas or whitespace.",
 				Validators:          []validator.List{listvalidator.ValueStringsAre(ipAddressValidator{})},
 			},
+			"allocation_strategy": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default strategy for choosing where new CIDR blocks are allocated, for resources that do not set their own allocation_strategy. " + allocationStrategyDescription + " Defaults to `first_fit`.",
+				Validators:          []validator.String{stringvalidator.OneOf(allocationStrategyNames()...)},
+			},
+			"min_cidr_mask_length": maskLengthLimitAttribute("Smallest mask length, i.e. largest subnet, allowed per IP family, e.g. `22` so that no IPv4 subnet is larger than a /22."),
+			"max_cidr_mask_length": maskLengthLimitAttribute("Largest mask length, i.e. smallest subnet, allowed per IP family, e.g. `28` so that no IPv4 subnet is smaller than a /28."),
+			"debug": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Logs every candidate CIDR block considered while allocating, why it was rejected, such as the allocated or reserved CIDR block it overlaps, and the CIDR block chosen. Candidates are logged at `DEBUG` level and choices at `INFO` level, so they show with `TF_LOG=DEBUG` or `TF_LOG=INFO`. Defaults to the `NETCALC_DEBUG` environment variable.",
+			},
+			"hold_released_cidr_blocks": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether CIDR blocks and addresses released by resources destroyed or updated during an apply stay allocated until the end of the run, so they are not handed out to resources created in the same run while the infrastructure using them may still be torn down, e.g. when a resource is replaced or a VPC subnet is deleted asynchronously. The releases are still recorded in the ledger, so the next run can allocate the CIDR blocks again. Defaults to `false`.",
+			},
+			"warn_utilization_percent": warnUtilizationPercentAttribute(),
+			"max_cidr_count": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Largest `cidr_count` of netcalc_subnets resources, so a typo such as `cidr_count = 100000` fails when planning instead of allocating CIDR blocks for a long time. Defaults to `%d`.", defaultMaxCIDRCount),
+				Validators:          []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"overlapping_pool_cidr_blocks": poolOverlapAttribute(),
+			"pool_cidr_blocks_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of a file with CIDR blocks to add to `pool_cidr_blocks`. A `.json` file holds an array of CIDR blocks, or of objects with a `cidr_block` or `cidr` attribute. A `.csv` file holds a CIDR block per row, in the `cidr_block` or `cidr` column if the file has a header, and in the first column otherwise. Any other file holds a CIDR block per line, and may have comments starting with `#`.",
+			},
+			"claimed_cidr_blocks_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of a file with CIDR blocks to add to `claimed_cidr_blocks`, e.g. exported from another system. The file has the same format as `pool_cidr_blocks_file`.",
+			},
+			"ledger_path": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of a JSON file, usually managed by a netcalc_allocation_ledger resource, in which allocations are recorded. Active allocations in the ledger are treated as claimed CIDR blocks, so allocations made from other Terraform states are not handed out again. Defaults to the `NETCALC_LEDGER_PATH` environment variable when no other ledger is configured.",
+			},
+			"ledger_s3":         ledgerS3Attribute(),
+			"ledger_consul":     ledgerConsulAttribute(),
+			"ledger_etcd":       ledgerEtcdAttribute(),
+			"ledger_infoblox":   ledgerInfobloxAttribute(),
+			"ledger_http":       ledgerHTTPAttribute(),
+			"ledger_vault":      ledgerVaultAttribute(),
+			"ledger_git":        ledgerGitAttribute(),
+			"ledger_kubernetes": ledgerKubernetesAttribute(),
+			"ledger_azure_blob": ledgerAzureBlobAttribute(),
+			"ledger_gcs":        ledgerGCSAttribute(),
+			"ledger_postgres":   ledgerPostgresAttribute(),
+			"ledger_redis":      ledgerRedisAttribute(),
+			"lock_dynamodb":     lockDynamoDBAttribute(),
+			"lock_consul":       lockConsulAttribute(),
+			"lock_azure_blob":   lockAzureBlobAttribute(),
+			"shared_ledger":     sharedLedgerAttribute(),
+			"webhook":           webhookAttribute(),
+			"ssm_parameters":    ssmParametersAttribute(),
+			"aws_discovery":     awsDiscoveryAttribute(),
+			"remote_states":     remoteStatesAttribute(),
+			"azure_discovery":   azureDiscoveryAttribute(),
+			"gcp_discovery":     gcpDiscoveryAttribute(),
+			"aws_ipam_pool":     awsIPAMPoolAttribute(),
 		},
 	}
 }
@@ -113,19 +295,293 @@ func (p *NetcalcProvider) Configure(ctx context.Context, req provider.ConfigureR
 	}
 
 	tflog.Info(ctx, "Configured new netcalc provider")
+	calculator := subnet.NewCalculator()
+	calculator.PoolOverlap = poolOverlap(data.PoolOverlap)
+	calculator.PoolPriorities = readPoolPriorities(ctx, data.PoolPriorities, &resp.Diagnostics)
 	p.calculator = &syncCalculator{
-		c: subnet.NewCalculator(),
+		c:            calculator,
+		holdReleased: data.HoldReleasedCIDRs.ValueBool(),
 	}
 
-	for _, prefix := range parsePrefixList(data.PoolCIDRBlocks, &resp.Diagnostics) {
-		p.calculator.AddPool(prefix)
+	// Pools are added before allocations, which are checked against them.
+	poolPrefixes := parsePrefixList(data.PoolCIDRBlocks, &resp.Diagnostics)
+	if data.PoolCIDRBlocks.IsNull() {
+		poolPrefixes = parsePrefixEnv("NETCALC_POOL_CIDR_BLOCKS", &resp.Diagnostics)
 	}
-	for _, prefix := range parsePrefixList(data.ClaimedCIDRBlocks, &resp.Diagnostics) {
-		p.calculator.AddAllocatedPrefix(prefix)
+	addPools(p.calculator, poolPrefixes, path.Root("pool_cidr_blocks"), &resp.Diagnostics)
+	if file := data.PoolCIDRBlocksFile.ValueString(); file != "" {
+		prefixes, err := readPrefixFile(file)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks_file"), "CIDR file error", fmt.Sprintf("Unable to read pool CIDR blocks from %s: %v", file, err))
+			return
+		}
+		addPools(p.calculator, prefixes, path.Root("pool_cidr_blocks_file"), &resp.Diagnostics)
+	}
+	var ipamAllocations []netip.Prefix
+	if !data.AWSIPAMPool.IsNull() {
+		var ipamData awsIPAMPoolModel
+		resp.Diagnostics.Append(data.AWSIPAMPool.As(ctx, &ipamData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		var cidrs []netip.Prefix
+		cidrs, ipamAllocations = readAWSIPAMPool(ctx, ipamData, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		addPools(p.calculator, cidrs, path.Root("aws_ipam_pool"), &resp.Diagnostics)
+	}
+	namedPools := readNamedPools(ctx, data.Pools, &resp.Diagnostics)
+	for _, pool := range namedPools {
+		calculator.OtherPools = append(calculator.OtherPools, pool.cidrBlocks...)
+	}
+	calculator.AllocationOutsidePools = claimedOutsidePools(data.ClaimedOutsidePools)
+
+	claimedPrefixes := parsePrefixList(data.ClaimedCIDRBlocks, &resp.Diagnostics)
+	if data.ClaimedCIDRBlocks.IsNull() {
+		claimedPrefixes = parsePrefixEnv("NETCALC_CLAIMED_CIDR_BLOCKS", &resp.Diagnostics)
+	}
+	addAllocatedPrefixes(p.calculator, claimedPrefixes, path.Root("claimed_cidr_blocks"), &resp.Diagnostics)
+	reservedPrefixes := parsePrefixList(data.ReservedCIDRBlocks, &resp.Diagnostics)
+	if data.ReservedCIDRBlocks.IsNull() {
+		reservedPrefixes = parsePrefixEnv("NETCALC_RESERVED_CIDR_BLOCKS", &resp.Diagnostics)
+	}
+	for _, prefix := range reservedPrefixes {
+		p.calculator.AddReservedPrefix(prefix)
+	}
+	if file := data.ClaimedCIDRBlocksFile.ValueString(); file != "" {
+		prefixes, err := readPrefixFile(file)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("claimed_cidr_blocks_file"), "CIDR file error", fmt.Sprintf("Unable to read claimed CIDR blocks from %s: %v", file, err))
+			return
+		}
+		addAllocatedPrefixes(p.calculator, prefixes, path.Root("claimed_cidr_blocks_file"), &resp.Diagnostics)
+	}
+	holdPrefixes(p.calculator, ipamAllocations)
+	if !data.AWSDiscovery.IsNull() {
+		var awsData awsDiscoveryModel
+		resp.Diagnostics.Append(data.AWSDiscovery.As(ctx, &awsData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		holdPrefixes(p.calculator, discoverAWS(ctx, awsData, &resp.Diagnostics))
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.AzureDiscovery.IsNull() {
+		var azureData azureDiscoveryModel
+		resp.Diagnostics.Append(data.AzureDiscovery.As(ctx, &azureData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		holdPrefixes(p.calculator, discoverAzure(ctx, azureData, &resp.Diagnostics))
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.GCPDiscovery.IsNull() {
+		var gcpData gcpDiscoveryModel
+		resp.Diagnostics.Append(data.GCPDiscovery.As(ctx, &gcpData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		holdPrefixes(p.calculator, discoverGCP(ctx, gcpData, &resp.Diagnostics))
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.RemoteStates.IsNull() {
+		holdPrefixes(p.calculator, readRemoteStates(ctx, data.RemoteStates, &resp.Diagnostics))
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	resp.DataSourceData = p.calculator
-	resp.ResourceData = p.calculator
+	providerData := &netcalcProviderData{
+		calculator:          p.calculator,
+		pools:               namedPools,
+		strategy:            allocationStrategy(data.AllocationStrategy, subnet.FirstFit),
+		debug:               debugEnabled(data.Debug),
+		maskPolicy:          newMaskLengthPolicy(ctx, data, &resp.Diagnostics),
+		maxCIDRCount:        defaultMaxCIDRCount,
+		claimedOutsidePools: calculator.AllocationOutsidePools,
+	}
+	if !data.MaxCIDRCount.IsNull() {
+		providerData.maxCIDRCount = data.MaxCIDRCount.ValueInt64()
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The pools of the calculator include those of the AWS IPAM pool.
+	pools := append(p.calculator.Pools(false), p.calculator.Pools(true)...)
+	for _, pool := range providerData.pools {
+		pools = append(pools, pool.cidrBlocks...)
+	}
+	checkPoolPriorities(calculator.PoolPriorities, pools, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !data.WarnUtilization.IsNull() {
+		providerData.utilization = newUtilizationWarning(data.WarnUtilization.ValueFloat64(), p.calculator, providerData.pools)
+	}
+	var ledgerAttribute path.Path
+	providerData.ledger, ledgerAttribute = newLedger(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if providerData.ledger != nil {
+		entries, err := providerData.ledger.Entries(ctx)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(ledgerAttribute, "Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+			return
+		}
+		holdPrefixes(p.calculator, activePrefixes(entries))
+	}
+	locker, lockAttribute := newLocker(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if data.SharedLedger.ValueBool() && providerData.ledger == nil {
+		resp.Diagnostics.AddAttributeError(path.Root("shared_ledger"), "Shared ledger without a ledger", "shared_ledger coordinates the allocations of provider blocks through the allocation ledger, but no ledger is configured.")
+		return
+	}
+	if locker != nil || data.SharedLedger.ValueBool() {
+		if providerData.ledger == nil {
+			resp.Diagnostics.AddAttributeWarning(lockAttribute, "Lock without a ledger", "The allocation lock only keeps Terraform runs from handing out the same CIDR blocks when they share an allocation ledger, but no ledger is configured.")
+		}
+		providerData.lock = &allocationLock{locker: locker, ledger: providerData.ledger, calculator: p.calculator}
+	}
+	if !data.Webhook.IsNull() {
+		var webhookData webhookModel
+		resp.Diagnostics.Append(data.Webhook.As(ctx, &webhookData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		providerData.webhook = newWebhook(ctx, webhookData, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.SSMParameters.IsNull() {
+		var ssmData ssmParametersModel
+		resp.Diagnostics.Append(data.SSMParameters.As(ctx, &ssmData, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		providerData.ssmParameters = newSSMParameters(ctx, ssmData, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
+}
+
+// ledgerAttributes are the provider attributes that configure a ledger, of
+// which at most one may be set.
+var ledgerAttributes = []string{"ledger_path", "ledger_s3", "ledger_consul", "ledger_etcd", "ledger_infoblox", "ledger_http", "ledger_vault", "ledger_git", "ledger_kubernetes", "ledger_azure_blob", "ledger_gcs", "ledger_postgres", "ledger_redis"}
+
+// ledgerConflictsValidator rejects ledger attributes set alongside another
+// ledger attribute.
+func ledgerConflictsValidator() validator.Object {
+	var expressions []path.Expression
+	for _, name := range ledgerAttributes {
+		expressions = append(expressions, path.MatchRoot(name))
+	}
+	return objectvalidator.ConflictsWith(expressions...)
+}
+
+// newLedger returns the ledger configured by one of the ledger attributes,
+// and the path of that attribute for diagnostics, or nil if no ledger is
+// configured.
+func newLedger(ctx context.Context, data SubnetCalculatorProviderModel, diagnostics *diag.Diagnostics) (ledger.Ledger, path.Path) {
+	switch {
+	case data.LedgerPath.ValueString() != "":
+		return ledger.NewFileLedger(data.LedgerPath.ValueString()), path.Root("ledger_path")
+	case !data.LedgerS3.IsNull():
+		var s3Data ledgerS3Model
+		diagnostics.Append(data.LedgerS3.As(ctx, &s3Data, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_s3")
+		}
+		s3Ledger, err := newS3Ledger(ctx, s3Data)
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("ledger_s3"), "Ledger error", fmt.Sprintf("Unable to configure the allocation ledger: %v", err))
+		}
+		return s3Ledger, path.Root("ledger_s3")
+	case !data.LedgerConsul.IsNull():
+		var consulData ledgerConsulModel
+		diagnostics.Append(data.LedgerConsul.As(ctx, &consulData, basetypes.ObjectAsOptions{})...)
+		return newConsulLedger(consulData), path.Root("ledger_consul")
+	case !data.LedgerEtcd.IsNull():
+		var etcdData ledgerEtcdModel
+		diagnostics.Append(data.LedgerEtcd.As(ctx, &etcdData, basetypes.ObjectAsOptions{})...)
+		return newEtcdLedger(ctx, etcdData, diagnostics), path.Root("ledger_etcd")
+	case !data.LedgerInfoblox.IsNull():
+		var infobloxData ledgerInfobloxModel
+		diagnostics.Append(data.LedgerInfoblox.As(ctx, &infobloxData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_infoblox")
+		}
+		return newInfobloxLedger(infobloxData, diagnostics), path.Root("ledger_infoblox")
+	case !data.LedgerHTTP.IsNull():
+		var httpData ledgerHTTPModel
+		diagnostics.Append(data.LedgerHTTP.As(ctx, &httpData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_http")
+		}
+		return newHTTPLedger(ctx, httpData, diagnostics), path.Root("ledger_http")
+	case !data.LedgerVault.IsNull():
+		var vaultData ledgerVaultModel
+		diagnostics.Append(data.LedgerVault.As(ctx, &vaultData, basetypes.ObjectAsOptions{})...)
+		return newVaultLedger(vaultData), path.Root("ledger_vault")
+	case !data.LedgerGit.IsNull():
+		var gitData ledgerGitModel
+		diagnostics.Append(data.LedgerGit.As(ctx, &gitData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_git")
+		}
+		return newGitLedger(gitData, diagnostics), path.Root("ledger_git")
+	case !data.LedgerKubernetes.IsNull():
+		var kubernetesData ledgerKubernetesModel
+		diagnostics.Append(data.LedgerKubernetes.As(ctx, &kubernetesData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_kubernetes")
+		}
+		return newKubernetesLedger(ctx, kubernetesData, diagnostics), path.Root("ledger_kubernetes")
+	case !data.LedgerAzureBlob.IsNull():
+		var azureBlobData ledgerAzureBlobModel
+		diagnostics.Append(data.LedgerAzureBlob.As(ctx, &azureBlobData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_azure_blob")
+		}
+		return newAzureBlobLedger(azureBlobData, diagnostics), path.Root("ledger_azure_blob")
+	case !data.LedgerGCS.IsNull():
+		var gcsData ledgerGCSModel
+		diagnostics.Append(data.LedgerGCS.As(ctx, &gcsData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_gcs")
+		}
+		return newGCSLedger(ctx, gcsData, diagnostics), path.Root("ledger_gcs")
+	case !data.LedgerPostgres.IsNull():
+		var postgresData ledgerPostgresModel
+		diagnostics.Append(data.LedgerPostgres.As(ctx, &postgresData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_postgres")
+		}
+		return newPostgresLedger(postgresData, diagnostics), path.Root("ledger_postgres")
+	case !data.LedgerRedis.IsNull():
+		var redisData ledgerRedisModel
+		diagnostics.Append(data.LedgerRedis.As(ctx, &redisData, basetypes.ObjectAsOptions{})...)
+		if diagnostics.HasError() {
+			return nil, path.Root("ledger_redis")
+		}
+		return newRedisLedger(redisData, diagnostics), path.Root("ledger_redis")
+	case os.Getenv("NETCALC_LEDGER_PATH") != "":
+		return ledger.NewFileLedger(os.Getenv("NETCALC_LEDGER_PATH")), path.Root("ledger_path")
+	}
+	return nil, path.Empty()
 }
 
 func parsePrefixList(data types.List, diagnostics *diag.Diagnostics) []netip.Prefix {
@@ -141,28 +597,124 @@ func parsePrefixList(data types.List, diagnostics *diag.Diagnostics) []netip.Pre
 			diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse pool CIDR: %q, %v", cidr, err))
 			continue
 		}
-		prefixes = append(prefixes, n)
+		prefixes = append(prefixes, canonicalPrefix(n, diagnostics))
+	}
+	return prefixes
+}
+
+// parsePrefixEnv parses the CIDR blocks in an environment variable, which are
+// separated by commas or whitespace.
+func parsePrefixEnv(name string, diagnostics *diag.Diagnostics) []netip.Prefix {
+	var prefixes []netip.Prefix
+	fields := strings.FieldsFunc(os.Getenv(name), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	for _, field := range fields {
+		n, err := netip.ParsePrefix(field)
+		if err != nil {
+			diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR in %s: %q, %v", name, field, err))
+			continue
+		}
+		prefixes = append(prefixes, canonicalPrefix(n, diagnostics))
 	}
 	return prefixes
 }
 
-func parsePrefix(cidr types.String, diagnostics diag.Diagnostics) netip.Prefix {
+func parsePrefix(cidr types.String, diagnostics *diag.Diagnostics) netip.Prefix {
 	n, err := netip.ParsePrefix(cidr.ValueString())
 	if err != nil {
 		diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+		return n
 	}
-	return n
+	return canonicalPrefix(n, diagnostics)
+}
+
+// canonicalPrefix returns a prefix with its host bits cleared, such as
+// 10.0.1.0/24 for 10.0.1.5/24, and warns about prefixes that had host bits
+// set, so only canonical CIDR blocks are allocated and stored.
+func canonicalPrefix(prefix netip.Prefix, diagnostics *diag.Diagnostics) netip.Prefix {
+	if masked := prefix.Masked(); masked != prefix {
+		diagnostics.AddWarning("Non-canonical CIDR block", fmt.Sprintf("The CIDR block %s has host bits set and is used as %s. Use %s in the configuration to avoid this warning.", prefix, masked, masked))
+		return masked
+	}
+	return prefix
+}
+
+// prefixList converts prefixes to a list value, which is empty rather than
+// null when there are no prefixes, so it can be used in expressions without
+// checking for null.
+func prefixList(ctx context.Context, prefixes []netip.Prefix) (types.List, diag.Diagnostics) {
+	return types.ListValueFrom(ctx, types.StringType, append([]string{}, prefixStrings(prefixes)...))
 }
 
 func (p *NetcalcProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSubnetResource,
 		NewSubnetsResource,
+		NewPoolResource,
+		NewIPRangeResource,
+		NewSubnetSplitResource,
+		NewSupernetResource,
+		NewAllocationLedgerResource,
+		NewDualStackSubnetResource,
+		NewSubnetPairResource,
+		NewSubnetGroupResource,
+		NewStaticSubnetResource,
+		NewHostResource,
+		NewCIDRCollectionResource,
 	}
 }
 
 func (p *NetcalcProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewSubnetInfoDataSource,
+		NewFreeSpaceDataSource,
+		NewOverlapDataSource,
+		NewContainsDataSource,
+		NewHostsDataSource,
+		NewULADataSource,
+		NewIPMathDataSource,
+		NewAllocationPlanDataSource,
+		NewGapsDataSource,
+		NewCoverageDataSource,
+		NewPoolLookupDataSource,
+		NewCapacityDataSource,
+		NewFitDataSource,
+		NewAddressRangeDataSource,
+		NewNetmaskDataSource,
+		NewAddressClassDataSource,
+		NewReverseDNSZonesDataSource,
+		NewDHCPScopeDataSource,
+		NewKubernetesCIDRPlanDataSource,
+		NewVPCLayoutDataSource,
+		NewRenumberingPlanDataSource,
+		NewIPv4EmbeddingDataSource,
+		NewIPv6HostAddressDataSource,
+	}
+}
+
+func (p *NetcalcProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewCIDRContainsFunction,
+		NewCIDROverlapsFunction,
+		NewCIDRsOverlapFunction,
+		NewNextSubnetFunction,
+		NewCIDRSubtractFunction,
+		NewCIDRInfoFunction,
+		NewMaskFromHostsFunction,
+		NewIPFamilyFunction,
+		NewExpandIPv6Function,
+		NewCompressIPv6Function,
+		NewReversePTRFunction,
+		NewHostCountFunction,
+		NewCIDRNetmaskFunction,
+		NewCIDRWildcardFunction,
+		NewSubnetsFitFunction,
+		NewCIDREqualFunction,
+		NewCIDRSubnetsMapFunction,
+		NewNthSubnetFunction,
+		NewSubnetIndexFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -174,32 +726,61 @@ func New(version string) func() provider.Provider {
 }
 
 type syncCalculator struct {
-	c SubnetCalculator
+	c *subnet.Calculator
 	m sync.Mutex
+	// holdReleased keeps released prefixes and ranges allocated until the
+	// end of the run, and released records the prefixes for resources that
+	// do not allocate from the calculator.
+	holdReleased bool
+	released     []netip.Prefix
 }
 
-func (s *syncCalculator) AddPool(prefix netip.Prefix) {
+func (s *syncCalculator) AddPool(prefix netip.Prefix) error {
 	s.m.Lock()
 	defer s.m.Unlock()
-	s.c.AddPool(prefix)
+	return s.c.AddPool(prefix)
 }
 
-func (s *syncCalculator) AddAllocatedPrefix(prefix netip.Prefix) {
+func (s *syncCalculator) AddAllocatedPrefix(prefix netip.Prefix) error {
 	s.m.Lock()
 	defer s.m.Unlock()
-	s.c.AddAllocatedPrefix(prefix)
+	return s.c.AddAllocatedPrefix(prefix)
 }
 
-func (s *syncCalculator) NextAvailableIPv4Subnet(numBits int) (netip.Prefix, error) {
+func (s *syncCalculator) HoldPrefix(prefix netip.Prefix) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	return s.c.NextAvailableIPv4Subnet(numBits)
+	s.c.HoldPrefix(prefix)
 }
 
-func (s *syncCalculator) NextAvailableIPv6Subnet(numBits int) (netip.Prefix, error) {
+func (s *syncCalculator) AddReservedPrefix(prefix netip.Prefix) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	return s.c.NextAvailableIPv6Subnet(numBits)
+	s.c.AddReservedPrefix(prefix)
+}
+
+func (s *syncCalculator) NextAvailableIPv4Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.NextAvailableIPv4Subnet(ctx, numBits)
+}
+
+func (s *syncCalculator) NextAvailableIPv6Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.NextAvailableIPv6Subnet(ctx, numBits)
+}
+
+func (s *syncCalculator) NextAvailableSubnet(ctx context.Context, ipv6 bool, numBits int, strategy subnet.Strategy) (netip.Prefix, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.NextAvailableSubnet(ctx, ipv6, numBits, strategy)
+}
+
+func (s *syncCalculator) NextAvailableSubnetInPools(pools []netip.Prefix, reserved []netip.Prefix, numBits int, strategy subnet.Strategy) (netip.Prefix, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.NextAvailableSubnetInPools(pools, reserved, numBits, strategy)
 }
 
 func (s *syncCalculator) DeleteAllocatedPrefix(prefix netip.Prefix) {
@@ -208,10 +789,93 @@ func (s *syncCalculator) DeleteAllocatedPrefix(prefix netip.Prefix) {
 	s.c.DeleteAllocatedPrefix(prefix)
 }
 
+func (s *syncCalculator) ReleasePrefix(prefix netip.Prefix) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.holdReleased {
+		// The prefix is not in the calculator if the resource was not read
+		// during the run.
+		s.c.HoldPrefix(prefix)
+		s.released = append(s.released, prefix)
+		return
+	}
+	s.c.DeleteAllocatedPrefix(prefix)
+}
+
+func (s *syncCalculator) ReleasedPrefixes() []netip.Prefix {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return slices.Clone(s.released)
+}
+
 func (s *syncCalculator) PrefixInPools(prefix netip.Prefix) bool {
 	s.m.Lock()
 	defer s.m.Unlock()
 	return s.c.PrefixInPools(prefix)
 }
 
+func (s *syncCalculator) ClaimPrefix(prefix netip.Prefix, covered []netip.Prefix) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.ClaimPrefix(prefix, covered)
+}
+
+func (s *syncCalculator) NextAvailableRange(prefix netip.Prefix, count uint64) (subnet.AddressRange, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.NextAvailableRange(prefix, count)
+}
+
+func (s *syncCalculator) DeleteAllocatedRange(r subnet.AddressRange) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.c.DeleteAllocatedRange(r)
+}
+
+func (s *syncCalculator) ReleaseRange(r subnet.AddressRange) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.holdReleased {
+		s.c.AddAllocatedRange(r)
+		return
+	}
+	s.c.DeleteAllocatedRange(r)
+}
+
+func (s *syncCalculator) ClaimRange(r subnet.AddressRange) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.ClaimRange(r)
+}
+
+func (s *syncCalculator) Pools(ipv6 bool) []netip.Prefix {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.Pools(ipv6)
+}
+
+func (s *syncCalculator) PoolPriorities() map[netip.Prefix]int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return maps.Clone(s.c.PoolPriorities)
+}
+
+func (s *syncCalculator) ReservedPrefixes() []netip.Prefix {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return slices.Clone(s.c.ReservedPrefixes)
+}
+
+func (s *syncCalculator) AllocatedPrefixes(ipv6 bool) []netip.Prefix {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.AllocatedPrefixes(ipv6)
+}
+
+func (s *syncCalculator) FreePrefixesInPool(pool netip.Prefix, used []netip.Prefix) []netip.Prefix {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.c.FreePrefixesInPool(pool, used)
+}
+
 var _ SubnetCalculator = &syncCalculator{}
@@ -4,10 +4,15 @@
 package provider
 
 import (
+	"fmt"
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/geezyx/subnet-calculator/internal/ledger"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -23,3 +28,252 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestAccProviderEnvironment(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	t.Setenv("NETCALC_LEDGER_PATH", ledgerPath)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Environment validation
+			{
+				PreConfig: func() {
+					t.Setenv("NETCALC_POOL_CIDR_BLOCKS", "10.0.0.0/16,10.1.0.0")
+				},
+				Config: `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 23
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR\s+in\s+NETCALC_POOL_CIDR_BLOCKS:\s+"10.1.0.0"`),
+			},
+			// Create and Read testing
+			{
+				PreConfig: func() {
+					t.Setenv("NETCALC_POOL_CIDR_BLOCKS", "10.0.0.0/16, 10.1.0.0/16\nfd00::/48")
+					t.Setenv("NETCALC_CLAIMED_CIDR_BLOCKS", "10.0.0.0/24 10.0.1.0/24")
+				},
+				Config: `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 23
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.2.0/23"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath), "10.0.2.0/23"),
+				),
+			},
+			// Provider attributes take precedence
+			{
+				Config: `
+				provider "netcalc" {
+				  claimed_cidr_blocks = []
+				}
+
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 23
+				}
+
+				resource "netcalc_subnet" "other" {
+					cidr_mask_length = 24
+				}`,
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.other", "cidr_block", "10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func TestAccProviderHoldReleasedCIDRBlocks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderHoldReleasedConfig(true, 25),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/25"),
+			},
+			// The replaced subnet does not get the CIDR block released in
+			// the same run.
+			{
+				Config: testAccProviderHoldReleasedConfig(true, 24),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.1.1.0/24"),
+				),
+			},
+			// Released CIDR blocks are available again by default.
+			{
+				Config: testAccProviderHoldReleasedConfig(false, 25),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/25"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.1.0.0/25"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderHoldReleasedConfig(hold bool, maskLength int) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks          = ["10.0.0.0/16"]
+  hold_released_cidr_blocks = %[1]t
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = %[2]d
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = ["10.1.0.0/16"]
+  cidr_mask_length = %[2]d
+  cidr_count       = 1
+}
+`, hold, maskLength)
+}
+
+// CIDR blocks with host bits set are used with the host bits cleared.
+func TestAccProviderNonCanonicalCIDRBlocks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "netcalc" {
+  pool_cidr_blocks    = ["10.0.0.5/16"]
+  claimed_cidr_blocks = ["10.0.0.1/24"]
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 24
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = ["10.1.2.3/16"]
+  cidr_mask_length = 24
+  cidr_count       = 1
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.1.0.0/24"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProviderDefaultRoutePools(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "netcalc" {
+  pool_cidr_blocks    = ["0.0.0.0/0", "::/0"]
+  claimed_cidr_blocks = ["0.0.0.0/1", "128.0.0.0/2", "::/1"]
+}
+
+resource "netcalc_subnet" "ipv4" {
+  cidr_mask_length = 2
+}
+
+resource "netcalc_subnet" "ipv6" {
+  ip_family        = "ipv6"
+  cidr_mask_length = 1
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = ["0.0.0.0/0"]
+  cidr_mask_length = 1
+  cidr_count       = 2
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_block", "192.0.0.0/2"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_block", "8000::/1"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "0.0.0.0/1,128.0.0.0/1"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "remaining_count", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProviderReservedCIDRBlocks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks     = ["10.0.0.0/16"]
+					claimed_cidr_blocks  = ["192.168.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/24", "192.168.0.0/24"]
+				}
+
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+
+				resource "netcalc_pool" "claimed" {
+					cidr_blocks = ["192.168.0.0/16"]
+				}
+
+				resource "netcalc_subnet" "claimed" {
+					pool_id          = netcalc_pool.claimed.id
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.claimed", "cidr_block", "192.168.1.0/24"),
+				),
+			},
+			// Subnets in newly reserved CIDR blocks are reallocated
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks     = ["10.0.0.0/16"]
+					claimed_cidr_blocks  = ["192.168.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/23", "192.168.0.0/24"]
+				}
+
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+
+				resource "netcalc_pool" "claimed" {
+					cidr_blocks = ["192.168.0.0/16"]
+				}
+
+				resource "netcalc_subnet" "claimed" {
+					pool_id          = netcalc_pool.claimed.id
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.claimed", "cidr_block", "192.168.1.0/24"),
+				),
+			},
+			// Reserved CIDR blocks from the environment
+			{
+				PreConfig: func() {
+					t.Setenv("NETCALC_RESERVED_CIDR_BLOCKS", "10.0.0.0/23, 10.0.2.0/24")
+				},
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`,
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.3.0/24"),
+			},
+		},
+	})
+}
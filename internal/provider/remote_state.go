@@ -0,0 +1,383 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// remoteStateModel describes an element of the remote_states provider
+// attribute.
+type remoteStateModel struct {
+	S3            types.Object `tfsdk:"s3"`
+	HTTP          types.Object `tfsdk:"http"`
+	TFC           types.Object `tfsdk:"tfc"`
+	OutputPattern types.String `tfsdk:"output_pattern"`
+}
+
+// remoteStateS3Model describes the s3 attribute of a remote state.
+type remoteStateS3Model struct {
+	Bucket       types.String `tfsdk:"bucket"`
+	Key          types.String `tfsdk:"key"`
+	Region       types.String `tfsdk:"region"`
+	Profile      types.String `tfsdk:"profile"`
+	Endpoint     types.String `tfsdk:"endpoint"`
+	UsePathStyle types.Bool   `tfsdk:"use_path_style"`
+}
+
+// remoteStateHTTPModel describes the http attribute of a remote state.
+type remoteStateHTTPModel struct {
+	Address  types.String `tfsdk:"address"`
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+}
+
+// remoteStateTFCModel describes the tfc attribute of a remote state.
+type remoteStateTFCModel struct {
+	Hostname     types.String `tfsdk:"hostname"`
+	Organization types.String `tfsdk:"organization"`
+	Workspace    types.String `tfsdk:"workspace"`
+	Token        types.String `tfsdk:"token"`
+}
+
+func remoteStatesAttribute() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "Terraform states of other workspaces whose CIDR block outputs are treated as claimed CIDR blocks, so allocations of sibling workspaces are respected without listing them in `claimed_cidr_blocks`. Every output whose name matches `output_pattern` is searched for CIDR blocks: strings, and strings in lists, sets, maps and objects, that are CIDR blocks are claimed, and other values are ignored. The states are read whenever the provider is configured, so a plan also claims CIDR blocks output since the last one.",
+		Optional:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"s3": schema.SingleNestedAttribute{
+					MarkdownDescription: "Reads the state from S3, like the `s3` backend. Credentials are taken from the usual AWS environment variables, shared configuration files and instance roles.",
+					Optional:            true,
+					Attributes: map[string]schema.Attribute{
+						"bucket": schema.StringAttribute{
+							MarkdownDescription: "Name of the S3 bucket.",
+							Required:            true,
+						},
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Key of the state object, e.g. `network/terraform.tfstate`. The state of a workspace other than `default` is at `env:/<workspace>/<key>` unless the backend sets `workspace_key_prefix`.",
+							Required:            true,
+						},
+						"region": schema.StringAttribute{
+							MarkdownDescription: "AWS region of the bucket. Defaults to the region of the AWS configuration.",
+							Optional:            true,
+						},
+						"profile": schema.StringAttribute{
+							MarkdownDescription: "Name of the AWS shared configuration profile to use.",
+							Optional:            true,
+						},
+						"endpoint": schema.StringAttribute{
+							MarkdownDescription: "Custom S3 endpoint URL, e.g. for S3-compatible object stores.",
+							Optional:            true,
+						},
+						"use_path_style": schema.BoolAttribute{
+							MarkdownDescription: "Whether to address the bucket in the path rather than the host name of S3 requests, as some S3-compatible object stores require.",
+							Optional:            true,
+						},
+					},
+					Validators: []validator.Object{
+						// A state has exactly one source.
+						objectvalidator.ExactlyOneOf(
+							path.MatchRelative().AtParent().AtName("http"),
+							path.MatchRelative().AtParent().AtName("tfc"),
+						),
+					},
+				},
+				"http": schema.SingleNestedAttribute{
+					MarkdownDescription: "Reads the state with `GET` from a URL, like the `http` backend.",
+					Optional:            true,
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							MarkdownDescription: "URL of the state.",
+							Required:            true,
+						},
+						"username": schema.StringAttribute{
+							MarkdownDescription: "Username for HTTP basic authentication.",
+							Optional:            true,
+						},
+						"password": schema.StringAttribute{
+							MarkdownDescription: "Password for HTTP basic authentication.",
+							Optional:            true,
+							Sensitive:           true,
+						},
+					},
+				},
+				"tfc": schema.SingleNestedAttribute{
+					MarkdownDescription: "Reads the outputs of the current state of an HCP Terraform or Terraform Enterprise workspace. Outputs marked as sensitive are not available to the provider.",
+					Optional:            true,
+					Attributes: map[string]schema.Attribute{
+						"hostname": schema.StringAttribute{
+							MarkdownDescription: "Hostname of HCP Terraform or Terraform Enterprise. Defaults to `app.terraform.io`.",
+							Optional:            true,
+						},
+						"organization": schema.StringAttribute{
+							MarkdownDescription: "Name of the organization of the workspace.",
+							Required:            true,
+						},
+						"workspace": schema.StringAttribute{
+							MarkdownDescription: "Name of the workspace.",
+							Required:            true,
+						},
+						"token": schema.StringAttribute{
+							MarkdownDescription: "API token, which needs permission to read the state outputs of the workspace. Defaults to the `TF_TOKEN_<hostname>` environment variable of the Terraform CLI, e.g. `TF_TOKEN_app_terraform_io`.",
+							Optional:            true,
+							Sensitive:           true,
+						},
+					},
+				},
+				"output_pattern": schema.StringAttribute{
+					MarkdownDescription: "Regular expression that the names of the outputs to claim CIDR blocks from match, e.g. `_cidr_blocks?$`. Defaults to all outputs.",
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// readRemoteStates returns the CIDR blocks in the matching outputs of the
+// states configured by the remote_states provider attribute.
+func readRemoteStates(ctx context.Context, data types.List, diagnostics *diag.Diagnostics) []netip.Prefix {
+	var states []remoteStateModel
+	diagnostics.Append(data.ElementsAs(ctx, &states, false)...)
+	if diagnostics.HasError() {
+		return nil
+	}
+	var prefixes []netip.Prefix
+	for i, state := range states {
+		statePath := path.Root("remote_states").AtListIndex(i)
+		pattern, err := regexp.Compile(state.OutputPattern.ValueString())
+		if err != nil {
+			diagnostics.AddAttributeError(statePath.AtName("output_pattern"), "Remote state error", fmt.Sprintf("Unable to parse output pattern: %v", err))
+			return nil
+		}
+		var name string
+		var outputs map[string]any
+		switch {
+		case !state.S3.IsNull():
+			var s3Data remoteStateS3Model
+			diagnostics.Append(state.S3.As(ctx, &s3Data, basetypes.ObjectAsOptions{})...)
+			name = fmt.Sprintf("s3://%s/%s", s3Data.Bucket.ValueString(), s3Data.Key.ValueString())
+			outputs, err = readS3State(ctx, s3Data)
+		case !state.HTTP.IsNull():
+			var httpData remoteStateHTTPModel
+			diagnostics.Append(state.HTTP.As(ctx, &httpData, basetypes.ObjectAsOptions{})...)
+			name = httpData.Address.ValueString()
+			outputs, err = readHTTPState(ctx, httpData)
+		case !state.TFC.IsNull():
+			var tfcData remoteStateTFCModel
+			diagnostics.Append(state.TFC.As(ctx, &tfcData, basetypes.ObjectAsOptions{})...)
+			name = fmt.Sprintf("workspace %s/%s", tfcData.Organization.ValueString(), tfcData.Workspace.ValueString())
+			outputs, err = readTFCOutputs(ctx, tfcData)
+		}
+		if diagnostics.HasError() {
+			return nil
+		}
+		if err != nil {
+			diagnostics.AddAttributeError(statePath, "Remote state error", fmt.Sprintf("Unable to read the outputs of %s: %v", name, err))
+			return nil
+		}
+		names := make([]string, 0, len(outputs))
+		for output := range outputs {
+			if pattern.MatchString(output) {
+				names = append(names, output)
+			}
+		}
+		sort.Strings(names)
+		for _, output := range names {
+			found := outputPrefixes(outputs[output])
+			tflog.Debug(ctx, "claimed CIDR blocks of remote state output", map[string]interface{}{"state": name, "output": output, "count": len(found)})
+			prefixes = append(prefixes, found...)
+		}
+	}
+	return prefixes
+}
+
+// outputPrefixes returns the CIDR blocks in an output value decoded from
+// JSON, searching lists and objects recursively.
+func outputPrefixes(value any) []netip.Prefix {
+	switch v := value.(type) {
+	case string:
+		if prefix, err := netip.ParsePrefix(v); err == nil {
+			return []netip.Prefix{prefix.Masked()}
+		}
+	case []any:
+		var prefixes []netip.Prefix
+		for _, elem := range v {
+			prefixes = append(prefixes, outputPrefixes(elem)...)
+		}
+		return prefixes
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var prefixes []netip.Prefix
+		for _, key := range keys {
+			prefixes = append(prefixes, outputPrefixes(v[key])...)
+		}
+		return prefixes
+	}
+	return nil
+}
+
+// parseStateOutputs returns the output values of a Terraform state file.
+func parseStateOutputs(b []byte) (map[string]any, error) {
+	var state struct {
+		Outputs map[string]struct {
+			Value any `json:"value"`
+		} `json:"outputs"`
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("unable to parse state: %w", err)
+	}
+	outputs := make(map[string]any, len(state.Outputs))
+	for name, output := range state.Outputs {
+		outputs[name] = output.Value
+	}
+	return outputs, nil
+}
+
+func readS3State(ctx context.Context, data remoteStateS3Model) (map[string]any, error) {
+	cfg, err := loadAWSConfig(ctx, data.Region.ValueString(), data.Profile.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS configuration: %w", err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := data.Endpoint.ValueString(); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = data.UsePathStyle.ValueBool()
+	})
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	b, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseStateOutputs(b)
+}
+
+func readHTTPState(ctx context.Context, data remoteStateHTTPModel) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, data.Address.ValueString(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !data.Username.IsNull() || !data.Password.IsNull() {
+		req.SetBasicAuth(data.Username.ValueString(), data.Password.ValueString())
+	}
+	b, err := getRemoteState(req)
+	if err != nil {
+		return nil, err
+	}
+	return parseStateOutputs(b)
+}
+
+func readTFCOutputs(ctx context.Context, data remoteStateTFCModel) (map[string]any, error) {
+	hostname := data.Hostname.ValueString()
+	if hostname == "" {
+		hostname = "app.terraform.io"
+	}
+	baseURL := hostname
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	token := data.Token.ValueString()
+	if token == "" {
+		// The Terraform CLI reads credentials from variables named after the
+		// hostname, with dots replaced by underscores.
+		host := baseURL[strings.Index(baseURL, "://")+3:]
+		token = os.Getenv("TF_TOKEN_" + strings.NewReplacer(".", "_", "-", "__").Replace(host))
+	}
+	get := func(apiPath string, v any) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/v2"+apiPath, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.api+json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		b, err := getRemoteState(req)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}
+
+	var workspace struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := get(fmt.Sprintf("/organizations/%s/workspaces/%s", url.PathEscape(data.Organization.ValueString()), url.PathEscape(data.Workspace.ValueString())), &workspace); err != nil {
+		return nil, err
+	}
+	var stateOutputs struct {
+		Data []struct {
+			Attributes struct {
+				Name      string `json:"name"`
+				Value     any    `json:"value"`
+				Sensitive bool   `json:"sensitive"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := get(fmt.Sprintf("/workspaces/%s/current-state-version-outputs", url.PathEscape(workspace.Data.ID)), &stateOutputs); err != nil {
+		return nil, err
+	}
+	outputs := make(map[string]any, len(stateOutputs.Data))
+	for _, output := range stateOutputs.Data {
+		if !output.Attributes.Sensitive {
+			outputs[output.Attributes.Name] = output.Attributes.Value
+		}
+	}
+	return outputs, nil
+}
+
+// getRemoteState sends a request for a state and returns the response body.
+// It fails unless the status is a success.
+func getRemoteState(req *http.Request) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
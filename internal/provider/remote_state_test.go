@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// newFakeStateServer serves a state with the http backend protocol, and
+// the outputs of a workspace with the HCP Terraform API.
+func newFakeStateServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state/app", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, _ := r.BasicAuth(); username != "terraform" || password != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{
+  "version": 4,
+  "outputs": {
+    "subnet_cidr_blocks": {"value": {"a": "10.0.1.0/24", "b": ["10.0.2.0/24"]}, "type": ["object", {}]},
+    "vpc_id": {"value": "vpc-0123", "type": "string"},
+    "other_cidr_block": {"value": "10.0.9.0/24", "type": "string"}
+  }
+}`)
+	})
+	mux.HandleFunc("/api/v2/organizations/example/workspaces/network", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tfc-token" {
+			http.Error(w, `{"errors":[{"status":"404","title":"not found"}]}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"id":"ws-123","type":"workspaces"}}`)
+	})
+	mux.HandleFunc("/api/v2/workspaces/ws-123/current-state-version-outputs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[
+  {"id":"wsout-1","type":"state-version-outputs","attributes":{"name":"vpc_cidr_block","sensitive":false,"value":"10.0.3.0/24"}},
+  {"id":"wsout-2","type":"state-version-outputs","attributes":{"name":"secret_cidr_block","sensitive":true,"value":null}}
+]}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAccProviderRemoteStates(t *testing.T) {
+	stateServer := newFakeStateServer(t)
+	awsServer := newFakeAWSServer(t)
+	awsServer.Config.Handler.(*fakeAWSServer).objects["/states/shared/terraform.tfstate"] = []byte(`{
+  "version": 4,
+  "outputs": {"cidr_blocks": {"value": ["10.0.0.0/24", "fd00::/64"], "type": ["list", "string"]}}
+}`)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Exactly one source per state
+			{
+				Config: testAccProviderRemoteStatesConfig(fmt.Sprintf(`{
+				  http = { address = "%[1]s/state/app" }
+				  tfc  = { organization = "example", workspace = "network" }
+				}`, stateServer.URL)),
+				ExpectError: regexp.MustCompile(`(?s)Invalid\s+Attribute\s+Combination.*2\s+attributes\s+specified`),
+			},
+			// Failed reads are reported
+			{
+				Config: testAccProviderRemoteStatesConfig(fmt.Sprintf(`{
+				  http = { address = "%[1]s/state/app", username = "terraform", password = "wrong" }
+				}`, stateServer.URL)),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+read\s+the\s+outputs\s+of\s+.*/state/app`),
+			},
+			// Matching outputs of every state are claimed
+			{
+				Config: testAccProviderRemoteStatesConfig(fmt.Sprintf(`{
+				  s3 = {
+				    bucket         = "states"
+				    key            = "shared/terraform.tfstate"
+				    region         = "us-east-1"
+				    endpoint       = %[2]q
+				    use_path_style = true
+				  }
+				},
+				{
+				  http           = { address = "%[1]s/state/app", username = "terraform", password = "secret" }
+				  output_pattern = "^subnet_"
+				},
+				{
+				  tfc = {
+				    hostname     = %[1]q
+				    organization = "example"
+				    workspace    = "network"
+				    token        = "tfc-token"
+				  }
+				}`, stateServer.URL, awsServer.URL)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.4.0/24"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderRemoteStatesConfig(states string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+
+  remote_states = [%[1]s]
+}
+
+resource "netcalc_subnet" "test" {
+  cidr_mask_length = 24
+}
+`, states)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &RenumberingPlanDataSource{}
+var _ datasource.DataSourceWithConfigure = &RenumberingPlanDataSource{}
+
+func NewRenumberingPlanDataSource() datasource.DataSource {
+	return &RenumberingPlanDataSource{}
+}
+
+// RenumberingPlanDataSource defines the data source implementation.
+type RenumberingPlanDataSource struct {
+	calculator SubnetCalculator
+	strategy   subnet.Strategy
+}
+
+// RenumberingPlanDataSourceModel describes the data source data model.
+type RenumberingPlanDataSourceModel struct {
+	CIDRBlocks          types.List   `tfsdk:"cidr_blocks"`
+	PoolID              types.String `tfsdk:"pool_id"`
+	AllocatedCIDRBlocks types.Set    `tfsdk:"allocated_cidr_blocks"`
+	Mappings            types.List   `tfsdk:"mappings"`
+	Mapping             types.Map    `tfsdk:"mapping"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+// RenumberingModel describes the new CIDR block of an old one.
+type RenumberingModel struct {
+	OldCIDRBlock types.String `tfsdk:"old_cidr_block"`
+	NewCIDRBlock types.String `tfsdk:"new_cidr_block"`
+}
+
+func (d *RenumberingPlanDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_renumbering_plan"
+}
+
+func (d *RenumberingPlanDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renumbering plan data source. Maps each of a list of existing subnets to a new CIDR block of the same size in the pools, e.g. to drive the migration of an environment to a new address plan. The largest subnets are placed first, and reading fails if they do not all fit. Like netcalc_allocation_plan, nothing is allocated.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks of the existing subnets. Each is mapped to a CIDR block of its own IP family.",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(ipAddressValidator{}),
+				},
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to renumber into instead of the provider's pool_cidr_blocks.",
+				Optional:            true,
+			},
+			"allocated_cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional CIDR blocks to treat as allocated.",
+				Optional:            true,
+				Validators:          []validator.Set{setvalidator.ValueStringsAre(ipAddressValidator{})},
+			},
+			"mappings": schema.ListNestedAttribute{
+				MarkdownDescription: "New CIDR block of each existing subnet, in the same order as cidr_blocks.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"old_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "CIDR block of the existing subnet, as given in cidr_blocks.",
+							Computed:            true,
+						},
+						"new_cidr_block": schema.StringAttribute{
+							MarkdownDescription: "New CIDR block of the same size.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"mapping": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "New CIDR blocks keyed by old CIDR block, for lookups. An old CIDR block listed more than once maps to the new CIDR block of its first occurrence.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the ID of the pool renumbered into.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *RenumberingPlanDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		d.calculator = data.calculator
+		d.strategy = data.strategy
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (d *RenumberingPlanDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data RenumberingPlanDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var cidrBlocks []string
+	resp.Diagnostics.Append(data.CIDRBlocks.ElementsAs(ctx, &cidrBlocks, false)...)
+	sim, diags := newAllocationSimulation(ctx, d.calculator, d.strategy, data.PoolID, data.AllocatedCIDRBlocks)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	old := make([]netip.Prefix, len(cidrBlocks))
+	for i, cidr := range cidrBlocks {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("cidr_blocks").AtListIndex(i), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+			continue
+		}
+		old[i] = prefix.Masked()
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	order := make([]int, len(old))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return old[order[i]].Bits() < old[order[j]].Bits() })
+	renumbered := make([]netip.Prefix, len(old))
+	for _, i := range order {
+		next, err := sim.next(ctx, old[i].Addr().Is6(), old[i].Bits())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("cidr_blocks").AtListIndex(i), "Renumbering does not fit", fmt.Sprintf("Unable to find a new CIDR block for %s: %v", cidrBlocks[i], err))
+			return
+		}
+		renumbered[i] = next
+	}
+
+	mappings := make([]RenumberingModel, 0, len(old))
+	mapping := make(map[string]string, len(old))
+	for i, cidr := range cidrBlocks {
+		mappings = append(mappings, RenumberingModel{
+			OldCIDRBlock: types.StringValue(cidr),
+			NewCIDRBlock: types.StringValue(renumbered[i].String()),
+		})
+		if _, ok := mapping[cidr]; !ok {
+			mapping[cidr] = renumbered[i].String()
+		}
+	}
+	mappingList, diags := types.ListValueFrom(ctx, data.Mappings.ElementType(ctx), mappings)
+	resp.Diagnostics.Append(diags...)
+	data.Mappings = mappingList
+	mappingMap, diags := types.MapValueFrom(ctx, types.StringType, mapping)
+	resp.Diagnostics.Append(diags...)
+	data.Mapping = mappingMap
+	data.ID = types.StringValue(sim.id)
+
+	tflog.Trace(ctx, "read a renumbering plan data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
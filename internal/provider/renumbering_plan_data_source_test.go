@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccRenumberingPlanDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Capacity validation
+			{
+				Config: `
+				data "netcalc_renumbering_plan" "too_big" {
+					pool_id     = "172.16.0.0/24"
+					cidr_blocks = ["10.0.0.0/24", "10.0.1.0/25"]
+				}`,
+				ExpectError: regexp.MustCompile(`Renumbering\s+does\s+not\s+fit`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_renumbering_plan" "test" {
+					pool_id     = "172.16.0.0/22,fd00::/48"
+					cidr_blocks = ["10.0.5.0/26", "10.0.8.0/24", "10.0.9.0/25", "fd12::/64"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "id", "172.16.0.0/22,fd00::/48"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.#", "4"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.0.old_cidr_block", "10.0.5.0/26"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.0.new_cidr_block", "172.16.1.128/26"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.1.new_cidr_block", "172.16.0.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.2.new_cidr_block", "172.16.1.0/25"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mappings.3.new_cidr_block", "fd00::/64"),
+					resource.TestCheckResourceAttr("data.netcalc_renumbering_plan.test", "mapping.10.0.8.0/24", "172.16.0.0/24"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ReverseDNSZonesDataSource{}
+
+func NewReverseDNSZonesDataSource() datasource.DataSource {
+	return &ReverseDNSZonesDataSource{}
+}
+
+// ReverseDNSZonesDataSource defines the data source implementation.
+type ReverseDNSZonesDataSource struct {
+}
+
+// ReverseDNSZonesDataSourceModel describes the data source data model.
+type ReverseDNSZonesDataSourceModel struct {
+	CIDRBlock types.String `tfsdk:"cidr_block"`
+	Zones     types.List   `tfsdk:"zones"`
+	ZoneNames types.List   `tfsdk:"zone_names"`
+	Exact     types.Bool   `tfsdk:"exact"`
+	ID        types.String `tfsdk:"id"`
+}
+
+// ReverseDNSZoneModel describes a reverse DNS zone.
+type ReverseDNSZoneModel struct {
+	Name      types.String `tfsdk:"name"`
+	CIDRBlock types.String `tfsdk:"cidr_block"`
+}
+
+func (d *ReverseDNSZonesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_reverse_dns_zones"
+}
+
+func (d *ReverseDNSZonesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reverse DNS zones data source. Computes the in-addr.arpa or ip6.arpa zones needed to cover a CIDR block, e.g. to create a DNS zone resource per zone for an allocated subnet. Zones are delegated on octet boundaries for IPv4 and nibble boundaries for IPv6, so a CIDR block between boundaries needs several zones.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to cover.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"zones": schema.ListNestedAttribute{
+				MarkdownDescription: "Reverse DNS zones covering the CIDR block, in address order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Zone name, e.g. `1.0.10.in-addr.arpa`.",
+							Computed:            true,
+						},
+						"cidr_block": schema.StringAttribute{
+							MarkdownDescription: "CIDR block of the addresses in the zone.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"zone_names": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the zones, in the same order as zones.",
+				Computed:            true,
+			},
+			"exact": schema.BoolAttribute{
+				MarkdownDescription: "Whether the zones cover exactly the CIDR block. False for IPv4 CIDR blocks longer than a /24, which are part of the zone of their /24 and would need RFC 2317 classless delegation.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ReverseDNSZonesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ReverseDNSZonesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+
+	zonePrefixes := subnet.ReverseZonePrefixes(prefix)
+	zones := make([]ReverseDNSZoneModel, 0, len(zonePrefixes))
+	names := make([]string, 0, len(zonePrefixes))
+	for _, p := range zonePrefixes {
+		name := subnet.ReverseZoneName(p)
+		zones = append(zones, ReverseDNSZoneModel{
+			Name:      types.StringValue(name),
+			CIDRBlock: types.StringValue(p.String()),
+		})
+		names = append(names, name)
+	}
+	zoneList, diags := types.ListValueFrom(ctx, data.Zones.ElementType(ctx), zones)
+	resp.Diagnostics.Append(diags...)
+	data.Zones = zoneList
+	nameList, diags := types.ListValueFrom(ctx, types.StringType, names)
+	resp.Diagnostics.Append(diags...)
+	data.ZoneNames = nameList
+	data.Exact = types.BoolValue(zonePrefixes[0].Bits() >= prefix.Bits())
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a reverse DNS zones data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
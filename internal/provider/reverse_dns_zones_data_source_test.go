@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccReverseDNSZonesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_reverse_dns_zones" "ipv4" {
+					cidr_block = "10.0.0.0/23"
+				}
+				data "netcalc_reverse_dns_zones" "small" {
+					cidr_block = "10.0.1.64/26"
+				}
+				data "netcalc_reverse_dns_zones" "ipv6" {
+					cidr_block = "2001:db8:1::/48"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "id", "10.0.0.0/23"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "zones.#", "2"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "zones.0.name", "0.0.10.in-addr.arpa"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "zones.0.cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "zone_names.1", "1.0.10.in-addr.arpa"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv4", "exact", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.small", "zone_names.#", "1"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.small", "zone_names.0", "1.0.10.in-addr.arpa"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.small", "exact", "false"),
+					resource.TestCheckResourceAttr("data.netcalc_reverse_dns_zones.ipv6", "zone_names.0", "1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ReversePTRFunction{}
+
+func NewReversePTRFunction() function.Function {
+	return &ReversePTRFunction{}
+}
+
+// ReversePTRFunction defines the function implementation.
+type ReversePTRFunction struct {
+}
+
+func (f *ReversePTRFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "reverse_ptr"
+}
+
+func (f *ReversePTRFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the PTR record name of an address",
+		MarkdownDescription: "Returns the reverse DNS name of an address, e.g. `4.1.0.10.in-addr.arpa` for `10.0.1.4`, or the 32-nibble ip6.arpa name for an IPv6 address. The name has no trailing dot. Use the netcalc_reverse_dns_zones data source to find the zone the record belongs in.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "address",
+				MarkdownDescription: "IPv4 or IPv6 address, e.g. `10.0.1.4`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ReversePTRFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var address string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &address))
+	if resp.Error != nil {
+		return
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse address: %q, %v", address, err))
+		return
+	}
+
+	name := subnet.ReverseZoneName(netip.PrefixFrom(addr.WithZone(""), addr.BitLen()))
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, name))
+}
@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccReversePTRFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::reverse_ptr("10.0.1.0/24")
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+address`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4" {
+					value = provider::netcalc::reverse_ptr("10.0.1.4")
+				}
+				output "ipv6" {
+					value = provider::netcalc::reverse_ptr("2001:db8::567:89ab")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4", "4.1.0.10.in-addr.arpa"),
+					resource.TestCheckOutput("ipv6", "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"),
+				),
+			},
+		},
+	})
+}
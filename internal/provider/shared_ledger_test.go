@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderSharedLedger(t *testing.T) {
+	ledgerPath, webhookURL := newSharedLedgerFixture(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderSharedLedgerConfig("", webhookURL, true),
+				ExpectError: regexp.MustCompile(`shared_ledger\s+coordinates\s+the\s+allocations\s+of\s+provider\s+blocks\s+through\s+the\s+allocation\s+ledger,\s+but\s+no\s+ledger\s+is\s+configured`),
+			},
+			// The allocation another provider block records after this one
+			// was configured is taken into account.
+			{
+				Config: testAccProviderSharedLedgerConfig(ledgerPath, webhookURL, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.a", "cidr_block", "10.0.0.0/26"),
+					resource.TestCheckResourceAttr("netcalc_subnet.b", "cidr_block", "10.0.0.128/26"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath), "10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProviderIsolatedLedger(t *testing.T) {
+	ledgerPath, webhookURL := newSharedLedgerFixture(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Without shared_ledger, the allocation of the other provider
+			// block is only found when recording a conflicting one.
+			{
+				Config:      testAccProviderSharedLedgerConfig(ledgerPath, webhookURL, false),
+				ExpectError: regexp.MustCompile(`10.0.0.64/26\s+overlaps\s+10.0.0.64/26,\s+which\s+is\s+allocated\s+to\s+"other"`),
+			},
+		},
+	})
+}
+
+// newSharedLedgerFixture returns the path of a ledger, and the URL of a
+// webhook that simulates another provider block, such as an alias, recording
+// an allocation in the ledger right after the first allocation of this one.
+func newSharedLedgerFixture(t *testing.T) (string, string) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			if err := ledger.NewFileLedger(ledgerPath).Allocate(r.Context(), netip.MustParsePrefix("10.0.0.64/26"), "other"); err != nil {
+				t.Errorf("unable to record allocation of the other provider block: %v", err)
+			}
+		})
+	}))
+	t.Cleanup(server.Close)
+	return ledgerPath, server.URL
+}
+
+func testAccProviderSharedLedgerConfig(ledgerPath string, webhookURL string, shared bool) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/24"]
+  ledger_path      = %[1]q
+  shared_ledger    = %[3]t
+
+  webhook = {
+    url = %[2]q
+  }
+}
+
+resource "netcalc_subnet" "a" {
+  cidr_mask_length = 26
+}
+
+resource "netcalc_subnet" "b" {
+  cidr_mask_length = 26
+
+  depends_on = [netcalc_subnet.a]
+}
+`, ledgerPath, webhookURL, shared)
+}
@@ -0,0 +1,193 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ssmParametersModel describes the ssm_parameters provider attribute.
+type ssmParametersModel struct {
+	Path     types.String `tfsdk:"path"`
+	Region   types.String `tfsdk:"region"`
+	Profile  types.String `tfsdk:"profile"`
+	Endpoint types.String `tfsdk:"endpoint"`
+}
+
+func ssmParametersAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Publishes every CIDR block allocated by netcalc_subnet, netcalc_subnet_group, netcalc_subnet_pair, netcalc_dual_stack_subnet, netcalc_static_subnet and netcalc_subnets resources as a parameter in AWS Systems Manager Parameter Store, and deletes it when the CIDR block is released, so consumers outside Terraform can look up assigned CIDR blocks. The parameters are named `<path>/<owner>/<cidr>`, where `owner` is the allocation owner ID also recorded in the ledger, which stays the same until the resource is replaced, and `cidr` is the CIDR block with `/` replaced by `_` and `:` by `-`, e.g. `/network/allocations/3f2a.../10.0.1.0_24`. The value of a parameter is the CIDR block. Failed requests are reported as warnings and not retried, since the allocation has already been made. Credentials are taken from the usual AWS environment variables, shared configuration files and instance roles.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path the parameters are created under, e.g. `/network/allocations`.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^(/[a-zA-Z0-9_.-]+)+/?$`), "must be a parameter path, e.g. /network/allocations"),
+				},
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "AWS region of the parameters. Defaults to the region of the AWS configuration.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the AWS shared configuration profile to use.",
+				Optional:            true,
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "Custom Systems Manager endpoint URL.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// ssmParameters publishes every CIDR block allocated as a parameter in AWS
+// Systems Manager Parameter Store.
+type ssmParameters struct {
+	path        string
+	endpoint    string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// newSSMParameters returns the publisher configured by the ssm_parameters
+// provider attribute.
+func newSSMParameters(ctx context.Context, data ssmParametersModel, diagnostics *diag.Diagnostics) *ssmParameters {
+	cfg, err := loadAWSConfig(ctx, data.Region.ValueString(), data.Profile.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("ssm_parameters"), "SSM Parameter Store error", fmt.Sprintf("Unable to load AWS configuration: %v", err))
+		return nil
+	}
+	if cfg.Region == "" {
+		diagnostics.AddAttributeError(path.Root("ssm_parameters").AtName("region"), "SSM Parameter Store error", "No AWS region is configured. Set region or the AWS_REGION environment variable.")
+		return nil
+	}
+	p := &ssmParameters{
+		path:        strings.TrimSuffix(data.Path.ValueString(), "/"),
+		endpoint:    strings.TrimSuffix(data.Endpoint.ValueString(), "/"),
+		region:      cfg.Region,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+	if p.endpoint == "" {
+		p.endpoint = fmt.Sprintf("https://ssm.%s.amazonaws.com", cfg.Region)
+	}
+	return p
+}
+
+// parameterName returns the name of the parameter of a CIDR block allocated
+// to owner.
+func (p *ssmParameters) parameterName(owner string, prefix netip.Prefix) string {
+	return fmt.Sprintf("%s/%s/%s", p.path, owner, strings.NewReplacer("/", "_", ":", "-").Replace(prefix.String()))
+}
+
+// allocated creates or overwrites the parameter of every prefix. It does
+// nothing for a nil publisher.
+func (p *ssmParameters) allocated(ctx context.Context, resourceType string, owner string, prefixes ...netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if p == nil {
+		return diagnostics
+	}
+	for _, prefix := range prefixes {
+		name := p.parameterName(owner, prefix)
+		err := p.call(ctx, "PutParameter", map[string]any{
+			"Name":        name,
+			"Value":       prefix.String(),
+			"Type":        "String",
+			"Overwrite":   true,
+			"Description": fmt.Sprintf("Allocated by %s %s", resourceType, owner),
+		})
+		if err != nil {
+			diagnostics.AddWarning("SSM Parameter Store error", fmt.Sprintf("Unable to publish the allocation of %s as parameter %s: %v", prefix, name, err))
+			continue
+		}
+		tflog.Debug(ctx, "published SSM parameter", map[string]interface{}{"name": name, "cidr": prefix.String()})
+	}
+	return diagnostics
+}
+
+// released deletes the parameter of every prefix. It does nothing for a nil
+// publisher.
+func (p *ssmParameters) released(ctx context.Context, resourceType string, owner string, prefixes ...netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if p == nil {
+		return diagnostics
+	}
+	for _, prefix := range prefixes {
+		name := p.parameterName(owner, prefix)
+		err := p.call(ctx, "DeleteParameter", map[string]any{"Name": name})
+		if err != nil && !strings.Contains(err.Error(), "ParameterNotFound") {
+			diagnostics.AddWarning("SSM Parameter Store error", fmt.Sprintf("Unable to delete parameter %s of released %s: %v", name, prefix, err))
+			continue
+		}
+		tflog.Debug(ctx, "deleted SSM parameter", map[string]interface{}{"name": name, "cidr": prefix.String()})
+	}
+	return diagnostics
+}
+
+// call sends a signed request for an action of the Systems Manager JSON API.
+func (p *ssmParameters) call(ctx context.Context, action string, input any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM."+action)
+	credentials, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := p.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "ssm", p.region, time.Now()); err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Type != "" {
+			// The type may be prefixed with a namespace, e.g.
+			// com.amazonaws.ssm#ParameterNotFound.
+			return fmt.Errorf("%s: %s: %s", action, apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:], apiErr.Message)
+		}
+		return fmt.Errorf("%s: %s: %s", action, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// fakeSSM serves the Parameter Store requests made by the provider from
+// memory.
+type fakeSSM struct {
+	m          sync.Mutex
+	parameters map[string]string
+}
+
+func newFakeSSM(t *testing.T) (*fakeSSM, *httptest.Server) {
+	f := &fakeSSM{parameters: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeSSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential=test/") || !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/ssm/") {
+		http.Error(w, `{"__type":"UnrecognizedClientException","message":"invalid signature"}`, http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		Name      string
+		Value     string
+		Type      string
+		Overwrite bool
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSSM.PutParameter":
+		if _, ok := f.parameters[input.Name]; ok && !input.Overwrite {
+			http.Error(w, `{"__type":"ParameterAlreadyExists","message":"exists"}`, http.StatusBadRequest)
+			return
+		}
+		f.parameters[input.Name] = input.Value
+		fmt.Fprint(w, `{"Tier":"Standard","Version":1}`)
+	case "AmazonSSM.DeleteParameter":
+		if _, ok := f.parameters[input.Name]; !ok {
+			http.Error(w, `{"__type":"com.amazonaws.ssm#ParameterNotFound","message":"not found"}`, http.StatusBadRequest)
+			return
+		}
+		delete(f.parameters, input.Name)
+		fmt.Fprint(w, `{}`)
+	default:
+		http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+	}
+}
+
+// checkParameters checks that the parameters are the CIDR blocks by the
+// pattern their name matches.
+func (f *fakeSSM) checkParameters(expected map[string]string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		f.m.Lock()
+		defer f.m.Unlock()
+		if len(f.parameters) != len(expected) {
+			return fmt.Errorf("expected %d parameters, got %v", len(expected), f.parameters)
+		}
+		for pattern, cidr := range expected {
+			found := false
+			for name, value := range f.parameters {
+				found = found || regexp.MustCompile(pattern).MatchString(name) && value == cidr
+			}
+			if !found {
+				return fmt.Errorf("expected a parameter matching %s with value %s, got %v", pattern, cidr, f.parameters)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccProviderSSMParameters(t *testing.T) {
+	fake, server := newFakeSSM(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProviderSSMParametersConfig(server.URL, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "fd00::/64"
+				}`),
+				Check: fake.checkParameters(map[string]string{
+					`^/network/allocations/[0-9a-f]+/10\.0\.0\.0_24$`: "10.0.0.0/24",
+					`^/network/allocations/[0-9a-f]+/fd00--_64$`:      "fd00::/64",
+				}),
+			},
+			// Releasing allocations
+			{
+				Config: testAccProviderSSMParametersConfig(server.URL, `
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "fd00::/64"
+				}`),
+				Check: fake.checkParameters(map[string]string{
+					`^/network/allocations/[0-9a-f]+/fd00--_64$`: "fd00::/64",
+				}),
+			},
+			// Failed requests are reported without failing the apply.
+			{
+				PreConfig: func() { t.Setenv("AWS_ACCESS_KEY_ID", "wrong") },
+				Config: testAccProviderSSMParametersConfig(server.URL, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "fd00::/64"
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					fake.checkParameters(map[string]string{
+						`^/network/allocations/[0-9a-f]+/fd00--_64$`: "fd00::/64",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderSSMParametersConfig(endpoint string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16", "fd00::/48"]
+
+  ssm_parameters = {
+    path     = "/network/allocations/"
+    region   = "us-east-1"
+    endpoint = %[1]q
+  }
+}
+%[2]s
+`, endpoint, resources)
+}
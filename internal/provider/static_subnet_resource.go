@@ -0,0 +1,295 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &StaticSubnetResource{}
+var _ resource.ResourceWithImportState = &StaticSubnetResource{}
+var _ resource.ResourceWithConfigure = &StaticSubnetResource{}
+var _ resource.ResourceWithModifyPlan = &StaticSubnetResource{}
+
+func NewStaticSubnetResource() resource.Resource {
+	return &StaticSubnetResource{}
+}
+
+// StaticSubnetResource defines the resource implementation.
+type StaticSubnetResource struct {
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	utilization   *utilizationWarning
+	maskPolicy    maskLengthPolicy
+}
+
+// StaticSubnetResourceModel describes the resource data model.
+type StaticSubnetResourceModel struct {
+	CIDRBlock types.String `tfsdk:"cidr_block"`
+	PoolID    types.String `tfsdk:"pool_id"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func (r *StaticSubnetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_static_subnet"
+}
+
+func (r *StaticSubnetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Static subnet resource. Registers a hand-assigned CIDR block as allocated, so it takes part in conflict detection with the calculated subnets. Calculated subnets in the same apply should depend on the static subnet, otherwise they may be allocated first. Set the provider's `ledger_path` to detect conflicts with static subnets registered in earlier applies.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to register. It must lie within the pools and must not overlap any allocated or claimed CIDR block.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool the CIDR block must lie within instead of the provider's pool_cidr_blocks.",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, same as the cidr_block.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// ModifyPlan checks that the CIDR block lies within the pools and is allowed by
+// the mask length policy, so mistakes are reported before anything is applied.
+func (r *StaticSubnetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan StaticSubnetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.CIDRBlock.IsUnknown() || plan.PoolID.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(r.validateStaticSubnet(plan)...)
+
+	// Only CIDR blocks that are not registered yet are checked against the
+	// mask length policy.
+	var prior types.String
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cidr_block"), &prior)...)
+	}
+	if prefix, err := netip.ParsePrefix(plan.CIDRBlock.ValueString()); err == nil && !plan.CIDRBlock.Equal(prior) {
+		resp.Diagnostics.Append(r.maskPolicy.check(path.Root("cidr_block"), prefix.Addr().Is6(), int64(prefix.Bits()))...)
+	}
+}
+
+func (r *StaticSubnetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.utilization = data.utilization
+		r.maskPolicy = data.maskPolicy
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *StaticSubnetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data StaticSubnetResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	resp.Diagnostics.Append(r.validateStaticSubnet(data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	prefix := netip.MustParsePrefix(data.CIDRBlock.ValueString())
+	if err := r.calculator.ClaimPrefix(prefix, nil); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR block already allocated", fmt.Sprintf("Unable to register CIDR block: %v", err))
+		return
+	}
+
+	owner, err := newAllocationOwner()
+	if err != nil {
+		r.calculator.DeleteAllocatedPrefix(prefix)
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	if r.ledger != nil {
+		if err := r.ledger.Allocate(ctx, prefix, owner); err != nil {
+			r.calculator.DeleteAllocatedPrefix(prefix)
+			resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR block already allocated", fmt.Sprintf("Unable to record allocation of %s: %v", prefix, err))
+			return
+		}
+	}
+	resp.Diagnostics.Append(r.webhook.allocated(ctx, "netcalc_static_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_static_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.utilization.allocated(ctx, prefix)...)
+
+	data.ID = types.StringValue(prefix.String())
+	tflog.Info(ctx, "created a static subnet resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *StaticSubnetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data StaticSubnetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A static subnet cannot be moved, so a CIDR block outside the pools is
+	// only reported.
+	for _, d := range r.validateStaticSubnet(data) {
+		resp.Diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *StaticSubnetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan StaticSubnetResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the pool can change. It may have been unknown when planning, so
+	// check the CIDR block against it again.
+	resp.Diagnostics.Append(r.validateStaticSubnet(plan)...)
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *StaticSubnetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data StaticSubnetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+
+	r.calculator.ReleasePrefix(prefix)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if r.ledger != nil {
+		if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+			return
+		}
+	}
+	resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_static_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_static_subnet", owner, prefix)...)
+	tflog.Info(ctx, "deleted a static subnet resource")
+}
+
+func (r *StaticSubnetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Parse the CIDR from the ID.
+	p, err := netip.ParsePrefix(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_block"), p.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), p.String())...)
+	tflog.Info(ctx, "imported a static subnet resource")
+}
+
+// validateStaticSubnet checks that the CIDR block is a network address and
+// lies within the pools.
+func (r *StaticSubnetResource) validateStaticSubnet(data StaticSubnetResourceModel) (diagnostics diag.Diagnostics) {
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		// Reported by the attribute validator.
+		return diagnostics
+	}
+	if prefix != prefix.Masked() {
+		diagnostics.AddAttributeError(path.Root("cidr_block"), "Invalid CIDR block", fmt.Sprintf("%s is not a network address, did you mean %s?", prefix, prefix.Masked()))
+		return diagnostics
+	}
+
+	if data.PoolID.IsNull() {
+		if !r.calculator.PrefixInPools(prefix) {
+			diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR block outside the pools", fmt.Sprintf("%s does not lie within the provider's pool_cidr_blocks.", prefix))
+		}
+		return diagnostics
+	}
+	pools, reserved, err := parsePoolID(data.PoolID.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", data.PoolID.ValueString(), err))
+		return diagnostics
+	}
+	if !prefixInPool(prefix, pools, reserved) {
+		diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR block outside the pools", fmt.Sprintf("%s does not lie within the pool or overlaps one of its reserved CIDR blocks.", prefix))
+	}
+	return diagnostics
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccStaticSubnetResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The CIDR block must lie within the pools
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.1.0.0/24"
+				}`,
+				ExpectError: regexp.MustCompile(`CIDR block outside the pools`),
+			},
+			// The CIDR block must be a network address
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.0.0.1/24"
+				}`,
+				ExpectError: regexp.MustCompile(`did you mean 10.0.0.0/24`),
+			},
+			// The CIDR block must not be claimed
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/16"]
+					claimed_cidr_blocks = ["10.0.0.0/23"]
+				}
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.0.1.0/24"
+				}`,
+				ExpectError: regexp.MustCompile(`10.0.1.0/24 overlaps allocated CIDR block\s+10.0.0.0/23`),
+			},
+			// Create and Read testing, calculated subnets are allocated around
+			// the static subnet
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.0.0.0/24"
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					depends_on       = [netcalc_static_subnet.test]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_static_subnet.test", "id", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.1.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_static_subnet.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// The pool must contain the CIDR block
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+				}
+				resource "netcalc_static_subnet" "test" {
+					cidr_block = "10.0.0.0/24"
+					pool_id    = netcalc_pool.test.id
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					depends_on       = [netcalc_static_subnet.test]
+				}`,
+				ExpectError: regexp.MustCompile(`CIDR block outside the pools`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,557 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SubnetGroupResource{}
+var _ resource.ResourceWithImportState = &SubnetGroupResource{}
+var _ resource.ResourceWithConfigure = &SubnetGroupResource{}
+var _ resource.ResourceWithModifyPlan = &SubnetGroupResource{}
+
+func NewSubnetGroupResource() resource.Resource {
+	return &SubnetGroupResource{}
+}
+
+// SubnetGroupResource defines the resource implementation.
+type SubnetGroupResource struct {
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	utilization   *utilizationWarning
+	maskPolicy    maskLengthPolicy
+	pools         map[string]namedPool
+	strategy      subnet.Strategy
+	debug         bool
+}
+
+// SubnetGroupResourceModel describes the resource data model.
+type SubnetGroupResourceModel struct {
+	Keys           types.Set    `tfsdk:"keys"`
+	IPFamily       types.String `tfsdk:"ip_family"`
+	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	PoolID         types.String `tfsdk:"pool_id"`
+	Pool           types.String `tfsdk:"pool"`
+	Strategy       types.String `tfsdk:"allocation_strategy"`
+	CIDRBlocks     types.Map    `tfsdk:"cidr_blocks"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func (r *SubnetGroupResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subnet_group"
+}
+
+func (r *SubnetGroupResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Subnet group resource. Allocates one subnet per key, e.g. per availability zone or team name. Each key keeps its CIDR block for as long as it is in the group, so adding or removing keys never shifts the CIDR blocks of the other keys, unlike subnets indexed with count.",
+
+		Attributes: map[string]schema.Attribute{
+			"keys": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Keys to allocate a subnet for. Adding a key allocates a subnet for it, removing a key releases its subnet. Keys must not contain commas or equals signs.",
+				Required:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.RegexMatches(regexp.MustCompile(`^[^,=]+$`), "must not be empty or contain commas or equals signs")),
+				},
+			},
+			"ip_family": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(ipFamilyIPv4),
+				MarkdownDescription: "The IP family for the calculated addresses. Must be one of ipv4 or ipv6.",
+				Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size in bits of every subnet. e.g. if you wanted /24 networks, 24 would be the value here.",
+				Required:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks. Changing the pool only reallocates the subnets that no longer fit in it.",
+				Optional:            true,
+			},
+			"pool": schema.StringAttribute{
+				MarkdownDescription: "Name of a pool in the provider's pools to allocate from instead of the provider's pool_cidr_blocks. Conflicts with pool_id. Changing the pool only reallocates the subnets that no longer fit in it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("pool_id")),
+				},
+			},
+			"allocation_strategy": allocationStrategyAttribute(),
+			"cidr_blocks": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Calculated CIDR blocks, keyed by key.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the calculated CIDR blocks as comma separated `key=cidr_block` pairs sorted by key.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan keeps the CIDR blocks of existing keys, so only the subnets of
+// added keys, or of keys whose subnet left the pool, are unknown in the plan,
+// and checks new mask lengths against the mask length policy.
+func (r *SubnetGroupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan SubnetGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The pools are not known until the provider is configured.
+	if r.calculator != nil {
+		if _, ok := resolvePool(r.pools, plan.Pool, plan.PoolID); !ok {
+			resp.Diagnostics.Append(unknownPoolError(r.pools, plan.Pool))
+			return
+		}
+	}
+	if req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("cidr_mask_length"), plan.IPFamily, plan.CIDRMaskLength, types.StringNull(), types.Int64Null(), allocationPools(r.calculator, r.pools, plan.Pool, plan.PoolID))...)
+		return
+	}
+
+	var state SubnetGroupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("cidr_mask_length"), plan.IPFamily, plan.CIDRMaskLength, state.IPFamily, state.CIDRMaskLength, allocationPools(r.calculator, r.pools, plan.Pool, plan.PoolID))...)
+	if resp.Diagnostics.HasError() || !setKnown(plan.Keys) || plan.PoolID.IsUnknown() || plan.Pool.IsUnknown() {
+		return
+	}
+	// Changing these replaces the resource, so nothing is kept.
+	if !plan.IPFamily.Equal(state.IPFamily) || !plan.CIDRMaskLength.Equal(state.CIDRMaskLength) {
+		return
+	}
+
+	kept := r.keptSubnets(ctx, plan, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	cidrBlocks := map[string]attr.Value{}
+	allKept := true
+	for _, key := range subnetGroupKeys(plan) {
+		if prefix, ok := kept[key]; ok {
+			cidrBlocks[key] = types.StringValue(prefix.String())
+		} else {
+			cidrBlocks[key] = types.StringUnknown()
+			allKept = false
+		}
+	}
+	val, diags := types.MapValue(types.StringType, cidrBlocks)
+	resp.Diagnostics.Append(diags...)
+	plan.CIDRBlocks = val
+	plan.ID = types.StringUnknown()
+	if allKept {
+		_, plan.ID, diags = subnetMap(ctx, kept)
+		resp.Diagnostics.Append(diags...)
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *SubnetGroupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.utilization = data.utilization
+		r.maskPolicy = data.maskPolicy
+		r.pools = data.pools
+		r.strategy = data.strategy
+		r.debug = data.debug
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *SubnetGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SubnetGroupResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	owner, err := newAllocationOwner()
+	if err != nil {
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+
+	subnets := map[string]netip.Prefix{}
+	resp.Diagnostics.Append(r.allocateSubnets(ctx, data, subnets, owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setSubnetGroup(ctx, &data, subnets)...)
+	tflog.Info(ctx, "created a subnet group resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SubnetGroupResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if the CIDR blocks are still valid. Subnets that are not are dropped,
+	// so the next apply allocates new subnets for their keys only.
+	subnets := subnetGroupCIDRBlocks(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for key, prefix := range subnets {
+		inPools, diags := r.subnetInPools(data.Pool, data.PoolID, prefix)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !inPools {
+			tflog.Info(ctx, fmt.Sprintf("CIDR block %s of key %q is no longer valid; removing it in order to indicate reallocation is needed", prefix, key))
+			delete(subnets, key)
+		}
+	}
+	resp.Diagnostics.Append(setSubnetGroup(ctx, &data, subnets)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SubnetGroupResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state SubnetGroupResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Imported groups have no owner yet.
+	if owner == "" {
+		var err error
+		if owner, err = newAllocationOwner(); err != nil {
+			resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+			return
+		}
+		resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	// Release the subnets that are not kept, and make sure the kept ones are
+	// not handed out again to the added keys.
+	kept := r.keptSubnets(ctx, plan, state, &resp.Diagnostics)
+	previous := subnetGroupCIDRBlocks(ctx, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, key := range sortedKeys(previous) {
+		prefix := previous[key]
+		if kept[key] == prefix {
+			r.calculator.HoldPrefix(prefix)
+			continue
+		}
+		r.calculator.ReleasePrefix(prefix)
+		if r.ledger != nil {
+			if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+				return
+			}
+		}
+		resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_subnet_group", owner, prefix)...)
+		resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnet_group", owner, prefix)...)
+	}
+
+	resp.Diagnostics.Append(r.allocateSubnets(ctx, plan, kept, owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(setSubnetGroup(ctx, &plan, kept)...)
+	tflog.Info(ctx, "updated a subnet group resource")
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SubnetGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SubnetGroupResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	subnets := subnetGroupCIDRBlocks(ctx, data, &resp.Diagnostics)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, key := range sortedKeys(subnets) {
+		r.calculator.ReleasePrefix(subnets[key])
+		if r.ledger != nil {
+			if err := r.ledger.Release(ctx, subnets[key], owner); err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", subnets[key], err))
+				return
+			}
+		}
+		resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_subnet_group", owner, subnets[key])...)
+		resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnet_group", owner, subnets[key])...)
+	}
+	tflog.Info(ctx, "deleted a subnet group resource")
+}
+
+func (r *SubnetGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Parse the key=cidr_block pairs from the ID.
+	subnets, err := parseSubnetMapID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Unable to parse ID %q: %v", req.ID, err))
+		return
+	}
+
+	var maskLength int
+	ipFamily := ipFamilyIPv4
+	for _, p := range subnets {
+		maskLength = p.Bits()
+		if p.Addr().Is6() {
+			ipFamily = ipFamilyIPv6
+		}
+	}
+	keys, diags := types.SetValueFrom(ctx, types.StringType, sortedKeys(subnets))
+	resp.Diagnostics.Append(diags...)
+	data := SubnetGroupResourceModel{
+		Keys:           keys,
+		IPFamily:       types.StringValue(ipFamily),
+		CIDRMaskLength: types.Int64Value(int64(maskLength)),
+		PoolID:         types.StringNull(),
+		Pool:           types.StringNull(),
+		Strategy:       types.StringNull(),
+	}
+	resp.Diagnostics.Append(setSubnetGroup(ctx, &data, subnets)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a subnet group resource")
+}
+
+// allocateSubnets allocates a subnet for every key of the model that is not in
+// subnets yet, in key order. Either every subnet is allocated or none is.
+func (r *SubnetGroupResource) allocateSubnets(ctx context.Context, data SubnetGroupResourceModel, subnets map[string]netip.Prefix, owner string) (diagnostics diag.Diagnostics) {
+	ipv6 := data.IPFamily.ValueString() == ipFamilyIPv6
+	strategy := allocationStrategy(data.Strategy, r.strategy)
+	calculator := tracedCalculator(ctx, r.calculator, r.debug)
+	nextFunc := func(numBits int) (netip.Prefix, error) {
+		return calculator.NextAvailableSubnet(ctx, ipv6, numBits, strategy)
+	}
+	// The pool may be carved out of an allocation, such as a claimed
+	// supernet, which the subnets are then recorded in.
+	var nested []netip.Prefix
+	poolID, ok := resolvePool(r.pools, data.Pool, data.PoolID)
+	if !ok {
+		diagnostics.Append(unknownPoolError(r.pools, data.Pool))
+		return diagnostics
+	}
+	if !poolID.IsNull() {
+		pools, reserved, err := parsePoolID(poolID.ValueString())
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", poolID.ValueString(), err))
+			return diagnostics
+		}
+		var familyPools []netip.Prefix
+		for _, p := range pools {
+			if p.Addr().Is6() == ipv6 {
+				familyPools = append(familyPools, p)
+			}
+		}
+		nextFunc = func(numBits int) (netip.Prefix, error) {
+			return calculator.NextAvailableSubnetInPools(familyPools, reserved, numBits, strategy)
+		}
+		nested = familyPools
+	}
+
+	var added []string
+	for _, key := range subnetGroupKeys(data) {
+		if _, ok := subnets[key]; ok {
+			continue
+		}
+		next, err := nextFunc(int(data.CIDRMaskLength.ValueInt64()))
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("keys"), "CIDR calculation error", fmt.Sprintf("Unable to calculate next available CIDR for key %q: %v", key, err))
+			break
+		}
+		subnets[key] = next
+		added = append(added, key)
+	}
+	if !diagnostics.HasError() {
+		var prefixes []netip.Prefix
+		for _, key := range added {
+			prefixes = append(prefixes, subnets[key])
+		}
+		diagnostics.Append(recordAllocations(ctx, r.ledger, owner, nested, prefixes...)...)
+	}
+	if diagnostics.HasError() {
+		// Release the subnets that were allocated so a failed apply allocates
+		// nothing.
+		for _, key := range added {
+			r.calculator.DeleteAllocatedPrefix(subnets[key])
+			delete(subnets, key)
+		}
+		return diagnostics
+	}
+	for _, key := range added {
+		diagnostics.Append(r.webhook.allocated(ctx, "netcalc_subnet_group", owner, subnets[key])...)
+		diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_subnet_group", owner, subnets[key])...)
+		diagnostics.Append(r.utilization.allocated(ctx, subnets[key])...)
+	}
+	return diagnostics
+}
+
+// keptSubnets returns the subnets in state whose keys are still planned and
+// that still fit in the planned pools.
+func (r *SubnetGroupResource) keptSubnets(ctx context.Context, plan SubnetGroupResourceModel, state SubnetGroupResourceModel, diagnostics *diag.Diagnostics) map[string]netip.Prefix {
+	subnets := subnetGroupCIDRBlocks(ctx, state, diagnostics)
+	planned := map[string]bool{}
+	for _, key := range subnetGroupKeys(plan) {
+		planned[key] = true
+	}
+	for key, prefix := range subnets {
+		inPools, diags := r.subnetInPools(plan.Pool, plan.PoolID, prefix)
+		diagnostics.Append(diags...)
+		if !planned[key] || !inPools {
+			delete(subnets, key)
+		}
+	}
+	return subnets
+}
+
+// subnetInPools reports whether a subnet fits in the named pool or the pool
+// with the given ID, or in the provider's pools when no pool is set.
+func (r *SubnetGroupResource) subnetInPools(pool types.String, id types.String, prefix netip.Prefix) (bool, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	// A named pool that was removed from the provider no longer contains
+	// the subnet.
+	poolID, ok := resolvePool(r.pools, pool, id)
+	if !ok {
+		return false, diagnostics
+	}
+	if poolID.IsNull() {
+		return r.calculator.PrefixInPools(prefix), diagnostics
+	}
+	pools, reserved, err := parsePoolID(poolID.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", poolID.ValueString(), err))
+		return false, diagnostics
+	}
+	return prefixInPool(prefix, pools, reserved), diagnostics
+}
+
+// subnetGroupKeys returns the model's keys in sorted order.
+func subnetGroupKeys(data SubnetGroupResourceModel) []string {
+	keys := map[string]bool{}
+	for _, elem := range data.Keys.Elements() {
+		if key, ok := elem.(types.String); ok {
+			keys[key.ValueString()] = true
+		}
+	}
+	return sortedKeys(keys)
+}
+
+// subnetGroupCIDRBlocks parses the model's CIDR blocks.
+func subnetGroupCIDRBlocks(ctx context.Context, data SubnetGroupResourceModel, diagnostics *diag.Diagnostics) map[string]netip.Prefix {
+	var cidrBlocks map[string]string
+	diagnostics.Append(data.CIDRBlocks.ElementsAs(ctx, &cidrBlocks, false)...)
+	subnets := map[string]netip.Prefix{}
+	for key, cidr := range cidrBlocks {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidr, err))
+			continue
+		}
+		subnets[key] = prefix
+	}
+	return subnets
+}
+
+// setSubnetGroup saves the allocated subnets into the model.
+func setSubnetGroup(ctx context.Context, data *SubnetGroupResourceModel, subnets map[string]netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	data.CIDRBlocks, data.ID, diagnostics = subnetMap(ctx, subnets)
+	return diagnostics
+}
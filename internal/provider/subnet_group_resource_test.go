@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccSubnetGroupResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Keys are used in the ID, so they must not contain separators
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet_group" "test" {
+					keys             = ["a=b"]
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`must not be empty or contain commas or equals\s+signs`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet_group" "test" {
+					keys             = ["b", "c"]
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.%", "2"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.b", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.c", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "id", "b=10.0.0.0/24,c=10.0.1.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_subnet_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Adding a key before the others and removing one keeps the
+			// remaining key's CIDR block
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet_group" "test" {
+					keys             = ["a", "c"]
+					cidr_mask_length = 24
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet_group.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("netcalc_subnet_group.test", tfjsonpath.New("cidr_blocks").AtMapKey("a")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.%", "2"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.a", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.c", "10.0.1.0/24"),
+				),
+			},
+			// Moving to a pool only reallocates the subnets outside of it
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_pool" "test" {
+					cidr_blocks          = ["10.0.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+				}
+				resource "netcalc_subnet_group" "test" {
+					keys             = ["a", "c"]
+					cidr_mask_length = 24
+					pool_id          = netcalc_pool.test.id
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet_group.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.a", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_group.test", "cidr_blocks.c", "10.0.1.0/24"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &SubnetInfoDataSource{}
+
+func NewSubnetInfoDataSource() datasource.DataSource {
+	return &SubnetInfoDataSource{}
+}
+
+// SubnetInfoDataSource defines the data source implementation.
+type SubnetInfoDataSource struct {
+}
+
+// SubnetInfoDataSourceModel describes the data source data model.
+type SubnetInfoDataSourceModel struct {
+	CIDRBlock        types.String `tfsdk:"cidr_block"`
+	IPFamily         types.String `tfsdk:"ip_family"`
+	PrefixLength     types.Int64  `tfsdk:"prefix_length"`
+	NetworkAddress   types.String `tfsdk:"network_address"`
+	Netmask          types.String `tfsdk:"netmask"`
+	WildcardMask     types.String `tfsdk:"wildcard_mask"`
+	BroadcastAddress types.String `tfsdk:"broadcast_address"`
+	FirstUsableHost  types.String `tfsdk:"first_usable_host"`
+	LastUsableHost   types.String `tfsdk:"last_usable_host"`
+	HostCount        types.Number `tfsdk:"host_count"`
+	IsPrivate        types.Bool   `tfsdk:"is_private"`
+	ID               types.String `tfsdk:"id"`
+}
+
+func (d *SubnetInfoDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subnet_info"
+}
+
+func (d *SubnetInfoDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Subnet info data source. Describes any CIDR block, replacing ad-hoc math with the cidr* functions.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "CIDR block to describe. Host bits are ignored, e.g. `10.0.1.5/24` is described as `10.0.1.0/24`.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "IP family of the CIDR block, either ipv4 or ipv6.",
+				Computed:            true,
+			},
+			"prefix_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size in bits.",
+				Computed:            true,
+			},
+			"network_address": schema.StringAttribute{
+				MarkdownDescription: "First address of the CIDR block.",
+				Computed:            true,
+			},
+			"netmask": schema.StringAttribute{
+				MarkdownDescription: "Netmask in address form, e.g. `255.255.255.0`.",
+				Computed:            true,
+			},
+			"wildcard_mask": schema.StringAttribute{
+				MarkdownDescription: "Inverse of the netmask, e.g. `0.0.0.255`, as used by ACLs.",
+				Computed:            true,
+			},
+			"broadcast_address": schema.StringAttribute{
+				MarkdownDescription: "Last address of an IPv4 CIDR block. Null for IPv6, which has no broadcast address.",
+				Computed:            true,
+			},
+			"first_usable_host": schema.StringAttribute{
+				MarkdownDescription: "First usable host address. The network and broadcast addresses of IPv4 CIDR blocks larger than a /31 are not usable.",
+				Computed:            true,
+			},
+			"last_usable_host": schema.StringAttribute{
+				MarkdownDescription: "Last usable host address.",
+				Computed:            true,
+			},
+			"host_count": schema.NumberAttribute{
+				MarkdownDescription: "Number of usable host addresses.",
+				Computed:            true,
+			},
+			"is_private": schema.BoolAttribute{
+				MarkdownDescription: "Whether the CIDR block lies entirely within private address space: RFC 1918 for IPv4, unique local addresses (RFC 4193) for IPv6.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *SubnetInfoDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SubnetInfoDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+	hosts := subnet.HostRange(prefix)
+
+	data.IPFamily = types.StringValue(ipFamilyIPv4)
+	data.BroadcastAddress = types.StringValue(subnet.LastAddr(prefix).String())
+	if prefix.Addr().Is6() {
+		data.IPFamily = types.StringValue(ipFamilyIPv6)
+		data.BroadcastAddress = types.StringNull()
+	}
+	data.PrefixLength = types.Int64Value(int64(prefix.Bits()))
+	data.NetworkAddress = types.StringValue(prefix.Addr().String())
+	data.Netmask = types.StringValue(subnet.Netmask(prefix).String())
+	data.WildcardMask = types.StringValue(subnet.WildcardMask(prefix).String())
+	data.FirstUsableHost = types.StringValue(hosts.Start.String())
+	data.LastUsableHost = types.StringValue(hosts.End.String())
+	data.HostCount = types.NumberValue(new(big.Float).SetInt(subnet.HostCount(prefix)))
+	data.IsPrivate = types.BoolValue(prefix.Addr().IsPrivate() && subnet.LastAddr(prefix).IsPrivate())
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a subnet info data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSubnetInfoDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_subnet_info" "ipv4" {
+					cidr_block = "10.0.1.5/24"
+				}
+				data "netcalc_subnet_info" "ipv6" {
+					cidr_block = "2001:db8::/126"
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "id", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "ip_family", "ipv4"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "prefix_length", "24"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "network_address", "10.0.1.0"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "netmask", "255.255.255.0"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "wildcard_mask", "0.0.0.255"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "broadcast_address", "10.0.1.255"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "first_usable_host", "10.0.1.1"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "last_usable_host", "10.0.1.254"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "host_count", "254"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv4", "is_private", "true"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "ip_family", "ipv6"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "netmask", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc"),
+					resource.TestCheckNoResourceAttr("data.netcalc_subnet_info.ipv6", "broadcast_address"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "first_usable_host", "2001:db8::"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "last_usable_host", "2001:db8::3"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "host_count", "4"),
+					resource.TestCheckResourceAttr("data.netcalc_subnet_info.ipv6", "is_private", "false"),
+				),
+			},
+		},
+	})
+}
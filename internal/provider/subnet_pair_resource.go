@@ -0,0 +1,428 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SubnetPairResource{}
+var _ resource.ResourceWithImportState = &SubnetPairResource{}
+var _ resource.ResourceWithConfigure = &SubnetPairResource{}
+var _ resource.ResourceWithModifyPlan = &SubnetPairResource{}
+
+func NewSubnetPairResource() resource.Resource {
+	return &SubnetPairResource{}
+}
+
+// SubnetPairResource defines the resource implementation.
+type SubnetPairResource struct {
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	utilization   *utilizationWarning
+	maskPolicy    maskLengthPolicy
+	strategy      subnet.Strategy
+	debug         bool
+}
+
+// SubnetPairResourceModel describes the resource data model.
+type SubnetPairResourceModel struct {
+	PoolIDs        types.Map    `tfsdk:"pool_ids"`
+	IPFamily       types.String `tfsdk:"ip_family"`
+	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	Strategy       types.String `tfsdk:"allocation_strategy"`
+	CIDRBlocks     types.Map    `tfsdk:"cidr_blocks"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func (r *SubnetPairResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subnet_pair"
+}
+
+func (r *SubnetPairResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Subnet pair resource. Allocates one subnet from each of several named pools, e.g. one per availability zone, and manages them as a unit: either every subnet is allocated or none is, and they are replaced and destroyed together.",
+
+		Attributes: map[string]schema.Attribute{
+			"pool_ids": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "IDs of the netcalc_pool resources to allocate from, keyed by name. Changing the pools only causes a new allocation when a subnet no longer fits in its pool or the names change.",
+				Required:            true,
+				Validators:          []validator.Map{mapvalidator.SizeAtLeast(1)},
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplaceIf(poolsNoLongerContainCIDRs, "Calculated CIDR blocks no longer fall within their pools, new CIDRs will be calculated.", ""),
+				},
+			},
+			"ip_family": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(ipFamilyIPv4),
+				MarkdownDescription: "The IP family for the calculated addresses. Must be one of ipv4 or ipv6.",
+				Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size in bits of every subnet. e.g. if you wanted /24 networks, 24 would be the value here.",
+				Required:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"allocation_strategy": allocationStrategyAttribute(),
+			"cidr_blocks": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Calculated CIDR blocks, keyed by pool name.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the calculated CIDR blocks as comma separated `name=cidr_block` pairs sorted by name.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan checks new mask lengths against the mask length policy, so they
+// are rejected before anything is applied.
+func (r *SubnetPairResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state SubnetPairResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pools [][]netip.Prefix
+	for _, elem := range plan.PoolIDs.Elements() {
+		if poolID, ok := elem.(types.String); ok && !poolID.IsUnknown() {
+			prefixes, _, err := parsePoolID(poolID.ValueString())
+			if err == nil {
+				pools = append(pools, prefixes)
+			}
+		}
+	}
+	resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("cidr_mask_length"), plan.IPFamily, plan.CIDRMaskLength, state.IPFamily, state.CIDRMaskLength, pools...)...)
+}
+
+func (r *SubnetPairResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.utilization = data.utilization
+		r.maskPolicy = data.maskPolicy
+		r.strategy = data.strategy
+		r.debug = data.debug
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *SubnetPairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SubnetPairResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	var poolIDs map[string]string
+	resp.Diagnostics.Append(data.PoolIDs.ElementsAs(ctx, &poolIDs, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Allocate in name order so the result does not depend on map iteration.
+	ipv6 := data.IPFamily.ValueString() == ipFamilyIPv6
+	strategy := allocationStrategy(data.Strategy, r.strategy)
+	subnets := map[string]netip.Prefix{}
+	for _, name := range sortedKeys(poolIDs) {
+		pools, reserved, err := parsePoolID(poolIDs[name])
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_ids").AtMapKey(name), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", poolIDs[name], err))
+			break
+		}
+		var familyPools []netip.Prefix
+		for _, p := range pools {
+			if p.Addr().Is6() == ipv6 {
+				familyPools = append(familyPools, p)
+			}
+		}
+		next, err := tracedCalculator(ctx, r.calculator, r.debug).NextAvailableSubnetInPools(familyPools, reserved, int(data.CIDRMaskLength.ValueInt64()), strategy)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_ids").AtMapKey(name), "CIDR calculation error", fmt.Sprintf("Unable to calculate next available CIDR in pool %q: %v", name, err))
+			break
+		}
+		subnets[name] = next
+	}
+	if resp.Diagnostics.HasError() {
+		// Release the subnets that were allocated so a failed pair allocates nothing.
+		for _, prefix := range subnets {
+			r.calculator.DeleteAllocatedPrefix(prefix)
+		}
+		return
+	}
+
+	owner, err := newAllocationOwner()
+	if err != nil {
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	var prefixes []netip.Prefix
+	for _, name := range sortedKeys(subnets) {
+		prefixes = append(prefixes, subnets[name])
+	}
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, nil, prefixes...)...)
+	if resp.Diagnostics.HasError() {
+		for _, prefix := range subnets {
+			r.calculator.DeleteAllocatedPrefix(prefix)
+		}
+		return
+	}
+	for _, name := range sortedKeys(subnets) {
+		resp.Diagnostics.Append(r.webhook.allocated(ctx, "netcalc_subnet_pair", owner, subnets[name])...)
+		resp.Diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_subnet_pair", owner, subnets[name])...)
+		resp.Diagnostics.Append(r.utilization.allocated(ctx, subnets[name])...)
+	}
+
+	resp.Diagnostics.Append(setSubnetPair(ctx, &data, subnets)...)
+	tflog.Info(ctx, "created a subnet pair resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetPairResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SubnetPairResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// See if the CIDR blocks are still valid. Imported resources have no
+	// pools in state until their next apply, so they cannot be checked yet.
+	if !data.PoolIDs.IsNull() && !subnetPairInPools(ctx, data.PoolIDs, data.CIDRBlocks) {
+		tflog.Info(ctx, "CIDR blocks are no longer valid; removing resource in order to indicate replacement is needed")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetPairResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SubnetPairResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state SubnetPairResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	// Set state values. Reallocation is modeled as a replacement, so nothing
+	// is reallocated here.
+	plan.CIDRBlocks = state.CIDRBlocks
+	plan.ID = state.ID
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SubnetPairResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SubnetPairResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	var cidrBlocks map[string]string
+	resp.Diagnostics.Append(data.CIDRBlocks.ElementsAs(ctx, &cidrBlocks, false)...)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, name := range sortedKeys(cidrBlocks) {
+		prefix, err := netip.ParsePrefix(cidrBlocks[name])
+		if err != nil {
+			resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", cidrBlocks[name], err))
+			return
+		}
+		r.calculator.ReleasePrefix(prefix)
+		if r.ledger != nil {
+			if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+				return
+			}
+		}
+		resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_subnet_pair", owner, prefix)...)
+		resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnet_pair", owner, prefix)...)
+	}
+	tflog.Info(ctx, "deleted a subnet pair resource")
+}
+
+func (r *SubnetPairResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Parse the name=cidr_block pairs from the ID.
+	subnets, err := parseSubnetMapID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Unable to parse ID %q: %v", req.ID, err))
+		return
+	}
+
+	var maskLength int
+	ipFamily := ipFamilyIPv4
+	for _, p := range subnets {
+		maskLength = p.Bits()
+		if p.Addr().Is6() {
+			ipFamily = ipFamilyIPv6
+		}
+	}
+	data := SubnetPairResourceModel{
+		PoolIDs:        types.MapNull(types.StringType),
+		IPFamily:       types.StringValue(ipFamily),
+		CIDRMaskLength: types.Int64Value(int64(maskLength)),
+	}
+	resp.Diagnostics.Append(setSubnetPair(ctx, &data, subnets)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a subnet pair resource")
+}
+
+// poolsNoLongerContainCIDRs requires replacement when the pool names change or
+// a subnet does not fit in its newly planned pool.
+func poolsNoLongerContainCIDRs(ctx context.Context, req planmodifier.MapRequest, resp *mapplanmodifier.RequiresReplaceIfFuncResponse) {
+	var cidrBlocks types.Map
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cidr_blocks"), &cidrBlocks)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.RequiresReplace = !subnetPairInPools(ctx, req.PlanValue, cidrBlocks)
+}
+
+// subnetPairInPools reports whether the pools are known, have the same names
+// as the subnets, and contain their subnets.
+func subnetPairInPools(ctx context.Context, poolIDs types.Map, cidrBlocks types.Map) bool {
+	if poolIDs.IsUnknown() || len(poolIDs.Elements()) != len(cidrBlocks.Elements()) {
+		return false
+	}
+	pools := poolIDs.Elements()
+	for name, cidr := range cidrBlocks.Elements() {
+		poolID, ok := pools[name].(types.String)
+		cidrBlock, _ := cidr.(types.String)
+		if !ok || !poolContainsCIDR(poolID, cidrBlock) {
+			return false
+		}
+	}
+	return true
+}
+
+// setSubnetPair saves the allocated subnets into the model.
+func setSubnetPair(ctx context.Context, data *SubnetPairResourceModel, subnets map[string]netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	data.CIDRBlocks, data.ID, diagnostics = subnetMap(ctx, subnets)
+	return diagnostics
+}
+
+// subnetMap returns subnets keyed by name as a map value, and an ID made up of
+// comma separated name=cidr_block pairs sorted by name.
+func subnetMap(ctx context.Context, subnets map[string]netip.Prefix) (types.Map, types.String, diag.Diagnostics) {
+	cidrBlocks := map[string]string{}
+	var pairs []string
+	for _, name := range sortedKeys(subnets) {
+		cidrBlocks[name] = subnets[name].String()
+		pairs = append(pairs, name+"="+subnets[name].String())
+	}
+	val, diagnostics := types.MapValueFrom(ctx, types.StringType, cidrBlocks)
+	return val, types.StringValue(strings.Join(pairs, ",")), diagnostics
+}
+
+// parseSubnetMapID parses an ID created by subnetMap.
+func parseSubnetMapID(id string) (map[string]netip.Prefix, error) {
+	subnets := map[string]netip.Prefix{}
+	for _, pair := range strings.Split(id, ",") {
+		name, cidr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected comma separated name=cidr_block pairs")
+		}
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		subnets[name] = p
+	}
+	return subnets, nil
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccSubnetPairResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Every pool must have room for a subnet
+			{
+				Config: `
+				resource "netcalc_subnet_pair" "test" {
+					pool_ids = {
+						a = "10.0.0.0/16"
+						b = "10.1.0.0/24,!10.1.0.0/25"
+					}
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`Unable to calculate next available CIDR in pool "b"`),
+			},
+			// A pool too small for the subnets is rejected when planning.
+			{
+				Config: `
+				resource "netcalc_subnet_pair" "test" {
+					pool_ids = {
+						a = "10.0.0.0/16"
+						b = "10.1.0.0/25"
+					}
+					cidr_mask_length = 24
+				}`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`A\s+/24\s+ipv4\s+subnet\s+does\s+not\s+fit\s+in\s+its\s+pool,\s+whose\s+largest\s+CIDR\s+block\s+is\s+10.1.0.0/25`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				resource "netcalc_pool" "a" {
+					cidr_blocks          = ["10.0.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+				}
+				resource "netcalc_pool" "b" {
+					cidr_blocks = ["10.1.0.0/16"]
+				}
+				resource "netcalc_subnet_pair" "test" {
+					pool_ids = {
+						a = netcalc_pool.a.id
+						b = netcalc_pool.b.id
+					}
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.%", "2"),
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.a", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.b", "10.1.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "id", "a=10.0.1.0/24,b=10.1.0.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "netcalc_subnet_pair.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"pool_ids"},
+			},
+			// Adding a pool replaces the whole set
+			{
+				Config: `
+				resource "netcalc_pool" "a" {
+					cidr_blocks          = ["10.0.0.0/16"]
+					reserved_cidr_blocks = ["10.0.0.0/24"]
+				}
+				resource "netcalc_pool" "b" {
+					cidr_blocks = ["10.1.0.0/16"]
+				}
+				resource "netcalc_pool" "c" {
+					cidr_blocks = ["10.2.0.0/16"]
+				}
+				resource "netcalc_subnet_pair" "test" {
+					pool_ids = {
+						a = netcalc_pool.a.id
+						b = netcalc_pool.b.id
+						c = netcalc_pool.c.id
+					}
+					cidr_mask_length = 24
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet_pair.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.%", "3"),
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.a", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_pair.test", "cidr_blocks.c", "10.2.0.0/24"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
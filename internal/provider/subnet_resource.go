@@ -6,11 +6,15 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
@@ -25,6 +29,7 @@ import (
 var _ resource.Resource = &SubnetResource{}
 var _ resource.ResourceWithImportState = &SubnetResource{}
 var _ resource.ResourceWithConfigure = &SubnetResource{}
+var _ resource.ResourceWithModifyPlan = &SubnetResource{}
 
 func NewSubnetResource() resource.Resource {
 	return &SubnetResource{}
@@ -32,13 +37,27 @@ func NewSubnetResource() resource.Resource {
 
 // SubnetResource defines the resource implementation.
 type SubnetResource struct {
-	calculator SubnetCalculator
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	utilization   *utilizationWarning
+	maskPolicy    maskLengthPolicy
+	pools         map[string]namedPool
+	strategy      subnet.Strategy
+	debug         bool
 }
 
 // SubnetResourceModel describes the resource data model.
 type SubnetResourceModel struct {
 	IPFamily       types.String `tfsdk:"ip_family"`
 	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	MinHosts       types.Int64  `tfsdk:"min_hosts"`
+	Locked         types.Bool   `tfsdk:"locked"`
+	PoolID         types.String `tfsdk:"pool_id"`
+	Pool           types.String `tfsdk:"pool"`
+	Strategy       types.String `tfsdk:"allocation_strategy"`
 	CIDRBlock      types.String `tfsdk:"cidr_block"`
 	ID             types.String `tfsdk:"id"`
 }
@@ -69,37 +88,188 @@ func (r *SubnetResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"cidr_mask_length": schema.Int64Attribute{
-				MarkdownDescription: "Network size in bits. e.g. if you wanted a /27 network, 27 would be the value here.",
-				Required:            true,
+				MarkdownDescription: "Network size in bits. e.g. if you wanted a /27 network, 27 would be the value here. Exactly one of cidr_mask_length or min_hosts must be set.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.Int64{
+					int64validator.ExactlyOneOf(path.MatchRoot("min_hosts")),
+				},
 				PlanModifiers: []planmodifier.Int64{
+					minHostsMaskLengthModifier{},
 					int64planmodifier.RequiresReplace(),
 				},
 			},
+			"min_hosts": schema.Int64Attribute{
+				MarkdownDescription: "Minimum number of usable host addresses. The smallest subnet of the chosen IP family that fits this many hosts is allocated, accounting for the network and broadcast addresses of IPv4 subnets.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"locked": schema.BoolAttribute{
+				MarkdownDescription: "Protects the allocated CIDR block. While the subnet is locked, any plan that would destroy the subnet or reallocate its CIDR block fails. Set to false and apply before making such changes.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"pool_id": schema.StringAttribute{
+				MarkdownDescription: "ID of a netcalc_pool to allocate from instead of the provider's pool_cidr_blocks. Changing the pool only causes a new allocation when the CIDR block no longer fits in it.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(poolNoLongerContainsCIDR, "Calculated CIDR block no longer falls within the pool, new CIDR will be calculated.", ""),
+				},
+			},
+			"pool": schema.StringAttribute{
+				MarkdownDescription: "Name of a pool in the provider's pools to allocate from instead of the provider's pool_cidr_blocks. Conflicts with pool_id. Changing the pool only causes a new allocation when the CIDR block no longer fits in it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("pool_id")),
+				},
+			},
+			"allocation_strategy": allocationStrategyAttribute(),
 			"cidr_block": schema.StringAttribute{
 				MarkdownDescription: "Calculated CIDR block.",
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"id": schema.StringAttribute{
 				MarkdownDescription: "Resource ID, same as the calculated cidr_block.",
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
+// minHostsMaskLengthModifier plans cidr_mask_length from min_hosts when the
+// mask length is not configured directly.
+type minHostsMaskLengthModifier struct {
+}
+
+func (m minHostsMaskLengthModifier) Description(ctx context.Context) string {
+	return "Calculates the mask length from min_hosts when cidr_mask_length is not configured."
+}
+
+func (m minHostsMaskLengthModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m minHostsMaskLengthModifier) PlanModifyInt64(ctx context.Context, req planmodifier.Int64Request, resp *planmodifier.Int64Response) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var minHosts types.Int64
+	var ipFamily types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("min_hosts"), &minHosts)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ip_family"), &ipFamily)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if minHosts.IsNull() || minHosts.IsUnknown() || ipFamily.IsUnknown() {
+		return
+	}
+
+	maskLength, err := subnet.MaskLengthForHosts(uint64(minHosts.ValueInt64()), ipFamily.ValueString() == ipFamilyIPv6)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("min_hosts"), "Invalid host count", fmt.Sprintf("Unable to calculate a mask length for %d hosts: %v", minHosts.ValueInt64(), err))
+		return
+	}
+	resp.PlanValue = types.Int64Value(int64(maskLength))
+}
+
+var _ planmodifier.Int64 = minHostsMaskLengthModifier{}
+
+func (r *SubnetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() && req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan SubnetResourceModel
+	if !req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// The pools are not known until the provider is configured.
+		if r.calculator != nil {
+			if _, ok := resolvePool(r.pools, plan.Pool, plan.PoolID); !ok {
+				resp.Diagnostics.Append(unknownPoolError(r.pools, plan.Pool))
+				return
+			}
+		}
+	}
+
+	// Nothing is allocated yet when the subnet is being created.
+	if req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("cidr_mask_length"), plan.IPFamily, plan.CIDRMaskLength, types.StringNull(), types.Int64Null(), allocationPools(r.calculator, r.pools, plan.Pool, plan.PoolID))...)
+		return
+	}
+
+	var state SubnetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(r.maskPolicy.checkPlanned(path.Root("cidr_mask_length"), plan.IPFamily, plan.CIDRMaskLength, state.IPFamily, state.CIDRMaskLength, allocationPools(r.calculator, r.pools, plan.Pool, plan.PoolID))...)
+	}
+
+	if req.Plan.Raw.IsNull() {
+		if state.Locked.ValueBool() {
+			resp.Diagnostics.AddError(
+				"Subnet is locked",
+				fmt.Sprintf("Destroying this subnet would release the locked CIDR block %s. Set locked = false and apply before destroying it.", state.CIDRBlock.ValueString()),
+			)
+		}
+		return
+	}
+
+	reallocates := !plan.IPFamily.Equal(state.IPFamily) || !plan.CIDRMaskLength.Equal(state.CIDRMaskLength) || !plan.CIDRBlock.Equal(state.CIDRBlock)
+	if !plan.PoolID.Equal(state.PoolID) && !plan.PoolID.IsNull() {
+		reallocates = reallocates || !poolContainsCIDR(plan.PoolID, state.CIDRBlock)
+	}
+	// Like pool_id, moving to another named pool only reallocates the CIDR
+	// block when it does not fit in the new pool.
+	if !plan.Pool.Equal(state.Pool) && !plan.Pool.IsNull() {
+		poolID, _ := resolvePool(r.pools, plan.Pool, plan.PoolID)
+		if !poolContainsCIDR(poolID, state.CIDRBlock) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("pool"))
+			reallocates = true
+		}
+	}
+	if reallocates && state.Locked.ValueBool() {
+		resp.Diagnostics.AddError(
+			"Subnet is locked",
+			fmt.Sprintf("This plan would reallocate the locked CIDR block %s. Set locked = false and apply before changing ip_family, cidr_mask_length, min_hosts, pool_id or pool.", state.CIDRBlock.ValueString()),
+		)
+	}
+}
+
 func (r *SubnetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	switch calc := req.ProviderData.(type) {
-	case SubnetCalculator:
-		r.calculator = calc
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.utilization = data.utilization
+		r.maskPolicy = data.maskPolicy
+		r.pools = data.pools
+		r.strategy = data.strategy
+		r.debug = data.debug
 	case nil:
 		return
 	default:
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected SubnetCalculator, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 	}
 }
@@ -113,10 +283,42 @@ func (r *SubnetResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(r.calculateSubnet(&data)...)
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	resp.Diagnostics.Append(r.calculateSubnet(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Record the allocation under a new owner so later reads can tell
+	// whether another system has claimed the CIDR block.
+	prefix := parsePrefix(data.CIDRBlock, &resp.Diagnostics)
+	owner, err := newAllocationOwner()
+	if err != nil {
+		r.calculator.DeleteAllocatedPrefix(prefix)
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	// The pool may be carved out of an allocation, such as a claimed
+	// supernet, which the subnet is then recorded in.
+	var nested []netip.Prefix
+	if poolID, ok := resolvePool(r.pools, data.Pool, data.PoolID); ok && !poolID.IsNull() {
+		nested, _, _ = parsePoolID(poolID.ValueString())
+	}
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, nested, prefix)...)
+	if resp.Diagnostics.HasError() {
+		r.calculator.DeleteAllocatedPrefix(prefix)
+		return
+	}
+	resp.Diagnostics.Append(r.webhook.allocated(ctx, "netcalc_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.utilization.allocated(ctx, prefix)...)
 
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
@@ -126,11 +328,34 @@ func (r *SubnetResource) Create(ctx context.Context, req resource.CreateRequest,
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *SubnetResource) calculateSubnet(plan *SubnetResourceModel) (diagnostics diag.Diagnostics) {
+func (r *SubnetResource) calculateSubnet(ctx context.Context, plan *SubnetResourceModel) (diagnostics diag.Diagnostics) {
 	cidrMaskLength := int(plan.CIDRMaskLength.ValueInt64())
-	nextFunc := r.calculator.NextAvailableIPv4Subnet
-	if plan.IPFamily.ValueString() == ipFamilyIPv6 {
-		nextFunc = r.calculator.NextAvailableIPv6Subnet
+	ipv6 := plan.IPFamily.ValueString() == ipFamilyIPv6
+	strategy := allocationStrategy(plan.Strategy, r.strategy)
+	calculator := tracedCalculator(ctx, r.calculator, r.debug)
+	nextFunc := func(numBits int) (netip.Prefix, error) {
+		return calculator.NextAvailableSubnet(ctx, ipv6, numBits, strategy)
+	}
+	poolID, ok := resolvePool(r.pools, plan.Pool, plan.PoolID)
+	if !ok {
+		diagnostics.Append(unknownPoolError(r.pools, plan.Pool))
+		return diagnostics
+	}
+	if !poolID.IsNull() {
+		pools, reserved, err := parsePoolID(poolID.ValueString())
+		if err != nil {
+			diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", poolID.ValueString(), err))
+			return diagnostics
+		}
+		var familyPools []netip.Prefix
+		for _, p := range pools {
+			if p.Addr().Is6() == ipv6 {
+				familyPools = append(familyPools, p)
+			}
+		}
+		nextFunc = func(numBits int) (netip.Prefix, error) {
+			return calculator.NextAvailableSubnetInPools(familyPools, reserved, numBits, strategy)
+		}
 	}
 	next, err := nextFunc(cidrMaskLength)
 	if err != nil {
@@ -155,16 +380,68 @@ func (r *SubnetResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	// See if the CIDR blocks are still valid
-	p := parsePrefix(data.CIDRBlock, resp.Diagnostics)
+	p := parsePrefix(data.CIDRBlock, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if !r.calculator.PrefixInPools(p) {
+	inPools := r.calculator.PrefixInPools(p)
+	// A named pool that was removed from the provider no longer contains
+	// the CIDR block.
+	poolID, ok := resolvePool(r.pools, data.Pool, data.PoolID)
+	if !ok {
+		inPools = false
+	} else if !poolID.IsNull() {
+		pools, reserved, err := parsePoolID(poolID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("pool_id"), "Invalid pool ID", fmt.Sprintf("Unable to parse pool ID %q: %v", poolID.ValueString(), err))
+			return
+		}
+		inPools = prefixInPool(p, pools, reserved)
+	}
+	if !inPools {
+		if data.Locked.ValueBool() {
+			resp.Diagnostics.AddWarning(
+				"Locked CIDR block is outside the pools",
+				fmt.Sprintf("The locked CIDR block %s is no longer in any pool. It is kept as is; set locked = false to allow a new CIDR block to be allocated.", data.CIDRBlock.ValueString()),
+			)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			return
+		}
 		tflog.Info(ctx, "CIDR block is no longer valid; removing resource in order to indicate replacement is needed")
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
+	// See if another system has claimed the CIDR block in the ledger.
+	if r.ledger != nil {
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ledgerOwner, ok, err := ledger.Owner(ctx, r.ledger, p)
+		if err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to look up allocation of %s: %v", p, err))
+			return
+		}
+		// Imported subnets have no owner, so they cannot be checked.
+		if owner != "" && ok && ledgerOwner != owner {
+			if data.Locked.ValueBool() {
+				resp.Diagnostics.AddError(
+					"Locked CIDR block claimed elsewhere",
+					fmt.Sprintf("The locked CIDR block %s is registered to %q in the ledger. Resolve the conflict, or set locked = false to allow a new CIDR block to be allocated.", p, ledgerOwner),
+				)
+				return
+			}
+			resp.Diagnostics.AddWarning(
+				"CIDR block claimed elsewhere",
+				fmt.Sprintf("The CIDR block %s is registered to %q in the ledger. The subnet will be replaced with a new allocation.", p, ledgerOwner),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -196,15 +473,79 @@ func (r *SubnetResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	prefix := parsePrefix(data.CIDRBlock, resp.Diagnostics)
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
 
-	r.calculator.DeleteAllocatedPrefix(prefix)
+	prefix := parsePrefix(data.CIDRBlock, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.calculator.ReleasePrefix(prefix)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if r.ledger != nil {
+		if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+			return
+		}
+	}
+	resp.Diagnostics.Append(r.webhook.released(ctx, "netcalc_subnet", owner, prefix)...)
+	resp.Diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnet", owner, prefix)...)
 	tflog.Info(ctx, "deleted a subnet resource")
 }
 
+// poolNoLongerContainsCIDR requires replacement when the subnet's CIDR block
+// does not fit in the newly planned pool.
+func poolNoLongerContainsCIDR(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	// Going back to the provider's pools is checked when the subnet is read.
+	if req.PlanValue.IsNull() {
+		return
+	}
+
+	var cidrBlock types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cidr_block"), &cidrBlock)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.RequiresReplace = !poolContainsCIDR(req.PlanValue, cidrBlock)
+}
+
+// poolContainsCIDR reports whether the pool with the given ID is known and
+// contains the CIDR block.
+func poolContainsCIDR(poolID types.String, cidrBlock types.String) bool {
+	if poolID.IsUnknown() {
+		return false
+	}
+	pools, reserved, err := parsePoolID(poolID.ValueString())
+	if err != nil {
+		return false
+	}
+	p, err := netip.ParsePrefix(cidrBlock.ValueString())
+	if err != nil {
+		return false
+	}
+	return prefixInPool(p, pools, reserved)
+}
+
+// prefixInPool reports whether a prefix fits in a pool without touching any of
+// its reserved CIDR blocks.
+func prefixInPool(prefix netip.Prefix, pools []netip.Prefix, reserved []netip.Prefix) bool {
+	if !prefixInAny(prefix, pools) {
+		return false
+	}
+	for _, r := range reserved {
+		if r.Overlaps(prefix) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *SubnetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Parse the CIDR from the ID.
 	p, err := netip.ParsePrefix(req.ID)
@@ -223,6 +564,7 @@ func (r *SubnetResource) ImportState(ctx context.Context, req resource.ImportSta
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_block"), cidrBlock)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ip_family"), ipFamily)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_mask_length"), maskLength)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("locked"), types.BoolValue(false))...)
 
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 	tflog.Info(ctx, "imported a resource")
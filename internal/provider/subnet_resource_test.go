@@ -4,6 +4,7 @@
 package provider
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -343,4 +344,167 @@ func TestAccSubnetResource(t *testing.T) {
 			},
 		},
 	})
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// cidr_mask_length and min_hosts are mutually exclusive
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet" "ipv4" {
+					cidr_mask_length = 24
+					min_hosts        = 254
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			// Size subnets by host count
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+				}
+				resource "netcalc_subnet" "ipv4" {
+					min_hosts = 254
+				}
+				resource "netcalc_subnet" "ipv6" {
+					ip_family = "ipv6"
+					min_hosts = 65536
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_mask_length", "24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_mask_length", "112"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv6", "cidr_block", "fd18:fad4:bce5:4400::/112"),
+				),
+			},
+			// Changing min_hosts without changing the mask length keeps the allocation
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+				}
+				resource "netcalc_subnet" "ipv4" {
+					min_hosts = 200
+				}
+				resource "netcalc_subnet" "ipv6" {
+					ip_family = "ipv6"
+					min_hosts = 65536
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_mask_length", "24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_block", "10.0.0.0/24"),
+				),
+			},
+			// Needing a larger subnet causes recalculation
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+				}
+				resource "netcalc_subnet" "ipv4" {
+					min_hosts = 255
+				}
+				resource "netcalc_subnet" "ipv6" {
+					ip_family = "ipv6"
+					min_hosts = 65536
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_mask_length", "23"),
+					resource.TestCheckResourceAttr("netcalc_subnet.ipv4", "cidr_block", "10.0.0.0/23"),
+				),
+			},
+		},
+	})
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create a locked subnet
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					locked           = true
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "locked", "true"),
+				),
+			},
+			// Reallocating a locked subnet fails
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 25
+					locked           = true
+				}`,
+				ExpectError: regexp.MustCompile(`Subnet is locked`),
+			},
+			// Destroying a locked subnet fails
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}`,
+				ExpectError: regexp.MustCompile(`Subnet is locked`),
+			},
+			// A locked subnet outside the pools is kept
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["192.168.0.0/16"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+					locked           = true
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+				),
+			},
+			// Unlocking keeps the allocation
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "locked", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubnetResourceExhausted(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/24"]
+					claimed_cidr_blocks = ["10.0.0.0/25"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`,
+				ExpectError: regexp.MustCompile(`pool\s+10.0.0.0/24\s+is\s+50.0%\s+used;\s+the\s+largest\s+free\s+CIDR\s+block\s+is\s+10.0.0.128/25,\s+so\s+a\s+subnet\s+with\s+mask\s+/25\s+or\s+longer\s+would\s+still\s+fit`),
+			},
+		},
+	})
 }
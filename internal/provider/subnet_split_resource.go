@@ -0,0 +1,224 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SubnetSplitResource{}
+var _ resource.ResourceWithImportState = &SubnetSplitResource{}
+var _ resource.ResourceWithValidateConfig = &SubnetSplitResource{}
+var _ resource.ResourceWithModifyPlan = &SubnetSplitResource{}
+
+// maxSplitBits limits a split to 65536 child subnets.
+const maxSplitBits = 16
+
+func NewSubnetSplitResource() resource.Resource {
+	return &SubnetSplitResource{}
+}
+
+// SubnetSplitResource defines the resource implementation.
+type SubnetSplitResource struct {
+}
+
+// SubnetSplitResourceModel describes the resource data model.
+type SubnetSplitResourceModel struct {
+	CIDRBlock      types.String `tfsdk:"cidr_block"`
+	CIDRMaskLength types.Int64  `tfsdk:"cidr_mask_length"`
+	CIDRBlocks     types.List   `tfsdk:"cidr_blocks"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func (r *SubnetSplitResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subnet_split"
+}
+
+func (r *SubnetSplitResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Subnet split resource. Splits a parent CIDR block into equal child subnets. The split is deterministic and does not use the provider's pools, so it suits parent CIDR blocks allocated elsewhere, e.g. by AWS IPAM.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Parent CIDR block to split.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Mask length of the child subnets. e.g. 24 to split the parent into /24 networks. At most %d bits longer than the parent's mask length.", maxSplitBits),
+				Required:            true,
+			},
+			"cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Child subnets in address order.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, the parent CIDR block and child mask length separated by a comma, e.g. `10.0.0.0/16,24`.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *SubnetSplitResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SubnetSplitResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.CIDRBlock.IsUnknown() || data.CIDRMaskLength.IsUnknown() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		// Reported by the attribute validator.
+		return
+	}
+	resp.Diagnostics.Append(checkSplit(prefix, data.CIDRMaskLength.ValueInt64())...)
+}
+
+// ModifyPlan plans the child subnets, which only depend on the configuration.
+func (r *SubnetSplitResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan SubnetSplitResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.CIDRBlock.IsUnknown() || plan.CIDRMaskLength.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(splitSubnet(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *SubnetSplitResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SubnetSplitResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(splitSubnet(ctx, &data)...)
+	tflog.Info(ctx, "created a subnet split resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetSplitResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SubnetSplitResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubnetSplitResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SubnetSplitResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(splitSubnet(ctx, &plan)...)
+	tflog.Info(ctx, "updated a subnet split resource")
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SubnetSplitResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "deleted a subnet split resource")
+}
+
+func (r *SubnetSplitResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	cidrBlock, mask, ok := strings.Cut(req.ID, ",")
+	if !ok {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Expected an ID in the form cidr_block,cidr_mask_length, got: %q", req.ID))
+		return
+	}
+	if _, err := netip.ParsePrefix(cidrBlock); err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+		return
+	}
+	maskLength, err := strconv.ParseInt(mask, 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ID", fmt.Sprintf("Unable to parse mask length from ID: %q, %v", req.ID, err))
+		return
+	}
+
+	data := SubnetSplitResourceModel{
+		CIDRBlock:      types.StringValue(cidrBlock),
+		CIDRMaskLength: types.Int64Value(maskLength),
+	}
+	resp.Diagnostics.Append(splitSubnet(ctx, &data)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	tflog.Info(ctx, "imported a subnet split resource")
+}
+
+// splitSubnet calculates the child subnets and ID of a split.
+func splitSubnet(ctx context.Context, data *SubnetSplitResourceModel) (diagnostics diag.Diagnostics) {
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return diagnostics
+	}
+	// The parent may have been unknown during validation, or come from an
+	// import ID, so the number of subnets is checked before splitting.
+	diagnostics.Append(checkSplit(prefix, data.CIDRMaskLength.ValueInt64())...)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+	subnets, err := subnet.SplitPrefix(prefix, int(data.CIDRMaskLength.ValueInt64()))
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "Invalid mask length", fmt.Sprintf("Unable to split %s: %v", prefix, err))
+		return diagnostics
+	}
+
+	cidrBlocks, diags := types.ListValueFrom(ctx, types.StringType, prefixStrings(subnets))
+	diagnostics.Append(diags...)
+	data.CIDRBlocks = cidrBlocks
+	data.ID = types.StringValue(fmt.Sprintf("%s,%d", data.CIDRBlock.ValueString(), data.CIDRMaskLength.ValueInt64()))
+	return diagnostics
+}
+
+// checkSplit checks that a prefix can be split into subnets of a mask length,
+// and that there are at most 2^maxSplitBits of them.
+func checkSplit(prefix netip.Prefix, maskLength int64) (diagnostics diag.Diagnostics) {
+	if maskLength < int64(prefix.Bits()) || maskLength > int64(prefix.Addr().BitLen()) {
+		diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "Invalid mask length", fmt.Sprintf("Mask length must be between %d and %d to split %s, got: %d.", prefix.Bits(), prefix.Addr().BitLen(), prefix, maskLength))
+		return diagnostics
+	}
+	if maskLength-int64(prefix.Bits()) > maxSplitBits {
+		diagnostics.AddAttributeError(path.Root("cidr_mask_length"), "Too many subnets", fmt.Sprintf("Splitting %s into /%d networks would create more than %d subnets.", prefix, maskLength, 1<<maxSplitBits))
+	}
+	return diagnostics
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccSubnetSplitResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The child mask length must fit in the parent
+			{
+				Config: `
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = "10.0.0.0/22"
+					cidr_mask_length = 21
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid mask length`),
+			},
+			{
+				Config: `
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = "10.0.0.0/8"
+					cidr_mask_length = 32
+				}`,
+				ExpectError: regexp.MustCompile(`Too many subnets`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = "10.0.0.0/22"
+					cidr_mask_length = 24
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "id", "10.0.0.0/22,24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "cidr_blocks.#", "4"),
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "cidr_blocks.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "cidr_blocks.3", "10.0.3.0/24"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "netcalc_subnet_split.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: `
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = "fd18:fad4:bce5:4400::/56"
+					cidr_mask_length = 64
+				}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnet_split.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "cidr_blocks.#", "256"),
+					resource.TestCheckResourceAttr("netcalc_subnet_split.test", "cidr_blocks.255", "fd18:fad4:bce5:44ff::/64"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccSubnetSplitResourceTooManySubnets(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A parent unknown until apply is checked when it is split.
+			{
+				Config: `
+				resource "terraform_data" "parent" {
+					input = "::/0"
+				}
+
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = terraform_data.parent.output
+					cidr_mask_length = 128
+				}`,
+				ExpectError: regexp.MustCompile(`Splitting\s+::/0\s+into\s+/128\s+networks\s+would\s+create\s+more\s+than`),
+			},
+			// So is the parent of an import ID.
+			{
+				Config: `
+				resource "netcalc_subnet_split" "test" {
+					cidr_block       = "10.0.0.0/22"
+					cidr_mask_length = 24
+				}`,
+				ResourceName:  "netcalc_subnet_split.test",
+				ImportState:   true,
+				ImportStateId: "::/0,128",
+				ExpectError:   regexp.MustCompile(`Too\s+many\s+subnets`),
+			},
+		},
+	})
+}
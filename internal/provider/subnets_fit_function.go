@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SubnetsFitFunction{}
+
+func NewSubnetsFitFunction() function.Function {
+	return &SubnetsFitFunction{}
+}
+
+// SubnetsFitFunction defines the function implementation.
+type SubnetsFitFunction struct {
+}
+
+func (f *SubnetsFitFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "subnets_fit"
+}
+
+func (f *SubnetsFitFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns how many subnets fit in a pool",
+		MarkdownDescription: "Returns how many subnets of the given mask length fit in a pool, e.g. 16 /24s in a /20. The count is 0 when the mask length is shorter than that of the pool. Counts are exact in expressions, but counts above 2^53 may be rounded when stored in state or shown as outputs. Use the netcalc_capacity data source to take allocated subnets into account.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "pool",
+				MarkdownDescription: "CIDR block to divide, e.g. `10.0.0.0/20`.",
+			},
+			function.Int64Parameter{
+				Name:                "cidr_mask_length",
+				MarkdownDescription: "Mask length of the subnets, e.g. `24`.",
+			},
+		},
+		Return: function.NumberReturn{},
+	}
+}
+
+func (f *SubnetsFitFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var pool string
+	var maskLength int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &pool, &maskLength))
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, funcErr := parsePrefixArgument(0, pool)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	if maskLength < 0 || maskLength > int64(prefix.Addr().BitLen()) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Mask length /%d must be between /0 and /%d", maskLength, prefix.Addr().BitLen()))
+		return
+	}
+
+	count := subnet.CountSubnets([]netip.Prefix{prefix}, int(maskLength))
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, new(big.Float).SetInt(count)))
+}
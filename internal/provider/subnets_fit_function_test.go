@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSubnetsFitFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Argument validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::subnets_fit("10.0.0.0/33", 24)
+				}`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+parse\s+CIDR`),
+			},
+			// Mask length validation
+			{
+				Config: `
+				output "invalid" {
+					value = provider::netcalc::subnets_fit("10.0.0.0/16", 33)
+				}`,
+				ExpectError: regexp.MustCompile(`Mask\s+length\s+/33\s+must\s+be\s+between\s+/0\s+and\s+/32`),
+			},
+			// Function testing
+			{
+				Config: `
+				output "ipv4" {
+					value = provider::netcalc::subnets_fit("10.0.0.0/20", 24)
+				}
+				output "same" {
+					value = provider::netcalc::subnets_fit("10.0.0.0/20", 20)
+				}
+				output "larger" {
+					value = provider::netcalc::subnets_fit("10.0.0.0/20", 16)
+				}
+				output "ipv6" {
+					value = provider::netcalc::subnets_fit("fd00::/48", 64)
+				}
+				output "ipv6_large" {
+					value = provider::netcalc::subnets_fit("::/0", 64) == tonumber("18446744073709551616")
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ipv4", "16"),
+					resource.TestCheckOutput("same", "1"),
+					resource.TestCheckOutput("larger", "0"),
+					resource.TestCheckOutput("ipv6", "65536"),
+					resource.TestCheckOutput("ipv6_large", "true"),
+				),
+			},
+		},
+	})
+}
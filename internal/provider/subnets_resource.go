@@ -6,10 +6,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/netip"
 	"strings"
 
+	"github.com/geezyx/subnet-calculator/internal/ledger"
 	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -17,7 +21,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -26,6 +31,8 @@ import (
 var _ resource.Resource = &SubnetsResource{}
 var _ resource.ResourceWithImportState = &SubnetsResource{}
 var _ resource.ResourceWithConfigure = &SubnetsResource{}
+var _ resource.ResourceWithValidateConfig = &SubnetsResource{}
+var _ resource.ResourceWithModifyPlan = &SubnetsResource{}
 
 func NewSubnetsResource() resource.Resource {
 	return &SubnetsResource{}
@@ -33,18 +40,38 @@ func NewSubnetsResource() resource.Resource {
 
 // SubnetsResource defines the resource implementation.
 type SubnetsResource struct {
+	calculator    SubnetCalculator
+	ledger        ledger.Ledger
+	lock          *allocationLock
+	webhook       *webhook
+	ssmParameters *ssmParameters
+	// maxCIDRCount is the largest cidr_count allowed, or zero before the
+	// provider is configured.
+	maxCIDRCount int64
+	// claimedOutsidePools is what happens to existing_cidr_blocks outside
+	// the pools.
+	claimedOutsidePools subnet.AllocationOutsidePools
 }
 
 // SubnetsResourceModel describes the resource data model.
 type SubnetsResourceModel struct {
 	PoolCIDRBlocks     types.Set    `tfsdk:"pool_cidr_blocks"`
 	ExistingCIDRBlocks types.Set    `tfsdk:"existing_cidr_blocks"`
+	IPFamily           types.String `tfsdk:"ip_family"`
 	CIDRMaskLength     types.Int64  `tfsdk:"cidr_mask_length"`
+	IPv6CIDRMaskLength types.Int64  `tfsdk:"ipv6_cidr_mask_length"`
 	CIDRCount          types.Int64  `tfsdk:"cidr_count"`
 	CIDRBlocks         types.List   `tfsdk:"cidr_blocks"`
+	IPv6CIDRBlocks     types.List   `tfsdk:"ipv6_cidr_blocks"`
+	RemainingCount     types.Int64  `tfsdk:"remaining_count"`
 	ID                 types.String `tfsdk:"id"`
+
+	// ExistingCIDRBlocksOverlap is one of the existingOverlap values.
+	ExistingCIDRBlocksOverlap types.String `tfsdk:"existing_cidr_blocks_overlap"`
 }
 
+const ipFamilyDual = "dual"
+
 func (r *SubnetsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_subnets"
 }
@@ -59,25 +86,41 @@ func (r *SubnetsResource) Schema(ctx context.Context, req resource.SchemaRequest
 				ElementType:         types.StringType,
 				MarkdownDescription: "Set of CIDR blocks from which to select an available subnet.",
 				Required:            true,
-				PlanModifiers: []planmodifier.Set{
-					setplanmodifier.RequiresReplaceIf(r.AvailableCIDRBlocksNoLongerContainsResourceCIDR, "Calculated CIDR block no longer falls within the available CIDR blocks, new CIDR will be calculated.", ""),
-				},
 			},
 			"existing_cidr_blocks": schema.SetAttribute{
 				ElementType:         types.StringType,
 				MarkdownDescription: "Set of CIDR blocks which are already in use.",
 				Optional:            true,
 			},
+			"existing_cidr_blocks_overlap": existingOverlapAttribute(),
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "The IP family for the calculated addresses. Must be one of ipv4, ipv6 or dual. When set, pool_cidr_blocks and existing_cidr_blocks may mix IPv4 and IPv6 CIDR blocks. When unset, the family is taken from pool_cidr_blocks, which must then all be the same family, as must existing_cidr_blocks.",
+				Optional:            true,
+				Computed:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(ipFamilyIPv4, ipFamilyIPv6, ipFamilyDual)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"cidr_mask_length": schema.Int64Attribute{
-				MarkdownDescription: "Network size in bits. e.g. if you wanted a /27 network, 27 would be the value here.",
+				MarkdownDescription: "Network size in bits. e.g. if you wanted a /27 network, 27 would be the value here. When ip_family is dual, this is the size of the IPv4 networks.",
 				Required:            true,
 				PlanModifiers: []planmodifier.Int64{
 					int64planmodifier.RequiresReplace(),
 				},
 			},
+			"ipv6_cidr_mask_length": schema.Int64Attribute{
+				MarkdownDescription: "Network size in bits of the IPv6 networks. Required when ip_family is dual, and not allowed otherwise.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
 			"cidr_count": schema.Int64Attribute{
-				MarkdownDescription: "Number of CIDR blocks to provision",
+				MarkdownDescription: "Number of CIDR blocks to provision. At most the provider's `max_cidr_count`.",
 				Required:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(1)},
 				PlanModifiers: []planmodifier.Int64{
 					int64planmodifier.RequiresReplace(),
 				},
@@ -90,8 +133,20 @@ func (r *SubnetsResource) Schema(ctx context.Context, req resource.SchemaRequest
 					listplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"ipv6_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Calculated IPv6 CIDR blocks when ip_family is dual.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"remaining_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of additional subnets of cidr_mask_length that still fit in the pools after this resource's allocations. When ip_family is dual, this is the number of IPv4 and IPv6 subnet pairs that still fit.",
+				Computed:            true,
+			},
 			"id": schema.StringAttribute{
-				MarkdownDescription: "Resource ID, same as the calculated cidr_blocks.",
+				MarkdownDescription: "Resource ID, same as the calculated cidr_blocks followed by any ipv6_cidr_blocks.",
 				Computed:            true,
 			},
 		},
@@ -99,6 +154,83 @@ func (r *SubnetsResource) Schema(ctx context.Context, req resource.SchemaRequest
 }
 
 func (r *SubnetsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// The subnets are calculated from the resource's own pools, so the
+	// provider's calculator is only used for releases.
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+		r.webhook = data.webhook
+		r.ssmParameters = data.ssmParameters
+		r.maxCIDRCount = data.maxCIDRCount
+		r.claimedOutsidePools = data.claimedOutsidePools
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *SubnetsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SubnetsResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.IPFamily.IsUnknown() || data.IPv6CIDRMaskLength.IsUnknown() {
+		return
+	}
+
+	dual := data.IPFamily.ValueString() == ipFamilyDual
+	if dual && data.IPv6CIDRMaskLength.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv6_cidr_mask_length"), "Missing IPv6 mask length", "ipv6_cidr_mask_length is required when ip_family is dual.")
+	}
+	if !dual && !data.IPv6CIDRMaskLength.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("ipv6_cidr_mask_length"), "Unexpected IPv6 mask length", "ipv6_cidr_mask_length can only be set when ip_family is dual.")
+	}
+
+	// Mask lengths must fit in the addresses of their IP family and in the
+	// pools. Parsing errors are reported when the plan is made.
+	if !setKnown(data.PoolCIDRBlocks) || data.CIDRMaskLength.IsUnknown() {
+		return
+	}
+	var pools []netip.Prefix
+	for _, elem := range data.PoolCIDRBlocks.Elements() {
+		if cidr, ok := elem.(types.String); ok {
+			if prefix, err := netip.ParsePrefix(cidr.ValueString()); err == nil {
+				pools = append(pools, prefix.Masked())
+			}
+		}
+	}
+	ipv6 := data.IPFamily.ValueString() == ipFamilyIPv6
+	if data.IPFamily.IsNull() && len(pools) > 0 {
+		ipv6 = pools[0].Addr().Is6()
+		// Without ip_family, every CIDR block must be of the family of the
+		// pools. The family is kept in state once known, so this is only
+		// checked against the configuration.
+		for _, pool := range pools {
+			if pool.Addr().Is6() != ipv6 {
+				resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "IP family mismatch", fmt.Sprintf("CIDR block %q is not expected IP family. Set ip_family to mix IPv4 and IPv6 CIDR blocks.", pool))
+			}
+		}
+		if setKnown(data.ExistingCIDRBlocks) {
+			for _, elem := range data.ExistingCIDRBlocks.Elements() {
+				cidr, ok := elem.(types.String)
+				if !ok {
+					continue
+				}
+				if prefix, err := netip.ParsePrefix(cidr.ValueString()); err == nil && prefix.Addr().Is6() != ipv6 {
+					resp.Diagnostics.AddAttributeError(path.Root("existing_cidr_blocks"), "IP family mismatch", fmt.Sprintf("CIDR block %q is not expected IP family. Set ip_family to mix IPv4 and IPv6 CIDR blocks.", prefix))
+				}
+			}
+		}
+	}
+	resp.Diagnostics.Append(checkMaskLengthFits(path.Root("cidr_mask_length"), ipv6, data.CIDRMaskLength.ValueInt64(), pools)...)
+	if dual && !data.IPv6CIDRMaskLength.IsNull() {
+		resp.Diagnostics.Append(checkMaskLengthFits(path.Root("ipv6_cidr_mask_length"), true, data.IPv6CIDRMaskLength.ValueInt64(), pools)...)
+	}
 }
 
 func (r *SubnetsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -110,38 +242,85 @@ func (r *SubnetsResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
 	// Load CIDR blocks into calculator.
 	calculator := subnet.NewCalculator()
 	family := r.LoadCIDRBlocks(ctx, data, calculator, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(r.addUnavailable(ctx, calculator)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if (family == modeV4 || family == modeDual) && calculator.IPv4Pools.Len() == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "No IPv4 pools", "An IPv4 subnet was requested, but pool_cidr_blocks does not contain any IPv4 CIDR blocks.")
+	}
+	if (family == modeV6 || family == modeDual) && calculator.IPv6Pools.Len() == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "No IPv6 pools", "An IPv6 subnet was requested, but pool_cidr_blocks does not contain any IPv6 CIDR blocks.")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	cidrMaskLength := int(data.CIDRMaskLength.ValueInt64())
-	var calculatedCIDRs []types.String
-	var cidrStrings []string
-	for i := int64(0); i < data.CIDRCount.ValueInt64(); i++ {
-		calc := calculator.NextAvailableIPv4Subnet
-		if family == modeV6 {
-			calc = calculator.NextAvailableIPv6Subnet
-		}
-		next, err := calc(cidrMaskLength)
-		if err != nil {
-			resp.Diagnostics.AddError("CIDR calculation error", fmt.Sprintf("Unable to calculate next available CIDR: %v", err))
-			return
-		}
-		calculatedCIDRs = append(calculatedCIDRs, types.StringValue(next.String()))
-		cidrStrings = append(cidrStrings, next.String())
+	calc := calculator.NextAvailableIPv4Subnet
+	if family == modeV6 {
+		calc = calculator.NextAvailableIPv6Subnet
+	}
+	cidrStrings := calculateSubnets(ctx, calc, int(data.CIDRMaskLength.ValueInt64()), data.CIDRCount.ValueInt64(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Save the calculated CIDR blocks into the Terraform state.
-	val, diagnostics := types.ListValueFrom(ctx, types.StringType, calculatedCIDRs)
+	val, diagnostics := types.ListValueFrom(ctx, types.StringType, cidrStrings)
 	resp.Diagnostics.Append(diagnostics...)
 	data.CIDRBlocks = val
 
+	data.IPv6CIDRBlocks = types.ListNull(types.StringType)
+	if family == modeDual {
+		ipv6CIDRStrings := calculateSubnets(ctx, calculator.NextAvailableIPv6Subnet, int(data.IPv6CIDRMaskLength.ValueInt64()), data.CIDRCount.ValueInt64(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		val, diagnostics := types.ListValueFrom(ctx, types.StringType, ipv6CIDRStrings)
+		resp.Diagnostics.Append(diagnostics...)
+		data.IPv6CIDRBlocks = val
+		cidrStrings = append(cidrStrings, ipv6CIDRStrings...)
+	}
+	data.RemainingCount = remainingCount(calculator, family, data)
+	data.IPFamily = types.StringValue(family.String())
+
 	// Set the ID
 	data.ID = types.StringValue(strings.Join(cidrStrings, ","))
 
+	// Record the allocations under a new owner, as for netcalc_subnet.
+	owner, err := newAllocationOwner()
+	if err != nil {
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	var allocated []netip.Prefix
+	for _, cidr := range cidrStrings {
+		allocated = append(allocated, netip.MustParsePrefix(cidr))
+	}
+	// The pools may be carved out of an allocation, such as a claimed
+	// supernet, which the allocations are then recorded in.
+	pools := parsePrefixSet(ctx, data.PoolCIDRBlocks, &resp.Diagnostics)
+	resp.Diagnostics.Append(recordAllocations(ctx, r.ledger, owner, pools, allocated...)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.webhook.allocated(ctx, "netcalc_subnets", owner, allocated...)...)
+	resp.Diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_subnets", owner, allocated...)...)
+
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
 	tflog.Info(ctx, "created a resource")
@@ -150,6 +329,68 @@ func (r *SubnetsResource) Create(ctx context.Context, req resource.CreateRequest
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// addUnavailable marks the CIDR blocks that the provider knows to be in use
+// as allocated in the calculator of the resource.
+func (r *SubnetsResource) addUnavailable(ctx context.Context, calculator *subnet.Calculator) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	// Allocations recorded in the ledger by other resources are unavailable.
+	if r.ledger != nil {
+		entries, err := r.ledger.Entries(ctx)
+		if err != nil {
+			diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+			return diagnostics
+		}
+		// Allocations elsewhere than the pools of the resource do not
+		// affect it.
+		var prefixes []netip.Prefix
+		for _, prefix := range activePrefixes(entries) {
+			if prefixOverlapsAny(prefix, append(calculator.Pools(false), calculator.Pools(true)...)) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		holdPrefixes(calculator, prefixes)
+	}
+	// So are CIDR blocks released and held during the run.
+	for _, prefix := range r.calculator.ReleasedPrefixes() {
+		calculator.HoldPrefix(prefix)
+	}
+	// Reserved CIDR blocks of the provider apply to every pool.
+	for _, prefix := range r.calculator.ReservedPrefixes() {
+		calculator.AddReservedPrefix(prefix)
+	}
+	return diagnostics
+}
+
+// calculateSubnets allocates count subnets of the given mask length using calc.
+func calculateSubnets(ctx context.Context, calc func(context.Context, int) (netip.Prefix, error), maskLength int, count int64, diagnostics *diag.Diagnostics) []string {
+	var cidrStrings []string
+	for i := int64(0); i < count; i++ {
+		next, err := calc(ctx, maskLength)
+		if err != nil {
+			diagnostics.AddError("CIDR calculation error", fmt.Sprintf("Unable to calculate next available CIDR: %v", err))
+			return nil
+		}
+		cidrStrings = append(cidrStrings, next.String())
+	}
+	return cidrStrings
+}
+
+// remainingCount returns how many more subnets of the requested size fit in the
+// calculator's pools, capped at the largest value Terraform numbers can hold here.
+func remainingCount(calculator *subnet.Calculator, family mode, data SubnetsResourceModel) types.Int64 {
+	count := calculator.AvailableSubnetCount(family == modeV6, int(data.CIDRMaskLength.ValueInt64()))
+	if family == modeDual {
+		ipv6Count := calculator.AvailableSubnetCount(true, int(data.IPv6CIDRMaskLength.ValueInt64()))
+		if ipv6Count.Cmp(count) < 0 {
+			count = ipv6Count
+		}
+	}
+	if !count.IsInt64() {
+		return types.Int64Value(math.MaxInt64)
+	}
+	return types.Int64Value(count.Int64())
+}
+
 func (r *SubnetsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data SubnetsResourceModel
 
@@ -160,6 +401,66 @@ func (r *SubnetsResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
+	// See if the CIDR blocks are still available. Those overlapping a
+	// reserved CIDR block of the provider, or registered to another owner in
+	// the ledger, are replaced with new allocations.
+	allocated := append(parsePrefixList(data.CIDRBlocks, &resp.Diagnostics), parsePrefixList(data.IPv6CIDRBlocks, &resp.Diagnostics)...)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	owners := make(map[netip.Prefix]string)
+	if r.ledger != nil {
+		entries, err := r.ledger.Entries(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+			return
+		}
+		for _, e := range entries {
+			if e.Active() {
+				owners[e.CIDR] = e.Owner
+			}
+		}
+	}
+	replace := false
+	reserved := r.calculator.ReservedPrefixes()
+check:
+	for _, cidr := range allocated {
+		for _, prefix := range reserved {
+			if prefix.Overlaps(cidr) {
+				tflog.Info(ctx, fmt.Sprintf("CIDR block %s overlaps reserved CIDR block %s; removing resource in order to indicate replacement is needed", cidr, prefix))
+				replace = true
+				break check
+			}
+		}
+		// Imported resources have no owner, so they cannot be checked.
+		if ledgerOwner, ok := owners[cidr]; owner != "" && ok && ledgerOwner != owner {
+			resp.Diagnostics.AddWarning(
+				"CIDR block claimed elsewhere",
+				fmt.Sprintf("The CIDR block %s is registered to %q in the ledger. The resource will be replaced with new allocations.", cidr, ledgerOwner),
+			)
+			replace = true
+			break
+		}
+	}
+	if replace {
+		// Delete does not run for a removed resource, so the CIDR blocks it
+		// still holds in the ledger are released here.
+		var held []netip.Prefix
+		for _, cidr := range allocated {
+			if ledgerOwner, ok := owners[cidr]; owner != "" && ok && ledgerOwner == owner {
+				held = append(held, cidr)
+			}
+		}
+		resp.Diagnostics.Append(r.release(ctx, owner, held)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -172,23 +473,236 @@ func (r *SubnetsResource) Update(ctx context.Context, req resource.UpdateRequest
 	var state SubnetsResourceModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Load CIDR blocks into calculator.
+	plan.CIDRBlocks = state.CIDRBlocks
+	plan.IPv6CIDRBlocks = state.IPv6CIDRBlocks
 	calculator := subnet.NewCalculator()
-	r.LoadCIDRBlocks(ctx, plan, calculator, &resp.Diagnostics)
+	family := r.LoadCIDRBlocks(ctx, plan, calculator, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Set state values.
-	plan.CIDRBlocks = state.CIDRBlocks
+	// CIDR blocks outside the pools that remain are replaced by CIDR blocks
+	// allocated from them, keeping the others.
+	cidrs := parsePrefixList(state.CIDRBlocks, &resp.Diagnostics)
+	ipv6CIDRs := parsePrefixList(state.IPv6CIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	plan.ID = state.ID
+	reallocate := len(prefixesOutsidePools(calculator, append(cidrs, ipv6CIDRs...))) > 0
+	if reallocate {
+		unlock, diags := r.lock.acquire(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+	}
+	// The CIDR blocks in use elsewhere count for remaining_count as well, as
+	// on create.
+	resp.Diagnostics.Append(r.addUnavailable(ctx, calculator)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if reallocate {
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		cidrs = r.reallocate(ctx, calculator, cidrs, int(plan.CIDRMaskLength.ValueInt64()), owner, &resp.Diagnostics)
+		if family == modeDual {
+			ipv6CIDRs = r.reallocate(ctx, calculator, ipv6CIDRs, int(plan.IPv6CIDRMaskLength.ValueInt64()), owner, &resp.Diagnostics)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		val, diagnostics := prefixList(ctx, cidrs)
+		resp.Diagnostics.Append(diagnostics...)
+		plan.CIDRBlocks = val
+		if family == modeDual {
+			val, diagnostics := prefixList(ctx, ipv6CIDRs)
+			resp.Diagnostics.Append(diagnostics...)
+			plan.IPv6CIDRBlocks = val
+		}
+		plan.ID = types.StringValue(strings.Join(prefixStrings(append(cidrs, ipv6CIDRs...)), ","))
+	}
+
+	// Set state values.
+	plan.RemainingCount = remainingCount(calculator, family, plan)
+	plan.IPFamily = types.StringValue(family.String())
 	tflog.Info(ctx, "updated a resource")
 
 	// Save updated data into Terraform state.
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
+// prefixesOutsidePools returns the prefixes that are not within the pools of
+// the calculator.
+func prefixesOutsidePools(calculator *subnet.Calculator, prefixes []netip.Prefix) []netip.Prefix {
+	var outside []netip.Prefix
+	for _, prefix := range prefixes {
+		if !calculator.PrefixInPools(prefix) {
+			outside = append(outside, prefix)
+		}
+	}
+	return outside
+}
+
+// reallocateOutsidePools replaces the prefixes outside the pools of the
+// calculator, in place, with prefixes of the given mask length allocated from
+// the pools. It returns the prefixes replaced and their replacements.
+func reallocateOutsidePools(ctx context.Context, calculator *subnet.Calculator, prefixes []netip.Prefix, maskLength int) ([]netip.Prefix, []netip.Prefix, error) {
+	var replaced, replacements []netip.Prefix
+	for i, prefix := range prefixes {
+		if calculator.PrefixInPools(prefix) {
+			continue
+		}
+		calc := calculator.NextAvailableIPv4Subnet
+		if prefix.Addr().Is6() {
+			calc = calculator.NextAvailableIPv6Subnet
+		}
+		next, err := calc(ctx, maskLength)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to replace %s: %w", prefix, err)
+		}
+		replaced = append(replaced, prefix)
+		replacements = append(replacements, next)
+		prefixes[i] = next
+	}
+	return replaced, replacements, nil
+}
+
+// reallocate returns the prefixes with the ones outside the pools of the
+// calculator replaced, releasing them as Delete does and recording their
+// replacements as Create does.
+func (r *SubnetsResource) reallocate(ctx context.Context, calculator *subnet.Calculator, prefixes []netip.Prefix, maskLength int, owner string, diagnostics *diag.Diagnostics) []netip.Prefix {
+	prefixes = append([]netip.Prefix(nil), prefixes...)
+	replaced, replacements, err := reallocateOutsidePools(ctx, calculator, prefixes, maskLength)
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "CIDR calculation error", fmt.Sprintf("Unable to reallocate CIDR blocks that are no longer within pool_cidr_blocks: %v", err))
+		return nil
+	}
+	if len(replaced) == 0 {
+		return prefixes
+	}
+	for _, prefix := range replaced {
+		r.calculator.ReleasePrefix(prefix)
+	}
+	if r.ledger != nil {
+		for _, prefix := range replaced {
+			if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+				diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+				return nil
+			}
+		}
+	}
+	diagnostics.Append(recordAllocations(ctx, r.ledger, owner, append(calculator.Pools(false), calculator.Pools(true)...), replacements...)...)
+	if diagnostics.HasError() {
+		return nil
+	}
+	diagnostics.Append(r.webhook.released(ctx, "netcalc_subnets", owner, replaced...)...)
+	diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnets", owner, replaced...)...)
+	diagnostics.Append(r.webhook.allocated(ctx, "netcalc_subnets", owner, replacements...)...)
+	diagnostics.Append(r.ssmParameters.allocated(ctx, "netcalc_subnets", owner, replacements...)...)
+	tflog.Info(ctx, "reallocated CIDR blocks outside the pools", map[string]interface{}{"replaced": prefixStrings(replaced), "replacements": prefixStrings(replacements)})
+	return prefixes
+}
+
+// ModifyPlan checks cidr_count against the provider's max_cidr_count, and
+// plans the reallocation of the CIDR blocks that are no longer within
+// pool_cidr_blocks. The resource is only replaced if they cannot be
+// reallocated from the pools that remain.
+func (r *SubnetsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state SubnetsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if r.maxCIDRCount > 0 && plan.CIDRCount.ValueInt64() > r.maxCIDRCount {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cidr_count"),
+			"CIDR count too large",
+			fmt.Sprintf("A cidr_count of %d is more than the provider allows. The provider attribute max_cidr_count allows at most %d.", plan.CIDRCount.ValueInt64(), r.maxCIDRCount),
+		)
+		return
+	}
+	if req.State.Raw.IsNull() || len(resp.RequiresReplace) > 0 {
+		return
+	}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || !setKnown(plan.PoolCIDRBlocks) || !setKnown(plan.ExistingCIDRBlocks) {
+		return
+	}
+
+	plan.CIDRBlocks = state.CIDRBlocks
+	plan.IPv6CIDRBlocks = state.IPv6CIDRBlocks
+	calculator := subnet.NewCalculator()
+	family := r.LoadCIDRBlocks(ctx, plan, calculator, &resp.Diagnostics)
+	cidrs := parsePrefixList(state.CIDRBlocks, &resp.Diagnostics)
+	ipv6CIDRs := parsePrefixList(state.IPv6CIDRBlocks, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	checkExistingOverlaps(ctx, plan, state, append(cidrs, ipv6CIDRs...), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Imported resources have no pools in state until their first apply, so
+	// their CIDR blocks are checked against the pools once they are known.
+	if state.PoolCIDRBlocks.IsNull() {
+		for _, prefix := range append(cidrs, ipv6CIDRs...) {
+			if !calculator.PrefixInPools(prefix) {
+				resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "Imported CIDR block outside the pools", fmt.Sprintf("The imported CIDR block %s does not lie within pool_cidr_blocks. Add a pool containing it, or import the resource again with CIDR blocks within the pools.", prefix))
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Try the reallocation to see if the pools that remain have room, taking
+	// the CIDR blocks in use elsewhere into account as apply does. The CIDR
+	// blocks are allocated again on apply, when the ledger is read again.
+	resp.Diagnostics.Append(r.addUnavailable(ctx, calculator)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	replaced, _, err := reallocateOutsidePools(ctx, calculator, cidrs, int(plan.CIDRMaskLength.ValueInt64()))
+	var ipv6Replaced []netip.Prefix
+	if err == nil && family == modeDual {
+		ipv6Replaced, _, err = reallocateOutsidePools(ctx, calculator, ipv6CIDRs, int(plan.IPv6CIDRMaskLength.ValueInt64()))
+	}
+	if err != nil && ctx.Err() != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("pool_cidr_blocks"), "CIDR calculation error", fmt.Sprintf("Unable to reallocate CIDR blocks that are no longer within pool_cidr_blocks: %v", err))
+		return
+	}
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("CIDR blocks outside pool_cidr_blocks cannot be reallocated, replacing the resource: %v", err))
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("pool_cidr_blocks"))
+		return
+	}
+	if len(replaced) == 0 && len(ipv6Replaced) == 0 {
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("CIDR blocks %v are no longer within pool_cidr_blocks and will be reallocated", prefixStrings(append(replaced, ipv6Replaced...))))
+	if len(replaced) > 0 {
+		plan.CIDRBlocks = types.ListUnknown(types.StringType)
+	}
+	if len(ipv6Replaced) > 0 {
+		plan.IPv6CIDRBlocks = types.ListUnknown(types.StringType)
+	}
+	plan.ID = types.StringUnknown()
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 func (r *SubnetsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data SubnetsResourceModel
 
@@ -198,54 +712,186 @@ func (r *SubnetsResource) Delete(ctx context.Context, req resource.DeleteRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	allocated := append(parsePrefixList(data.CIDRBlocks, &resp.Diagnostics), parsePrefixList(data.IPv6CIDRBlocks, &resp.Diagnostics)...)
+	for _, prefix := range allocated {
+		r.calculator.ReleasePrefix(prefix)
+	}
+	resp.Diagnostics.Append(r.release(ctx, owner, allocated)...)
 	tflog.Info(ctx, "deleted a resource")
 }
 
+// release records in the ledger that owner no longer holds the prefixes,
+// and notifies the webhook and SSM parameters.
+func (r *SubnetsResource) release(ctx context.Context, owner string, prefixes []netip.Prefix) (diagnostics diag.Diagnostics) {
+	if r.ledger != nil {
+		for _, prefix := range prefixes {
+			if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+				diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+				return diagnostics
+			}
+		}
+	}
+	diagnostics.Append(r.webhook.released(ctx, "netcalc_subnets", owner, prefixes...)...)
+	diagnostics.Append(r.ssmParameters.released(ctx, "netcalc_subnets", owner, prefixes...)...)
+	return diagnostics
+}
+
 func (r *SubnetsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Parse the CIDRs from the ID.
-	var prefixes []netip.Prefix
-	var calculatedCIDRs []types.String
+	// Parse the CIDRs from the ID. IDs of dual stack resources list the
+	// IPv4 CIDR blocks followed by the IPv6 CIDR blocks.
+	var ipv4Prefixes, ipv6Prefixes []netip.Prefix
 	for _, cidr := range strings.Split(req.ID, ",") {
 		p, err := netip.ParsePrefix(cidr)
 		if err != nil {
 			resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", cidr, err))
 			continue
 		}
-		prefixes = append(prefixes, p)
-		calculatedCIDRs = append(calculatedCIDRs, types.StringValue(cidr))
+		if p != p.Masked() {
+			resp.Diagnostics.AddError("Non-canonical CIDR block", fmt.Sprintf("The CIDR block %s in the ID has host bits set. Did you mean %s?", p, p.Masked()))
+			continue
+		}
+		if p.Addr().Is4() {
+			if len(ipv6Prefixes) > 0 {
+				resp.Diagnostics.AddError("CIDR families out of order", fmt.Sprintf("IPv4 CIDR block %s follows IPv6 CIDR block %s in the ID. IDs of dual stack resources list the IPv4 CIDR blocks followed by the IPv6 CIDR blocks.", p, ipv6Prefixes[len(ipv6Prefixes)-1]))
+				return
+			}
+			ipv4Prefixes = append(ipv4Prefixes, p)
+		} else {
+			ipv6Prefixes = append(ipv6Prefixes, p)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefixes := ipv4Prefixes
+	dual := len(ipv4Prefixes) > 0 && len(ipv6Prefixes) > 0
+	if !dual {
+		prefixes = append(ipv4Prefixes, ipv6Prefixes...)
 	}
 	if len(prefixes) == 0 {
 		resp.Diagnostics.AddError("Invalid ID", "ID must consist of comma-separated CIDR blocks of the same size.")
+		return
 	}
-	maskLength := prefixes[0].Bits()
-	for _, p := range prefixes {
-		if p.Bits() != maskLength {
-			resp.Diagnostics.AddError("CIDR prefix lengths do not match", fmt.Sprintf("Expected all cidr masks to be the same size, but found %d and %d.", maskLength, p.Bits()))
-		}
+	if dual && len(prefixes) != len(ipv6Prefixes) {
+		resp.Diagnostics.AddError("CIDR counts do not match", fmt.Sprintf("Expected the same number of IPv4 and IPv6 CIDR blocks, but found %d and %d.", len(prefixes), len(ipv6Prefixes)))
+		return
+	}
+	maskLength := importMaskLength(prefixes, &resp.Diagnostics)
+	checkImportOverlaps(prefixes, &resp.Diagnostics)
+	if dual {
+		checkImportOverlaps(ipv6Prefixes, &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Save the calculated CIDR blocks into the Terraform state.
-	val, diagnostics := types.ListValueFrom(ctx, types.StringType, calculatedCIDRs)
+	val, diagnostics := types.ListValueFrom(ctx, types.StringType, prefixStrings(prefixes))
 	resp.Diagnostics.Append(diagnostics...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_blocks"), val)...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_count"), types.Int64Value(int64(len(calculatedCIDRs))))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_count"), types.Int64Value(int64(len(prefixes))))...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_mask_length"), types.Int64Value(int64(maskLength)))...)
+	if dual {
+		ipv6MaskLength := importMaskLength(ipv6Prefixes, &resp.Diagnostics)
+		val, diagnostics := types.ListValueFrom(ctx, types.StringType, prefixStrings(ipv6Prefixes))
+		resp.Diagnostics.Append(diagnostics...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ipv6_cidr_blocks"), val)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ipv6_cidr_mask_length"), types.Int64Value(int64(ipv6MaskLength)))...)
+	}
+	family := modeDual
+	if !dual {
+		family = modeV4
+		if prefixes[0].Addr().Is6() {
+			family = modeV6
+		}
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ip_family"), types.StringValue(family.String()))...)
 
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 	tflog.Info(ctx, "imported a resource")
 }
 
+// importMaskLength returns the mask length shared by all prefixes.
+func importMaskLength(prefixes []netip.Prefix, diagnostics *diag.Diagnostics) int {
+	maskLength := prefixes[0].Bits()
+	for _, p := range prefixes {
+		if p.Bits() != maskLength {
+			diagnostics.AddError("CIDR prefix lengths do not match", fmt.Sprintf("Expected all cidr masks to be the same size, but found %d and %d.", maskLength, p.Bits()))
+		}
+	}
+	return maskLength
+}
+
+// checkImportOverlaps reports imported prefixes that overlap each other.
+func checkImportOverlaps(prefixes []netip.Prefix, diagnostics *diag.Diagnostics) {
+	for i, p := range prefixes {
+		for _, q := range prefixes[i+1:] {
+			if p.Overlaps(q) {
+				diagnostics.AddError("Overlapping CIDR blocks", fmt.Sprintf("The ID lists %s and %s, which overlap. The CIDR blocks of a resource must not overlap.", p, q))
+			}
+		}
+	}
+}
+
+func prefixStrings(prefixes []netip.Prefix) []string {
+	var cidrs []string
+	for _, p := range prefixes {
+		cidrs = append(cidrs, p.String())
+	}
+	return cidrs
+}
+
 type mode int
 
 const (
 	modeUnknown mode = iota
 	modeV4
 	modeV6
+	modeDual
 )
 
+// String returns the ip_family value of a mode.
+func (m mode) String() string {
+	switch m {
+	case modeV4:
+		return ipFamilyIPv4
+	case modeV6:
+		return ipFamilyIPv6
+	case modeDual:
+		return ipFamilyDual
+	default:
+		return ""
+	}
+}
+
 func (r *SubnetsResource) LoadCIDRBlocks(ctx context.Context, s SubnetsResourceModel, calculator *subnet.Calculator, diagnostics *diag.Diagnostics) mode {
 	family := modeUnknown
+	switch s.IPFamily.ValueString() {
+	case ipFamilyIPv4:
+		family = modeV4
+	case ipFamilyIPv6:
+		family = modeV6
+	case ipFamilyDual:
+		family = modeDual
+	}
+	explicitFamily := family != modeUnknown
 	familyMatches := func(cidr netip.Prefix) bool {
+		if explicitFamily {
+			// Pools and existing blocks may mix families when the family is
+			// chosen explicitly; the calculator keeps them apart.
+			return true
+		}
 		switch family {
 		case modeV4:
 			return cidr.Addr().Is4()
@@ -265,21 +911,29 @@ func (r *SubnetsResource) LoadCIDRBlocks(ctx context.Context, s SubnetsResourceM
 			diagnostics.AddError("IP family mismatch", fmt.Sprintf("CIDR block %q is not expected IP family", cidr))
 			continue
 		}
-		calculator.AddPool(cidr)
+		if err := calculator.AddPool(cidr); err != nil {
+			diagnostics.AddError("Invalid pool CIDR block", fmt.Sprintf("Unable to add pool: %v", err))
+		}
 	}
+	calculator.AllocationOutsidePools = r.claimedOutsidePools
 	for _, cidr := range parsePrefixSet(ctx, s.ExistingCIDRBlocks, diagnostics) {
 		if !familyMatches(cidr) {
 			diagnostics.AddError("IP family mismatch", fmt.Sprintf("CIDR block %q is not expected IP family", cidr))
 			continue
 		}
-		calculator.AddAllocatedPrefix(cidr)
+		addAllocatedPrefixes(calculator, []netip.Prefix{cidr}, path.Root("existing_cidr_blocks"), diagnostics)
 	}
+	// The CIDR blocks of the resource may be outside pools that changed, in
+	// which case they are reallocated.
 	for _, cidr := range parsePrefixList(s.CIDRBlocks, diagnostics) {
 		if !familyMatches(cidr) {
 			diagnostics.AddError("IP family mismatch", fmt.Sprintf("CIDR block %q is not expected IP family", cidr))
 			continue
 		}
-		calculator.AddAllocatedPrefix(cidr)
+		calculator.HoldPrefix(cidr)
+	}
+	for _, cidr := range parsePrefixList(s.IPv6CIDRBlocks, diagnostics) {
+		calculator.HoldPrefix(cidr)
 	}
 	return family
 }
@@ -294,50 +948,7 @@ func parsePrefixSet(ctx context.Context, data types.Set, diagnostics *diag.Diagn
 			diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse pool CIDR: %q, %v", cidr, err))
 			continue
 		}
-		prefixes = append(prefixes, n)
+		prefixes = append(prefixes, canonicalPrefix(n, diagnostics))
 	}
 	return prefixes
 }
-
-// AvailableCIDRBlocksNoLongerContainsResourceCIDR checks the existing calculated CIDR block (if it exists in the current state)
-// against the list of available CIDR blocks in the configuration. If the calculated CIDR no longer belongs to one of the available
-// blocks, it will require replacement.
-func (r *SubnetsResource) AvailableCIDRBlocksNoLongerContainsResourceCIDR(ctx context.Context, req planmodifier.SetRequest, resp *setplanmodifier.RequiresReplaceIfFuncResponse) {
-	calculator := subnet.NewCalculator()
-
-	var state SubnetsResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-	var config SubnetsResourceModel
-	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Load state into calculator.
-	r.LoadCIDRBlocks(ctx, config, calculator, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	for _, elem := range state.CIDRBlocks.Elements() {
-		cidr, ok := elem.(types.String)
-		if !ok {
-			resp.Diagnostics.AddError("Value conversion error", "Unable to build a value from the the list of allocated CIDR blocks.")
-		}
-
-		n, err := netip.ParsePrefix(cidr.ValueString())
-		if err != nil {
-			resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse calculated CIDR: %q, %v", cidr, err))
-			continue
-		}
-		if !calculator.PrefixInPools(n) {
-			tflog.Debug(ctx, fmt.Sprintf("Prefix %s is not in cidr blocks %v", cidr.ValueString(), config.PoolCIDRBlocks))
-			resp.RequiresReplace = true
-		} else {
-			tflog.Debug(ctx, fmt.Sprintf("Prefix %s is still in cidr blocks %v", cidr.ValueString(), config.PoolCIDRBlocks))
-		}
-	}
-}
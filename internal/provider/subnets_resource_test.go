@@ -4,9 +4,18 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/geezyx/subnet-calculator/internal/ledger"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 )
 
 func TestAccSubnetsResource(t *testing.T) {
@@ -27,6 +36,7 @@ func TestAccSubnetsResource(t *testing.T) {
 					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.0.0.0/24"),
 					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.1", "10.0.1.0/24"),
 					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.2", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "remaining_count", "253"),
 				),
 			},
 			// Changing cidr_count causes recalculation
@@ -90,6 +100,7 @@ func TestAccSubnetsResource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24"),
 					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "remaining_count", "255"),
 				),
 			},
 			// Changing the CIDR block count should cause a recalculation
@@ -325,7 +336,7 @@ func TestAccSubnetsResource(t *testing.T) {
 				ResourceName:            "netcalc_subnets.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks"},
+				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks", "remaining_count"},
 			},
 			// Change the pool CIDR blocks after import:
 			{
@@ -347,7 +358,7 @@ func TestAccSubnetsResource(t *testing.T) {
 				ResourceName:            "netcalc_subnets.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks"},
+				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks", "remaining_count"},
 			},
 			// Change the existing CIDR blocks after import:
 			{
@@ -370,8 +381,402 @@ func TestAccSubnetsResource(t *testing.T) {
 				ResourceName:            "netcalc_subnets.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks"},
+				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks", "remaining_count"},
 			},
 		},
 	})
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The requested family must have pools
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					ip_family        = "ipv6"
+					cidr_mask_length = 64
+					cidr_count       = 1
+				  }`,
+				ExpectError: regexp.MustCompile(`No IPv6 pools`),
+			},
+			// Without ip_family, the pools must be of one family
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					cidr_mask_length = 24
+					cidr_count       = 1
+				  }`,
+				ExpectError: regexp.MustCompile(`Set\s+ip_family\s+to\s+mix\s+IPv4\s+and\s+IPv6\s+CIDR\s+blocks`),
+			},
+			// Dual stack requires an IPv6 mask length
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					ip_family        = "dual"
+					cidr_mask_length = 24
+					cidr_count       = 1
+				  }`,
+				ExpectError: regexp.MustCompile(`Missing IPv6 mask length`),
+			},
+			// The requested family is chosen from mixed-family pools
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks     = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					existing_cidr_blocks = ["10.0.0.0/24", "fd18:fad4:bce5:4400::/64"]
+					ip_family            = "ipv6"
+					cidr_mask_length     = 64
+					cidr_count           = 2
+				  }`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "fd18:fad4:bce5:4401::/64,fd18:fad4:bce5:4402::/64"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "fd18:fad4:bce5:4401::/64"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.1", "fd18:fad4:bce5:4402::/64"),
+					resource.TestCheckNoResourceAttr("netcalc_subnets.test", "ipv6_cidr_blocks"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "remaining_count", "253"),
+				),
+			},
+			// Single-family imports keep ip_family, so they are not replaced.
+			{
+				ResourceName:            "netcalc_subnets.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks", "remaining_count"},
+			},
+			// Dual stack allocates from both families
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks      = ["10.0.0.0/16", "fd18:fad4:bce5:4400::/56"]
+					existing_cidr_blocks  = ["10.0.0.0/24", "fd18:fad4:bce5:4400::/64"]
+					ip_family             = "dual"
+					cidr_mask_length      = 24
+					ipv6_cidr_mask_length = 64
+					cidr_count            = 2
+				  }`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.1.0/24,10.0.2.0/24,fd18:fad4:bce5:4401::/64,fd18:fad4:bce5:4402::/64"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.1", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "ipv6_cidr_blocks.0", "fd18:fad4:bce5:4401::/64"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "ipv6_cidr_blocks.1", "fd18:fad4:bce5:4402::/64"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "remaining_count", "253"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "netcalc_subnets.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"pool_cidr_blocks", "existing_cidr_blocks", "remaining_count"},
+			},
+		},
+	})
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/24", "10.1.0.0/24"]
+					cidr_mask_length = 25
+					cidr_count       = 3
+				  }`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/25,10.0.0.128/25,10.1.0.0/25"),
+				),
+			},
+			// Removing a pool only reallocates the CIDR blocks within it,
+			// without replacing the resource.
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/24", "10.2.0.0/24"]
+					cidr_mask_length = 25
+					cidr_count       = 3
+				  }`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnets.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/25,10.0.0.128/25,10.2.0.0/25"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.0", "10.0.0.0/25"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.1", "10.0.0.128/25"),
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "cidr_blocks.2", "10.2.0.0/25"),
+				),
+			},
+			// The resource is replaced when the remaining pools have no room
+			// for the CIDR blocks outside them.
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/24"]
+					cidr_mask_length = 25
+					cidr_count       = 3
+				  }`,
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+calculate\s+next\s+available\s+CIDR`),
+			},
+		},
+	})
+}
+
+func TestAccSubnetsResourceImportValidation(t *testing.T) {
+	config := `
+	resource "netcalc_subnets" "test" {
+		pool_cidr_blocks = ["10.0.0.0/16"]
+		cidr_mask_length = 24
+		cidr_count       = 2
+	}`
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:        config,
+				ResourceName:  "netcalc_subnets.test",
+				ImportState:   true,
+				ImportStateId: "10.0.0.0/24,fd00::/64,10.0.1.0/24,fd00:0:0:1::/64",
+				ExpectError:   regexp.MustCompile(`IPv4\s+CIDR\s+block\s+10.0.1.0/24\s+follows\s+IPv6\s+CIDR\s+block\s+fd00::/64`),
+			},
+			{
+				Config:        config,
+				ResourceName:  "netcalc_subnets.test",
+				ImportState:   true,
+				ImportStateId: "10.0.0.0/24,10.0.1.0/24,10.0.0.0/24",
+				ExpectError:   regexp.MustCompile(`The\s+ID\s+lists\s+10.0.0.0/24\s+and\s+10.0.0.0/24,\s+which\s+overlap`),
+			},
+			{
+				Config:        config,
+				ResourceName:  "netcalc_subnets.test",
+				ImportState:   true,
+				ImportStateId: "10.0.0.1/24",
+				ExpectError:   regexp.MustCompile(`has\s+host\s+bits\s+set.\s+Did\s+you\s+mean\s+10.0.0.0/24\?`),
+			},
+			// CIDR blocks outside the pools are reported once the pools are
+			// known, rather than silently reallocated.
+			{
+				Config:             config,
+				ResourceName:       "netcalc_subnets.test",
+				ImportState:        true,
+				ImportStateId:      "10.1.0.0/24,10.0.0.0/24",
+				ImportStatePersist: true,
+			},
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`The\s+imported\s+CIDR\s+block\s+10.1.0.0/24\s+does\s+not\s+lie\s+within\s+pool_cidr_blocks`),
+			},
+			{
+				Config: `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16", "10.1.0.0/16"]
+					cidr_mask_length = 24
+					cidr_count       = 2
+				}`,
+				Check: resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.1.0.0/24,10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func TestAccSubnetsResourceRefresh(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubnetsResourceRefreshConfig(ledgerPath, `[]`),
+				Check:  resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/24,10.0.1.0/24"),
+			},
+			// A CIDR block registered to another owner in the ledger is
+			// replaced on refresh, and the other CIDR block of the resource
+			// is released so that it can be allocated again.
+			{
+				PreConfig: func() {
+					l := ledger.NewFileLedger(ledgerPath)
+					prefix := netip.MustParsePrefix("10.0.0.0/24")
+					owner, _, err := ledger.Owner(context.Background(), l, prefix)
+					if err == nil {
+						err = l.Release(context.Background(), prefix, owner)
+					}
+					if err == nil {
+						err = l.Allocate(context.Background(), prefix, "other")
+					}
+					if err != nil {
+						t.Fatalf("unable to reassign %s in the ledger: %v", prefix, err)
+					}
+				},
+				Config: testAccSubnetsResourceRefreshConfig(ledgerPath, `[]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.1.0/24,10.0.2.0/24"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath), "10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"),
+				),
+			},
+			// So is a CIDR block the provider reserves.
+			{
+				Config: testAccSubnetsResourceRefreshConfig(ledgerPath, `["10.0.1.0/24"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.2.0/24,10.0.3.0/24"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath), "10.0.0.0/24", "10.0.2.0/24", "10.0.3.0/24"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSubnetsResourceRefreshConfig(ledgerPath string, reserved string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path          = %[1]q
+  reserved_cidr_blocks = %[2]s
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  cidr_mask_length = 24
+  cidr_count       = 2
+}
+`, ledgerPath, reserved)
+}
+
+func TestAccSubnetsResourceUpdateSharedLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubnetsResourceUpdateSharedLedgerConfig(ledgerPath, `[]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.b", "id", "10.0.0.64/26"),
+					resource.TestCheckResourceAttr("netcalc_subnets.b", "remaining_count", "2"),
+				),
+			},
+			// An in-place update still counts the allocations of the other
+			// resource in the ledger.
+			{
+				Config: testAccSubnetsResourceUpdateSharedLedgerConfig(ledgerPath, `["192.168.0.0/24"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnets.b", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.b", "id", "10.0.0.64/26"),
+					resource.TestCheckResourceAttr("netcalc_subnets.b", "remaining_count", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSubnetsResourceUpdateSharedLedgerConfig(ledgerPath string, existing string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %[1]q
+}
+
+resource "netcalc_subnets" "a" {
+  pool_cidr_blocks = ["10.0.0.0/24"]
+  cidr_mask_length = 26
+  cidr_count       = 1
+}
+
+resource "netcalc_subnets" "b" {
+  pool_cidr_blocks     = ["10.0.0.0/24"]
+  existing_cidr_blocks = %[2]s
+  cidr_mask_length     = 26
+  cidr_count           = 1
+
+  depends_on = [netcalc_subnets.a]
+}
+`, ledgerPath, existing)
+}
+
+func TestAccSubnetsResourceLedgerError(t *testing.T) {
+	ipam := &fakeIPAMServer{allocations: map[string]string{"10.0.0.0/24": "legacy"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/allocations/10.0.2.0/24" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		ipam.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	httpLedger := newTestHTTPLedger(t, server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerHTTPBackend(server.URL, "PUT"), `
+				resource "netcalc_subnets" "test" {
+					pool_cidr_blocks = ["10.0.0.0/16"]
+					cidr_mask_length = 24
+					cidr_count       = 2
+				}`),
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+record\s+allocation\s+of\s+10.0.2.0/24`),
+			},
+			// The CIDR block recorded before the failure is released again.
+			{
+				Config: testAccProviderLedgerConfig("", testAccLedgerHTTPBackend(server.URL, "PUT"), ""),
+				Check:  testAccCheckLedgerActive(httpLedger, "10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func TestAccSubnetsResourceReallocateLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	l := ledger.NewFileLedger(ledgerPath)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubnetsResourceReallocateLedgerConfig(ledgerPath, `["10.0.0.0/24", "10.1.0.0/24"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnets.test", "id", "10.0.0.0/25,10.0.0.128/25,10.1.0.0/25"),
+				),
+			},
+			// The resource is replaced when the CIDR blocks in use elsewhere
+			// leave the remaining pools no room for the CIDR blocks outside
+			// them.
+			{
+				PreConfig: func() {
+					if err := l.Allocate(context.Background(), netip.MustParsePrefix("10.2.0.0/24"), "other"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccSubnetsResourceReallocateLedgerConfig(ledgerPath, `["10.0.0.0/24", "10.2.0.0/24"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("netcalc_subnets.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				ExpectError: regexp.MustCompile(`Unable\s+to\s+calculate\s+next\s+available\s+CIDR`),
+			},
+		},
+	})
+}
+
+func testAccSubnetsResourceReallocateLedgerConfig(ledgerPath string, pools string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  ledger_path = %[1]q
+}
+
+resource "netcalc_subnets" "test" {
+  pool_cidr_blocks = %[2]s
+  cidr_mask_length = 25
+  cidr_count       = 3
+}
+`, ledgerPath, pools)
 }
@@ -0,0 +1,373 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SupernetResource{}
+var _ resource.ResourceWithImportState = &SupernetResource{}
+var _ resource.ResourceWithConfigure = &SupernetResource{}
+var _ resource.ResourceWithValidateConfig = &SupernetResource{}
+var _ resource.ResourceWithModifyPlan = &SupernetResource{}
+
+func NewSupernetResource() resource.Resource {
+	return &SupernetResource{}
+}
+
+// SupernetResource defines the resource implementation.
+type SupernetResource struct {
+	calculator SubnetCalculator
+	ledger     ledger.Ledger
+	lock       *allocationLock
+}
+
+// SupernetResourceModel describes the resource data model.
+type SupernetResourceModel struct {
+	CIDRBlocks types.Set    `tfsdk:"cidr_blocks"`
+	CIDRBlock  types.String `tfsdk:"cidr_block"`
+	ID         types.String `tfsdk:"id"`
+}
+
+func (r *SupernetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_supernet"
+}
+
+func (r *SupernetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Supernet resource. Claims the smallest CIDR block covering a set of CIDR blocks, reserving the aggregate before growing into it. Other netcalc resources will not allocate from a claimed supernet unless it is used as a netcalc_pool. The supernet is recorded in the provider's ledger over the allocations it covers, so other Terraform states do not allocate from it either.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_blocks": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "CIDR blocks of a single IP family that the supernet must cover.",
+				Required:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(ipAddressValidator{}),
+				},
+			},
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Claimed supernet. Creating the resource fails if the supernet overlaps any allocated CIDR block other than those within cidr_blocks.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource ID, same as the claimed cidr_block.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *SupernetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SupernetResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.CIDRBlocks.IsUnknown() {
+		return
+	}
+
+	// Unknown elements are skipped, so only the known CIDR blocks are checked.
+	var prefixes []netip.Prefix
+	for _, elem := range data.CIDRBlocks.Elements() {
+		cidr, ok := elem.(types.String)
+		if !ok || cidr.IsUnknown() {
+			continue
+		}
+		p, err := netip.ParsePrefix(cidr.ValueString())
+		if err != nil {
+			// Reported by the attribute validator.
+			return
+		}
+		prefixes = append(prefixes, p)
+	}
+	if len(prefixes) == 0 {
+		return
+	}
+	if _, err := subnet.Supernet(prefixes); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_blocks"), "Invalid CIDR blocks", fmt.Sprintf("Unable to calculate a supernet: %v", err))
+	}
+}
+
+// ModifyPlan plans the supernet from the covered CIDR blocks, and replaces the
+// resource when the supernet changes.
+func (r *SupernetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan SupernetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !setKnown(plan.CIDRBlocks) {
+		return
+	}
+
+	resp.Diagnostics.Append(calculateSupernet(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var state SupernetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if !plan.CIDRBlock.Equal(state.CIDRBlock) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("cidr_block"))
+	}
+}
+
+func (r *SupernetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	switch data := req.ProviderData.(type) {
+	case *netcalcProviderData:
+		r.calculator = data.calculator
+		r.ledger = data.ledger
+		r.lock = data.lock
+	case nil:
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *netcalcProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+	}
+}
+
+func (r *SupernetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SupernetResourceModel
+
+	// Read Terraform plan data into the model.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	owner, err := newAllocationOwner()
+	if err != nil {
+		resp.Diagnostics.AddError("Owner generation error", fmt.Sprintf("Unable to generate an allocation owner: %v", err))
+		return
+	}
+	resp.Diagnostics.Append(setAllocationOwner(ctx, resp.Private, owner)...)
+	resp.Diagnostics.Append(r.claimSupernet(ctx, &data, owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, "created a supernet resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SupernetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SupernetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SupernetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SupernetResourceModel
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state SupernetResourceModel
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The supernet could not be planned if the CIDR blocks were unknown, so
+	// claim it again in case it changed.
+	if plan.CIDRBlock.IsUnknown() {
+		unlock, diags := r.lock.acquire(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+		owner, diags := getAllocationOwner(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		prior := parsePrefix(state.CIDRBlock, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// The prior supernet is released first, as the new one usually
+		// contains it.
+		r.calculator.DeleteAllocatedPrefix(prior)
+		if r.ledger != nil {
+			if err := r.ledger.Release(ctx, prior, owner); err != nil {
+				r.calculator.HoldPrefix(prior)
+				resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prior, err))
+				return
+			}
+		}
+		resp.Diagnostics.Append(r.claimSupernet(ctx, &plan, owner)...)
+		if resp.Diagnostics.HasError() {
+			r.calculator.HoldPrefix(prior)
+			resp.Diagnostics.Append(r.recordSupernet(ctx, owner, prior, parsePrefixSet(ctx, state.CIDRBlocks, &resp.Diagnostics))...)
+			return
+		}
+	}
+	tflog.Info(ctx, "updated a supernet resource")
+
+	// Save updated data into Terraform state.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SupernetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SupernetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock, diags := r.lock.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() { resp.Diagnostics.Append(unlock(ctx)...) }()
+
+	prefix := parsePrefix(data.CIDRBlock, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.calculator.ReleasePrefix(prefix)
+	owner, diags := getAllocationOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if r.ledger != nil {
+		if err := r.ledger.Release(ctx, prefix, owner); err != nil {
+			resp.Diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to record release of %s: %v", prefix, err))
+			return
+		}
+	}
+	tflog.Info(ctx, "deleted a supernet resource")
+}
+
+func (r *SupernetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Parse the CIDR from the ID.
+	p, err := netip.ParsePrefix(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("CIDR parsing error", fmt.Sprintf("Unable to parse CIDR from ID: %q, %v", req.ID, err))
+		return
+	}
+
+	cidrBlocks, diagnostics := types.SetValueFrom(ctx, types.StringType, []string{p.String()})
+	resp.Diagnostics.Append(diagnostics...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_blocks"), cidrBlocks)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cidr_block"), p.String())...)
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	tflog.Info(ctx, "imported a supernet resource")
+}
+
+// setKnown reports whether a set and all of its elements are known.
+func setKnown(set types.Set) bool {
+	if set.IsUnknown() {
+		return false
+	}
+	for _, elem := range set.Elements() {
+		if elem.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// claimSupernet calculates the supernet, claims it in the calculator and
+// records it under owner in the ledger.
+func (r *SupernetResource) claimSupernet(ctx context.Context, data *SupernetResourceModel, owner string) (diagnostics diag.Diagnostics) {
+	diagnostics.Append(calculateSupernet(ctx, data)...)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+
+	supernet := netip.MustParsePrefix(data.CIDRBlock.ValueString())
+	covered := parsePrefixSet(ctx, data.CIDRBlocks, &diagnostics)
+	if err := r.calculator.ClaimPrefix(supernet, covered); err != nil {
+		diagnostics.AddError("Supernet claim error", fmt.Sprintf("Unable to claim supernet: %v", err))
+		return diagnostics
+	}
+	diagnostics.Append(r.recordSupernet(ctx, owner, supernet, covered)...)
+	if diagnostics.HasError() {
+		r.calculator.DeleteAllocatedPrefix(supernet)
+	}
+	return diagnostics
+}
+
+// recordSupernet records the supernet under owner in the ledger, over the
+// allocations within the CIDR blocks it covers, as it is claimed over them in
+// the calculator.
+func (r *SupernetResource) recordSupernet(ctx context.Context, owner string, supernet netip.Prefix, covered []netip.Prefix) (diagnostics diag.Diagnostics) {
+	if r.ledger == nil {
+		return diagnostics
+	}
+	entries, err := r.ledger.Entries(ctx)
+	if err != nil {
+		diagnostics.AddError("Ledger error", fmt.Sprintf("Unable to read the allocation ledger: %v", err))
+		return diagnostics
+	}
+	var nested []netip.Prefix
+	for _, prefix := range activePrefixes(entries) {
+		if prefixInAny(prefix, covered) {
+			nested = append(nested, prefix)
+		}
+	}
+	return recordAllocations(ctx, r.ledger, owner, nested, supernet)
+}
+
+// calculateSupernet calculates the supernet covering the model's CIDR blocks.
+func calculateSupernet(ctx context.Context, data *SupernetResourceModel) (diagnostics diag.Diagnostics) {
+	prefixes := parsePrefixSet(ctx, data.CIDRBlocks, &diagnostics)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+	supernet, err := subnet.Supernet(prefixes)
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root("cidr_blocks"), "Invalid CIDR blocks", fmt.Sprintf("Unable to calculate a supernet: %v", err))
+		return diagnostics
+	}
+
+	data.CIDRBlock = types.StringValue(supernet.String())
+	data.ID = types.StringValue(supernet.String())
+	return diagnostics
+}
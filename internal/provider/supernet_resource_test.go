@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/geezyx/subnet-calculator/internal/ledger"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSupernetResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The CIDR blocks must be of a single IP family
+			{
+				Config: `
+				resource "netcalc_supernet" "test" {
+					cidr_blocks = ["10.0.0.0/24", "fd18:fad4:bce5:4400::/64"]
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid CIDR blocks`),
+			},
+			// The supernet must not overlap other allocations
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/16"]
+					claimed_cidr_blocks = ["10.0.2.0/24"]
+				}
+				resource "netcalc_supernet" "test" {
+					cidr_blocks = ["10.0.0.0/24", "10.0.3.0/24"]
+				}`,
+				ExpectError: regexp.MustCompile(`10.0.0.0/22 overlaps allocated CIDR block\s+10.0.2.0/24`),
+			},
+			// Create and Read testing
+			{
+				Config: `
+				provider "netcalc" {
+					pool_cidr_blocks    = ["10.0.0.0/16"]
+					claimed_cidr_blocks = ["10.0.4.0/24"]
+				}
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_supernet" "test" {
+					cidr_blocks = [netcalc_subnet.test.cidr_block, "10.0.3.0/24"]
+				}
+				resource "netcalc_pool" "test" {
+					cidr_blocks = [netcalc_supernet.test.cidr_block]
+				}
+				resource "netcalc_subnet" "in_supernet" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "outside_supernet" {
+					cidr_mask_length = 22
+					depends_on       = [netcalc_supernet.test]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr("netcalc_supernet.test", "cidr_block", "10.0.0.0/22"),
+					resource.TestCheckResourceAttr("netcalc_supernet.test", "id", "10.0.0.0/22"),
+					resource.TestCheckResourceAttr("netcalc_subnet.in_supernet", "cidr_block", "10.0.1.0/24"),
+					resource.TestCheckResourceAttr("netcalc_subnet.outside_supernet", "cidr_block", "10.0.8.0/22"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "netcalc_supernet.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"cidr_blocks"},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccSupernetResourceLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The supernet is recorded over the subnet it covers, and the
+			// subnets of a pool carved out of it within it.
+			{
+				Config: testAccSupernetResourceLedgerConfig(ledgerPath, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_supernet" "test" {
+					cidr_blocks = [netcalc_subnet.test.cidr_block, "10.0.3.0/24"]
+				}
+				resource "netcalc_pool" "test" {
+					cidr_blocks = [netcalc_supernet.test.cidr_block]
+				}
+				resource "netcalc_subnet" "in_supernet" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_supernet.test", "cidr_block", "10.0.0.0/22"),
+					resource.TestCheckResourceAttr("netcalc_subnet.in_supernet", "cidr_block", "10.0.1.0/24"),
+					testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath), "10.0.0.0/24", "10.0.0.0/22", "10.0.1.0/24"),
+				),
+			},
+			// Later runs, like other Terraform states, hold the supernet from the
+			// ledger.
+			{
+				Config: testAccSupernetResourceLedgerConfig(ledgerPath, `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}
+				resource "netcalc_supernet" "test" {
+					cidr_blocks = [netcalc_subnet.test.cidr_block, "10.0.3.0/24"]
+				}
+				resource "netcalc_pool" "test" {
+					cidr_blocks = [netcalc_supernet.test.cidr_block]
+				}
+				resource "netcalc_subnet" "in_supernet" {
+					pool_id          = netcalc_pool.test.id
+					cidr_mask_length = 24
+				}
+				resource "netcalc_subnet" "outside_supernet" {
+					cidr_mask_length = 24
+				}`),
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.outside_supernet", "cidr_block", "10.0.4.0/24"),
+			},
+			// Releasing the supernet
+			{
+				Config: testAccSupernetResourceLedgerConfig(ledgerPath, ""),
+				Check:  testAccCheckLedgerActive(ledger.NewFileLedger(ledgerPath)),
+			},
+		},
+	})
+}
+
+func testAccSupernetResourceLedgerConfig(ledgerPath string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+  ledger_path      = %[1]q
+}
+%[2]s
+`, ledgerPath, resources)
+}
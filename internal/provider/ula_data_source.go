@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ULADataSource{}
+
+func NewULADataSource() datasource.DataSource {
+	return &ULADataSource{}
+}
+
+// ULADataSource defines the data source implementation.
+type ULADataSource struct {
+}
+
+// ULADataSourceModel describes the data source data model.
+type ULADataSourceModel struct {
+	Seed      types.String `tfsdk:"seed"`
+	CIDRBlock types.String `tfsdk:"cidr_block"`
+	GlobalID  types.String `tfsdk:"global_id"`
+	ID        types.String `tfsdk:"id"`
+}
+
+func (d *ULADataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ula"
+}
+
+func (d *ULADataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "ULA data source. Generates an RFC 4193 unique local IPv6 /48 prefix with a pseudo-random global ID, instead of hand-picked ranges such as `fd00::/48` that are likely to collide when networks are merged or peered.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				MarkdownDescription: "Seed the global ID is derived from, e.g. the name of the site. The same seed always generates the same prefix. Without a seed a new random prefix is generated on every read, so it should only be used to pick a prefix to hard-code.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.LengthAtLeast(1)},
+			},
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Generated /48 unique local prefix, e.g. `fd08:1ad7:3d3c::/48`.",
+				Computed:            true,
+			},
+			"global_id": schema.StringAttribute{
+				MarkdownDescription: "40-bit global ID of the prefix as 10 hexadecimal digits.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, same as the cidr_block.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ULADataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ULADataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	globalID := subnet.SeededULAGlobalID(data.Seed.ValueString())
+	if data.Seed.IsNull() {
+		var err error
+		globalID, err = subnet.RandomULAGlobalID()
+		if err != nil {
+			resp.Diagnostics.AddError("Global ID generation error", fmt.Sprintf("Unable to generate a random global ID: %v", err))
+			return
+		}
+	}
+	prefix := subnet.ULAPrefix(globalID)
+
+	data.CIDRBlock = types.StringValue(prefix.String())
+	data.GlobalID = types.StringValue(hex.EncodeToString(globalID[:]))
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a ULA data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccULADataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: `
+				data "netcalc_ula" "seeded" {
+					seed = "production"
+				}
+				data "netcalc_ula" "random" {
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_ula.seeded", "id", "fd08:1ad7:3d3c::/48"),
+					resource.TestCheckResourceAttr("data.netcalc_ula.seeded", "cidr_block", "fd08:1ad7:3d3c::/48"),
+					resource.TestCheckResourceAttr("data.netcalc_ula.seeded", "global_id", "081ad73d3c"),
+					resource.TestMatchResourceAttr("data.netcalc_ula.random", "cidr_block", regexp.MustCompile(`^fd[0-9a-f]{2}:[0-9a-f]{1,4}:[0-9a-f]{1,4}::/48$`)),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+func warnUtilizationPercentAttribute() schema.Attribute {
+	return schema.Float64Attribute{
+		MarkdownDescription: "Percentage of the addresses of a pool, e.g. `80`, above which allocations of netcalc_subnet, netcalc_subnet_group, netcalc_subnet_pair, netcalc_dual_stack_subnet and netcalc_static_subnet resources report a warning, giving lead time before the pool is exhausted. Pools are the CIDR blocks of `pool_cidr_blocks`, `pool_cidr_blocks_file`, `aws_ipam_pool` and `pools`, and claimed, allocated and reserved CIDR blocks count as used. Each pool is reported at most once per run.",
+		Optional:            true,
+		Validators:          []validator.Float64{float64validator.Between(0, 100)},
+	}
+}
+
+// utilizationWarning reports pools whose utilization exceeds a threshold
+// after an allocation.
+type utilizationWarning struct {
+	threshold  float64
+	calculator SubnetCalculator
+	// namedPools are the CIDR blocks of the named pools, which are not pools
+	// of the calculator.
+	namedPools []netip.Prefix
+
+	m sync.Mutex
+	// warned are the pools already reported during the run.
+	warned map[netip.Prefix]bool
+}
+
+// newUtilizationWarning returns the check configured by the
+// warn_utilization_percent provider attribute.
+func newUtilizationWarning(threshold float64, calculator SubnetCalculator, pools map[string]namedPool) *utilizationWarning {
+	u := &utilizationWarning{threshold: threshold, calculator: calculator, warned: map[netip.Prefix]bool{}}
+	for _, pool := range pools {
+		u.namedPools = append(u.namedPools, pool.cidrBlocks...)
+	}
+	return u
+}
+
+// allocated warns about the pools of the allocated prefixes whose utilization
+// exceeds the threshold. It does nothing for a nil check.
+func (u *utilizationWarning) allocated(ctx context.Context, prefixes ...netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if u == nil {
+		return diagnostics
+	}
+	u.m.Lock()
+	defer u.m.Unlock()
+	pools := append(u.calculator.Pools(false), u.calculator.Pools(true)...)
+	for _, pool := range sortPrefixes(append(pools, u.namedPools...)) {
+		if u.warned[pool] || !prefixOverlapsAny(pool, prefixes) {
+			continue
+		}
+		total := addressCount([]netip.Prefix{pool})
+		used := new(big.Float).Sub(total, addressCount(u.calculator.FreePrefixesInPool(pool, nil)))
+		utilization, _ := new(big.Float).Quo(used.Mul(used, big.NewFloat(100)), total).Float64()
+		if utilization <= u.threshold {
+			continue
+		}
+		u.warned[pool] = true
+		tflog.Warn(ctx, "pool utilization above threshold", map[string]interface{}{"pool": pool.String(), "utilization": utilization})
+		diagnostics.AddWarning(
+			"Pool utilization above threshold",
+			fmt.Sprintf("Pool %s is %.1f%% utilized, above warn_utilization_percent of %g%%. Add CIDR blocks to the pool or release unused allocations before it is exhausted.", pool, utilization, u.threshold),
+		)
+	}
+	return diagnostics
+}
@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProviderWarnUtilizationPercent(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderWarnUtilizationPercentConfig(150),
+				ExpectError: regexp.MustCompile(`Attribute\s+warn_utilization_percent\s+value\s+must\s+be\s+between\s+0.000000\s+and\s+100.000000`),
+			},
+			// Allocations above the threshold are warnings, not errors.
+			{
+				Config: testAccProviderWarnUtilizationPercentConfig(50),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.first", "cidr_block", "10.0.0.0/25"),
+					resource.TestCheckResourceAttr("netcalc_static_subnet.second", "cidr_block", "10.0.0.128/26"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderWarnUtilizationPercentConfig(threshold float64) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks         = ["10.0.0.0/24"]
+  warn_utilization_percent = %[1]g
+}
+
+resource "netcalc_subnet" "first" {
+  cidr_mask_length = 25
+}
+
+resource "netcalc_static_subnet" "second" {
+  cidr_block = "10.0.0.128/26"
+
+  depends_on = [netcalc_subnet.first]
+}
+`, threshold)
+}
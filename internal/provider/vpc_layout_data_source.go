@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/geezyx/subnet-calculator/internal/subnet"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &VPCLayoutDataSource{}
+
+func NewVPCLayoutDataSource() datasource.DataSource {
+	return &VPCLayoutDataSource{}
+}
+
+// VPCLayoutDataSource defines the data source implementation.
+type VPCLayoutDataSource struct {
+}
+
+// VPCLayoutDataSourceModel describes the data source data model.
+type VPCLayoutDataSourceModel struct {
+	CIDRBlock         types.String `tfsdk:"cidr_block"`
+	AvailabilityZones types.List   `tfsdk:"availability_zones"`
+	Tiers             types.Map    `tfsdk:"tiers"`
+	Subnets           types.Map    `tfsdk:"subnets"`
+	FreeCIDRBlocks    types.List   `tfsdk:"free_cidr_blocks"`
+	ID                types.String `tfsdk:"id"`
+}
+
+func (d *VPCLayoutDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vpc_layout"
+}
+
+func (d *VPCLayoutDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "VPC layout data source. Lays out a subnet per tier and availability zone within a regional CIDR block, e.g. public, private and database subnets in each of three zones, replacing chains of cidrsubnet calls. The largest subnets are placed first, so the layout fits whenever the total size of the subnets does.",
+
+		Attributes: map[string]schema.Attribute{
+			"cidr_block": schema.StringAttribute{
+				MarkdownDescription: "Regional CIDR block to lay the subnets out in, e.g. the CIDR block of a VPC.",
+				Required:            true,
+				Validators:          []validator.String{ipAddressValidator{}},
+			},
+			"availability_zones": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Availability zones to create a subnet in for every tier.",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"tiers": schema.MapAttribute{
+				ElementType:         types.Int64Type,
+				MarkdownDescription: "CIDR mask length of the subnets of each tier, keyed by tier name, e.g. `{ public = 24, private = 20 }`. Subnets of the same size are placed in order of tier name and then availability zone.",
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.ValueInt64sAre(int64validator.Between(0, 128)),
+				},
+			},
+			"subnets": schema.MapAttribute{
+				ElementType:         types.MapType{ElemType: types.StringType},
+				MarkdownDescription: "CIDR blocks of the subnets, keyed by tier name and then availability zone, e.g. `subnets[\"public\"][\"us-east-1a\"]`.",
+				Computed:            true,
+			},
+			"free_cidr_blocks": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Space left in the regional CIDR block after the layout, as a list of minimal CIDR blocks in address order, e.g. for tiers added later.",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Data source ID, the regional CIDR block without host bits.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *VPCLayoutDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data VPCLayoutDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix, err := netip.ParsePrefix(data.CIDRBlock.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr_block"), "CIDR parsing error", fmt.Sprintf("Unable to parse CIDR: %q, %v", data.CIDRBlock.ValueString(), err))
+		return
+	}
+	prefix = prefix.Masked()
+	var zones []string
+	resp.Diagnostics.Append(data.AvailabilityZones.ElementsAs(ctx, &zones, false)...)
+	var tiers map[string]int64
+	resp.Diagnostics.Append(data.Tiers.ElementsAs(ctx, &tiers, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tierNames := sortedKeys(tiers)
+	var maskLengths []int
+	for _, tier := range tierNames {
+		maskLength := int(tiers[tier])
+		if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+			resp.Diagnostics.AddAttributeError(path.Root("tiers").AtMapKey(tier), "Invalid CIDR mask length", fmt.Sprintf("CIDR mask length of tier %q must be between %d and %d to fit in %s, got: %d", tier, prefix.Bits(), prefix.Addr().BitLen(), prefix, maskLength))
+		}
+		for range zones {
+			maskLengths = append(maskLengths, maskLength)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	prefixes, err := subnet.Pack(prefix, maskLengths)
+	if err != nil {
+		resp.Diagnostics.AddError("Layout does not fit", fmt.Sprintf("Unable to lay out %d tiers in %d availability zones: %v", len(tiers), len(zones), err))
+		return
+	}
+
+	subnets := make(map[string]map[string]string, len(tiers))
+	for i, tier := range tierNames {
+		subnets[tier] = make(map[string]string, len(zones))
+		for j, zone := range zones {
+			subnets[tier][zone] = prefixes[i*len(zones)+j].String()
+		}
+	}
+	subnetMap, diags := types.MapValueFrom(ctx, types.MapType{ElemType: types.StringType}, subnets)
+	resp.Diagnostics.Append(diags...)
+	data.Subnets = subnetMap
+	free, diags := prefixList(ctx, subnet.Subtract(prefix, prefixes))
+	resp.Diagnostics.Append(diags...)
+	data.FreeCIDRBlocks = free
+	data.ID = types.StringValue(prefix.String())
+
+	tflog.Trace(ctx, "read a VPC layout data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
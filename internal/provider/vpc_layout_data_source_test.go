@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVPCLayoutDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Size validation
+			{
+				Config: `
+				data "netcalc_vpc_layout" "too_big" {
+					cidr_block         = "10.0.0.0/16"
+					availability_zones = ["a", "b", "c"]
+					tiers              = { private = 17 }
+				}`,
+				ExpectError: regexp.MustCompile(`Layout\s+does\s+not\s+fit`),
+			},
+			// Read testing
+			{
+				Config: `
+				data "netcalc_vpc_layout" "test" {
+					cidr_block         = "10.0.0.0/16"
+					availability_zones = ["us-east-1a", "us-east-1b", "us-east-1c"]
+					tiers = {
+						public  = 24
+						private = 20
+						db      = 26
+					}
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "id", "10.0.0.0/16"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.%", "3"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.private.us-east-1a", "10.0.0.0/20"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.private.us-east-1c", "10.0.32.0/20"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.public.us-east-1a", "10.0.48.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.public.us-east-1b", "10.0.49.0/24"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.db.us-east-1a", "10.0.51.0/26"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "subnets.db.us-east-1c", "10.0.51.128/26"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "free_cidr_blocks.#", "5"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "free_cidr_blocks.0", "10.0.51.192/26"),
+					resource.TestCheckResourceAttr("data.netcalc_vpc_layout.test", "free_cidr_blocks.4", "10.0.128.0/17"),
+				),
+			},
+		},
+	})
+}
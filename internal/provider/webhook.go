@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// webhookModel describes the webhook provider attribute.
+type webhookModel struct {
+	URL     types.String `tfsdk:"url"`
+	Headers types.Map    `tfsdk:"headers"`
+}
+
+func webhookAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Sends an event to a URL for every CIDR block allocated or released by netcalc_subnet, netcalc_subnet_group, netcalc_subnet_pair, netcalc_dual_stack_subnet, netcalc_static_subnet and netcalc_subnets resources, so external inventory systems stay in sync without polling. Events are sent with `POST` as a JSON object with the attributes `event` (`allocate` or `release`), `resource_type`, `owner`, `cidr` and `timestamp` in RFC 3339 format. Terraform does not tell providers the addresses of resources, so `owner` identifies the resource instead: it is the allocation owner ID also recorded in the ledger, which stays the same until the resource is replaced. Failed requests are reported as warnings and not retried, since the allocation has already been made.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL the events are sent to.",
+				Required:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Headers sent with every request, e.g. `Authorization`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+// allocationEvent is the request body of webhook requests.
+type allocationEvent struct {
+	Event        string    `json:"event"`
+	ResourceType string    `json:"resource_type"`
+	Owner        string    `json:"owner"`
+	CIDR         string    `json:"cidr"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// webhook sends an event for every CIDR block allocated or released.
+type webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// newWebhook returns the webhook configured by the webhook provider
+// attribute.
+func newWebhook(ctx context.Context, data webhookModel, diagnostics *diag.Diagnostics) *webhook {
+	w := &webhook{
+		url:    data.URL.ValueString(),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	diagnostics.Append(data.Headers.ElementsAs(ctx, &w.headers, false)...)
+	return w
+}
+
+// allocated sends an allocate event for every prefix. It does nothing for a
+// nil webhook.
+func (w *webhook) allocated(ctx context.Context, resourceType string, owner string, prefixes ...netip.Prefix) diag.Diagnostics {
+	return w.send(ctx, "allocate", resourceType, owner, prefixes)
+}
+
+// released sends a release event for every prefix. It does nothing for a nil
+// webhook.
+func (w *webhook) released(ctx context.Context, resourceType string, owner string, prefixes ...netip.Prefix) diag.Diagnostics {
+	return w.send(ctx, "release", resourceType, owner, prefixes)
+}
+
+func (w *webhook) send(ctx context.Context, event string, resourceType string, owner string, prefixes []netip.Prefix) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if w == nil {
+		return diagnostics
+	}
+	for _, prefix := range prefixes {
+		if err := w.post(ctx, allocationEvent{
+			Event:        event,
+			ResourceType: resourceType,
+			Owner:        owner,
+			CIDR:         prefix.String(),
+			Timestamp:    time.Now().UTC(),
+		}); err != nil {
+			diagnostics.AddWarning("Webhook error", fmt.Sprintf("Unable to send the %s event of %s: %v", event, prefix, err))
+			continue
+		}
+		tflog.Debug(ctx, "sent webhook event", map[string]interface{}{"event": event, "cidr": prefix.String()})
+	}
+	return diagnostics
+}
+
+func (w *webhook) post(ctx context.Context, event allocationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("POST %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// fakeWebhook records the events it receives.
+type fakeWebhook struct {
+	m      sync.Mutex
+	events []allocationEvent
+}
+
+func newFakeWebhook(t *testing.T) (*fakeWebhook, *httptest.Server) {
+	f := &fakeWebhook{}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	var e allocationEvent
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.events = append(f.events, e)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestAccProviderWebhook(t *testing.T) {
+	fake, server := newFakeWebhook(t)
+	var owner string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProviderWebhookConfig(server.URL, "secret", `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+					func(s *terraform.State) error {
+						fake.m.Lock()
+						defer fake.m.Unlock()
+						if len(fake.events) != 1 {
+							return fmt.Errorf("expected 1 event, got %v", fake.events)
+						}
+						e := fake.events[0]
+						if e.Event != "allocate" || e.ResourceType != "netcalc_subnet" || e.CIDR != "10.0.0.0/24" || e.Owner == "" || e.Timestamp.IsZero() {
+							return fmt.Errorf("unexpected event %+v", e)
+						}
+						owner = e.Owner
+						return nil
+					},
+				),
+			},
+			// Releasing allocations
+			{
+				Config: testAccProviderWebhookConfig(server.URL, "secret", ""),
+				Check: func(s *terraform.State) error {
+					fake.m.Lock()
+					defer fake.m.Unlock()
+					if len(fake.events) != 2 {
+						return fmt.Errorf("expected 2 events, got %v", fake.events)
+					}
+					e := fake.events[1]
+					if e.Event != "release" || e.ResourceType != "netcalc_subnet" || e.CIDR != "10.0.0.0/24" || e.Owner != owner {
+						return fmt.Errorf("unexpected event %+v", e)
+					}
+					return nil
+				},
+			},
+			// Failed requests are reported without failing the apply.
+			{
+				Config: testAccProviderWebhookConfig(server.URL, "wrong", `
+				resource "netcalc_subnet" "test" {
+					cidr_mask_length = 24
+				}`),
+				Check: resource.TestCheckResourceAttr("netcalc_subnet.test", "cidr_block", "10.0.0.0/24"),
+			},
+		},
+	})
+}
+
+func testAccProviderWebhookConfig(url string, token string, resources string) string {
+	return fmt.Sprintf(`
+provider "netcalc" {
+  pool_cidr_blocks = ["10.0.0.0/16"]
+
+  webhook = {
+    url = %[1]q
+    headers = {
+      Authorization = "Bearer %[2]s"
+    }
+  }
+}
+%[3]s
+`, url, token, resources)
+}
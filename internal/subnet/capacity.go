@@ -0,0 +1,281 @@
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+
+	iradix "github.com/hashicorp/go-immutable-radix"
+)
+
+// Pools returns the pools of one IP family in address order.
+func (c *Calculator) Pools(ipv6 bool) []netip.Prefix {
+	if ipv6 {
+		return treePrefixes(c.IPv6Pools)
+	}
+	return treePrefixes(c.IPv4Pools)
+}
+
+// AllocatedPrefixes returns the allocated prefixes of one IP family in address order.
+func (c *Calculator) AllocatedPrefixes(ipv6 bool) []netip.Prefix {
+	if ipv6 {
+		return treePrefixes(c.AllocatedIPv6Prefixes)
+	}
+	return treePrefixes(c.AllocatedIPv4Prefixes)
+}
+
+// FreePrefixes returns the unallocated, unreserved space of every pool of one
+// IP family as a list of minimal CIDR blocks.
+func (c *Calculator) FreePrefixes(ipv6 bool) []netip.Prefix {
+	allocated := append(c.AllocatedPrefixes(ipv6), c.ReservedPrefixes...)
+	var free []netip.Prefix
+	for _, pool := range c.Pools(ipv6) {
+		free = append(free, Subtract(pool, allocated)...)
+	}
+	return free
+}
+
+// AvailableSubnetCount returns how many more subnets of the given mask length
+// can be allocated from the pools of one IP family.
+func (c *Calculator) AvailableSubnetCount(ipv6 bool, maskLength int) *big.Int {
+	return CountSubnets(c.FreePrefixes(ipv6), maskLength)
+}
+
+// Subtract removes the used prefixes from a prefix and returns what is left
+// as a list of minimal CIDR blocks in address order.
+func Subtract(prefix netip.Prefix, used []netip.Prefix) []netip.Prefix {
+	prefix = prefix.Masked()
+	var overlapping []netip.Prefix
+	for _, u := range used {
+		if u.Addr().Is4() != prefix.Addr().Is4() || !u.Overlaps(prefix) {
+			continue
+		}
+		if u.Bits() <= prefix.Bits() {
+			// The whole prefix is used.
+			return nil
+		}
+		overlapping = append(overlapping, u)
+	}
+	if len(overlapping) == 0 {
+		return []netip.Prefix{prefix}
+	}
+	lower, upper := split(prefix)
+	return append(Subtract(lower, overlapping), Subtract(upper, overlapping)...)
+}
+
+// NextSubnet returns the first subnet of the given mask length in a prefix
+// that does not overlap any of the used prefixes, without needing a
+// calculator.
+func NextSubnet(prefix netip.Prefix, used []netip.Prefix, maskLength int) (netip.Prefix, error) {
+	prefix = prefix.Masked()
+	if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+		return netip.Prefix{}, fmt.Errorf("mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen())
+	}
+	for _, free := range Subtract(prefix, used) {
+		if free.Bits() <= maskLength {
+			return netip.PrefixFrom(free.Addr(), maskLength), nil
+		}
+	}
+	return netip.Prefix{}, exhausted(maskLength, []netip.Prefix{prefix}, func(pool netip.Prefix) []netip.Prefix {
+		return Subtract(pool, used)
+	})
+}
+
+// CountSubnets returns how many subnets of the given mask length fit in a
+// list of non-overlapping prefixes.
+func CountSubnets(prefixes []netip.Prefix, maskLength int) *big.Int {
+	count := new(big.Int)
+	for _, p := range prefixes {
+		if p.Bits() > maskLength || maskLength > p.Addr().BitLen() {
+			continue
+		}
+		count.Add(count, new(big.Int).Lsh(big.NewInt(1), uint(maskLength-p.Bits())))
+	}
+	return count
+}
+
+// HostCount returns the number of usable host addresses in a prefix, which
+// excludes the network and broadcast addresses of IPv4 subnets larger than a
+// /31.
+func HostCount(prefix netip.Prefix) *big.Int {
+	count := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		count.Sub(count, big.NewInt(2))
+	}
+	return count
+}
+
+// SplitPrefix divides a prefix into all of its subnets of the given mask
+// length, in address order.
+func SplitPrefix(prefix netip.Prefix, maskLength int) ([]netip.Prefix, error) {
+	prefix = prefix.Masked()
+	if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen())
+	}
+	subnets := []netip.Prefix{prefix}
+	for bits := prefix.Bits(); bits < maskLength; bits++ {
+		halves := make([]netip.Prefix, 0, len(subnets)*2)
+		for _, p := range subnets {
+			lower, upper := split(p)
+			halves = append(halves, lower, upper)
+		}
+		subnets = halves
+	}
+	return subnets, nil
+}
+
+// Pack carves subnets of the given mask lengths out of a prefix, returning
+// them in the order of the mask lengths. The largest subnets are placed first,
+// which leaves no gaps between them, so the subnets fit whenever their total
+// size does. Subnets of the same size are placed in the order given.
+func Pack(prefix netip.Prefix, maskLengths []int) ([]netip.Prefix, error) {
+	prefix = prefix.Masked()
+	order := make([]int, len(maskLengths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return maskLengths[order[i]] < maskLengths[order[j]] })
+
+	subnets := make([]netip.Prefix, len(maskLengths))
+	next := prefix.Addr()
+	for n, i := range order {
+		maskLength := maskLengths[i]
+		if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+			return nil, fmt.Errorf("mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen())
+		}
+		if !next.IsValid() || !prefix.Contains(next) {
+			return nil, fmt.Errorf("subnets do not fit in %s, only %d of %d could be placed", prefix, n, len(order))
+		}
+		subnets[i] = netip.PrefixFrom(next, maskLength)
+		next = LastAddr(subnets[i]).Next()
+	}
+	return subnets, nil
+}
+
+// Sequential carves subnets of the given mask lengths out of a prefix one
+// after another, like Terraform's cidrsubnets function. Each subnet starts at
+// the first address after the previous subnet that is aligned to its size, so
+// placing a larger subnet after a smaller one can leave a gap.
+func Sequential(prefix netip.Prefix, maskLengths []int) ([]netip.Prefix, error) {
+	prefix = prefix.Masked()
+	subnets := make([]netip.Prefix, 0, len(maskLengths))
+	next := prefix.Addr()
+	for _, maskLength := range maskLengths {
+		if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+			return nil, fmt.Errorf("mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen())
+		}
+		if !next.IsValid() || !prefix.Contains(next) {
+			return nil, fmt.Errorf("subnets do not fit in %s, only %d of %d could be placed", prefix, len(subnets), len(maskLengths))
+		}
+		s := netip.PrefixFrom(next, maskLength).Masked()
+		if s.Addr().Less(next) {
+			start := LastAddr(s).Next()
+			if !start.IsValid() || !prefix.Contains(start) {
+				return nil, fmt.Errorf("subnets do not fit in %s, only %d of %d could be placed", prefix, len(subnets), len(maskLengths))
+			}
+			s = netip.PrefixFrom(start, maskLength)
+		}
+		subnets = append(subnets, s)
+		next = LastAddr(s).Next()
+	}
+	return subnets, nil
+}
+
+// NthSubnet returns the subnet at index among the subnets of the given mask
+// length in a prefix, in address order, like Terraform's cidrsubnet function.
+// Negative indexes count back from the last subnet.
+func NthSubnet(prefix netip.Prefix, maskLength int, index *big.Int) (netip.Prefix, error) {
+	prefix = prefix.Masked()
+	if maskLength < prefix.Bits() || maskLength > prefix.Addr().BitLen() {
+		return netip.Prefix{}, fmt.Errorf("mask length /%d must be between /%d and /%d", maskLength, prefix.Bits(), prefix.Addr().BitLen())
+	}
+	count := new(big.Int).Lsh(big.NewInt(1), uint(maskLength-prefix.Bits()))
+	offset := new(big.Int).Set(index)
+	if index.Sign() < 0 {
+		offset.Add(offset, count)
+	}
+	if offset.Sign() < 0 || offset.Cmp(count) >= 0 {
+		return netip.Prefix{}, fmt.Errorf("subnet index %s is outside %s, which has %s /%d subnets", index, prefix, count, maskLength)
+	}
+	addr, err := HostAddrBig(prefix, offset.Lsh(offset, uint(prefix.Addr().BitLen()-maskLength)))
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, maskLength), nil
+}
+
+// SubnetIndex returns the index of a subnet among the subnets of the same
+// size in a prefix, the inverse of NthSubnet, and fails if the subnet is not
+// within the prefix.
+func SubnetIndex(prefix netip.Prefix, subnet netip.Prefix) (*big.Int, error) {
+	prefix, subnet = prefix.Masked(), subnet.Masked()
+	if subnet.Addr().Is4() != prefix.Addr().Is4() || subnet.Bits() < prefix.Bits() || !prefix.Contains(subnet.Addr()) {
+		return nil, fmt.Errorf("%s is not within %s", subnet, prefix)
+	}
+	offset := new(big.Int).SetBytes(subnet.Addr().AsSlice())
+	offset.Sub(offset, new(big.Int).SetBytes(prefix.Addr().AsSlice()))
+	return offset.Rsh(offset, uint(subnet.Addr().BitLen()-subnet.Bits())), nil
+}
+
+// Supernet returns the smallest prefix that covers all of the given prefixes,
+// which must be of the same IP family.
+func Supernet(prefixes []netip.Prefix) (netip.Prefix, error) {
+	if len(prefixes) == 0 {
+		return netip.Prefix{}, fmt.Errorf("at least one CIDR block is required")
+	}
+	supernet := prefixes[0].Masked()
+	for _, p := range prefixes[1:] {
+		if p.Addr().Is4() != supernet.Addr().Is4() {
+			return netip.Prefix{}, fmt.Errorf("CIDR blocks %s and %s are of different IP families", supernet, p)
+		}
+		bits := supernet.Bits()
+		if p.Bits() < bits {
+			bits = p.Bits()
+		}
+		for ; bits > 0; bits-- {
+			if netip.PrefixFrom(supernet.Addr(), bits).Masked().Contains(p.Addr()) {
+				break
+			}
+		}
+		supernet = netip.PrefixFrom(supernet.Addr(), bits).Masked()
+	}
+	return supernet, nil
+}
+
+// prefixWithin reports whether a prefix is entirely contained in any of the
+// given prefixes.
+func prefixWithin(prefix netip.Prefix, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Bits() <= prefix.Bits() && p.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// split divides a prefix into its two halves.
+func split(prefix netip.Prefix) (netip.Prefix, netip.Prefix) {
+	bits := prefix.Bits() + 1
+	lower := netip.PrefixFrom(prefix.Addr(), bits)
+	if prefix.Addr().Is4() {
+		addr, _ := increment4(prefix.Addr().As4(), bits)
+		return lower, netip.PrefixFrom(netip.AddrFrom4(addr), bits)
+	}
+	addr, _ := increment16(prefix.Addr().As16(), bits)
+	upper := netip.PrefixFrom(netip.AddrFrom16(addr), bits)
+	return lower, upper
+}
+
+func treePrefixes(tree *iradix.Tree) []netip.Prefix {
+	var prefixes []netip.Prefix
+	tree.Root().Walk(func(k []byte, v interface{}) bool {
+		n, ok := v.(netip.Prefix)
+		if !ok {
+			panic("unexpected node type found in radix tree")
+		}
+		prefixes = append(prefixes, n)
+		return false
+	})
+	return prefixes
+}
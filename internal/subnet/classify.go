@@ -0,0 +1,78 @@
+package subnet
+
+import "net/netip"
+
+// Address classes returned by Classify.
+const (
+	ClassPrivate       = "private"
+	ClassCGNAT         = "cgnat"
+	ClassLoopback      = "loopback"
+	ClassLinkLocal     = "link_local"
+	ClassMulticast     = "multicast"
+	ClassDocumentation = "documentation"
+	ClassBenchmarking  = "benchmarking"
+	ClassUniqueLocal   = "unique_local"
+	ClassUnspecified   = "unspecified"
+	ClassReserved      = "reserved"
+	ClassGlobalUnicast = "global_unicast"
+)
+
+// specialPurposePrefixes are the special-purpose ranges Classify recognises,
+// from the IANA IPv4 and IPv6 special-purpose address registries.
+var specialPurposePrefixes = []struct {
+	class  string
+	prefix netip.Prefix
+}{
+	{ClassUnspecified, netip.MustParsePrefix("0.0.0.0/8")},
+	{ClassPrivate, netip.MustParsePrefix("10.0.0.0/8")},
+	{ClassCGNAT, netip.MustParsePrefix("100.64.0.0/10")},
+	{ClassLoopback, netip.MustParsePrefix("127.0.0.0/8")},
+	{ClassLinkLocal, netip.MustParsePrefix("169.254.0.0/16")},
+	{ClassPrivate, netip.MustParsePrefix("172.16.0.0/12")},
+	{ClassReserved, netip.MustParsePrefix("192.0.0.0/24")},
+	{ClassDocumentation, netip.MustParsePrefix("192.0.2.0/24")},
+	{ClassPrivate, netip.MustParsePrefix("192.168.0.0/16")},
+	{ClassBenchmarking, netip.MustParsePrefix("198.18.0.0/15")},
+	{ClassDocumentation, netip.MustParsePrefix("198.51.100.0/24")},
+	{ClassDocumentation, netip.MustParsePrefix("203.0.113.0/24")},
+	{ClassMulticast, netip.MustParsePrefix("224.0.0.0/4")},
+	{ClassReserved, netip.MustParsePrefix("240.0.0.0/4")},
+	{ClassUnspecified, netip.MustParsePrefix("::/128")},
+	{ClassLoopback, netip.MustParsePrefix("::1/128")},
+	{ClassReserved, netip.MustParsePrefix("::ffff:0:0/96")},
+	{ClassReserved, netip.MustParsePrefix("64:ff9b::/96")},
+	{ClassReserved, netip.MustParsePrefix("100::/64")},
+	{ClassBenchmarking, netip.MustParsePrefix("2001:2::/48")},
+	{ClassDocumentation, netip.MustParsePrefix("2001:db8::/32")},
+	{ClassDocumentation, netip.MustParsePrefix("3fff::/20")},
+	{ClassUniqueLocal, netip.MustParsePrefix("fc00::/7")},
+	{ClassLinkLocal, netip.MustParsePrefix("fe80::/10")},
+	{ClassMulticast, netip.MustParsePrefix("ff00::/8")},
+}
+
+// ipv6GlobalUnicast is the IPv6 global unicast range.
+var ipv6GlobalUnicast = netip.MustParsePrefix("2000::/3")
+
+// Classify returns the classes of the special-purpose ranges a prefix lies
+// entirely within, in the order of the ranges, e.g. private for 10.1.0.0/16.
+// A prefix that overlaps no special-purpose range, and for IPv6 lies within
+// 2000::/3, is global_unicast. A prefix that spans several ranges, or a
+// special-purpose range and global unicast addresses, has no class.
+func Classify(prefix netip.Prefix) []string {
+	prefix = prefix.Masked()
+	var classes []string
+	overlaps := false
+	for _, s := range specialPurposePrefixes {
+		if !s.prefix.Overlaps(prefix) {
+			continue
+		}
+		overlaps = true
+		if s.prefix.Bits() <= prefix.Bits() {
+			classes = append(classes, s.class)
+		}
+	}
+	if !overlaps && (prefix.Addr().Is4() || ipv6GlobalUnicast.Bits() <= prefix.Bits() && ipv6GlobalUnicast.Contains(prefix.Addr())) {
+		classes = append(classes, ClassGlobalUnicast)
+	}
+	return classes
+}
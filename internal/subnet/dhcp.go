@@ -0,0 +1,72 @@
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// DHCPScope describes the addresses a DHCP server leases in a subnet.
+type DHCPScope struct {
+	// Range is the range of leased addresses.
+	Range AddressRange
+	// Router is the default gateway.
+	Router netip.Addr
+	// Exclusions are the ranges within Range that are not leased.
+	Exclusions []AddressRange
+}
+
+// NewDHCPScope plans the DHCP scope of a subnet. The router is the address at
+// routerIndex, following the conventions of HostAddr, and must be a usable host
+// address. The scope spans the usable host addresses, less reservedHead
+// addresses at the start and reservedTail addresses at the end, and never
+// includes the subnet-router anycast address of an IPv6 subnet. A router at
+// either end of the scope is left out of it, and a router inside it is
+// excluded.
+func NewDHCPScope(prefix netip.Prefix, routerIndex int64, reservedHead, reservedTail uint64) (DHCPScope, error) {
+	prefix = prefix.Masked()
+	hosts := HostRange(prefix)
+	if prefix.Addr().Is6() && hosts.Start != hosts.End {
+		// The first address of an IPv6 subnet is the subnet-router anycast
+		// address, which is never leased.
+		hosts.Start = hosts.Start.Next()
+	}
+	router, err := HostAddr(prefix, routerIndex)
+	if err != nil {
+		return DHCPScope{}, err
+	}
+	if router.Compare(hosts.Start) < 0 || router.Compare(hosts.End) > 0 {
+		return DHCPScope{}, fmt.Errorf("router %s is not a usable host address of %s", router, prefix)
+	}
+
+	reserved := new(big.Int).SetUint64(reservedHead)
+	reserved.Add(reserved, new(big.Int).SetUint64(reservedTail))
+	if reserved.Cmp(hosts.Size()) >= 0 {
+		return DHCPScope{}, fmt.Errorf("reserving %d addresses leaves no addresses to lease in %s", reserved, prefix)
+	}
+	start, _ := addToAddr(hosts.Start, reservedHead)
+	scope := DHCPScope{
+		Range:  AddressRange{Start: start, End: subFromAddr(hosts.End, reservedTail)},
+		Router: router,
+	}
+	switch {
+	case router == scope.Range.Start && router == scope.Range.End:
+		return DHCPScope{}, fmt.Errorf("router %s is the only address left to lease in %s", router, prefix)
+	case router == scope.Range.Start:
+		scope.Range.Start = router.Next()
+	case router == scope.Range.End:
+		scope.Range.End = router.Prev()
+	case router.Compare(scope.Range.Start) > 0 && router.Compare(scope.Range.End) < 0:
+		scope.Exclusions = append(scope.Exclusions, AddressRange{Start: router, End: router})
+	}
+	return scope, nil
+}
+
+// Size returns the number of addresses leased in the scope.
+func (s DHCPScope) Size() *big.Int {
+	size := s.Range.Size()
+	for _, e := range s.Exclusions {
+		size.Sub(size, e.Size())
+	}
+	return size
+}
@@ -0,0 +1,77 @@
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// ExhaustedError is returned when no subnet of the requested mask length is
+// available in the pools searched. It describes what is left of the pools, so
+// users can tell whether to add a pool or ask for a smaller subnet.
+type ExhaustedError struct {
+	MaskLength int
+	// Pools are the utilization of the pools searched, in the order searched.
+	Pools []PoolUtilization
+	// LargestFree is the largest free CIDR block left in the pools, or the
+	// zero prefix if they are full. A subnet with its mask length, or a longer
+	// one, would still fit.
+	LargestFree netip.Prefix
+}
+
+// PoolUtilization is how much of a pool is allocated or reserved.
+type PoolUtilization struct {
+	Pool netip.Prefix
+	// Percent of the addresses of the pool that are used.
+	Percent float64
+}
+
+func (e *ExhaustedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "No eligible subnet with mask /%v found", e.MaskLength)
+	if len(e.Pools) == 0 {
+		b.WriteString(": there are no pools")
+		return b.String()
+	}
+	b.WriteString(": ")
+	for i, pool := range e.Pools {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "pool %s is %.1f%% used", pool.Pool, pool.Percent)
+	}
+	if !e.LargestFree.IsValid() {
+		b.WriteString("; no free space is left")
+	} else {
+		fmt.Fprintf(&b, "; the largest free CIDR block is %s, so a subnet with mask /%d or longer would still fit", e.LargestFree, e.LargestFree.Bits())
+	}
+	return b.String()
+}
+
+// exhausted returns the error for pools without a free subnet of the given
+// mask length, where free returns the free space of a pool.
+func exhausted(numBits int, pools []netip.Prefix, free func(pool netip.Prefix) []netip.Prefix) error {
+	e := &ExhaustedError{MaskLength: numBits}
+	for _, pool := range pools {
+		size := CountSubnets([]netip.Prefix{pool}, pool.Addr().BitLen())
+		poolFree := free(pool)
+		used := new(big.Int).Sub(size, CountSubnets(poolFree, pool.Addr().BitLen()))
+		percent, _ := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Mul(used, big.NewInt(100))), new(big.Float).SetInt(size)).Float64()
+		e.Pools = append(e.Pools, PoolUtilization{Pool: pool, Percent: percent})
+		for _, f := range poolFree {
+			if !e.LargestFree.IsValid() || f.Bits() < e.LargestFree.Bits() {
+				e.LargestFree = f
+			}
+		}
+	}
+	return e
+}
+
+// exhausted returns the error for the pools of one IP family of the
+// calculator without a free subnet of the given mask length.
+func (c *Calculator) exhausted(ipv6 bool, numBits int) error {
+	return exhausted(numBits, c.searchOrder(c.Pools(ipv6)), func(pool netip.Prefix) []netip.Prefix {
+		return c.FreePrefixesInPool(pool, nil)
+	})
+}
@@ -0,0 +1,53 @@
+package subnet
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// interfaceIDBits is the length of an IPv6 interface identifier.
+const interfaceIDBits = 64
+
+// EUI64Addr returns the address with the modified EUI-64 interface identifier
+// of a MAC address (RFC 4291 appendix A) in an IPv6 prefix of at most /64.
+// 48-bit MAC addresses are extended with ff:fe in the middle.
+func EUI64Addr(prefix netip.Prefix, mac net.HardwareAddr) (netip.Addr, error) {
+	prefix = prefix.Masked()
+	if !prefix.Addr().Is6() || prefix.Bits() > 128-interfaceIDBits {
+		return netip.Addr{}, fmt.Errorf("%s is not an IPv6 prefix of /%d or shorter", prefix, 128-interfaceIDBits)
+	}
+	var id []byte
+	switch len(mac) {
+	case 6:
+		id = []byte{mac[0], mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]}
+	case 8:
+		id = append(id, mac...)
+	default:
+		return netip.Addr{}, fmt.Errorf("%s is not a 48-bit or 64-bit MAC address", mac)
+	}
+	// Invert the universal/local bit.
+	id[0] ^= 0x02
+	a := prefix.Addr().As16()
+	copy(a[8:], id)
+	return netip.AddrFrom16(a), nil
+}
+
+// StableAddr returns an address in a prefix whose host bits are derived from
+// the SHA-256 digest of the prefix and a key, in the spirit of RFC 7217 stable
+// opaque interface identifiers. The same prefix and key always yield the same
+// address, and the first address of the prefix is never returned.
+func StableAddr(prefix netip.Prefix, key string) netip.Addr {
+	prefix = prefix.Masked()
+	sum := sha256.Sum256([]byte(prefix.String() + "\x00" + key))
+	a := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(a)*8; i++ {
+		a[i/8] |= sum[i/8] & (128 >> (i % 8))
+	}
+	addr, _ := netip.AddrFromSlice(a)
+	if addr == prefix.Addr() && prefix.Bits() < addr.BitLen() {
+		addr = addr.Next()
+	}
+	return addr
+}
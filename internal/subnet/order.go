@@ -0,0 +1,32 @@
+package subnet
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// SortPools returns a copy of pools in the order subnets are searched for in
+// them: pools with a higher priority first, and pools of the same priority
+// in canonical order, IPv4 before IPv6, then by address and mask length.
+// Pools without a priority have priority 0. The order only depends on the
+// pools and priorities, not on the order they are given in, so the same
+// configuration always allocates the same subnets.
+func SortPools(pools []netip.Prefix, priorities map[netip.Prefix]int) []netip.Prefix {
+	sorted := append([]netip.Prefix(nil), pools...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if pi, pj := priorities[sorted[i]], priorities[sorted[j]]; pi != pj {
+			return pi > pj
+		}
+		if c := sorted[i].Addr().Compare(sorted[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Bits() < sorted[j].Bits()
+	})
+	return sorted
+}
+
+// searchOrder returns pools in the order subnets are searched for in them,
+// using the calculator's pool priorities.
+func (c *Calculator) searchOrder(pools []netip.Prefix) []netip.Prefix {
+	return SortPools(pools, c.PoolPriorities)
+}
@@ -0,0 +1,357 @@
+package subnet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// AddressRange is an inclusive range of addresses of one IP family.
+type AddressRange struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+func (r AddressRange) String() string {
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// Overlaps reports whether two ranges share any address.
+func (r AddressRange) Overlaps(o AddressRange) bool {
+	return r.Start.Compare(o.End) <= 0 && o.Start.Compare(r.End) <= 0
+}
+
+// Prefixes returns the minimal list of CIDR blocks covering the range, in
+// address order.
+func (r AddressRange) Prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	start := r.Start
+	for start.IsValid() && start.Compare(r.End) <= 0 {
+		// Find the largest aligned block that starts at start and ends
+		// within the range.
+		bits := 0
+		for ; bits < start.BitLen(); bits++ {
+			p := netip.PrefixFrom(start, bits)
+			if p.Masked().Addr() == start && LastAddr(p).Compare(r.End) <= 0 {
+				break
+			}
+		}
+		p := netip.PrefixFrom(start, bits)
+		prefixes = append(prefixes, p)
+		start = LastAddr(p).Next()
+	}
+	return prefixes
+}
+
+// Size returns the number of addresses in the range.
+func (r AddressRange) Size() *big.Int {
+	start := new(big.Int).SetBytes(r.Start.AsSlice())
+	end := new(big.Int).SetBytes(r.End.AsSlice())
+	return end.Sub(end, start).Add(end, big.NewInt(1))
+}
+
+// Gaps removes the used prefixes from a prefix and returns what is left as
+// ranges of contiguous addresses in address order.
+func Gaps(prefix netip.Prefix, used []netip.Prefix) []AddressRange {
+	var gaps []AddressRange
+	for _, free := range Subtract(prefix, used) {
+		if n := len(gaps); n > 0 && gaps[n-1].End.Next() == free.Addr() {
+			gaps[n-1].End = LastAddr(free)
+			continue
+		}
+		gaps = append(gaps, AddressRange{Start: free.Addr(), End: LastAddr(free)})
+	}
+	return gaps
+}
+
+// ParseAddressRange parses a range in the form "start-end".
+func ParseAddressRange(s string) (AddressRange, error) {
+	first, last, ok := strings.Cut(s, "-")
+	if !ok {
+		return AddressRange{}, fmt.Errorf("address range %q must be in the form start-end", s)
+	}
+	start, err := netip.ParseAddr(first)
+	if err != nil {
+		return AddressRange{}, err
+	}
+	end, err := netip.ParseAddr(last)
+	if err != nil {
+		return AddressRange{}, err
+	}
+	if start.Is4() != end.Is4() || start.Compare(end) > 0 {
+		return AddressRange{}, fmt.Errorf("invalid address range %q", s)
+	}
+	return AddressRange{Start: start, End: end}, nil
+}
+
+// HostRange returns the range of usable host addresses in a prefix. IPv4
+// subnets larger than a /31 exclude the network and broadcast addresses.
+func HostRange(prefix netip.Prefix) AddressRange {
+	prefix = prefix.Masked()
+	r := AddressRange{Start: prefix.Addr(), End: LastAddr(prefix)}
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		r.Start = r.Start.Next()
+		r.End = r.End.Prev()
+	}
+	return r
+}
+
+// LastAddr returns the last address in a prefix.
+func LastAddr(prefix netip.Prefix) netip.Addr {
+	prefix = prefix.Masked()
+	if prefix.Addr().Is4() {
+		a := prefix.Addr().As4()
+		n := binary.BigEndian.Uint32(a[:]) | ^uint32(0)>>prefix.Bits()
+		binary.BigEndian.PutUint32(a[:], n)
+		return netip.AddrFrom4(a)
+	}
+	a := prefix.Addr().As16()
+	for i := prefix.Bits(); i < 128; i++ {
+		a[i/8] |= 128 >> (i % 8)
+	}
+	return netip.AddrFrom16(a)
+}
+
+// NextAvailableRange finds the first range of count host addresses within a
+// prefix that does not overlap any allocated range, and fails if none is
+// available. Allocated prefixes inside the prefix, such as the CIDR blocks of
+// ranges created in earlier applies, and reserved prefixes are treated as
+// allocated ranges too.
+func (c *Calculator) NextAvailableRange(prefix netip.Prefix, count uint64) (AddressRange, error) {
+	if count == 0 {
+		return AddressRange{}, fmt.Errorf("address count must be at least 1")
+	}
+	used := append([]AddressRange(nil), c.AllocatedRanges...)
+	for _, p := range c.AllocatedPrefixes(prefix.Addr().Is6()) {
+		if p.Bits() > prefix.Bits() && prefix.Contains(p.Addr()) {
+			used = append(used, AddressRange{Start: p.Masked().Addr(), End: LastAddr(p)})
+		}
+	}
+	for _, r := range c.ReservedPrefixes {
+		used = append(used, AddressRange{Start: r.Addr(), End: LastAddr(r)})
+	}
+	hosts := HostRange(prefix)
+	start := hosts.Start
+	for start.IsValid() && start.Compare(hosts.End) <= 0 {
+		end, ok := addToAddr(start, count-1)
+		if !ok || end.Compare(hosts.End) > 0 {
+			break
+		}
+		candidate := AddressRange{Start: start, End: end}
+		conflict, found := overlappingRange(candidate, used)
+		if !found {
+			c.AllocatedRanges = append(c.AllocatedRanges, candidate)
+			return candidate, nil
+		}
+		start = conflict.End.Next()
+	}
+
+	return AddressRange{}, fmt.Errorf("No free range of %d addresses found in %s", count, prefix)
+}
+
+// AddAllocatedRange marks a range as allocated.
+func (c *Calculator) AddAllocatedRange(r AddressRange) {
+	c.AllocatedRanges = append(c.AllocatedRanges, r)
+}
+
+// DeleteAllocatedRange releases an allocated range.
+func (c *Calculator) DeleteAllocatedRange(r AddressRange) {
+	for i, a := range c.AllocatedRanges {
+		if a == r {
+			c.AllocatedRanges = append(c.AllocatedRanges[:i], c.AllocatedRanges[i+1:]...)
+			return
+		}
+	}
+}
+
+// overlappingRange returns the used range overlapping r that ends last.
+func overlappingRange(r AddressRange, used []AddressRange) (AddressRange, bool) {
+	var conflict AddressRange
+	found := false
+	for _, a := range used {
+		if a.Start.Is4() != r.Start.Is4() || !a.Overlaps(r) {
+			continue
+		}
+		if !found || a.End.Compare(conflict.End) > 0 {
+			conflict = a
+			found = true
+		}
+	}
+	return conflict, found
+}
+
+// addToAddr adds n to an address, reporting false if the result overflows the
+// address family.
+func addToAddr(a netip.Addr, n uint64) (netip.Addr, bool) {
+	if a.Is4() {
+		b := a.As4()
+		sum := uint64(binary.BigEndian.Uint32(b[:])) + n
+		if sum > uint64(^uint32(0)) {
+			return netip.Addr{}, false
+		}
+		binary.BigEndian.PutUint32(b[:], uint32(sum))
+		return netip.AddrFrom4(b), true
+	}
+	b := a.As16()
+	lo := binary.BigEndian.Uint64(b[8:])
+	hi := binary.BigEndian.Uint64(b[:8])
+	sum := lo + n
+	if sum < lo {
+		if hi == ^uint64(0) {
+			return netip.Addr{}, false
+		}
+		hi++
+	}
+	binary.BigEndian.PutUint64(b[:8], hi)
+	binary.BigEndian.PutUint64(b[8:], sum)
+	return netip.AddrFrom16(b), true
+}
+
+// HostAddr returns the host address at index within a prefix, following the
+// conventions of Terraform's cidrhost function: index 0 is the network
+// address, and negative indexes count back from the last address.
+func HostAddr(prefix netip.Prefix, index int64) (netip.Addr, error) {
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	offset := index
+	if index < 0 {
+		offset = -index - 1
+	}
+	if hostBits < 63 && offset >= int64(1)<<hostBits {
+		return netip.Addr{}, fmt.Errorf("host index %d is outside %s", index, prefix)
+	}
+	if index < 0 {
+		return subFromAddr(LastAddr(prefix), uint64(offset)), nil
+	}
+	a, _ := addToAddr(prefix.Addr(), uint64(offset))
+	return a, nil
+}
+
+// HostAddrBig is like HostAddr, but accepts indexes beyond the range of an
+// int64, as needed to address all of a large IPv6 prefix.
+func HostAddrBig(prefix netip.Prefix, index *big.Int) (netip.Addr, error) {
+	prefix = prefix.Masked()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	offset := new(big.Int).Set(index)
+	if index.Sign() < 0 {
+		offset.Add(offset, size)
+	}
+	if offset.Sign() < 0 || offset.Cmp(size) >= 0 {
+		return netip.Addr{}, fmt.Errorf("host index %s is outside %s", index, prefix)
+	}
+
+	sum := new(big.Int).Add(new(big.Int).SetBytes(prefix.Addr().AsSlice()), offset)
+	b := make([]byte, prefix.Addr().BitLen()/8)
+	sum.FillBytes(b)
+	a, _ := netip.AddrFromSlice(b)
+	return a, nil
+}
+
+// Hosts returns up to limit usable host addresses of a prefix, skipping the
+// first offset of them.
+func Hosts(prefix netip.Prefix, offset, limit uint64) []netip.Addr {
+	r := HostRange(prefix)
+	hosts := []netip.Addr{}
+	a, ok := addToAddr(r.Start, offset)
+	for ; ok && uint64(len(hosts)) < limit && a.Compare(r.End) <= 0; a, ok = addToAddr(a, 1) {
+		hosts = append(hosts, a)
+	}
+	return hosts
+}
+
+// ClaimRange marks a range as allocated, and fails if it overlaps any
+// allocated range or reserved prefix, or contains an allocated prefix, such as
+// a host address claimed in an earlier apply.
+func (c *Calculator) ClaimRange(r AddressRange) error {
+	if conflict, found := overlappingRange(r, c.AllocatedRanges); found {
+		return fmt.Errorf("%s overlaps allocated range %s", r, conflict)
+	}
+	for _, p := range c.ReservedPrefixes {
+		if r.Overlaps(AddressRange{Start: p.Addr(), End: LastAddr(p)}) {
+			return fmt.Errorf("%s overlaps reserved CIDR block %s", r, p)
+		}
+	}
+	for _, p := range c.AllocatedPrefixes(r.Start.Is6()) {
+		if r.Start.Compare(p.Masked().Addr()) <= 0 && LastAddr(p).Compare(r.End) <= 0 {
+			return fmt.Errorf("%s overlaps allocated CIDR block %s", r, p)
+		}
+	}
+	c.AddAllocatedRange(r)
+	return nil
+}
+
+// subFromAddr subtracts n from an address. The result must not underflow the
+// address family.
+func subFromAddr(a netip.Addr, n uint64) netip.Addr {
+	if a.Is4() {
+		b := a.As4()
+		binary.BigEndian.PutUint32(b[:], binary.BigEndian.Uint32(b[:])-uint32(n))
+		return netip.AddrFrom4(b)
+	}
+	b := a.As16()
+	lo := binary.BigEndian.Uint64(b[8:])
+	hi := binary.BigEndian.Uint64(b[:8])
+	if n > lo {
+		hi--
+	}
+	binary.BigEndian.PutUint64(b[:8], hi)
+	binary.BigEndian.PutUint64(b[8:], lo-n)
+	return netip.AddrFrom16(b)
+}
+
+// Netmask returns the netmask of a prefix in address form, e.g.
+// 255.255.255.0 for a /24.
+func Netmask(prefix netip.Prefix) netip.Addr {
+	if prefix.Addr().Is4() {
+		var a [4]byte
+		binary.BigEndian.PutUint32(a[:], ^(^uint32(0) >> prefix.Bits()))
+		return netip.AddrFrom4(a)
+	}
+	var a [16]byte
+	for i := 0; i < prefix.Bits(); i++ {
+		a[i/8] |= 128 >> (i % 8)
+	}
+	return netip.AddrFrom16(a)
+}
+
+// WildcardMask returns the inverse of the netmask of a prefix, e.g. 0.0.0.255
+// for a /24.
+func WildcardMask(prefix netip.Prefix) netip.Addr {
+	mask := Netmask(prefix).AsSlice()
+	for i := range mask {
+		mask[i] = ^mask[i]
+	}
+	a, _ := netip.AddrFromSlice(mask)
+	return a
+}
+
+// MaskLength returns the prefix length of a netmask in address form, e.g. 24
+// for 255.255.255.0, and fails if the mask bits are not contiguous.
+func MaskLength(mask netip.Addr) (int, error) {
+	b := mask.AsSlice()
+	length := 0
+	for length < len(b)*8 && b[length/8]&(128>>(length%8)) != 0 {
+		length++
+	}
+	if Netmask(netip.PrefixFrom(mask, length)) != mask {
+		return 0, fmt.Errorf("netmask %s is not contiguous", mask)
+	}
+	return length, nil
+}
+
+// WildcardMaskLength returns the prefix length of a wildcard mask, e.g. 24
+// for 0.0.0.255, and fails if the mask bits are not contiguous.
+func WildcardMaskLength(mask netip.Addr) (int, error) {
+	b := mask.AsSlice()
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	netmask, _ := netip.AddrFromSlice(b)
+	length, err := MaskLength(netmask)
+	if err != nil {
+		return 0, fmt.Errorf("wildcard mask %s is not contiguous", mask)
+	}
+	return length, nil
+}
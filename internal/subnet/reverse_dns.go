@@ -0,0 +1,54 @@
+package subnet
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// maxIPv4ReverseZoneLength is the longest IPv4 prefix with its own
+// in-addr.arpa zone. Longer prefixes are part of the zone of their /24.
+const maxIPv4ReverseZoneLength = 24
+
+// ReverseZonePrefixes returns the prefixes of the reverse DNS zones covering a
+// prefix. Zones are delegated on octet boundaries for IPv4 and nibble
+// boundaries for IPv6, so a prefix between boundaries is covered by several
+// zones, e.g. 10.0.0.0/23 by the zones of 10.0.0.0/24 and 10.0.1.0/24. An
+// IPv4 prefix longer than a /24 is covered by the zone of its /24, which
+// contains addresses outside the prefix.
+func ReverseZonePrefixes(prefix netip.Prefix) []netip.Prefix {
+	prefix = prefix.Masked()
+	step := 4
+	if prefix.Addr().Is4() {
+		step = 8
+		if prefix.Bits() > maxIPv4ReverseZoneLength {
+			return []netip.Prefix{netip.PrefixFrom(prefix.Addr(), maxIPv4ReverseZoneLength).Masked()}
+		}
+	}
+	zoneLength := (prefix.Bits() + step - 1) / step * step
+	zones, _ := SplitPrefix(prefix, zoneLength)
+	return zones
+}
+
+// ReverseZoneName returns the reverse DNS zone name of a prefix on an octet
+// (IPv4) or nibble (IPv6) boundary, e.g. 1.0.10.in-addr.arpa for 10.0.1.0/24
+// or 8.b.d.0.1.0.0.2.ip6.arpa for 2001:db8::/32. Bits beyond the last whole
+// octet or nibble are ignored.
+func ReverseZoneName(prefix netip.Prefix) string {
+	var labels []string
+	b := prefix.Addr().AsSlice()
+	if prefix.Addr().Is4() {
+		for i := prefix.Bits()/8 - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+		return strings.Join(append(labels, "in-addr", "arpa"), ".")
+	}
+	for i := prefix.Bits()/4 - 1; i >= 0; i-- {
+		nibble := b[i/2] >> 4
+		if i%2 == 1 {
+			nibble = b[i/2] & 0xf
+		}
+		labels = append(labels, strconv.FormatUint(uint64(nibble), 16))
+	}
+	return strings.Join(append(labels, "ip6", "arpa"), ".")
+}
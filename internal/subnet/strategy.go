@@ -0,0 +1,68 @@
+package subnet
+
+import (
+	"context"
+	"net/netip"
+)
+
+// Strategy chooses where in the free space of the pools a subnet is
+// allocated.
+type Strategy string
+
+const (
+	// FirstFit allocates the subnet at the lowest free address.
+	FirstFit Strategy = "first_fit"
+	// BestFit allocates the subnet in the smallest free CIDR block it fits
+	// in, which keeps large free CIDR blocks intact for large subnets.
+	BestFit Strategy = "best_fit"
+	// Spread allocates the subnet at the start of the largest free CIDR
+	// block, which leaves room next to every subnet for it to grow into.
+	Spread Strategy = "spread"
+)
+
+// Strategies are the supported strategies.
+var Strategies = []Strategy{FirstFit, BestFit, Spread}
+
+// choose picks a subnet of the given mask length from free CIDR blocks in
+// order of preference, usually address order. Ties are broken by that order,
+// so the strategies are deterministic.
+func (s Strategy) choose(free []netip.Prefix, numBits int) (netip.Prefix, bool) {
+	best := -1
+	for i, f := range free {
+		if f.Bits() > numBits || numBits > f.Addr().BitLen() {
+			continue
+		}
+		if best < 0 || s == BestFit && f.Bits() > free[best].Bits() || s == Spread && f.Bits() < free[best].Bits() {
+			best = i
+		}
+	}
+	if best < 0 {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(free[best].Addr(), numBits), true
+}
+
+// NextAvailableSubnet finds a subnet of a given mask length in the
+// calculator's pools of one IP family using the given strategy, and fails if
+// none is available. The empty strategy is FirstFit, whose search stops when
+// the context is canceled.
+func (c *Calculator) NextAvailableSubnet(ctx context.Context, ipv6 bool, numBits int, strategy Strategy) (netip.Prefix, error) {
+	if strategy == "" || strategy == FirstFit {
+		if ipv6 {
+			return c.NextAvailableIPv6Subnet(ctx, numBits)
+		}
+		return c.NextAvailableIPv4Subnet(ctx, numBits)
+	}
+	allocated := append(c.AllocatedPrefixes(ipv6), c.ReservedPrefixes...)
+	var free []netip.Prefix
+	for _, pool := range c.searchOrder(c.Pools(ipv6)) {
+		free = append(free, Subtract(pool, allocated)...)
+	}
+	subnet, ok := strategy.choose(free, numBits)
+	c.traceChoice(strategy, free, numBits, subnet, ok)
+	if !ok {
+		return netip.Prefix{}, c.exhausted(ipv6, numBits)
+	}
+	c.HoldPrefix(subnet)
+	return subnet, nil
+}
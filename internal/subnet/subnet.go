@@ -1,9 +1,12 @@
 package subnet
 
 import (
+	"context"
 	"fmt"
-	iradix "github.com/hashicorp/go-immutable-radix"
+	"math/bits"
 	"net/netip"
+
+	iradix "github.com/hashicorp/go-immutable-radix"
 )
 
 // Calculator stores radix trees of supernets and subnets.
@@ -12,6 +15,86 @@ type Calculator struct {
 	AllocatedIPv4Prefixes *iradix.Tree
 	IPv6Pools             *iradix.Tree
 	AllocatedIPv6Prefixes *iradix.Tree
+	AllocatedRanges       []AddressRange
+	// ReservedPrefixes are never allocated or claimed, not even from pools
+	// carved out of them, and are not part of any pool.
+	ReservedPrefixes []netip.Prefix
+	// Tracer, if set, is told about every candidate considered while
+	// allocating a subnet and the subnet chosen.
+	Tracer Tracer
+	// PoolOverlap is what AddPool does with a pool that duplicates or
+	// overlaps pools already added.
+	PoolOverlap PoolOverlap
+	// PoolPriorities are the priorities of pools, keyed by pool. Subnets are
+	// allocated from pools with a higher priority first, see SortPools.
+	PoolPriorities map[netip.Prefix]int
+	// AllocationOutsidePools is what AddAllocatedPrefix does with a prefix
+	// that is not within any pool.
+	AllocationOutsidePools AllocationOutsidePools
+	// OtherPools are pools that subnets are allocated from with
+	// NextAvailableSubnetInPools, which count as pools for
+	// AllocationOutsidePools.
+	OtherPools []netip.Prefix
+}
+
+// AllocationOutsidePools is what AddAllocatedPrefix does with a prefix that
+// is not within any pool.
+type AllocationOutsidePools int
+
+const (
+	// AllocationOutsidePoolsAllow allocates the prefix.
+	AllocationOutsidePoolsAllow AllocationOutsidePools = iota
+	// AllocationOutsidePoolsWarn allocates the prefix, and returns an
+	// *OutsidePoolsError for the caller to report as a warning.
+	AllocationOutsidePoolsWarn
+	// AllocationOutsidePoolsError rejects the prefix with an
+	// *OutsidePoolsError.
+	AllocationOutsidePoolsError
+)
+
+// OutsidePoolsError is returned by AddAllocatedPrefix for a prefix that is
+// not within any pool.
+type OutsidePoolsError struct {
+	Prefix netip.Prefix
+	// Allocated is whether Prefix was allocated rather than rejected.
+	Allocated bool
+}
+
+func (e *OutsidePoolsError) Error() string {
+	return fmt.Sprintf("%s is not within any pool", e.Prefix)
+}
+
+// PoolOverlap is what AddPool does with a pool that duplicates or overlaps
+// pools already added.
+type PoolOverlap int
+
+const (
+	// PoolOverlapMerge merges the pool with the pools it overlaps, so the
+	// larger of them remains.
+	PoolOverlapMerge PoolOverlap = iota
+	// PoolOverlapWarn merges the pool like PoolOverlapMerge, and returns an
+	// *OverlappingPoolError for the caller to report as a warning.
+	PoolOverlapWarn
+	// PoolOverlapError rejects the pool with an *OverlappingPoolError.
+	PoolOverlapError
+)
+
+// OverlappingPoolError is returned by AddPool for a pool that duplicates or
+// overlaps pools already added.
+type OverlappingPoolError struct {
+	Pool netip.Prefix
+	// Overlapping are the pools already added that Pool overlaps.
+	Overlapping []netip.Prefix
+	// Merged is whether Pool was merged with the overlapping pools rather
+	// than rejected.
+	Merged bool
+}
+
+func (e *OverlappingPoolError) Error() string {
+	if len(e.Overlapping) == 1 && e.Overlapping[0] == e.Pool {
+		return fmt.Sprintf("pool %s was already added", e.Pool)
+	}
+	return fmt.Sprintf("pool %s overlaps pools %v", e.Pool, e.Overlapping)
 }
 
 // NewCalculator creates a new Calculator from a list of supernets and subnets.
@@ -24,7 +107,65 @@ func NewCalculator() *Calculator {
 	}
 }
 
-func (c *Calculator) AddPool(prefix netip.Prefix) {
+// Clone returns a copy of the calculator that can be changed without affecting
+// the original, e.g. to try out allocations.
+func (c *Calculator) Clone() *Calculator {
+	clone := *c
+	clone.AllocatedRanges = append([]AddressRange(nil), c.AllocatedRanges...)
+	clone.ReservedPrefixes = append([]netip.Prefix(nil), c.ReservedPrefixes...)
+	return &clone
+}
+
+// AddPool adds a pool to allocate subnets from. It fails for an invalid
+// prefix or one with host bits set, and handles a pool overlapping pools
+// already added as set by PoolOverlap. A pool adjacent to a pool of the same
+// size, such as 10.0.128.0/17 next to 10.0.0.0/17, is merged with it into a
+// pool twice the size, so subnets can span both. Pools with a priority in
+// PoolPriorities are kept as they are.
+func (c *Calculator) AddPool(prefix netip.Prefix) error {
+	if !prefix.IsValid() {
+		return fmt.Errorf("invalid pool %s", prefix)
+	}
+	if prefix != prefix.Masked() {
+		return fmt.Errorf("pool %s has host bits set, did you mean %s?", prefix, prefix.Masked())
+	}
+	pools := c.IPv4Pools
+	if prefix.Addr().Is6() {
+		pools = c.IPv6Pools
+	}
+	var overlapping []netip.Prefix
+	for _, pool := range treePrefixes(pools) {
+		if pool.Overlaps(prefix) {
+			overlapping = append(overlapping, pool)
+		}
+	}
+	var err error
+	if len(overlapping) > 0 {
+		switch c.PoolOverlap {
+		case PoolOverlapError:
+			return &OverlappingPoolError{Pool: prefix, Overlapping: overlapping}
+		case PoolOverlapWarn:
+			err = &OverlappingPoolError{Pool: prefix, Overlapping: overlapping, Merged: true}
+		}
+	}
+	// Pools are kept disjoint, so a pool is either within a single pool
+	// already added, or contains all the pools it overlaps.
+	for _, pool := range overlapping {
+		if pool.Bits() <= prefix.Bits() {
+			return err
+		}
+	}
+	for _, pool := range overlapping {
+		c.DeletePool(pool)
+	}
+	for {
+		sibling, ok := siblingPrefix(prefix)
+		if !ok || !c.hasPool(sibling) || c.hasPriority(prefix) || c.hasPriority(sibling) {
+			break
+		}
+		c.DeletePool(sibling)
+		prefix, _ = prefix.Addr().Prefix(prefix.Bits() - 1)
+	}
 	addr := prefix.Addr().As16()
 	bytes := make([]byte, len(addr))
 	copy(bytes, addr[:])
@@ -33,6 +174,49 @@ func (c *Calculator) AddPool(prefix netip.Prefix) {
 	} else {
 		c.IPv6Pools, _, _ = c.IPv6Pools.Insert(bytes, prefix)
 	}
+	return err
+}
+
+// withinPools reports whether a prefix is entirely within a pool or one of
+// the OtherPools.
+func (c *Calculator) withinPools(prefix netip.Prefix) bool {
+	pools := c.Pools(prefix.Addr().Is6())
+	return prefixWithin(prefix, append(pools, c.OtherPools...))
+}
+
+// hasPool reports whether a prefix is one of the pools.
+func (c *Calculator) hasPool(prefix netip.Prefix) bool {
+	pools := c.IPv4Pools
+	if prefix.Addr().Is6() {
+		pools = c.IPv6Pools
+	}
+	addr := prefix.Addr().As16()
+	pool, ok := pools.Get(addr[:])
+	return ok && pool == prefix
+}
+
+// hasPriority reports whether a prefix has a priority in PoolPriorities.
+func (c *Calculator) hasPriority(prefix netip.Prefix) bool {
+	_, ok := c.PoolPriorities[prefix]
+	return ok
+}
+
+// siblingPrefix returns the prefix of the same size that forms the next
+// larger prefix together with a prefix, and false for a prefix of all
+// addresses.
+func siblingPrefix(prefix netip.Prefix) (netip.Prefix, bool) {
+	if prefix.Bits() == 0 {
+		return netip.Prefix{}, false
+	}
+	bit := prefix.Bits() - 1
+	if prefix.Addr().Is4() {
+		addr := prefix.Addr().As4()
+		addr[bit/8] ^= 0x80 >> (bit % 8)
+		return netip.PrefixFrom(netip.AddrFrom4(addr), prefix.Bits()), true
+	}
+	addr := prefix.Addr().As16()
+	addr[bit/8] ^= 0x80 >> (bit % 8)
+	return netip.PrefixFrom(netip.AddrFrom16(addr), prefix.Bits()), true
 }
 
 func (c *Calculator) DeletePool(prefix netip.Prefix) {
@@ -46,10 +230,25 @@ func (c *Calculator) DeletePool(prefix netip.Prefix) {
 	}
 }
 
-func (c *Calculator) AddAllocatedPrefix(prefix netip.Prefix) {
-	addr := prefix.Addr().As16()
-	bytes := make([]byte, len(addr))
-	copy(bytes, addr[:])
+// AddAllocatedPrefix marks a prefix as allocated, and handles a prefix that
+// is not within any pool as set by AllocationOutsidePools.
+func (c *Calculator) AddAllocatedPrefix(prefix netip.Prefix) error {
+	var err error
+	if c.AllocationOutsidePools != AllocationOutsidePoolsAllow && !c.withinPools(prefix) {
+		if c.AllocationOutsidePools == AllocationOutsidePoolsError {
+			return &OutsidePoolsError{Prefix: prefix}
+		}
+		err = &OutsidePoolsError{Prefix: prefix, Allocated: true}
+	}
+	c.HoldPrefix(prefix)
+	return err
+}
+
+// HoldPrefix marks a prefix as allocated without checking it against the
+// pools, such as a prefix allocated from them before, or one given up by a
+// resource that must not be handed out again during the run.
+func (c *Calculator) HoldPrefix(prefix netip.Prefix) {
+	bytes := allocatedKey(prefix)
 	if prefix.Addr().Is4() {
 		c.AllocatedIPv4Prefixes, _, _ = c.AllocatedIPv4Prefixes.Insert(bytes, prefix)
 	} else {
@@ -58,9 +257,7 @@ func (c *Calculator) AddAllocatedPrefix(prefix netip.Prefix) {
 }
 
 func (c *Calculator) DeleteAllocatedPrefix(prefix netip.Prefix) {
-	addr := prefix.Addr().As16()
-	bytes := make([]byte, len(addr))
-	copy(bytes, addr[:])
+	bytes := allocatedKey(prefix)
 	if prefix.Addr().Is4() {
 		c.AllocatedIPv4Prefixes, _, _ = c.AllocatedIPv4Prefixes.Delete(bytes)
 	} else {
@@ -68,9 +265,37 @@ func (c *Calculator) DeleteAllocatedPrefix(prefix netip.Prefix) {
 	}
 }
 
-// PrefixInPools tests to see if a prefix is a part of any
-// pools that have been added to the calculator.
+// AddReservedPrefix marks a prefix as reserved. Unlike an allocated prefix,
+// which records existing usage, a reserved prefix can never be allocated.
+func (c *Calculator) AddReservedPrefix(prefix netip.Prefix) {
+	c.ReservedPrefixes = append(c.ReservedPrefixes, prefix.Masked())
+}
+
+// reservedOverlap returns the first reserved prefix that overlaps a prefix.
+func (c *Calculator) reservedOverlap(prefix netip.Prefix) (netip.Prefix, bool) {
+	for _, r := range c.ReservedPrefixes {
+		if r.Overlaps(prefix) {
+			return r, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// allocatedKey returns the radix tree key of an allocated prefix. The mask
+// length is part of the key so that nested allocations, such as a claimed
+// supernet and a subnet at the start of it, are stored separately.
+func allocatedKey(prefix netip.Prefix) []byte {
+	addr := prefix.Addr().As16()
+	return append(addr[:], byte(prefix.Bits()))
+}
+
+// PrefixInPools tests to see if a prefix is entirely a part of any
+// pools that have been added to the calculator, outside of the reserved
+// prefixes.
 func (c *Calculator) PrefixInPools(prefix netip.Prefix) bool {
+	if _, reserved := c.reservedOverlap(prefix); reserved {
+		return false
+	}
 	pool := c.IPv4Pools
 	if prefix.Addr().Is6() {
 		pool = c.IPv6Pools
@@ -81,7 +306,7 @@ func (c *Calculator) PrefixInPools(prefix netip.Prefix) bool {
 		if !ok {
 			panic("unexpected node type found in radix tree")
 		}
-		if n.Contains(prefix.Addr()) {
+		if n.Contains(prefix.Addr()) && n.Bits() <= prefix.Bits() {
 			result = true
 			return true
 		}
@@ -91,87 +316,171 @@ func (c *Calculator) PrefixInPools(prefix netip.Prefix) bool {
 }
 
 // NextAvailableIPv4Subnet finds the first available IPv4 subnet of a given mask length
-// from a list of subnets and supernets, and fails if none are available.
-func (c *Calculator) NextAvailableIPv4Subnet(numBits int) (netip.Prefix, error) {
+// from a list of subnets and supernets, and fails if none are available or
+// the context is canceled first.
+func (c *Calculator) NextAvailableIPv4Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
 	// For each eligible subnet, walk the tree and determine if the subnet is
 	// available for use, and return the first subnet that is available.
-	sf := newSubnetV4Factory(c, numBits)
+	sf := newSubnetV4Factory(ctx, c, numBits)
 	defer sf.stop()
 
 	for subnet := range sf.subnetsChan {
-		if c.prefixAvailable(subnet) {
-			addr := subnet.Addr().As16()
-			bytes := make([]byte, len(addr))
-			copy(bytes, addr[:])
-			c.AllocatedIPv4Prefixes, _, _ = c.AllocatedIPv4Prefixes.Insert(bytes, subnet)
-			return subnet, nil
+		if conflict, reason, ok := c.prefixConflict(subnet); ok {
+			c.traceRejected(subnet, conflict, reason)
+			continue
 		}
+		bytes := allocatedKey(subnet)
+		c.AllocatedIPv4Prefixes, _, _ = c.AllocatedIPv4Prefixes.Insert(bytes, subnet)
+		c.traceChosen(subnet, "first available subnet, with the first_fit strategy")
+		return subnet, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return netip.Prefix{}, fmt.Errorf("search for a subnet with mask /%d stopped: %w", numBits, err)
 	}
 
-	return netip.Prefix{}, fmt.Errorf("No eligible subnet with mask /%v found", numBits)
+	return netip.Prefix{}, c.exhausted(false, numBits)
 }
 
 // NextAvailableIPv6Subnet finds the first available IPv6 subnet of a given mask length
-// from a list of subnets and supernets, and fails if none are available.
-func (c *Calculator) NextAvailableIPv6Subnet(numBits int) (netip.Prefix, error) {
+// from a list of subnets and supernets, and fails if none are available or
+// the context is canceled first.
+func (c *Calculator) NextAvailableIPv6Subnet(ctx context.Context, numBits int) (netip.Prefix, error) {
 	// For each eligible subnet, walk the tree and determine if the subnet is
 	// available for use, and return the first subnet that is available.
-	sf := newSubnetV6Factory(c, numBits)
+	sf := newSubnetV6Factory(ctx, c, numBits)
 	defer sf.stop()
 
 	for subnet := range sf.subnetsChan {
-		if c.prefixAvailable(subnet) {
-			addr := subnet.Addr().As16()
-			bytes := make([]byte, len(addr))
-			copy(bytes, addr[:])
-			c.AllocatedIPv6Prefixes, _, _ = c.AllocatedIPv6Prefixes.Insert(bytes, subnet)
-			return subnet, nil
+		if conflict, reason, ok := c.prefixConflict(subnet); ok {
+			c.traceRejected(subnet, conflict, reason)
+			continue
+		}
+		bytes := allocatedKey(subnet)
+		c.AllocatedIPv6Prefixes, _, _ = c.AllocatedIPv6Prefixes.Insert(bytes, subnet)
+		c.traceChosen(subnet, "first available subnet, with the first_fit strategy")
+		return subnet, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return netip.Prefix{}, fmt.Errorf("search for a subnet with mask /%d stopped: %w", numBits, err)
+	}
+
+	return netip.Prefix{}, c.exhausted(true, numBits)
+}
+
+// NextAvailableSubnetInPools finds an available subnet of a given mask length
+// within the given pools instead of the calculator's pools using the given
+// strategy, treating the reserved prefixes as unavailable, and fails if none
+// are available. The pools are searched in the order of SortPools, whatever
+// the order they are given in, and the empty strategy is FirstFit.
+func (c *Calculator) NextAvailableSubnetInPools(pools []netip.Prefix, reserved []netip.Prefix, numBits int, strategy Strategy) (netip.Prefix, error) {
+	pools = c.searchOrder(pools)
+	var free []netip.Prefix
+	for _, pool := range pools {
+		free = append(free, c.FreePrefixesInPool(pool, reserved)...)
+	}
+	subnet, ok := strategy.choose(free, numBits)
+	c.traceChoice(strategy, free, numBits, subnet, ok)
+	if !ok {
+		return netip.Prefix{}, exhausted(numBits, pools, func(pool netip.Prefix) []netip.Prefix {
+			return c.FreePrefixesInPool(pool, reserved)
+		})
+	}
+	c.HoldPrefix(subnet)
+	return subnet, nil
+}
+
+// FreePrefixesInPool returns the space of a pool that is neither allocated,
+// reserved nor in the given used prefixes, as a list of minimal CIDR blocks in
+// address order.
+func (c *Calculator) FreePrefixesInPool(pool netip.Prefix, used []netip.Prefix) []netip.Prefix {
+	// A pool carved out of an allocation, such as a claimed supernet, is
+	// allocated from rather than treated as used.
+	var allocated []netip.Prefix
+	for _, a := range c.AllocatedPrefixes(pool.Addr().Is6()) {
+		if a.Bits() > pool.Bits() || !a.Contains(pool.Addr()) {
+			allocated = append(allocated, a)
 		}
 	}
+	allocated = append(allocated, c.ReservedPrefixes...)
+	return Subtract(pool, append(allocated, used...))
+}
 
-	return netip.Prefix{}, fmt.Errorf("No eligible subnet with mask /%v found", numBits)
+// ClaimPrefix marks a prefix as allocated, and fails if it overlaps any
+// allocated prefix that is not within one of the covered prefixes, or any
+// reserved prefix.
+func (c *Calculator) ClaimPrefix(prefix netip.Prefix, covered []netip.Prefix) error {
+	if r, reserved := c.reservedOverlap(prefix); reserved {
+		return fmt.Errorf("%s overlaps reserved CIDR block %s", prefix, r)
+	}
+	for _, a := range c.AllocatedPrefixes(prefix.Addr().Is6()) {
+		if a.Overlaps(prefix) && !prefixWithin(a, covered) {
+			return fmt.Errorf("%s overlaps allocated CIDR block %s", prefix, a)
+		}
+	}
+	c.HoldPrefix(prefix)
+	return nil
 }
 
-// subnetAvailable tests to see if an IPNet is available in an existing tree of subnets.
-func (c *Calculator) prefixAvailable(prefix netip.Prefix) bool {
+// prefixConflict returns the reserved or allocated prefix a prefix overlaps,
+// and why that makes the prefix unavailable. It returns false if the prefix is
+// available.
+func (c *Calculator) prefixConflict(prefix netip.Prefix) (netip.Prefix, string, bool) {
+	if r, reserved := c.reservedOverlap(prefix); reserved {
+		return r, "overlaps reserved CIDR block " + r.String(), true
+	}
 	allocated := c.AllocatedIPv4Prefixes
 	if prefix.Addr().Is6() {
 		allocated = c.AllocatedIPv6Prefixes
 	}
-	result := true
+	var conflict netip.Prefix
 	allocated.Root().Walk(func(k []byte, v interface{}) bool {
 		n, ok := v.(netip.Prefix)
 		if !ok {
 			panic("unexpected node type found in radix tree")
 		}
-		// ones, _ := n.Mask.Size()
-		// // fmt.Printf("net: %s   ones: %d   bits: %d\n", currentIPNet.String(), ones, bits)
-		// if ones <= 16 {
-		// 	return false
-		// }
-		if n.Contains(prefix.Addr()) {
-			result = false
-			return true
-		}
-		if prefix.Contains(n.Addr()) {
-			result = false
+		if n.Contains(prefix.Addr()) || prefix.Contains(n.Addr()) {
+			conflict = n
 			return true
 		}
 		return false
 	})
-	return result
+	if !conflict.IsValid() {
+		return netip.Prefix{}, "", false
+	}
+	return conflict, "overlaps allocated CIDR block " + conflict.String(), true
+}
+
+// MaskLengthForHosts returns the longest mask length (the smallest subnet) that
+// provides at least the given number of usable host addresses. IPv4 subnets
+// reserve the network and broadcast addresses, so they need two extra addresses.
+func MaskLengthForHosts(hosts uint64, ipv6 bool) (int, error) {
+	if hosts == 0 {
+		return 0, fmt.Errorf("host count must be at least 1")
+	}
+	if ipv6 {
+		// Every address in an IPv6 subnet is usable.
+		return 128 - bits.Len64(hosts-1), nil
+	}
+	if hosts > (1<<32)-2 {
+		return 0, fmt.Errorf("%d hosts do not fit in the IPv4 address space", hosts)
+	}
+	return 32 - bits.Len64(hosts+1), nil
 }
 
+// subnetFactory generates the candidate subnets of the pools in search order
+// until it is stopped or its context is canceled.
 type subnetFactory struct {
-	supernets    *iradix.Tree
+	ctx          context.Context
+	supernets    []netip.Prefix
 	prefixLength int
 	subnetsChan  chan netip.Prefix
 	doneChan     chan struct{}
 }
 
-func newSubnetV4Factory(c *Calculator, prefixLength int) *subnetFactory {
+func newSubnetV4Factory(ctx context.Context, c *Calculator, prefixLength int) *subnetFactory {
 	sf := &subnetFactory{
-		supernets:    c.IPv4Pools,
+		ctx:          ctx,
+		supernets:    c.searchOrder(c.Pools(false)),
 		prefixLength: prefixLength,
 		subnetsChan:  make(chan netip.Prefix),
 		doneChan:     make(chan struct{}),
@@ -180,9 +489,10 @@ func newSubnetV4Factory(c *Calculator, prefixLength int) *subnetFactory {
 	return sf
 }
 
-func newSubnetV6Factory(c *Calculator, prefixLength int) *subnetFactory {
+func newSubnetV6Factory(ctx context.Context, c *Calculator, prefixLength int) *subnetFactory {
 	sf := &subnetFactory{
-		supernets:    c.IPv6Pools,
+		ctx:          ctx,
+		supernets:    c.searchOrder(c.Pools(true)),
 		prefixLength: prefixLength,
 		subnetsChan:  make(chan netip.Prefix),
 		doneChan:     make(chan struct{}),
@@ -195,61 +505,79 @@ func (sf *subnetFactory) stop() {
 	close(sf.doneChan)
 }
 
+// send hands a candidate subnet to the consumer, and returns false when the
+// factory is stopped or its context is canceled instead.
+func (sf *subnetFactory) send(prefix netip.Prefix) bool {
+	select {
+	case sf.subnetsChan <- prefix:
+		return true
+	case <-sf.doneChan:
+		return false
+	case <-sf.ctx.Done():
+		return false
+	}
+}
+
 func (sf *subnetFactory) run4() {
-	sf.supernets.Root().Walk(func(k []byte, v interface{}) bool {
-		select {
-		case <-sf.doneChan:
-			return true
-		default:
-			n, ok := v.(netip.Prefix)
-			if !ok {
-				panic("unexpected node type found in radix tree")
+	defer close(sf.subnetsChan)
+	for _, n := range sf.supernets {
+		addr := n.Addr().As4()
+		newPrefix := netip.PrefixFrom(netip.AddrFrom4(addr), sf.prefixLength)
+		if !sf.send(newPrefix) {
+			return
+		}
+		for {
+			var ok bool
+			if addr, ok = increment4(addr, sf.prefixLength); !ok {
+				// The last subnet of the address space, e.g. of a
+				// 0.0.0.0/0 or ::/0 pool, was sent.
+				break
 			}
-			addr := n.Addr().As4()
-			newPrefix := netip.PrefixFrom(netip.AddrFrom4(addr), sf.prefixLength)
-			sf.subnetsChan <- newPrefix
-			for {
-				addr = increment4(addr, sf.prefixLength)
-				newPrefix = netip.PrefixFrom(netip.AddrFrom4(addr), sf.prefixLength)
-				if !n.Contains(newPrefix.Addr()) {
-					break
-				}
-				sf.subnetsChan <- newPrefix
+			newPrefix = netip.PrefixFrom(netip.AddrFrom4(addr), sf.prefixLength)
+			if !n.Contains(newPrefix.Addr()) {
+				break
+			}
+			if !sf.send(newPrefix) {
+				return
 			}
-			return false
 		}
-	})
-	close(sf.subnetsChan)
+	}
 }
 
 func (sf *subnetFactory) run6() {
-	sf.supernets.Root().Walk(func(k []byte, v interface{}) bool {
-		select {
-		case <-sf.doneChan:
-			return true
-		default:
-			n, ok := v.(netip.Prefix)
-			if !ok {
-				panic("unexpected node type found in radix tree")
+	defer close(sf.subnetsChan)
+	for _, n := range sf.supernets {
+		addr := n.Addr().As16()
+		newPrefix := netip.PrefixFrom(netip.AddrFrom16(addr), sf.prefixLength)
+		if !sf.send(newPrefix) {
+			return
+		}
+		for {
+			var ok bool
+			if addr, ok = increment16(addr, sf.prefixLength); !ok {
+				// The last subnet of the address space, e.g. of a
+				// 0.0.0.0/0 or ::/0 pool, was sent.
+				break
 			}
-			addr := n.Addr().As16()
-			newPrefix := netip.PrefixFrom(netip.AddrFrom16(addr), sf.prefixLength)
-			sf.subnetsChan <- newPrefix
-			for {
-				addr = increment16(addr, sf.prefixLength)
-				newPrefix = netip.PrefixFrom(netip.AddrFrom16(addr), sf.prefixLength)
-				if !n.Contains(newPrefix.Addr()) {
-					break
-				}
-				sf.subnetsChan <- newPrefix
+			newPrefix = netip.PrefixFrom(netip.AddrFrom16(addr), sf.prefixLength)
+			if !n.Contains(newPrefix.Addr()) {
+				break
+			}
+			if !sf.send(newPrefix) {
+				return
 			}
-			return false
 		}
-	})
-	close(sf.subnetsChan)
+	}
 }
 
-func increment4(a [4]byte, bit int) [4]byte {
+// increment4 adds one to an address at the given mask length, i.e. returns
+// the address of the next subnet of that mask length. It returns false when
+// there is no next subnet, because the address would overflow or the mask
+// length is 0.
+func increment4(a [4]byte, bit int) ([4]byte, bool) {
+	if bit == 0 {
+		return [4]byte{}, false
+	}
 	octet := (bit - 1) / 8
 	val := uint16(128) >> ((bit - 1) - (octet * 8))
 	sum16 := uint16(a[octet]) + val
@@ -257,12 +585,11 @@ func increment4(a [4]byte, bit int) [4]byte {
 	carry := sum16 >> 8
 	for {
 		if carry == 0 {
-			return a
+			return a, true
 		}
 		octet--
 		if octet < 0 {
-			// overflow
-			return [4]byte{}
+			return [4]byte{}, false
 		}
 		sum16 = uint16(a[octet]) + carry
 		a[octet] = byte(sum16)
@@ -270,7 +597,14 @@ func increment4(a [4]byte, bit int) [4]byte {
 	}
 }
 
-func increment16(a [16]byte, bit int) [16]byte {
+// increment16 adds one to an address at the given mask length, i.e. returns
+// the address of the next subnet of that mask length. It returns false when
+// there is no next subnet, because the address would overflow or the mask
+// length is 0.
+func increment16(a [16]byte, bit int) ([16]byte, bool) {
+	if bit == 0 {
+		return [16]byte{}, false
+	}
 	octet := (bit - 1) / 8
 	val := uint16(128) >> ((bit - 1) - (octet * 8))
 	sum16 := uint16(a[octet]) + val
@@ -278,12 +612,11 @@ func increment16(a [16]byte, bit int) [16]byte {
 	carry := sum16 >> 8
 	for {
 		if carry == 0 {
-			return a
+			return a, true
 		}
 		octet--
 		if octet < 0 {
-			// overflow
-			return [16]byte{}
+			return [16]byte{}, false
 		}
 		sum16 = uint16(a[octet]) + carry
 		a[octet] = byte(sum16)
@@ -1,6 +1,10 @@
 package subnet
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
 	"net/netip"
 	"testing"
 
@@ -11,15 +15,15 @@ func TestNextAvailableSubnet(t *testing.T) {
 	assert := assert.New(t)
 	calc := NewCalculator()
 	calc.AddPool(netip.MustParsePrefix("fd18:fad4:bce5:4400::/56"))
-	next, err := calc.NextAvailableIPv6Subnet(64)
+	next, err := calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4400::/64", next.String())
 	}
-	next, err = calc.NextAvailableIPv6Subnet(64)
+	next, err = calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4401::/64", next.String())
 	}
-	next, err = calc.NextAvailableIPv6Subnet(64)
+	next, err = calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4402::/64", next.String())
 	}
@@ -29,21 +33,1027 @@ func TestNextAvailableSubnetWithAllocated(t *testing.T) {
 	assert := assert.New(t)
 	calc := NewCalculator()
 	calc.AddPool(netip.MustParsePrefix("fd18:fad4:bce5:4400::/56"))
-	calc.AddAllocatedPrefix(netip.MustParsePrefix("fd18:fad4:bce5:4400::/64"))
-	next, err := calc.NextAvailableIPv6Subnet(64)
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("fd18:fad4:bce5:4400::/64")))
+	next, err := calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4401::/64", next.String())
 	}
-	next, err = calc.NextAvailableIPv6Subnet(64)
+	next, err = calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4402::/64", next.String())
 	}
-	next, err = calc.NextAvailableIPv6Subnet(64)
+	next, err = calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4403::/64", next.String())
 	}
-	next, err = calc.NextAvailableIPv6Subnet(64)
+	next, err = calc.NextAvailableIPv6Subnet(context.Background(), 64)
 	if assert.NoError(err) {
 		assert.Equal("fd18:fad4:bce5:4404::/64", next.String())
 	}
 }
+
+func TestMaskLengthForHosts(t *testing.T) {
+	tests := []struct {
+		hosts uint64
+		ipv6  bool
+		want  int
+	}{
+		{hosts: 1, want: 30},
+		{hosts: 2, want: 30},
+		{hosts: 3, want: 29},
+		{hosts: 254, want: 24},
+		{hosts: 255, want: 23},
+		{hosts: (1 << 32) - 2, want: 0},
+		{hosts: 1, ipv6: true, want: 128},
+		{hosts: 2, ipv6: true, want: 127},
+		{hosts: 1 << 16, ipv6: true, want: 112},
+		{hosts: (1 << 16) + 1, ipv6: true, want: 111},
+		{hosts: 1 << 63, ipv6: true, want: 65},
+	}
+	for _, tt := range tests {
+		got, err := MaskLengthForHosts(tt.hosts, tt.ipv6)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.want, got, "hosts=%d ipv6=%v", tt.hosts, tt.ipv6)
+		}
+	}
+
+	_, err := MaskLengthForHosts(0, false)
+	assert.Error(t, err)
+	_, err = MaskLengthForHosts(1<<32, false)
+	assert.Error(t, err)
+}
+
+func TestSubtract(t *testing.T) {
+	assert := assert.New(t)
+	free := Subtract(netip.MustParsePrefix("10.0.0.0/22"), []netip.Prefix{
+		netip.MustParsePrefix("10.0.1.0/24"),
+		netip.MustParsePrefix("10.0.2.128/25"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("fd18:fad4:bce5:4400::/56"),
+	})
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("10.0.2.0/25"),
+		netip.MustParsePrefix("10.0.3.0/24"),
+	}, free)
+
+	assert.Empty(Subtract(netip.MustParsePrefix("10.0.0.0/24"), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}))
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}, Subtract(netip.MustParsePrefix("10.0.0.0/24"), nil))
+}
+
+func TestAddPool(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	assert.EqualError(calc.AddPool(netip.Prefix{}), "invalid pool invalid Prefix")
+	assert.EqualError(calc.AddPool(netip.MustParsePrefix("10.0.0.1/16")), "pool 10.0.0.1/16 has host bits set, did you mean 10.0.0.0/16?")
+
+	// Overlapping pools are merged into the larger of them.
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.4.0/24")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.1.0/24")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}, calc.Pools(false))
+
+	calc.PoolOverlap = PoolOverlapWarn
+	err := calc.AddPool(netip.MustParsePrefix("10.0.0.0/8"))
+	var overlap *OverlappingPoolError
+	if assert.ErrorAs(err, &overlap) {
+		assert.True(overlap.Merged)
+		assert.Equal("pool 10.0.0.0/8 overlaps pools [10.0.0.0/16]", err.Error())
+	}
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, calc.Pools(false))
+
+	calc.PoolOverlap = PoolOverlapError
+	err = calc.AddPool(netip.MustParsePrefix("10.0.0.0/8"))
+	if assert.ErrorAs(err, &overlap) {
+		assert.False(overlap.Merged)
+		assert.Equal("pool 10.0.0.0/8 was already added", err.Error())
+	}
+	assert.Error(calc.AddPool(netip.MustParsePrefix("10.1.0.0/16")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("192.168.0.0/16")))
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.0.0/16")}, calc.Pools(false))
+}
+
+func TestAddAdjacentPools(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.128.0/17")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/18")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.1.0.0/17")))
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/18"),
+		netip.MustParsePrefix("10.0.128.0/17"),
+		netip.MustParsePrefix("10.1.0.0/17"),
+	}, calc.Pools(false))
+
+	// Merging continues as long as the merged pool has a sibling.
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.64.0/18")))
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("10.1.0.0/17"),
+	}, calc.Pools(false))
+	subnet, err := calc.NextAvailableIPv4Subnet(context.Background(), 16)
+	assert.NoError(err)
+	assert.Equal(netip.MustParsePrefix("10.0.0.0/16"), subnet)
+
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("fd00::/65")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("fd00::8000:0:0:0/65")))
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("fd00::/64")}, calc.Pools(true))
+
+	// Pools with a priority are kept apart.
+	calc = NewCalculator()
+	calc.PoolPriorities = map[netip.Prefix]int{netip.MustParsePrefix("10.0.128.0/17"): 1}
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/17")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.128.0/17")))
+	assert.Len(calc.Pools(false), 2)
+}
+
+func TestAllocationOutsidePools(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.1.0.0/24")))
+
+	calc.AllocationOutsidePools = AllocationOutsidePoolsWarn
+	err := calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/15"))
+	var outside *OutsidePoolsError
+	if assert.ErrorAs(err, &outside) {
+		assert.True(outside.Allocated)
+		assert.Equal("10.0.0.0/15 is not within any pool", err.Error())
+	}
+
+	calc.AllocationOutsidePools = AllocationOutsidePoolsError
+	calc.OtherPools = []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("192.168.0.0/24")))
+	err = calc.AddAllocatedPrefix(netip.MustParsePrefix("10.2.0.0/24"))
+	if assert.ErrorAs(err, &outside) {
+		assert.False(outside.Allocated)
+	}
+	calc.HoldPrefix(netip.MustParsePrefix("10.3.0.0/24"))
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/15"),
+		netip.MustParsePrefix("10.0.1.0/24"),
+		netip.MustParsePrefix("10.1.0.0/24"),
+		netip.MustParsePrefix("10.3.0.0/24"),
+		netip.MustParsePrefix("192.168.0.0/24"),
+	}, calc.AllocatedPrefixes(false))
+}
+
+func TestExhaustedError(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	_, err := calc.NextAvailableIPv4Subnet(context.Background(), 24)
+	assert.EqualError(err, "No eligible subnet with mask /24 found: there are no pools")
+
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/23")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.1.0.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.0/25")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.1.0.0/25")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.1.0.128/26")))
+	for _, strategy := range Strategies {
+		_, err = calc.NextAvailableSubnet(context.Background(), false, 24, strategy)
+		var exhausted *ExhaustedError
+		if assert.ErrorAs(err, &exhausted) {
+			assert.Equal(netip.MustParsePrefix("10.0.1.128/25"), exhausted.LargestFree)
+			assert.Equal("No eligible subnet with mask /24 found: pool 10.0.0.0/23 is 75.0% used, pool 10.1.0.0/24 is 75.0% used; the largest free CIDR block is 10.0.1.128/25, so a subnet with mask /25 or longer would still fit", err.Error())
+		}
+	}
+
+	_, err = NextSubnet(netip.MustParsePrefix("10.1.0.0/24"), []netip.Prefix{netip.MustParsePrefix("10.1.0.0/24")}, 26)
+	assert.EqualError(err, "No eligible subnet with mask /26 found: pool 10.1.0.0/24 is 100.0% used; no free space is left")
+}
+
+func TestNextAvailableSubnetCanceled(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("fd00::/16")))
+	// The first half of the pool is allocated, so the first free /64 is 2^47
+	// candidates away and the search only ends when it is canceled.
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("fd00::/17")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := calc.NextAvailableIPv6Subnet(ctx, 64)
+	assert.ErrorIs(err, context.Canceled)
+	assert.EqualError(err, "search for a subnet with mask /64 stopped: context canceled")
+	_, err = calc.NextAvailableSubnet(ctx, true, 64, FirstFit)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestDefaultRoutePools(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		pool, last string
+		maskLength int
+	}{
+		{"0.0.0.0/0", "255.0.0.0/8", 8},
+		{"::/0", "ff00::/8", 8},
+	} {
+		pool := netip.MustParsePrefix(tc.pool)
+		ipv6 := pool.Addr().Is6()
+		calc := NewCalculator()
+		assert.NoError(calc.AddPool(pool))
+		assert.True(calc.PrefixInPools(netip.MustParsePrefix(tc.last)))
+		assert.Equal("256", calc.AvailableSubnetCount(ipv6, tc.maskLength).String())
+
+		for _, strategy := range Strategies {
+			subnet, err := calc.Clone().NextAvailableSubnet(context.Background(), ipv6, 0, strategy)
+			assert.NoError(err)
+			assert.Equal(pool, subnet, strategy)
+		}
+		next, err := NextSubnet(pool, nil, 0)
+		assert.NoError(err)
+		assert.Equal(pool, next)
+
+		// The search ends at the last subnet of the address space rather
+		// than wrapping around to the first one.
+		last := netip.MustParsePrefix(tc.last)
+		for _, allocated := range Subtract(pool, []netip.Prefix{last}) {
+			assert.NoError(calc.AddAllocatedPrefix(allocated))
+		}
+		subnet, err := calc.NextAvailableSubnet(context.Background(), ipv6, tc.maskLength, FirstFit)
+		assert.NoError(err)
+		assert.Equal(last, subnet)
+		_, err = calc.NextAvailableSubnet(context.Background(), ipv6, tc.maskLength, FirstFit)
+		assert.EqualError(err, fmt.Sprintf("No eligible subnet with mask /8 found: pool %s is 100.0%% used; no free space is left", pool))
+	}
+}
+
+func TestSortPools(t *testing.T) {
+	assert := assert.New(t)
+	pools := []netip.Prefix{
+		netip.MustParsePrefix("fd00::/56"),
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+	}
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("fd00::/56"),
+	}, SortPools(pools, nil))
+	assert.Equal(netip.MustParsePrefix("fd00::/56"), pools[0], "the pools given are not sorted in place")
+
+	priorities := map[netip.Prefix]int{
+		netip.MustParsePrefix("10.1.0.0/16"): 10,
+		netip.MustParsePrefix("10.0.0.0/8"):  -1,
+	}
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("fd00::/56"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+	}, SortPools(pools, priorities))
+}
+
+func TestPoolPriorities(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.NoError(calc.AddPool(netip.MustParsePrefix("10.1.0.0/24")))
+	calc.PoolPriorities = map[netip.Prefix]int{netip.MustParsePrefix("10.1.0.0/24"): 1}
+	for _, strategy := range Strategies {
+		subnet, err := calc.Clone().NextAvailableSubnet(context.Background(), false, 26, strategy)
+		assert.NoError(err)
+		assert.Equal(netip.MustParsePrefix("10.1.0.0/26"), subnet, strategy)
+	}
+
+	// Pools given to NextAvailableSubnetInPools are searched in the same
+	// order whatever the order they are given in.
+	pools := []netip.Prefix{netip.MustParsePrefix("10.3.0.0/24"), netip.MustParsePrefix("10.2.0.0/24")}
+	subnet, err := calc.NextAvailableSubnetInPools(pools, nil, 26, FirstFit)
+	assert.NoError(err)
+	assert.Equal(netip.MustParsePrefix("10.2.0.0/26"), subnet)
+}
+
+func TestAvailableSubnetCount(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+	calc.AddPool(netip.MustParsePrefix("fd18:fad4:bce5:4400::/56"))
+	assert.Equal("256", calc.AvailableSubnetCount(false, 24).String())
+	assert.Equal("256", calc.AvailableSubnetCount(true, 64).String())
+	assert.Equal("0", calc.AvailableSubnetCount(false, 15).String())
+
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.128/25")))
+	assert.Equal("254", calc.AvailableSubnetCount(false, 24).String())
+	assert.Equal("509", calc.AvailableSubnetCount(false, 25).String())
+
+	_, err := calc.NextAvailableIPv6Subnet(context.Background(), 64)
+	assert.NoError(err)
+	assert.Equal("255", calc.AvailableSubnetCount(true, 64).String())
+	assert.Equal("18374686479671623680", calc.AvailableSubnetCount(true, 120).String())
+}
+
+func TestNextAvailableSubnetInPools(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("192.168.0.0/16"))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+	pools := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/22"), netip.MustParsePrefix("fd18:fad4:bce5:4400::/56")}
+	reserved := []netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}
+
+	next, err := calc.NextAvailableSubnetInPools(pools, reserved, 24, FirstFit)
+	if assert.NoError(err) {
+		assert.Equal("10.0.2.0/24", next.String())
+	}
+	next, err = calc.NextAvailableSubnetInPools(pools, reserved, 24, FirstFit)
+	if assert.NoError(err) {
+		assert.Equal("10.0.3.0/24", next.String())
+	}
+	_, err = calc.NextAvailableSubnetInPools(pools[:1], reserved, 24, FirstFit)
+	assert.Error(err)
+	next, err = calc.NextAvailableSubnetInPools(pools, reserved, 64, FirstFit)
+	if assert.NoError(err) {
+		assert.Equal("fd18:fad4:bce5:4400::/64", next.String())
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		want     []string
+	}{
+		{FirstFit, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}},
+		{BestFit, []string{"10.0.1.0/24", "10.0.5.0/24", "10.0.2.0/24"}},
+		{Spread, []string{"10.0.128.0/24", "10.0.64.0/24", "10.0.192.0/24"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			assert := assert.New(t)
+			calc := NewCalculator()
+			calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+			assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+			assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.4.0/24")))
+			var got []string
+			for range tt.want {
+				next, err := calc.NextAvailableSubnet(context.Background(), false, 24, tt.strategy)
+				if !assert.NoError(err) {
+					return
+				}
+				got = append(got, next.String())
+			}
+			assert.Equal(tt.want, got)
+
+			// The pools given are preferred in order, and are searched as
+			// a whole by the other strategies.
+			pools := []netip.Prefix{netip.MustParsePrefix("192.168.0.0/24"), netip.MustParsePrefix("192.168.4.0/22")}
+			next, err := calc.NextAvailableSubnetInPools(pools, nil, 24, tt.strategy)
+			if assert.NoError(err) {
+				if tt.strategy == FirstFit || tt.strategy == BestFit {
+					assert.Equal("192.168.0.0/24", next.String())
+				} else {
+					assert.Equal("192.168.4.0/24", next.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNextAvailableRange(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	prefix := netip.MustParsePrefix("10.0.0.0/24")
+	next, err := calc.NextAvailableRange(prefix, 10)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.1-10.0.0.10", next.String())
+	}
+	calc.AddAllocatedRange(AddressRange{Start: netip.MustParseAddr("10.0.0.15"), End: netip.MustParseAddr("10.0.0.20")})
+	next, err = calc.NextAvailableRange(prefix, 5)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.21-10.0.0.25", next.String())
+	}
+	next, err = calc.NextAvailableRange(prefix, 4)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.11-10.0.0.14", next.String())
+	}
+	_, err = calc.NextAvailableRange(prefix, 230)
+	assert.Error(err)
+	next, err = calc.NextAvailableRange(prefix, 229)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.26-10.0.0.254", next.String())
+	}
+
+	calc = NewCalculator()
+	assert.NoError(calc.AddAllocatedPrefix(prefix))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/29")))
+	next, err = calc.NextAvailableRange(prefix, 2)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.8-10.0.0.9", next.String())
+	}
+
+	next, err = calc.NextAvailableRange(netip.MustParsePrefix("fd18:fad4:bce5:4400::/64"), 256)
+	if assert.NoError(err) {
+		assert.Equal("fd18:fad4:bce5:4400::-fd18:fad4:bce5:4400::ff", next.String())
+	}
+}
+
+func TestAddressRangePrefixes(t *testing.T) {
+	tests := []struct {
+		r    string
+		want []string
+	}{
+		{r: "10.0.0.1-10.0.0.10", want: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/31", "10.0.0.10/32"}},
+		{r: "10.0.0.0-10.0.0.255", want: []string{"10.0.0.0/24"}},
+		{r: "255.255.255.254-255.255.255.255", want: []string{"255.255.255.254/31"}},
+		{r: "fd18:fad4:bce5:4400::-fd18:fad4:bce5:4400::ff", want: []string{"fd18:fad4:bce5:4400::/120"}},
+	}
+	for _, tt := range tests {
+		r, err := ParseAddressRange(tt.r)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		var got []string
+		for _, p := range r.Prefixes() {
+			got = append(got, p.String())
+		}
+		assert.Equal(t, tt.want, got, tt.r)
+	}
+	_, err := ParseAddressRange("10.0.0.10-10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestGaps(t *testing.T) {
+	assert := assert.New(t)
+	used := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/26"),
+		netip.MustParsePrefix("10.0.0.128/27"),
+		netip.MustParsePrefix("192.168.0.0/24"),
+	}
+	gaps := Gaps(netip.MustParsePrefix("10.0.0.0/24"), used)
+	if assert.Len(gaps, 2) {
+		assert.Equal("10.0.0.64-10.0.0.127", gaps[0].String())
+		assert.Equal("10.0.0.160-10.0.0.255", gaps[1].String())
+		assert.Equal(int64(96), gaps[1].Size().Int64())
+		assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.160/27"), netip.MustParsePrefix("10.0.0.192/26")}, gaps[1].Prefixes())
+	}
+	assert.Empty(Gaps(netip.MustParsePrefix("10.0.0.0/24"), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}))
+	assert.Equal("0.0.0.0-255.255.255.255", Gaps(netip.MustParsePrefix("0.0.0.0/0"), nil)[0].String())
+}
+
+func TestSplitPrefix(t *testing.T) {
+	assert := assert.New(t)
+	subnets, err := SplitPrefix(netip.MustParsePrefix("10.0.0.0/22"), 24)
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/24"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("10.0.2.0/24"),
+			netip.MustParsePrefix("10.0.3.0/24"),
+		}, subnets)
+	}
+	subnets, err = SplitPrefix(netip.MustParsePrefix("fd18:fad4:bce5:4400::/56"), 56)
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{netip.MustParsePrefix("fd18:fad4:bce5:4400::/56")}, subnets)
+	}
+	_, err = SplitPrefix(netip.MustParsePrefix("10.0.0.0/22"), 21)
+	assert.Error(err)
+	_, err = SplitPrefix(netip.MustParsePrefix("10.0.0.0/22"), 33)
+	assert.Error(err)
+}
+
+func TestSupernet(t *testing.T) {
+	tests := []struct {
+		prefixes []string
+		want     string
+	}{
+		{prefixes: []string{"10.0.0.0/24"}, want: "10.0.0.0/24"},
+		{prefixes: []string{"10.0.0.0/24", "10.0.1.0/24"}, want: "10.0.0.0/23"},
+		{prefixes: []string{"10.0.1.0/24", "10.0.2.0/24"}, want: "10.0.0.0/22"},
+		{prefixes: []string{"10.0.0.0/16", "10.0.3.0/24"}, want: "10.0.0.0/16"},
+		{prefixes: []string{"10.0.0.0/8", "192.168.0.0/16"}, want: "0.0.0.0/0"},
+		{prefixes: []string{"fd18:fad4:bce5:4400::/64", "fd18:fad4:bce5:4401::/64"}, want: "fd18:fad4:bce5:4400::/63"},
+	}
+	for _, tt := range tests {
+		var prefixes []netip.Prefix
+		for _, p := range tt.prefixes {
+			prefixes = append(prefixes, netip.MustParsePrefix(p))
+		}
+		got, err := Supernet(prefixes)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.want, got.String())
+		}
+	}
+	_, err := Supernet([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("fd18:fad4:bce5:4400::/64")})
+	assert.Error(t, err)
+}
+
+func TestClaimPrefix(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.4.0/24")))
+
+	assert.NoError(calc.ClaimPrefix(netip.MustParsePrefix("10.0.0.0/22"), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}))
+	assert.Error(calc.ClaimPrefix(netip.MustParsePrefix("10.0.4.0/22"), nil))
+
+	// The claimed prefix can be used as a pool, but is not allocated from
+	// the provider's pools.
+	next, err := calc.NextAvailableSubnetInPools([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/22")}, nil, 24, FirstFit)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.0/24", next.String())
+	}
+	next, err = calc.NextAvailableIPv4Subnet(context.Background(), 22)
+	if assert.NoError(err) {
+		assert.Equal("10.0.8.0/22", next.String())
+	}
+}
+
+func TestReservedPrefix(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+	calc.AddReservedPrefix(netip.MustParsePrefix("10.0.0.0/23"))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.2.0/24")))
+
+	next, err := calc.NextAvailableIPv4Subnet(context.Background(), 24)
+	if assert.NoError(err) {
+		assert.Equal("10.0.3.0/24", next.String())
+	}
+	assert.False(calc.PrefixInPools(netip.MustParsePrefix("10.0.1.0/24")))
+	assert.False(calc.PrefixInPools(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.True(calc.PrefixInPools(netip.MustParsePrefix("10.0.3.0/24")))
+
+	// Unlike allocated prefixes, reserved prefixes are not allocated from
+	// when they are used as a pool.
+	_, err = calc.NextAvailableSubnetInPools([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/22")}, nil, 24, FirstFit)
+	assert.Error(err)
+	assert.Error(calc.ClaimPrefix(netip.MustParsePrefix("10.0.1.0/24"), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}))
+	assert.Error(calc.ClaimRange(AddressRange{Start: netip.MustParseAddr("10.0.1.10"), End: netip.MustParseAddr("10.0.4.10")}))
+	_, err = calc.NextAvailableRange(netip.MustParsePrefix("10.0.0.0/22"), 10)
+	assert.Error(err)
+
+	assert.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.4.0/22"),
+		netip.MustParsePrefix("10.0.8.0/21"),
+		netip.MustParsePrefix("10.0.16.0/20"),
+		netip.MustParsePrefix("10.0.32.0/19"),
+		netip.MustParsePrefix("10.0.64.0/18"),
+		netip.MustParsePrefix("10.0.128.0/17"),
+	}, calc.FreePrefixes(false))
+}
+
+// recordingTracer records the decisions of a calculator as strings.
+type recordingTracer []string
+
+func (r *recordingTracer) Rejected(candidate netip.Prefix, conflict netip.Prefix, reason string) {
+	*r = append(*r, candidate.String()+" rejected: "+reason)
+}
+
+func (r *recordingTracer) Chosen(subnet netip.Prefix, reason string) {
+	*r = append(*r, subnet.String()+" chosen: "+reason)
+}
+
+func TestTracer(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/22"))
+	calc.AddReservedPrefix(netip.MustParsePrefix("10.0.0.0/24"))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.0/25")))
+	var trace recordingTracer
+	calc.Tracer = &trace
+
+	next, err := calc.NextAvailableIPv4Subnet(context.Background(), 24)
+	if assert.NoError(err) {
+		assert.Equal("10.0.2.0/24", next.String())
+	}
+	assert.Equal(recordingTracer{
+		"10.0.0.0/24 rejected: overlaps reserved CIDR block 10.0.0.0/24",
+		"10.0.1.0/24 rejected: overlaps allocated CIDR block 10.0.1.0/25",
+		"10.0.2.0/24 chosen: first available subnet, with the first_fit strategy",
+	}, trace)
+
+	trace = nil
+	next, err = calc.NextAvailableSubnet(context.Background(), false, 25, Spread)
+	if assert.NoError(err) {
+		assert.Equal("10.0.3.0/25", next.String())
+	}
+	assert.Equal(recordingTracer{
+		"10.0.1.128/25 rejected: the spread strategy prefers free CIDR block 10.0.3.0/24",
+		"10.0.3.0/25 chosen: start of the largest free CIDR block, with the spread strategy",
+	}, trace)
+
+	trace = nil
+	_, err = calc.NextAvailableSubnet(context.Background(), false, 24, BestFit)
+	assert.Error(err)
+	assert.Equal(recordingTracer{
+		"10.0.1.128/25 rejected: free CIDR block is too small for a /24 subnet",
+		"10.0.3.128/25 rejected: free CIDR block is too small for a /24 subnet",
+	}, trace)
+}
+
+func TestClone(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.0.0/24")))
+
+	clone := calc.Clone()
+	next, err := clone.NextAvailableIPv4Subnet(context.Background(), 24)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.0/24", next.String())
+	}
+	assert.NoError(clone.ClaimRange(AddressRange{Start: netip.MustParseAddr("10.0.2.1"), End: netip.MustParseAddr("10.0.2.1")}))
+
+	assert.Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}, calc.AllocatedPrefixes(false))
+	assert.Empty(calc.AllocatedRanges)
+}
+
+func TestPrefixInPools(t *testing.T) {
+	assert := assert.New(t)
+	calc := NewCalculator()
+	calc.AddPool(netip.MustParsePrefix("10.0.0.0/16"))
+
+	assert.True(calc.PrefixInPools(netip.MustParsePrefix("10.0.1.0/24")))
+	assert.True(calc.PrefixInPools(netip.MustParsePrefix("10.0.0.0/16")))
+	assert.False(calc.PrefixInPools(netip.MustParsePrefix("10.0.0.0/15")))
+	assert.False(calc.PrefixInPools(netip.MustParsePrefix("10.1.0.0/24")))
+}
+
+func TestHostAddr(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix string
+		index  int64
+		want   string
+	}{
+		{"10.0.1.0/24", 0, "10.0.1.0"},
+		{"10.0.1.0/24", 4, "10.0.1.4"},
+		{"10.0.1.0/24", 255, "10.0.1.255"},
+		{"10.0.1.0/24", -1, "10.0.1.255"},
+		{"10.0.1.0/24", -256, "10.0.1.0"},
+		{"10.0.1.0/24", 256, ""},
+		{"10.0.1.0/24", -257, ""},
+		{"fd00::/64", 1, "fd00::1"},
+		{"fd00::/64", -2, "fd00::ffff:ffff:ffff:fffe"},
+		{"fd00::/56", -1 << 62, "fd00:0:0:ff:c000::"},
+	} {
+		got, err := HostAddr(netip.MustParsePrefix(tc.prefix), tc.index)
+		if tc.want == "" {
+			assert.Error(err, "%s %d", tc.prefix, tc.index)
+			continue
+		}
+		if assert.NoError(err, "%s %d", tc.prefix, tc.index) {
+			assert.Equal(tc.want, got.String(), "%s %d", tc.prefix, tc.index)
+		}
+	}
+
+	calc := NewCalculator()
+	host := AddressRange{Start: netip.MustParseAddr("10.0.1.4"), End: netip.MustParseAddr("10.0.1.4")}
+	assert.NoError(calc.ClaimRange(host))
+	assert.Error(calc.ClaimRange(host))
+
+	// Claimed host addresses are allocated prefixes in later applies.
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.0/24")))
+	assert.NoError(calc.AddAllocatedPrefix(netip.MustParsePrefix("10.0.1.5/32")))
+	next := AddressRange{Start: netip.MustParseAddr("10.0.1.5"), End: netip.MustParseAddr("10.0.1.5")}
+	assert.Error(calc.ClaimRange(next))
+	next = AddressRange{Start: netip.MustParseAddr("10.0.1.6"), End: netip.MustParseAddr("10.0.1.6")}
+	assert.NoError(calc.ClaimRange(next))
+}
+
+func TestHostAddrBig(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix string
+		index  string
+		want   string
+	}{
+		{"10.0.1.0/24", "4", "10.0.1.4"},
+		{"10.0.1.0/24", "-1", "10.0.1.255"},
+		{"10.0.1.0/24", "256", ""},
+		{"10.0.1.0/24", "-257", ""},
+		{"::/0", "-1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"fd00::/48", "18446744073709551616", "fd00:0:0:1::"},
+		{"fd00::/48", "-18446744073709551617", "fd00::fffe:ffff:ffff:ffff:ffff"},
+		{"fd00::/64", "18446744073709551616", ""},
+	} {
+		index, _ := new(big.Int).SetString(tc.index, 10)
+		got, err := HostAddrBig(netip.MustParsePrefix(tc.prefix), index)
+		if tc.want == "" {
+			assert.Error(err, "%s %s", tc.prefix, tc.index)
+			continue
+		}
+		if assert.NoError(err, "%s %s", tc.prefix, tc.index) {
+			assert.Equal(tc.want, got.String(), "%s %s", tc.prefix, tc.index)
+		}
+	}
+}
+
+func TestHosts(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix        string
+		offset, limit uint64
+		want          []string
+	}{
+		{"10.0.1.0/30", 0, 10, []string{"10.0.1.1", "10.0.1.2"}},
+		{"10.0.1.0/31", 0, 10, []string{"10.0.1.0", "10.0.1.1"}},
+		{"10.0.1.0/24", 250, 10, []string{"10.0.1.251", "10.0.1.252", "10.0.1.253", "10.0.1.254"}},
+		{"10.0.1.0/24", 300, 10, []string{}},
+		{"255.255.255.252/30", 5, 10, []string{}},
+		{"fd00::/64", 1 << 40, 2, []string{"fd00::100:0:0", "fd00::100:0:1"}},
+		{"::/0", 0, 0, []string{}},
+	} {
+		got := []string{}
+		for _, a := range Hosts(netip.MustParsePrefix(tc.prefix), tc.offset, tc.limit) {
+			got = append(got, a.String())
+		}
+		assert.Equal(tc.want, got, "%s %d %d", tc.prefix, tc.offset, tc.limit)
+	}
+}
+
+func TestSubnetInfo(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix, netmask, wildcard, hosts string
+	}{
+		{"10.0.1.0/24", "255.255.255.0", "0.0.0.255", "254"},
+		{"10.0.1.0/31", "255.255.255.254", "0.0.0.1", "2"},
+		{"0.0.0.0/0", "0.0.0.0", "255.255.255.255", "4294967294"},
+		{"fd00::/64", "ffff:ffff:ffff:ffff::", "::ffff:ffff:ffff:ffff", "18446744073709551616"},
+	} {
+		prefix := netip.MustParsePrefix(tc.prefix)
+		assert.Equal(tc.netmask, Netmask(prefix).String(), tc.prefix)
+		assert.Equal(tc.wildcard, WildcardMask(prefix).String(), tc.prefix)
+		assert.Equal(tc.hosts, HostCount(prefix).String(), tc.prefix)
+	}
+}
+
+func TestULAPrefix(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("fd12:3456:789a::/48", ULAPrefix([5]byte{0x12, 0x34, 0x56, 0x78, 0x9a}).String())
+
+	seeded := ULAPrefix(SeededULAGlobalID("example"))
+	assert.Equal(seeded, ULAPrefix(SeededULAGlobalID("example")))
+	assert.NotEqual(seeded, ULAPrefix(SeededULAGlobalID("other")))
+	assert.True(netip.MustParsePrefix("fd00::/8").Contains(seeded.Addr()))
+
+	id, err := RandomULAGlobalID()
+	if assert.NoError(err) {
+		assert.True(netip.MustParsePrefix("fd00::/8").Contains(ULAPrefix(id).Addr()))
+	}
+}
+
+func TestMaskLength(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		mask   string
+		length int
+	}{
+		{"255.255.255.0", 24},
+		{"255.255.240.0", 20},
+		{"0.0.0.0", 0},
+		{"255.255.255.255", 32},
+		{"ffff:ffff:ffff:ffff::", 64},
+	} {
+		length, err := MaskLength(netip.MustParseAddr(tc.mask))
+		if assert.NoError(err, tc.mask) {
+			assert.Equal(tc.length, length, tc.mask)
+		}
+	}
+	_, err := MaskLength(netip.MustParseAddr("255.0.255.0"))
+	assert.Error(err)
+
+	length, err := WildcardMaskLength(netip.MustParseAddr("0.0.15.255"))
+	if assert.NoError(err) {
+		assert.Equal(20, length)
+	}
+	_, err = WildcardMaskLength(netip.MustParseAddr("255.255.255.0"))
+	assert.Error(err)
+}
+
+func TestClassify(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix  string
+		classes []string
+	}{
+		{"10.1.0.0/16", []string{ClassPrivate}},
+		{"100.100.0.1/32", []string{ClassCGNAT}},
+		{"8.8.8.8/32", []string{ClassGlobalUnicast}},
+		{"198.51.100.0/25", []string{ClassDocumentation}},
+		{"224.0.0.251/32", []string{ClassMulticast}},
+		{"0.0.0.0/0", nil},
+		{"::1/128", []string{ClassLoopback}},
+		{"fd00:1::/64", []string{ClassUniqueLocal}},
+		{"fe80::/64", []string{ClassLinkLocal}},
+		{"2001:db8::/48", []string{ClassDocumentation}},
+		{"2600::/16", []string{ClassGlobalUnicast}},
+		{"2000::/3", nil},
+		{"4000::/16", nil},
+	} {
+		assert.Equal(tc.classes, Classify(netip.MustParsePrefix(tc.prefix)), tc.prefix)
+	}
+}
+
+func TestReverseZones(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix string
+		zones  []string
+	}{
+		{"10.0.0.0/8", []string{"10.in-addr.arpa"}},
+		{"10.0.0.0/23", []string{"0.0.10.in-addr.arpa", "1.0.10.in-addr.arpa"}},
+		{"10.0.1.64/26", []string{"1.0.10.in-addr.arpa"}},
+		{"0.0.0.0/0", []string{"in-addr.arpa"}},
+		{"2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa"}},
+		{"2001:db8::/31", []string{"8.b.d.0.1.0.0.2.ip6.arpa", "9.b.d.0.1.0.0.2.ip6.arpa"}},
+	} {
+		var zones []string
+		for _, p := range ReverseZonePrefixes(netip.MustParsePrefix(tc.prefix)) {
+			zones = append(zones, ReverseZoneName(p))
+		}
+		assert.Equal(tc.zones, zones, tc.prefix)
+	}
+}
+
+func TestNewDHCPScope(t *testing.T) {
+	assert := assert.New(t)
+	scope, err := NewDHCPScope(netip.MustParsePrefix("10.0.1.0/24"), 1, 0, 0)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.2-10.0.1.254", scope.Range.String())
+		assert.Equal("10.0.1.1", scope.Router.String())
+		assert.Empty(scope.Exclusions)
+		assert.Equal(int64(253), scope.Size().Int64())
+	}
+
+	scope, err = NewDHCPScope(netip.MustParsePrefix("10.0.1.0/24"), 10, 5, 10)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.6-10.0.1.244", scope.Range.String())
+		assert.Equal([]AddressRange{{Start: netip.MustParseAddr("10.0.1.10"), End: netip.MustParseAddr("10.0.1.10")}}, scope.Exclusions)
+		assert.Equal(int64(238), scope.Size().Int64())
+	}
+
+	scope, err = NewDHCPScope(netip.MustParsePrefix("10.0.1.0/24"), -2, 0, 0)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.1-10.0.1.253", scope.Range.String())
+	}
+
+	_, err = NewDHCPScope(netip.MustParsePrefix("10.0.1.0/24"), 0, 0, 0)
+	assert.Error(err)
+	_, err = NewDHCPScope(netip.MustParsePrefix("10.0.1.0/29"), 1, 3, 3)
+	assert.Error(err)
+}
+
+func TestPack(t *testing.T) {
+	assert := assert.New(t)
+	subnets, err := Pack(netip.MustParsePrefix("10.0.0.0/22"), []int{26, 24, 25, 24})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.2.128/26"),
+			netip.MustParsePrefix("10.0.0.0/24"),
+			netip.MustParsePrefix("10.0.2.0/25"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+		}, subnets)
+	}
+
+	_, err = Pack(netip.MustParsePrefix("10.0.0.0/24"), []int{25, 25, 26})
+	assert.Error(err)
+	_, err = Pack(netip.MustParsePrefix("10.0.0.0/24"), []int{23})
+	assert.Error(err)
+	_, err = Pack(netip.MustParsePrefix("0.0.0.0/0"), []int{1, 1})
+	assert.NoError(err)
+}
+
+func TestSequential(t *testing.T) {
+	assert := assert.New(t)
+	subnets, err := Sequential(netip.MustParsePrefix("10.0.0.0/22"), []int{26, 24, 25, 26})
+	if assert.NoError(err) {
+		assert.Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/26"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("10.0.2.0/25"),
+			netip.MustParsePrefix("10.0.2.128/26"),
+		}, subnets)
+	}
+
+	_, err = Sequential(netip.MustParsePrefix("10.0.0.0/24"), []int{26, 25, 25})
+	assert.Error(err)
+	_, err = Sequential(netip.MustParsePrefix("10.0.0.0/24"), []int{23})
+	assert.Error(err)
+	_, err = Sequential(netip.MustParsePrefix("255.255.255.0/24"), []int{25, 25, 25})
+	assert.Error(err)
+}
+
+func TestNthSubnet(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix     string
+		maskLength int
+		index      int64
+		subnet     string
+	}{
+		{"10.0.0.0/16", 24, 0, "10.0.0.0/24"},
+		{"10.0.0.0/16", 24, 5, "10.0.5.0/24"},
+		{"10.0.0.0/16", 24, -1, "10.0.255.0/24"},
+		{"10.0.0.0/16", 16, 0, "10.0.0.0/16"},
+		{"fd00::/48", 64, 10, "fd00:0:0:a::/64"},
+	} {
+		subnet, err := NthSubnet(netip.MustParsePrefix(tc.prefix), tc.maskLength, big.NewInt(tc.index))
+		if assert.NoError(err, tc.subnet) {
+			assert.Equal(tc.subnet, subnet.String())
+		}
+		index, err := SubnetIndex(netip.MustParsePrefix(tc.prefix), subnet)
+		if assert.NoError(err, tc.subnet) && tc.index >= 0 {
+			assert.Equal(tc.index, index.Int64(), tc.subnet)
+		}
+	}
+
+	_, err := NthSubnet(netip.MustParsePrefix("10.0.0.0/16"), 24, big.NewInt(256))
+	assert.Error(err)
+	_, err = NthSubnet(netip.MustParsePrefix("10.0.0.0/16"), 24, big.NewInt(-257))
+	assert.Error(err)
+	_, err = NthSubnet(netip.MustParsePrefix("10.0.0.0/16"), 8, big.NewInt(0))
+	assert.Error(err)
+	_, err = SubnetIndex(netip.MustParsePrefix("10.0.0.0/16"), netip.MustParsePrefix("10.1.0.0/24"))
+	assert.Error(err)
+	_, err = SubnetIndex(netip.MustParsePrefix("10.0.0.0/16"), netip.MustParsePrefix("10.0.0.0/8"))
+	assert.Error(err)
+}
+
+func TestNextSubnet(t *testing.T) {
+	assert := assert.New(t)
+	used := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/26"),
+		netip.MustParsePrefix("10.0.0.128/25"),
+		netip.MustParsePrefix("fd00::/64"),
+	}
+	subnet, err := NextSubnet(netip.MustParsePrefix("10.0.0.0/23"), used, 26)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.64/26", subnet.String())
+	}
+	subnet, err = NextSubnet(netip.MustParsePrefix("10.0.0.0/23"), used, 25)
+	if assert.NoError(err) {
+		assert.Equal("10.0.1.0/25", subnet.String())
+	}
+	subnet, err = NextSubnet(netip.MustParsePrefix("10.0.0.0/24"), nil, 24)
+	if assert.NoError(err) {
+		assert.Equal("10.0.0.0/24", subnet.String())
+	}
+
+	_, err = NextSubnet(netip.MustParsePrefix("10.0.0.0/24"), used, 25)
+	assert.Error(err)
+	_, err = NextSubnet(netip.MustParsePrefix("10.0.0.0/24"), nil, 23)
+	assert.Error(err)
+	_, err = NextSubnet(netip.MustParsePrefix("10.0.0.0/24"), nil, 33)
+	assert.Error(err)
+}
+
+func TestEmbedIPv4(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		prefix string
+		ipv4   string
+		ipv6   string
+	}{
+		{"64:ff9b::/96", "192.0.2.33/32", "64:ff9b::c000:221/128"},
+		{"64:ff9b::/96", "192.0.2.0/24", "64:ff9b::c000:200/120"},
+		{"2001:db8::/32", "192.0.2.33/32", "2001:db8:c000:221::/64"},
+		{"2001:db8::/48", "192.0.2.33/32", "2001:db8:0:c000:2:2100::/88"},
+		{"2001:db8::/56", "192.0.2.0/24", "2001:db8:0:c0:0:200::/88"},
+		{"2002::/16", "192.0.2.0/24", "2002:c000:200::/40"},
+	} {
+		embedded, err := EmbedIPv4(netip.MustParsePrefix(tc.prefix), netip.MustParsePrefix(tc.ipv4))
+		if assert.NoError(err, tc.ipv4) {
+			assert.Equal(tc.ipv6, embedded.String(), tc.prefix)
+		}
+		extracted, err := ExtractIPv4(netip.MustParsePrefix(tc.prefix), embedded)
+		if assert.NoError(err, tc.ipv6) {
+			assert.Equal(tc.ipv4, extracted.String(), tc.prefix)
+		}
+	}
+
+	_, err := EmbedIPv4(netip.MustParsePrefix("2001:db8::/100"), netip.MustParsePrefix("192.0.2.0/24"))
+	assert.Error(err)
+	_, err = ExtractIPv4(NAT64WellKnownPrefix, netip.MustParsePrefix("2001:db8::/64"))
+	assert.Error(err)
+}
+
+func TestEUI64Addr(t *testing.T) {
+	assert := assert.New(t)
+	mac, _ := net.ParseMAC("00:1a:2b:3c:4d:5e")
+	addr, err := EUI64Addr(netip.MustParsePrefix("2001:db8:1:2::/64"), mac)
+	if assert.NoError(err) {
+		assert.Equal("2001:db8:1:2:21a:2bff:fe3c:4d5e", addr.String())
+	}
+	_, err = EUI64Addr(netip.MustParsePrefix("2001:db8:1:2::/80"), mac)
+	assert.Error(err)
+}
+
+func TestStableAddr(t *testing.T) {
+	assert := assert.New(t)
+	prefix := netip.MustParsePrefix("2001:db8:1:2::/64")
+	addr := StableAddr(prefix, "web-1")
+	assert.True(prefix.Contains(addr))
+	assert.NotEqual(prefix.Addr(), addr)
+	assert.Equal(addr, StableAddr(prefix, "web-1"))
+	assert.NotEqual(addr, StableAddr(prefix, "web-2"))
+	assert.True(netip.MustParsePrefix("10.0.1.0/24").Contains(StableAddr(netip.MustParsePrefix("10.0.1.0/24"), "web-1")))
+}
@@ -0,0 +1,68 @@
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Tracer receives the decisions made while allocating a subnet, to explain
+// why a subnet was chosen.
+type Tracer interface {
+	// Rejected reports a candidate that was not chosen and why. Conflict is
+	// the allocated or reserved prefix the candidate overlaps, and invalid
+	// if the candidate was rejected for another reason.
+	Rejected(candidate netip.Prefix, conflict netip.Prefix, reason string)
+	// Chosen reports the allocated subnet and why it was chosen.
+	Chosen(subnet netip.Prefix, reason string)
+}
+
+func (c *Calculator) traceRejected(candidate netip.Prefix, conflict netip.Prefix, reason string) {
+	if c.Tracer != nil {
+		c.Tracer.Rejected(candidate, conflict, reason)
+	}
+}
+
+func (c *Calculator) traceChosen(subnet netip.Prefix, reason string) {
+	if c.Tracer != nil {
+		c.Tracer.Chosen(subnet, reason)
+	}
+}
+
+// traceChoice reports how a strategy chose a subnet of the given mask length
+// from free CIDR blocks.
+func (c *Calculator) traceChoice(s Strategy, free []netip.Prefix, numBits int, chosen netip.Prefix, ok bool) {
+	if c.Tracer == nil {
+		return
+	}
+	if s == "" {
+		s = FirstFit
+	}
+	var block netip.Prefix
+	for _, f := range free {
+		if ok && f.Contains(chosen.Addr()) {
+			block = f
+		}
+	}
+	for _, f := range free {
+		switch {
+		case numBits > f.Addr().BitLen():
+			c.Tracer.Rejected(f, netip.Prefix{}, fmt.Sprintf("free CIDR block is not of the address family of a /%d subnet", numBits))
+		case f.Bits() > numBits:
+			c.Tracer.Rejected(f, netip.Prefix{}, fmt.Sprintf("free CIDR block is too small for a /%d subnet", numBits))
+		case f == block:
+		default:
+			c.Tracer.Rejected(f, netip.Prefix{}, fmt.Sprintf("the %s strategy prefers free CIDR block %s", s, block))
+		}
+	}
+	if !ok {
+		return
+	}
+	switch s {
+	case BestFit:
+		c.Tracer.Chosen(chosen, "start of the smallest free CIDR block that fits, with the best_fit strategy")
+	case Spread:
+		c.Tracer.Chosen(chosen, "start of the largest free CIDR block, with the spread strategy")
+	default:
+		c.Tracer.Chosen(chosen, "start of the first free CIDR block that fits, with the first_fit strategy")
+	}
+}
@@ -0,0 +1,89 @@
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+var (
+	// NAT64WellKnownPrefix is the RFC 6052 well-known prefix for IPv4/IPv6
+	// translation.
+	NAT64WellKnownPrefix = netip.MustParsePrefix("64:ff9b::/96")
+	// SixToFourPrefix is the RFC 3056 6to4 prefix, which embeds an IPv4
+	// address after its first 16 bits.
+	SixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// ValidNAT64PrefixLength reports whether an IPv6 prefix length is one of the
+// lengths RFC 6052 allows for embedding IPv4 addresses.
+func ValidNAT64PrefixLength(length int) bool {
+	switch length {
+	case 32, 40, 48, 56, 64, 96:
+		return true
+	}
+	return false
+}
+
+// embeddedBitPosition returns the position within an IPv6 address of bit i of
+// an IPv4 address embedded after a prefix of the given length. Following RFC
+// 6052, bits 64 to 71 of the IPv6 address are left zero.
+func embeddedBitPosition(prefixLength, i int) int {
+	pos := prefixLength + i
+	if prefixLength < 64 && pos >= 64 {
+		pos += 8
+	}
+	return pos
+}
+
+// EmbedIPv4 maps an IPv4 prefix into an IPv6 prefix by embedding it after
+// the given IPv6 prefix, as done by NAT64 (RFC 6052) and 6to4 (RFC 3056).
+func EmbedIPv4(prefix netip.Prefix, ipv4 netip.Prefix) (netip.Prefix, error) {
+	prefix = prefix.Masked()
+	ipv4 = ipv4.Masked()
+	if !prefix.Addr().Is6() || !ipv4.Addr().Is4() {
+		return netip.Prefix{}, fmt.Errorf("%s cannot be embedded in %s", ipv4, prefix)
+	}
+	if embeddedBitPosition(prefix.Bits(), 31) >= 128 {
+		return netip.Prefix{}, fmt.Errorf("%s has no room for an IPv4 address", prefix)
+	}
+	a := prefix.Addr().As16()
+	v4 := ipv4.Addr().As4()
+	for i := 0; i < 32; i++ {
+		if v4[i/8]&(128>>(i%8)) != 0 {
+			pos := embeddedBitPosition(prefix.Bits(), i)
+			a[pos/8] |= 128 >> (pos % 8)
+		}
+	}
+	length := prefix.Bits()
+	if ipv4.Bits() > 0 {
+		length = embeddedBitPosition(prefix.Bits(), ipv4.Bits()-1) + 1
+	}
+	return netip.PrefixFrom(netip.AddrFrom16(a), length), nil
+}
+
+// ExtractIPv4 reverses EmbedIPv4, returning the IPv4 prefix embedded in an
+// IPv6 prefix within the given IPv6 prefix.
+func ExtractIPv4(prefix netip.Prefix, ipv6 netip.Prefix) (netip.Prefix, error) {
+	prefix = prefix.Masked()
+	ipv6 = ipv6.Masked()
+	if !ipv6.Addr().Is6() || ipv6.Bits() < prefix.Bits() || !prefix.Contains(ipv6.Addr()) {
+		return netip.Prefix{}, fmt.Errorf("%s is not within %s", ipv6, prefix)
+	}
+	if embeddedBitPosition(prefix.Bits(), 31) >= 128 {
+		return netip.Prefix{}, fmt.Errorf("%s has no room for an IPv4 address", prefix)
+	}
+	a := ipv6.Addr().As16()
+	var v4 [4]byte
+	length := 0
+	for i := 0; i < 32; i++ {
+		pos := embeddedBitPosition(prefix.Bits(), i)
+		if pos >= ipv6.Bits() {
+			break
+		}
+		if a[pos/8]&(128>>(pos%8)) != 0 {
+			v4[i/8] |= 128 >> (i % 8)
+		}
+		length = i + 1
+	}
+	return netip.PrefixFrom(netip.AddrFrom4(v4), length), nil
+}
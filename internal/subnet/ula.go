@@ -0,0 +1,38 @@
+package subnet
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"net/netip"
+)
+
+// ULAPrefixLength is the length of a unique local address prefix assigned to a
+// site, made up of the fd00::/8 prefix and a 40-bit global ID.
+const ULAPrefixLength = 48
+
+// ULAPrefix returns the RFC 4193 unique local /48 prefix with the given global
+// ID.
+func ULAPrefix(globalID [5]byte) netip.Prefix {
+	var a [16]byte
+	a[0] = 0xfd
+	copy(a[1:6], globalID[:])
+	return netip.PrefixFrom(netip.AddrFrom16(a), ULAPrefixLength)
+}
+
+// SeededULAGlobalID derives a global ID from a seed, following the algorithm
+// suggested by RFC 4193 with the seed in place of the time and EUI-64: the
+// least significant 40 bits of the seed's SHA-1 digest. The same seed always
+// yields the same global ID.
+func SeededULAGlobalID(seed string) [5]byte {
+	var id [5]byte
+	sum := sha1.Sum([]byte(seed))
+	copy(id[:], sum[len(sum)-len(id):])
+	return id
+}
+
+// RandomULAGlobalID returns a random global ID.
+func RandomULAGlobalID() ([5]byte, error) {
+	var id [5]byte
+	_, err := rand.Read(id[:])
+	return id, err
+}